@@ -1,8 +1,15 @@
 package config
 
 import (
-	"github.com/spf13/viper"
+	"context"
+	"fmt"
 	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
 )
 
 const (
@@ -14,11 +21,41 @@ const (
 	DefaultAppVersion = "unknown" // default application version
 )
 
+// Build information, to set at build time with -ldflags (like -X github.com/ankorstore/yokai/config.BuildVersion=v1.2.3),
+// used as defaults of the app.version, app.build.commit and app.build.date config fields.
+var (
+	BuildVersion string // application version
+	BuildCommit  string // application git commit SHA (defaults to the vcs.revision of the Go build info)
+	BuildDate    string // application build date (defaults to the vcs.time of the Go build info)
+)
+
 // Config allows to access the application configuration, and inherits of all [Viper] features.
 //
+// The config values are replaced at once on reloads and on the remote config source refreshes, and are safe to read
+// concurrently with them through the Config accessors (the embedded Viper being the one the config was created with).
+//
 // [Viper]: https://github.com/spf13/viper
 type Config struct {
 	*viper.Viper
+	latest                  atomic.Pointer[viper.Viper]
+	source                  ConfigSource
+	sourcePrecedence        string
+	sourceTimeout           time.Duration
+	sourceSettings          map[string]interface{}
+	sourceListeners         []func()
+	options                 Options
+	envReplacer             *envKeyReplacer
+	files                   []string
+	skipped                 []string
+	sources                 map[string]string
+	changeListeners         []func(keys []string)
+	errorListeners          []func(err error)
+	flagListeners           []func(name string, enabled bool)
+	flagResolutionListeners []func(name string, value FlagValue)
+	flagProvider            FlagProvider
+	flagValues              map[string]FlagValue
+	secrets                 map[string]struct{}
+	mutex                   sync.Mutex
 }
 
 // GetEnvVar returns the value of an env var.
@@ -41,11 +78,53 @@ func (c *Config) AppVersion() string {
 	return c.GetString("app.version")
 }
 
+// AppBuildCommit returns the configured application git commit SHA (from config field app.build.commit or env var
+// APP_BUILD_COMMIT).
+func (c *Config) AppBuildCommit() string {
+	return c.GetString("app.build.commit")
+}
+
+// AppBuildDate returns the configured application build date (from config field app.build.date or env var
+// APP_BUILD_DATE).
+func (c *Config) AppBuildDate() string {
+	return c.GetString("app.build.date")
+}
+
 // AppDebug returns if the application debug mode is enabled (from config field app.debug or env var APP_DEBUG).
 func (c *Config) AppDebug() bool {
 	return c.GetBool("app.debug")
 }
 
+// GetDuration returns the duration value of a key, from a duration string (like "30s" or "1m30s") or from a number of
+// seconds (like 0.5), or zero if the key is not set. Unlike the Viper one, it returns an error for malformed values.
+func (c *Config) GetDuration(key string) (time.Duration, error) {
+	if !c.IsSet(key) {
+		return 0, nil
+	}
+
+	duration, err := parseDuration(c.Get(key))
+	if err != nil {
+		return 0, fmt.Errorf("invalid config %s: %w", key, err)
+	}
+
+	return duration, nil
+}
+
+// GetBytes returns the size in bytes value of a key, from a number of bytes or from a string with a unit (like "2M" or
+// "16MB", as multiples of 1024), or zero if the key is not set. It returns an error for malformed values.
+func (c *Config) GetBytes(key string) (ByteSize, error) {
+	if !c.IsSet(key) {
+		return 0, nil
+	}
+
+	size, err := parseByteSize(c.Get(key))
+	if err != nil {
+		return 0, fmt.Errorf("invalid config %s: %w", key, err)
+	}
+
+	return size, nil
+}
+
 // IsProdEnv returns if the application is running in prod mode.
 func (c *Config) IsProdEnv() bool {
 	return c.AppEnv() == AppEnvProd
@@ -60,3 +139,86 @@ func (c *Config) IsDevEnv() bool {
 func (c *Config) IsTestEnv() bool {
 	return c.AppEnv() == AppEnvTest
 }
+
+// Source returns the remote [ConfigSource] the config was fetched from, or nil if none.
+func (c *Config) Source() ConfigSource {
+	return c.source
+}
+
+// OnSourceChange registers a function to call when the remote config source values change on refresh.
+func (c *Config) OnSourceChange(fn func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sourceListeners = append(c.sourceListeners, fn)
+}
+
+// RefreshSource fetches again the remote config source values and applies them, returning if they changed.
+// The functions registered with OnSourceChange are called if they changed (and the ones registered with OnChange with
+// the changed keys), and the previous values are kept on error.
+//
+// The values are applied on top of the config files loaded again, and replaced at once as on [Config.Reload].
+func (c *Config) RefreshSource(ctx context.Context) (bool, error) {
+	if c.source == nil {
+		return false, nil
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, c.sourceTimeout)
+	defer cancel()
+
+	settings, err := c.source.Fetch(fetchCtx)
+	if err != nil {
+		return false, fmt.Errorf("could not fetch config from source %s: %w", c.source.Name(), err)
+	}
+
+	c.mutex.Lock()
+
+	if reflect.DeepEqual(settings, c.sourceSettings) {
+		c.mutex.Unlock()
+
+		return false, nil
+	}
+
+	loaded, err := c.rebuild(settings)
+	if err != nil {
+		c.mutex.Unlock()
+
+		return false, err
+	}
+
+	c.sourceSettings = settings
+	changed := c.swap(loaded)
+	listeners := c.sourceListeners
+	changeListeners := c.changeListeners
+
+	c.mutex.Unlock()
+
+	for _, listener := range listeners {
+		listener()
+	}
+
+	notifyChange(changeListeners, changed)
+
+	return true, nil
+}
+
+// WatchSource refreshes the remote config source values every given interval, until the given context is canceled.
+// Refresh failures are ignored, the previous values being kept until the next successful refresh.
+func (c *Config) WatchSource(ctx context.Context, interval time.Duration) {
+	if c.source == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			//nolint:errcheck
+			c.RefreshSource(ctx)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/config"
 	"github.com/stretchr/testify/assert"
@@ -143,6 +144,49 @@ func TestAppVersionOverrideFromEnvVar(t *testing.T) {
 	assert.Equal(t, "0.1.2", cfg.AppVersion())
 }
 
+func TestAppBuildInfoFromBuildVars(t *testing.T) {
+	config.BuildVersion, config.BuildCommit, config.BuildDate = "1.2.3", "abc123", "2024-01-01T00:00:00Z"
+	defer func() {
+		config.BuildVersion, config.BuildCommit, config.BuildDate = "", "", ""
+	}()
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config/layers/local"),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3", cfg.AppVersion())
+	assert.Equal(t, "abc123", cfg.AppBuildCommit())
+	assert.Equal(t, "2024-01-01T00:00:00Z", cfg.AppBuildDate())
+
+	// the configured version takes precedence
+	cfg, err = createTestConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0.1.0", cfg.AppVersion())
+	assert.Equal(t, "abc123", cfg.AppBuildCommit())
+}
+
+func TestAppBuildInfoOverrideFromEnvVars(t *testing.T) {
+	t.Setenv("APP_BUILD_COMMIT", "def456")
+	t.Setenv("APP_BUILD_DATE", "2024-02-02T00:00:00Z")
+
+	cfg, err := createTestConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "def456", cfg.AppBuildCommit())
+	assert.Equal(t, "2024-02-02T00:00:00Z", cfg.AppBuildDate())
+}
+
+func TestAppBuildInfoNotSetWhenUnknown(t *testing.T) {
+	cfg, err := createTestConfig()
+
+	assert.NoError(t, err)
+	assert.False(t, cfg.IsSet("app.build.commit"))
+	assert.False(t, cfg.IsSet("app.build.date"))
+	assert.NotContains(t, cfg.AllSettings()["app"], "build")
+}
+
 func TestValuesFromConfig(t *testing.T) {
 	cfg, err := createTestConfig()
 
@@ -185,6 +229,21 @@ func TestValuesWithEnvVarsPlaceholder(t *testing.T) {
 	assert.Equal(t, "foo-bar-baz", cfg.GetString("config.placeholder"))
 }
 
+func TestValuesWithEnvVarsPlaceholderInMap(t *testing.T) {
+	t.Setenv("BAR", "bar")
+
+	cfg, err := createTestConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "foo-bar-baz", cfg.GetString("config.placeholder"))
+
+	// sibling keys not hidden by the expanded value
+	values := cfg.GetStringMap("config")
+	assert.Equal(t, "foo-bar-baz", values["placeholder"])
+	assert.Equal(t, "foo", values["substitution"])
+	assert.Contains(t, values, "values")
+}
+
 func TestValuesWithEnvVarsSubstitution(t *testing.T) {
 	cfg, err := createTestConfig()
 
@@ -204,3 +263,96 @@ func createTestConfig() (*config.Config, error) {
 		config.WithFilePaths("./testdata/config/valid"),
 	)
 }
+
+func TestGetDuration(t *testing.T) {
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		value    interface{}
+		expected time.Duration
+		err      string
+	}{
+		{value: "30s", expected: 30 * time.Second},
+		{value: "1m30s", expected: 90 * time.Second},
+		{value: " 250ms ", expected: 250 * time.Millisecond},
+		{value: "5", expected: 5 * time.Second},
+		{value: 0.5, expected: 500 * time.Millisecond},
+		{value: 10, expected: 10 * time.Second},
+		{value: 2 * time.Minute, expected: 2 * time.Minute},
+		{value: "forever", err: `invalid config test.duration: invalid duration "forever"`},
+		{value: "30 seconds", err: `invalid config test.duration: invalid duration "30 seconds"`},
+		{value: []string{"30s"}, err: "invalid config test.duration: invalid duration [30s]"},
+	}
+
+	for _, tt := range tests {
+		cfg.Set("test.duration", tt.value)
+
+		duration, err := cfg.GetDuration("test.duration")
+
+		if tt.err != "" {
+			assert.Error(t, err, tt.value)
+			assert.Equal(t, tt.err, err.Error())
+		} else {
+			assert.NoError(t, err, tt.value)
+			assert.Equal(t, tt.expected, duration, tt.value)
+		}
+	}
+
+	duration, err := cfg.GetDuration("test.missing")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), duration)
+}
+
+func TestGetDurationFromEnvVar(t *testing.T) {
+	t.Setenv("CONFIG_VALUES_DURATION_VALUE", "45s")
+
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	cfg.SetDefault("config.values.duration_value", "10s")
+
+	duration, err := cfg.GetDuration("config.values.duration_value")
+	assert.NoError(t, err)
+	assert.Equal(t, 45*time.Second, duration)
+}
+
+func TestGetBytes(t *testing.T) {
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		value    interface{}
+		expected config.ByteSize
+		err      string
+	}{
+		{value: "2M", expected: 2 << 20},
+		{value: "16MB", expected: 16 << 20},
+		{value: "512 KiB", expected: 512 << 10},
+		{value: "1024", expected: 1024},
+		{value: 4096, expected: 4096},
+		{value: 1.5, expected: 1},
+		{value: "2 parsecs", err: `invalid config test.size: invalid byte size unit "2 parsecs"`},
+		{value: "MB", err: `invalid config test.size: invalid byte size "MB"`},
+		{value: -1, err: "invalid config test.size: invalid byte size -1"},
+		{value: true, err: "invalid config test.size: invalid byte size true"},
+	}
+
+	for _, tt := range tests {
+		cfg.Set("test.size", tt.value)
+
+		size, err := cfg.GetBytes("test.size")
+
+		if tt.err != "" {
+			assert.Error(t, err, tt.value)
+			assert.Equal(t, tt.err, err.Error())
+		} else {
+			assert.NoError(t, err, tt.value)
+			assert.Equal(t, tt.expected, size, tt.value)
+		}
+	}
+
+	size, err := cfg.GetBytes("test.missing")
+	assert.NoError(t, err)
+	assert.Equal(t, config.ByteSize(0), size)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DumpFormatJson = "json" // JSON config dump format
+	DumpFormatYaml = "yaml" // YAML config dump format
+)
+
+// ConfigDump is the content of a config dump: the merged config values, with the secret values masked, and the env vars
+// overriding them.
+type ConfigDump struct {
+	Settings     map[string]interface{} `json:"settings" yaml:"settings"`
+	EnvOverrides map[string]string      `json:"env_overrides" yaml:"env_overrides"`
+}
+
+// Dump writes the fully merged config in a given format ([DumpFormatJson] or [DumpFormatYaml]), to inspect the config
+// the application is actually running with: the values of the secret keys are replaced by [SecretMask] (see
+// MaskedSettings), and the config keys overridden by env vars are listed with their env var names (never their
+// values).
+func (c *Config) Dump(w io.Writer, format string) error {
+	dump := c.dump()
+
+	switch strings.ToLower(format) {
+	case DumpFormatJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(dump); err != nil {
+			return fmt.Errorf("could not dump config as json: %w", err)
+		}
+	case DumpFormatYaml, "yml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(dump); err != nil {
+			return fmt.Errorf("could not dump config as yaml: %w", err)
+		}
+
+		if err := encoder.Close(); err != nil {
+			return fmt.Errorf("could not dump config as yaml: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported config dump format %s", format)
+	}
+
+	return nil
+}
+
+func (c *Config) dump() ConfigDump {
+	settings := c.MaskedSettings()
+	keys := c.AllKeys()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	overrides := map[string]string{}
+	if c.envReplacer != nil {
+		for _, key := range keys {
+			if env, ok := c.envReplacer.lookup(key); ok {
+				overrides[key] = env
+			}
+		}
+	}
+
+	return ConfigDump{
+		Settings:     settings,
+		EnvOverrides: overrides,
+	}
+}
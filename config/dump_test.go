@@ -0,0 +1,126 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func createTestDumpConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeTestSecretFile(t, dir, "password", "s3cr3t-password")
+
+	t.Setenv("SECRET_DIR", dir)
+	t.Setenv("API_URL", "https://env.example.com")
+	t.Setenv("DATABASE_CREDENTIALS_TOKEN", "s3cr3t-env-token")
+
+	cfg, err := createTestSecretConfig(t)
+	assert.NoError(t, err)
+
+	cfg.MarkSecret("api.key")
+
+	return cfg
+}
+
+// assertNoSecretInDump asserts that none of the secret values of the test dump config appear in a dump.
+func assertNoSecretInDump(t *testing.T, dump string) {
+	t.Helper()
+
+	for _, secret := range []string{"s3cr3t-password", "s3cr3t-env-token", "admin", "plain-key"} {
+		assert.NotContains(t, dump, secret)
+	}
+}
+
+func TestDumpAsJson(t *testing.T) {
+	cfg := createTestDumpConfig(t)
+
+	var buffer bytes.Buffer
+	err := cfg.Dump(&buffer, config.DumpFormatJson)
+	assert.NoError(t, err)
+
+	assertNoSecretInDump(t, buffer.String())
+
+	var dump config.ConfigDump
+	err = json.Unmarshal(buffer.Bytes(), &dump)
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"host":        "localhost",
+			"password":    config.SecretMask,
+			"credentials": config.SecretMask,
+		},
+		dump.Settings["database"],
+	)
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"url": "https://env.example.com",
+			"key": config.SecretMask,
+		},
+		dump.Settings["api"],
+	)
+
+	// env overrides annotated with their env var names only
+	assert.Equal(t, "API_URL", dump.EnvOverrides["api.url"])
+	assert.Equal(t, "DATABASE_CREDENTIALS_TOKEN", dump.EnvOverrides["database.credentials.token"])
+	assert.NotContains(t, dump.EnvOverrides, "database.host")
+}
+
+func TestDumpAsYaml(t *testing.T) {
+	cfg := createTestDumpConfig(t)
+
+	var buffer bytes.Buffer
+	err := cfg.Dump(&buffer, config.DumpFormatYaml)
+	assert.NoError(t, err)
+
+	assertNoSecretInDump(t, buffer.String())
+
+	var dump config.ConfigDump
+	err = yaml.Unmarshal(buffer.Bytes(), &dump)
+	assert.NoError(t, err)
+
+	//nolint:forcetypeassert
+	assert.Equal(t, "secret-app", dump.Settings["app"].(map[string]interface{})["name"])
+	//nolint:forcetypeassert
+	assert.Equal(t, config.SecretMask, dump.Settings["database"].(map[string]interface{})["password"])
+	assert.Equal(t, "API_URL", dump.EnvOverrides["api.url"])
+}
+
+func TestDumpWithEnvAlias(t *testing.T) {
+	t.Setenv("PORT", "8081")
+	t.Setenv("MYAPP_MODULES_HTTP_SERVER_ADDRESS", "0.0.0.0")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/env"))
+	assert.NoError(t, err)
+
+	var buffer bytes.Buffer
+	err = cfg.Dump(&buffer, config.DumpFormatJson)
+	assert.NoError(t, err)
+
+	var dump config.ConfigDump
+	err = json.Unmarshal(buffer.Bytes(), &dump)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "PORT", dump.EnvOverrides["modules.http.server.port"])
+	assert.Equal(t, "MYAPP_MODULES_HTTP_SERVER_ADDRESS", dump.EnvOverrides["modules.http.server.address"])
+	assert.NotContains(t, dump.EnvOverrides, "app.name")
+}
+
+func TestDumpFailureOnUnsupportedFormat(t *testing.T) {
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	var buffer bytes.Buffer
+	err = cfg.Dump(&buffer, "xml")
+	assert.Error(t, err)
+	assert.Equal(t, "unsupported config dump format xml", err.Error())
+	assert.Empty(t, buffer.String())
+}
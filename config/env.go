@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	EnvCaseUpper = "upper" // env vars names in upper case
+	EnvCaseLower = "lower" // env vars names in lower case
+
+	DefaultEnvSeparator = "_" // default replacement of the config keys dots in the env vars names
+)
+
+// envAlias is the configuration of an env var name not following the config key to env var name mapping.
+type envAlias struct {
+	Key string `mapstructure:"key"`
+	Env string `mapstructure:"env"`
+}
+
+// envKeyReplacer maps the config keys to env vars names, and is configured once the config files are loaded.
+type envKeyReplacer struct {
+	prefix    string
+	separator string
+	lowerCase bool
+	names     map[string]struct{}
+	aliases   map[string]string
+}
+
+func newEnvKeyReplacer() *envKeyReplacer {
+	return &envKeyReplacer{
+		separator: DefaultEnvSeparator,
+		names:     map[string]struct{}{},
+		aliases:   map[string]string{},
+	}
+}
+
+// Replace returns the env var name of a given config key (prefixed, with its dots replaced by the separator), or the
+// given name as is if explicitly bound.
+func (r *envKeyReplacer) Replace(key string) string {
+	if _, ok := r.names[key]; ok {
+		return key
+	}
+
+	key = r.prefix + strings.ReplaceAll(key, ".", r.separator)
+
+	if r.lowerCase {
+		return strings.ToLower(key)
+	}
+
+	return key
+}
+
+// envName returns the env var name of a given config key, as resolved by Viper (upper cased, then replaced).
+func (r *envKeyReplacer) envName(key string) string {
+	return r.Replace(strings.ToUpper(key))
+}
+
+// configure applies the modules.config.env config keys: the env vars prefix, separator, case and aliases.
+func (r *envKeyReplacer) configure(v *viper.Viper) error {
+	r.prefix = v.GetString("modules.config.env.prefix")
+
+	if separator := v.GetString("modules.config.env.separator"); separator != "" {
+		r.separator = separator
+	}
+
+	switch envCase := strings.ToLower(v.GetString("modules.config.env.case")); envCase {
+	case "", EnvCaseUpper:
+		r.lowerCase = false
+	case EnvCaseLower:
+		r.lowerCase = true
+	default:
+		return fmt.Errorf("invalid config env case %s, must be %s or %s", envCase, EnvCaseUpper, EnvCaseLower)
+	}
+
+	var aliases []envAlias
+	if err := v.UnmarshalKey("modules.config.env.aliases", &aliases); err != nil {
+		return fmt.Errorf("invalid config env aliases: %w", err)
+	}
+
+	for _, alias := range aliases {
+		if alias.Key == "" || alias.Env == "" {
+			return fmt.Errorf("invalid config env alias %s for key %s, key and env must be set", alias.Env, alias.Key)
+		}
+
+		r.bind(v, alias.Key, alias.Env)
+	}
+
+	// the APP_ENV env var selects the config files, so it is kept as fallback for the app.env key
+	r.bind(v, "app.env", "APP_ENV")
+
+	return nil
+}
+
+// lookup returns the name of the env var overriding a given config key (its alias first), and if it is set.
+func (r *envKeyReplacer) lookup(key string) (string, bool) {
+	if env, ok := r.aliases[strings.ToLower(key)]; ok {
+		if _, ok = os.LookupEnv(env); ok {
+			return env, true
+		}
+	}
+
+	env := r.envName(key)
+	_, ok := os.LookupEnv(env)
+
+	return env, ok
+}
+
+func (r *envKeyReplacer) bind(v *viper.Viper, key string, env string) {
+	r.names[env] = struct{}{}
+	r.aliases[strings.ToLower(key)] = env
+
+	//nolint:errcheck
+	v.BindEnv(key, env)
+}
@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvWithPrefix(t *testing.T) {
+	t.Setenv("MYAPP_MODULES_HTTP_SERVER_ADDRESS", "0.0.0.0")
+	t.Setenv("MODULES_HTTP_SERVER_PORT", "8081")
+
+	cfg, err := createEnvTestConfig()
+	assert.NoError(t, err)
+
+	// env over file, for prefixed env vars only
+	assert.Equal(t, "0.0.0.0", cfg.GetString("modules.http.server.address"))
+	assert.Equal(t, 8080, cfg.GetInt("modules.http.server.port"))
+
+	t.Setenv("MYAPP_MODULES_HTTP_SERVER_PORT", "8082")
+
+	cfg, err = createEnvTestConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 8082, cfg.GetInt("modules.http.server.port"))
+}
+
+func TestEnvWithPrefixAndAppEnv(t *testing.T) {
+	t.Setenv("APP_ENV", "lower")
+
+	cfg, err := createEnvTestConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "lower", cfg.AppEnv())
+
+	t.Setenv("myapp_app__env", "custom")
+
+	cfg, err = createEnvTestConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "custom", cfg.AppEnv())
+}
+
+func TestEnvWithAlias(t *testing.T) {
+	t.Setenv("PORT", "9090")
+
+	cfg, err := createEnvTestConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 9090, cfg.GetInt("modules.http.server.port"))
+
+	// prefixed env var over its alias
+	t.Setenv("MYAPP_MODULES_HTTP_SERVER_PORT", "9091")
+
+	cfg, err = createEnvTestConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 9091, cfg.GetInt("modules.http.server.port"))
+}
+
+func TestEnvWithSeparatorAndLowerCase(t *testing.T) {
+	t.Setenv("APP_ENV", "lower")
+	t.Setenv("PORT", "9090")
+	t.Setenv("MYAPP_MODULES_HTTP_SERVER_ADDRESS", "0.0.0.0")
+
+	cfg, err := createEnvTestConfig()
+	assert.NoError(t, err)
+
+	// aliases names kept as is
+	assert.Equal(t, 9090, cfg.GetInt("modules.http.server.port"))
+	assert.Equal(t, "localhost", cfg.GetString("modules.http.server.address"))
+
+	t.Setenv("myapp_modules__http__server__address", "0.0.0.0")
+
+	cfg, err = createEnvTestConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "0.0.0.0", cfg.GetString("modules.http.server.address"))
+}
+
+func TestEnvWithInvalidCase(t *testing.T) {
+	t.Setenv("APP_ENV", "invalid")
+
+	_, err := createEnvTestConfig()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid config env case camel, must be upper or lower")
+}
+
+func TestEnvWithInvalidAlias(t *testing.T) {
+	t.Setenv("APP_ENV", "alias")
+
+	_, err := createEnvTestConfig()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid config env alias  for key modules.http.server.port, key and env must be set")
+}
+
+func createEnvTestConfig() (*config.Config, error) {
+	return config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config/env"),
+	)
+}
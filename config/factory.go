@@ -1,10 +1,14 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -27,6 +31,13 @@ func NewDefaultConfigFactory() ConfigFactory {
 //
 //	var cfg, _ = config.NewDefaultConfigFactory().Create()
 //
+// The config file is loaded first, then the config files of the profiles (from the APP_PROFILES env var, or from the
+// modules.config.profiles config key) are merged in order, then the config file of the APP_ENV env var, and finally the
+// config files of the APP_CONFIG_PROFILES env var profiles, if found. The config files of the file layers (see
+// [WithFileLayers]) are then merged in order the same way.
+// The env vars override the config files values, with the env vars names mapping configured in the modules.config.env
+// config keys.
+//
 // is equivalent to:
 //
 //	var cfg, _ = config.NewDefaultConfigFactory().Create(
@@ -39,12 +50,64 @@ func (f *DefaultConfigFactory) Create(options ...ConfigOption) (*Config, error)
 		opt(&appliedOptions)
 	}
 
-	v := viper.New()
+	loaded, err := f.loadFiles(appliedOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	v := loaded.viper
+
+	cfg := &Config{
+		Viper:        v,
+		options:      appliedOptions,
+		envReplacer:  loaded.envReplacer,
+		files:        loaded.files,
+		skipped:      loaded.skipped,
+		sources:      loaded.sources,
+		flagProvider: appliedOptions.FlagProvider,
+	}
+
+	cfg.latest.Store(v)
+
+	cfg.MarkSecret(loaded.secrets...)
+	cfg.MarkSecret(v.GetStringSlice(secretsConfigKey)...)
+
+	if err = f.loadSource(cfg, appliedOptions); err != nil {
+		return nil, err
+	}
+
+	if err = expandEnvPlaceholders(v); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadedFiles are the config values loaded from the config files and the env vars.
+type loadedFiles struct {
+	viper       *viper.Viper
+	envReplacer *envKeyReplacer
+	files       []string
+	skipped     []string
+	sources     map[string]string
+	secrets     []string
+}
+
+// loadFiles returns a new [viper.Viper] loaded from the config files and the env vars, with the resolved config files
+// paths, the config file supplying each top level key, and the keys resolved from secret files.
+//
+// The config file (looked up in the file paths) is loaded first, then the config files of the profiles and the APP_ENV
+// one are merged, and finally the ones of the APP_CONFIG_PROFILES env var (the missing ones being skipped). The config
+// files found in the file layers are then merged in order the same way (the missing ones being skipped), the later
+// layers overriding the earlier ones key by key.
+func (f *DefaultConfigFactory) loadFiles(options Options) (*loadedFiles, error) {
+	envReplacer := newEnvKeyReplacer()
+
+	v := viper.NewWithOptions(viper.EnvKeyReplacer(envReplacer))
 
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
-	v.SetConfigName(appliedOptions.FileName)
-	for _, path := range appliedOptions.FilePaths {
+	v.SetConfigName(options.FileName)
+	for _, path := range options.FilePaths {
 		v.AddConfigPath(path)
 	}
 
@@ -54,9 +117,35 @@ func (f *DefaultConfigFactory) Create(options ...ConfigOption) (*Config, error)
 		return nil, err
 	}
 
+	loaded := &loadedFiles{
+		viper:       v,
+		envReplacer: envReplacer,
+		sources:     map[string]string{},
+	}
+
+	if err := loaded.addFile(v.ConfigFileUsed()); err != nil {
+		return nil, err
+	}
+
+	profiles := f.profiles(v)
+	for _, profile := range profiles {
+		v.SetConfigName(fmt.Sprintf("%s.%s", options.FileName, profile))
+		if err := v.MergeInConfig(); err != nil {
+			if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+				return nil, fmt.Errorf("could not load config file for profile %s: %w", profile, err)
+			} else {
+				return nil, fmt.Errorf("could not merge config for profile %s: %w", profile, err)
+			}
+		}
+
+		if err := loaded.addFile(v.ConfigFileUsed()); err != nil {
+			return nil, err
+		}
+	}
+
 	appEnv := os.Getenv("APP_ENV")
 	if appEnv != "" {
-		v.SetConfigName(fmt.Sprintf("%s.%s", appliedOptions.FileName, appEnv))
+		v.SetConfigName(fmt.Sprintf("%s.%s", options.FileName, appEnv))
 		if err := v.MergeInConfig(); err != nil {
 			if errors.As(err, &viper.ConfigFileNotFoundError{}) {
 				return nil, fmt.Errorf("could not load config file for env %s: %w", appEnv, err)
@@ -64,20 +153,255 @@ func (f *DefaultConfigFactory) Create(options ...ConfigOption) (*Config, error)
 				return nil, fmt.Errorf("could not merge config for env %s: %w", appEnv, err)
 			}
 		}
+
+		if err := loaded.addFile(v.ConfigFileUsed()); err != nil {
+			return nil, err
+		}
 	}
 
-	for _, key := range v.AllKeys() {
-		val := v.GetString(key)
-		if strings.Contains(val, "${") {
-			v.Set(key, os.ExpandEnv(val))
+	configProfiles := f.configProfiles()
+	for _, profile := range configProfiles {
+		name := fmt.Sprintf("%s.%s", options.FileName, profile)
+
+		v.SetConfigName(name)
+		if err := v.MergeInConfig(); err != nil {
+			if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+				loaded.skipped = append(loaded.skipped, name)
+
+				continue
+			}
+
+			return nil, fmt.Errorf("could not merge config for config profile %s: %w", profile, err)
+		}
+
+		if err := loaded.addFile(v.ConfigFileUsed()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, layer := range options.FileLayers {
+		if err := f.mergeLayer(loaded, layer, options.FileName, append(profiles, appEnv), configProfiles); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := envReplacer.configure(v); err != nil {
+		return nil, err
+	}
+
+	secrets, err := resolveSecretFiles(v, envReplacer)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded.secrets = secrets
+
+	return loaded, nil
+}
+
+// mergeLayer merges the config files found in a file layer directory: the config file, the profiles and APP_ENV ones,
+// and the APP_CONFIG_PROFILES ones.
+func (f *DefaultConfigFactory) mergeLayer(loaded *loadedFiles, layer string, fileName string, suffixes []string, configProfiles []string) error {
+	names := []string{fileName}
+	for _, suffix := range append(suffixes, configProfiles...) {
+		if suffix != "" {
+			names = append(names, fmt.Sprintf("%s.%s", fileName, suffix))
+		}
+	}
+
+	for _, name := range names {
+		file := findConfigFile(layer, name)
+		if file == "" {
+			continue
+		}
+
+		loaded.viper.SetConfigFile(file)
+		if err := loaded.viper.MergeInConfig(); err != nil {
+			return fmt.Errorf("could not merge config file %s: %w", file, err)
+		}
+
+		if err := loaded.addFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addFile adds a merged config file, as the source of its top level keys.
+func (l *loadedFiles) addFile(file string) error {
+	fileViper := viper.New()
+	fileViper.SetConfigFile(file)
+
+	if err := fileViper.ReadInConfig(); err != nil {
+		return fmt.Errorf("could not read config file %s: %w", file, err)
+	}
+
+	for key := range fileViper.AllSettings() {
+		l.sources[key] = file
+	}
+
+	l.files = append(l.files, file)
+
+	return nil
+}
+
+// findConfigFile returns the absolute path of a config file in a directory, with any of the supported extensions, or
+// an empty string if not found.
+func findConfigFile(dir string, name string) string {
+	for _, ext := range viper.SupportedExts {
+		file, err := filepath.Abs(filepath.Join(dir, fmt.Sprintf("%s.%s", name, ext)))
+		if err != nil {
+			return ""
+		}
+
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file
+		}
+	}
+
+	return ""
+}
+
+// loadSource resolves the remote config source (from the options, or from the modules.config.source config keys
+// otherwise), and applies its values to the config.
+func (f *DefaultConfigFactory) loadSource(cfg *Config, options Options) error {
+	source := options.Source
+	if source == nil {
+		configuredSource, err := newConfigSource(cfg.Viper)
+		if err != nil {
+			return err
 		}
+
+		source = configuredSource
+	}
+
+	if source == nil {
+		return nil
+	}
+
+	cfg.source = source
+
+	cfg.sourcePrecedence = options.SourcePrecedence
+	if cfg.sourcePrecedence == "" {
+		cfg.sourcePrecedence = cfg.GetString("modules.config.source.precedence")
+	}
+
+	cfg.sourceTimeout = DefaultSourceTimeout
+	if timeout := cfg.GetFloat64("modules.config.source.timeout"); timeout > 0 {
+		cfg.sourceTimeout = time.Duration(timeout * float64(time.Second))
 	}
 
-	return &Config{v}, nil
+	_, err := cfg.RefreshSource(context.Background())
+	if err != nil && cfg.GetBool("modules.config.source.fallback") {
+		// the local config is used until a successful refresh
+		cfg.reportError(fmt.Errorf("config source unavailable, falling back to the local config: %w", err))
+
+		return nil
+	}
+
+	return err
+}
+
+// profiles returns the config profiles to merge in order, from the APP_PROFILES env var (comma separated) if set, or
+// from the modules.config.profiles config key otherwise.
+func (f *DefaultConfigFactory) profiles(v *viper.Viper) []string {
+	var profiles []string
+	if envProfiles := os.Getenv("APP_PROFILES"); envProfiles != "" {
+		profiles = strings.Split(envProfiles, ",")
+	} else {
+		profiles = v.GetStringSlice("modules.config.profiles")
+	}
+
+	var cleanedProfiles []string
+	for _, profile := range profiles {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			cleanedProfiles = append(cleanedProfiles, profile)
+		}
+	}
+
+	return cleanedProfiles
+}
+
+// configProfiles returns the config profiles suffixes to merge in order on top of the APP_ENV config file, from the
+// APP_CONFIG_PROFILES env var (comma separated).
+func (f *DefaultConfigFactory) configProfiles() []string {
+	var profiles []string
+	for _, profile := range strings.Split(os.Getenv("APP_CONFIG_PROFILES"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
 }
 
 func (f *DefaultConfigFactory) setDefaults(v *viper.Viper) {
 	v.SetDefault("app.name", DefaultAppName)
 	v.SetDefault("app.version", DefaultAppVersion)
 	v.SetDefault("app.debug", false)
+
+	if BuildVersion != "" {
+		v.SetDefault("app.version", BuildVersion)
+	}
+
+	commit, date := BuildCommit, BuildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && commit == "":
+				commit = setting.Value
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			}
+		}
+	}
+
+	// only set when known, to not add empty fields to the configuration
+	if commit != "" {
+		v.SetDefault("app.build.commit", commit)
+	}
+
+	if date != "" {
+		v.SetDefault("app.build.date", date)
+	}
+}
+
+// expandEnvPlaceholders expands the env vars placeholders of the config values: the config files values are merged back
+// (instead of being set), to not hide their sibling keys when their parent map is read.
+func expandEnvPlaceholders(v *viper.Viper) error {
+	expanded := map[string]interface{}{}
+	for _, key := range v.AllKeys() {
+		val := v.GetString(key)
+		if strings.Contains(val, "${") {
+			if v.InConfig(key) {
+				setNestedValue(expanded, strings.Split(key, "."), os.ExpandEnv(val))
+			} else {
+				v.Set(key, os.ExpandEnv(val))
+			}
+		}
+	}
+
+	if len(expanded) > 0 {
+		if err := v.MergeConfigMap(expanded); err != nil {
+			return fmt.Errorf("could not expand config env vars placeholders: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// setNestedValue sets a value in nested settings, from its dotted key parts.
+func setNestedValue(settings map[string]interface{}, keyParts []string, value interface{}) {
+	for _, part := range keyParts[:len(keyParts)-1] {
+		nested, ok := settings[part].(map[string]interface{})
+		if !ok {
+			nested = map[string]interface{}{}
+			settings[part] = nested
+		}
+
+		settings = nested
+	}
+
+	settings[keyParts[len(keyParts)-1]] = value
 }
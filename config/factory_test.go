@@ -1,6 +1,7 @@
 package config_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/ankorstore/yokai/config"
@@ -58,3 +59,111 @@ func TestCreateFailureOnInvalidConfigContent(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "could not merge config for env test")
 }
+
+func TestCreateWithProfiles(t *testing.T) {
+	factory := config.NewDefaultConfigFactory()
+
+	cfg, err := factory.Create(config.WithFilePaths("./testdata/config/profiles"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "cloud-eu-app", cfg.AppName())
+	assert.Equal(t, config.AppEnvDev, cfg.AppEnv())
+	assert.Equal(t, "0.1.0", cfg.AppVersion())
+
+	// later profiles override earlier ones key by key
+	assert.Equal(t, "base", cfg.GetString("config.values.base_value"))
+	assert.Equal(t, "cloud", cfg.GetString("config.values.cloud_value"))
+	assert.Equal(t, "cloud-eu", cfg.GetString("config.values.region_value"))
+	assert.Equal(t, "cloud-eu", cfg.GetString("config.values.env_value"))
+}
+
+func TestCreateWithProfilesAndEnv(t *testing.T) {
+	factory := config.NewDefaultConfigFactory()
+
+	t.Setenv("APP_ENV", "test")
+
+	cfg, err := factory.Create(config.WithFilePaths("./testdata/config/profiles"))
+	assert.NoError(t, err)
+
+	// env config merged last
+	assert.Equal(t, "cloud-eu-app", cfg.AppName())
+	assert.Equal(t, config.AppEnvTest, cfg.AppEnv())
+	assert.Equal(t, "cloud-eu", cfg.GetString("config.values.region_value"))
+	assert.Equal(t, "test", cfg.GetString("config.values.env_value"))
+}
+
+func TestCreateWithProfilesFromEnvVar(t *testing.T) {
+	factory := config.NewDefaultConfigFactory()
+
+	t.Setenv("APP_PROFILES", "cloud-eu, cloud")
+
+	cfg, err := factory.Create(config.WithFilePaths("./testdata/config/profiles"))
+	assert.NoError(t, err)
+
+	// env var profiles order takes precedence over the config ones
+	assert.Equal(t, "cloud-eu-app", cfg.AppName())
+	assert.Equal(t, "base", cfg.GetString("config.values.base_value"))
+	assert.Equal(t, "cloud", cfg.GetString("config.values.cloud_value"))
+	assert.Equal(t, "cloud", cfg.GetString("config.values.region_value"))
+	assert.Equal(t, "cloud", cfg.GetString("config.values.env_value"))
+}
+
+func TestCreateWithConfigProfiles(t *testing.T) {
+	factory := config.NewDefaultConfigFactory()
+
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PROFILES", "missing, debug")
+
+	cfg, err := factory.Create(config.WithFilePaths("./testdata/config/profiles"))
+	assert.NoError(t, err)
+
+	// config profiles merged on top of the env config, the missing ones being skipped
+	assert.Equal(t, "cloud-eu-app", cfg.AppName())
+	assert.Equal(t, config.AppEnvTest, cfg.AppEnv())
+	assert.Equal(t, "cloud-eu", cfg.GetString("config.values.region_value"))
+	assert.Equal(t, "debug", cfg.GetString("config.values.env_value"))
+	assert.Equal(t, "debug", cfg.GetString("config.values.debug_value"))
+
+	files := cfg.Files()
+	assert.Len(t, files, 5)
+	assert.Equal(t, "config.yaml", filepath.Base(files[0]))
+	assert.Equal(t, "config.cloud.yaml", filepath.Base(files[1]))
+	assert.Equal(t, "config.cloud-eu.yaml", filepath.Base(files[2]))
+	assert.Equal(t, "config.test.yaml", filepath.Base(files[3]))
+	assert.Equal(t, "config.debug.yaml", filepath.Base(files[4]))
+
+	assert.Equal(t, []string{"config.missing"}, cfg.SkippedFiles())
+}
+
+func TestCreateFailureOnInvalidConfigProfileFileContent(t *testing.T) {
+	factory := config.NewDefaultConfigFactory()
+
+	t.Setenv("APP_CONFIG_PROFILES", "invalid")
+
+	_, err := factory.Create(config.WithFilePaths("./testdata/config/profiles"))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not merge config for config profile invalid")
+}
+
+func TestCreateFailureOnInvalidConfigProfile(t *testing.T) {
+	factory := config.NewDefaultConfigFactory()
+
+	t.Setenv("APP_PROFILES", "missing")
+
+	_, err := factory.Create(config.WithFilePaths("./testdata/config/profiles"))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not load config file for profile missing")
+}
+
+func TestCreateFailureOnInvalidConfigProfileContent(t *testing.T) {
+	factory := config.NewDefaultConfigFactory()
+
+	t.Setenv("APP_PROFILES", "invalid")
+
+	_, err := factory.Create(config.WithFilePaths("./testdata/config/profiles"))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not merge config for profile invalid")
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// flagsConfigKey is the config key under which the feature flags are configured.
+const flagsConfigKey = "flags"
+
+type ctxFlagKey struct{}
+
+// WithFlagKey returns a copy of the provided context, carrying the key (for example a user id, or a request id) used
+// to bucket the feature flags percentage rollouts.
+func WithFlagKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, ctxFlagKey{}, key)
+}
+
+// CtxFlagKey returns the feature flags rollout key carried by a provided context with [WithFlagKey], or an empty string.
+func CtxFlagKey(ctx context.Context) string {
+	if key, ok := ctx.Value(ctxFlagKey{}).(string); ok {
+		return key
+	}
+
+	return ""
+}
+
+const (
+	FlagSourceConfig  = "config"  // feature flag value resolved from the config
+	FlagSourceDefault = "default" // feature flag value defaulted, when not found in the provider nor in the config
+)
+
+// FlagValue is the effective value of a feature flag.
+type FlagValue struct {
+	Enabled bool    // if the flag is enabled
+	Rollout float64 // percentage rollout of the flag, from 0 to 100 (100 for a full rollout)
+	Variant string  // variant served when the flag is enabled, if any
+	Source  string  // source of the value: FlagSourceConfig, FlagSourceDefault, or the FlagProvider name
+}
+
+// FlagProvider is the interface for feature flags providers, to resolve the feature flags from another source than
+// the config (for example a remote flags service). The flags not found by the provider are resolved from the config.
+type FlagProvider interface {
+	Name() string
+	Lookup(name string) (FlagValue, bool)
+}
+
+// Flag is a feature flag, resolved from the [FlagProvider] if any, or from the flags.<name> config keys.
+type Flag struct {
+	name           string
+	config         *Config
+	defaultEnabled bool
+	defaultVariant string
+}
+
+// Flag returns the [Flag] of a given name.
+//
+// The flag value is resolved on first read and cached, until the config files are reloaded or the remote config
+// source values are refreshed: the config changes are reflected without restart.
+func (c *Config) Flag(name string) *Flag {
+	return &Flag{
+		name:   name,
+		config: c,
+	}
+}
+
+// FlagNames returns the sorted names of the feature flags configured under the flags config key.
+func (c *Config) FlagNames() []string {
+	var names []string
+	for name := range c.GetStringMap(flagsConfigKey) {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// OnFlagEvaluation registers a function to call with the name and the result of each feature flag evaluation.
+func (c *Config) OnFlagEvaluation(fn func(name string, enabled bool)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.flagListeners = append(c.flagListeners, fn)
+}
+
+// OnFlagResolution registers a function to call with the name and the effective value of each feature flag, once when
+// it is first read (and again on the first read following a config reload or a remote config source refresh).
+func (c *Config) OnFlagResolution(fn func(name string, value FlagValue)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.flagResolutionListeners = append(c.flagResolutionListeners, fn)
+}
+
+// FlagProvider returns the [FlagProvider] the feature flags are resolved from, or nil if none.
+func (c *Config) FlagProvider() FlagProvider {
+	return c.flagProvider
+}
+
+// Name returns the name of the [Flag].
+func (f *Flag) Name() string {
+	return f.name
+}
+
+// WithDefault returns a copy of the [Flag], enabled or not by default when not found in the provider nor in the
+// config (disabled otherwise).
+func (f *Flag) WithDefault(enabled bool) *Flag {
+	flag := *f
+	flag.defaultEnabled = enabled
+
+	return &flag
+}
+
+// WithDefaultVariant returns a copy of the [Flag], serving a default variant when it is disabled, or when it has no
+// configured variant.
+func (f *Flag) WithDefaultVariant(variant string) *Flag {
+	flag := *f
+	flag.defaultVariant = variant
+
+	return &flag
+}
+
+// Value returns the effective [FlagValue] of the [Flag], resolved from the [FlagProvider] if any, then from the
+// config, and defaulted otherwise.
+func (f *Flag) Value() FlagValue {
+	c := f.config
+
+	c.mutex.Lock()
+	value, ok := c.flagValues[f.name]
+	c.mutex.Unlock()
+
+	if !ok {
+		value = f.resolve()
+
+		c.mutex.Lock()
+		if cached, found := c.flagValues[f.name]; found {
+			c.mutex.Unlock()
+
+			return f.withDefaults(cached)
+		}
+
+		if c.flagValues == nil {
+			c.flagValues = map[string]FlagValue{}
+		}
+
+		c.flagValues[f.name] = value
+		listeners := c.flagResolutionListeners
+		c.mutex.Unlock()
+
+		value = f.withDefaults(value)
+
+		for _, listener := range listeners {
+			listener(f.name, value)
+		}
+
+		return value
+	}
+
+	return f.withDefaults(value)
+}
+
+// Enabled returns true if the [Flag] is enabled (flags.<name>.enabled, false by default), and if the rollout key of
+// the provided context (see [WithFlagKey]) falls in its optional percentage rollout (flags.<name>.rollout, from 0 to
+// 100). Without rollout key, the flag is only enabled for a full rollout.
+//
+// The flags.<name>.envs.<env>.enabled and flags.<name>.envs.<env>.rollout config keys override the flag for the
+// current APP_ENV.
+func (f *Flag) Enabled(ctx context.Context) bool {
+	enabled := f.evaluate(ctx, f.Value())
+
+	f.config.mutex.Lock()
+	listeners := f.config.flagListeners
+	f.config.mutex.Unlock()
+
+	for _, listener := range listeners {
+		listener(f.name, enabled)
+	}
+
+	return enabled
+}
+
+// Variant returns the variant of the [Flag] (flags.<name>.variant) if it is enabled for the provided context (see
+// [Flag.Enabled]), or its default variant otherwise (see [Flag.WithDefaultVariant]).
+func (f *Flag) Variant(ctx context.Context) string {
+	if f.Enabled(ctx) {
+		if variant := f.Value().Variant; variant != "" {
+			return variant
+		}
+	}
+
+	return f.defaultVariant
+}
+
+func (f *Flag) evaluate(ctx context.Context, value FlagValue) bool {
+	if !value.Enabled {
+		return false
+	}
+
+	if value.Rollout >= 100 {
+		return true
+	}
+
+	if value.Rollout <= 0 {
+		return false
+	}
+
+	key := CtxFlagKey(ctx)
+	if key == "" {
+		return false
+	}
+
+	return float64(FlagBucket(f.name, key)) < value.Rollout*100
+}
+
+// resolve returns the value of the [Flag] from the [FlagProvider] if any, or from the config, with an empty source if
+// not found.
+func (f *Flag) resolve() FlagValue {
+	if provider := f.config.flagProvider; provider != nil {
+		if value, ok := provider.Lookup(f.name); ok {
+			if value.Source == "" {
+				value.Source = provider.Name()
+			}
+
+			return value
+		}
+	}
+
+	enabledKey := f.key("enabled")
+	if !f.config.IsSet(enabledKey) {
+		return FlagValue{}
+	}
+
+	value := FlagValue{
+		Enabled: f.config.GetBool(enabledKey),
+		Rollout: 100,
+		Variant: f.config.GetString(f.key("variant")),
+		Source:  FlagSourceConfig,
+	}
+
+	if rolloutKey := f.key("rollout"); f.config.IsSet(rolloutKey) {
+		value.Rollout = f.config.GetFloat64(rolloutKey)
+	}
+
+	return value
+}
+
+// withDefaults returns the provided value, or the default value of the [Flag] if it was not found.
+func (f *Flag) withDefaults(value FlagValue) FlagValue {
+	if value.Source != "" {
+		return value
+	}
+
+	return FlagValue{
+		Enabled: f.defaultEnabled,
+		Rollout: 100,
+		Variant: f.defaultVariant,
+		Source:  FlagSourceDefault,
+	}
+}
+
+// key returns the config key of a flag setting, overridden for the current APP_ENV if set.
+func (f *Flag) key(setting string) string {
+	envKey := fmt.Sprintf("%s.%s.envs.%s.%s", flagsConfigKey, f.name, f.config.AppEnv(), setting)
+	if f.config.IsSet(envKey) {
+		return envKey
+	}
+
+	return fmt.Sprintf("%s.%s.%s", flagsConfigKey, f.name, setting)
+}
+
+// FlagBucket returns the deterministic rollout bucket (from 0 to 9999) of a rollout key for a given flag name: a flag
+// with a rollout of N percents is enabled for the keys of the buckets below N*100.
+func FlagBucket(name string, key string) uint32 {
+	hash := fnv.New32a()
+
+	//nolint:errcheck
+	hash.Write([]byte(name + ":" + key))
+
+	return hash.Sum32() % 10000
+}
@@ -0,0 +1,337 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestFlagsConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/flags"))
+	assert.NoError(t, err)
+
+	return cfg
+}
+
+func TestFlagEnabled(t *testing.T) {
+	cfg := createTestFlagsConfig(t)
+
+	tests := []struct {
+		flag     string
+		key      string
+		expected bool
+	}{
+		{"enabled", "", true},
+		{"disabled", "user-1", false},
+		{"missing", "user-1", false},
+		{"zero", "user-1", false},
+		{"full", "", true},
+		{"disabled-rollout", "user-1", false},
+		{"rollout", "", false},
+		{"rollout", "user-1", false},
+		{"rollout", "user-2", true},
+		{"rollout", "user-3", true},
+		{"rollout", "user-4", false},
+		{"env", "", true},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.key != "" {
+			ctx = config.WithFlagKey(ctx, tt.key)
+		}
+
+		assert.Equal(t, tt.expected, cfg.Flag(tt.flag).Enabled(ctx), "flag %s with key %q", tt.flag, tt.key)
+	}
+}
+
+func TestFlagBucket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		key      string
+		expected uint32
+	}{
+		{"rollout", "user-1", 6617},
+		{"rollout", "user-2", 3760},
+		{"rollout", "user-3", 1379},
+		{"rollout", "user-4", 9474},
+		{"rollout", "request-1", 2279},
+	}
+
+	for _, tt := range tests {
+		// the buckets are deterministic, across evaluations and processes
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, tt.expected, config.FlagBucket(tt.name, tt.key))
+		}
+	}
+
+	// the buckets of a key depend on the flag name
+	assert.NotEqual(t, config.FlagBucket("rollout", "user-1"), config.FlagBucket("other", "user-1"))
+}
+
+func TestFlagRolloutDistribution(t *testing.T) {
+	t.Parallel()
+
+	enabled := 0
+	for i := 0; i < 10000; i++ {
+		if config.FlagBucket("rollout", fmt.Sprintf("user-%d", i)) < 2500 {
+			enabled++
+		}
+	}
+
+	// roughly 25% of the keys fall in a 25% rollout
+	assert.InDelta(t, 2500, enabled, 300)
+}
+
+func TestFlagEnabledWithEnvOverride(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+
+	cfg := createTestFlagsConfig(t)
+
+	assert.False(t, cfg.Flag("env").Enabled(context.Background()))
+	assert.True(t, cfg.Flag("enabled").Enabled(context.Background()))
+}
+
+func TestFlagEnabledAfterReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+
+	err := os.WriteFile(file, []byte("flags:\n  reload:\n    enabled: false\n"), 0o600)
+	assert.NoError(t, err)
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths(dir))
+	assert.NoError(t, err)
+
+	flag := cfg.Flag("reload")
+	assert.Equal(t, "reload", flag.Name())
+	assert.False(t, flag.Enabled(context.Background()))
+
+	err = os.WriteFile(file, []byte("flags:\n  reload:\n    enabled: true\n"), 0o600)
+	assert.NoError(t, err)
+
+	_, err = cfg.Reload()
+	assert.NoError(t, err)
+
+	assert.True(t, flag.Enabled(context.Background()))
+}
+
+func TestFlagEvaluationListeners(t *testing.T) {
+	cfg := createTestFlagsConfig(t)
+
+	evaluations := map[string][]bool{}
+	cfg.OnFlagEvaluation(func(name string, enabled bool) {
+		evaluations[name] = append(evaluations[name], enabled)
+	})
+
+	cfg.Flag("enabled").Enabled(context.Background())
+	cfg.Flag("disabled").Enabled(context.Background())
+	cfg.Flag("enabled").Enabled(context.Background())
+
+	assert.Equal(
+		t,
+		map[string][]bool{
+			"enabled":  {true, true},
+			"disabled": {false},
+		},
+		evaluations,
+	)
+}
+
+func TestCtxFlagKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", config.CtxFlagKey(context.Background()))
+	assert.Equal(t, "user-1", config.CtxFlagKey(config.WithFlagKey(context.Background(), "user-1")))
+}
+
+type testFlagProvider struct {
+	values  map[string]config.FlagValue
+	lookups int
+}
+
+func (p *testFlagProvider) Name() string {
+	return "test-provider"
+}
+
+func (p *testFlagProvider) Lookup(name string) (config.FlagValue, bool) {
+	p.lookups++
+
+	value, ok := p.values[name]
+
+	return value, ok
+}
+
+func TestFlagDefault(t *testing.T) {
+	cfg := createTestFlagsConfig(t)
+
+	ctx := context.Background()
+
+	// missing flags fall back to their default
+	assert.False(t, cfg.Flag("missing").Enabled(ctx))
+	assert.True(t, cfg.Flag("missing").WithDefault(true).Enabled(ctx))
+	assert.Equal(
+		t,
+		config.FlagValue{Enabled: true, Rollout: 100, Variant: "red", Source: config.FlagSourceDefault},
+		cfg.Flag("missing").WithDefault(true).WithDefaultVariant("red").Value(),
+	)
+
+	// configured flags ignore their default
+	assert.False(t, cfg.Flag("disabled").WithDefault(true).Enabled(ctx))
+	assert.True(t, cfg.Flag("enabled").WithDefault(false).Enabled(ctx))
+	assert.Equal(
+		t,
+		config.FlagValue{Enabled: true, Rollout: 50, Source: config.FlagSourceConfig},
+		cfg.Flag("rollout").WithDefault(false).Value(),
+	)
+}
+
+func TestFlagOverrideWithEnvVar(t *testing.T) {
+	t.Setenv("FLAGS_DISABLED_ENABLED", "true")
+	t.Setenv("FLAGS_BETA_ENABLED", "true")
+
+	cfg := createTestFlagsConfig(t)
+
+	assert.True(t, cfg.Flag("disabled").Enabled(context.Background()))
+	assert.True(t, cfg.Flag("beta").Enabled(context.Background()))
+	assert.Equal(t, config.FlagSourceConfig, cfg.Flag("beta").Value().Source)
+}
+
+func TestFlagVariant(t *testing.T) {
+	cfg := createTestFlagsConfig(t)
+
+	tests := []struct {
+		flag     string
+		key      string
+		expected string
+	}{
+		{"variant", "", "blue"},
+		{"enabled", "", "default"},
+		{"disabled", "", "default"},
+		{"missing", "", "default"},
+		{"variant-rollout", "", "default"},
+		{"variant-rollout", "user-2", "green"},
+		{"variant-rollout", "user-3", "default"},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.key != "" {
+			ctx = config.WithFlagKey(ctx, tt.key)
+		}
+
+		assert.Equal(
+			t,
+			tt.expected,
+			cfg.Flag(tt.flag).WithDefaultVariant("default").Variant(ctx),
+			"flag %s with key %q",
+			tt.flag,
+			tt.key,
+		)
+	}
+
+	assert.Equal(t, "", cfg.Flag("missing").Variant(context.Background()))
+}
+
+func TestFlagProvider(t *testing.T) {
+	provider := &testFlagProvider{
+		values: map[string]config.FlagValue{
+			"disabled": {Enabled: true, Rollout: 100, Variant: "remote"},
+			"remote":   {Enabled: true, Rollout: 100, Source: "remote-service"},
+		},
+	}
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config/flags"),
+		config.WithFlagProvider(provider),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, provider, cfg.FlagProvider())
+
+	ctx := context.Background()
+
+	// resolved from the provider first
+	assert.True(t, cfg.Flag("disabled").Enabled(ctx))
+	assert.Equal(t, "remote", cfg.Flag("disabled").Variant(ctx))
+	assert.Equal(t, "test-provider", cfg.Flag("disabled").Value().Source)
+	assert.Equal(t, "remote-service", cfg.Flag("remote").Value().Source)
+
+	// then from the config
+	assert.True(t, cfg.Flag("enabled").Enabled(ctx))
+	assert.Equal(t, config.FlagSourceConfig, cfg.Flag("enabled").Value().Source)
+
+	// the resolutions are cached
+	assert.Equal(t, 3, provider.lookups)
+}
+
+func TestFlagResolutionListeners(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+
+	err := os.WriteFile(file, []byte("flags:\n  reload:\n    enabled: false\n"), 0o600)
+	assert.NoError(t, err)
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths(dir))
+	assert.NoError(t, err)
+
+	var resolutions []config.FlagValue
+	cfg.OnFlagResolution(func(name string, value config.FlagValue) {
+		assert.Equal(t, "reload", name)
+
+		resolutions = append(resolutions, value)
+	})
+
+	// resolved once
+	for i := 0; i < 3; i++ {
+		assert.False(t, cfg.Flag("reload").Enabled(context.Background()))
+	}
+
+	assert.Equal(t, []config.FlagValue{{Enabled: false, Rollout: 100, Source: config.FlagSourceConfig}}, resolutions)
+
+	// resolved again after reload
+	err = os.WriteFile(file, []byte("flags:\n  reload:\n    enabled: true\n    rollout: 20\n"), 0o600)
+	assert.NoError(t, err)
+
+	_, err = cfg.Reload()
+	assert.NoError(t, err)
+
+	cfg.Flag("reload").Enabled(context.Background())
+	cfg.Flag("reload").Enabled(context.Background())
+
+	assert.Equal(
+		t,
+		[]config.FlagValue{
+			{Enabled: false, Rollout: 100, Source: config.FlagSourceConfig},
+			{Enabled: true, Rollout: 20, Source: config.FlagSourceConfig},
+		},
+		resolutions,
+	)
+}
+
+func TestFlagNames(t *testing.T) {
+	cfg := createTestFlagsConfig(t)
+
+	assert.Equal(
+		t,
+		[]string{
+			"disabled",
+			"disabled-rollout",
+			"enabled",
+			"env",
+			"full",
+			"rollout",
+			"variant",
+			"variant-rollout",
+			"zero",
+		},
+		cfg.FlagNames(),
+	)
+}
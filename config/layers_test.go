@@ -0,0 +1,115 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestLayersConfig(t *testing.T, layers ...string) (*config.Config, error) {
+	t.Helper()
+
+	return config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config/layers/base"),
+		config.WithFileLayers(layers...),
+	)
+}
+
+func testLayerFile(t *testing.T, layer string, name string) string {
+	t.Helper()
+
+	file, err := filepath.Abs(filepath.Join("testdata", "config", "layers", layer, name))
+	assert.NoError(t, err)
+
+	return file
+}
+
+func TestFileLayersMerge(t *testing.T) {
+	cfg, err := createTestLayersConfig(t, "./testdata/config/layers/overrides", "./testdata/config/layers/local")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "overrides-app", cfg.AppName())
+	assert.Equal(t, "1.0.0", cfg.AppVersion())
+
+	assert.Equal(t, "local-db", cfg.GetString("database.host"))
+	assert.Equal(t, 5432, cfg.GetInt("database.port"))
+	assert.Equal(t, "10s", cfg.GetString("database.options.timeout"))
+	assert.Equal(t, 20, cfg.GetInt("database.options.pool"))
+
+	assert.Equal(t, []string{"overrides-1"}, cfg.GetStringSlice("hosts"))
+
+	assert.True(t, cfg.GetBool("features.search"))
+	assert.True(t, cfg.GetBool("features.export"))
+
+	assert.Equal(t, "60s", cfg.GetString("cache.ttl"))
+
+	assert.Equal(
+		t,
+		[]string{
+			testLayerFile(t, "base", "config.yaml"),
+			testLayerFile(t, "overrides", "config.yaml"),
+			testLayerFile(t, "local", "config.yaml"),
+		},
+		cfg.Files(),
+	)
+}
+
+func TestFileLayersMergeWithEnvAndEnvVarsOverrides(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("DATABASE_OPTIONS_POOL", "50")
+	t.Setenv("CACHE_TTL", "5s")
+
+	cfg, err := createTestLayersConfig(t, "./testdata/config/layers/overrides", "./testdata/config/layers/local")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "test", cfg.AppEnv())
+	assert.Equal(t, "overrides-app", cfg.AppName())
+	assert.Equal(t, 50, cfg.GetInt("database.options.pool"))
+	assert.Equal(t, "10s", cfg.GetString("database.options.timeout"))
+	assert.Equal(t, "5s", cfg.GetString("cache.ttl"))
+
+	t.Setenv("CACHE_TTL", "")
+
+	cfg, err = createTestLayersConfig(t, "./testdata/config/layers/overrides", "./testdata/config/layers/local")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "30s", cfg.GetString("cache.ttl"))
+}
+
+func TestFileLayersOrder(t *testing.T) {
+	cfg, err := createTestLayersConfig(t, "./testdata/config/layers/local", "./testdata/config/layers/overrides")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "db.example.com", cfg.GetString("database.host"))
+	assert.Equal(t, "10s", cfg.GetString("database.options.timeout"))
+	assert.Equal(t, 20, cfg.GetInt("database.options.pool"))
+}
+
+func TestFileLayersWithMissingLayerFiles(t *testing.T) {
+	cfg, err := createTestLayersConfig(t, "./testdata/config/layers/invalid")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "base-app", cfg.AppName())
+	assert.Len(t, cfg.Files(), 1)
+}
+
+func TestSources(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+
+	cfg, err := createTestLayersConfig(t, "./testdata/config/layers/overrides", "./testdata/config/layers/local")
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		map[string]string{
+			"app":      testLayerFile(t, "overrides", "config.yaml"),
+			"database": testLayerFile(t, "local", "config.yaml"),
+			"hosts":    testLayerFile(t, "overrides", "config.yaml"),
+			"features": testLayerFile(t, "local", "config.yaml"),
+			"cache":    testLayerFile(t, "local", "config.test.yaml"),
+		},
+		cfg.Sources(),
+	)
+}
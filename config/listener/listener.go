@@ -0,0 +1,61 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"syscall"
+)
+
+// Config is the configuration of the tcp listeners created with [Listen], decoded from the servers listener config
+// (like modules.http.server.listener or modules.grpc.server.listener).
+type Config struct {
+	// Backlog is the maximum length of the pending connections queue, capped by the system limit (like the
+	// net.core.somaxconn sysctl on Linux). Zero keeps the system default.
+	Backlog int `mapstructure:"backlog"`
+	// ReusePort enables SO_REUSEPORT, to let several listeners bind the same port (for example for zero-downtime
+	// restarts), the kernel balancing the incoming connections between them.
+	ReusePort bool `mapstructure:"reuse_port"`
+}
+
+// Listen returns a tcp [net.Listener] on a given address, configured with a given [Config].
+//
+// The backlog and SO_REUSEPORT settings are supported on Linux, macOS and FreeBSD only, an error being returned on
+// the other platforms if they are set.
+func Listen(ctx context.Context, address string, config Config) (net.Listener, error) {
+	listenConfig := net.ListenConfig{}
+
+	if config.ReusePort {
+		listenConfig.Control = func(network string, address string, conn syscall.RawConn) error {
+			return controlSocket(conn, setReusePort)
+		}
+	}
+
+	listener, err := listenConfig.Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Backlog > 0 {
+		if err = setBacklog(listener, config.Backlog); err != nil {
+			//nolint:errcheck
+			listener.Close()
+
+			return nil, err
+		}
+	}
+
+	return listener, nil
+}
+
+// controlSocket applies a function on the file descriptor of a raw socket.
+func controlSocket(conn syscall.RawConn, fn func(fd uintptr) error) error {
+	var fnErr error
+
+	if err := conn.Control(func(fd uintptr) {
+		fnErr = fn(fd)
+	}); err != nil {
+		return err
+	}
+
+	return fnErr
+}
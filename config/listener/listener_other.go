@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !freebsd
+
+package listener
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+func setReusePort(fd uintptr) error {
+	return fmt.Errorf("SO_REUSEPORT is not supported on %s", runtime.GOOS)
+}
+
+func setBacklog(listener net.Listener, backlog int) error {
+	return fmt.Errorf("listener backlog is not supported on %s", runtime.GOOS)
+}
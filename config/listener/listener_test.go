@@ -0,0 +1,36 @@
+package listener_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ankorstore/yokai/config/listener"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListen(t *testing.T) {
+	t.Parallel()
+
+	lis, err := listener.Listen(context.Background(), "127.0.0.1:0", listener.Config{})
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	addr, ok := lis.Addr().(*net.TCPAddr)
+	assert.True(t, ok)
+	assert.Equal(t, "127.0.0.1", addr.IP.String())
+	assert.NotZero(t, addr.Port)
+
+	// port already in use
+	_, err = listener.Listen(context.Background(), lis.Addr().String(), listener.Config{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "address already in use")
+}
+
+func TestListenWithInvalidAddress(t *testing.T) {
+	t.Parallel()
+
+	_, err := listener.Listen(context.Background(), "invalid", listener.Config{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing port in address")
+}
@@ -0,0 +1,40 @@
+//go:build linux || darwin || freebsd
+
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func setReusePort(fd uintptr) error {
+	if err := unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1); err != nil {
+		return os.NewSyscallError("setsockopt SO_REUSEPORT", err)
+	}
+
+	return nil
+}
+
+// setBacklog calls listen again on the listening socket, to update its backlog.
+func setBacklog(listener net.Listener, backlog int) error {
+	tcpListener, ok := listener.(*net.TCPListener)
+	if !ok {
+		return fmt.Errorf("cannot set the backlog of a %T listener", listener)
+	}
+
+	conn, err := tcpListener.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	return controlSocket(conn, func(fd uintptr) error {
+		if err := unix.Listen(int(fd), backlog); err != nil {
+			return os.NewSyscallError("listen", err)
+		}
+
+		return nil
+	})
+}
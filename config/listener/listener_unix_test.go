@@ -0,0 +1,87 @@
+//go:build linux || darwin || freebsd
+
+package listener_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ankorstore/yokai/config/listener"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func socketOption(t *testing.T, lis net.Listener, level int, option int) int {
+	t.Helper()
+
+	tcpListener, ok := lis.(*net.TCPListener)
+	assert.True(t, ok)
+
+	conn, err := tcpListener.SyscallConn()
+	assert.NoError(t, err)
+
+	var value int
+	var optErr error
+
+	err = conn.Control(func(fd uintptr) {
+		value, optErr = unix.GetsockoptInt(int(fd), level, option)
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, optErr)
+
+	return value
+}
+
+func TestListenWithoutReusePort(t *testing.T) {
+	t.Parallel()
+
+	lis, err := listener.Listen(context.Background(), "127.0.0.1:0", listener.Config{})
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	assert.Zero(t, socketOption(t, lis, unix.SOL_SOCKET, unix.SO_REUSEPORT))
+}
+
+func TestListenWithReusePort(t *testing.T) {
+	t.Parallel()
+
+	config := listener.Config{ReusePort: true}
+
+	first, err := listener.Listen(context.Background(), "127.0.0.1:0", config)
+	assert.NoError(t, err)
+	defer first.Close()
+
+	second, err := listener.Listen(context.Background(), first.Addr().String(), config)
+	assert.NoError(t, err)
+	defer second.Close()
+
+	assert.Equal(t, first.Addr().String(), second.Addr().String())
+	assert.NotZero(t, socketOption(t, first, unix.SOL_SOCKET, unix.SO_REUSEPORT))
+	assert.NotZero(t, socketOption(t, second, unix.SOL_SOCKET, unix.SO_REUSEPORT))
+}
+
+func TestListenWithBacklog(t *testing.T) {
+	t.Parallel()
+
+	lis, err := listener.Listen(context.Background(), "127.0.0.1:0", listener.Config{Backlog: 16})
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	// still listening after the backlog update
+	assert.NotZero(t, socketOption(t, lis, unix.SOL_SOCKET, unix.SO_ACCEPTCONN))
+
+	accepted := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err == nil {
+			err = conn.Close()
+		}
+		accepted <- err
+	}()
+
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Close())
+	assert.NoError(t, <-accepted)
+}
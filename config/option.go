@@ -2,8 +2,13 @@ package config
 
 // Options are options for the [ConfigFactory] implementations.
 type Options struct {
-	FileName  string
-	FilePaths []string
+	FileName         string
+	FilePaths        []string
+	FileLayers       []string
+	Source           ConfigSource
+	SourcePrecedence string
+	FlagProvider     FlagProvider
+	ErrorHandler     func(err error)
 }
 
 // DefaultConfigOptions are the default options used in the [DefaultConfigFactory].
@@ -33,3 +38,44 @@ func WithFilePaths(p ...string) ConfigOption {
 		o.FilePaths = p
 	}
 }
+
+// WithFileLayers is used to specify a list of directories of config files to merge in order on top of the config
+// files found in the file paths, the later ones overriding the earlier ones key by key (maps being merged, and slices
+// replaced), before the env vars overrides.
+func WithFileLayers(l ...string) ConfigOption {
+	return func(o *Options) {
+		o.FileLayers = l
+	}
+}
+
+// WithSource is used to specify a remote [ConfigSource] to fetch config from, instead of the one configured in the
+// modules.config.source config keys.
+func WithSource(s ConfigSource) ConfigOption {
+	return func(o *Options) {
+		o.Source = s
+	}
+}
+
+// WithSourcePrecedence is used to specify if the local config files values (SourcePrecedenceLocal) or the remote
+// config source values (SourcePrecedenceRemote) win, instead of the modules.config.source.precedence config key.
+func WithSourcePrecedence(p string) ConfigOption {
+	return func(o *Options) {
+		o.SourcePrecedence = p
+	}
+}
+
+// WithFlagProvider is used to specify a [FlagProvider] to resolve the feature flags from, before the config.
+func WithFlagProvider(p FlagProvider) ConfigOption {
+	return func(o *Options) {
+		o.FlagProvider = p
+	}
+}
+
+// WithErrorHandler is used to specify a function to call with the non fatal config errors: the remote config source
+// unavailable at startup with the modules.config.source.fallback config key, and the watched config files reload
+// failures without functions registered with OnReloadError. These errors are ignored otherwise.
+func WithErrorHandler(h func(err error)) ConfigOption {
+	return func(o *Options) {
+		o.ErrorHandler = h
+	}
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	SchemaTypeAny      SchemaType = "any"      // any value
+	SchemaTypeString   SchemaType = "string"   // string (or scalar) value
+	SchemaTypeBool     SchemaType = "bool"     // boolean value
+	SchemaTypeInt      SchemaType = "int"      // integer value
+	SchemaTypeFloat    SchemaType = "float"    // number value
+	SchemaTypeDuration SchemaType = "duration" // duration value, see [Config.GetDuration]
+	SchemaTypeByteSize SchemaType = "bytesize" // size in bytes value, see [Config.GetBytes]
+	SchemaTypeList     SchemaType = "list"     // list value, or comma separated string
+	SchemaTypeMap      SchemaType = "map"      // map value, with any sub keys
+)
+
+// SchemaType is the expected type of a config key value.
+type SchemaType string
+
+// SchemaKey is the expected config key of a [Schema], with its type and optional allowed values.
+//
+// Its path is relative to the schema prefix, and can contain * segments matching one or more key segments (like
+// methods.*.max_recv, to match method names containing dots). The sub keys of a [SchemaTypeMap] key are not validated.
+type SchemaKey struct {
+	Path    string
+	Type    SchemaType
+	Allowed []string
+}
+
+// Schema is the expected config keys under a config prefix (like modules.http.server), to validate the loaded config
+// with [Config.Validate].
+type Schema struct {
+	prefix string
+	keys   []SchemaKey
+}
+
+// NewSchema returns a new [Schema] instance, for a config prefix and its expected keys.
+func NewSchema(prefix string, keys ...SchemaKey) *Schema {
+	normalized := make([]SchemaKey, len(keys))
+	for i, key := range keys {
+		normalized[i] = key
+		normalized[i].Path = strings.ToLower(key.Path)
+	}
+
+	return &Schema{
+		prefix: strings.ToLower(prefix),
+		keys:   normalized,
+	}
+}
+
+// Prefix returns the config prefix of the schema.
+func (s *Schema) Prefix() string {
+	return s.prefix
+}
+
+// Keys returns the expected config keys of the schema.
+func (s *Schema) Keys() []SchemaKey {
+	return s.keys
+}
+
+// Diagnostic is a config schema validation issue, on a config key.
+type Diagnostic struct {
+	Key     string
+	Message string
+}
+
+// String returns the diagnostic description.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Key, d.Message)
+}
+
+// Validate validates the config against schemas, and returns the diagnostics sorted by key (empty if valid):
+//   - the keys under the prefix of a schema but not expected by it are reported as unknown (with the closest expected
+//     key, to help fixing typos)
+//   - the values of the expected keys not matching their type or their allowed values are reported as invalid
+func (c *Config) Validate(schemas ...*Schema) []Diagnostic {
+	diagnostics := map[string]Diagnostic{}
+
+	// type checks of the set expected keys
+	for _, schema := range schemas {
+		for _, key := range schema.keys {
+			if strings.Contains(key.Path, "*") {
+				continue
+			}
+
+			fullKey := joinKey(schema.prefix, key.Path)
+			if c.IsSet(fullKey) {
+				if message := checkSchemaValue(key, c.Get(fullKey)); message != "" {
+					diagnostics[fullKey] = Diagnostic{Key: fullKey, Message: message}
+				}
+			}
+		}
+	}
+
+	// unknown keys, and type checks of the wildcard keys
+	for _, fullKey := range c.AllKeys() {
+		for _, schema := range schemas {
+			if !strings.HasPrefix(fullKey, schema.prefix+".") {
+				continue
+			}
+
+			path := strings.TrimPrefix(fullKey, schema.prefix+".")
+
+			key, exact, ok := schema.match(path)
+			if !ok {
+				if _, ok := diagnostics[fullKey]; !ok && !c.matchesAnySchema(fullKey, schemas) {
+					diagnostics[fullKey] = Diagnostic{Key: fullKey, Message: schema.unknownKeyMessage(path)}
+				}
+
+				continue
+			}
+
+			if exact && strings.Contains(key.Path, "*") {
+				if message := checkSchemaValue(key, c.Get(fullKey)); message != "" {
+					diagnostics[fullKey] = Diagnostic{Key: fullKey, Message: message}
+				}
+			}
+		}
+	}
+
+	result := make([]Diagnostic, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		result = append(result, diagnostic)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result
+}
+
+// matchesAnySchema returns if a config key is expected by any of the schemas, for overlapping prefixes.
+func (c *Config) matchesAnySchema(fullKey string, schemas []*Schema) bool {
+	for _, schema := range schemas {
+		if !strings.HasPrefix(fullKey, schema.prefix+".") {
+			continue
+		}
+
+		if _, _, ok := schema.match(strings.TrimPrefix(fullKey, schema.prefix+".")); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match returns the schema key matching a config key path (relative to the prefix), and if it matches exactly (and not
+// as a sub key of a map key).
+func (s *Schema) match(path string) (SchemaKey, bool, bool) {
+	segments := strings.Split(path, ".")
+
+	for _, key := range s.keys {
+		if matchSegments(strings.Split(key.Path, "."), segments) {
+			return key, true, true
+		}
+	}
+
+	// sub keys of the map keys
+	for _, key := range s.keys {
+		if key.Type != SchemaTypeMap && key.Type != SchemaTypeAny {
+			continue
+		}
+
+		keySegments := strings.Split(key.Path, ".")
+		for i := len(keySegments); i < len(segments); i++ {
+			if matchSegments(keySegments, segments[:i]) {
+				return key, false, true
+			}
+		}
+	}
+
+	return SchemaKey{}, false, false
+}
+
+// unknownKeyMessage returns the message of an unknown key, suggesting the closest expected key if any.
+func (s *Schema) unknownKeyMessage(path string) string {
+	closest := ""
+	closestDistance := len(path)/2 + 1
+
+	for _, key := range s.keys {
+		if distance := levenshtein(path, key.Path); distance < closestDistance {
+			closest = key.Path
+			closestDistance = distance
+		}
+	}
+
+	if closest == "" {
+		return "unknown config key"
+	}
+
+	return fmt.Sprintf("unknown config key, did you mean %s?", joinKey(s.prefix, closest))
+}
+
+// matchSegments returns if key segments match pattern segments, the * ones matching one or more segments.
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	if pattern[0] != "*" {
+		return pattern[0] == segments[0] && matchSegments(pattern[1:], segments[1:])
+	}
+
+	for i := 1; i <= len(segments); i++ {
+		if matchSegments(pattern[1:], segments[i:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSchemaValue returns the message of a value not matching its schema key type or allowed values, or an empty
+// string if valid.
+func checkSchemaValue(key SchemaKey, value any) string {
+	// empty values (like unresolved env var placeholders) fall back to the defaults
+	if str, ok := value.(string); ok && strings.TrimSpace(str) == "" {
+		return ""
+	}
+
+	var err error
+
+	kind := reflect.ValueOf(value).Kind()
+	composite := kind == reflect.Map || kind == reflect.Slice
+
+	switch key.Type {
+	case SchemaTypeString:
+		if composite {
+			err = fmt.Errorf("not a string")
+		}
+	case SchemaTypeBool:
+		_, err = cast.ToBoolE(value)
+	case SchemaTypeInt:
+		_, err = cast.ToInt64E(value)
+	case SchemaTypeFloat:
+		_, err = cast.ToFloat64E(value)
+	case SchemaTypeDuration:
+		_, err = parseDuration(value)
+	case SchemaTypeByteSize:
+		_, err = parseByteSize(value)
+	case SchemaTypeList:
+		if kind != reflect.Slice && kind != reflect.String {
+			err = fmt.Errorf("not a list")
+		}
+	case SchemaTypeMap:
+		if kind != reflect.Map {
+			err = fmt.Errorf("not a map")
+		}
+	}
+
+	if err != nil {
+		return fmt.Sprintf("invalid %s value %v: %v", key.Type, value, err)
+	}
+
+	if len(key.Allowed) > 0 && !composite {
+		for _, allowed := range key.Allowed {
+			if strings.EqualFold(allowed, cast.ToString(value)) {
+				return ""
+			}
+		}
+
+		return fmt.Sprintf("invalid value %v, allowed values: %s", value, strings.Join(key.Allowed, ", "))
+	}
+
+	return ""
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSchema() *config.Schema {
+	return config.NewSchema(
+		"modules.server",
+		config.SchemaKey{Path: "port", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "mode", Type: config.SchemaTypeString, Allowed: []string{"fast", "safe"}},
+		config.SchemaKey{Path: "timeout", Type: config.SchemaTypeDuration},
+		config.SchemaKey{Path: "max_body", Type: config.SchemaTypeByteSize},
+		config.SchemaKey{Path: "debug", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "hosts", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "headers", Type: config.SchemaTypeMap},
+		config.SchemaKey{Path: "methods.*.max_recv", Type: config.SchemaTypeByteSize},
+	)
+}
+
+func createTestSchemaConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/schema"))
+	assert.NoError(t, err)
+
+	return cfg
+}
+
+func TestValidateWithValidConfig(t *testing.T) {
+	cfg := createTestSchemaConfig(t)
+
+	// modules.other is not under a schema prefix
+	assert.Empty(t, cfg.Validate(testSchema()))
+	assert.Empty(t, cfg.Validate())
+}
+
+func TestValidateWithInvalidConfig(t *testing.T) {
+	t.Setenv("APP_PROFILES", "typo")
+
+	cfg := createTestSchemaConfig(t)
+
+	assert.Equal(
+		t,
+		[]config.Diagnostic{
+			{Key: "modules.server.debug", Message: `invalid bool value maybe: strconv.ParseBool: parsing "maybe": invalid syntax`},
+			{Key: "modules.server.hosts", Message: "invalid list value map[nested:true]: not a list"},
+			{Key: "modules.server.hosts.nested", Message: "unknown config key"},
+			{Key: "modules.server.max_body", Message: `invalid bytesize value -1: invalid byte size -1`},
+			{Key: "modules.server.methods./test.service/bidi.limit", Message: "unknown config key"},
+			{Key: "modules.server.methods./test.service/bidi.max_recv", Message: `invalid bytesize value huge: invalid byte size "huge"`},
+			{Key: "modules.server.mode", Message: "invalid value slow, allowed values: fast, safe"},
+			{Key: "modules.server.prot", Message: "unknown config key, did you mean modules.server.port?"},
+			{Key: "modules.server.timeout", Message: `invalid duration value soon: invalid duration "soon"`},
+		},
+		cfg.Validate(testSchema()),
+	)
+}
+
+func TestValidateWithEnvVarOverride(t *testing.T) {
+	t.Setenv("MODULES_SERVER_PORT", "invalid")
+
+	cfg := createTestSchemaConfig(t)
+
+	diagnostics := cfg.Validate(testSchema())
+
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, "modules.server.port", diagnostics[0].Key)
+	assert.Contains(t, diagnostics[0].String(), "modules.server.port: invalid int value invalid")
+}
+
+func TestValidateWithOverlappingSchemas(t *testing.T) {
+	t.Setenv("APP_PROFILES", "typo")
+
+	cfg := createTestSchemaConfig(t)
+
+	diagnostics := cfg.Validate(
+		config.NewSchema("modules", config.SchemaKey{Path: "other.unknown", Type: config.SchemaTypeBool}),
+		config.NewSchema("modules.server", config.SchemaKey{Path: "*", Type: config.SchemaTypeAny}),
+	)
+
+	assert.Empty(t, diagnostics)
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	SecretMask          = "***"     // replacement of the secret values in the config dumps
+	SecretFilePrefix    = "file://" // prefix of the config values read from a file
+	SecretFileEnvSuffix = "_FILE"   // suffix of the env vars naming a file to read a config value from
+
+	secretsConfigKey = "modules.config.secrets"
+)
+
+// MarkSecret marks config keys (and their nested keys) as secrets, to mask their values in the config dumps (see
+// MaskedSettings). The keys of the modules.config.secrets config, and the keys resolved from files, are marked at load.
+func (c *Config) MarkSecret(keys ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.markSecret(keys...)
+}
+
+// IsSecret returns if a config key, or one of its parent keys, is marked as secret.
+func (c *Config) IsSecret(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.isSecret(key)
+}
+
+// MaskedSettings returns all the config values (like AllSettings), with the values of the secret keys replaced by
+// [SecretMask], to be safely dumped (for example on debug endpoints).
+func (c *Config) MaskedSettings() map[string]interface{} {
+	settings := c.AllSettings()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.maskSettings(settings, "")
+}
+
+func (c *Config) markSecret(keys ...string) {
+	if c.secrets == nil {
+		c.secrets = map[string]struct{}{}
+	}
+
+	for _, key := range keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			c.secrets[key] = struct{}{}
+		}
+	}
+}
+
+func (c *Config) isSecret(key string) bool {
+	key = strings.ToLower(key)
+
+	for {
+		if _, ok := c.secrets[key]; ok {
+			return true
+		}
+
+		index := strings.LastIndex(key, ".")
+		if index < 0 {
+			return false
+		}
+
+		key = key[:index]
+	}
+}
+
+func (c *Config) maskSettings(settings map[string]interface{}, prefix string) map[string]interface{} {
+	masked := make(map[string]interface{}, len(settings))
+
+	for name, value := range settings {
+		key := joinKey(prefix, name)
+
+		if c.isSecret(key) {
+			masked[name] = SecretMask
+
+			continue
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			masked[name] = c.maskSettings(nested, key)
+		} else {
+			masked[name] = value
+		}
+	}
+
+	return masked
+}
+
+// resolveSecretFiles replaces the config values of the form file://<path>, and the ones of the keys with a <ENV>_FILE
+// env var, by the contents of the files (trailing newlines trimmed), and returns the resolved keys. The config files
+// values are merged back (instead of being set), to not hide their sibling keys when their parent map is read.
+func resolveSecretFiles(v *viper.Viper, envReplacer *envKeyReplacer) ([]string, error) {
+	var resolved []string
+
+	merged := map[string]interface{}{}
+	for _, key := range v.AllKeys() {
+		env := envReplacer.envName(key)
+		_, envSet := os.LookupEnv(env)
+
+		envFile := env + SecretFileEnvSuffix
+		if path, ok := os.LookupEnv(envFile); ok && path != "" {
+			if envSet {
+				return nil, fmt.Errorf("config %s cannot be set by both %s and %s env vars", key, env, envFile)
+			}
+
+			content, err := readSecretFile(key, path)
+			if err != nil {
+				return nil, err
+			}
+
+			v.Set(key, content)
+			resolved = append(resolved, key)
+
+			continue
+		}
+
+		value, ok := v.Get(key).(string)
+		if !ok || !strings.HasPrefix(value, SecretFilePrefix) {
+			continue
+		}
+
+		content, err := readSecretFile(key, os.ExpandEnv(strings.TrimPrefix(value, SecretFilePrefix)))
+		if err != nil {
+			return nil, err
+		}
+
+		if v.InConfig(key) && !envSet {
+			setNestedValue(merged, strings.Split(key, "."), content)
+		} else {
+			v.Set(key, content)
+		}
+
+		resolved = append(resolved, key)
+	}
+
+	if len(merged) > 0 {
+		if err := v.MergeConfigMap(merged); err != nil {
+			return nil, fmt.Errorf("could not resolve config secret files: %w", err)
+		}
+	}
+
+	return resolved, nil
+}
+
+func readSecretFile(key string, path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read config %s secret file %s: %w", key, path, err)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
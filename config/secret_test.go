@@ -0,0 +1,162 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestSecretConfig(t *testing.T) (*config.Config, error) {
+	t.Helper()
+
+	return config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/secret"))
+}
+
+func writeTestSecretFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestSecretFromFileValue(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSecretFile(t, dir, "password", "s3cr3t\n\n")
+
+	t.Setenv("SECRET_DIR", dir)
+
+	cfg, err := createTestSecretConfig(t)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "s3cr3t", cfg.GetString("database.password"))
+	assert.Equal(t, "localhost", cfg.GetString("database.host"))
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"host":     "localhost",
+			"password": "s3cr3t",
+			"credentials": map[string]interface{}{
+				"user":  "admin",
+				"token": "token",
+			},
+		},
+		cfg.GetStringMap("database"),
+	)
+
+	assert.True(t, cfg.IsSecret("database.password"))
+	assert.False(t, cfg.IsSecret("database.host"))
+}
+
+func TestSecretFromFileValueInEnvVar(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSecretFile(t, dir, "password", "s3cr3t\n")
+	path := writeTestSecretFile(t, dir, "key", "env-key\r\n")
+
+	t.Setenv("SECRET_DIR", dir)
+	t.Setenv("API_KEY", "file://"+path)
+
+	cfg, err := createTestSecretConfig(t)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "env-key", cfg.GetString("api.key"))
+	assert.True(t, cfg.IsSecret("api.key"))
+}
+
+func TestSecretFromEnvVarFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSecretFile(t, dir, "password", "s3cr3t\n")
+	path := writeTestSecretFile(t, dir, "key", "file-key\n")
+
+	t.Setenv("SECRET_DIR", dir)
+	t.Setenv("API_KEY_FILE", path)
+
+	cfg, err := createTestSecretConfig(t)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "file-key", cfg.GetString("api.key"))
+	assert.Equal(t, "https://example.com", cfg.GetString("api.url"))
+	assert.True(t, cfg.IsSecret("api.key"))
+	assert.False(t, cfg.IsSecret("api.url"))
+}
+
+func TestSecretFromEnvVarFileWithEnvVar(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSecretFile(t, dir, "password", "s3cr3t\n")
+	path := writeTestSecretFile(t, dir, "key", "file-key\n")
+
+	t.Setenv("SECRET_DIR", dir)
+	t.Setenv("API_KEY", "env-key")
+	t.Setenv("API_KEY_FILE", path)
+
+	_, err := createTestSecretConfig(t)
+	assert.Error(t, err)
+	assert.Equal(t, "config api.key cannot be set by both API_KEY and API_KEY_FILE env vars", err.Error())
+}
+
+func TestSecretWithMissingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Setenv("SECRET_DIR", dir)
+
+	_, err := createTestSecretConfig(t)
+	assert.Error(t, err)
+	assert.Contains(
+		t,
+		err.Error(),
+		"could not read config database.password secret file "+filepath.Join(dir, "password"),
+	)
+
+	writeTestSecretFile(t, dir, "password", "s3cr3t\n")
+	t.Setenv("API_KEY_FILE", filepath.Join(dir, "missing"))
+
+	_, err = createTestSecretConfig(t)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not read config api.key secret file "+filepath.Join(dir, "missing"))
+}
+
+func TestMaskedSettings(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSecretFile(t, dir, "password", "s3cr3t\n")
+
+	t.Setenv("SECRET_DIR", dir)
+
+	cfg, err := createTestSecretConfig(t)
+	assert.NoError(t, err)
+
+	cfg.MarkSecret("API.Key")
+
+	assert.True(t, cfg.IsSecret("database.credentials"))
+	assert.True(t, cfg.IsSecret("database.credentials.token"))
+	assert.True(t, cfg.IsSecret("api.key"))
+	assert.False(t, cfg.IsSecret("database"))
+
+	settings := cfg.MaskedSettings()
+
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"host":        "localhost",
+			"password":    config.SecretMask,
+			"credentials": config.SecretMask,
+		},
+		settings["database"],
+	)
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"url": "https://example.com",
+			"key": config.SecretMask,
+		},
+		settings["api"],
+	)
+
+	// values still accessible
+	assert.Equal(t, "s3cr3t", cfg.GetString("database.password"))
+	assert.Equal(t, "token", cfg.GetString("database.credentials.token"))
+	assert.Equal(t, "plain-key", cfg.AllSettings()["api"].(map[string]interface{})["key"])
+}
@@ -0,0 +1,405 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	SourceTypeHttp   = "http"   // http remote config source type
+	SourceTypeConsul = "consul" // consul KV remote config source type
+	SourceTypeEtcd   = "etcd3"  // etcd v3 KV remote config source type
+
+	SourcePrecedenceLocal  = "local"  // local config files values win over the remote config source values
+	SourcePrecedenceRemote = "remote" // remote config source values win over the local config files values
+
+	DefaultSourceFormat  = "yaml"          // default remote config format
+	DefaultSourceTimeout = 5 * time.Second // default remote config fetch timeout
+)
+
+// ConfigSource is the interface for remote config sources.
+type ConfigSource interface {
+	Name() string
+	Fetch(ctx context.Context) (map[string]interface{}, error)
+}
+
+// HttpConfigSource is a [ConfigSource] fetching the config from an HTTP endpoint.
+type HttpConfigSource struct {
+	URL     string
+	Format  string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// NewHttpConfigSource returns a new [HttpConfigSource], fetching the config from a given url.
+//
+// The config format is resolved from the response Content-Type header if the Format field is empty, and defaults
+// to YAML.
+func NewHttpConfigSource(url string) *HttpConfigSource {
+	return &HttpConfigSource{
+		URL:     url,
+		Headers: map[string]string{},
+		Client:  http.DefaultClient,
+	}
+}
+
+// Name returns the config source name.
+func (s *HttpConfigSource) Name() string {
+	return fmt.Sprintf("%s %s", SourceTypeHttp, s.URL)
+}
+
+// Fetch fetches and decodes the config from the HTTP endpoint.
+func (s *HttpConfigSource) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	return fetchConfig(ctx, s.Client, s.URL, s.Format, s.Headers)
+}
+
+// ConsulConfigSource is a [ConfigSource] fetching the config from a Consul KV key.
+type ConsulConfigSource struct {
+	Address string
+	Key     string
+	Token   string
+	Format  string
+	Client  *http.Client
+}
+
+// NewConsulConfigSource returns a new [ConsulConfigSource], fetching the config from a given key of the Consul KV store
+// of a given address (ex: http://localhost:8500).
+//
+// The config format defaults to YAML if the Format field is empty.
+func NewConsulConfigSource(address string, key string) *ConsulConfigSource {
+	return &ConsulConfigSource{
+		Address: address,
+		Key:     key,
+		Client:  http.DefaultClient,
+	}
+}
+
+// Name returns the config source name.
+func (s *ConsulConfigSource) Name() string {
+	return fmt.Sprintf("%s %s", SourceTypeConsul, s.Key)
+}
+
+// Fetch fetches and decodes the config from the Consul KV key raw value.
+func (s *ConsulConfigSource) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	headers := map[string]string{}
+	if s.Token != "" {
+		headers["X-Consul-Token"] = s.Token
+	}
+
+	format := s.Format
+	if format == "" {
+		format = DefaultSourceFormat
+	}
+
+	kvUrl := fmt.Sprintf(
+		"%s/v1/kv/%s?raw=true",
+		strings.TrimSuffix(s.Address, "/"),
+		(&url.URL{Path: strings.TrimPrefix(s.Key, "/")}).EscapedPath(),
+	)
+
+	return fetchConfig(ctx, s.Client, kvUrl, format, headers)
+}
+
+// EtcdConfigSource is a [ConfigSource] fetching the config from an etcd v3 KV key, with the etcd gRPC gateway JSON API
+// (no etcd client dependency).
+type EtcdConfigSource struct {
+	Address string
+	Key     string
+	Token   string
+	Format  string
+	Client  *http.Client
+}
+
+// NewEtcdConfigSource returns a new [EtcdConfigSource], fetching the config from a given key of the etcd KV store of a
+// given address (ex: http://localhost:2379).
+//
+// The config format defaults to YAML if the Format field is empty.
+func NewEtcdConfigSource(address string, key string) *EtcdConfigSource {
+	return &EtcdConfigSource{
+		Address: address,
+		Key:     key,
+		Client:  http.DefaultClient,
+	}
+}
+
+// Name returns the config source name.
+func (s *EtcdConfigSource) Name() string {
+	return fmt.Sprintf("%s %s", SourceTypeEtcd, s.Key)
+}
+
+// Fetch fetches and decodes the config from the etcd KV key value.
+func (s *EtcdConfigSource) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if s.Token != "" {
+		headers["Authorization"] = s.Token
+	}
+
+	format := s.Format
+	if format == "" {
+		format = DefaultSourceFormat
+	}
+
+	request, err := json.Marshal(map[string]string{
+		"key": base64.StdEncoding.EncodeToString([]byte(s.Key)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body, _, err := fetchRaw(
+		ctx,
+		s.Client,
+		http.MethodPost,
+		fmt.Sprintf("%s/v3/kv/range", strings.TrimSuffix(s.Address, "/")),
+		bytes.NewReader(request),
+		headers,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Kvs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("could not decode etcd response: %w", err)
+	}
+
+	if len(response.Kvs) == 0 {
+		return nil, fmt.Errorf("key %s not found", s.Key)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(response.Kvs[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode etcd value: %w", err)
+	}
+
+	return decodeConfig(value, format)
+}
+
+func fetchConfig(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	format string,
+	headers map[string]string,
+) (map[string]interface{}, error) {
+	body, contentType, err := fetchRaw(ctx, client, http.MethodGet, url, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" {
+		format = formatFromContentType(contentType)
+	}
+
+	return decodeConfig(body, format)
+}
+
+// fetchRaw performs an HTTP request, and returns the response body and content type.
+func fetchRaw(
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	url string,
+	body io.Reader,
+	headers map[string]string,
+) ([]byte, string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return respBody, resp.Header.Get("Content-Type"), nil
+}
+
+// decodeConfig decodes a config of a given format.
+func decodeConfig(body []byte, format string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigType(format)
+
+	if err := v.ReadConfig(bytes.NewReader(body)); err != nil {
+		return nil, fmt.Errorf("could not decode %s config: %w", format, err)
+	}
+
+	return v.AllSettings(), nil
+}
+
+func formatFromContentType(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return "json"
+	case strings.Contains(contentType, "toml"):
+		return "toml"
+	default:
+		return DefaultSourceFormat
+	}
+}
+
+// newConfigSource returns the [ConfigSource] configured in the modules.config.source config keys, or nil if none.
+func newConfigSource(v *viper.Viper) (ConfigSource, error) {
+	sourceType := v.GetString("modules.config.source.type")
+
+	if sourceType == "" {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	client, err := newSourceClient(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch sourceType {
+	case SourceTypeHttp:
+		source := NewHttpConfigSource(os.ExpandEnv(v.GetString("modules.config.source.url")))
+		source.Client = client
+		source.Format = v.GetString("modules.config.source.format")
+		for name, value := range v.GetStringMapString("modules.config.source.headers") {
+			source.Headers[name] = os.ExpandEnv(value)
+		}
+
+		return source, nil
+	case SourceTypeConsul:
+		source := NewConsulConfigSource(
+			os.ExpandEnv(v.GetString("modules.config.source.address")),
+			v.GetString("modules.config.source.key"),
+		)
+		source.Client = client
+		source.Token = os.ExpandEnv(v.GetString("modules.config.source.token"))
+		source.Format = v.GetString("modules.config.source.format")
+
+		return source, nil
+	case SourceTypeEtcd:
+		source := NewEtcdConfigSource(
+			os.ExpandEnv(v.GetString("modules.config.source.address")),
+			v.GetString("modules.config.source.key"),
+		)
+		source.Client = client
+		source.Token = os.ExpandEnv(v.GetString("modules.config.source.token"))
+		source.Format = v.GetString("modules.config.source.format")
+
+		return source, nil
+	default:
+		return nil, fmt.Errorf("unsupported config source type %s", sourceType)
+	}
+}
+
+// newSourceClient returns the HTTP client of the configured remote config source: with the TLS settings of the
+// modules.config.source.tls config keys if any, or the default client otherwise.
+func newSourceClient(v *viper.Viper) (*http.Client, error) {
+	caFile := os.ExpandEnv(v.GetString("modules.config.source.tls.ca_file"))
+	certFile := os.ExpandEnv(v.GetString("modules.config.source.tls.cert_file"))
+	keyFile := os.ExpandEnv(v.GetString("modules.config.source.tls.key_file"))
+	insecureSkipVerify := v.GetBool("modules.config.source.tls.insecure_skip_verify")
+
+	if caFile == "" && certFile == "" && keyFile == "" && !insecureSkipVerify {
+		return http.DefaultClient, nil
+	}
+
+	//nolint:gosec
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read config source tls ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid config source tls ca file %s", caFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load config source tls client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	//nolint:forcetypeassert
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// applySourceSettings applies the remote config source settings: under the local config values (as defaults) with the
+// local precedence, or over them (merged) with the remote precedence.
+func applySourceSettings(v *viper.Viper, settings map[string]interface{}, precedence string) error {
+	if precedence == SourcePrecedenceRemote {
+		return v.MergeConfigMap(settings)
+	}
+
+	flattened := map[string]interface{}{}
+	flattenSettings("", settings, flattened)
+
+	for key, value := range flattened {
+		v.SetDefault(key, value)
+	}
+
+	return nil
+}
+
+// flattenSettings flattens nested settings into dotted keys.
+func flattenSettings(prefix string, settings map[string]interface{}, flattened map[string]interface{}) {
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenSettings(key, nested, flattened)
+		} else {
+			flattened[key] = value
+		}
+	}
+}
@@ -0,0 +1,484 @@
+package config_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const remoteYamlConfig = `
+app:
+  name: remote-app
+  env: remote
+config:
+  values:
+    string_value: remote
+    int_value: 42
+`
+
+func newTestSourceServer(t *testing.T, contentType string, body *atomic.Value) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		//nolint:forcetypeassert
+		_, err := w.Write([]byte(body.Load().(string)))
+		assert.NoError(t, err)
+	}))
+}
+
+type testConfigSource struct {
+	settings map[string]interface{}
+}
+
+func (s *testConfigSource) Name() string {
+	return "test"
+}
+
+func (s *testConfigSource) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	return s.settings, nil
+}
+
+func TestCreateWithHttpSource(t *testing.T) {
+	var body atomic.Value
+	body.Store(remoteYamlConfig)
+
+	server := newTestSourceServer(t, "application/yaml", &body)
+	defer server.Close()
+
+	t.Setenv("CONFIG_SOURCE_URL", server.URL)
+	t.Setenv("CONFIG_SOURCE_TOKEN", "secret")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.NoError(t, err)
+
+	assert.IsType(t, &config.HttpConfigSource{}, cfg.Source())
+	assert.Equal(t, "http "+server.URL, cfg.Source().Name())
+
+	// local values win
+	assert.Equal(t, "local-app", cfg.AppName())
+	assert.Equal(t, "0.1.0", cfg.AppVersion())
+	assert.Equal(t, "local", cfg.GetString("config.values.string_value"))
+
+	// remote values complete
+	assert.Equal(t, "remote", cfg.AppEnv())
+	assert.Equal(t, 42, cfg.GetInt("config.values.int_value"))
+}
+
+func TestCreateWithHttpSourceAndRemotePrecedence(t *testing.T) {
+	var body atomic.Value
+	body.Store(`{"app": {"name": "remote-app"}, "config": {"values": {"int_value": 42}}}`)
+
+	server := newTestSourceServer(t, "application/json", &body)
+	defer server.Close()
+
+	t.Setenv("CONFIG_SOURCE_URL", server.URL)
+	t.Setenv("CONFIG_SOURCE_TOKEN", "secret")
+	t.Setenv("MODULES_CONFIG_SOURCE_PRECEDENCE", config.SourcePrecedenceRemote)
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "remote-app", cfg.AppName())
+	assert.Equal(t, "0.1.0", cfg.AppVersion())
+	assert.Equal(t, "local", cfg.GetString("config.values.string_value"))
+	assert.Equal(t, 42, cfg.GetInt("config.values.int_value"))
+}
+
+func TestCreateWithConsulSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kv/apps/remote-app/config", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("raw"))
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, err := w.Write([]byte(remoteYamlConfig))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	t.Setenv("MODULES_CONFIG_SOURCE_TYPE", config.SourceTypeConsul)
+	t.Setenv("MODULES_CONFIG_SOURCE_ADDRESS", server.URL)
+	t.Setenv("MODULES_CONFIG_SOURCE_KEY", "apps/remote-app/config")
+	t.Setenv("MODULES_CONFIG_SOURCE_TOKEN", "secret")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.NoError(t, err)
+
+	assert.IsType(t, &config.ConsulConfigSource{}, cfg.Source())
+	assert.Equal(t, "consul apps/remote-app/config", cfg.Source().Name())
+
+	assert.Equal(t, "local-app", cfg.AppName())
+	assert.Equal(t, "remote", cfg.AppEnv())
+	assert.Equal(t, 42, cfg.GetInt("config.values.int_value"))
+}
+
+func TestCreateWithEtcdSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v3/kv/range", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+
+		var request map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		key, err := base64.StdEncoding.DecodeString(request["key"])
+		assert.NoError(t, err)
+
+		if string(key) != "apps/remote-app/config" {
+			_, err = w.Write([]byte(`{"header":{}}`))
+			assert.NoError(t, err)
+
+			return
+		}
+
+		_, err = w.Write([]byte(fmt.Sprintf(
+			`{"header":{},"kvs":[{"key":%q,"value":%q}],"count":"1"}`,
+			request["key"],
+			base64.StdEncoding.EncodeToString([]byte(remoteYamlConfig)),
+		)))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	t.Setenv("MODULES_CONFIG_SOURCE_TYPE", config.SourceTypeEtcd)
+	t.Setenv("MODULES_CONFIG_SOURCE_ADDRESS", server.URL)
+	t.Setenv("MODULES_CONFIG_SOURCE_KEY", "apps/remote-app/config")
+	t.Setenv("MODULES_CONFIG_SOURCE_TOKEN", "secret")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.NoError(t, err)
+
+	assert.IsType(t, &config.EtcdConfigSource{}, cfg.Source())
+	assert.Equal(t, "etcd3 apps/remote-app/config", cfg.Source().Name())
+
+	assert.Equal(t, "local-app", cfg.AppName())
+	assert.Equal(t, "remote", cfg.AppEnv())
+	assert.Equal(t, 42, cfg.GetInt("config.values.int_value"))
+
+	// missing key
+	t.Setenv("MODULES_CONFIG_SOURCE_KEY", "apps/missing/config")
+
+	_, err = config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.Error(t, err)
+	assert.Equal(t, "could not fetch config from source etcd3 apps/missing/config: key apps/missing/config not found", err.Error())
+}
+
+func TestCreateWithTlsSource(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(remoteYamlConfig))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(
+		caFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+		0o600,
+	)
+	assert.NoError(t, err)
+
+	t.Setenv("MODULES_CONFIG_SOURCE_TYPE", config.SourceTypeConsul)
+	t.Setenv("MODULES_CONFIG_SOURCE_ADDRESS", server.URL)
+	t.Setenv("MODULES_CONFIG_SOURCE_KEY", "apps/remote-app/config")
+
+	// untrusted server certificate
+	_, err = config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate")
+
+	// trusted server certificate
+	t.Setenv("MODULES_CONFIG_SOURCE_TLS_CA_FILE", caFile)
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.NoError(t, err)
+	assert.Equal(t, 42, cfg.GetInt("config.values.int_value"))
+
+	// invalid ca file
+	t.Setenv("MODULES_CONFIG_SOURCE_TLS_CA_FILE", "./testdata/config/source/config.yaml")
+
+	_, err = config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.Error(t, err)
+	assert.Equal(t, "invalid config source tls ca file ./testdata/config/source/config.yaml", err.Error())
+}
+
+func TestCreateWithFallbackOnUnavailableSource(t *testing.T) {
+	var body atomic.Value
+	body.Store(remoteYamlConfig)
+
+	var available atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		//nolint:forcetypeassert
+		_, err := w.Write([]byte(body.Load().(string)))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	t.Setenv("CONFIG_SOURCE_URL", server.URL)
+	t.Setenv("MODULES_CONFIG_SOURCE_FALLBACK", "true")
+
+	var reportedErr error
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config/source"),
+		config.WithErrorHandler(func(err error) {
+			reportedErr = err
+		}),
+	)
+	assert.NoError(t, err)
+
+	// fallback reported
+	assert.Error(t, reportedErr)
+	assert.Contains(t, reportedErr.Error(), "config source unavailable, falling back to the local config")
+
+	// local values only
+	assert.Equal(t, "local-app", cfg.AppName())
+	assert.Equal(t, 0, cfg.GetInt("config.values.int_value"))
+
+	var changedKeys []string
+	cfg.OnChange(func(keys []string) {
+		changedKeys = keys
+	})
+
+	// remote values applied once available
+	available.Store(true)
+
+	changed, err := cfg.RefreshSource(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 42, cfg.GetInt("config.values.int_value"))
+	assert.Contains(t, changedKeys, "config.values.int_value")
+}
+
+func TestCreateWithCustomSource(t *testing.T) {
+	source := &testConfigSource{
+		settings: map[string]interface{}{
+			"app": map[string]interface{}{
+				"name": "custom-app",
+			},
+		},
+	}
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config/valid"),
+		config.WithSource(source),
+		config.WithSourcePrecedence(config.SourcePrecedenceRemote),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, source, cfg.Source())
+	assert.Equal(t, "custom-app", cfg.AppName())
+	assert.Equal(t, "default", cfg.GetString("config.values.string_value"))
+}
+
+func TestCreateWithoutSource(t *testing.T) {
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	assert.Nil(t, cfg.Source())
+
+	changed, err := cfg.RefreshSource(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestCreateFailureOnUnavailableSource(t *testing.T) {
+	var body atomic.Value
+	body.Store(remoteYamlConfig)
+
+	server := newTestSourceServer(t, "application/yaml", &body)
+	defer server.Close()
+
+	t.Setenv("CONFIG_SOURCE_URL", server.URL)
+	t.Setenv("CONFIG_SOURCE_TOKEN", "invalid")
+
+	_, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.Error(t, err)
+	assert.Equal(t, "could not fetch config from source http "+server.URL+": unexpected response status 401", err.Error())
+
+	server.Close()
+
+	_, err = config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not fetch config from source http "+server.URL)
+}
+
+func TestCreateFailureOnInvalidSourceConfig(t *testing.T) {
+	var body atomic.Value
+	body.Store("invalid: [")
+
+	server := newTestSourceServer(t, "application/yaml", &body)
+	defer server.Close()
+
+	t.Setenv("CONFIG_SOURCE_URL", server.URL)
+	t.Setenv("CONFIG_SOURCE_TOKEN", "secret")
+
+	_, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not decode yaml config")
+}
+
+func TestCreateFailureOnUnsupportedSourceType(t *testing.T) {
+	t.Setenv("MODULES_CONFIG_SOURCE_TYPE", "invalid")
+
+	_, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.Error(t, err)
+	assert.Equal(t, "unsupported config source type invalid", err.Error())
+}
+
+func TestRefreshSource(t *testing.T) {
+	var body atomic.Value
+	body.Store(remoteYamlConfig)
+
+	server := newTestSourceServer(t, "application/yaml", &body)
+	defer server.Close()
+
+	t.Setenv("CONFIG_SOURCE_URL", server.URL)
+	t.Setenv("CONFIG_SOURCE_TOKEN", "secret")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.NoError(t, err)
+
+	var changes atomic.Int32
+	cfg.OnSourceChange(func() {
+		changes.Add(1)
+	})
+
+	var changedKeys []string
+	cfg.OnChange(func(keys []string) {
+		changedKeys = keys
+	})
+
+	// unchanged
+	changed, err := cfg.RefreshSource(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, int32(0), changes.Load())
+
+	// changed
+	body.Store("config:\n  values:\n    int_value: 24\n")
+
+	changed, err = cfg.RefreshSource(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, int32(1), changes.Load())
+	assert.Equal(t, 24, cfg.GetInt("config.values.int_value"))
+	assert.NotEqual(t, "remote", cfg.AppEnv())
+	assert.Equal(t, []string{"app.env", "config.values.int_value"}, changedKeys)
+
+	// invalid values ignored
+	body.Store("invalid: [")
+
+	changed, err = cfg.RefreshSource(context.Background())
+	assert.Error(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 24, cfg.GetInt("config.values.int_value"))
+}
+
+func TestRefreshSourceWithConcurrentReads(t *testing.T) {
+	var body atomic.Value
+	body.Store(remoteYamlConfig)
+
+	server := newTestSourceServer(t, "application/yaml", &body)
+	defer server.Close()
+
+	t.Setenv("CONFIG_SOURCE_URL", server.URL)
+	t.Setenv("CONFIG_SOURCE_TOKEN", "secret")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				assert.Contains(t, []int{42, 24, 12}, cfg.GetInt("config.values.int_value"))
+				assert.NotEmpty(t, cfg.AllSettings())
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		body.Store(fmt.Sprintf("config:\n  values:\n    int_value: %d\n", []int{24, 12}[i%2]))
+
+		changed, err := cfg.RefreshSource(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, changed)
+	}
+
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, 12, cfg.GetInt("config.values.int_value"))
+}
+
+func TestWatchSource(t *testing.T) {
+	var body atomic.Value
+	body.Store(remoteYamlConfig)
+
+	server := newTestSourceServer(t, "application/yaml", &body)
+	defer server.Close()
+
+	t.Setenv("CONFIG_SOURCE_URL", server.URL)
+	t.Setenv("CONFIG_SOURCE_TOKEN", "secret")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/source"))
+	assert.NoError(t, err)
+
+	changed := make(chan struct{}, 1)
+	cfg.OnSourceChange(func() {
+		changed <- struct{}{}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		cfg.WatchSource(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	body.Store("config:\n  values:\n    int_value: 24\n")
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("config source change not detected")
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,335 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// ByteSize is a size in bytes, decoded by [Config.Unmarshal] and [Config.UnmarshalKey] from an integer (in bytes), or
+// from a string with a unit (B, KB, MB, GB or TB, as multiples of 1024, like "16MB" or "512 KiB").
+type ByteSize int64
+
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1 << 10,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1 << 20,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1 << 30,
+	"GIB": 1 << 30,
+	"T":   1 << 40,
+	"TB":  1 << 40,
+	"TIB": 1 << 40,
+}
+
+// ParseByteSize parses a size in bytes, with an optional unit (B, KB, MB, GB or TB, as multiples of 1024).
+func ParseByteSize(s string) (ByteSize, error) {
+	trimmed := strings.TrimSpace(s)
+
+	i := 0
+	for i < len(trimmed) && (trimmed[i] >= '0' && trimmed[i] <= '9' || trimmed[i] == '.') {
+		i++
+	}
+
+	value, err := strconv.ParseFloat(trimmed[:i], 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	multiplier, ok := byteSizeUnits[strings.ToUpper(strings.TrimSpace(trimmed[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit %q", s)
+	}
+
+	return ByteSize(value * float64(multiplier)), nil
+}
+
+// Unmarshal decodes the whole config into a struct, see [Config.UnmarshalKey].
+func (c *Config) Unmarshal(out any, opts ...viper.DecoderConfigOption) error {
+	return c.UnmarshalKey("", out, opts...)
+}
+
+// UnmarshalKey decodes the config values of a key into a struct (the whole config if the key is empty), with the
+// mapstructure tags as config keys names. The struct fields values are resolved one by one, so the env vars overrides
+// apply as with the Get accessors.
+//
+// On top of the [Viper] decoding, the values are decoded:
+//   - into time.Duration from duration strings (like "1m30s"), or from numbers in seconds (like 0.5)
+//   - into time.Time from RFC3339 strings
+//   - into [ByteSize] from integers in bytes, or from strings with a unit (like "16MB")
+//   - into slices from comma separated strings (the items being trimmed)
+//
+// The struct fields with a `required:"true"` tag must be set in the config (unless their parent struct key is not
+// set): all the missing keys are listed at once, in an aggregated error. The decoding errors name the full config path
+// of the invalid keys.
+//
+// [Viper]: https://github.com/spf13/viper
+func (c *Config) UnmarshalKey(key string, out any, opts ...viper.DecoderConfigOption) error {
+	var input any
+
+	if isStructType(reflect.TypeOf(out)) {
+		settings := map[string]any{}
+
+		if missing := c.collectSettings(key, reflect.TypeOf(out), settings); len(missing) > 0 {
+			return fmt.Errorf("missing required config keys: %s", strings.Join(missing, ", "))
+		}
+
+		input = settings
+	} else if key == "" {
+		input = c.AllSettings()
+	} else {
+		input = c.Get(key)
+	}
+
+	decoderConfig := &mapstructure.DecoderConfig{
+		Result:           out,
+		WeaklyTypedInput: true,
+		DecodeHook:       decodeHooks(),
+	}
+
+	for _, opt := range opts {
+		opt(decoderConfig)
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return fmt.Errorf("could not decode config: %w", err)
+	}
+
+	if err = decoder.Decode(input); err != nil {
+		return prefixDecodeError(key, err)
+	}
+
+	return nil
+}
+
+// decodeHooks returns the durations, times, byte sizes and comma separated slices decode hooks.
+func decodeHooks() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		durationDecodeHook,
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+		byteSizeDecodeHook,
+		sliceDecodeHook,
+	)
+}
+
+func durationDecodeHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+
+	return parseDuration(data)
+}
+
+func byteSizeDecodeHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if to != reflect.TypeOf(ByteSize(0)) {
+		return data, nil
+	}
+
+	return parseByteSize(data)
+}
+
+// parseDuration parses a duration from a duration string (like "1m30s"), or from a number of seconds.
+func parseDuration(value any) (time.Duration, error) {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.String:
+		s := strings.TrimSpace(v.String())
+		if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+
+		duration, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+
+		return duration, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			return time.Duration(v.Int()), nil
+		}
+
+		return time.Duration(v.Int()) * time.Second, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return time.Duration(v.Uint()) * time.Second, nil
+	case reflect.Float32, reflect.Float64:
+		return time.Duration(v.Float() * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("invalid duration %v", value)
+	}
+}
+
+// parseByteSize parses a size in bytes from a number of bytes, or from a string with an optional unit.
+func parseByteSize(value any) (ByteSize, error) {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.String:
+		return ParseByteSize(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, fmt.Errorf("invalid byte size %d", v.Int())
+		}
+
+		return ByteSize(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ByteSize(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if v.Float() < 0 {
+			return 0, fmt.Errorf("invalid byte size %v", v.Float())
+		}
+
+		return ByteSize(v.Float()), nil
+	default:
+		return 0, fmt.Errorf("invalid byte size %v", value)
+	}
+}
+
+func sliceDecodeHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to.Kind() != reflect.Slice || to.Elem().Kind() == reflect.Uint8 {
+		return data, nil
+	}
+
+	s := strings.TrimSpace(data.(string))
+	if s == "" {
+		return []string{}, nil
+	}
+
+	items := strings.Split(s, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+
+	return items, nil
+}
+
+// collectSettings collects in nested settings the values of the set config keys of a struct fields (walking the nested
+// structs), and returns the keys of the fields with a `required:"true"` tag that are not set.
+func (c *Config) collectSettings(prefix string, t reflect.Type, settings map[string]any) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var missing []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, squash := fieldKeyName(field)
+		if name == "-" {
+			continue
+		}
+
+		if squash {
+			missing = append(missing, c.collectSettings(prefix, field.Type, settings)...)
+
+			continue
+		}
+
+		fieldKey := joinKey(prefix, name)
+		required := field.Tag.Get("required") == "true"
+
+		if isStructType(field.Type) {
+			// the nested keys can be set (by env vars) without their parent key
+			nested := map[string]any{}
+			nestedMissing := c.collectSettings(fieldKey, field.Type, nested)
+
+			switch {
+			case len(nested) > 0 || c.IsSet(fieldKey):
+				settings[name] = nested
+				missing = append(missing, nestedMissing...)
+			case required:
+				missing = append(missing, fieldKey)
+			}
+
+			continue
+		}
+
+		if c.IsSet(fieldKey) {
+			settings[name] = c.Get(fieldKey)
+		} else if required {
+			missing = append(missing, fieldKey)
+		}
+	}
+
+	return missing
+}
+
+// isStructType returns if a type is a struct (or a pointer to a struct) decoded field by field, time.Time excepted.
+func isStructType(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t != nil && t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+// fieldKeyName returns the config key name of a struct field (from its mapstructure tag, or its lower cased name), and
+// if it is squashed in its parent.
+func fieldKeyName(field reflect.StructField) (string, bool) {
+	name, options, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+
+	squash := field.Anonymous && strings.Contains(options, "squash")
+
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	return name, squash
+}
+
+func joinKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+// prefixDecodeError prefixes the names of the invalid fields in the decoding errors with the decoded config key, to
+// name their full config path.
+func prefixDecodeError(key string, err error) error {
+	var decodeErr *mapstructure.Error
+	if key == "" || !errors.As(err, &decodeErr) {
+		return fmt.Errorf("could not decode config: %w", err)
+	}
+
+	prefixed := &mapstructure.Error{Errors: make([]string, len(decodeErr.Errors))}
+	for i, message := range decodeErr.Errors {
+		prefixed.Errors[i] = prefixDecodeErrorMessage(key, message)
+	}
+
+	return fmt.Errorf("could not decode config %s: %w", key, prefixed)
+}
+
+// prefixDecodeErrorMessage prefixes the first quoted field name of a decoding error message with the decoded config key.
+func prefixDecodeErrorMessage(key string, message string) string {
+	start := strings.Index(message, "'")
+	if start < 0 {
+		return message
+	}
+
+	rest := message[start+1:]
+
+	switch {
+	case strings.HasPrefix(rest, "'"), strings.HasPrefix(rest, "["):
+		return message[:start+1] + key + rest
+	default:
+		return message[:start+1] + key + "." + rest
+	}
+}
@@ -0,0 +1,177 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type testServerConfig struct {
+	Address        string            `mapstructure:"address" required:"true"`
+	Port           int               `mapstructure:"port"`
+	ReadTimeout    time.Duration     `mapstructure:"read_timeout"`
+	WriteTimeout   time.Duration     `mapstructure:"write_timeout"`
+	IdleTimeout    time.Duration     `mapstructure:"idle_timeout"`
+	MaxBodySize    config.ByteSize   `mapstructure:"max_body_size"`
+	MaxHeaderBytes config.ByteSize   `mapstructure:"max_header_bytes"`
+	BufferSize     config.ByteSize   `mapstructure:"buffer_size"`
+	StartedAt      time.Time         `mapstructure:"started_at"`
+	Methods        []string          `mapstructure:"methods"`
+	Hosts          []string          `mapstructure:"hosts"`
+	Headers        map[string]string `mapstructure:"headers"`
+	Tls            testTlsConfig     `mapstructure:"tls"`
+}
+
+type testTlsConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file" required:"true"`
+	KeyFile  string `mapstructure:"key_file"`
+}
+
+func createTestUnmarshalConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config/unmarshal"))
+	assert.NoError(t, err)
+
+	return cfg
+}
+
+func TestUnmarshalKey(t *testing.T) {
+	t.Setenv("MODULES_TEST_SERVER_PORT", "9090")
+	t.Setenv("MODULES_TEST_SERVER_TLS_KEY_FILE", "/path/to/key.pem")
+
+	cfg := createTestUnmarshalConfig(t)
+
+	var serverConfig testServerConfig
+	err := cfg.UnmarshalKey("modules.test.server", &serverConfig)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ":8080", serverConfig.Address)
+	assert.Equal(t, 9090, serverConfig.Port)
+	assert.Equal(t, 90*time.Second, serverConfig.ReadTimeout)
+	assert.Equal(t, 5*time.Second, serverConfig.WriteTimeout)
+	assert.Equal(t, 500*time.Millisecond, serverConfig.IdleTimeout)
+	assert.Equal(t, config.ByteSize(16<<20), serverConfig.MaxBodySize)
+	assert.Equal(t, config.ByteSize(1024), serverConfig.MaxHeaderBytes)
+	assert.Equal(t, config.ByteSize(512<<10), serverConfig.BufferSize)
+	assert.Equal(t, time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC), serverConfig.StartedAt.UTC())
+	assert.Equal(t, []string{"GET", "POST", "PUT"}, serverConfig.Methods)
+	assert.Equal(t, []string{"foo.example.com", "bar.example.com"}, serverConfig.Hosts)
+	assert.Equal(t, map[string]string{"x-foo": "foo"}, serverConfig.Headers)
+	assert.Equal(t, testTlsConfig{Enabled: true, CertFile: "/path/to/cert.pem", KeyFile: "/path/to/key.pem"}, serverConfig.Tls)
+}
+
+func TestUnmarshalKeyWithEnvOnlyNestedKeys(t *testing.T) {
+	t.Setenv("MODULES_OTHER_SERVER_ADDRESS", ":9090")
+	t.Setenv("MODULES_OTHER_SERVER_TLS_CERT_FILE", "/path/to/other.pem")
+	t.Setenv("MODULES_OTHER_SERVER_METHODS", "GET,HEAD")
+
+	cfg := createTestUnmarshalConfig(t)
+
+	var serverConfig testServerConfig
+	err := cfg.UnmarshalKey("modules.other.server", &serverConfig)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ":9090", serverConfig.Address)
+	assert.Equal(t, "/path/to/other.pem", serverConfig.Tls.CertFile)
+	assert.Equal(t, []string{"GET", "HEAD"}, serverConfig.Methods)
+}
+
+func TestUnmarshalKeyWithMissingRequiredKeys(t *testing.T) {
+	t.Setenv("MODULES_OTHER_SERVER_TLS_ENABLED", "true")
+
+	cfg := createTestUnmarshalConfig(t)
+
+	var serverConfig testServerConfig
+	err := cfg.UnmarshalKey("modules.other.server", &serverConfig)
+	assert.Error(t, err)
+	assert.Equal(
+		t,
+		"missing required config keys: modules.other.server.address, modules.other.server.tls.cert_file",
+		err.Error(),
+	)
+}
+
+func TestUnmarshalKeyWithInvalidValues(t *testing.T) {
+	t.Parallel()
+
+	cfg := createTestUnmarshalConfig(t)
+
+	var invalidConfig struct {
+		Port    int                  `mapstructure:"port"`
+		Timeout time.Duration        `mapstructure:"timeout"`
+		Size    config.ByteSize      `mapstructure:"size"`
+		Hosts   []struct{ Port int } `mapstructure:"hosts"`
+	}
+
+	err := cfg.UnmarshalKey("modules.test.invalid", &invalidConfig)
+	assert.Error(t, err)
+
+	assert.Contains(t, err.Error(), "could not decode config modules.test.invalid")
+	assert.Contains(t, err.Error(), "cannot parse 'modules.test.invalid.port' as int")
+	assert.Contains(t, err.Error(), "error decoding 'modules.test.invalid.timeout'")
+	assert.Contains(t, err.Error(), `error decoding 'modules.test.invalid.size': invalid byte size unit "16 parsecs"`)
+	assert.Contains(t, err.Error(), "cannot parse 'modules.test.invalid.hosts[0].Port' as int")
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	cfg := createTestUnmarshalConfig(t)
+
+	var appConfig struct {
+		App struct {
+			Name    string `mapstructure:"name" required:"true"`
+			Version string `mapstructure:"version"`
+		} `mapstructure:"app"`
+		Server testServerConfig `mapstructure:"server"`
+	}
+
+	err := cfg.Unmarshal(&appConfig)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "unmarshal-app", appConfig.App.Name)
+	assert.Equal(t, config.DefaultAppVersion, appConfig.App.Version)
+	assert.Equal(t, testServerConfig{}, appConfig.Server)
+
+	// not a struct
+	var settings map[string]interface{}
+	err = cfg.UnmarshalKey("modules.test.server.headers", &settings)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"x-foo": "foo"}, settings)
+}
+
+func TestParseByteSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    string
+		expected config.ByteSize
+		err      bool
+	}{
+		{value: "1024", expected: 1024},
+		{value: "10B", expected: 10},
+		{value: "16KB", expected: 16 << 10},
+		{value: "16k", expected: 16 << 10},
+		{value: "1.5 MiB", expected: 3 << 19},
+		{value: "2GB", expected: 2 << 30},
+		{value: "1TB", expected: 1 << 40},
+		{value: "", err: true},
+		{value: "MB", err: true},
+		{value: "12 parsecs", err: true},
+	}
+
+	for _, tt := range tests {
+		size, err := config.ParseByteSize(tt.value)
+
+		if tt.err {
+			assert.Error(t, err, tt.value)
+		} else {
+			assert.NoError(t, err, tt.value)
+			assert.Equal(t, tt.expected, size, tt.value)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// current returns the [viper.Viper] holding the latest config values, replaced at once on reloads and on the remote
+// config source refreshes, so that the values can be read while being reloaded.
+func (c *Config) current() *viper.Viper {
+	if v := c.latest.Load(); v != nil {
+		return v
+	}
+
+	return c.Viper
+}
+
+// Get returns the value of a key.
+func (c *Config) Get(key string) any {
+	return c.current().Get(key)
+}
+
+// GetString returns the value of a key as a string.
+func (c *Config) GetString(key string) string {
+	return c.current().GetString(key)
+}
+
+// GetBool returns the value of a key as a bool.
+func (c *Config) GetBool(key string) bool {
+	return c.current().GetBool(key)
+}
+
+// GetInt returns the value of a key as an int.
+func (c *Config) GetInt(key string) int {
+	return c.current().GetInt(key)
+}
+
+// GetInt32 returns the value of a key as an int32.
+func (c *Config) GetInt32(key string) int32 {
+	return c.current().GetInt32(key)
+}
+
+// GetInt64 returns the value of a key as an int64.
+func (c *Config) GetInt64(key string) int64 {
+	return c.current().GetInt64(key)
+}
+
+// GetUint returns the value of a key as an uint.
+func (c *Config) GetUint(key string) uint {
+	return c.current().GetUint(key)
+}
+
+// GetUint16 returns the value of a key as an uint16.
+func (c *Config) GetUint16(key string) uint16 {
+	return c.current().GetUint16(key)
+}
+
+// GetUint32 returns the value of a key as an uint32.
+func (c *Config) GetUint32(key string) uint32 {
+	return c.current().GetUint32(key)
+}
+
+// GetUint64 returns the value of a key as an uint64.
+func (c *Config) GetUint64(key string) uint64 {
+	return c.current().GetUint64(key)
+}
+
+// GetFloat64 returns the value of a key as a float64.
+func (c *Config) GetFloat64(key string) float64 {
+	return c.current().GetFloat64(key)
+}
+
+// GetTime returns the value of a key as a time.
+func (c *Config) GetTime(key string) time.Time {
+	return c.current().GetTime(key)
+}
+
+// GetIntSlice returns the value of a key as a slice of ints.
+func (c *Config) GetIntSlice(key string) []int {
+	return c.current().GetIntSlice(key)
+}
+
+// GetStringSlice returns the value of a key as a slice of strings.
+func (c *Config) GetStringSlice(key string) []string {
+	return c.current().GetStringSlice(key)
+}
+
+// GetStringMap returns the value of a key as a map of interfaces.
+func (c *Config) GetStringMap(key string) map[string]any {
+	return c.current().GetStringMap(key)
+}
+
+// GetStringMapString returns the value of a key as a map of strings.
+func (c *Config) GetStringMapString(key string) map[string]string {
+	return c.current().GetStringMapString(key)
+}
+
+// GetStringMapStringSlice returns the value of a key as a map of slices of strings.
+func (c *Config) GetStringMapStringSlice(key string) map[string][]string {
+	return c.current().GetStringMapStringSlice(key)
+}
+
+// GetSizeInBytes returns the value of a key as a size in bytes, see [Config.GetBytes] for a stricter parsing.
+func (c *Config) GetSizeInBytes(key string) uint {
+	return c.current().GetSizeInBytes(key)
+}
+
+// IsSet returns if a key is set, in the config files, the env vars, the remote config source or at runtime.
+func (c *Config) IsSet(key string) bool {
+	return c.current().IsSet(key)
+}
+
+// InConfig returns if a key is set in the config files.
+func (c *Config) InConfig(key string) bool {
+	return c.current().InConfig(key)
+}
+
+// AllKeys returns all the config keys.
+func (c *Config) AllKeys() []string {
+	return c.current().AllKeys()
+}
+
+// AllSettings returns all the config values, as nested maps.
+func (c *Config) AllSettings() map[string]any {
+	return c.current().AllSettings()
+}
+
+// Sub returns a new [viper.Viper] holding the config values of a key, or nil if the key is not set.
+func (c *Config) Sub(key string) *viper.Viper {
+	return c.current().Sub(key)
+}
+
+// UnmarshalExact decodes the whole config into a struct, failing on the config keys not matching a struct field.
+func (c *Config) UnmarshalExact(out any, opts ...viper.DecoderConfigOption) error {
+	return c.current().UnmarshalExact(out, opts...)
+}
+
+// ConfigFileUsed returns the path of the last config file loaded.
+func (c *Config) ConfigFileUsed() string {
+	return c.current().ConfigFileUsed()
+}
+
+// Set overrides the value of a key at runtime, until the next reload.
+func (c *Config) Set(key string, value any) {
+	c.current().Set(key, value)
+}
+
+// SetDefault sets the default value of a key, until the next reload.
+func (c *Config) SetDefault(key string, value any) {
+	c.current().SetDefault(key, value)
+}
+
+// MergeConfigMap merges the given values into the config values, until the next reload.
+func (c *Config) MergeConfigMap(cfg map[string]any) error {
+	return c.current().MergeConfigMap(cfg)
+}
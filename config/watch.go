@@ -0,0 +1,304 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// DefaultWatchDebounce is the default delay to wait for the config files writes to settle before reloading them.
+const DefaultWatchDebounce = 100 * time.Millisecond
+
+// Files returns the resolved config files paths (the config file, the profiles ones, the APP_ENV one, and the
+// APP_CONFIG_PROFILES ones), in their loading order.
+func (c *Config) Files() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.files...)
+}
+
+// SkippedFiles returns the names (without extension) of the APP_CONFIG_PROFILES config files that were not found, and
+// therefore skipped.
+func (c *Config) SkippedFiles() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.skipped...)
+}
+
+// Sources returns the config file supplying each top level config key (the last merged one defining it), for
+// debugging the config files merge.
+func (c *Config) Sources() map[string]string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	sources := make(map[string]string, len(c.sources))
+	for key, file := range c.sources {
+		sources[key] = file
+	}
+
+	return sources
+}
+
+// OnChange registers a function to call with the keys whose values changed, when the config files are reloaded or the
+// remote config source values are refreshed.
+func (c *Config) OnChange(fn func(keys []string)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.changeListeners = append(c.changeListeners, fn)
+}
+
+// OnReloadError registers a function to call when the watched config files cannot be reloaded (they are otherwise
+// reported to the function provided with [WithErrorHandler], if any).
+func (c *Config) OnReloadError(fn func(err error)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.errorListeners = append(c.errorListeners, fn)
+}
+
+// Reload loads again the config files (with the env vars, and the remote config source values if any), and replaces
+// the config values at once, returning the keys whose values changed. The functions registered with OnChange are
+// called with these keys if any, and the previous values are kept on error.
+//
+// The values set at runtime (with Set) are not kept, and the config not created from files is never reloaded.
+func (c *Config) Reload() ([]string, error) {
+	c.mutex.Lock()
+
+	if len(c.files) == 0 {
+		c.mutex.Unlock()
+
+		return nil, nil
+	}
+
+	loaded, err := c.rebuild(c.sourceSettings)
+	if err != nil {
+		c.mutex.Unlock()
+
+		return nil, err
+	}
+
+	changed := c.swap(loaded)
+	changeListeners := c.changeListeners
+	c.mutex.Unlock()
+
+	notifyChange(changeListeners, changed)
+
+	return changed, nil
+}
+
+// rebuild returns the config values loaded again from the config files (with the env vars), with the given remote
+// config source values applied, into a new [viper.Viper] to swap in.
+func (c *Config) rebuild(sourceSettings map[string]interface{}) (*loadedFiles, error) {
+	loaded, err := (&DefaultConfigFactory{}).loadFiles(c.options)
+	if err != nil {
+		return nil, fmt.Errorf("could not reload config files: %w", err)
+	}
+
+	if sourceSettings != nil {
+		if err = applySourceSettings(loaded.viper, sourceSettings, c.sourcePrecedence); err != nil {
+			return nil, fmt.Errorf("could not apply config from source %s: %w", c.source.Name(), err)
+		}
+	}
+
+	if err = expandEnvPlaceholders(loaded.viper); err != nil {
+		return nil, fmt.Errorf("could not reload config files: %w", err)
+	}
+
+	return loaded, nil
+}
+
+// swap replaces at once the config values with the rebuilt ones, returning the keys whose values changed. It must be
+// called with the config mutex held.
+func (c *Config) swap(loaded *loadedFiles) []string {
+	changed := changedKeys(snapshotSettings(c.current()), snapshotSettings(loaded.viper))
+
+	c.latest.Store(loaded.viper)
+	c.envReplacer = loaded.envReplacer
+	c.files = loaded.files
+	c.skipped = loaded.skipped
+	c.sources = loaded.sources
+	c.flagValues = nil
+	c.markSecret(loaded.secrets...)
+	c.markSecret(loaded.viper.GetStringSlice(secretsConfigKey)...)
+
+	return changed
+}
+
+// WatchFiles watches the resolved config files, and reloads them on changes, until the given context is canceled.
+//
+// The reloads are delayed until the files writes settle for [DefaultWatchDebounce], and the reload failures (like a
+// malformed file) are reported to the functions registered with OnReloadError, the previous values being kept until
+// the next successful reload. The files directories are watched, to also detect the files replaced (for example by
+// editors, or by Kubernetes ConfigMap volumes symlinks updates).
+func (c *Config) WatchFiles(ctx context.Context) error {
+	files := c.Files()
+	if len(files) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not create config files watcher: %w", err)
+	}
+
+	dirs := map[string]struct{}{}
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if _, ok := dirs[dir]; ok {
+			continue
+		}
+
+		if err = watcher.Add(dir); err != nil {
+			//nolint:errcheck
+			watcher.Close()
+
+			return fmt.Errorf("could not watch config files directory %s: %w", dir, err)
+		}
+
+		dirs[dir] = struct{}{}
+	}
+
+	go c.watchFiles(ctx, watcher, files)
+
+	return nil
+}
+
+func (c *Config) watchFiles(ctx context.Context, watcher *fsnotify.Watcher, files []string) {
+	//nolint:errcheck
+	defer watcher.Close()
+
+	watched := map[string]string{}
+	for _, file := range files {
+		watched[filepath.Clean(file)] = realPath(file)
+	}
+
+	debounce := time.NewTimer(DefaultWatchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			if c.isWatchedFileEvent(event, watched) {
+				debounce.Reset(DefaultWatchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+			c.reportError(fmt.Errorf("config files watcher error: %w", err))
+		case <-debounce.C:
+			if _, err := c.Reload(); err != nil {
+				c.reportError(err)
+			}
+		}
+	}
+}
+
+// isWatchedFileEvent returns if a watcher event concerns a watched file: on the file itself, or on its symlink target
+// (updating the known target).
+func (c *Config) isWatchedFileEvent(event fsnotify.Event, watched map[string]string) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	if _, ok := watched[filepath.Clean(event.Name)]; ok {
+		return true
+	}
+
+	changed := false
+	for file, target := range watched {
+		if current := realPath(file); current != target {
+			watched[file] = current
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// reportError reports a non fatal error to the functions registered with OnReloadError, or to the function provided
+// with [WithErrorHandler] otherwise.
+func (c *Config) reportError(err error) {
+	c.mutex.Lock()
+	errorListeners := c.errorListeners
+	errorHandler := c.options.ErrorHandler
+	c.mutex.Unlock()
+
+	if len(errorListeners) == 0 {
+		if errorHandler != nil {
+			errorHandler(err)
+		}
+
+		return
+	}
+
+	for _, listener := range errorListeners {
+		listener(err)
+	}
+}
+
+func realPath(file string) string {
+	resolved, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return ""
+	}
+
+	return resolved
+}
+
+// snapshotSettings returns the values of all the config keys.
+func snapshotSettings(v *viper.Viper) map[string]interface{} {
+	settings := map[string]interface{}{}
+	for _, key := range v.AllKeys() {
+		settings[key] = v.Get(key)
+	}
+
+	return settings
+}
+
+// changedKeys returns the sorted keys whose values are different (or missing) between two settings snapshots.
+func changedKeys(before map[string]interface{}, after map[string]interface{}) []string {
+	var keys []string
+	for key, value := range after {
+		if previous, ok := before[key]; !ok || !reflect.DeepEqual(previous, value) {
+			keys = append(keys, key)
+		}
+	}
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func notifyChange(listeners []func(keys []string), keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	for _, listener := range listeners {
+		listener(keys)
+	}
+}
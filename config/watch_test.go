@@ -0,0 +1,228 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestConfigFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	assert.NoError(t, err)
+}
+
+func createTestWatchedConfig(t *testing.T) (*config.Config, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeTestConfigFile(t, file, "app:\n  name: watched-app\nconfig:\n  values:\n    string_value: initial\n    int_value: 1\n")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths(dir))
+	assert.NoError(t, err)
+
+	return cfg, file
+}
+
+func TestFiles(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	files := cfg.Files()
+	assert.Len(t, files, 2)
+	assert.Equal(t, "config.yaml", filepath.Base(files[0]))
+	assert.Equal(t, "config.test.yaml", filepath.Base(files[1]))
+}
+
+func TestReload(t *testing.T) {
+	cfg, file := createTestWatchedConfig(t)
+
+	var calls [][]string
+	cfg.OnChange(func(keys []string) {
+		calls = append(calls, keys)
+	})
+
+	// unchanged
+	keys, err := cfg.Reload()
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+	assert.Empty(t, calls)
+
+	// changed
+	writeTestConfigFile(t, file, "app:\n  name: watched-app\nconfig:\n  values:\n    string_value: updated\n    int_value: 1\n    new_value: true\n")
+
+	keys, err = cfg.Reload()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config.values.new_value", "config.values.string_value"}, keys)
+	assert.Equal(t, [][]string{keys}, calls)
+	assert.Equal(t, "updated", cfg.GetString("config.values.string_value"))
+	assert.True(t, cfg.GetBool("config.values.new_value"))
+	assert.Equal(t, "watched-app", cfg.AppName())
+}
+
+func TestReloadWithMalformedFile(t *testing.T) {
+	cfg, file := createTestWatchedConfig(t)
+
+	var calls int
+	cfg.OnChange(func(keys []string) {
+		calls++
+	})
+
+	writeTestConfigFile(t, file, "config: [")
+
+	keys, err := cfg.Reload()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not reload config files")
+	assert.Nil(t, keys)
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, "initial", cfg.GetString("config.values.string_value"))
+}
+
+func TestReloadWithConcurrentReads(t *testing.T) {
+	cfg, file := createTestWatchedConfig(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				value := cfg.GetString("config.values.string_value")
+				assert.Contains(t, []string{"initial", "updated"}, value)
+				assert.Equal(t, "watched-app", cfg.AppName())
+				assert.NotEmpty(t, cfg.AllSettings())
+			}
+		}()
+	}
+
+	writeTestConfigFile(t, file, "app:\n  name: watched-app\nconfig:\n  values:\n    string_value: updated\n")
+
+	for i := 0; i < 20; i++ {
+		_, err := cfg.Reload()
+		assert.NoError(t, err)
+	}
+
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, "updated", cfg.GetString("config.values.string_value"))
+}
+
+func TestReloadWithoutFiles(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{}
+
+	keys, err := cfg.Reload()
+	assert.NoError(t, err)
+	assert.Nil(t, keys)
+
+	err = cfg.WatchFiles(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestWatchFiles(t *testing.T) {
+	cfg, file := createTestWatchedConfig(t)
+
+	changes := make(chan []string, 10)
+	cfg.OnChange(func(keys []string) {
+		changes <- keys
+	})
+
+	errs := make(chan error, 10)
+	cfg.OnReloadError(func(err error) {
+		errs <- err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := cfg.WatchFiles(ctx)
+	assert.NoError(t, err)
+
+	// changed
+	writeTestConfigFile(t, file, "app:\n  name: watched-app\nconfig:\n  values:\n    string_value: updated\n    int_value: 1\n")
+
+	select {
+	case keys := <-changes:
+		assert.Equal(t, []string{"config.values.string_value"}, keys)
+		assert.Equal(t, "updated", cfg.GetString("config.values.string_value"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("config file change not detected")
+	}
+
+	// malformed, previous config kept
+	writeTestConfigFile(t, file, "config: [")
+
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "could not reload config files")
+		assert.Equal(t, "updated", cfg.GetString("config.values.string_value"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("config file reload error not reported")
+	}
+
+	// replaced file
+	tmpFile := file + ".tmp"
+	writeTestConfigFile(t, tmpFile, "app:\n  name: watched-app\nconfig:\n  values:\n    string_value: replaced\n    int_value: 1\n")
+
+	err = os.Rename(tmpFile, file)
+	assert.NoError(t, err)
+
+	select {
+	case keys := <-changes:
+		assert.Equal(t, []string{"config.values.string_value"}, keys)
+		assert.Equal(t, "replaced", cfg.GetString("config.values.string_value"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("config file replacement not detected")
+	}
+
+	assert.Empty(t, changes)
+}
+
+func TestWatchFilesWithErrorHandler(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeTestConfigFile(t, file, "config:\n  values:\n    string_value: initial\n")
+
+	errs := make(chan error, 10)
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths(dir),
+		config.WithErrorHandler(func(err error) {
+			errs <- err
+		}),
+	)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = cfg.WatchFiles(ctx)
+	assert.NoError(t, err)
+
+	// malformed, reported to the error handler without reload error listeners
+	writeTestConfigFile(t, file, "config: [")
+
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "could not reload config files")
+		assert.Equal(t, "initial", cfg.GetString("config.values.string_value"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("config file reload error not reported")
+	}
+}
@@ -1,7 +1,11 @@
 package fxconfig
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/ankorstore/yokai/config"
 	"go.uber.org/fx"
@@ -24,17 +28,131 @@ var FxConfigModule = fx.Module(
 // FxConfigParam allows injection of the required dependencies in [NewFxConfig].
 type FxConfigParam struct {
 	fx.In
-	Factory config.ConfigFactory
+	LifeCycle    fx.Lifecycle
+	Factory      config.ConfigFactory
+	Schemas      []*config.Schema    `group:"config-schemas"`
+	FlagProvider config.FlagProvider `optional:"true"`
 }
 
 // NewFxConfig returns a [config.Config].
+//
+// The APP_CONFIG_PATH env var can reference a colon or comma separated list of config directories: the config files
+// are looked up in the first one, and the ones found in the next ones are merged in order on top.
+//
+// If the config has a remote source and modules.config.source.refresh_interval is set, the remote source values are
+// refreshed periodically while the application is running.
+//
+// If config schemas are registered (see [AsConfigSchema]), the config is validated when the application starts: the
+// diagnostics are reported on stderr, or fail the start if modules.config.validation.strict is true.
+//
+// If a [config.FlagProvider] is provided, the feature flags are resolved from it before the config.
+//
+// If modules.config.watch.enabled is true, the config files are watched and reloaded on changes while the application
+// is running.
 func NewFxConfig(p FxConfigParam) (*config.Config, error) {
-	return p.Factory.Create(
+	paths := configPaths(os.Getenv("APP_CONFIG_PATH"))
+
+	options := []config.ConfigOption{
 		config.WithFileName("config"),
 		config.WithFilePaths(
 			".",
 			"./configs",
-			os.Getenv("APP_CONFIG_PATH"),
+			paths[0],
 		),
-	)
+		config.WithFileLayers(paths[1:]...),
+	}
+
+	if p.FlagProvider != nil {
+		options = append(options, config.WithFlagProvider(p.FlagProvider))
+	}
+
+	cfg, err := p.Factory.Create(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Source() != nil {
+		if interval := cfg.GetFloat64("modules.config.source.refresh_interval"); interval > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			p.LifeCycle.Append(fx.Hook{
+				OnStart: func(context.Context) error {
+					go cfg.WatchSource(ctx, time.Duration(interval*float64(time.Second)))
+
+					return nil
+				},
+				OnStop: func(context.Context) error {
+					cancel()
+
+					return nil
+				},
+			})
+		}
+	}
+
+	if len(p.Schemas) > 0 {
+		p.LifeCycle.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				return validateConfig(cfg, p.Schemas)
+			},
+		})
+	}
+
+	if cfg.GetBool("modules.config.watch.enabled") {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		p.LifeCycle.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				return cfg.WatchFiles(ctx)
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+
+				return nil
+			},
+		})
+	}
+
+	return cfg, nil
+}
+
+// configPaths splits a colon or comma separated list of config directories, always returning at least one (possibly
+// empty) path.
+func configPaths(value string) []string {
+	paths := []string{}
+	for _, path := range strings.FieldsFunc(value, func(r rune) bool { return r == ':' || r == ',' }) {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	if len(paths) == 0 {
+		return []string{""}
+	}
+
+	return paths
+}
+
+// validateConfig validates the config against the registered schemas, returning an error with the diagnostics in strict
+// mode, or reporting them on stderr otherwise.
+func validateConfig(cfg *config.Config, schemas []*config.Schema) error {
+	diagnostics := cfg.Validate(schemas...)
+	if len(diagnostics) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		messages[i] = diagnostic.String()
+	}
+
+	if cfg.GetBool("modules.config.validation.strict") {
+		return fmt.Errorf("invalid config: %s", strings.Join(messages, "; "))
+	}
+
+	for _, message := range messages {
+		fmt.Fprintf(os.Stderr, "config validation warning: %s\n", message)
+	}
+
+	return nil
 }
@@ -1,7 +1,15 @@
 package fxconfig_test
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/fxconfig"
@@ -93,6 +101,128 @@ func TestModuleWithCustomEnv(t *testing.T) {
 	assert.Equal(t, "foo-bar-baz", cfg.GetString("config.substitution"))
 }
 
+func TestModuleWithMultipleConfigPaths(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config:testdata/layers/overrides, testdata/layers/local")
+	t.Setenv("APP_VERSION", "1.0.0")
+
+	var cfg *config.Config
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fx.Populate(&cfg),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, "overrides-app", cfg.AppName())
+	assert.Equal(t, "1.0.0", cfg.AppVersion())
+	assert.Equal(t, "overrides", cfg.GetString("config.values.string_value"))
+	assert.Equal(t, 2, cfg.GetInt("config.values.int_value"))
+	assert.Equal(t, "foo--baz", cfg.GetString("config.substitution"))
+
+	sources := cfg.Sources()
+	assert.Equal(t, "config.yaml", filepath.Base(sources["app"]))
+	assert.Contains(t, sources["app"], filepath.Join("layers", "overrides"))
+	assert.Contains(t, sources["config"], filepath.Join("layers", "local"))
+}
+
+func testConfigSchema() *config.Schema {
+	return config.NewSchema(
+		"modules.test",
+		config.SchemaKey{Path: "port", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "mode", Type: config.SchemaTypeString, Allowed: []string{"fast", "safe"}},
+	)
+}
+
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	assert.NoError(t, err)
+
+	stderr := os.Stderr
+	os.Stderr = writer
+
+	defer func() {
+		os.Stderr = stderr
+	}()
+
+	fn()
+
+	assert.NoError(t, writer.Close())
+
+	output, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+
+	return string(output)
+}
+
+func TestModuleWithValidConfigSchema(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_PROFILES", "strict")
+
+	var cfg *config.Config
+
+	output := captureStderr(t, func() {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxconfig.AsConfigSchema(testConfigSchema()),
+			fx.Populate(&cfg),
+		).RequireStart().RequireStop()
+	})
+
+	assert.Equal(t, "default-app", cfg.AppName())
+	assert.Empty(t, output)
+}
+
+func TestModuleWithConfigSchemaWarnings(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_PROFILES", "schema")
+
+	var cfg *config.Config
+
+	output := captureStderr(t, func() {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxconfig.AsConfigSchema(testConfigSchema()),
+			fx.Populate(&cfg),
+		).RequireStart().RequireStop()
+	})
+
+	assert.Equal(t, 8080, cfg.GetInt("modules.test.prot"))
+	assert.Equal(
+		t,
+		"config validation warning: modules.test.mode: invalid value slow, allowed values: fast, safe\n"+
+			"config validation warning: modules.test.prot: unknown config key, did you mean modules.test.port?\n",
+		output,
+	)
+}
+
+func TestModuleWithStrictConfigSchemaErrors(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_PROFILES", "schema,strict")
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxconfig.AsConfigSchema(testConfigSchema()),
+		fx.Invoke(func(*config.Config) {}),
+	)
+
+	err := app.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(
+		t,
+		err.Error(),
+		"invalid config: modules.test.mode: invalid value slow, allowed values: fast, safe; "+
+			"modules.test.prot: unknown config key, did you mean modules.test.port?",
+	)
+}
+
 func TestModuleDecoration(t *testing.T) {
 	var cfg *config.Config
 
@@ -104,5 +234,132 @@ func TestModuleDecoration(t *testing.T) {
 		fx.Populate(&cfg),
 	).RequireStart().RequireStop()
 
-	assert.Equal(t, &config.Config{}, cfg)
+	assert.Empty(t, cfg.AllSettings())
+	assert.Empty(t, cfg.Files())
+}
+
+type testFlagProvider struct{}
+
+func (p *testFlagProvider) Name() string {
+	return "test-provider"
+}
+
+func (p *testFlagProvider) Lookup(name string) (config.FlagValue, bool) {
+	return config.FlagValue{Enabled: name == "remote", Rollout: 100}, true
+}
+
+func TestModuleWithFlagProvider(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var cfg *config.Config
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fx.Provide(
+			fx.Annotate(
+				func() *testFlagProvider {
+					return &testFlagProvider{}
+				},
+				fx.As(new(config.FlagProvider)),
+			),
+		),
+		fx.Populate(&cfg),
+	).RequireStart().RequireStop()
+
+	assert.True(t, cfg.Flag("remote").Enabled(context.Background()))
+	assert.False(t, cfg.Flag("other").Enabled(context.Background()))
+	assert.Equal(t, "test-provider", cfg.Flag("remote").Value().Source)
+}
+
+func TestModuleWithFilesWatch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+
+	err := os.WriteFile(file, []byte("modules:\n  config:\n    watch:\n      enabled: true\nconfig:\n  flag: false\n"), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("APP_CONFIG_PATH", dir)
+
+	changes := make(chan []string, 10)
+
+	var cfg *config.Config
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fx.Invoke(func(cfg *config.Config) {
+			cfg.OnChange(func(keys []string) {
+				changes <- keys
+			})
+		}),
+		fx.Populate(&cfg),
+	).RequireStart()
+
+	assert.False(t, cfg.GetBool("config.flag"))
+
+	err = os.WriteFile(file, []byte("modules:\n  config:\n    watch:\n      enabled: true\nconfig:\n  flag: true\n"), 0o600)
+	assert.NoError(t, err)
+
+	select {
+	case keys := <-changes:
+		assert.Equal(t, []string{"config.flag"}, keys)
+		assert.True(t, cfg.GetBool("config.flag"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("config file change not detected")
+	}
+
+	app.RequireStop()
+}
+
+func TestModuleWithRemoteSourceRefresh(t *testing.T) {
+	var value atomic.Value
+	value.Store("config:\n  flag: false\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kv/apps/app/config", r.URL.Path)
+
+		//nolint:forcetypeassert
+		_, err := w.Write([]byte(value.Load().(string)))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_CONFIG_SOURCE_TYPE", config.SourceTypeConsul)
+	t.Setenv("MODULES_CONFIG_SOURCE_ADDRESS", server.URL)
+	t.Setenv("MODULES_CONFIG_SOURCE_KEY", "apps/app/config")
+	t.Setenv("MODULES_CONFIG_SOURCE_REFRESH_INTERVAL", "0.01")
+
+	changes := make(chan []string, 10)
+
+	var cfg *config.Config
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fx.Invoke(func(cfg *config.Config) {
+			cfg.OnChange(func(keys []string) {
+				changes <- keys
+			})
+		}),
+		fx.Populate(&cfg),
+	).RequireStart()
+
+	assert.False(t, cfg.GetBool("config.flag"))
+
+	value.Store("config:\n  flag: true\n")
+
+	select {
+	case keys := <-changes:
+		assert.Equal(t, []string{"config.flag"}, keys)
+		assert.True(t, cfg.GetBool("config.flag"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("remote config change not detected")
+	}
+
+	app.RequireStop()
 }
@@ -0,0 +1,25 @@
+package fxconfig
+
+import (
+	"github.com/ankorstore/yokai/config"
+	"go.uber.org/fx"
+)
+
+// AsConfigSchema registers config schemas into Fx, to validate the loaded config when the application starts.
+func AsConfigSchema(schemas ...*config.Schema) fx.Option {
+	var schemaOptions []fx.Option
+
+	for _, schema := range schemas {
+		schemaOptions = append(
+			schemaOptions,
+			fx.Supply(
+				fx.Annotate(
+					schema,
+					fx.ResultTags(`group:"config-schemas"`),
+				),
+			),
+		)
+	}
+
+	return fx.Options(schemaOptions...)
+}
@@ -2,6 +2,7 @@ package factory
 
 import (
 	"github.com/ankorstore/yokai/config"
+	"github.com/spf13/viper"
 )
 
 type TestConfigFactory struct{}
@@ -11,5 +12,5 @@ func NewTestConfigFactory() config.ConfigFactory {
 }
 
 func (f *TestConfigFactory) Create(options ...config.ConfigOption) (*config.Config, error) {
-	return &config.Config{}, nil
+	return &config.Config{Viper: viper.New()}, nil
 }
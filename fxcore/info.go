@@ -50,6 +50,7 @@ type FxCoreModuleInfo struct {
 	AppEnv         string
 	AppDebug       bool
 	AppVersion     string
+	ConfigFiles    []string
 	LogLevel       string
 	LogOutput      string
 	TraceProcessor string
@@ -93,6 +94,7 @@ func NewFxCoreModuleInfo(p FxCoreModuleInfoParam) *FxCoreModuleInfo {
 		AppEnv:         p.Config.AppEnv(),
 		AppDebug:       p.Config.AppDebug(),
 		AppVersion:     p.Config.AppVersion(),
+		ConfigFiles:    p.Config.Files(),
 		LogLevel:       logLevel,
 		LogOutput:      logOutput,
 		TraceProcessor: traceProcessor,
@@ -115,6 +117,9 @@ func (i *FxCoreModuleInfo) Data() map[string]interface{} {
 			"debug":   i.AppDebug,
 			"version": i.AppVersion,
 		},
+		"config": map[string]interface{}{
+			"files": i.ConfigFiles,
+		},
 		"log": map[string]interface{}{
 			"level":  i.LogLevel,
 			"output": i.LogOutput,
@@ -1,6 +1,7 @@
 package fxcore_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/ankorstore/yokai/config"
@@ -38,6 +39,12 @@ func TestNewFxCoreModuleInfo(t *testing.T) {
 				"debug":   true,
 				"version": "0.1.0",
 			},
+			"config": map[string]interface{}{
+				"files": []string{
+					absPath(t, "./testdata/config/config.yaml"),
+					absPath(t, "./testdata/config/config.test.yaml"),
+				},
+			},
 			"log": map[string]interface{}{
 				"level":  "debug",
 				"output": "test",
@@ -53,3 +60,12 @@ func TestNewFxCoreModuleInfo(t *testing.T) {
 		info.Data(),
 	)
 }
+
+func absPath(t *testing.T, path string) string {
+	t.Helper()
+
+	abs, err := filepath.Abs(path)
+	assert.NoError(t, err)
+
+	return abs
+}
@@ -1,8 +1,11 @@
 package fxcore
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -35,6 +38,9 @@ const (
 	ModuleName                      = "core"
 	DefaultPort                     = 8081
 	DefaultMetricsPath              = "/metrics"
+	DefaultVersionPath              = "/version"
+	DefaultConfigDumpPath           = "/_config"
+	DefaultLogLevelPath             = "/_log/level"
 	DefaultHealthCheckStartupPath   = "/healthz"
 	DefaultHealthCheckLivenessPath  = "/livez"
 	DefaultHealthCheckReadinessPath = "/readyz"
@@ -64,6 +70,7 @@ var FxCoreModule = fx.Module(
 	fxhealthcheck.FxHealthcheckModule,
 	fx.Provide(
 		NewFxModuleInfoRegistry,
+		NewFxShutdownCoordinator,
 		NewFxCore,
 		fx.Annotate(
 			NewFxCoreModuleInfo,
@@ -101,6 +108,26 @@ type FxCoreParam struct {
 func NewFxCore(p FxCoreParam) (*Core, error) {
 	appDebug := p.Config.AppDebug()
 
+	// skipped config files
+	for _, file := range p.Config.SkippedFiles() {
+		p.Logger.Debug().Str("file", file).Msg("skipped missing config profile file")
+	}
+
+	// feature flags, logged once on first read, and resolved at startup for the configured ones
+	p.Config.OnFlagResolution(func(name string, value config.FlagValue) {
+		p.Logger.Info().
+			Str("flag", name).
+			Bool("enabled", value.Enabled).
+			Float64("rollout", value.Rollout).
+			Str("variant", value.Variant).
+			Str("source", value.Source).
+			Msg("feature flag resolved")
+	})
+
+	for _, name := range p.Config.FlagNames() {
+		p.Config.Flag(name).Value()
+	}
+
 	// logger
 	coreLogger := httpserver.NewEchoLogger(
 		log.FromZerolog(p.Logger.ToZerolog().With().Str("module", ModuleName).Logger()),
@@ -255,10 +282,13 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 
 	// template expositions
 	metricsExpose := p.Config.GetBool("modules.core.server.metrics.expose")
+	versionExpose := p.Config.GetBool("modules.core.server.version.expose")
 	startupExpose := p.Config.GetBool("modules.core.server.healthcheck.startup.expose")
 	livenessExpose := p.Config.GetBool("modules.core.server.healthcheck.liveness.expose")
 	readinessExpose := p.Config.GetBool("modules.core.server.healthcheck.readiness.expose")
 	configExpose := p.Config.GetBool("modules.core.server.debug.config.expose")
+	configDumpExpose := p.Config.GetBool("modules.config.dump.enabled")
+	logLevelExpose := p.Config.GetBool("modules.core.server.log_level.expose")
 	pprofExpose := p.Config.GetBool("modules.core.server.debug.pprof.expose")
 	routesExpose := p.Config.GetBool("modules.core.server.debug.routes.expose")
 	statsExpose := p.Config.GetBool("modules.core.server.debug.stats.expose")
@@ -267,10 +297,13 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 
 	// template paths
 	metricsPath := p.Config.GetString("modules.core.server.metrics.path")
+	versionPath := p.Config.GetString("modules.core.server.version.path")
 	startupPath := p.Config.GetString("modules.core.server.healthcheck.startup.path")
 	livenessPath := p.Config.GetString("modules.core.server.healthcheck.liveness.path")
 	readinessPath := p.Config.GetString("modules.core.server.healthcheck.readiness.path")
 	configPath := p.Config.GetString("modules.core.server.debug.config.path")
+	configDumpPath := p.Config.GetString("modules.config.dump.path")
+	logLevelPath := p.Config.GetString("modules.core.server.log_level.path")
 	pprofPath := p.Config.GetString("modules.core.server.debug.pprof.path")
 	routesPath := p.Config.GetString("modules.core.server.debug.routes.path")
 	statsPath := p.Config.GetString("modules.core.server.debug.stats.path")
@@ -288,6 +321,17 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 		coreServer.Logger.Debug("registered metrics handler")
 	}
 
+	// version
+	if versionExpose {
+		if versionPath == "" {
+			versionPath = DefaultVersionPath
+		}
+
+		coreServer.GET(versionPath, handler.DebugVersionHandler(p.Config))
+
+		coreServer.Logger.Debug("registered version handler")
+	}
+
 	// healthcheck startup
 	if startupExpose {
 		if startupPath == "" {
@@ -332,6 +376,44 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 		coreServer.Logger.Debug("registered debug config handler")
 	}
 
+	// config dump
+	if configDumpExpose {
+		if configDumpPath == "" {
+			configDumpPath = DefaultConfigDumpPath
+		}
+
+		coreServer.GET(configDumpPath, handler.DebugConfigDumpHandler(p.Config))
+
+		coreServer.Logger.Debug("registered config dump handler")
+
+		// the config dump is logged once, compacted on a single line
+		var dump, compacted bytes.Buffer
+		if err := p.Config.Dump(&dump, config.DumpFormatJson); err == nil && json.Compact(&compacted, dump.Bytes()) == nil {
+			p.Logger.Debug().RawJSON("config", compacted.Bytes()).Msg("config dump")
+		}
+	}
+
+	// log level
+	if logLevelExpose {
+		if logLevelPath == "" {
+			logLevelPath = DefaultLogLevelPath
+		}
+
+		var middlewares []echo.MiddlewareFunc
+		if token := p.Config.GetString("modules.core.server.log_level.token"); token != "" {
+			middlewares = append(middlewares, middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+				Validator: func(key string, c echo.Context) (bool, error) {
+					return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
+				},
+			}))
+		}
+
+		coreServer.GET(logLevelPath, handler.LogLevelHandler(p.Logger), middlewares...)
+		coreServer.PUT(logLevelPath, handler.LogLevelHandler(p.Logger), middlewares...)
+
+		coreServer.Logger.Debug("registered log level handler")
+	}
+
 	// debug pprof
 	if pprofExpose || appDebug {
 		if pprofPath == "" {
@@ -2,15 +2,19 @@ package fxcore_test
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/fxcore"
 	"github.com/ankorstore/yokai/fxcore/testdata/probes"
 	"github.com/ankorstore/yokai/fxhealthcheck"
 	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/ankorstore/yokai/trace/tracetest"
 	"github.com/prometheus/client_golang/prometheus"
@@ -125,6 +129,56 @@ func TestModuleWithMetricsEnabledAndCollected(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestModuleWithVersionEnabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_VERSION", "1.2.3")
+	t.Setenv("APP_BUILD_COMMIT", "abc123")
+	t.Setenv("VERSION_ENABLED", "true")
+
+	var core *fxcore.Core
+	var logBuffer logtest.TestLogBuffer
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core, &logBuffer))
+
+	// [GET] /version
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(
+		t,
+		`{"application":"core-app","version":"1.2.3","commit":"abc123","date":""}`,
+		rec.Body.String(),
+	)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "core-app",
+		"version": "1.2.3",
+		"module":  "core",
+		"uri":     "/version",
+		"status":  200,
+		"message": "request logger",
+	})
+}
+
+func TestModuleWithVersionDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("VERSION_ENABLED", "false")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	// [GET] /version
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
 func TestModuleWithHealthcheckDisabled(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 	t.Setenv("STARTUP_ENABLED", "false")
@@ -423,6 +477,224 @@ func TestModuleWithDebugConfigEnabled(t *testing.T) {
 	)
 }
 
+func TestModuleWithConfigDumpEnabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("CONFIG_DUMP_ENABLED", "true")
+	t.Setenv("DATABASE_PASSWORD", "s3cr3t-password")
+
+	var core *fxcore.Core
+	var logBuffer logtest.TestLogBuffer
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core, &logBuffer))
+
+	// [GET] /_config
+	req := httptest.NewRequest(http.MethodGet, "/_config", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name": "core-app"`)
+	assert.Contains(t, rec.Body.String(), `"password": "***"`)
+	assert.NotContains(t, rec.Body.String(), "s3cr3t-password")
+
+	// [GET] /_config as yaml
+	req = httptest.NewRequest(http.MethodGet, "/_config", nil)
+	req.Header.Set("Accept", "application/yaml")
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "password: '***'")
+	assert.NotContains(t, rec.Body.String(), "s3cr3t-password")
+
+	// startup dump log
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "debug",
+		"message": "config dump",
+	})
+
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+
+	for _, record := range records {
+		assert.NotContains(t, fmt.Sprintf("%v", record), "s3cr3t-password")
+	}
+}
+
+func TestModuleWithConfigDumpDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("CONFIG_DUMP_ENABLED", "false")
+	t.Setenv("APP_DEBUG", "false")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	// [GET] /_config
+	req := httptest.NewRequest(http.MethodGet, "/_config", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestModuleWithFlagsResolution(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var cfg *config.Config
+	var logBuffer logtest.TestLogBuffer
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&cfg, &logBuffer))
+
+	// configured flags resolved at startup
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"flag":    "core-flag",
+		"enabled": true,
+		"rollout": 100.0,
+		"variant": "blue",
+		"source":  config.FlagSourceConfig,
+		"message": "feature flag resolved",
+	})
+
+	// other flags resolved once on first read
+	assert.True(t, cfg.Flag("other-flag").WithDefault(true).Enabled(context.Background()))
+	assert.True(t, cfg.Flag("other-flag").WithDefault(true).Enabled(context.Background()))
+
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+
+	resolutions := 0
+	for _, record := range records {
+		if flag, err := record.Attribute("flag"); err == nil && flag == "other-flag" {
+			resolutions++
+		}
+	}
+
+	assert.Equal(t, 1, resolutions)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"flag":    "other-flag",
+		"enabled": true,
+		"source":  config.FlagSourceDefault,
+		"message": "feature flag resolved",
+	})
+}
+
+func TestModuleWithLogLevelEnabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("LOG_LEVEL_ENABLED", "true")
+	t.Setenv("LOG_LEVEL_TOKEN", "")
+
+	var core *fxcore.Core
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core, &logger, &logBuffer))
+
+	// [GET] /_log/level
+	req := httptest.NewRequest(http.MethodGet, "/_log/level", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"debug"}`, rec.Body.String())
+
+	// [PUT] /_log/level to info
+	req = httptest.NewRequest(http.MethodPut, "/_log/level", strings.NewReader(`{"level":"info"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info"}`, rec.Body.String())
+
+	logger.Debug().Msg("debug message while info")
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "debug",
+		"message": "debug message while info",
+	})
+
+	// [PUT] /_log/level back to debug
+	req = httptest.NewRequest(http.MethodPut, "/_log/level", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	logger.Debug().Msg("debug message while debug")
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "debug",
+		"message": "debug message while debug",
+	})
+
+	// [PUT] /_log/level with invalid level
+	req = httptest.NewRequest(http.MethodPut, "/_log/level", strings.NewReader(`{"level":"invalid"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestModuleWithLogLevelEnabledAndProtected(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("LOG_LEVEL_ENABLED", "true")
+	t.Setenv("LOG_LEVEL_TOKEN", "s3cr3t-token")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	// [PUT] /_log/level without token
+	req := httptest.NewRequest(http.MethodPut, "/_log/level", strings.NewReader(`{"level":"info"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// [PUT] /_log/level with invalid token
+	req = httptest.NewRequest(http.MethodPut, "/_log/level", strings.NewReader(`{"level":"info"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer invalid")
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// [PUT] /_log/level with valid token
+	req = httptest.NewRequest(http.MethodPut, "/_log/level", strings.NewReader(`{"level":"info"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer s3cr3t-token")
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info"}`, rec.Body.String())
+}
+
+func TestModuleWithLogLevelDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("LOG_LEVEL_ENABLED", "false")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	// [PUT] /_log/level
+	req := httptest.NewRequest(http.MethodPut, "/_log/level", strings.NewReader(`{"level":"info"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
 func TestModuleWithDebugPprofDisabled(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 	t.Setenv("PPROF_ENABLED", "false")
@@ -983,3 +1255,53 @@ func TestModuleDashboardTheme(t *testing.T) {
 		}
 	}
 }
+
+func TestModuleWithShutdownCoordinator(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("READINESS_ENABLED", "true")
+
+	var core *fxcore.Core
+	var events []string
+
+	readiness := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		rec := httptest.NewRecorder()
+		core.HttpServer().ServeHTTP(rec, req)
+
+		return fmt.Sprintf("readiness %d", rec.Code)
+	}
+
+	stage := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			events = append(events, readiness(), name)
+
+			return nil
+		}
+	}
+
+	app := fxcore.NewBootstrapper().BootstrapTestApp(
+		t,
+		fx.Invoke(func(lc fx.Lifecycle, coordinator *healthcheck.ShutdownCoordinator) {
+			// stages hooks registered in reverse order of the configured modules.core.shutdown.order
+			for _, name := range []string{"grpcserver", "httpserver"} {
+				name := name
+
+				coordinator.RegisterStage(name, stage(name))
+
+				lc.Append(fx.Hook{
+					OnStop: func(ctx context.Context) error {
+						return coordinator.StopStage(ctx, name)
+					},
+				})
+			}
+		}),
+		fx.Populate(&core),
+	).RequireStart()
+
+	assert.Equal(t, "readiness 200", readiness())
+
+	app.RequireStop()
+
+	// readiness failing first, then http server stopped, then grpc server stopped
+	assert.Equal(t, []string{"readiness 500", "httpserver", "readiness 500", "grpcserver"}, events)
+}
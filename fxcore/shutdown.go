@@ -0,0 +1,49 @@
+package fxcore
+
+import (
+	"fmt"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"go.uber.org/fx"
+)
+
+// FxShutdownCoordinatorParam allows injection of the required dependencies in [NewFxShutdownCoordinator].
+type FxShutdownCoordinatorParam struct {
+	fx.In
+	Config  *config.Config
+	Checker *healthcheck.Checker
+	Logger  *log.Logger
+}
+
+// NewFxShutdownCoordinator returns a new [healthcheck.ShutdownCoordinator], shared by the servers modules (like the
+// http and grpc ones) to stop in the modules.core.shutdown.order sequence, after failing the readiness checks.
+func NewFxShutdownCoordinator(p FxShutdownCoordinatorParam) (*healthcheck.ShutdownCoordinator, error) {
+	readinessDelay, err := p.Config.GetDuration("modules.core.shutdown.readiness_delay")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shutdown coordinator: %w", err)
+	}
+
+	stageDelay, err := p.Config.GetDuration("modules.core.shutdown.stage_delay")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shutdown coordinator: %w", err)
+	}
+
+	coordinator := healthcheck.NewShutdownCoordinator(healthcheck.ShutdownConfig{
+		Order:          p.Config.GetStringSlice("modules.core.shutdown.order"),
+		ReadinessDelay: readinessDelay,
+		StageDelay:     stageDelay,
+	})
+
+	// fails the readiness as soon as the shutdown starts
+	p.Checker.RegisterProbe(coordinator, healthcheck.Readiness)
+
+	p.Logger.Debug().
+		Strs("order", p.Config.GetStringSlice("modules.core.shutdown.order")).
+		Dur("readiness_delay", readinessDelay).
+		Dur("stage_delay", stageDelay).
+		Msg("shutdown coordinator created")
+
+	return coordinator, nil
+}
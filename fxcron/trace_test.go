@@ -9,9 +9,11 @@ import (
 	"github.com/stretchr/testify/mock"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
 )
 
 type tracerProviderMock struct {
+	embedded.TracerProvider
 	mock.Mock
 }
 
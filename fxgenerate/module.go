@@ -1,6 +1,7 @@
 package fxgenerate
 
 import (
+	"github.com/ankorstore/yokai/generate/id"
 	"github.com/ankorstore/yokai/generate/uuid"
 	"go.uber.org/fx"
 )
@@ -16,6 +17,7 @@ var FxGenerateModule = fx.Module(
 	fx.Provide(
 		uuid.NewDefaultUuidGeneratorFactory,
 		NewFxUuidGenerator,
+		NewFxIdGenerator,
 	),
 )
 
@@ -29,3 +31,17 @@ type FxUuidGeneratorParam struct {
 func NewFxUuidGenerator(p FxUuidGeneratorParam) (uuid.UuidGenerator, error) {
 	return p.Factory.Create(), nil
 }
+
+// FxIdGeneratorParam allows injection of the required dependencies in [NewFxIdGenerator].
+type FxIdGeneratorParam struct {
+	fx.In
+	Generator uuid.UuidGenerator
+}
+
+// NewFxIdGenerator returns an [id.IdGenerator], using by default the [uuid.UuidGenerator].
+//
+// It can be overridden (for example with fx.Decorate) to provide another identifiers format, for example for the
+// requests ids of the http and gRPC servers.
+func NewFxIdGenerator(p FxIdGeneratorParam) id.IdGenerator {
+	return p.Generator
+}
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/ankorstore/yokai/fxgenerate"
+	testid "github.com/ankorstore/yokai/fxgenerate/testdata/id"
 	testuuid "github.com/ankorstore/yokai/fxgenerate/testdata/uuid"
+	"github.com/ankorstore/yokai/generate/id"
 	"github.com/ankorstore/yokai/generate/uuid"
 	googleuuid "github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -56,3 +58,54 @@ func TestModuleUuidGeneratorDecoration(t *testing.T) {
 
 	assert.Equal(t, "static", generator.Generate())
 }
+
+func TestModuleIdGenerator(t *testing.T) {
+	t.Parallel()
+
+	var generator id.IdGenerator
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxgenerate.FxGenerateModule,
+		fx.Populate(&generator),
+	).RequireStart().RequireStop()
+
+	value := generator.Generate()
+
+	parsedValue, err := googleuuid.Parse(value)
+	assert.NoError(t, err)
+	assert.Equal(t, value, parsedValue.String())
+}
+
+func TestModuleIdGeneratorWithUuidGeneratorDecoration(t *testing.T) {
+	t.Parallel()
+
+	var generator id.IdGenerator
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxgenerate.FxGenerateModule,
+		fx.Decorate(testuuid.NewTestStaticUuidGeneratorFactory),
+		fx.Populate(&generator),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, "static", generator.Generate())
+}
+
+func TestModuleIdGeneratorDecoration(t *testing.T) {
+	t.Parallel()
+
+	var generator id.IdGenerator
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxgenerate.FxGenerateModule,
+		fx.Decorate(testid.NewTestIdGenerator),
+		fx.Populate(&generator),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, "custom-id", generator.Generate())
+}
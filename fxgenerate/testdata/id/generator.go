@@ -0,0 +1,13 @@
+package id
+
+import "github.com/ankorstore/yokai/generate/id"
+
+type TestIdGenerator struct{}
+
+func NewTestIdGenerator() id.IdGenerator {
+	return &TestIdGenerator{}
+}
+
+func (g *TestIdGenerator) Generate() string {
+	return "custom-id"
+}
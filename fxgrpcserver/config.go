@@ -0,0 +1,116 @@
+package fxgrpcserver
+
+import (
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/config/listener"
+)
+
+// serverConfig is the typed modules.grpc.server config.
+type serverConfig struct {
+	Port        int               `mapstructure:"port"`
+	Log         logConfig         `mapstructure:"log"`
+	Recovery    recoveryConfig    `mapstructure:"recovery"`
+	Concurrency concurrencyConfig `mapstructure:"concurrency"`
+	HealthCheck healthCheckConfig `mapstructure:"healthcheck"`
+	Test        testConfig        `mapstructure:"test"`
+	Listener    listener.Config   `mapstructure:"listener"`
+	Validation  validationConfig  `mapstructure:"validation"`
+}
+
+// validationConfig is the typed modules.grpc.server.validation config.
+type validationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// logConfig is the typed modules.grpc.server.log config.
+type logConfig struct {
+	Enabled bool              `mapstructure:"enabled"`
+	Levels  map[string]string `mapstructure:"levels"`
+	Fields  map[string]string `mapstructure:"fields"`
+}
+
+// recoveryConfig is the typed modules.grpc.server.recovery config.
+type recoveryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// concurrencyConfig is the typed modules.grpc.server.concurrency config.
+type concurrencyConfig struct {
+	Limit   int                       `mapstructure:"limit"`
+	Methods []concurrencyMethodConfig `mapstructure:"methods"`
+}
+
+// concurrencyMethodConfig is the configuration of a gRPC method concurrency limit.
+type concurrencyMethodConfig struct {
+	Method string `mapstructure:"method"`
+	Limit  int    `mapstructure:"limit"`
+}
+
+// healthCheckConfig is the typed modules.grpc.server.healthcheck config.
+type healthCheckConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	WatchInterval time.Duration `mapstructure:"watch_interval"`
+}
+
+// testConfig is the typed modules.grpc.server.test config.
+type testConfig struct {
+	Bufconn struct {
+		Size config.ByteSize `mapstructure:"size"`
+	} `mapstructure:"bufconn"`
+	Tcp struct {
+		Enabled bool `mapstructure:"enabled"`
+	} `mapstructure:"tcp"`
+}
+
+// configuredServer decodes the modules.grpc.server config, with its defaults.
+func configuredServer(cfg *config.Config) (serverConfig, error) {
+	serverCfg := serverConfig{
+		Port:     DefaultPort,
+		Log:      logConfig{Enabled: true},
+		Recovery: recoveryConfig{Enabled: true},
+	}
+	serverCfg.Test.Bufconn.Size = DefaultBufconnSize
+
+	if err := cfg.UnmarshalKey("modules.grpc.server", &serverCfg); err != nil {
+		return serverConfig{}, err
+	}
+
+	return serverCfg, nil
+}
+
+// configSchema returns the modules.grpc.server config schema, to report the unknown keys (like typos) and invalid
+// values when the application starts.
+func configSchema() *config.Schema {
+	return config.NewSchema(
+		"modules.grpc.server",
+		config.SchemaKey{Path: "enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "port", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "listener.backlog", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "listener.reuse_port", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "request_id.metadata_key", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "recovery.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "log.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "log.metadata", Type: config.SchemaTypeMap},
+		config.SchemaKey{Path: "log.exclude", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "log.levels", Type: config.SchemaTypeMap},
+		config.SchemaKey{Path: "log.fields", Type: config.SchemaTypeMap},
+		config.SchemaKey{Path: "log.sample_rate", Type: config.SchemaTypeFloat},
+		config.SchemaKey{Path: "trace.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "trace.exclude", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "metrics.collect.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "metrics.collect.namespace", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "metrics.collect.subsystem", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "metrics.buckets", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "concurrency.limit", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "concurrency.methods", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "methods.*.max_recv", Type: config.SchemaTypeByteSize},
+		config.SchemaKey{Path: "validation.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "reflection.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "healthcheck.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "healthcheck.watch_interval", Type: config.SchemaTypeDuration},
+		config.SchemaKey{Path: "test.bufconn.size", Type: config.SchemaTypeByteSize},
+		config.SchemaKey{Path: "test.tcp.enabled", Type: config.SchemaTypeBool},
+	)
+}
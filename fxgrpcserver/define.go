@@ -1,6 +1,10 @@
 package fxgrpcserver
 
-import "google.golang.org/grpc"
+import (
+	"github.com/ankorstore/yokai/grpcserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
 
 type GrpcServiceDefinition interface {
 	ReturnType() string
@@ -26,3 +30,35 @@ func (d *grpcServiceDefinition) ReturnType() string {
 func (d *grpcServiceDefinition) Description() *grpc.ServiceDesc {
 	return d.description
 }
+
+type ErrorMappingDefinition interface {
+	Matcher() grpcserver.ErrorMatcher
+	Code() codes.Code
+	Position() int64
+}
+
+type errorMappingDefinition struct {
+	matcher  grpcserver.ErrorMatcher
+	code     codes.Code
+	position int64
+}
+
+func NewErrorMappingDefinition(matcher grpcserver.ErrorMatcher, code codes.Code, position int64) ErrorMappingDefinition {
+	return &errorMappingDefinition{
+		matcher:  matcher,
+		code:     code,
+		position: position,
+	}
+}
+
+func (d *errorMappingDefinition) Matcher() grpcserver.ErrorMatcher {
+	return d.matcher
+}
+
+func (d *errorMappingDefinition) Code() codes.Code {
+	return d.code
+}
+
+func (d *errorMappingDefinition) Position() int64 {
+	return d.position
+}
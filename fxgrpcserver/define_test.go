@@ -1,11 +1,14 @@
 package fxgrpcserver_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/ankorstore/yokai/fxgrpcserver"
 	"github.com/ankorstore/yokai/fxgrpcserver/testdata/proto"
+	"github.com/ankorstore/yokai/grpcserver"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 )
 
 func TestNewGrpcServiceDefinition(t *testing.T) {
@@ -17,3 +20,16 @@ func TestNewGrpcServiceDefinition(t *testing.T) {
 	assert.Equal(t, "*TestService", definition.ReturnType())
 	assert.Equal(t, &proto.Service_ServiceDesc, definition.Description())
 }
+
+func TestNewErrorMappingDefinition(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	definition := fxgrpcserver.NewErrorMappingDefinition(grpcserver.MatchErrorIs(errTest), codes.NotFound, 1)
+
+	assert.Implements(t, (*fxgrpcserver.ErrorMappingDefinition)(nil), definition)
+	assert.True(t, definition.Matcher()(errTest))
+	assert.Equal(t, codes.NotFound, definition.Code())
+	assert.Equal(t, int64(1), definition.Position())
+}
@@ -0,0 +1,45 @@
+package fxgrpcserver
+
+import (
+	"net"
+	"sync"
+)
+
+// GrpcServerListenerAddr exposes the address the gRPC server listener is bound to, once the application is started.
+//
+// It allows for example the tests using a real network socket (see modules.grpc.server.test.tcp.enabled) to dial
+// the ephemeral port chosen at startup.
+type GrpcServerListenerAddr struct {
+	mutex sync.RWMutex
+	addr  net.Addr
+}
+
+// NewFxGrpcServerListenerAddr returns a new [GrpcServerListenerAddr], set when the gRPC server starts.
+func NewFxGrpcServerListenerAddr() *GrpcServerListenerAddr {
+	return &GrpcServerListenerAddr{}
+}
+
+// Addr returns the address the gRPC server listener is bound to, nil if the gRPC server is not started.
+func (a *GrpcServerListenerAddr) Addr() net.Addr {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.addr
+}
+
+// Port returns the TCP port the gRPC server listener is bound to, 0 if the gRPC server is not started or is
+// listening on a bufconn listener.
+func (a *GrpcServerListenerAddr) Port() int {
+	if tcpAddr, ok := a.Addr().(*net.TCPAddr); ok {
+		return tcpAddr.Port
+	}
+
+	return 0
+}
+
+func (a *GrpcServerListenerAddr) set(addr net.Addr) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.addr = addr
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/ankorstore/yokai/config"
-	"github.com/ankorstore/yokai/generate/uuid"
+	"github.com/ankorstore/yokai/config/listener"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/generate/id"
 	"github.com/ankorstore/yokai/grpcserver"
 	"github.com/ankorstore/yokai/grpcserver/grpcservertest"
 	"github.com/ankorstore/yokai/healthcheck"
@@ -16,11 +19,13 @@ import (
 	grpcprom "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters/interceptor"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/test/bufconn"
 )
@@ -36,7 +41,9 @@ var FxGrpcServerModule = fx.Module(
 	fx.Provide(
 		grpcserver.NewDefaultGrpcServerFactory,
 		NewFxGrpcBufconnListener,
+		NewFxGrpcServerListenerAddr,
 		NewFxGrpcServerRegistry,
+		NewFxGrpcHealthCheckService,
 		NewFxGrpcServer,
 		fx.Annotate(
 			NewFxGrpcServerModuleInfo,
@@ -44,6 +51,7 @@ var FxGrpcServerModule = fx.Module(
 			fx.ResultTags(`group:"core-module-infos"`),
 		),
 	),
+	fxconfig.AsConfigSchema(configSchema()),
 )
 
 type FxGrpcBufconnListenerParam struct {
@@ -51,32 +59,65 @@ type FxGrpcBufconnListenerParam struct {
 	Config *config.Config
 }
 
-func NewFxGrpcBufconnListener(p FxGrpcBufconnListenerParam) *bufconn.Listener {
-	size := p.Config.GetInt("modules.grpc.server.test.bufconn.size")
+func NewFxGrpcBufconnListener(p FxGrpcBufconnListenerParam) (*bufconn.Listener, error) {
+	serverCfg, err := configuredServer(p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc bufconn listener: %w", err)
+	}
+
+	size := int(serverCfg.Test.Bufconn.Size)
 	if size == 0 {
 		size = DefaultBufconnSize
 	}
 
-	return grpcservertest.NewBufconnListener(size)
+	return grpcservertest.NewBufconnListener(size), nil
+}
+
+type FxGrpcHealthCheckServiceParam struct {
+	fx.In
+	Config  *config.Config
+	Checker *healthcheck.Checker
+}
+
+func NewFxGrpcHealthCheckService(p FxGrpcHealthCheckServiceParam) (*grpcserver.GrpcHealthCheckService, error) {
+	serverCfg, err := configuredServer(p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc healthcheck service: %w", err)
+	}
+
+	return grpcserver.NewGrpcHealthCheckService(p.Checker).WatchInterval(serverCfg.HealthCheck.WatchInterval), nil
 }
 
 type FxGrpcServerParam struct {
 	fx.In
 	LifeCycle       fx.Lifecycle
 	Factory         grpcserver.GrpcServerFactory
-	Generator       uuid.UuidGenerator
+	Generator       id.IdGenerator
 	Listener        *bufconn.Listener
+	ListenerAddr    *GrpcServerListenerAddr
 	Registry        *GrpcServerRegistry
 	Config          *config.Config
 	Logger          *log.Logger
-	Checker         *healthcheck.Checker
+	AuditLogger     *log.AuditLogger `optional:"true"`
+	HealthCheck     *grpcserver.GrpcHealthCheckService
 	TracerProvider  trace.TracerProvider
 	MetricsRegistry *prometheus.Registry
+	Shutdown        *healthcheck.ShutdownCoordinator `optional:"true"`
+	ErrorMappings   []ErrorMappingDefinition         `group:"grpc-server-error-mappings"`
 }
 
 func NewFxGrpcServer(p FxGrpcServerParam) (*grpc.Server, error) {
+	// typed config
+	serverCfg, err := configuredServer(p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc server: %w", err)
+	}
+
 	// server interceptors
-	unaryInterceptors, streamInterceptors := createInterceptors(p)
+	unaryInterceptors, streamInterceptors, err := createInterceptors(serverCfg, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc server: %w", err)
+	}
 
 	// server options
 	grpcServerOptions := []grpc.ServerOption{
@@ -96,8 +137,8 @@ func NewFxGrpcServer(p FxGrpcServerParam) (*grpc.Server, error) {
 	}
 
 	// healthcheck
-	if p.Config.GetBool("modules.grpc.server.healthcheck.enabled") {
-		grpcServer.RegisterService(&grpc_health_v1.Health_ServiceDesc, grpcserver.NewGrpcHealthCheckService(p.Checker))
+	if serverCfg.HealthCheck.Enabled {
+		grpcServer.RegisterService(&grpc_health_v1.Health_ServiceDesc, p.HealthCheck)
 	}
 
 	// registrations
@@ -110,26 +151,58 @@ func NewFxGrpcServer(p FxGrpcServerParam) (*grpc.Server, error) {
 		grpcServer.RegisterService(service.Description(), service.Implementation())
 	}
 
-	// lifecycles
+	// lifecycles, not serving if disabled (the server stays constructable, for example for tests)
+	if !isServerEnabled(p.Config) {
+		p.Logger.Info().Msg("grpc server disabled, not serving")
+
+		return grpcServer, nil
+	}
+
+	stop := func() {
+		switch {
+		case p.Config.IsTestEnv() && serverCfg.Test.Tcp.Enabled:
+			grpcServer.Stop()
+		case !p.Config.IsTestEnv():
+			// report NOT_SERVING to the health clients while draining
+			p.HealthCheck.Shutdown()
+
+			grpcServer.GracefulStop()
+		}
+	}
+
 	p.LifeCycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			port := p.Config.GetInt("modules.grpc.server.port")
+			port := serverCfg.Port
 			if port == 0 {
 				port = DefaultPort
 			}
 
-			go func() {
-				var lis net.Listener
-				if p.Config.IsTestEnv() {
-					lis = p.Listener
-				} else {
-					lis, err = net.Listen("tcp", fmt.Sprintf(":%d", port))
-					if err != nil {
-						p.Logger.Error().Err(err).Msgf("failed to listen on %d for grpc server", port)
-					}
+			// listener bound before serving, to fail the startup if the port is already in use
+			var lis net.Listener
+			switch {
+			case p.Config.IsTestEnv() && serverCfg.Test.Tcp.Enabled:
+				// real network socket on an ephemeral port, for the tests needing it (TLS handshakes, proxies, ...)
+				tcpLis, err := listener.Listen(ctx, "127.0.0.1:0", listener.Config{})
+				if err != nil {
+					return fmt.Errorf("failed to listen on ephemeral port for grpc server: %w", err)
 				}
 
-				if err = grpcServer.Serve(lis); err != nil {
+				lis = tcpLis
+			case p.Config.IsTestEnv():
+				lis = p.Listener
+			default:
+				tcpLis, err := listener.Listen(ctx, fmt.Sprintf(":%d", port), serverCfg.Listener)
+				if err != nil {
+					return fmt.Errorf("failed to listen on %d for grpc server: %w", port, err)
+				}
+
+				lis = tcpLis
+			}
+
+			p.ListenerAddr.set(lis.Addr())
+
+			go func() {
+				if err := grpcServer.Serve(lis); err != nil {
 					p.Logger.Error().Err(err).Msg("failed to serve grpc server")
 				}
 			}()
@@ -137,54 +210,81 @@ func NewFxGrpcServer(p FxGrpcServerParam) (*grpc.Server, error) {
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			if !p.Config.IsTestEnv() {
-				grpcServer.GracefulStop()
+			// coordinated with the other servers, the readiness being failed first
+			if p.Shutdown != nil {
+				return p.Shutdown.StopStage(ctx, ModuleName)
 			}
 
+			stop()
+
 			return nil
 		},
 	})
 
+	if p.Shutdown != nil {
+		p.Shutdown.RegisterStage(ModuleName, func(context.Context) error {
+			stop()
+
+			return nil
+		})
+	}
+
 	return grpcServer, nil
 }
 
 //nolint:cyclop
-func createInterceptors(p FxGrpcServerParam) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+func createInterceptors(serverCfg serverConfig, p FxGrpcServerParam) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor, error) {
+	// interceptors
+	var unaryInterceptors []grpc.UnaryServerInterceptor
+	var streamInterceptors []grpc.StreamServerInterceptor
+
 	// panic recovery
-	panicRecoveryHandler := grpcserver.NewGrpcPanicRecoveryHandler()
+	if serverCfg.Recovery.Enabled {
+		panicRecoveryHandler := grpcserver.NewGrpcPanicRecoveryHandler()
 
-	// interceptors
-	unaryInterceptors := []grpc.UnaryServerInterceptor{
-		recovery.UnaryServerInterceptor(
-			recovery.WithRecoveryHandlerContext(panicRecoveryHandler.Handle(p.Config.AppDebug())),
-		),
+		unaryInterceptors = append(
+			unaryInterceptors,
+			recovery.UnaryServerInterceptor(
+				recovery.WithRecoveryHandlerContext(panicRecoveryHandler.Handle(p.Config.AppDebug())),
+			),
+		)
+		streamInterceptors = append(
+			streamInterceptors,
+			recovery.StreamServerInterceptor(
+				recovery.WithRecoveryHandlerContext(panicRecoveryHandler.Handle(p.Config.AppDebug())),
+			),
+		)
 	}
 
-	streamInterceptors := []grpc.StreamServerInterceptor{
-		recovery.StreamServerInterceptor(
-			recovery.WithRecoveryHandlerContext(panicRecoveryHandler.Handle(p.Config.AppDebug())),
-		),
+	// audit logger
+	if p.AuditLogger != nil {
+		auditLoggerInterceptor := grpcserver.NewGrpcAuditLoggerInterceptor(p.AuditLogger)
+
+		unaryInterceptors = append(unaryInterceptors, auditLoggerInterceptor.UnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, auditLoggerInterceptor.StreamInterceptor())
 	}
 
 	// tracer
 	if p.Config.GetBool("modules.grpc.server.trace.enabled") {
-		var methodFilters []otelgrpc.Filter
+		var methodFilters []otelgrpc.InterceptorFilter
 		for _, method := range p.Config.GetStringSlice("modules.grpc.server.trace.exclude") {
-			methodFilters = append(methodFilters, filters.FullMethodName(method))
+			methodFilters = append(methodFilters, interceptor.FullMethodName(method))
 		}
 
+		//nolint:staticcheck // deprecated in favor of the stats handlers
 		unaryInterceptors = append(
 			unaryInterceptors,
 			otelgrpc.UnaryServerInterceptor(
 				otelgrpc.WithTracerProvider(p.TracerProvider),
-				otelgrpc.WithInterceptorFilter(filters.None(methodFilters...)),
+				otelgrpc.WithInterceptorFilter(interceptor.None(methodFilters...)),
 			),
 		)
+		//nolint:staticcheck // deprecated in favor of the stats handlers
 		streamInterceptors = append(
 			streamInterceptors,
 			otelgrpc.StreamServerInterceptor(
 				otelgrpc.WithTracerProvider(p.TracerProvider),
-				otelgrpc.WithInterceptorFilter(filters.None(methodFilters...)),
+				otelgrpc.WithInterceptorFilter(interceptor.None(methodFilters...)),
 			),
 		)
 	}
@@ -192,25 +292,30 @@ func createInterceptors(p FxGrpcServerParam) ([]grpc.UnaryServerInterceptor, []g
 	// logger
 	loggerInterceptor := grpcserver.
 		NewGrpcLoggerInterceptor(p.Generator, log.FromZerolog(p.Logger.ToZerolog().With().Str("system", ModuleName).Logger())).
+		RequestIdMetadataKey(p.Config.GetString("modules.grpc.server.request_id.metadata_key")).
 		Metadata(p.Config.GetStringMapString("modules.grpc.server.log.metadata")).
-		Exclude(p.Config.GetStringSlice("modules.grpc.server.log.exclude")...)
+		Exclude(p.Config.GetStringSlice("modules.grpc.server.log.exclude")...).
+		LogLevels(configuredLogLevels(serverCfg.Log.Levels, p.Logger)).
+		FieldNames(configuredLogFieldNames(serverCfg.Log.Fields))
+
+	// the gRPC calls logs can be sampled with a different rate than the application logs (errors are never sampled)
+	if p.Config.IsSet("modules.grpc.server.log.sample_rate") {
+		loggerInterceptor.Sampler(log.NewSamplerWithConfig(log.SamplerConfig{
+			Default: log.NewRatioSampler(p.Config.GetFloat64("modules.grpc.server.log.sample_rate")),
+		}))
+	}
+
+	// the request id is handled by the logger interceptor, kept without logs
+	if !serverCfg.Log.Enabled {
+		loggerInterceptor.DisableLogs()
+	}
 
 	unaryInterceptors = append(unaryInterceptors, loggerInterceptor.UnaryInterceptor())
 	streamInterceptors = append(streamInterceptors, loggerInterceptor.StreamInterceptor())
 
 	// metrics
 	if p.Config.GetBool("modules.grpc.server.metrics.collect.enabled") {
-		namespace := p.Config.GetString("modules.grpc.server.metrics.collect.namespace")
-		if namespace == "" {
-			namespace = p.Config.AppName()
-		}
-
-		subsystem := p.Config.GetString("modules.grpc.server.metrics.collect.subsystem")
-		if subsystem == "" {
-			subsystem = ModuleName
-		}
-
-		grpcSrvMetricsSubsystem := strings.ReplaceAll(fmt.Sprintf("%s_%s", namespace, subsystem), "-", "_")
+		grpcSrvMetricsSubsystem := metricsSubsystem(p)
 
 		var grpcSrvMetricsBuckets []float64
 		if bucketsConfig := p.Config.GetString("modules.grpc.server.metrics.buckets"); bucketsConfig != "" {
@@ -260,5 +365,184 @@ func createInterceptors(p FxGrpcServerParam) ([]grpc.UnaryServerInterceptor, []g
 		)
 	}
 
-	return unaryInterceptors, streamInterceptors
+	// concurrency limiter
+	concurrencyLimit := serverCfg.Concurrency.Limit
+	if concurrencyLimit > 0 || len(serverCfg.Concurrency.Methods) > 0 {
+		methodLimits := map[string]int{}
+		for _, methodConfig := range serverCfg.Concurrency.Methods {
+			methodLimits[methodConfig.Method] = methodConfig.Limit
+		}
+
+		limiterInterceptor := grpcserver.
+			NewGrpcConcurrencyLimiterInterceptor(concurrencyLimit).
+			MethodLimits(methodLimits).
+			Metrics(p.MetricsRegistry, "", metricsSubsystem(p))
+
+		unaryInterceptors = append(unaryInterceptors, limiterInterceptor.UnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, limiterInterceptor.StreamInterceptor())
+	}
+
+	// message size
+	methodsMaxRecv, err := configuredMethodsMaxRecv(p.Config, p.Config.GetStringMap("modules.grpc.server.methods"), "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(methodsMaxRecv) > 0 || p.Config.GetBool("modules.grpc.server.metrics.collect.enabled") {
+		messageSizeInterceptor := grpcserver.
+			NewGrpcMessageSizeInterceptor().
+			MethodLimits(methodsMaxRecv)
+
+		if p.Config.GetBool("modules.grpc.server.metrics.collect.enabled") {
+			messageSizeInterceptor.Metrics(p.MetricsRegistry, "", metricsSubsystem(p))
+		}
+
+		unaryInterceptors = append(unaryInterceptors, messageSizeInterceptor.UnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, messageSizeInterceptor.StreamInterceptor())
+	}
+
+	// validation
+	if serverCfg.Validation.Enabled {
+		validationInterceptor := grpcserver.NewGrpcValidationInterceptor()
+
+		unaryInterceptors = append(unaryInterceptors, validationInterceptor.UnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, validationInterceptor.StreamInterceptor())
+	}
+
+	// error mappings, innermost for the other interceptors to observe the converted status
+	if len(p.ErrorMappings) > 0 {
+		errorMappingDefinitions := append([]ErrorMappingDefinition{}, p.ErrorMappings...)
+		sort.SliceStable(errorMappingDefinitions, func(i, j int) bool {
+			return errorMappingDefinitions[i].Position() < errorMappingDefinitions[j].Position()
+		})
+
+		errorRegistry := grpcserver.NewGrpcErrorRegistry()
+		for _, errorMappingDefinition := range errorMappingDefinitions {
+			errorRegistry.Register(errorMappingDefinition.Matcher(), errorMappingDefinition.Code())
+		}
+
+		errorInterceptor := grpcserver.NewGrpcErrorInterceptor(errorRegistry).Debug(p.Config.AppDebug())
+
+		unaryInterceptors = append(unaryInterceptors, errorInterceptor.UnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, errorInterceptor.StreamInterceptor())
+	}
+
+	return unaryInterceptors, streamInterceptors, nil
+}
+
+// configuredMethodsMaxRecv returns the max_recv per method name of the methods configuration: the method names
+// containing dots (like /package.Service/Method) being split in nested keys by the config, they are joined back.
+func configuredMethodsMaxRecv(cfg *config.Config, methodsConfig map[string]interface{}, prefix string) (map[string]int, error) {
+	methodsMaxRecv := map[string]int{}
+
+	for key, value := range methodsConfig {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name := key
+		if prefix != "" {
+			name = fmt.Sprintf("%s.%s", prefix, key)
+		}
+
+		if _, ok := nested["max_recv"]; ok {
+			maxRecv, err := cfg.GetBytes(fmt.Sprintf("modules.grpc.server.methods.%s.max_recv", name))
+			if err != nil {
+				return nil, err
+			}
+
+			methodsMaxRecv[name] = int(maxRecv)
+
+			continue
+		}
+
+		nestedMaxRecv, err := configuredMethodsMaxRecv(cfg, nested, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for method, limit := range nestedMaxRecv {
+			methodsMaxRecv[method] = limit
+		}
+	}
+
+	return methodsMaxRecv, nil
+}
+
+// configuredLogLevels returns the log levels per gRPC status code of the levels configuration, keyed by status code
+// names (like NotFound, or not_found): the unknown status codes are reported and ignored.
+func configuredLogLevels(levelsConfig map[string]string, logger *log.Logger) map[codes.Code]zerolog.Level {
+	levels := map[codes.Code]zerolog.Level{}
+
+	for name, level := range levelsConfig {
+		code, ok := fetchCode(name)
+		if !ok {
+			logger.Error().Msgf("invalid grpc server log level status code %s", name)
+
+			continue
+		}
+
+		levels[code] = log.FetchLogLevel(strings.ToLower(level))
+	}
+
+	return levels
+}
+
+// configuredLogFieldNames returns the logged fields renaming of the fields configuration, matching the default field
+// names case-insensitively (the config keys being lower cased).
+func configuredLogFieldNames(fieldsConfig map[string]string) map[string]string {
+	defaultNames := []string{
+		grpcserver.LogFieldGrpcType,
+		grpcserver.LogFieldGrpcMethod,
+		grpcserver.LogFieldGrpcCode,
+		grpcserver.LogFieldGrpcStatus,
+		grpcserver.LogFieldGrpcDuration,
+		grpcserver.LogFieldTraceId,
+		grpcserver.LogFieldSpanId,
+	}
+
+	names := map[string]string{}
+	for name, renamed := range fieldsConfig {
+		for _, defaultName := range defaultNames {
+			if strings.EqualFold(name, defaultName) {
+				names[defaultName] = renamed
+			}
+		}
+	}
+
+	return names
+}
+
+// fetchCode returns the gRPC status code of a name, ignoring the case and underscores.
+func fetchCode(name string) (codes.Code, bool) {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+	}
+
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		if normalize(code.String()) == normalize(name) {
+			return code, true
+		}
+	}
+
+	return codes.OK, false
+}
+
+func isServerEnabled(cfg *config.Config) bool {
+	return !cfg.IsSet("modules.grpc.server.enabled") || cfg.GetBool("modules.grpc.server.enabled")
+}
+
+func metricsSubsystem(p FxGrpcServerParam) string {
+	namespace := p.Config.GetString("modules.grpc.server.metrics.collect.namespace")
+	if namespace == "" {
+		namespace = p.Config.AppName()
+	}
+
+	subsystem := p.Config.GetString("modules.grpc.server.metrics.collect.subsystem")
+	if subsystem == "" {
+		subsystem = ModuleName
+	}
+
+	return strings.ReplaceAll(fmt.Sprintf("%s_%s", namespace, subsystem), "-", "_")
 }
@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ankorstore/yokai/config/listener"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxgenerate"
 	"github.com/ankorstore/yokai/fxgrpcserver"
 	"github.com/ankorstore/yokai/fxgrpcserver/testdata/factory"
+	"github.com/ankorstore/yokai/fxgrpcserver/testdata/generator"
 	"github.com/ankorstore/yokai/fxgrpcserver/testdata/probes"
 	"github.com/ankorstore/yokai/fxgrpcserver/testdata/proto"
 	"github.com/ankorstore/yokai/fxgrpcserver/testdata/service"
@@ -20,6 +25,7 @@ import (
 	"github.com/ankorstore/yokai/fxlog"
 	"github.com/ankorstore/yokai/fxmetrics"
 	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/grpcserver"
 	"github.com/ankorstore/yokai/healthcheck"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/ankorstore/yokai/trace/tracetest"
@@ -28,10 +34,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 )
 
@@ -399,6 +408,814 @@ func TestModuleHealthCheck(t *testing.T) {
 	assert.True(t, traceExporter.HasSpan("grpc.health.v1.Health/Check"))
 }
 
+func TestModuleWithTestTcpListener(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_TEST_TCP_ENABLED", "true")
+
+	var grpcServer *grpc.Server
+	var listenerAddr *fxgrpcserver.GrpcServerListenerAddr
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fxhealthcheck.AsCheckerProbe(probes.NewSuccessProbe),
+		fx.Populate(&grpcServer, &listenerAddr),
+	).RequireStart()
+	defer app.RequireStop()
+
+	// ephemeral port, not the configured one
+	port := listenerAddr.Port()
+	assert.NotZero(t, port)
+	assert.NotEqual(t, fxgrpcserver.DefaultPort, port)
+
+	// client preparation, on the real network socket
+	conn, err := grpc.DialContext(
+		context.Background(),
+		fmt.Sprintf("127.0.0.1:%d", port),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	response, err := grpc_health_v1.NewHealthClient(conn).Check(
+		context.Background(),
+		&grpc_health_v1.HealthCheckRequest{Service: "test::readiness"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+}
+
+func TestModuleWithShutdownCoordinator(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_TEST_TCP_ENABLED", "true")
+
+	var grpcServer *grpc.Server
+	var listenerAddr *fxgrpcserver.GrpcServerListenerAddr
+	var events []string
+
+	coordinator := healthcheck.NewShutdownCoordinator(healthcheck.ShutdownConfig{
+		Order: []string{fxgrpcserver.ModuleName, "next"},
+	})
+
+	// registered before the grpc server stage, but stopped after it
+	coordinator.RegisterStage("next", func(ctx context.Context) error {
+		_, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", listenerAddr.Port()), time.Second)
+
+		events = append(events, fmt.Sprintf("next stopped, grpc server serving: %v", err == nil))
+
+		return nil
+	})
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Supply(coordinator),
+		fx.Populate(&grpcServer, &listenerAddr),
+	).RequireStart()
+
+	var stages []string
+	for _, stage := range coordinator.Stages() {
+		stages = append(stages, stage.Name)
+	}
+
+	assert.Equal(t, []string{fxgrpcserver.ModuleName, "next"}, stages)
+
+	app.RequireStop()
+
+	assert.True(t, coordinator.Stopping())
+	assert.Equal(t, []string{"next stopped, grpc server serving: false"}, events)
+}
+
+func TestModuleHealthCheckWatch(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_HEALTHCHECK_WATCH_INTERVAL", "0.01")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var healthCheckService *grpcserver.GrpcHealthCheckService
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Options(
+			fxhealthcheck.AsCheckerProbe(probes.NewSuccessProbe),
+		),
+		fx.Populate(&grpcServer, &lis, &healthCheckService),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	// client preparation
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "test::readiness"})
+	assert.NoError(t, err)
+
+	// initial status assertions
+	response, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	// status transition assertions
+	healthCheckService.SetServingStatus("test::readiness", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	response, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+}
+
+func TestModuleConcurrencyLimit(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_CONCURRENCY_LIMIT", "1")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	// first stream, kept in-flight
+	stream, err := client.Bidi(context.Background())
+	assert.NoError(t, err)
+
+	err = stream.Send(&proto.Request{Message: "in-flight"})
+	assert.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.NoError(t, err)
+
+	expectedMetric := `
+		# HELP foo_bar_grpc_server_in_flight_requests Number of in-flight gRPC calls
+		# TYPE foo_bar_grpc_server_in_flight_requests gauge
+		foo_bar_grpc_server_in_flight_requests{grpc_method="/test.Service/Bidi"} 1
+	`
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"foo_bar_grpc_server_in_flight_requests",
+	)
+	assert.NoError(t, err)
+
+	// calls above the limit are rejected
+	_, err = client.Unary(context.Background(), &proto.Request{Message: "rejected"})
+	assert.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// calls accepted again once the first stream is done
+	err = stream.CloseSend()
+	assert.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+
+	response, err := client.Unary(context.Background(), &proto.Request{Message: "accepted"})
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+}
+
+func TestModuleMethodsMaxRecv(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_PROFILES", "methods")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	// calls within the limit are accepted
+	response, err := client.Unary(context.Background(), &proto.Request{Message: "accepted"})
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+
+	// calls above the limit are rejected
+	_, err = client.Unary(context.Background(), &proto.Request{Message: strings.Repeat("rejected", 10)})
+	assert.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "too large for /test.Service/Unary, limit is 32")
+
+	// the messages sizes are observed, including the rejected ones
+	count, err := testutil.GatherAndCount(metricsRegistry, "foo_bar_grpc_server_message_size_bytes")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	families, err := metricsRegistry.Gather()
+	assert.NoError(t, err)
+
+	samples := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() == "foo_bar_grpc_server_message_size_bytes" {
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "grpc_direction" {
+						samples[label.GetValue()] = metric.GetHistogram().GetSampleCount()
+					}
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]uint64{"recv": 2, "sent": 1}, samples)
+}
+
+func TestModuleValidation(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	tests := []struct {
+		name    string
+		enabled string
+		code    codes.Code
+	}{
+		{"disabled by default", "", codes.OK},
+		{"enabled", "true", codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MODULES_GRPC_SERVER_VALIDATION_ENABLED", tt.enabled)
+
+			var grpcServer *grpc.Server
+			var lis *bufconn.Listener
+
+			fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxgenerate.FxGenerateModule,
+				fxmetrics.FxMetricsModule,
+				fxhealthcheck.FxHealthcheckModule,
+				fxgrpcserver.FxGrpcServerModule,
+				fx.Provide(service.NewTestServiceDependency),
+				fx.Options(
+					fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+				),
+				fx.Populate(&grpcServer, &lis),
+			).RequireStart().RequireStop()
+
+			defer func() {
+				err := lis.Close()
+				assert.NoError(t, err)
+
+				grpcServer.GracefulStop()
+			}()
+
+			conn, err := prepareGrpcClientTestConnection(lis)
+			assert.NoError(t, err)
+
+			client := proto.NewServiceClient(conn)
+
+			// valid messages are accepted
+			response, err := client.Unary(context.Background(), &proto.Request{Message: "valid"})
+			assert.NoError(t, err)
+			assert.True(t, response.Success)
+
+			// invalid messages are rejected only if the validation is enabled
+			_, err = client.Unary(context.Background(), &proto.Request{Message: "invalid"})
+			assert.Equal(t, tt.code, status.Code(err))
+
+			if tt.code == codes.InvalidArgument {
+				assert.Contains(t, err.Error(), "invalid Request.Message: value must not be in list [invalid]")
+
+				details := status.Convert(err).Details()
+				assert.Len(t, details, 1)
+
+				badRequest, ok := details[0].(*errdetails.BadRequest)
+				assert.True(t, ok)
+				assert.Equal(t, "Message", badRequest.GetFieldViolations()[0].GetField())
+				assert.Equal(t, "value must not be in list [invalid]", badRequest.GetFieldViolations()[0].GetDescription())
+			}
+		})
+	}
+}
+
+func TestModuleErrorMappings(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+			fxgrpcserver.AsGrpcServerErrorMapping(grpcserver.MatchErrorIs(service.ErrTestNotFound), codes.NotFound),
+		),
+		fx.Populate(&grpcServer, &lis),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	// mapped errors are returned with their code and message
+	_, err = client.Unary(context.Background(), &proto.Request{Message: "not-found"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "unary call on test: test not found", status.Convert(err).Message())
+
+	// status errors are returned as is
+	_, err = client.Unary(context.Background(), &proto.Request{ShouldFail: true})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "failure", status.Convert(err).Message())
+}
+
+func TestModuleWithAuditLogger(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis, &logBuffer),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", testRequestId)
+
+	_, err = client.Unary(ctx, &proto.Request{Message: "audit"})
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"audit":     true,
+		"requestID": testRequestId,
+		"actor":     "user-1",
+		"action":    "unary",
+		"resource":  "test",
+		"outcome":   "success",
+	})
+}
+
+func TestModuleRequestIdMetadataKey(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_REQUEST_ID_METADATA_KEY", "x-correlation-id")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis, &logBuffer),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	// incoming request id reused
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-correlation-id", testRequestId)
+
+	var trailer metadata.MD
+	_, err = client.Unary(ctx, &proto.Request{Message: "test"}, grpc.Trailer(&trailer))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{testRequestId}, trailer.Get("x-correlation-id"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"system":    "grpcserver",
+		"message":   "unary call on test",
+		"requestID": testRequestId,
+	})
+
+	// request id generated if absent
+	trailer = metadata.MD{}
+	_, err = client.Unary(context.Background(), &proto.Request{Message: "test"}, grpc.Trailer(&trailer))
+	assert.NoError(t, err)
+
+	assert.Len(t, trailer.Get("x-correlation-id"), 1)
+	assert.NotEqual(t, testRequestId, trailer.Get("x-correlation-id")[0])
+	assert.NotEmpty(t, trailer.Get("x-correlation-id")[0])
+}
+
+func TestModuleLogLevelsAndFields(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_PROFILES", "logs")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis, &logBuffer),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	_, err = client.Unary(context.Background(), &proto.Request{ShouldFail: true, Message: "test"})
+	assert.Error(t, err)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":      "warn",
+		"system":     "grpcserver",
+		"rpcMethod":  "/test.Service/Unary",
+		"grpcStatus": "Internal",
+		"message":    "grpc call error",
+	})
+}
+
+func TestModuleWithLogsDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_LOG_ENABLED", "false")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis, &logBuffer),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", testRequestId)
+
+	var trailer metadata.MD
+	_, err = client.Unary(ctx, &proto.Request{ShouldFail: true, Message: "test"}, grpc.Trailer(&trailer))
+	assert.Error(t, err)
+
+	// request id still handled
+	assert.Equal(t, []string{testRequestId}, trailer.Get("x-request-id"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"system":    "grpcserver",
+		"message":   "unary call on test",
+		"requestID": testRequestId,
+	})
+
+	// calls not logged
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"message": "grpc call start",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"message": "grpc call error",
+	})
+}
+
+func TestModuleWithRecoveryDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_RECOVERY_ENABLED", "false")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+
+	// outermost interceptor, to catch the panics not recovered by the module
+	unrecovered := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = status.Errorf(codes.Aborted, "unrecovered panic: %v", r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerOptions(grpc.UnaryInterceptor(unrecovered)),
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	_, err = client.Unary(context.Background(), &proto.Request{ShouldPanic: true, Message: "test"})
+	assert.Error(t, err)
+
+	// panic not recovered by the module recovery interceptor
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Contains(t, err.Error(), "unrecovered panic: test")
+}
+
+func TestModuleWithCustomIdGenerator(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Decorate(generator.NewTestIdGenerator),
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis, &logBuffer),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		err := lis.Close()
+		assert.NoError(t, err)
+
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	// request id generated by the custom generator
+	var trailer metadata.MD
+	_, err = client.Unary(context.Background(), &proto.Request{Message: "test"}, grpc.Trailer(&trailer))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{generator.TestIdValue}, trailer.Get("x-request-id"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"system":    "grpcserver",
+		"message":   "unary call on test",
+		"requestID": generator.TestIdValue,
+	})
+}
+
+func TestModuleDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_ENABLED", "false")
+
+	var grpcServer *grpc.Server
+	var lis *bufconn.Listener
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(service.NewTestServiceDependency),
+		fx.Options(
+			fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		),
+		fx.Populate(&grpcServer, &lis, &logBuffer),
+	).RequireStart().RequireStop()
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"message": "grpc server disabled, not serving",
+	})
+
+	// still constructed, with its services
+	assert.Contains(t, fmt.Sprintf("%+v", grpcServer.GetServiceInfo()), "test.Service")
+
+	// not serving
+	conn, err := prepareGrpcClientTestConnection(lis)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = proto.NewServiceClient(conn).Unary(ctx, &proto.Request{Message: "test"})
+	assert.Error(t, err)
+}
+
 func TestModuleDecoration(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 	t.Setenv("APP_ENV", "test")
@@ -440,3 +1257,171 @@ func prepareGrpcClientTestConnection(lis *bufconn.Listener) (*grpc.ClientConn, e
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	)
 }
+
+func TestModuleWithInvalidTypedConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("MODULES_GRPC_SERVER_CONCURRENCY_LIMIT", "unlimited")
+
+	var grpcServer *grpc.Server
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Populate(&grpcServer),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "could not decode config modules.grpc.server")
+	assert.Contains(t, app.Err().Error(), "'modules.grpc.server.concurrency.limit'")
+}
+
+func TestModuleWithValidConfigSchema(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_PROFILES", "methods,strict")
+
+	var grpcServer *grpc.Server
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Populate(&grpcServer),
+	).RequireStart().RequireStop()
+
+	assert.NotNil(t, grpcServer)
+}
+
+func TestModuleWithInvalidConfigSchema(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_PROFILES", "typo,strict")
+
+	var grpcServer *grpc.Server
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Populate(&grpcServer),
+	)
+	assert.NoError(t, app.Err())
+
+	err := app.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(
+		t,
+		err.Error(),
+		"invalid config: "+
+			"modules.grpc.server.methods./test.service/unary.max_send: unknown config key; "+
+			"modules.grpc.server.reflexion.enabled: unknown config key, did you mean modules.grpc.server.reflection.enabled?; "+
+			`modules.grpc.server.trace.enabled: invalid bool value sometimes: strconv.ParseBool: parsing "sometimes": invalid syntax`,
+	)
+}
+
+func TestModuleWithPortAlreadyInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_GRPC_SERVER_PORT", strconv.Itoa(port))
+
+	var grpcServer *grpc.Server
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Populate(&grpcServer),
+	)
+	assert.NoError(t, app.Err())
+
+	err = app.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("failed to listen on %d for grpc server", port))
+	assert.Contains(t, err.Error(), "address already in use")
+}
+
+func TestModuleWithListenerReusePort(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "freebsd" {
+		t.Skipf("SO_REUSEPORT not supported on %s", runtime.GOOS)
+	}
+
+	lis, err := listener.Listen(context.Background(), ":0", listener.Config{ReusePort: true})
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	port := lis.Addr().(*net.TCPAddr).Port
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_GRPC_SERVER_PORT", strconv.Itoa(port))
+	t.Setenv("MODULES_GRPC_SERVER_LISTENER_REUSE_PORT", "true")
+	t.Setenv("MODULES_GRPC_SERVER_LISTENER_BACKLOG", "1024")
+
+	var grpcServer *grpc.Server
+	var listenerAddr *fxgrpcserver.GrpcServerListenerAddr
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fxhealthcheck.AsCheckerProbe(probes.NewSuccessProbe),
+		fx.Populate(&grpcServer, &listenerAddr),
+	)
+	assert.NoError(t, app.Err())
+
+	// the port is shared with the already bound listener
+	assert.NoError(t, app.Start(context.Background()))
+	assert.Equal(t, port, listenerAddr.Port())
+
+	// once the other listener is closed, the connections are served by the grpc server
+	assert.NoError(t, lis.Close())
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		fmt.Sprintf("127.0.0.1:%d", port),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	response, err := grpc_health_v1.NewHealthClient(conn).Check(
+		context.Background(),
+		&grpc_health_v1.HealthCheckRequest{Service: "test::readiness"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	assert.NoError(t, app.Stop(context.Background()))
+}
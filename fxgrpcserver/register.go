@@ -1,8 +1,12 @@
 package fxgrpcserver
 
 import (
+	"sync/atomic"
+
+	"github.com/ankorstore/yokai/grpcserver"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 func AsGrpcServerService(constructor any, description *grpc.ServiceDesc) fx.Option {
@@ -42,3 +46,17 @@ func AsGrpcServerOptions(options ...grpc.ServerOption) fx.Option {
 
 	return fx.Options(serverOptions...)
 }
+
+var errorMappingsPosition atomic.Int64
+
+// AsGrpcServerErrorMapping registers an error mapping into Fx, converting the errors matched by the handlers into gRPC
+// status errors with the provided code: the first mapping (in registration order) matching an error provides the code.
+func AsGrpcServerErrorMapping(matcher grpcserver.ErrorMatcher, code codes.Code) fx.Option {
+	return fx.Supply(
+		fx.Annotate(
+			NewErrorMappingDefinition(matcher, code, errorMappingsPosition.Add(1)),
+			fx.As(new(ErrorMappingDefinition)),
+			fx.ResultTags(`group:"grpc-server-error-mappings"`),
+		),
+	)
+}
@@ -0,0 +1,15 @@
+package generator
+
+import "github.com/ankorstore/yokai/generate/id"
+
+const TestIdValue = "custom-id"
+
+type TestIdGenerator struct{}
+
+func NewTestIdGenerator() id.IdGenerator {
+	return &TestIdGenerator{}
+}
+
+func (g *TestIdGenerator) Generate() string {
+	return TestIdValue
+}
@@ -0,0 +1,90 @@
+// Validation methods of the test messages, with the same shape as the code generated by protoc-gen-validate (to avoid
+// depending on it), for a `string message = 3 [(validate.rules).string = {not_in: ["invalid"]}]` rule.
+
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validate checks the field values on Request with the rules defined in the proto definition for this message, and
+// returns the first violation.
+func (m *Request) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Request with the rules defined in the proto definition for this message, and
+// returns all the violations in a RequestMultiError.
+func (m *Request) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Request) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetMessage() == "invalid" {
+		err := RequestValidationError{
+			field:  "Message",
+			reason: "value must not be in list [invalid]",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return RequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// RequestMultiError is an error wrapping multiple validation errors returned by Request.ValidateAll().
+type RequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m RequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m RequestMultiError) AllErrors() []error { return m }
+
+// RequestValidationError is the validation error returned by Request.Validate if the designated constraints aren't met.
+type RequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e RequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e RequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e RequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e RequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e RequestValidationError) ErrorName() string { return "RequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e RequestValidationError) Error() string {
+	return fmt.Sprintf("invalid Request.%s: %s", e.field, e.reason)
+}
@@ -10,10 +10,13 @@ import (
 
 	"github.com/ankorstore/yokai/fxgrpcserver/testdata/proto"
 	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/ankorstore/yokai/log"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+var ErrTestNotFound = errors.New("test not found")
+
 type TestService struct {
 	proto.UnimplementedServiceServer
 	dependency *TestServiceDependency
@@ -40,6 +43,23 @@ func (t *TestService) Unary(ctx context.Context, in *proto.Request) (*proto.Resp
 		return nil, status.Error(codes.Internal, "failure")
 	}
 
+	if in.Message == "not-found" {
+		return nil, fmt.Errorf("unary call on %s: %w", appName, ErrTestNotFound)
+	}
+
+	if in.Message == "audit" {
+		ctx = log.WithAuditActor(ctx, "user-1")
+
+		err := grpcserver.CtxAuditLogger(ctx).Audit(ctx, log.AuditEvent{
+			Action:   "unary",
+			Resource: appName,
+			Outcome:  log.AuditOutcomeSuccess,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if in.ShouldPanic {
 		logger.Error().Msgf("unary call panic on %s", appName)
 
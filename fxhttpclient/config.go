@@ -0,0 +1,104 @@
+package fxhttpclient
+
+import (
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/httpclient/transport"
+)
+
+// clientSchemaKeys are the keys of a client config, expected in the modules.http.client block for the default client,
+// and in the modules.http.client.clients.<name> blocks for the named clients.
+var clientSchemaKeys = []config.SchemaKey{
+	{Path: "timeout", Type: config.SchemaTypeInt},
+	{Path: "user_agent", Type: config.SchemaTypeString},
+	{Path: "headers", Type: config.SchemaTypeMap},
+	{Path: "request_id.header", Type: config.SchemaTypeString},
+	{Path: "propagate.headers", Type: config.SchemaTypeList},
+	{Path: "transport.max_idle_connections", Type: config.SchemaTypeInt},
+	{Path: "transport.max_connections_per_host", Type: config.SchemaTypeInt},
+	{Path: "transport.max_idle_connections_per_host", Type: config.SchemaTypeInt},
+	{Path: "transport.idle_connection_timeout", Type: config.SchemaTypeFloat},
+	{Path: "transport.tls_handshake_timeout", Type: config.SchemaTypeFloat},
+	{Path: "transport.expect_continue_timeout", Type: config.SchemaTypeFloat},
+	{Path: "transport.dialer.timeout", Type: config.SchemaTypeFloat},
+	{Path: "transport.dialer.keep_alive", Type: config.SchemaTypeFloat},
+	{Path: "transport.disable_compression", Type: config.SchemaTypeBool},
+	{Path: "transport.accept_encoding", Type: config.SchemaTypeString},
+	{Path: "tls.ca_file", Type: config.SchemaTypeString},
+	{Path: "tls.cert_file", Type: config.SchemaTypeString},
+	{Path: "tls.key_file", Type: config.SchemaTypeString},
+	{Path: "tls.insecure_skip_verify", Type: config.SchemaTypeBool},
+	{Path: "tls.min_version", Type: config.SchemaTypeString},
+	{Path: "proxy.url", Type: config.SchemaTypeString},
+	{Path: "proxy.no_proxy", Type: config.SchemaTypeList},
+	{Path: "proxy.from_environment", Type: config.SchemaTypeBool},
+	{Path: "oauth2.token_url", Type: config.SchemaTypeString},
+	{Path: "oauth2.client_id", Type: config.SchemaTypeString},
+	{Path: "oauth2.client_secret", Type: config.SchemaTypeString},
+	{Path: "oauth2.client_secret_file", Type: config.SchemaTypeString},
+	{Path: "oauth2.scopes", Type: config.SchemaTypeList},
+	{Path: "oauth2.audience", Type: config.SchemaTypeString},
+	{Path: "oauth2.expiry_delta", Type: config.SchemaTypeFloat},
+	{Path: "log.request.enabled", Type: config.SchemaTypeBool},
+	{Path: "log.request.level", Type: config.SchemaTypeString},
+	{Path: "log.request.body", Type: config.SchemaTypeBool},
+	{Path: "log.request_body", Type: config.SchemaTypeBool},
+	{Path: "log.request_headers", Type: config.SchemaTypeMap},
+	{Path: "log.response.enabled", Type: config.SchemaTypeBool},
+	{Path: "log.response.level", Type: config.SchemaTypeString},
+	{Path: "log.response.level_from_response", Type: config.SchemaTypeBool},
+	{Path: "log.response.body", Type: config.SchemaTypeBool},
+	{Path: "log.response_body", Type: config.SchemaTypeBool},
+	{Path: "log.response_headers", Type: config.SchemaTypeMap},
+	{
+		Path:    "log.response_body_streaming",
+		Type:    config.SchemaTypeString,
+		Allowed: []string{transport.ResponseBodyStreamingCapture, transport.ResponseBodyStreamingSkip},
+	},
+	{Path: "log.body_max_size", Type: config.SchemaTypeInt},
+	{Path: "log.redact_json_fields", Type: config.SchemaTypeList},
+	{Path: "log.exclude_hosts", Type: config.SchemaTypeList},
+	{Path: "log.levels", Type: config.SchemaTypeMap},
+	{Path: "trace.enabled", Type: config.SchemaTypeBool},
+	{Path: "trace.exclude_hosts", Type: config.SchemaTypeList},
+	{Path: "metrics.collect.enabled", Type: config.SchemaTypeBool},
+	{Path: "metrics.collect.in_flight", Type: config.SchemaTypeBool},
+	{Path: "metrics.buckets", Type: config.SchemaTypeList},
+	{Path: "retry.enabled", Type: config.SchemaTypeBool},
+	{Path: "retry.max_attempts", Type: config.SchemaTypeInt},
+	{Path: "retry.initial_backoff", Type: config.SchemaTypeFloat},
+	{Path: "retry.max_backoff", Type: config.SchemaTypeFloat},
+	{Path: "retry.jitter", Type: config.SchemaTypeFloat},
+	{Path: "retry.retry_on", Type: config.SchemaTypeList},
+	{Path: "retry.methods", Type: config.SchemaTypeList},
+	{Path: "circuit_breaker.enabled", Type: config.SchemaTypeBool},
+	{Path: "circuit_breaker.failure_ratio", Type: config.SchemaTypeFloat},
+	{Path: "circuit_breaker.minimum_requests", Type: config.SchemaTypeInt},
+	{Path: "circuit_breaker.open_timeout", Type: config.SchemaTypeFloat},
+	{Path: "circuit_breaker.half_open_max_requests", Type: config.SchemaTypeInt},
+	{Path: "circuit_breaker.interval", Type: config.SchemaTypeFloat},
+	{Path: "circuit_breaker.hosts", Type: config.SchemaTypeList},
+	{Path: "cache.enabled", Type: config.SchemaTypeBool},
+	{Path: "cache.max_entries", Type: config.SchemaTypeInt},
+	{Path: "cache.max_size", Type: config.SchemaTypeInt},
+	{Path: "cache.max_body_size", Type: config.SchemaTypeInt},
+	{Path: "cache.default_ttl", Type: config.SchemaTypeFloat},
+	{Path: "cache.allow_authorized", Type: config.SchemaTypeBool},
+}
+
+// configSchema returns the modules.http.client config schema, to report the unknown keys (like typos) and invalid
+// values when the application starts. The named clients blocks expect the same keys as the default client.
+func configSchema() *config.Schema {
+	keys := []config.SchemaKey{
+		{Path: "metrics.collect.namespace", Type: config.SchemaTypeString},
+		{Path: "metrics.collect.subsystem", Type: config.SchemaTypeString},
+	}
+
+	for _, key := range clientSchemaKeys {
+		namedKey := key
+		namedKey.Path = "clients.*." + key.Path
+
+		keys = append(keys, key, namedKey)
+	}
+
+	return config.NewSchema("modules.http.client", keys...)
+}
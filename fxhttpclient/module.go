@@ -1,14 +1,22 @@
 package fxhttpclient
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/httpclient"
 	"github.com/ankorstore/yokai/httpclient/transport"
 	"github.com/ankorstore/yokai/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
@@ -20,8 +28,12 @@ const (
 	DefaultMaxIdleConnections        = 100
 	DefaultMaxConnectionsPerHost     = 100
 	DefaultMaxIdleConnectionsPerHost = 100
+	DefaultUserAgentSuffix           = "yokai-httpclient"
 )
 
+// responseCodePattern matches the exact response codes (like 404) and classes (like 4xx) of the log levels config.
+var responseCodePattern = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+
 // FxHttpClientModule is the [Fx] http client module.
 //
 // [Fx]: https://github.com/uber-go/fx
@@ -30,36 +42,88 @@ var FxHttpClientModule = fx.Module(
 	fx.Provide(
 		httpclient.NewDefaultHttpClientFactory,
 		NewFxHttpClient,
+		NewFxHttpClientRegistry,
 	),
+	fxconfig.AsConfigSchema(configSchema()),
 )
 
-// FxHttpClientParam allows injection of the required dependencies in [NewFxHttpClient].
+// FxHttpClientParam allows injection of the required dependencies in [NewFxHttpClient] and [NewFxHttpClientRegistry].
 type FxHttpClientParam struct {
 	fx.In
-	Factory        httpclient.HttpClientFactory
-	TracerProvider trace.TracerProvider
-	Config         *config.Config
-	Logger         *log.Logger
+	Factory         httpclient.HttpClientFactory
+	TracerProvider  trace.TracerProvider
+	Config          *config.Config
+	Logger          *log.Logger
+	MetricsRegistry *prometheus.Registry `optional:"true"`
 }
 
-// NewFxHttpClient returns a new [http.Client].
+// NewFxHttpClient returns a new [http.Client], configured by the modules.http.client config keys.
 func NewFxHttpClient(p FxHttpClientParam) (*http.Client, error) {
-	timeout := p.Config.GetInt("modules.http.client.timeout")
+	return createHttpClient(p, &clientConfig{config: p.Config})
+}
+
+// NewFxHttpClientRegistry returns a new [HttpClientRegistry], holding the named [http.Client] configured by the
+// modules.http.client.clients.<name> config keys.
+func NewFxHttpClientRegistry(p FxHttpClientParam) (*HttpClientRegistry, error) {
+	clients := map[string]*http.Client{}
+
+	for name := range p.Config.GetStringMap("modules.http.client.clients") {
+		client, err := createHttpClient(p, &clientConfig{config: p.Config, name: name})
+		if err != nil {
+			return nil, fmt.Errorf("cannot create http client %s: %w", name, err)
+		}
+
+		clients[name] = client
+	}
+
+	return NewHttpClientRegistry(clients), nil
+}
+
+// clientConfig resolves the config keys of a client: the keys of a named client fallback on the modules.http.client
+// ones if not set in its modules.http.client.clients.<name> block.
+type clientConfig struct {
+	config *config.Config
+	name   string
+}
+
+func (c *clientConfig) key(key string) string {
+	if c.name != "" {
+		if namedKey := fmt.Sprintf("modules.http.client.clients.%s.%s", c.name, key); c.config.IsSet(namedKey) {
+			return namedKey
+		}
+	}
+
+	return fmt.Sprintf("modules.http.client.%s", key)
+}
+
+func (c *clientConfig) metricsName() string {
+	if c.name == "" {
+		return transport.DefaultMetricsClientName
+	}
+
+	return c.name
+}
+
+//nolint:cyclop
+func createHttpClient(p FxHttpClientParam, c *clientConfig) (*http.Client, error) {
+	cfg := p.Config
+
+	timeout := cfg.GetInt(c.key("timeout"))
 	if timeout == 0 {
 		timeout = DefaultTimeout
 	}
 
-	maxIdleConnections := p.Config.GetInt("modules.http.client.transport.max_idle_connections")
+	maxIdleConnections := cfg.GetInt(c.key("transport.max_idle_connections"))
 	if maxIdleConnections == 0 {
 		maxIdleConnections = DefaultMaxIdleConnections
 	}
 
-	maxConnectionsPerHost := p.Config.GetInt("modules.http.client.transport.max_connections_per_host")
+	maxConnectionsPerHost := cfg.GetInt(c.key("transport.max_connections_per_host"))
 	if maxConnectionsPerHost == 0 {
 		maxConnectionsPerHost = DefaultMaxConnectionsPerHost
 	}
 
-	maxIdleConnectionsPerHost := p.Config.GetInt("modules.http.client.transport.max_idle_connections_per_host")
+	maxIdleConnectionsPerHost := cfg.GetInt(c.key("transport.max_idle_connections_per_host"))
 	if maxIdleConnectionsPerHost == 0 {
 		maxIdleConnectionsPerHost = DefaultMaxIdleConnectionsPerHost
 	}
@@ -68,16 +132,53 @@ func NewFxHttpClient(p FxHttpClientParam) (*http.Client, error) {
 		MaxIdleConnections:        maxIdleConnections,
 		MaxConnectionsPerHost:     maxConnectionsPerHost,
 		MaxIdleConnectionsPerHost: maxIdleConnectionsPerHost,
+		IdleConnectionTimeout:     configuredSeconds(cfg, c.key("transport.idle_connection_timeout")),
+		TlsHandshakeTimeout:       configuredSeconds(cfg, c.key("transport.tls_handshake_timeout")),
+		ExpectContinueTimeout:     configuredSeconds(cfg, c.key("transport.expect_continue_timeout")),
+		DialerTimeout:             configuredSeconds(cfg, c.key("transport.dialer.timeout")),
+		DialerKeepAlive:           configuredSeconds(cfg, c.key("transport.dialer.keep_alive")),
+		DisableCompression:        cfg.GetBool(c.key("transport.disable_compression")),
+	}
+
+	tlsConfig, err := configuredTlsConfig(p, c)
+	if err != nil {
+		return nil, err
+	}
+
+	baseTransportConfig.TlsConfig = tlsConfig
+
+	proxy, err := configuredProxy(p, c)
+	if err != nil {
+		return nil, err
+	}
+
+	baseTransportConfig.Proxy = proxy
+
+	responseLevels, err := configuredResponseLevels(c)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBodyStreaming, err := configuredResponseBodyStreaming(c)
+	if err != nil {
+		return nil, err
 	}
 
 	loggerTransportConfig := &transport.LoggerTransportConfig{
-		LogRequest:                       p.Config.GetBool("modules.http.client.log.request.enabled"),
-		LogRequestBody:                   p.Config.GetBool("modules.http.client.log.request.body"),
-		LogRequestLevel:                  log.FetchLogLevel(p.Config.GetString("modules.http.client.log.request.level")),
-		LogResponse:                      p.Config.GetBool("modules.http.client.log.response.enabled"),
-		LogResponseBody:                  p.Config.GetBool("modules.http.client.log.response.body"),
-		LogResponseLevel:                 log.FetchLogLevel(p.Config.GetString("modules.http.client.log.response.level")),
-		LogResponseLevelFromResponseCode: p.Config.GetBool("modules.http.client.log.response.level_from_response"),
+		LogRequest:                       cfg.GetBool(c.key("log.request.enabled")),
+		LogRequestBody:                   configuredBodyLogging(c, "request"),
+		LogRequestLevel:                  log.FetchLogLevel(cfg.GetString(c.key("log.request.level"))),
+		LogResponse:                      cfg.GetBool(c.key("log.response.enabled")),
+		LogResponseBody:                  configuredBodyLogging(c, "response"),
+		LogResponseLevel:                 log.FetchLogLevel(cfg.GetString(c.key("log.response.level"))),
+		LogResponseLevelFromResponseCode: cfg.GetBool(c.key("log.response.level_from_response")),
+		LogResponseLevelsByResponseCode:  responseLevels,
+		LogBodyMaxSize:                   cfg.GetInt(c.key("log.body_max_size")),
+		LogResponseBodyStreaming:         responseBodyStreaming,
+		RedactJsonFields:                 cfg.GetStringSlice(c.key("log.redact_json_fields")),
+		RequestHeadersToLog:              cfg.GetStringMapString(c.key("log.request_headers")),
+		ResponseHeadersToLog:             cfg.GetStringMapString(c.key("log.response_headers")),
+		ExcludeHosts:                     cfg.GetStringSlice(c.key("log.exclude_hosts")),
 	}
 
 	var roundTripper http.RoundTripper
@@ -86,17 +187,99 @@ func NewFxHttpClient(p FxHttpClientParam) (*http.Client, error) {
 		loggerTransportConfig,
 	)
 
+	if cfg.GetBool(c.key("cache.enabled")) {
+		roundTripper = transport.NewCacheTransportWithConfig(roundTripper, cacheTransportConfig(p, c))
+
+		p.Logger.Debug().Str("client", c.metricsName()).Msg("http client: enabled cache")
+	}
+
+	roundTripper = transport.NewHeadersTransportWithConfig(
+		roundTripper,
+		&transport.HeadersTransportConfig{
+			Headers: configuredHeaders(c),
+		},
+	)
+
+	if cfg.IsSet(c.key("oauth2.token_url")) {
+		oauth2Config, err := oauth2TransportConfig(c, baseTransportConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		roundTripper = transport.NewOAuth2TransportWithConfig(roundTripper, oauth2Config)
+
+		p.Logger.
+			Debug().
+			Str("client", c.metricsName()).
+			Str("token_url", oauth2Config.TokenUrl).
+			Msg("http client: enabled oauth2 client credentials")
+	}
+
+	if cfg.GetBool(c.key("retry.enabled")) {
+		roundTripper = transport.NewRetryTransportWithConfig(roundTripper, retryTransportConfig(p, c))
+
+		p.Logger.Debug().Str("client", c.metricsName()).Msg("http client: enabled retries")
+	}
+
+	if cfg.GetBool(c.key("circuit_breaker.enabled")) {
+		circuitBreakerConfig, err := circuitBreakerTransportConfig(p, c)
+		if err != nil {
+			return nil, err
+		}
+
+		roundTripper = transport.NewCircuitBreakerTransportWithConfig(roundTripper, circuitBreakerConfig)
+
+		p.Logger.Debug().Str("client", c.metricsName()).Msg("http client: enabled circuit breaker")
+	}
+
+	requestIdHeader := cfg.GetString(c.key("request_id.header"))
+	if requestIdHeader == "" {
+		requestIdHeader = httpclient.HeaderXRequestId
+	}
+
+	roundTripper = transport.NewRequestIdTransportWithConfig(
+		roundTripper,
+		&transport.RequestIdTransportConfig{
+			RequestIdHeader:     requestIdHeader,
+			BaggageRequestIdKey: httpclient.BaggageRequestIdKey,
+		},
+	)
+
+	if propagatedHeaders := cfg.GetStringSlice(c.key("propagate.headers")); len(propagatedHeaders) > 0 {
+		roundTripper = transport.NewPropagationTransportWithConfig(
+			roundTripper,
+			&transport.PropagationTransportConfig{
+				Headers: propagatedHeaders,
+			},
+		)
+	}
+
+	if cfg.GetBool(c.key("metrics.collect.enabled")) {
+		roundTripper = transport.NewMetricsTransportWithConfig(roundTripper, metricsTransportConfig(p, c))
+
+		p.Logger.Debug().Str("client", c.metricsName()).Msg("http client: enabled metrics")
+	}
+
 	p.Logger.
 		Debug().
+		Str("client", c.metricsName()).
 		Int("timeout", timeout).
 		Str("base transport config", fmt.Sprintf("%+v", baseTransportConfig)).
 		Str("logger transport config", fmt.Sprintf("%+v", loggerTransportConfig)).
 		Msg("http client: applied configs")
 
-	if p.Config.GetBool("modules.http.client.trace.enabled") {
-		roundTripper = otelhttp.NewTransport(roundTripper, otelhttp.WithTracerProvider(p.TracerProvider))
+	if cfg.GetBool(c.key("trace.enabled")) {
+		traceOptions := []otelhttp.Option{otelhttp.WithTracerProvider(p.TracerProvider)}
+
+		if excludedHosts := cfg.GetStringSlice(c.key("trace.exclude_hosts")); len(excludedHosts) > 0 {
+			traceOptions = append(traceOptions, otelhttp.WithFilter(func(req *http.Request) bool {
+				return !transport.MatchHost(req.URL.Hostname(), excludedHosts...)
+			}))
+		}
+
+		roundTripper = otelhttp.NewTransport(roundTripper, traceOptions...)
 
-		p.Logger.Debug().Msg("http client: enabled tracing")
+		p.Logger.Debug().Str("client", c.metricsName()).Msg("http client: enabled tracing")
 	}
 
 	return p.Factory.Create(
@@ -104,3 +287,308 @@ func NewFxHttpClient(p FxHttpClientParam) (*http.Client, error) {
 		httpclient.WithTransport(roundTripper),
 	)
 }
+
+func configuredHeaders(c *clientConfig) map[string]string {
+	headers := map[string]string{}
+
+	for header, value := range c.config.GetStringMapString(c.key("headers")) {
+		headers[http.CanonicalHeaderKey(header)] = value
+	}
+
+	if userAgent := c.config.GetString(c.key("user_agent")); userAgent != "" {
+		headers["User-Agent"] = userAgent
+	} else if headers["User-Agent"] == "" {
+		headers["User-Agent"] = fmt.Sprintf("%s/%s %s", c.config.AppName(), c.config.AppVersion(), DefaultUserAgentSuffix)
+	}
+
+	// explicitly set, the responses are not transparently decompressed anymore
+	if acceptEncoding := c.config.GetString(c.key("transport.accept_encoding")); acceptEncoding != "" {
+		headers["Accept-Encoding"] = acceptEncoding
+	}
+
+	return headers
+}
+
+func retryTransportConfig(p FxHttpClientParam, c *clientConfig) *transport.RetryTransportConfig {
+	retryConfig := &transport.RetryTransportConfig{
+		MaxAttempts:    p.Config.GetInt(c.key("retry.max_attempts")),
+		InitialBackoff: configuredSeconds(p.Config, c.key("retry.initial_backoff")),
+		MaxBackoff:     configuredSeconds(p.Config, c.key("retry.max_backoff")),
+		Jitter:         transport.DefaultRetryJitter,
+		Namespace:      strings.ReplaceAll(p.Config.GetString("modules.http.client.metrics.collect.namespace"), "-", "_"),
+		Subsystem:      strings.ReplaceAll(p.Config.GetString("modules.http.client.metrics.collect.subsystem"), "-", "_"),
+	}
+
+	if p.Config.IsSet(c.key("retry.jitter")) {
+		retryConfig.Jitter = p.Config.GetFloat64(c.key("retry.jitter"))
+	}
+
+	if p.Config.IsSet(c.key("retry.retry_on")) {
+		retryConfig.RetryOnStatusCodes = p.Config.GetIntSlice(c.key("retry.retry_on"))
+	}
+
+	if p.Config.IsSet(c.key("retry.methods")) {
+		retryConfig.RetryOnMethods = p.Config.GetStringSlice(c.key("retry.methods"))
+	}
+
+	if p.MetricsRegistry != nil {
+		retryConfig.Registry = p.MetricsRegistry
+	}
+
+	return retryConfig
+}
+
+func metricsTransportConfig(p FxHttpClientParam, c *clientConfig) *transport.MetricsTransportConfig {
+	metricsConfig := &transport.MetricsTransportConfig{
+		Namespace:       strings.ReplaceAll(p.Config.GetString("modules.http.client.metrics.collect.namespace"), "-", "_"),
+		Subsystem:       strings.ReplaceAll(p.Config.GetString("modules.http.client.metrics.collect.subsystem"), "-", "_"),
+		CollectInFlight: p.Config.GetBool(c.key("metrics.collect.in_flight")),
+		Client:          c.metricsName(),
+	}
+
+	if bucketsConfig := p.Config.GetString(c.key("metrics.buckets")); bucketsConfig != "" {
+		for _, s := range strings.Split(strings.ReplaceAll(bucketsConfig, " ", ""), ",") {
+			f, err := strconv.ParseFloat(s, 64)
+			if err == nil {
+				metricsConfig.Buckets = append(metricsConfig.Buckets, f)
+			}
+		}
+	}
+
+	if p.MetricsRegistry != nil {
+		metricsConfig.Registry = p.MetricsRegistry
+	}
+
+	return metricsConfig
+}
+
+// oauth2TransportConfig returns the OAuth2 client credentials configuration from the oauth2 config keys, the client
+// secret being read from the client_secret_file if not set. The tokens are fetched with the client base transport.
+func oauth2TransportConfig(c *clientConfig, baseTransportConfig *transport.BaseTransportConfig) (*transport.OAuth2TransportConfig, error) {
+	oauth2Config := &transport.OAuth2TransportConfig{
+		TokenUrl:     c.config.GetString(c.key("oauth2.token_url")),
+		ClientId:     c.config.GetString(c.key("oauth2.client_id")),
+		ClientSecret: c.config.GetString(c.key("oauth2.client_secret")),
+		Scopes:       c.config.GetStringSlice(c.key("oauth2.scopes")),
+		Audience:     c.config.GetString(c.key("oauth2.audience")),
+		ExpiryDelta:  configuredSeconds(c.config, c.key("oauth2.expiry_delta")),
+		TokenClient: &http.Client{
+			Transport: transport.NewBaseTransportWithConfig(baseTransportConfig),
+			Timeout:   DefaultTimeout * time.Second,
+		},
+	}
+
+	if oauth2Config.ClientSecret == "" {
+		if secretFile := c.config.GetString(c.key("oauth2.client_secret_file")); secretFile != "" {
+			secret, err := os.ReadFile(secretFile)
+			if err != nil {
+				return nil, fmt.Errorf("invalid http client oauth2 client secret file: %w", err)
+			}
+
+			oauth2Config.ClientSecret = strings.TrimSpace(string(secret))
+		}
+	}
+
+	if oauth2Config.TokenUrl == "" || oauth2Config.ClientId == "" || oauth2Config.ClientSecret == "" {
+		return nil, fmt.Errorf("invalid http client oauth2 configuration: token_url, client_id and client_secret are required")
+	}
+
+	return oauth2Config, nil
+}
+
+// configuredTlsConfig returns the TLS configuration from the tls config keys, or nil if none are set (to keep the Go
+// defaults).
+func configuredTlsConfig(p FxHttpClientParam, c *clientConfig) (*tls.Config, error) {
+	tlsFilesConfig := &transport.TlsFilesConfig{
+		CaFile:             p.Config.GetString(c.key("tls.ca_file")),
+		CertFile:           p.Config.GetString(c.key("tls.cert_file")),
+		KeyFile:            p.Config.GetString(c.key("tls.key_file")),
+		InsecureSkipVerify: p.Config.GetBool(c.key("tls.insecure_skip_verify")),
+		MinVersion:         p.Config.GetString(c.key("tls.min_version")),
+	}
+
+	if *tlsFilesConfig == (transport.TlsFilesConfig{}) {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	tlsConfig, err := transport.NewTlsConfig(tlsFilesConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid http client tls configuration: %w", err)
+	}
+
+	if tlsConfig.InsecureSkipVerify {
+		p.Logger.
+			Warn().
+			Str("client", c.metricsName()).
+			Msg("http client: TLS certificates verification is DISABLED, connections are vulnerable to man-in-the-middle attacks, do not use in production")
+	}
+
+	return tlsConfig, nil
+}
+
+// configuredProxy returns the proxy function from the proxy config keys, or nil if none are set (to keep the proxy
+// resolution from the environment).
+func configuredProxy(p FxHttpClientParam, c *clientConfig) (transport.ProxyFunc, error) {
+	proxyConfig := &transport.ProxyConfig{
+		URL:             p.Config.GetString(c.key("proxy.url")),
+		NoProxy:         p.Config.GetStringSlice(c.key("proxy.no_proxy")),
+		FromEnvironment: true,
+	}
+
+	fromEnvironmentSet := p.Config.IsSet(c.key("proxy.from_environment"))
+	if fromEnvironmentSet {
+		proxyConfig.FromEnvironment = p.Config.GetBool(c.key("proxy.from_environment"))
+	}
+
+	if proxyConfig.URL == "" {
+		if !fromEnvironmentSet {
+			//nolint:nilnil
+			return nil, nil
+		}
+	} else if fromEnvironmentSet && proxyConfig.FromEnvironment {
+		p.Logger.
+			Warn().
+			Str("client", c.metricsName()).
+			Str("proxy", transport.RedactProxyUrl(proxyConfig.URL)).
+			Msg("http client: both proxy url and proxy from environment are configured, using the proxy url")
+	}
+
+	proxy, err := transport.NewProxyFunc(proxyConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid http client proxy configuration: %w", err)
+	}
+
+	if proxyConfig.URL != "" {
+		p.Logger.
+			Debug().
+			Str("client", c.metricsName()).
+			Str("proxy", transport.RedactProxyUrl(proxyConfig.URL)).
+			Strs("no_proxy", proxyConfig.NoProxy).
+			Msg("http client: enabled proxy")
+	}
+
+	return proxy, nil
+}
+
+// circuitBreakerHostConfig is the configuration of a host circuit breaker settings override.
+type circuitBreakerHostConfig struct {
+	Host                string  `mapstructure:"host"`
+	FailureRatio        float64 `mapstructure:"failure_ratio"`
+	MinimumRequests     int     `mapstructure:"minimum_requests"`
+	OpenTimeout         float64 `mapstructure:"open_timeout"`
+	HalfOpenMaxRequests int     `mapstructure:"half_open_max_requests"`
+	Interval            float64 `mapstructure:"interval"`
+}
+
+func cacheTransportConfig(p FxHttpClientParam, c *clientConfig) *transport.CacheTransportConfig {
+	cacheConfig := &transport.CacheTransportConfig{
+		MaxEntries:      cacheMaxEntries(p.Config, c),
+		MaxBodySize:     p.Config.GetInt(c.key("cache.max_body_size")),
+		DefaultTTL:      configuredSeconds(p.Config, c.key("cache.default_ttl")),
+		AllowAuthorized: p.Config.GetBool(c.key("cache.allow_authorized")),
+		Namespace:       strings.ReplaceAll(p.Config.GetString("modules.http.client.metrics.collect.namespace"), "-", "_"),
+		Subsystem:       strings.ReplaceAll(p.Config.GetString("modules.http.client.metrics.collect.subsystem"), "-", "_"),
+	}
+
+	if p.MetricsRegistry != nil {
+		cacheConfig.Registry = p.MetricsRegistry
+	}
+
+	return cacheConfig
+}
+
+// cacheMaxEntries returns the configured maximum number of cached responses, from cache.max_size, or else from its
+// former cache.max_entries name.
+func cacheMaxEntries(cfg *config.Config, c *clientConfig) int {
+	if cfg.IsSet(c.key("cache.max_size")) {
+		return cfg.GetInt(c.key("cache.max_size"))
+	}
+
+	return cfg.GetInt(c.key("cache.max_entries"))
+}
+
+func circuitBreakerTransportConfig(p FxHttpClientParam, c *clientConfig) (*transport.CircuitBreakerTransportConfig, error) {
+	circuitBreakerConfig := &transport.CircuitBreakerTransportConfig{
+		Settings: transport.CircuitBreakerSettings{
+			FailureRatio:        p.Config.GetFloat64(c.key("circuit_breaker.failure_ratio")),
+			MinimumRequests:     p.Config.GetInt(c.key("circuit_breaker.minimum_requests")),
+			OpenTimeout:         configuredSeconds(p.Config, c.key("circuit_breaker.open_timeout")),
+			HalfOpenMaxRequests: p.Config.GetInt(c.key("circuit_breaker.half_open_max_requests")),
+			Interval:            configuredSeconds(p.Config, c.key("circuit_breaker.interval")),
+		},
+		HostSettings: map[string]transport.CircuitBreakerSettings{},
+		Namespace:    strings.ReplaceAll(p.Config.GetString("modules.http.client.metrics.collect.namespace"), "-", "_"),
+		Subsystem:    strings.ReplaceAll(p.Config.GetString("modules.http.client.metrics.collect.subsystem"), "-", "_"),
+	}
+
+	var hostsConfig []circuitBreakerHostConfig
+	if err := p.Config.UnmarshalKey(c.key("circuit_breaker.hosts"), &hostsConfig); err != nil {
+		return nil, fmt.Errorf("invalid http client circuit breaker hosts configuration: %w", err)
+	}
+
+	for _, hostConfig := range hostsConfig {
+		circuitBreakerConfig.HostSettings[hostConfig.Host] = transport.CircuitBreakerSettings{
+			FailureRatio:        hostConfig.FailureRatio,
+			MinimumRequests:     hostConfig.MinimumRequests,
+			OpenTimeout:         time.Duration(hostConfig.OpenTimeout * float64(time.Second)),
+			HalfOpenMaxRequests: hostConfig.HalfOpenMaxRequests,
+			Interval:            time.Duration(hostConfig.Interval * float64(time.Second)),
+		}
+	}
+
+	if p.MetricsRegistry != nil {
+		circuitBreakerConfig.Registry = p.MetricsRegistry
+	}
+
+	return circuitBreakerConfig, nil
+}
+
+// configuredBodyLogging returns if the request or response body logging is enabled: the log.request_body and
+// log.response_body keys take precedence over the log.request.body and log.response.body ones.
+func configuredBodyLogging(c *clientConfig, kind string) bool {
+	if key := c.key(fmt.Sprintf("log.%s_body", kind)); c.config.IsSet(key) {
+		return c.config.GetBool(key)
+	}
+
+	return c.config.GetBool(c.key(fmt.Sprintf("log.%s.body", kind)))
+}
+
+// configuredResponseLevels returns the responses log levels by exact response code (like 404) or class (like 4xx).
+func configuredResponseLevels(c *clientConfig) (map[string]zerolog.Level, error) {
+	levels := c.config.GetStringMapString(c.key("log.levels"))
+	if len(levels) == 0 {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	responseLevels := make(map[string]zerolog.Level, len(levels))
+	for code, level := range levels {
+		code = strings.ToLower(code)
+
+		if !responseCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("invalid http client log level response code %s", code)
+		}
+
+		responseLevels[code] = log.FetchLogLevel(level)
+	}
+
+	return responseLevels, nil
+}
+
+// configuredResponseBodyStreaming returns the streamed response bodies logging mode, capture by default.
+func configuredResponseBodyStreaming(c *clientConfig) (string, error) {
+	switch mode := strings.ToLower(c.config.GetString(c.key("log.response_body_streaming"))); mode {
+	case "", transport.ResponseBodyStreamingCapture:
+		return transport.ResponseBodyStreamingCapture, nil
+	case transport.ResponseBodyStreamingSkip:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid http client log response body streaming mode %s", mode)
+	}
+}
+
+// configuredSeconds returns a duration from a config key in seconds, or zero if not set (to keep the Go defaults).
+func configuredSeconds(cfg *config.Config, key string) time.Duration {
+	return time.Duration(cfg.GetFloat64(key) * float64(time.Second))
+}
@@ -2,21 +2,39 @@ package fxhttpclient_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxhttpclient"
 	"github.com/ankorstore/yokai/fxhttpclient/testdata/factory"
 	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
 	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpclient"
+	"github.com/ankorstore/yokai/httpclient/propagated"
+	"github.com/ankorstore/yokai/httpclient/transport"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
@@ -175,6 +193,152 @@ func TestModule(t *testing.T) {
 	)
 }
 
+func TestModuleWithExcludedHosts(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_EXCLUDE_HOSTS", "127.0.0.1 .internal.local")
+	t.Setenv("MODULES_HTTP_CLIENT_TRACE_EXCLUDE_HOSTS", "127.0.0.1 .internal.local")
+
+	var httpClient *http.Client
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+	var traceExporter tracetest.TestTraceExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &logger, &logBuffer, &traceExporter),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	excludedUrl := httpServer.URL
+	includedUrl := strings.Replace(httpServer.URL, "127.0.0.1", "localhost", 1)
+
+	for _, url := range []string{excludedUrl, includedUrl} {
+		req, err := http.NewRequestWithContext(logger.WithContext(context.Background()), http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		resp, err := httpClient.Do(req)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+
+	// excluded host: no log records nor spans
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"url":     excludedUrl,
+		"message": "http client request",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"url":     excludedUrl,
+		"message": "http client response",
+	})
+
+	tracetest.AssertHasNotTraceSpan(t, traceExporter, "HTTP GET", semconv.HTTPURL(excludedUrl))
+
+	// not excluded host: log records and spans
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"method":  "GET",
+		"url":     includedUrl,
+		"message": "http client request",
+	})
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"url":     includedUrl,
+		"code":    http.StatusNoContent,
+		"message": "http client response",
+	})
+
+	tracetest.AssertHasTraceSpan(
+		t,
+		traceExporter,
+		"HTTP GET",
+		semconv.HTTPMethod(http.MethodGet),
+		semconv.HTTPURL(includedUrl),
+		semconv.HTTPStatusCode(http.StatusNoContent),
+	)
+}
+
+func TestModuleWithTransportConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_TRACE_ENABLED", "false")
+	t.Setenv("MODULES_HTTP_CLIENT_TIMEOUT", "10")
+	t.Setenv("MODULES_HTTP_CLIENT_TRANSPORT_MAX_IDLE_CONNECTIONS", "200")
+	t.Setenv("MODULES_HTTP_CLIENT_TRANSPORT_MAX_CONNECTIONS_PER_HOST", "50")
+	t.Setenv("MODULES_HTTP_CLIENT_TRANSPORT_MAX_IDLE_CONNECTIONS_PER_HOST", "20")
+	t.Setenv("MODULES_HTTP_CLIENT_TRANSPORT_IDLE_CONNECTION_TIMEOUT", "60")
+	t.Setenv("MODULES_HTTP_CLIENT_TRANSPORT_TLS_HANDSHAKE_TIMEOUT", "5")
+	t.Setenv("MODULES_HTTP_CLIENT_TRANSPORT_EXPECT_CONTINUE_TIMEOUT", "0.5")
+	t.Setenv("MODULES_HTTP_CLIENT_TRANSPORT_DIALER_TIMEOUT", "2")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, 10*time.Second, httpClient.Timeout)
+
+	baseTransport := clientBaseTransport(t, httpClient)
+
+	assert.Equal(t, 200, baseTransport.MaxIdleConns)
+	assert.Equal(t, 50, baseTransport.MaxConnsPerHost)
+	assert.Equal(t, 20, baseTransport.MaxIdleConnsPerHost)
+	assert.Equal(t, 60*time.Second, baseTransport.IdleConnTimeout)
+	assert.Equal(t, 5*time.Second, baseTransport.TLSHandshakeTimeout)
+	assert.Equal(t, 500*time.Millisecond, baseTransport.ExpectContinueTimeout)
+}
+
+func TestModuleWithDefaultTransportConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_TRACE_ENABLED", "false")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, fxhttpclient.DefaultTimeout*time.Second, httpClient.Timeout)
+
+	baseTransport := clientBaseTransport(t, httpClient)
+
+	//nolint:forcetypeassert
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+
+	assert.Equal(t, fxhttpclient.DefaultMaxIdleConnections, baseTransport.MaxIdleConns)
+	assert.Equal(t, fxhttpclient.DefaultMaxConnectionsPerHost, baseTransport.MaxConnsPerHost)
+	assert.Equal(t, fxhttpclient.DefaultMaxIdleConnectionsPerHost, baseTransport.MaxIdleConnsPerHost)
+	assert.Equal(t, defaultTransport.IdleConnTimeout, baseTransport.IdleConnTimeout)
+	assert.Equal(t, defaultTransport.TLSHandshakeTimeout, baseTransport.TLSHandshakeTimeout)
+	assert.Equal(t, defaultTransport.ExpectContinueTimeout, baseTransport.ExpectContinueTimeout)
+}
+
 func TestModuleDecoration(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 	t.Setenv("APP_ENV", "test")
@@ -194,3 +358,1392 @@ func TestModuleDecoration(t *testing.T) {
 
 	assert.Equal(t, http.DefaultClient, httpClient)
 }
+
+func TestModuleRequestIdPropagation(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-request-id", r.Header.Get(httpclient.HeaderXRequestId))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	member, err := baggage.NewMember(httpclient.BaggageRequestIdKey, "test-request-id")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.RequestURI = ""
+	req = req.WithContext(baggage.ContextWithBaggage(context.Background(), bag))
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "test-request-id", resp.Header.Get("received-request-id"))
+}
+
+func TestModuleRequestIdPropagationWithCustomHeader(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_REQUEST_ID_HEADER", "x-correlation-id")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-request-id", r.Header.Get(httpclient.HeaderXRequestId))
+		w.Header().Set("received-correlation-id", r.Header.Get("x-correlation-id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	member, err := baggage.NewMember(httpclient.BaggageRequestIdKey, "test-request-id")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.RequestURI = ""
+	req = req.WithContext(baggage.ContextWithBaggage(context.Background(), bag))
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", resp.Header.Get("received-request-id"))
+	assert.Equal(t, "test-request-id", resp.Header.Get("received-correlation-id"))
+}
+
+func TestModuleHeadersPropagation(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_PROPAGATE_HEADERS", "x-tenant-id")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-request-id", r.Header.Get(httpclient.HeaderXRequestId))
+		w.Header().Set("received-tenant-id", r.Header.Get("x-tenant-id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	requestIdMember, err := baggage.NewMember(httpclient.BaggageRequestIdKey, "test-request-id")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(requestIdMember)
+	assert.NoError(t, err)
+
+	headers := http.Header{}
+	headers.Set("x-tenant-id", "test-tenant-id")
+
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	ctx = propagated.WithHeaders(ctx, headers)
+
+	req := httptest.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.RequestURI = ""
+	req = req.WithContext(ctx)
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "test-request-id", resp.Header.Get("received-request-id"))
+	assert.Equal(t, "test-tenant-id", resp.Header.Get("received-tenant-id"))
+}
+
+func TestModuleWithDefaultHeaders(t *testing.T) {
+	t.Setenv("APP_ENV", "headers")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("HTTP_CLIENT_API_KEY", "test-api-key")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-user-agent", r.Header.Get("User-Agent"))
+		w.Header().Set("received-api-key", r.Header.Get("x-api-key"))
+		w.Header().Set("received-static", r.Header.Get("x-static"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	// default headers
+	req := httptest.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.RequestURI = ""
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "dev/1.2.3 yokai-httpclient", resp.Header.Get("received-user-agent"))
+	assert.Equal(t, "test-api-key", resp.Header.Get("received-api-key"))
+	assert.Equal(t, "static", resp.Header.Get("received-static"))
+
+	// explicit request headers win
+	req = httptest.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.RequestURI = ""
+	req.Header.Set("User-Agent", "request-agent")
+	req.Header.Set("x-api-key", "request-api-key")
+
+	resp, err = httpClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "request-agent", resp.Header.Get("received-user-agent"))
+	assert.Equal(t, "request-api-key", resp.Header.Get("received-api-key"))
+	assert.Equal(t, "static", resp.Header.Get("received-static"))
+}
+
+func TestModuleWithNamedClientUserAgent(t *testing.T) {
+	t.Setenv("APP_ENV", "headers")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var customClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fxhttpclient.NamedClient("custom"),
+		fx.Invoke(
+			fx.Annotate(
+				func(custom *http.Client) {
+					customClient = custom
+				},
+				fx.ParamTags(`name:"custom"`),
+			),
+		),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-user-agent", r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.RequestURI = ""
+
+	resp, err := customClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "custom-agent", resp.Header.Get("received-user-agent"))
+}
+
+func TestModuleWithRetry(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_RETRY_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_CLIENT_RETRY_MAX_ATTEMPTS", "3")
+	t.Setenv("MODULES_HTTP_CLIENT_RETRY_INITIAL_BACKOFF", "0.001")
+	t.Setenv("MODULES_HTTP_CLIENT_RETRY_MAX_BACKOFF", "0.01")
+
+	var httpClient *http.Client
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &logger, &logBuffer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	var requests atomic.Int32
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.RequestURI = ""
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), requests.Load())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"attempt": 3,
+		"method":  "GET",
+		"url":     httpServer.URL,
+		"code":    http.StatusServiceUnavailable,
+		"message": "http client request retry",
+	})
+
+	expectedMetric := `
+		# HELP http_client_retries_total Number of retried HTTP client requests
+		# TYPE http_client_retries_total counter
+		http_client_retries_total{host="` + req.URL.Host + `",method="GET"} 2
+	`
+
+	err = testutil.GatherAndCompare(metricsRegistry, strings.NewReader(expectedMetric), "http_client_retries_total")
+	assert.NoError(t, err)
+}
+
+func TestModuleWithCircuitBreaker(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_CIRCUIT_BREAKER_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_CLIENT_CIRCUIT_BREAKER_MINIMUM_REQUESTS", "2")
+	t.Setenv("MODULES_HTTP_CLIENT_CIRCUIT_BREAKER_OPEN_TIMEOUT", "60")
+
+	var httpClient *http.Client
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &logger, &logBuffer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	var requests atomic.Int32
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer httpServer.Close()
+
+	ctx := logger.WithContext(context.Background())
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := httpClient.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+
+	//nolint:bodyclose
+	_, err = httpClient.Do(req)
+	assert.ErrorIs(t, err, httpclient.ErrCircuitOpen)
+	assert.Equal(t, int32(2), requests.Load())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"host":    req.URL.Host,
+		"from":    "closed",
+		"to":      "open",
+		"message": "http client circuit breaker state change",
+	})
+
+	expectedMetric := `
+		# HELP http_client_circuit_breaker_state State of the HTTP client circuit breakers (0 closed, 1 half-open, 2 open)
+		# TYPE http_client_circuit_breaker_state gauge
+		http_client_circuit_breaker_state{host="` + req.URL.Host + `"} 2
+	`
+
+	err = testutil.GatherAndCompare(metricsRegistry, strings.NewReader(expectedMetric), "http_client_circuit_breaker_state")
+	assert.NoError(t, err)
+}
+
+func TestModuleWithCache(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_CACHE_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_CLIENT_CACHE_DEFAULT_TTL", "60")
+	t.Setenv("MODULES_HTTP_CLIENT_CACHE_MAX_SIZE", "10")
+
+	var httpClient *http.Client
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	var requests atomic.Int32
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer httpServer.Close()
+
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodGet, httpServer.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := httpClient.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// authorized requests are not cached
+	req, err := http.NewRequest(http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Empty(t, resp.Header.Get(transport.HeaderXFromCache))
+
+	assert.Equal(t, int32(2), requests.Load())
+
+	expectedMetric := `
+		# HELP http_client_cache_requests_total Number of HTTP client cacheable requests, by cache result
+		# TYPE http_client_cache_requests_total counter
+		http_client_cache_requests_total{host="` + req.URL.Host + `",result="hit"} 2
+		http_client_cache_requests_total{host="` + req.URL.Host + `",result="miss"} 1
+	`
+
+	err = testutil.GatherAndCompare(metricsRegistry, strings.NewReader(expectedMetric), "http_client_cache_requests_total")
+	assert.NoError(t, err)
+}
+
+func TestModuleWithBodyTruncationAndRedaction(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_BODY_MAX_SIZE", "25")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_REDACT_JSON_FIELDS", "password")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_RESPONSE_BODY", "false")
+
+	var httpClient *http.Client
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &logger, &logBuffer),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+		_, err := w.Write([]byte(`{"output":"ok"}`))
+		assert.NoError(t, err)
+	}))
+	defer httpServer.Close()
+
+	data := []byte(`{"login":"john","password":"secret"}`)
+	req := httptest.NewRequest(http.MethodPost, httpServer.URL, bytes.NewBuffer(data))
+	req.RequestURI = ""
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"request": `{"login":"john","password...[truncated, 40 bytes total]`,
+		"message": "http client request",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"request": "secret",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"response": `{"output":"ok"}`,
+	})
+}
+
+func TestModuleWithHeadersLogging(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	var httpClient *http.Client
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &logger, &logBuffer),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-bar", "bar")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.RequestURI = ""
+	req.Header.Set("x-foo", "foo")
+	req.Header.Set("authorization", "Bearer secret")
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":         "info",
+		"foo":           "foo",
+		"authorization": "[redacted]",
+		"message":       "http client request",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"bar":     "bar",
+		"message": "http client response",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"request": "Bearer secret",
+	})
+}
+
+func TestModuleWithMetrics(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_METRICS_COLLECT_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_CLIENT_METRICS_COLLECT_NAMESPACE", "foo")
+	t.Setenv("MODULES_HTTP_CLIENT_METRICS_COLLECT_SUBSYSTEM", "bar")
+	t.Setenv("MODULES_HTTP_CLIENT_METRICS_COLLECT_IN_FLIGHT", "true")
+	t.Setenv("MODULES_HTTP_CLIENT_METRICS_BUCKETS", "0.1, 1, 10")
+
+	var httpClient *http.Client
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer httpServer.Close()
+
+	for _, path := range []string{"/users/1", "/users/2", "/error"} {
+		resp, err := httpClient.Get(httpServer.URL + path)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+	}
+
+	host := strings.TrimPrefix(httpServer.URL, "http://")
+
+	expectedMetric := `
+		# HELP foo_bar_http_client_requests_total Number of HTTP client requests
+		# TYPE foo_bar_http_client_requests_total counter
+		foo_bar_http_client_requests_total{client="default",host="` + host + `",method="GET",status_class="2xx"} 2
+		foo_bar_http_client_requests_total{client="default",host="` + host + `",method="GET",status_class="5xx"} 1
+		# HELP foo_bar_http_client_in_flight_requests Number of in-flight HTTP client requests
+		# TYPE foo_bar_http_client_in_flight_requests gauge
+		foo_bar_http_client_in_flight_requests{client="default",host="` + host + `"} 0
+	`
+
+	err := testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"foo_bar_http_client_requests_total",
+		"foo_bar_http_client_in_flight_requests",
+	)
+	assert.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(metricsRegistry, "foo_bar_http_client_request_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestModuleWithNamedClients(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "named")
+	t.Setenv("MODULES_HTTP_CLIENT_METRICS_COLLECT_ENABLED", "true")
+
+	var httpClient *http.Client
+	var paymentsClient *http.Client
+	var ordersClient *http.Client
+	var registry *fxhttpclient.HttpClientRegistry
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxhttpclient.FxHttpClientModule,
+		fxhttpclient.NamedClient("payments"),
+		fxhttpclient.NamedClient("orders"),
+		fx.Invoke(
+			fx.Annotate(
+				func(payments *http.Client, orders *http.Client) {
+					paymentsClient = payments
+					ordersClient = orders
+				},
+				fx.ParamTags(`name:"payments"`, `name:"orders"`),
+			),
+		),
+		fx.Populate(&httpClient, &registry, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, []string{"orders", "payments"}, registry.Names())
+
+	registryPaymentsClient, err := registry.Get("payments")
+	assert.NoError(t, err)
+	assert.Same(t, paymentsClient, registryPaymentsClient)
+
+	_, err = registry.Get("invalid")
+	assert.Error(t, err)
+	assert.Equal(t, "http client invalid not found", err.Error())
+
+	// distinct instances with their own settings
+	assert.NotSame(t, httpClient, paymentsClient)
+	assert.NotSame(t, httpClient, ordersClient)
+	assert.NotSame(t, paymentsClient, ordersClient)
+
+	assert.Equal(t, 30*time.Second, httpClient.Timeout)
+	assert.Equal(t, 5*time.Second, paymentsClient.Timeout)
+	assert.Equal(t, 60*time.Second, ordersClient.Timeout)
+
+	// shared metrics, with client label
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer httpServer.Close()
+
+	for _, client := range []*http.Client{httpClient, paymentsClient, ordersClient} {
+		resp, err := client.Get(httpServer.URL)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+	}
+
+	host := strings.TrimPrefix(httpServer.URL, "http://")
+
+	expectedMetric := `
+		# HELP http_client_requests_total Number of HTTP client requests
+		# TYPE http_client_requests_total counter
+		http_client_requests_total{client="default",host="` + host + `",method="GET",status_class="2xx"} 1
+		http_client_requests_total{client="orders",host="` + host + `",method="GET",status_class="2xx"} 1
+		http_client_requests_total{client="payments",host="` + host + `",method="GET",status_class="2xx"} 1
+	`
+
+	err = testutil.GatherAndCompare(metricsRegistry, strings.NewReader(expectedMetric), "http_client_requests_total")
+	assert.NoError(t, err)
+}
+
+func TestModuleWithNamedClientsTransportConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "pools")
+
+	var paymentsClient *http.Client
+	var searchClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fxhttpclient.NamedClient("payments"),
+		fxhttpclient.NamedClient("search"),
+		fx.Invoke(
+			fx.Annotate(
+				func(payments *http.Client, search *http.Client) {
+					paymentsClient = payments
+					searchClient = search
+				},
+				fx.ParamTags(`name:"payments"`, `name:"search"`),
+			),
+		),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, 5*time.Second, paymentsClient.Timeout)
+	assert.Equal(t, 2*time.Second, searchClient.Timeout)
+
+	// payments client transport and TLS settings
+	paymentsTransport := clientBaseTransport(t, paymentsClient)
+
+	assert.Equal(t, fxhttpclient.DefaultMaxIdleConnections, paymentsTransport.MaxIdleConns)
+	assert.Equal(t, 10, paymentsTransport.MaxConnsPerHost)
+	assert.Equal(t, 5, paymentsTransport.MaxIdleConnsPerHost)
+	assert.Equal(t, 2*time.Second, paymentsTransport.TLSHandshakeTimeout)
+	assert.Equal(t, uint16(tls.VersionTLS13), paymentsTransport.TLSClientConfig.MinVersion)
+	assert.False(t, paymentsTransport.TLSClientConfig.InsecureSkipVerify)
+
+	// search client transport and TLS settings
+	searchTransport := clientBaseTransport(t, searchClient)
+
+	assert.Equal(t, 500, searchTransport.MaxIdleConns)
+	assert.Equal(t, 200, searchTransport.MaxConnsPerHost)
+	assert.Equal(t, fxhttpclient.DefaultMaxIdleConnectionsPerHost, searchTransport.MaxIdleConnsPerHost)
+	assert.Equal(t, 120*time.Second, searchTransport.IdleConnTimeout)
+	assert.True(t, searchTransport.TLSClientConfig.InsecureSkipVerify)
+
+	// only the search client accepts the self-signed certificate
+	httpServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	resp, err := searchClient.Get(httpServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	//nolint:bodyclose
+	_, err = paymentsClient.Get(httpServer.URL)
+	assert.Error(t, err)
+}
+
+func TestModuleWithLogLevels(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "levels")
+
+	var httpClient *http.Client
+	var strictClient *http.Client
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fxhttpclient.NamedClient("strict"),
+		fx.Invoke(
+			fx.Annotate(
+				func(strict *http.Client) {
+					strictClient = strict
+				},
+				fx.ParamTags(`name:"strict"`),
+			),
+		),
+		fx.Populate(&httpClient, &logger, &logBuffer),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, err := strconv.Atoi(r.URL.Query().Get("code"))
+		assert.NoError(t, err)
+
+		w.WriteHeader(code)
+	}))
+	defer httpServer.Close()
+
+	tests := []struct {
+		client        *http.Client
+		code          int
+		expectedLevel string
+	}{
+		{httpClient, http.StatusNotFound, "info"},
+		{httpClient, http.StatusBadRequest, "warn"},
+		{httpClient, http.StatusServiceUnavailable, "warn"},
+		{strictClient, http.StatusNotFound, "error"},
+	}
+
+	for _, tt := range tests {
+		logBuffer.Reset()
+
+		url := httpServer.URL + "?code=" + strconv.Itoa(tt.code)
+
+		req, err := http.NewRequestWithContext(logger.WithContext(context.Background()), http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		resp, err := tt.client.Do(req)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+			"level":   tt.expectedLevel,
+			"url":     url,
+			"code":    tt.code,
+			"message": "http client response",
+		})
+	}
+}
+
+func TestModuleWithInvalidLogLevels(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "levels")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_LEVELS", `{"40x": "info"}`)
+
+	var httpClient *http.Client
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "invalid http client log level response code 40x")
+}
+
+func TestModuleWithSkippedStreamedResponseBody(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_RESPONSE_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_RESPONSE_BODY", "true")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_BODY_MAX_SIZE", "10")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_RESPONSE_BODY_STREAMING", "skip")
+
+	var httpClient *http.Client
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &logger, &logBuffer),
+	).RequireStart().RequireStop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+
+		_, err := w.Write([]byte(strings.Repeat("a", 100)))
+		assert.NoError(t, err)
+	}))
+	defer httpServer.Close()
+
+	req, err := http.NewRequestWithContext(logger.WithContext(context.Background()), http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+
+	// logged before the body is read
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"response": transport.StreamingBodyValue,
+		"message":  "http client response",
+	})
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("a", 100), string(body))
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+}
+
+func TestModuleWithInvalidResponseBodyStreaming(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_LOG_RESPONSE_BODY_STREAMING", "invalid")
+
+	var httpClient *http.Client
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "invalid http client log response body streaming mode invalid")
+}
+
+func TestModuleWithCompression(t *testing.T) {
+	content := strings.Repeat("compressed content ", 10)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("received-accept-encoding", r.Header.Get("Accept-Encoding"))
+
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			_, err := w.Write([]byte(content))
+			assert.NoError(t, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+
+		writer := gzip.NewWriter(w)
+
+		_, err := writer.Write([]byte(content))
+		assert.NoError(t, err)
+
+		err = writer.Close()
+		assert.NoError(t, err)
+	}))
+	defer httpServer.Close()
+
+	tests := []struct {
+		name                   string
+		env                    map[string]string
+		expectedAcceptEncoding string
+		expectedEncoding       string
+		expectedResponse       string
+	}{
+		{
+			name:                   "transparent compression",
+			env:                    map[string]string{},
+			expectedAcceptEncoding: "gzip",
+			expectedEncoding:       "",
+			expectedResponse:       content,
+		},
+		{
+			name: "disabled compression",
+			env: map[string]string{
+				"MODULES_HTTP_CLIENT_TRANSPORT_DISABLE_COMPRESSION": "true",
+			},
+			expectedAcceptEncoding: "",
+			expectedEncoding:       "",
+			expectedResponse:       content,
+		},
+		{
+			name: "disabled compression with accept encoding",
+			env: map[string]string{
+				"MODULES_HTTP_CLIENT_TRANSPORT_DISABLE_COMPRESSION": "true",
+				"MODULES_HTTP_CLIENT_TRANSPORT_ACCEPT_ENCODING":     "gzip",
+			},
+			expectedAcceptEncoding: "gzip",
+			expectedEncoding:       "gzip",
+			expectedResponse:       "[gzip encoded body, ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			t.Setenv("MODULES_HTTP_CLIENT_LOG_RESPONSE_ENABLED", "true")
+			t.Setenv("MODULES_HTTP_CLIENT_LOG_RESPONSE_BODY", "true")
+
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var httpClient *http.Client
+			var logger *log.Logger
+			var logBuffer logtest.TestLogBuffer
+
+			fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxhttpclient.FxHttpClientModule,
+				fx.Populate(&httpClient, &logger, &logBuffer),
+			).RequireStart().RequireStop()
+
+			req, err := http.NewRequestWithContext(logger.WithContext(context.Background()), http.MethodGet, httpServer.URL, nil)
+			assert.NoError(t, err)
+
+			resp, err := httpClient.Do(req)
+			assert.NoError(t, err)
+
+			_, err = io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			err = resp.Body.Close()
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.expectedAcceptEncoding, resp.Header.Get("received-accept-encoding"))
+			assert.Equal(t, tt.expectedEncoding, resp.Header.Get("Content-Encoding"))
+
+			logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+				"response": tt.expectedResponse,
+				"message":  "http client response",
+			})
+		})
+	}
+}
+
+func TestModuleWithTlsCaFile(t *testing.T) {
+	httpServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: httpServer.Certificate().Raw}), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_TLS_CA_FILE", caFile)
+	t.Setenv("MODULES_HTTP_CLIENT_TLS_MIN_VERSION", "1.2")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	resp, err := httpClient.Get(httpServer.URL)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestModuleWithTlsInsecureSkipVerify(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_TLS_INSECURE_SKIP_VERIFY", "true")
+
+	var httpClient *http.Client
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &logBuffer),
+	).RequireStart().RequireStop()
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"message": "TLS certificates verification is DISABLED",
+	})
+
+	httpServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	resp, err := httpClient.Get(httpServer.URL)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+}
+
+func TestModuleWithInvalidTlsConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_TLS_CA_FILE", "/invalid/ca.pem")
+
+	var httpClient *http.Client
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "invalid http client tls configuration: cannot read tls ca file")
+}
+
+func TestModuleWithProxy(t *testing.T) {
+	var proxyAuthorization string
+
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyAuthorization = r.Header.Get("Proxy-Authorization")
+
+		w.WriteHeader(http.StatusOK)
+		//nolint:errcheck
+		w.Write([]byte("proxied " + r.URL.String()))
+	}))
+	defer proxyServer.Close()
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_PROXY_URL", strings.Replace(proxyServer.URL, "http://", "http://user:secret@", 1))
+	t.Setenv("MODULES_HTTP_CLIENT_PROXY_FROM_ENVIRONMENT", "true")
+
+	var httpClient *http.Client
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// explicit url wins over the environment
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"client":  "default",
+		"proxy":   strings.Replace(proxyServer.URL, "http://", "http://user:xxxxx@", 1),
+		"message": "http client: both proxy url and proxy from environment are configured, using the proxy url",
+	})
+
+	resp, err := httpClient.Get("http://example.com/foo")
+	assert.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "proxied http://example.com/foo", string(body))
+	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")), proxyAuthorization)
+
+	// credentials never logged
+	assert.NotContains(t, logBuffer.Buffer().String(), "secret")
+}
+
+func TestModuleWithInvalidProxyConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_PROXY_URL", "proxy.internal")
+
+	var httpClient *http.Client
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "invalid http client proxy configuration: invalid proxy url proxy.internal")
+}
+
+func TestModuleWithOAuth2(t *testing.T) {
+	var fetches int32
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientId, clientSecret, ok := r.BasicAuth()
+		if !ok || clientId != "client-id" || clientSecret != "file-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		err := r.ParseForm()
+		assert.NoError(t, err)
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+		assert.Equal(t, "test-audience", r.PostForm.Get("audience"))
+
+		atomic.AddInt32(&fetches, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck
+		w.Write([]byte(`{"access_token":"secret-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-authorization", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	secretFile := filepath.Join(t.TempDir(), "client-secret")
+	err := os.WriteFile(secretFile, []byte("file-secret\n"), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "named")
+	t.Setenv("MODULES_HTTP_CLIENT_CLIENTS_PAYMENTS_OAUTH2_TOKEN_URL", tokenServer.URL)
+	t.Setenv("MODULES_HTTP_CLIENT_CLIENTS_PAYMENTS_OAUTH2_CLIENT_ID", "client-id")
+	t.Setenv("MODULES_HTTP_CLIENT_CLIENTS_PAYMENTS_OAUTH2_CLIENT_SECRET_FILE", secretFile)
+	t.Setenv("MODULES_HTTP_CLIENT_CLIENTS_PAYMENTS_OAUTH2_SCOPES", "read write")
+	t.Setenv("MODULES_HTTP_CLIENT_CLIENTS_PAYMENTS_OAUTH2_AUDIENCE", "test-audience")
+
+	var httpClient *http.Client
+	var registry *fxhttpclient.HttpClientRegistry
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &registry, &logBuffer),
+	).RequireStart().RequireStop()
+
+	paymentsClient, err := registry.Get("payments")
+	assert.NoError(t, err)
+
+	// token fetched lazily, then reused
+	assert.Equal(t, int32(0), atomic.LoadInt32(&fetches))
+
+	for i := 0; i < 2; i++ {
+		resp, err := paymentsClient.Get(httpServer.URL)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Bearer secret-token", resp.Header.Get("received-authorization"))
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// other clients not authenticated
+	resp, err := httpClient.Get(httpServer.URL)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Empty(t, resp.Header.Get("received-authorization"))
+
+	// token never logged
+	assert.NotContains(t, logBuffer.Buffer().String(), "secret-token")
+	assert.NotContains(t, logBuffer.Buffer().String(), "file-secret")
+}
+
+func TestModuleWithOAuth2TokenError(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer tokenServer.Close()
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_OAUTH2_TOKEN_URL", tokenServer.URL)
+	t.Setenv("MODULES_HTTP_CLIENT_OAUTH2_CLIENT_ID", "client-id")
+	t.Setenv("MODULES_HTTP_CLIENT_OAUTH2_CLIENT_SECRET", "invalid-secret")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	//nolint:bodyclose
+	_, err := httpClient.Get("http://example.com")
+	assert.Error(t, err)
+
+	var tokenErr *httpclient.OAuth2TokenError
+	assert.True(t, errors.As(err, &tokenErr))
+	assert.Equal(t, tokenServer.URL, tokenErr.TokenUrl)
+}
+
+func TestModuleWithInvalidOAuth2Config(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_OAUTH2_TOKEN_URL", "http://example.com/token")
+	t.Setenv("MODULES_HTTP_CLIENT_OAUTH2_CLIENT_ID", "client-id")
+	t.Setenv("MODULES_HTTP_CLIENT_OAUTH2_CLIENT_SECRET_FILE", "invalid/secret")
+
+	var httpClient *http.Client
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "invalid http client oauth2 client secret file")
+}
+
+func TestModuleWithValidConfigSchema(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "pools")
+	t.Setenv("APP_PROFILES", "strict")
+
+	var registry *fxhttpclient.HttpClientRegistry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&registry),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, []string{"payments", "search"}, registry.Names())
+}
+
+func TestModuleWithInvalidConfigSchema(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_PROFILES", "typo,strict")
+
+	var httpClient *http.Client
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	)
+	assert.NoError(t, app.Err())
+
+	err := app.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(
+		t,
+		err.Error(),
+		"invalid config: "+
+			"modules.http.client.clients.payments.log.response_body_streaming: invalid value discard, allowed values: capture, skip; "+
+			"modules.http.client.clients.payments.transport.max_conections_per_host: unknown config key, "+
+			"did you mean modules.http.client.clients.*.transport.max_connections_per_host?; "+
+			"modules.http.client.timeot: unknown config key, did you mean modules.http.client.timeout?",
+	)
+}
+
+// clientBaseTransport returns the [http.Transport] at the bottom of the transports stack of a client.
+func clientBaseTransport(t *testing.T, client *http.Client) *http.Transport {
+	t.Helper()
+
+	roundTripper := client.Transport
+
+	for {
+		switch current := roundTripper.(type) {
+		case *transport.BaseTransport:
+			return current.Base()
+		case interface{ Base() http.RoundTripper }:
+			roundTripper = current.Base()
+		default:
+			t.Fatalf("unexpected transport %T", roundTripper)
+
+			return nil
+		}
+	}
+}
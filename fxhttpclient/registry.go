@@ -0,0 +1,60 @@
+package fxhttpclient
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"go.uber.org/fx"
+)
+
+// HttpClientRegistry is the registry of the named [http.Client], configured by the modules.http.client.clients.<name>
+// config keys.
+type HttpClientRegistry struct {
+	clients map[string]*http.Client
+}
+
+// NewHttpClientRegistry returns a new [HttpClientRegistry], for a provided map of named [http.Client].
+func NewHttpClientRegistry(clients map[string]*http.Client) *HttpClientRegistry {
+	return &HttpClientRegistry{
+		clients: clients,
+	}
+}
+
+// Names returns the sorted names of the registered [http.Client].
+func (r *HttpClientRegistry) Names() []string {
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns the [http.Client] registered for a provided name.
+func (r *HttpClientRegistry) Get(name string) (*http.Client, error) {
+	client, ok := r.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("http client %s not found", name)
+	}
+
+	return client, nil
+}
+
+// NamedClient provides the named [http.Client] of a provided name from the [HttpClientRegistry], annotated with the
+// name:"<name>" tag, so that constructors can depend on it.
+//
+// For example, fxhttpclient.NamedClient("payments") allows injecting the client configured by the
+// modules.http.client.clients.payments config keys with a `name:"payments"` tagged parameter.
+func NamedClient(name string) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func(registry *HttpClientRegistry) (*http.Client, error) {
+				return registry.Get(name)
+			},
+			fx.ResultTags(fmt.Sprintf(`name:"%s"`, name)),
+		),
+	)
+}
@@ -0,0 +1,158 @@
+package fxhttpserver
+
+import (
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/config/listener"
+	httpservermiddleware "github.com/ankorstore/yokai/httpserver/middleware"
+)
+
+// serverConfig is the typed modules.http.server config.
+type serverConfig struct {
+	MaxHeaderBytes config.ByteSize `mapstructure:"max_header_bytes"`
+	Log            logConfig       `mapstructure:"log"`
+	Recovery       recoveryConfig  `mapstructure:"recovery"`
+	Timeout        timeoutConfig   `mapstructure:"timeout"`
+	Cache          cacheConfig     `mapstructure:"cache"`
+	Uploads        uploadsConfig   `mapstructure:"uploads"`
+	Listener       listener.Config `mapstructure:"listener"`
+}
+
+// logConfig is the typed modules.http.server.log config.
+type logConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// recoveryConfig is the typed modules.http.server.recovery config.
+type recoveryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// timeoutConfig is the typed modules.http.server.timeout config.
+type timeoutConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Duration time.Duration `mapstructure:"duration"`
+	Exclude  []string      `mapstructure:"exclude"`
+}
+
+// cacheConfig is the typed modules.http.server.cache config.
+type cacheConfig struct {
+	Enabled            bool              `mapstructure:"enabled"`
+	TTL                time.Duration     `mapstructure:"ttl"`
+	TTLs               map[string]string `mapstructure:"ttls"`
+	MaxEntries         int               `mapstructure:"max_entries"`
+	Vary               []string          `mapstructure:"vary"`
+	AllowAuthorization bool              `mapstructure:"allow_authorization"`
+}
+
+// uploadsConfig is the typed modules.http.server.uploads config.
+type uploadsConfig struct {
+	MaxMemory           config.ByteSize `mapstructure:"max_memory"`
+	TempDir             string          `mapstructure:"temp_dir"`
+	AllowedContentTypes []string        `mapstructure:"allowed_content_types"`
+	MaxFileSize         config.ByteSize `mapstructure:"max_file_size"`
+}
+
+// configuredServer decodes the modules.http.server config, with its defaults.
+func configuredServer(cfg *config.Config) (serverConfig, error) {
+	serverCfg := serverConfig{
+		MaxHeaderBytes: DefaultMaxHeaderBytes,
+		Log:            logConfig{Enabled: true},
+		Recovery:       recoveryConfig{Enabled: true},
+	}
+
+	if err := cfg.UnmarshalKey("modules.http.server", &serverCfg); err != nil {
+		return serverConfig{}, err
+	}
+
+	return serverCfg, nil
+}
+
+// configSchema returns the modules.http.server config schema, to report the unknown keys (like typos) and invalid
+// values when the application starts.
+func configSchema() *config.Schema {
+	return config.NewSchema(
+		"modules.http.server",
+		config.SchemaKey{Path: "enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "port", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "base_url", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "max_header_bytes", Type: config.SchemaTypeByteSize},
+		config.SchemaKey{Path: "listener.backlog", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "listener.reuse_port", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "trusted_proxies", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "forwarded_headers.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "exclude", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "h2c.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "admin.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "admin.port", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "admin.healthcheck.startup.path", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "admin.healthcheck.liveness.path", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "admin.healthcheck.readiness.path", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "admin.debug.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "admin.debug.routes.path", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "admin.debug.pprof.path", Type: config.SchemaTypeString},
+		config.SchemaKey{
+			Path:    "router.trailing_slash",
+			Type:    config.SchemaTypeString,
+			Allowed: []string{TrailingSlashStrict, TrailingSlashRedirect, TrailingSlashStrip},
+		},
+		config.SchemaKey{Path: "routing.remove_trailing_slash", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "routing.add_trailing_slash", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "routing.redirect_code", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "json.serializer", Type: config.SchemaTypeString, Allowed: []string{"stdlib", "goccy"}},
+		config.SchemaKey{Path: "json_serializer", Type: config.SchemaTypeString, Allowed: []string{"stdlib", "goccy"}},
+		config.SchemaKey{Path: "validation.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "request_id.trust_incoming", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "propagate.headers", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "errors.obfuscate", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "errors.stack", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "recovery.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "recovery.status", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "recovery.readiness.threshold", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "recovery.readiness.window", Type: config.SchemaTypeDuration},
+		config.SchemaKey{Path: "log.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "log.headers", Type: config.SchemaTypeMap},
+		config.SchemaKey{Path: "log.exclude", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "log.level_from_response", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "log.route", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "log.handler", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "log.success_sample_rate", Type: config.SchemaTypeFloat},
+		config.SchemaKey{Path: "log.sample_rate", Type: config.SchemaTypeFloat},
+		config.SchemaKey{Path: "log.body.request", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "log.body.response", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "trace.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "trace.exclude", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "trace.response_header", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "metrics.collect.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "metrics.collect.namespace", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "metrics.collect.subsystem", Type: config.SchemaTypeString},
+		config.SchemaKey{
+			Path:    "metrics.type",
+			Type:    config.SchemaTypeString,
+			Allowed: []string{httpservermiddleware.HttpServerMetricsTypeHistogram, httpservermiddleware.HttpServerMetricsTypeSummary},
+		},
+		config.SchemaKey{Path: "metrics.buckets", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "metrics.size_buckets", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "metrics.objectives", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "metrics.normalize", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "metrics.expose.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "metrics.expose.path", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "metrics.expose.token", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "timeout.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "timeout.duration", Type: config.SchemaTypeDuration},
+		config.SchemaKey{Path: "timeout.exclude", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "cache.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "cache.ttl", Type: config.SchemaTypeDuration},
+		config.SchemaKey{Path: "cache.max_entries", Type: config.SchemaTypeInt},
+		config.SchemaKey{Path: "cache.ttls.*", Type: config.SchemaTypeDuration},
+		config.SchemaKey{Path: "cache.vary", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "cache.allow_authorization", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "uploads.max_memory", Type: config.SchemaTypeByteSize},
+		config.SchemaKey{Path: "uploads.temp_dir", Type: config.SchemaTypeString},
+		config.SchemaKey{Path: "uploads.allowed_content_types", Type: config.SchemaTypeList},
+		config.SchemaKey{Path: "uploads.max_file_size", Type: config.SchemaTypeByteSize},
+		config.SchemaKey{Path: "templates.enabled", Type: config.SchemaTypeBool},
+		config.SchemaKey{Path: "templates.path", Type: config.SchemaTypeString},
+	)
+}
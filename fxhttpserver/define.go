@@ -1,22 +1,35 @@
 package fxhttpserver
 
+import "github.com/ankorstore/yokai/httpserver"
+
 // MiddlewareDefinition is the interface for middlewares definitions.
 type MiddlewareDefinition interface {
 	Concrete() bool
 	Middleware() any
 	Kind() MiddlewareKind
+	Options() MiddlewareOptions
+	Position() int64
 }
 
 type middlewareDefinition struct {
 	middleware any
 	kind       MiddlewareKind
+	options    MiddlewareOptions
+	position   int64
 }
 
 // NewMiddlewareDefinition returns a new [MiddlewareDefinition].
-func NewMiddlewareDefinition(middleware any, kind MiddlewareKind) MiddlewareDefinition {
+func NewMiddlewareDefinition(middleware any, kind MiddlewareKind, options ...MiddlewareOption) MiddlewareDefinition {
+	return NewMiddlewareDefinitionWithPosition(middleware, kind, 0, options...)
+}
+
+// NewMiddlewareDefinitionWithPosition returns a new [MiddlewareDefinition], with a registration position.
+func NewMiddlewareDefinitionWithPosition(middleware any, kind MiddlewareKind, position int64, options ...MiddlewareOption) MiddlewareDefinition {
 	return &middlewareDefinition{
 		middleware: middleware,
 		kind:       kind,
+		options:    ApplyMiddlewareOptions(options...),
+		position:   position,
 	}
 }
 
@@ -35,6 +48,16 @@ func (d *middlewareDefinition) Kind() MiddlewareKind {
 	return d.kind
 }
 
+// Options returns the middleware options.
+func (d *middlewareDefinition) Options() MiddlewareOptions {
+	return d.options
+}
+
+// Position returns the middleware registration position.
+func (d *middlewareDefinition) Position() int64 {
+	return d.position
+}
+
 // HandlerDefinition is the interface for handlers definitions.
 type HandlerDefinition interface {
 	Concrete() bool
@@ -42,6 +65,7 @@ type HandlerDefinition interface {
 	Path() string
 	Handler() any
 	Middlewares() []MiddlewareDefinition
+	Options() HandlerOptions
 }
 
 type handlerDefinition struct {
@@ -49,15 +73,17 @@ type handlerDefinition struct {
 	path        string
 	handler     any
 	middlewares []MiddlewareDefinition
+	options     HandlerOptions
 }
 
 // NewHandlerDefinition returns a new [HandlerDefinition].
-func NewHandlerDefinition(method string, path string, handler any, middlewares []MiddlewareDefinition) HandlerDefinition {
+func NewHandlerDefinition(method string, path string, handler any, middlewares []MiddlewareDefinition, options ...HandlerOption) HandlerDefinition {
 	return &handlerDefinition{
 		method:      method,
 		path:        path,
 		handler:     handler,
 		middlewares: middlewares,
+		options:     ApplyHandlerOptions(options...),
 	}
 }
 
@@ -86,6 +112,11 @@ func (d *handlerDefinition) Middlewares() []MiddlewareDefinition {
 	return d.middlewares
 }
 
+// Options returns the handler options.
+func (d *handlerDefinition) Options() HandlerOptions {
+	return d.options
+}
+
 // HandlersGroupDefinition is the interface for handlers groups definitions.
 type HandlersGroupDefinition interface {
 	Prefix() string
@@ -122,3 +153,32 @@ func (h *handlersGroupDefinition) Handlers() []HandlerDefinition {
 func (h *handlersGroupDefinition) Middlewares() []MiddlewareDefinition {
 	return h.middlewares
 }
+
+// ErrorMapperDefinition is the interface for error mappers definitions.
+type ErrorMapperDefinition interface {
+	Mapper() httpserver.ErrorMapper
+	Position() int64
+}
+
+type errorMapperDefinition struct {
+	mapper   httpserver.ErrorMapper
+	position int64
+}
+
+// NewErrorMapperDefinition returns a new [ErrorMapperDefinition].
+func NewErrorMapperDefinition(mapper httpserver.ErrorMapper, position int64) ErrorMapperDefinition {
+	return &errorMapperDefinition{
+		mapper:   mapper,
+		position: position,
+	}
+}
+
+// Mapper returns the error mapper.
+func (d *errorMapperDefinition) Mapper() httpserver.ErrorMapper {
+	return d.mapper
+}
+
+// Position returns the error mapper registration position.
+func (d *errorMapperDefinition) Position() int64 {
+	return d.position
+}
@@ -1,12 +1,14 @@
 package fxhttpserver_test
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
 	"github.com/ankorstore/yokai/fxhttpserver"
 	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
 	"github.com/ankorstore/yokai/fxhttpserver/testdata/middleware"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,6 +21,17 @@ func TestMiddlewareDefinition(t *testing.T) {
 
 	assert.False(t, md.Concrete())
 	assert.Equal(t, kind, md.Kind())
+	assert.Equal(t, 0, md.Options().Priority)
+	assert.Equal(t, int64(0), md.Position())
+
+	md = fxhttpserver.NewMiddlewareDefinition(middleware.NewTestGlobalMiddleware, kind, fxhttpserver.WithPriority(10))
+
+	assert.Equal(t, 10, md.Options().Priority)
+
+	md = fxhttpserver.NewMiddlewareDefinitionWithPosition(middleware.NewTestGlobalMiddleware, kind, 3, fxhttpserver.WithPriority(10))
+
+	assert.Equal(t, 10, md.Options().Priority)
+	assert.Equal(t, int64(3), md.Position())
 }
 
 func TestHandlerDefinition(t *testing.T) {
@@ -57,3 +70,18 @@ func TestHandlersGroupDefinition(t *testing.T) {
 	assert.Equal(t, handlers, hgd.Handlers())
 	assert.Equal(t, middlewares, hgd.Middlewares())
 }
+
+func TestErrorMapperDefinition(t *testing.T) {
+	t.Parallel()
+
+	mapper := func(err error) (*echo.HTTPError, bool) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, "mapped"), true
+	}
+
+	emd := fxhttpserver.NewErrorMapperDefinition(mapper, 42)
+
+	httpErr, ok := emd.Mapper()(errors.New("test"))
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, httpErr.Code)
+	assert.Equal(t, int64(42), emd.Position())
+}
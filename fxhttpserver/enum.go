@@ -22,3 +22,26 @@ func (k MiddlewareKind) String() string {
 		return "global-use"
 	}
 }
+
+// DefaultMiddleware is an enum for the module default middlewares (logger, metrics, tracer).
+type DefaultMiddleware int
+
+const (
+	Logger DefaultMiddleware = iota
+	Metrics
+	Tracer
+)
+
+// String returns a string representation of a [DefaultMiddleware].
+func (m DefaultMiddleware) String() string {
+	switch m {
+	case Logger:
+		return "logger"
+	case Metrics:
+		return "metrics"
+	case Tracer:
+		return "tracer"
+	default:
+		return "logger"
+	}
+}
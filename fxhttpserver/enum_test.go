@@ -30,3 +30,26 @@ func TestMiddlewareKindAsString(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultMiddlewareAsString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    fxhttpserver.DefaultMiddleware
+		expected string
+	}{
+		{fxhttpserver.Logger, "logger"},
+		{fxhttpserver.Metrics, "metrics"},
+		{fxhttpserver.Tracer, "tracer"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expected, func(t *testing.T) {
+			t.Parallel()
+
+			actual := tt.input.String()
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 // FxHttpServerModuleInfo is a module info collector for fxcore.
 type FxHttpServerModuleInfo struct {
 	Port         int
+	AdminEnabled bool
+	AdminPort    int
 	Debug        bool
 	Logger       string
 	Binder       string
@@ -21,13 +23,10 @@ type FxHttpServerModuleInfo struct {
 
 // NewFxHttpServerModuleInfo returns a new [FxHttpServerModuleInfo].
 func NewFxHttpServerModuleInfo(httpServer *echo.Echo, cfg *config.Config) *FxHttpServerModuleInfo {
-	port := cfg.GetInt("modules.http.server.port")
-	if port == 0 {
-		port = DefaultPort
-	}
-
 	return &FxHttpServerModuleInfo{
-		Port:         port,
+		Port:         configuredPort(cfg, "modules.http.server.port", DefaultPort),
+		AdminEnabled: isAdminEnabled(cfg),
+		AdminPort:    configuredPort(cfg, "modules.http.server.admin.port", DefaultAdminPort),
 		Debug:        httpServer.Debug,
 		Logger:       fmt.Sprintf("%T", httpServer.Logger),
 		Binder:       fmt.Sprintf("%T", httpServer.Binder),
@@ -46,7 +45,11 @@ func (i *FxHttpServerModuleInfo) Name() string {
 // Data return the data of the module info.
 func (i *FxHttpServerModuleInfo) Data() map[string]interface{} {
 	return map[string]interface{}{
-		"port":         i.Port,
+		"port": i.Port,
+		"admin": map[string]interface{}{
+			"enabled": i.AdminEnabled,
+			"port":    i.AdminPort,
+		},
 		"debug":        i.Debug,
 		"binder":       i.Binder,
 		"serializer":   i.Serializer,
@@ -27,7 +27,11 @@ func TestNewFxHttpServerModuleInfo(t *testing.T) {
 	assert.Equal(
 		t,
 		map[string]interface{}{
-			"port":         fxhttpserver.DefaultPort,
+			"port": fxhttpserver.DefaultPort,
+			"admin": map[string]interface{}{
+				"enabled": false,
+				"port":    fxhttpserver.DefaultAdminPort,
+			},
 			"debug":        true,
 			"binder":       "*echo.DefaultBinder",
 			"serializer":   "*echo.DefaultJSONSerializer",
@@ -38,3 +42,26 @@ func TestNewFxHttpServerModuleInfo(t *testing.T) {
 		info.Data(),
 	)
 }
+
+func TestNewFxHttpServerModuleInfoWithAdminServer(t *testing.T) {
+	t.Setenv("MODULES_HTTP_SERVER_PORT", "8000")
+	t.Setenv("MODULES_HTTP_SERVER_ADMIN_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_ADMIN_PORT", "9000")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config"),
+	)
+	assert.NoError(t, err)
+
+	info := fxhttpserver.NewFxHttpServerModuleInfo(echo.New(), cfg)
+
+	assert.Equal(t, 8000, info.Data()["port"])
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"enabled": true,
+			"port":    9000,
+		},
+		info.Data()["admin"],
+	)
+}
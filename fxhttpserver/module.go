@@ -2,26 +2,58 @@ package fxhttpserver
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ankorstore/yokai/config"
-	"github.com/ankorstore/yokai/generate/uuid"
+	"github.com/ankorstore/yokai/config/listener"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/generate/id"
+	"github.com/ankorstore/yokai/healthcheck"
 	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/handler"
+	"github.com/ankorstore/yokai/httpserver/httpservertest"
 	httpservermiddleware "github.com/ankorstore/yokai/httpserver/middleware"
 	"github.com/ankorstore/yokai/log"
 	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
+	"golang.org/x/net/http2"
 )
 
 const (
-	ModuleName  = "httpserver"
-	DefaultPort = 8080
+	ModuleName                           = "httpserver"
+	DefaultPort                          = 8080
+	DefaultAdminPort                     = 8082
+	DefaultMetricsPath                   = "/metrics"
+	DefaultAdminHealthCheckStartupPath   = "/healthz"
+	DefaultAdminHealthCheckLivenessPath  = "/livez"
+	DefaultAdminHealthCheckReadinessPath = "/readyz"
+	DefaultAdminDebugRoutesPath          = "/debug/routes"
+	DefaultAdminDebugPProfPath           = "/debug/pprof"
+	DefaultRecoveryReadinessWindow       = time.Minute
+	DefaultMaxHeaderBytes                = 64 << 10 // 64KB, instead of the net/http 1MB
 )
 
+// Trailing slash handling modes of the modules.http.server.router.trailing_slash config.
+const (
+	TrailingSlashStrict   = "strict"   // routes matched as registered, echo default behavior
+	TrailingSlashRedirect = "redirect" // requests redirected to the path without trailing slash
+	TrailingSlashStrip    = "strip"    // trailing slash internally removed from the path before routing
+)
+
+// DefaultExcludedPaths are the paths excluded by default from the logging, tracing and metrics middlewares.
+var DefaultExcludedPaths = []string{"/healthz", "/readyz", "/metrics"}
+
 // FxHttpServerModule is the [Fx] httpserver module.
 //
 // [Fx]: https://github.com/uber-go/fx
@@ -37,6 +69,7 @@ var FxHttpServerModule = fx.Module(
 			fx.ResultTags(`group:"core-module-infos"`),
 		),
 	),
+	fxconfig.AsConfigSchema(configSchema()),
 )
 
 // FxHttpServerParam allows injection of the required dependencies in [NewFxHttpServer].
@@ -44,12 +77,18 @@ type FxHttpServerParam struct {
 	fx.In
 	LifeCycle       fx.Lifecycle
 	Factory         httpserver.HttpServerFactory
-	Generator       uuid.UuidGenerator
+	Generator       id.IdGenerator
 	Registry        *HttpServerRegistry
 	Config          *config.Config
 	Logger          *log.Logger
+	AuditLogger     *log.AuditLogger `optional:"true"`
 	TracerProvider  trace.TracerProvider
 	MetricsRegistry *prometheus.Registry
+	Checker         *healthcheck.Checker             `optional:"true"`
+	Shutdown        *healthcheck.ShutdownCoordinator `optional:"true"`
+	JsonSerializer  echo.JSONSerializer              `optional:"true"`
+	Validations     []*httpserver.Validation         `group:"httpserver-validations"`
+	ErrorMappers    []ErrorMapperDefinition          `group:"httpserver-error-mappers"`
 }
 
 // NewFxHttpServer returns a new [echo.Echo].
@@ -67,17 +106,68 @@ func NewFxHttpServer(p FxHttpServerParam) (*echo.Echo, error) {
 		renderer = httpserver.NewHtmlTemplateRenderer(p.Config.GetString("modules.http.server.templates.path"))
 	}
 
+	// json serializer
+	jsonSerializer := p.JsonSerializer
+	if jsonSerializer == nil {
+		jsonSerializer = httpserver.NewJsonSerializer(
+			httpserver.FetchJsonSerializer(configuredJsonSerializer(p.Config)),
+		)
+	}
+
+	// validator
+	var validator echo.Validator
+	if p.Config.GetBool("modules.http.server.validation.enabled") {
+		echoValidator, err := httpserver.NewEchoValidator(p.Validations...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create http server validator: %w", err)
+		}
+
+		validator = echoValidator
+	}
+
+	// error mappers, in registration order
+	errorMapperDefinitions := append([]ErrorMapperDefinition{}, p.ErrorMappers...)
+	sort.SliceStable(errorMapperDefinitions, func(i, j int) bool {
+		return errorMapperDefinitions[i].Position() < errorMapperDefinitions[j].Position()
+	})
+
+	errorMappers := make([]httpserver.ErrorMapper, 0, len(errorMapperDefinitions))
+	for _, errorMapperDefinition := range errorMapperDefinitions {
+		errorMappers = append(errorMappers, errorMapperDefinition.Mapper())
+	}
+
+	// typed config
+	serverCfg, err := configuredServer(p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http server: %w", err)
+	}
+
+	// recovery
+	recoveryStatus, panicTracker := http.StatusInternalServerError, (*httpserver.PanicTracker)(nil)
+	if serverCfg.Recovery.Enabled {
+		recoveryStatus, panicTracker, err = configuredRecovery(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create http server: %w", err)
+		}
+	}
+
 	// server
 	httpServer, err := p.Factory.Create(
 		httpserver.WithDebug(appDebug),
 		httpserver.WithBanner(false),
-		httpserver.WithRecovery(true),
+		httpserver.WithRecovery(serverCfg.Recovery.Enabled),
+		httpserver.WithRecoveryStatus(recoveryStatus),
+		httpserver.WithPanicTracker(panicTracker),
 		httpserver.WithLogger(echoLogger),
 		httpserver.WithRenderer(renderer),
+		httpserver.WithJsonSerializer(jsonSerializer),
+		httpserver.WithValidator(validator),
+		httpserver.WithMaxHeaderBytes(int(serverCfg.MaxHeaderBytes)),
 		httpserver.WithHttpErrorHandler(
 			httpserver.JsonErrorHandler(
 				p.Config.GetBool("modules.http.server.errors.obfuscate") || !appDebug,
 				p.Config.GetBool("modules.http.server.errors.stack") || appDebug,
+				errorMappers...,
 			),
 		),
 	)
@@ -85,56 +175,236 @@ func NewFxHttpServer(p FxHttpServerParam) (*echo.Echo, error) {
 		return nil, fmt.Errorf("failed to create http server: %w", err)
 	}
 
+	// dynamic routing, to allow handlers registration at runtime
+	p.Registry.dynamicRouter = httpserver.NewDynamicRouter(httpServer)
+
+	// routing
+	httpServer, err = withRoutingPreMiddlewares(httpServer, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http server: %w", err)
+	}
+
 	// middlewares
-	httpServer = withDefaultMiddlewares(httpServer, p)
+	httpServer, err = withDefaultMiddlewares(httpServer, serverCfg, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http server: %w", err)
+	}
 
 	// groups, handlers & middlewares registrations
-	httpServer = withRegisteredResources(httpServer, p)
+	httpServer, err = withRegisteredResources(httpServer, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http server: %w", err)
+	}
+
+	// admin server
+	var adminServer *echo.Echo
+	if isAdminEnabled(p.Config) {
+		adminServer, err = createAdminServer(httpServer, echoLogger, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create http admin server: %w", err)
+		}
+
+		// metrics exposition
+		adminServer = withMetricsExposition(adminServer, p)
+	} else {
+		// metrics exposition
+		httpServer = withMetricsExposition(httpServer, p)
+	}
+
+	// lifecycles, not serving if disabled (the server stays constructable, for example for tests)
+	if !isServerEnabled(p.Config) {
+		p.Logger.Info().Msg("http server disabled, not serving")
+
+		return httpServer, nil
+	}
+
+	// the admin server is shut down first, to stop reporting ready before the main server drains
+	shutdown := func(ctx context.Context) error {
+		var adminErr error
+		if adminServer != nil && !p.Config.IsTestEnv() {
+			adminErr = adminServer.Shutdown(ctx)
+		}
+
+		return errors.Join(adminErr, httpServer.Shutdown(ctx))
+	}
 
-	// lifecycles
 	p.LifeCycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			if !p.Config.IsTestEnv() {
-				port := p.Config.GetInt("modules.http.server.port")
-				if port == 0 {
-					port = DefaultPort
-				}
+			if p.Config.IsTestEnv() {
+				// in-memory listener, to be used with httpservertest.NewTestClient()
+				httpServer.HidePort = true
+				httpServer.Listener = httpservertest.NewTestListener()
 
 				//nolint:errcheck
-				go httpServer.Start(fmt.Sprintf(":%d", port))
+				go httpServer.Start("")
+			} else {
+				port := configuredPort(p.Config, "modules.http.server.port", DefaultPort)
+				adminPort := configuredPort(p.Config, "modules.http.server.admin.port", DefaultAdminPort)
+
+				// listeners bound before serving, to fail the startup if a port is already in use
+				if err := listen(ctx, httpServer, "http server", port, serverCfg.Listener); err != nil {
+					return err
+				}
+
+				if adminServer != nil {
+					if err := listen(ctx, adminServer, "http admin server", adminPort, serverCfg.Listener); err != nil {
+						//nolint:errcheck
+						httpServer.Listener.Close()
+
+						return err
+					}
+				}
+
+				if p.Config.GetBool("modules.http.server.h2c.enabled") {
+					//nolint:errcheck
+					go httpServer.StartH2CServer(fmt.Sprintf(":%d", port), &http2.Server{})
+				} else {
+					//nolint:errcheck
+					go httpServer.Start(fmt.Sprintf(":%d", port))
+				}
+
+				if adminServer != nil {
+					//nolint:errcheck
+					go adminServer.Start(fmt.Sprintf(":%d", adminPort))
+				}
 			}
 
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			if !p.Config.IsTestEnv() {
-				return httpServer.Shutdown(ctx)
+			// coordinated with the other servers, the readiness being failed first
+			if p.Shutdown != nil {
+				return p.Shutdown.StopStage(ctx, ModuleName)
 			}
 
-			return nil
+			return shutdown(ctx)
 		},
 	})
 
+	if p.Shutdown != nil {
+		p.Shutdown.RegisterStage(ModuleName, shutdown)
+	}
+
 	return httpServer, nil
 }
 
-func withDefaultMiddlewares(httpServer *echo.Echo, p FxHttpServerParam) *echo.Echo {
+func withRoutingPreMiddlewares(httpServer *echo.Echo, p FxHttpServerParam) (*echo.Echo, error) {
+	// pre middlewares are executed before routing, and therefore before the default middlewares
+	if p.Config.GetBool("modules.http.server.forwarded_headers.enabled") {
+		trustedProxies := httpserver.DefaultTrustedProxies
+		if p.Config.IsSet("modules.http.server.trusted_proxies") {
+			trustedProxies = p.Config.GetStringSlice("modules.http.server.trusted_proxies")
+		}
+
+		trustedProxiesNetworks, err := httpserver.ParseTrustedProxies(trustedProxies)
+		if err != nil {
+			return nil, err
+		}
+
+		httpServer.Pre(httpservermiddleware.ForwardedHeadersMiddlewareWithConfig(
+			httpservermiddleware.ForwardedHeadersMiddlewareConfig{
+				TrustedProxies: trustedProxiesNetworks,
+			},
+		))
+	}
+
+	removeTrailingSlash, addTrailingSlash, redirectCode, err := configuredTrailingSlash(p.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	trailingSlashConfig := echomiddleware.TrailingSlashConfig{
+		RedirectCode: redirectCode,
+	}
+
+	if removeTrailingSlash {
+		httpServer.Pre(echomiddleware.RemoveTrailingSlashWithConfig(trailingSlashConfig))
+	}
+
+	if addTrailingSlash {
+		httpServer.Pre(echomiddleware.AddTrailingSlashWithConfig(trailingSlashConfig))
+	}
+
+	return httpServer, nil
+}
+
+// configuredTrailingSlash resolves the trailing slash normalization from the modules.http.server.router.trailing_slash
+// mode and the modules.http.server.routing options.
+func configuredTrailingSlash(cfg *config.Config) (bool, bool, int, error) {
+	removeTrailingSlash := cfg.GetBool("modules.http.server.routing.remove_trailing_slash")
+	addTrailingSlash := cfg.GetBool("modules.http.server.routing.add_trailing_slash")
+	redirectCode := cfg.GetInt("modules.http.server.routing.redirect_code")
+
+	switch mode := cfg.GetString("modules.http.server.router.trailing_slash"); mode {
+	case "", TrailingSlashStrict:
+	case TrailingSlashStrip:
+		removeTrailingSlash = true
+		redirectCode = 0
+	case TrailingSlashRedirect:
+		removeTrailingSlash = true
+		if redirectCode == 0 {
+			redirectCode = http.StatusMovedPermanently
+		}
+	default:
+		return false, false, 0, fmt.Errorf("invalid trailing slash mode %s", mode)
+	}
+
+	if removeTrailingSlash && addTrailingSlash {
+		return false, false, 0, errors.New("trailing slash cannot be both removed and added")
+	}
+
+	return removeTrailingSlash, addTrailingSlash, redirectCode, nil
+}
+
+func withDefaultMiddlewares(httpServer *echo.Echo, serverCfg serverConfig, p FxHttpServerParam) (*echo.Echo, error) {
+	// base url middleware
+	if baseUrl := p.Config.GetString("modules.http.server.base_url"); baseUrl != "" {
+		httpServer.Use(httpservermiddleware.BaseUrlMiddleware(baseUrl))
+	}
+
 	// request id middleware
+	trustIncomingRequestId := true
+	if p.Config.IsSet("modules.http.server.request_id.trust_incoming") {
+		trustIncomingRequestId = p.Config.GetBool("modules.http.server.request_id.trust_incoming")
+	}
+
 	httpServer.Use(httpservermiddleware.RequestIdMiddlewareWithConfig(
 		httpservermiddleware.RequestIdMiddlewareConfig{
-			Generator: p.Generator,
+			Generator:      p.Generator,
+			IgnoreIncoming: !trustIncomingRequestId,
 		},
 	))
 
+	// audit logger middleware
+	if p.AuditLogger != nil {
+		httpServer.Use(httpservermiddleware.AuditLoggerMiddleware(p.AuditLogger))
+	}
+
+	// headers propagation middleware
+	if propagatedHeaders := configuredPropagatedHeaders(p); len(propagatedHeaders) > 0 {
+		httpServer.Use(httpservermiddleware.HeadersPropagationMiddleware(propagatedHeaders...))
+	}
+
 	// request tracer middleware
 	if p.Config.GetBool("modules.http.server.trace.enabled") {
 		httpServer.Use(httpservermiddleware.RequestTracerMiddlewareWithConfig(
 			p.Config.AppName(),
 			httpservermiddleware.RequestTracerMiddlewareConfig{
+				Skipper:                     defaultMiddlewareSkipper(p, Tracer),
 				TracerProvider:              p.TracerProvider,
-				RequestUriPrefixesToExclude: p.Config.GetStringSlice("modules.http.server.trace.exclude"),
+				RequestUriPrefixesToExclude: excludedPaths(p, "modules.http.server.trace.exclude"),
 			},
 		))
+
+		// trace id header middleware
+		if traceIdHeader := p.Config.GetString("modules.http.server.trace.response_header"); traceIdHeader != "" {
+			httpServer.Use(httpservermiddleware.TraceIdHeaderMiddlewareWithConfig(
+				httpservermiddleware.TraceIdHeaderMiddlewareConfig{
+					Skipper: defaultMiddlewareSkipper(p, Tracer),
+					Header:  traceIdHeader,
+				},
+			))
+		}
 	}
 
 	// request logger middleware
@@ -146,51 +416,249 @@ func withDefaultMiddlewares(httpServer *echo.Echo, p FxHttpServerParam) *echo.Ec
 		requestHeadersToLog[headerName] = fieldName
 	}
 
+	routeBodyLoggingOverrides := map[string]bool{}
+	for routeKey, handlerOptions := range p.Registry.HandlersOptions() {
+		if handlerOptions.ForceBodyLogging {
+			routeBodyLoggingOverrides[routeKey] = true
+		}
+
+		if handlerOptions.DisableBodyLogging {
+			routeBodyLoggingOverrides[routeKey] = false
+		}
+	}
+
+	var successSampler func() bool
+	if p.Config.IsSet("modules.http.server.log.success_sample_rate") {
+		successSampler = httpservermiddleware.RatioSampler(p.Config.GetFloat64("modules.http.server.log.success_sample_rate"))
+	}
+
+	// the requests logs can be sampled with a different rate than the application logs (errors are never sampled)
+	var sampler zerolog.Sampler
+	if p.Config.IsSet("modules.http.server.log.sample_rate") {
+		sampler = log.NewSamplerWithConfig(log.SamplerConfig{
+			Default: log.NewRatioSampler(p.Config.GetFloat64("modules.http.server.log.sample_rate")),
+		})
+	}
+
 	httpServer.Use(httpservermiddleware.RequestLoggerMiddlewareWithConfig(
 		httpservermiddleware.RequestLoggerMiddlewareConfig{
+			Skipper:                         defaultMiddlewareSkipper(p, Logger),
 			RequestHeadersToLog:             requestHeadersToLog,
-			RequestUriPrefixesToExclude:     p.Config.GetStringSlice("modules.http.server.log.exclude"),
+			RequestUriPrefixesToExclude:     excludedPaths(p, "modules.http.server.log.exclude"),
 			LogLevelFromResponseOrErrorCode: p.Config.GetBool("modules.http.server.log.level_from_response"),
+			LogRequestBody:                  p.Config.GetBool("modules.http.server.log.body.request"),
+			LogResponseBody:                 p.Config.GetBool("modules.http.server.log.body.response"),
+			RouteBodyLoggingOverrides:       routeBodyLoggingOverrides,
+			LogRoute:                        p.Config.GetBool("modules.http.server.log.route"),
+			LogHandler:                      p.Config.GetBool("modules.http.server.log.handler"),
+			SuccessSampler:                  successSampler,
+			Sampler:                         sampler,
+			Disabled:                        !serverCfg.Log.Enabled,
 		},
 	))
 
 	// request metrics middleware
 	if p.Config.GetBool("modules.http.server.metrics.collect.enabled") {
-		namespace := p.Config.GetString("modules.http.server.metrics.collect.namespace")
-		if namespace == "" {
-			namespace = p.Config.AppName()
+		metricsType, objectives, err := configuredMetricsType(p)
+		if err != nil {
+			return nil, err
 		}
 
-		subsystem := p.Config.GetString("modules.http.server.metrics.collect.subsystem")
-		if subsystem == "" {
-			subsystem = ModuleName
-		}
-
-		var buckets []float64
-		if bucketsConfig := p.Config.GetString("modules.http.server.metrics.buckets"); bucketsConfig != "" {
-			for _, s := range strings.Split(strings.ReplaceAll(bucketsConfig, " ", ""), ",") {
-				f, err := strconv.ParseFloat(s, 64)
-				if err == nil {
-					buckets = append(buckets, f)
-				}
-			}
-		}
+		metricsSkipper := defaultMiddlewareSkipper(p, Metrics)
+		metricsExcludedPaths := excludedPaths(p, "")
 
 		metricsMiddlewareConfig := httpservermiddleware.RequestMetricsMiddlewareConfig{
+			Skipper: func(c echo.Context) bool {
+				return metricsSkipper(c) ||
+					isExposedMetricsPath(p, c.Path()) ||
+					httpserver.MatchPrefix(metricsExcludedPaths, c.Request().URL.Path)
+			},
 			Registry:            p.MetricsRegistry,
-			Namespace:           strings.ReplaceAll(namespace, "-", "_"),
-			Subsystem:           strings.ReplaceAll(subsystem, "-", "_"),
-			Buckets:             buckets,
+			Namespace:           metricsNamespace(p),
+			Subsystem:           metricsSubsystem(p),
+			Buckets:             configuredBuckets(p, "modules.http.server.metrics.buckets"),
+			SizeBuckets:         configuredBuckets(p, "modules.http.server.metrics.size_buckets"),
 			NormalizeHTTPStatus: p.Config.GetBool("modules.http.server.metrics.normalize"),
+			Type:                metricsType,
+			Objectives:          objectives,
 		}
 
 		httpServer.Use(httpservermiddleware.RequestMetricsMiddlewareWithConfig(metricsMiddlewareConfig))
 	}
 
-	return httpServer
+	// request timeout middleware
+	if serverCfg.Timeout.Enabled {
+		timeoutExcludedPaths := serverCfg.Timeout.Exclude
+
+		httpServer.Use(httpservermiddleware.RequestTimeoutMiddlewareWithConfig(
+			httpservermiddleware.RequestTimeoutMiddlewareConfig{
+				Skipper: func(c echo.Context) bool {
+					return httpserver.MatchPrefix(timeoutExcludedPaths, c.Request().URL.Path)
+				},
+				Timeout: serverCfg.Timeout.Duration,
+			},
+		))
+	}
+
+	// response cache middleware
+	if serverCfg.Cache.Enabled {
+		prefixesTTL := map[string]time.Duration{}
+		for prefix := range serverCfg.Cache.TTLs {
+			ttl, err := p.Config.GetDuration(fmt.Sprintf("modules.http.server.cache.ttls.%s", prefix))
+			if err != nil {
+				httpServer.Logger.Errorf("invalid response cache ttl for prefix %s: %v", prefix, err)
+
+				continue
+			}
+
+			prefixesTTL[prefix] = ttl
+		}
+
+		httpServer.Use(httpservermiddleware.ResponseCacheMiddlewareWithConfig(
+			httpservermiddleware.ResponseCacheMiddlewareConfig{
+				TTL:                     serverCfg.Cache.TTL,
+				MaxEntries:              serverCfg.Cache.MaxEntries,
+				PrefixesTTL:             prefixesTTL,
+				VaryHeaders:             serverCfg.Cache.Vary,
+				AllowAuthorizedRequests: serverCfg.Cache.AllowAuthorization,
+				Registry:                p.MetricsRegistry,
+				Namespace:               metricsNamespace(p),
+				Subsystem:               metricsSubsystem(p),
+			},
+		))
+	}
+
+	// uploads middleware
+	uploadsRoutes := map[string]bool{}
+	for routeKey, handlerOptions := range p.Registry.HandlersOptions() {
+		if handlerOptions.Uploads {
+			uploadsRoutes[routeKey] = true
+		}
+	}
+
+	if len(uploadsRoutes) > 0 {
+		httpServer.Use(httpservermiddleware.UploadsMiddlewareWithConfig(
+			httpservermiddleware.UploadsMiddlewareConfig{
+				Skipper: func(c echo.Context) bool {
+					return !uploadsRoutes[httpserver.RouteKey(c.Request().Method, c.Path())]
+				},
+				MaxMemory:           int64(serverCfg.Uploads.MaxMemory),
+				TempDir:             serverCfg.Uploads.TempDir,
+				AllowedContentTypes: serverCfg.Uploads.AllowedContentTypes,
+				MaxFileSize:         int64(serverCfg.Uploads.MaxFileSize),
+				Registry:            p.MetricsRegistry,
+				Namespace:           metricsNamespace(p),
+				Subsystem:           metricsSubsystem(p),
+			},
+		))
+	}
+
+	return httpServer, nil
+}
+
+func metricsNamespace(p FxHttpServerParam) string {
+	namespace := p.Config.GetString("modules.http.server.metrics.collect.namespace")
+	if namespace == "" {
+		namespace = p.Config.AppName()
+	}
+
+	return strings.ReplaceAll(namespace, "-", "_")
+}
+
+func metricsSubsystem(p FxHttpServerParam) string {
+	subsystem := p.Config.GetString("modules.http.server.metrics.collect.subsystem")
+	if subsystem == "" {
+		subsystem = ModuleName
+	}
+
+	return strings.ReplaceAll(subsystem, "-", "_")
+}
+
+// configuredBuckets returns the metrics buckets of a config key, as comma separated values (middleware defaults if not
+// set).
+func configuredBuckets(p FxHttpServerParam, key string) []float64 {
+	var buckets []float64
+	if bucketsConfig := p.Config.GetString(key); bucketsConfig != "" {
+		for _, s := range strings.Split(strings.ReplaceAll(bucketsConfig, " ", ""), ",") {
+			f, err := strconv.ParseFloat(s, 64)
+			if err == nil {
+				buckets = append(buckets, f)
+			}
+		}
+	}
+
+	return buckets
 }
 
-func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam) *echo.Echo {
+// configuredMetricsType returns the requests durations metric type (histogram by default), and the summary objectives
+// from the modules.http.server.metrics.objectives config, as comma separated quantile:error pairs (middleware defaults
+// if empty).
+func configuredMetricsType(p FxHttpServerParam) (string, map[float64]float64, error) {
+	metricsType := strings.ToLower(p.Config.GetString("modules.http.server.metrics.type"))
+
+	switch metricsType {
+	case "", httpservermiddleware.HttpServerMetricsTypeHistogram:
+		return httpservermiddleware.HttpServerMetricsTypeHistogram, nil, nil
+	case httpservermiddleware.HttpServerMetricsTypeSummary:
+		objectives := map[float64]float64{}
+
+		objectivesConfig := strings.ReplaceAll(p.Config.GetString("modules.http.server.metrics.objectives"), " ", "")
+		if objectivesConfig == "" {
+			return metricsType, objectives, nil
+		}
+
+		for _, objective := range strings.Split(objectivesConfig, ",") {
+			quantileConfig, errorConfig, found := strings.Cut(objective, ":")
+
+			quantile, quantileErr := strconv.ParseFloat(quantileConfig, 64)
+			allowedError, errorErr := strconv.ParseFloat(errorConfig, 64)
+
+			if !found || quantileErr != nil || errorErr != nil || quantile <= 0 || quantile >= 1 || allowedError < 0 || allowedError >= 1 {
+				return "", nil, fmt.Errorf("invalid http server metrics objective %s", objective)
+			}
+
+			objectives[quantile] = allowedError
+		}
+
+		return metricsType, objectives, nil
+	default:
+		return "", nil, fmt.Errorf("invalid http server metrics type %s", metricsType)
+	}
+}
+
+func defaultMiddlewareSkipper(p FxHttpServerParam, middleware DefaultMiddleware) echomiddleware.Skipper {
+	excludedRoutes := map[string]bool{}
+	for routeKey, handlerOptions := range p.Registry.HandlersOptions() {
+		if handlerOptions.ExcludesDefaultMiddleware(middleware) {
+			excludedRoutes[routeKey] = true
+		}
+	}
+
+	return func(c echo.Context) bool {
+		return excludedRoutes[httpserver.RouteKey(c.Request().Method, c.Path())]
+	}
+}
+
+func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam) (*echo.Echo, error) {
+	routeNames := map[string]string{}
+
+	nameRoute := func(route *echo.Route, name string) error {
+		if name == "" {
+			return nil
+		}
+
+		routeKey := httpserver.RouteKey(route.Method, route.Path)
+
+		if existingRouteKey, ok := routeNames[name]; ok {
+			return fmt.Errorf("route name %s is used by both %s and %s", name, existingRouteKey, routeKey)
+		}
+
+		routeNames[name] = routeKey
+		route.Name = name
+
+		return nil
+	}
+
 	// register handler groups
 	resolvedHandlersGroups, err := p.Registry.ResolveHandlersGroups()
 	if err != nil {
@@ -201,12 +669,22 @@ func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam) *echo.E
 		group := httpServer.Group(g.Prefix(), g.Middlewares()...)
 
 		for _, h := range g.Handlers() {
-			group.Add(
+			route := group.Add(
 				strings.ToUpper(h.Method()),
 				h.Path(),
 				h.Handler(),
 				h.Middlewares()...,
 			)
+
+			err = nameRoute(route, h.Options().Name)
+			if err != nil {
+				return nil, err
+			}
+
+			if h.Options().Streaming {
+				httpserver.MarkStreamingRoutes(httpServer, route)
+			}
+
 			httpServer.Logger.Debugf("registering handler in group for [%s]%s%s", h.Method(), g.Prefix(), h.Path())
 		}
 
@@ -228,7 +706,7 @@ func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam) *echo.E
 			httpServer.Use(m.Middleware())
 		}
 
-		httpServer.Logger.Debugf("registered %s middleware %T", m.Kind().String(), m.Middleware())
+		httpServer.Logger.Debugf("registered %s middleware %T with priority %d", m.Kind().String(), m.Middleware(), m.Priority())
 	}
 
 	// register handlers
@@ -238,15 +716,231 @@ func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam) *echo.E
 	}
 
 	for _, h := range resolvedHandlers {
-		httpServer.Add(
+		route := httpServer.Add(
 			strings.ToUpper(h.Method()),
 			h.Path(),
 			h.Handler(),
 			h.Middlewares()...,
 		)
 
+		err = nameRoute(route, h.Options().Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if h.Options().Streaming {
+			httpserver.MarkStreamingRoutes(httpServer, route)
+		}
+
 		httpServer.Logger.Debugf("registered handler for [%s]%s", h.Method(), h.Path())
 	}
 
+	return httpServer, nil
+}
+
+func createAdminServer(httpServer *echo.Echo, echoLogger echo.Logger, p FxHttpServerParam) (*echo.Echo, error) {
+	appDebug := p.Config.AppDebug()
+
+	adminServer, err := p.Factory.Create(
+		httpserver.WithDebug(appDebug),
+		httpserver.WithBanner(false),
+		httpserver.WithRecovery(true),
+		httpserver.WithLogger(echoLogger),
+		httpserver.WithHttpErrorHandler(
+			httpserver.JsonErrorHandler(
+				p.Config.GetBool("modules.http.server.errors.obfuscate") || !appDebug,
+				p.Config.GetBool("modules.http.server.errors.stack") || appDebug,
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// healthcheck
+	if p.Checker != nil {
+		probes := map[healthcheck.ProbeKind]string{
+			healthcheck.Startup:   configuredPath(p.Config, "modules.http.server.admin.healthcheck.startup.path", DefaultAdminHealthCheckStartupPath),
+			healthcheck.Liveness:  configuredPath(p.Config, "modules.http.server.admin.healthcheck.liveness.path", DefaultAdminHealthCheckLivenessPath),
+			healthcheck.Readiness: configuredPath(p.Config, "modules.http.server.admin.healthcheck.readiness.path", DefaultAdminHealthCheckReadinessPath),
+		}
+
+		for kind, path := range probes {
+			adminServer.GET(path, handler.HealthCheckHandler(p.Checker, kind))
+
+			adminServer.Logger.Debugf("registered admin healthcheck %s handler", kind.String())
+		}
+	}
+
+	// debug
+	if p.Config.GetBool("modules.http.server.admin.debug.enabled") || appDebug {
+		adminServer.GET(
+			configuredPath(p.Config, "modules.http.server.admin.debug.routes.path", DefaultAdminDebugRoutesPath),
+			handler.DebugRoutesHandler(httpServer),
+		)
+
+		pprofGroup := adminServer.Group(configuredPath(p.Config, "modules.http.server.admin.debug.pprof.path", DefaultAdminDebugPProfPath))
+
+		pprofGroup.GET("/", handler.PprofIndexHandler())
+		pprofGroup.GET("/allocs", handler.PprofAllocsHandler())
+		pprofGroup.GET("/block", handler.PprofBlockHandler())
+		pprofGroup.GET("/cmdline", handler.PprofCmdlineHandler())
+		pprofGroup.GET("/goroutine", handler.PprofGoroutineHandler())
+		pprofGroup.GET("/heap", handler.PprofHeapHandler())
+		pprofGroup.GET("/mutex", handler.PprofMutexHandler())
+		pprofGroup.GET("/profile", handler.PprofProfileHandler())
+		pprofGroup.GET("/symbol", handler.PprofSymbolHandler())
+		pprofGroup.POST("/symbol", handler.PprofSymbolHandler())
+		pprofGroup.GET("/threadcreate", handler.PprofThreadCreateHandler())
+		pprofGroup.GET("/trace", handler.PprofTraceHandler())
+
+		adminServer.Logger.Debug("registered admin debug handlers")
+	}
+
+	return adminServer, nil
+}
+
+func withMetricsExposition(httpServer *echo.Echo, p FxHttpServerParam) *echo.Echo {
+	if !p.Config.GetBool("modules.http.server.metrics.expose.enabled") {
+		return httpServer
+	}
+
+	var middlewares []echo.MiddlewareFunc
+	if token := p.Config.GetString("modules.http.server.metrics.expose.token"); token != "" {
+		middlewares = append(middlewares, echomiddleware.KeyAuthWithConfig(echomiddleware.KeyAuthConfig{
+			Validator: func(key string, c echo.Context) (bool, error) {
+				return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
+			},
+		}))
+	}
+
+	httpServer.GET(exposedMetricsPath(p), handler.MetricsHandler(p.MetricsRegistry), middlewares...)
+
 	return httpServer
 }
+
+func exposedMetricsPath(p FxHttpServerParam) string {
+	return configuredPath(p.Config, "modules.http.server.metrics.expose.path", DefaultMetricsPath)
+}
+
+func isMetricsExposedOnMainServer(p FxHttpServerParam) bool {
+	return p.Config.GetBool("modules.http.server.metrics.expose.enabled") && !isAdminEnabled(p.Config)
+}
+
+func isExposedMetricsPath(p FxHttpServerParam, path string) bool {
+	return isMetricsExposedOnMainServer(p) && path == exposedMetricsPath(p)
+}
+
+// excludedPaths returns the paths excluded from a default middleware: the ones of its own configuration key (if any),
+// merged with the globally excluded ones and the exposed metrics path.
+func excludedPaths(p FxHttpServerParam, key string) []string {
+	var paths []string
+	if key != "" {
+		paths = append(paths, p.Config.GetStringSlice(key)...)
+	}
+
+	if p.Config.IsSet("modules.http.server.exclude") {
+		paths = append(paths, p.Config.GetStringSlice("modules.http.server.exclude")...)
+	} else {
+		paths = append(paths, DefaultExcludedPaths...)
+	}
+
+	if isMetricsExposedOnMainServer(p) {
+		paths = append(paths, exposedMetricsPath(p))
+	}
+
+	return paths
+}
+
+// configuredRecovery returns the panic recovery status, and the panic tracker flipping the readiness if configured.
+func configuredRecovery(p FxHttpServerParam) (int, *httpserver.PanicTracker, error) {
+	status := http.StatusInternalServerError
+	if p.Config.IsSet("modules.http.server.recovery.status") {
+		status = p.Config.GetInt("modules.http.server.recovery.status")
+	}
+
+	if status < http.StatusInternalServerError || http.StatusText(status) == "" {
+		return 0, nil, fmt.Errorf("invalid recovery status %d, must be a server error status", status)
+	}
+
+	threshold := p.Config.GetInt("modules.http.server.recovery.readiness.threshold")
+	if threshold <= 0 {
+		return status, nil, nil
+	}
+
+	window := DefaultRecoveryReadinessWindow
+	if p.Config.IsSet("modules.http.server.recovery.readiness.window") {
+		configuredWindow, err := p.Config.GetDuration("modules.http.server.recovery.readiness.window")
+		if err != nil {
+			return 0, nil, err
+		}
+
+		window = configuredWindow
+	}
+
+	tracker := httpserver.NewPanicTracker(threshold, window)
+
+	// flips the readiness to unhealthy after threshold panics within the window
+	if p.Checker != nil {
+		p.Checker.RegisterProbe(tracker, healthcheck.Readiness)
+	}
+
+	return status, tracker, nil
+}
+
+// configuredJsonSerializer returns the json serializer configured in modules.http.server.json.serializer, or else in
+// its modules.http.server.json_serializer alias.
+func configuredJsonSerializer(cfg *config.Config) string {
+	if cfg.IsSet("modules.http.server.json.serializer") {
+		return cfg.GetString("modules.http.server.json.serializer")
+	}
+
+	return cfg.GetString("modules.http.server.json_serializer")
+}
+
+// configuredPropagatedHeaders returns the headers to propagate to the outgoing requests, defaulting to the
+// modules.http.client.propagate.headers ones, to be configured once for both the server and the clients.
+func configuredPropagatedHeaders(p FxHttpServerParam) []string {
+	if p.Config.IsSet("modules.http.server.propagate.headers") {
+		return p.Config.GetStringSlice("modules.http.server.propagate.headers")
+	}
+
+	return p.Config.GetStringSlice("modules.http.client.propagate.headers")
+}
+
+// listen binds the tcp listener of a server on a port, with the modules.http.server.listener config, to be used when the
+// server starts.
+func listen(ctx context.Context, server *echo.Echo, name string, port int, listenerCfg listener.Config) error {
+	lis, err := listener.Listen(ctx, fmt.Sprintf(":%d", port), listenerCfg)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %d for %s: %w", port, name, err)
+	}
+
+	server.Listener = lis
+
+	return nil
+}
+
+func isServerEnabled(cfg *config.Config) bool {
+	return !cfg.IsSet("modules.http.server.enabled") || cfg.GetBool("modules.http.server.enabled")
+}
+
+func isAdminEnabled(cfg *config.Config) bool {
+	return cfg.GetBool("modules.http.server.admin.enabled")
+}
+
+func configuredPort(cfg *config.Config, key string, defaultPort int) int {
+	if port := cfg.GetInt(key); port != 0 {
+		return port
+	}
+
+	return defaultPort
+}
+
+func configuredPath(cfg *config.Config, key string, defaultPath string) string {
+	if path := cfg.GetString(key); path != "" {
+		return path
+	}
+
+	return defaultPath
+}
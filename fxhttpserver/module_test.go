@@ -1,35 +1,60 @@
 package fxhttpserver_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ankorstore/yokai/config/listener"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxgenerate"
 	"github.com/ankorstore/yokai/fxhttpserver"
 	"github.com/ankorstore/yokai/fxhttpserver/testdata/factory"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/generator"
 	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
 	"github.com/ankorstore/yokai/fxhttpserver/testdata/middleware"
 	"github.com/ankorstore/yokai/fxhttpserver/testdata/service"
 	"github.com/ankorstore/yokai/fxlog"
 	"github.com/ankorstore/yokai/fxmetrics"
 	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/httpclient"
+	"github.com/ankorstore/yokai/httpclient/transport"
 	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/httpservertest"
+	httpservermiddleware "github.com/ankorstore/yokai/httpserver/middleware"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
+	"golang.org/x/net/http2"
 )
 
 var (
@@ -69,6 +94,15 @@ var (
 
 		return c.JSON(http.StatusOK, "concrete")
 	}
+
+	concreteEchoBodyHandler = func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+
+		return c.String(http.StatusOK, string(body))
+	}
 )
 
 //nolint:maintidx
@@ -161,6 +195,8 @@ func TestModuleWithAutowiredResources(t *testing.T) {
 		"traceID":   testTraceId,
 		"foo":       "foo",
 		"bar":       "bar",
+		"route":     "/bar",
+		"handler":   "github.com/ankorstore/yokai/fxhttpserver/testdata/handler.(*TestBarHandler).Handle.func1",
 		"message":   "request logger",
 	})
 
@@ -236,6 +272,8 @@ func TestModuleWithAutowiredResources(t *testing.T) {
 		"traceID":   testTraceId,
 		"foo":       "foo",
 		"bar":       "bar",
+		"route":     "/baz",
+		"handler":   "github.com/ankorstore/yokai/fxhttpserver/testdata/handler.(*TestBazHandler).Handle.func1",
 		"message":   "request logger",
 	})
 
@@ -443,6 +481,8 @@ func TestModuleWithAutowiredResources(t *testing.T) {
 		"method":    "GET",
 		"uri":       "/invalid",
 		"status":    404,
+		"route":     "unmatched",
+		"handler":   "unmatched",
 		"requestID": testRequestId,
 		"traceID":   testTraceId,
 		"error":     "code=404, message=Not Found",
@@ -700,14 +740,11 @@ func TestModuleWithEchoResources(t *testing.T) {
 	assert.Equal(t, "SAMEORIGIN", rec.Header().Get(echo.HeaderXFrameOptions)) // Secure middleware
 }
 
-func TestModuleWithMetrics(t *testing.T) {
+func TestModuleWithUntrustedIncomingRequestId(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
-	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("MODULES_HTTP_SERVER_REQUEST_ID_TRUST_INCOMING", "false")
 
 	var httpServer *echo.Echo
-	var logBuffer logtest.TestLogBuffer
-	var traceExporter tracetest.TestTraceExporter
-	var metricsRegistry *prometheus.Registry
 
 	fxtest.New(
 		t,
@@ -718,36 +755,180 @@ func TestModuleWithMetrics(t *testing.T) {
 		fxmetrics.FxMetricsModule,
 		fxgenerate.FxGenerateModule,
 		fxhttpserver.FxHttpServerModule,
-		fx.Provide(service.NewTestService),
 		fx.Options(
-			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+			fxhttpserver.AsHandler("GET", "/concrete", func(c echo.Context) error {
+				return c.String(http.StatusOK, httpserver.CtxRequestId(c))
+			}),
 		),
-		fx.Populate(&httpServer, &logBuffer, &traceExporter, &metricsRegistry),
+		fx.Populate(&httpServer),
 	).RequireStart().RequireStop()
 
-	// [GET] /bar
-	req := httptest.NewRequest(http.MethodGet, "/bar", nil)
+	// [GET] /concrete
+	req := httptest.NewRequest(http.MethodGet, "/concrete", nil)
+	req.Header.Add("x-request-id", testRequestId)
 	rec := httptest.NewRecorder()
 	httpServer.ServeHTTP(rec, req)
 
 	assert.Equal(t, http.StatusOK, rec.Code)
-	assert.Contains(t, rec.Body.String(), "bar: test")
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderXRequestID))
+	assert.NotEqual(t, testRequestId, rec.Header().Get(echo.HeaderXRequestID))
+	assert.Equal(t, rec.Header().Get(echo.HeaderXRequestID), rec.Body.String())
+}
+
+func TestModuleWithAuditLogger(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("DELETE", "/orders/:id", func(c echo.Context) error {
+				httpserver.SetAuditActor(c, "user-1")
+
+				err := httpserver.CtxAuditLogger(c).Audit(c.Request().Context(), log.AuditEvent{
+					Action:   "delete",
+					Resource: "order/" + c.Param("id"),
+					Outcome:  log.AuditOutcomeSuccess,
+				})
+				if err != nil {
+					return err
+				}
+
+				return c.NoContent(http.StatusNoContent)
+			}),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// [DELETE] /orders/1
+	req := httptest.NewRequest(http.MethodDelete, "/orders/1", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
 
 	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
-		"level":   "info",
-		"service": "test",
-		"module":  "httpserver",
-		"message": "in bar handler",
+		"audit":     true,
+		"requestID": testRequestId,
+		"actor":     "user-1",
+		"action":    "delete",
+		"resource":  "order/1",
+		"outcome":   "success",
 	})
+}
+
+func TestModuleWithTracingDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_TRACE_ENABLED", "false")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/concrete", concreteHandler),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// [GET] /concrete
+	req := httptest.NewRequest(http.MethodGet, "/concrete", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	req.Header.Add("traceparent", testTraceParent)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
 
 	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
-		"level":   "info",
-		"service": "test",
-		"module":  "httpserver",
-		"method":  "GET",
-		"uri":     "/bar",
-		"status":  200,
+		"level":     "info",
+		"service":   "test",
+		"module":    "httpserver",
+		"method":    "GET",
+		"uri":       "/concrete",
+		"status":    200,
+		"message":   "request logger",
+		"requestID": testRequestId,
+	})
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
 		"message": "request logger",
+		"traceID": testTraceId,
+	})
+}
+
+func TestModuleWithTestClient(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+	var traceExporter tracetest.TestTraceExporter
+	var metricsRegistry *prometheus.Registry
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer, &logBuffer, &traceExporter, &metricsRegistry),
+	).RequireStart()
+
+	// [GET] /bar, with a real round trip through the middlewares chain
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://test/bar", nil)
+	assert.NoError(t, err)
+	req.Header.Add("x-request-id", testRequestId)
+	req.Header.Add("traceparent", testTraceParent)
+
+	resp, err := httpservertest.NewTestClient(httpServer).Do(req)
+	assert.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, testRequestId, resp.Header.Get("x-request-id"))
+	assert.Contains(t, string(body), "bar: test")
+
+	app.RequireStop()
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"service":   "test",
+		"module":    "httpserver",
+		"method":    "GET",
+		"uri":       "/bar",
+		"status":    200,
+		"message":   "request logger",
+		"requestID": testRequestId,
+		"traceID":   testTraceId,
 	})
 
 	tracetest.AssertHasTraceSpan(
@@ -759,33 +940,27 @@ func TestModuleWithMetrics(t *testing.T) {
 		semconv.HTTPStatusCode(http.StatusOK),
 	)
 
-	expectedHelp := `
+	expectedMetric := `
 		# HELP foo_bar_requests_total Number of processed HTTP requests
 		# TYPE foo_bar_requests_total counter
-	`
-	expectedMetric := `
 		foo_bar_requests_total{handler="/bar",method="GET",status="2xx"} 1
 	`
 
-	err := testutil.GatherAndCompare(
-		metricsRegistry,
-		strings.NewReader(expectedHelp+expectedMetric),
-		"foo_bar_requests_total",
-	)
+	err = testutil.GatherAndCompare(metricsRegistry, strings.NewReader(expectedMetric), "foo_bar_requests_total")
 	assert.NoError(t, err)
 }
 
-func TestModuleWithTemplates(t *testing.T) {
+func TestModuleWithSSEHandler(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
-	t.Setenv("APP_DEBUG", "true")
-	t.Setenv("TEMPLATES_ENABLED", "true")
-	t.Setenv("TEMPLATES_PATH", "testdata/templates/*.html")
+	t.Setenv("MODULES_HTTP_SERVER_TIMEOUT_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_TIMEOUT_DURATION", "30ms")
 
 	var httpServer *echo.Echo
 	var logBuffer logtest.TestLogBuffer
-	var traceExporter tracetest.TestTraceExporter
+	var metricsRegistry *prometheus.Registry
 
-	fxtest.New(
+	app := fxtest.New(
 		t,
 		fx.NopLogger,
 		fxconfig.FxConfigModule,
@@ -794,50 +969,129 @@ func TestModuleWithTemplates(t *testing.T) {
 		fxmetrics.FxMetricsModule,
 		fxgenerate.FxGenerateModule,
 		fxhttpserver.FxHttpServerModule,
-		fx.Provide(service.NewTestService),
 		fx.Options(
-			fxhttpserver.AsHandler("GET", "/template", handler.NewTestTemplateHandler),
+			fxhttpserver.AsHandler("GET", "/events", handler.NewTestEventsHandler, fxhttpserver.WithStreaming()),
 		),
-		fx.Populate(&httpServer, &logBuffer, &traceExporter),
-	).RequireStart().RequireStop()
+		fx.Populate(&httpServer, &logBuffer, &metricsRegistry),
+	).RequireStart()
 
-	// [GET] /template
-	req := httptest.NewRequest(http.MethodGet, "/template", nil)
-	rec := httptest.NewRecorder()
-	httpServer.ServeHTTP(rec, req)
+	// [GET] /events, streamed for longer than the request timeout
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://test/events", nil)
+	assert.NoError(t, err)
+	req.Header.Add("x-request-id", testRequestId)
+	req.Header.Add("accept", httpserver.MIMETextEventStream)
 
-	assert.Equal(t, http.StatusOK, rec.Code)
-	assert.Contains(t, rec.Body.String(), "App name: test")
+	resp, err := httpservertest.NewTestClient(httpServer).Do(req)
+	assert.NoError(t, err)
 
-	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
-		"level":   "info",
-		"service": "test",
-		"module":  "httpserver",
-		"message": "in template handler",
-	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, httpserver.MIMETextEventStream, resp.Header.Get("content-type"))
+
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, []string{"1", "2", "3"}, data)
+
+	app.RequireStop()
 
 	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
-		"level":   "info",
-		"service": "test",
-		"module":  "httpserver",
-		"method":  "GET",
-		"uri":     "/template",
-		"status":  200,
-		"message": "request logger",
+		"level":     "info",
+		"method":    "GET",
+		"uri":       "/events",
+		"status":    200,
+		"sse":       true,
+		"message":   "request logger",
+		"requestID": testRequestId,
 	})
 
-	tracetest.AssertHasTraceSpan(
+	expectedMetric := `
+		# HELP foo_bar_requests_total Number of processed HTTP requests
+		# TYPE foo_bar_requests_total counter
+		foo_bar_requests_total{handler="/events",method="GET",status="2xx"} 1
+	`
+
+	err = testutil.GatherAndCompare(metricsRegistry, strings.NewReader(expectedMetric), "foo_bar_requests_total")
+	assert.NoError(t, err)
+}
+
+func TestModuleWithH2C(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, listener.Close())
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_PORT", strconv.Itoa(port))
+	t.Setenv("MODULES_HTTP_SERVER_H2C_ENABLED", "true")
+
+	var httpServer *echo.Echo
+
+	app := fxtest.New(
 		t,
-		traceExporter,
-		"GET /template",
-		semconv.HTTPMethod(http.MethodGet),
-		semconv.HTTPRoute("/template"),
-		semconv.HTTPStatusCode(http.StatusOK),
-	)
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/stream", func(c echo.Context) error {
+				c.Response().WriteHeader(http.StatusOK)
+				for _, chunk := range []string{"foo", "bar"} {
+					_, err := c.Response().Write([]byte(chunk))
+					if err != nil {
+						return err
+					}
+					c.Response().Flush()
+				}
+
+				return nil
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart()
+	defer app.RequireStop()
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/stream", port)
+
+	var resp *http.Response
+	assert.Eventually(t, func() bool {
+		//nolint:bodyclose,noctx
+		resp, err = client.Get(url)
+
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+	assert.Equal(t, "foobar", string(body))
 }
 
-func TestModuleDecoration(t *testing.T) {
+func TestModuleWithValidConfigSchema(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_PROFILES", "strict")
 
 	var httpServer *echo.Echo
 
@@ -850,9 +1104,2689 @@ func TestModuleDecoration(t *testing.T) {
 		fxmetrics.FxMetricsModule,
 		fxgenerate.FxGenerateModule,
 		fxhttpserver.FxHttpServerModule,
-		fx.Decorate(factory.NewTestHttpServerFactory),
 		fx.Populate(&httpServer),
 	).RequireStart().RequireStop()
 
-	assert.False(t, httpServer.HideBanner)
+	assert.NotNil(t, httpServer)
+}
+
+func TestModuleWithInvalidConfigSchema(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_PROFILES", "typo,strict")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+	assert.NoError(t, app.Err())
+
+	err := app.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(
+		t,
+		err.Error(),
+		"invalid config: "+
+			"modules.http.server.json.serializer: invalid value jsoniter, allowed values: stdlib, goccy; "+
+			"modules.http.server.log.body.requests: unknown config key, did you mean modules.http.server.log.body.request?; "+
+			"modules.http.server.prot: unknown config key, did you mean modules.http.server.port?",
+	)
+}
+
+func TestModuleWithPortAlreadyInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_PORT", strconv.Itoa(port))
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+	assert.NoError(t, app.Err())
+
+	err = app.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("failed to listen on %d for http server", port))
+	assert.Contains(t, err.Error(), "address already in use")
+}
+
+func TestModuleWithListenerReusePort(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "freebsd" {
+		t.Skipf("SO_REUSEPORT not supported on %s", runtime.GOOS)
+	}
+
+	lis, err := listener.Listen(context.Background(), ":0", listener.Config{ReusePort: true})
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	port := lis.Addr().(*net.TCPAddr).Port
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_PORT", strconv.Itoa(port))
+	t.Setenv("MODULES_HTTP_SERVER_LISTENER_REUSE_PORT", "true")
+	t.Setenv("MODULES_HTTP_SERVER_LISTENER_BACKLOG", "1024")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+	assert.NoError(t, app.Err())
+
+	// the port is shared with the already bound listener
+	assert.NoError(t, app.Start(context.Background()))
+	assert.Equal(t, port, httpServer.Listener.Addr().(*net.TCPAddr).Port)
+
+	// once the other listener is closed, the connections are served by the http server
+	assert.NoError(t, lis.Close())
+
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/not-found", port))
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	assert.NoError(t, app.Stop(context.Background()))
+}
+
+func TestModuleWithAdminServer(t *testing.T) {
+	var ports []int
+	for i := 0; i < 2; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		ports = append(ports, listener.Addr().(*net.TCPAddr).Port)
+		assert.NoError(t, listener.Close())
+	}
+
+	port, adminPort := ports[0], ports[1]
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_PORT", strconv.Itoa(port))
+	t.Setenv("MODULES_HTTP_SERVER_ADMIN_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_ADMIN_PORT", strconv.Itoa(adminPort))
+	t.Setenv("MODULES_HTTP_SERVER_ADMIN_DEBUG_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_METRICS_EXPOSE_ENABLED", "true")
+
+	checker, err := healthcheck.NewDefaultCheckerFactory().Create()
+	assert.NoError(t, err)
+
+	var httpServer *echo.Echo
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Supply(checker),
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart()
+
+	// main server: business routes only
+	req := httptest.NewRequest(http.MethodGet, "/bar", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	for _, path := range []string{"/metrics", "/healthz", "/livez", "/readyz", "/debug/routes"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		rec = httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+
+	// admin server: healthcheck, metrics and debug routes
+	adminUrl := fmt.Sprintf("http://127.0.0.1:%d", adminPort)
+
+	adminGet := func(path string) (int, string) {
+		//nolint:noctx
+		resp, err := http.Get(adminUrl + path)
+		if err != nil {
+			return 0, ""
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	assert.Eventually(t, func() bool {
+		code, _ := adminGet("/healthz")
+
+		return code == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+
+	for _, path := range []string{"/livez", "/readyz"} {
+		code, body := adminGet(path)
+		assert.Equal(t, http.StatusOK, code, path)
+		assert.Contains(t, body, `"success":true`, path)
+	}
+
+	code, body := adminGet("/metrics")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `foo_bar_requests_total{handler="/bar",method="GET",status="2xx"} 1`)
+	assert.NotContains(t, body, `handler="/metrics"`)
+
+	code, body = adminGet("/debug/routes")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"path":"/bar"`)
+	assert.NotContains(t, body, `"path":"/healthz"`)
+
+	code, _ = adminGet("/debug/pprof/cmdline")
+	assert.Equal(t, http.StatusOK, code)
+
+	// shutdown
+	app.RequireStop()
+
+	code, _ = adminGet("/healthz")
+	assert.Equal(t, 0, code)
+}
+
+func TestModuleWithAdminServerShutdownOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		coordinator *healthcheck.ShutdownCoordinator
+	}{
+		{
+			name: "without shutdown coordinator",
+		},
+		{
+			name: "with shutdown coordinator",
+			coordinator: healthcheck.NewShutdownCoordinator(healthcheck.ShutdownConfig{
+				Order: []string{fxhttpserver.ModuleName},
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ports []int
+			for i := 0; i < 2; i++ {
+				listener, err := net.Listen("tcp", "127.0.0.1:0")
+				assert.NoError(t, err)
+				ports = append(ports, listener.Addr().(*net.TCPAddr).Port)
+				assert.NoError(t, listener.Close())
+			}
+
+			port, adminPort := ports[0], ports[1]
+
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			t.Setenv("MODULES_HTTP_SERVER_PORT", strconv.Itoa(port))
+			t.Setenv("MODULES_HTTP_SERVER_ADMIN_ENABLED", "true")
+			t.Setenv("MODULES_HTTP_SERVER_ADMIN_PORT", strconv.Itoa(adminPort))
+
+			checker, err := healthcheck.NewDefaultCheckerFactory().Create()
+			assert.NoError(t, err)
+
+			options := []fx.Option{
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Supply(checker),
+			}
+
+			if tt.coordinator != nil {
+				options = append(options, fx.Supply(tt.coordinator))
+			}
+
+			var httpServer *echo.Echo
+
+			entered := make(chan struct{})
+			release := make(chan struct{})
+
+			options = append(options, fxhttpserver.AsHandler("GET", "/slow", func(c echo.Context) error {
+				close(entered)
+				<-release
+
+				return c.NoContent(http.StatusOK)
+			}))
+
+			app := fxtest.New(t, append(options, fx.Populate(&httpServer))...).RequireStart()
+
+			adminUp := func() bool {
+				//nolint:noctx
+				resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/healthz", adminPort))
+				if err != nil {
+					return false
+				}
+
+				return resp.Body.Close() == nil
+			}
+
+			assert.Eventually(t, adminUp, 5*time.Second, 50*time.Millisecond)
+
+			// in-flight request on the main server, draining on shutdown
+			responded := make(chan int, 1)
+			go func() {
+				//nolint:noctx
+				resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/slow", port))
+				if err != nil {
+					responded <- 0
+
+					return
+				}
+				defer resp.Body.Close()
+
+				responded <- resp.StatusCode
+			}()
+
+			select {
+			case <-entered:
+			case code := <-responded:
+				t.Fatalf("request responded with %d before shutdown", code)
+			}
+
+			stopped := make(chan error, 1)
+			go func() {
+				stopped <- app.Stop(context.Background())
+			}()
+
+			// the admin server is down while the main server is still draining
+			assert.Eventually(t, func() bool { return !adminUp() }, 5*time.Second, 50*time.Millisecond)
+
+			close(release)
+
+			assert.Equal(t, http.StatusOK, <-responded)
+			assert.NoError(t, <-stopped)
+		})
+	}
+}
+
+func TestModuleWithBodyLoggingDisabledByRoute(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_LOG_BODY_REQUEST", "true")
+	t.Setenv("MODULES_HTTP_SERVER_LOG_BODY_RESPONSE", "true")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("POST", "/default", concreteEchoBodyHandler),
+			fxhttpserver.AsHandlersGroup(
+				"/group",
+				[]*fxhttpserver.HandlerRegistration{
+					fxhttpserver.NewHandlerRegistration("POST", "/silent", concreteEchoBodyHandler, fxhttpserver.WithoutBodyLogging()),
+				},
+			),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// [POST] /default
+	req := httptest.NewRequest(http.MethodPost, "/default", strings.NewReader("default body"))
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "default body", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":        "info",
+		"method":       "POST",
+		"uri":          "/default",
+		"status":       200,
+		"requestBody":  "default body",
+		"responseBody": "default body",
+		"message":      "request logger",
+	})
+
+	// [POST] /group/silent
+	req = httptest.NewRequest(http.MethodPost, "/group/silent", strings.NewReader("silent body"))
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "silent body", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"method":  "POST",
+		"uri":     "/group/silent",
+		"status":  200,
+		"message": "request logger",
+	})
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"requestBody": "silent body",
+	})
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"responseBody": "silent body",
+	})
+}
+
+func TestModuleWithBodyLoggingEnabledByRoute(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("POST", "/default", concreteEchoBodyHandler),
+			fxhttpserver.AsHandler("POST", "/audited", concreteEchoBodyHandler, fxhttpserver.WithBodyLogging()),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// [POST] /default
+	req := httptest.NewRequest(http.MethodPost, "/default", strings.NewReader("default body"))
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"method":  "POST",
+		"uri":     "/default",
+		"status":  200,
+		"message": "request logger",
+	})
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"requestBody": "default body",
+	})
+
+	// [POST] /audited
+	req = httptest.NewRequest(http.MethodPost, "/audited", strings.NewReader("audited body"))
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "audited body", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":        "info",
+		"method":       "POST",
+		"uri":          "/audited",
+		"status":       200,
+		"requestBody":  "audited body",
+		"responseBody": "audited body",
+		"message":      "request logger",
+	})
+}
+
+func TestModuleWithMetrics(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_DEBUG", "true")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+	var traceExporter tracetest.TestTraceExporter
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer, &logBuffer, &traceExporter, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	// [GET] /bar
+	req := httptest.NewRequest(http.MethodGet, "/bar", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "bar: test")
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "test",
+		"module":  "httpserver",
+		"message": "in bar handler",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "test",
+		"module":  "httpserver",
+		"method":  "GET",
+		"uri":     "/bar",
+		"status":  200,
+		"message": "request logger",
+	})
+
+	tracetest.AssertHasTraceSpan(
+		t,
+		traceExporter,
+		"GET /bar",
+		semconv.HTTPMethod(http.MethodGet),
+		semconv.HTTPRoute("/bar"),
+		semconv.HTTPStatusCode(http.StatusOK),
+	)
+
+	expectedHelp := `
+		# HELP foo_bar_requests_total Number of processed HTTP requests
+		# TYPE foo_bar_requests_total counter
+	`
+	expectedMetric := `
+		foo_bar_requests_total{handler="/bar",method="GET",status="2xx"} 1
+	`
+
+	err := testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedHelp+expectedMetric),
+		"foo_bar_requests_total",
+	)
+	assert.NoError(t, err)
+
+	// sizes observed with the configured size buckets
+	metricFamilies, err := metricsRegistry.Gather()
+	assert.NoError(t, err)
+
+	sizeHistograms := map[string]*dto.Histogram{}
+	for _, metricFamily := range metricFamilies {
+		if name := metricFamily.GetName(); name == "foo_bar_request_size_bytes" || name == "foo_bar_response_size_bytes" {
+			sizeHistograms[name] = metricFamily.GetMetric()[0].GetHistogram()
+		}
+	}
+
+	assert.Len(t, sizeHistograms, 2)
+
+	for _, histogram := range sizeHistograms {
+		assert.Equal(t, uint64(1), histogram.GetSampleCount())
+		assert.Len(t, histogram.GetBucket(), 2)
+		assert.Equal(t, float64(100), histogram.GetBucket()[0].GetUpperBound())
+		assert.Equal(t, float64(1000), histogram.GetBucket()[1].GetUpperBound())
+	}
+
+	assert.Equal(t, float64(0), sizeHistograms["foo_bar_request_size_bytes"].GetSampleSum())
+	assert.Equal(t, float64(rec.Body.Len()), sizeHistograms["foo_bar_response_size_bytes"].GetSampleSum())
+}
+
+func TestModuleWithMiddlewaresPriorities(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	orderedMiddleware := func(name string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Response().Header().Add("x-order", name)
+
+				return next(c)
+			}
+		}
+	}
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsMiddleware(orderedMiddleware("rate-limit"), fxhttpserver.GlobalUse),
+			fxhttpserver.AsMiddleware(orderedMiddleware("auth"), fxhttpserver.GlobalUse, fxhttpserver.WithPriority(10)),
+			fxhttpserver.AsMiddleware(orderedMiddleware("audit"), fxhttpserver.GlobalUse),
+			fxhttpserver.AsMiddleware(orderedMiddleware("last"), fxhttpserver.GlobalUse, fxhttpserver.WithPriority(-10)),
+			fxhttpserver.AsMiddleware(orderedMiddleware("pre"), fxhttpserver.GlobalPre),
+			fxhttpserver.AsMiddleware(orderedMiddleware("first-pre"), fxhttpserver.GlobalPre, fxhttpserver.WithPriority(1)),
+			fxhttpserver.AsHandler("GET", "/ordered", concreteHandler),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// [GET] /ordered
+	req := httptest.NewRequest(http.MethodGet, "/ordered", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"first-pre", "pre", "auth", "rate-limit", "audit", "last"}, rec.Header().Values("x-order"))
+}
+
+func TestModuleWithGlobalExclusions(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	tests := []struct {
+		name             string
+		exclude          *string
+		excludedPaths    []string
+		notExcludedPaths []string
+	}{
+		{
+			name:             "default exclusions",
+			exclude:          nil,
+			excludedPaths:    []string{"/healthz", "/readyz", "/metrics"},
+			notExcludedPaths: []string{"/global"},
+		},
+		{
+			name:             "configured exclusions",
+			exclude:          func() *string { s := "/global"; return &s }(),
+			excludedPaths:    []string{"/global"},
+			notExcludedPaths: []string{"/healthz", "/readyz", "/metrics"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.exclude != nil {
+				t.Setenv("MODULES_HTTP_SERVER_EXCLUDE", *tt.exclude)
+			}
+
+			var httpServer *echo.Echo
+			var logBuffer logtest.TestLogBuffer
+			var traceExporter tracetest.TestTraceExporter
+			var metricsRegistry *prometheus.Registry
+
+			var handlers []fx.Option
+			for _, path := range append(tt.excludedPaths, tt.notExcludedPaths...) {
+				handlers = append(handlers, fxhttpserver.AsHandler("GET", path, concreteHandler))
+			}
+
+			fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Options(handlers...),
+				fx.Populate(&httpServer, &logBuffer, &traceExporter, &metricsRegistry),
+			).RequireStart().RequireStop()
+
+			for _, path := range append(tt.excludedPaths, tt.notExcludedPaths...) {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				rec := httptest.NewRecorder()
+				httpServer.ServeHTTP(rec, req)
+
+				assert.Equal(t, http.StatusOK, rec.Code, path)
+			}
+
+			for _, path := range tt.excludedPaths {
+				logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+					"uri":     path,
+					"message": "request logger",
+				})
+				tracetest.AssertHasNotTraceSpan(t, traceExporter, fmt.Sprintf("GET %s", path))
+			}
+
+			for _, path := range tt.notExcludedPaths {
+				logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+					"uri":     path,
+					"message": "request logger",
+				})
+				tracetest.AssertHasTraceSpan(t, traceExporter, fmt.Sprintf("GET %s", path))
+			}
+
+			// metrics
+			metricFamilies, err := metricsRegistry.Gather()
+			assert.NoError(t, err)
+
+			handlersWithMetrics := map[string]bool{}
+			for _, metricFamily := range metricFamilies {
+				if metricFamily.GetName() != "foo_bar_requests_total" {
+					continue
+				}
+
+				for _, metric := range metricFamily.GetMetric() {
+					for _, label := range metric.GetLabel() {
+						if label.GetName() == "handler" {
+							handlersWithMetrics[label.GetValue()] = true
+						}
+					}
+				}
+			}
+
+			for _, path := range tt.excludedPaths {
+				assert.False(t, handlersWithMetrics[path], path)
+			}
+
+			for _, path := range tt.notExcludedPaths {
+				assert.True(t, handlersWithMetrics[path], path)
+			}
+		})
+	}
+}
+
+func TestModuleWithResponseCache(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_CACHE_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_CACHE_TTL", "1m")
+
+	calls := 0
+
+	var httpServer *echo.Echo
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/catalog", func(c echo.Context) error {
+				calls++
+
+				return c.String(http.StatusOK, fmt.Sprintf("catalog:%d", calls))
+			}),
+		),
+		fx.Populate(&httpServer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	// [GET] /catalog
+	for _, expectedCache := range []string{"MISS", "HIT", "HIT"} {
+		req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "catalog:1", rec.Body.String())
+		assert.Equal(t, expectedCache, rec.Header().Get("X-Cache"))
+	}
+
+	assert.Equal(t, 1, calls)
+
+	expected := `
+		# HELP foo_bar_response_cache_hits_total Number of HTTP responses served from cache
+		# TYPE foo_bar_response_cache_hits_total counter
+		foo_bar_response_cache_hits_total 2
+		# HELP foo_bar_response_cache_misses_total Number of cacheable HTTP requests not served from cache
+		# TYPE foo_bar_response_cache_misses_total counter
+		foo_bar_response_cache_misses_total 1
+	`
+
+	err := testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expected),
+		"foo_bar_response_cache_hits_total",
+		"foo_bar_response_cache_misses_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithRequestTimeout(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_TIMEOUT_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_TIMEOUT_DURATION", "50ms")
+
+	downstreamCancelled := make(chan struct{})
+
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(downstreamCancelled)
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer downstream.Close()
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/downstream", func(c echo.Context) error {
+				budget, ok := httpserver.CtxRemainingBudget(c)
+				assert.True(t, ok)
+				assert.LessOrEqual(t, budget, 50*time.Millisecond)
+
+				req, err := http.NewRequestWithContext(c.Request().Context(), http.MethodGet, downstream.URL, nil)
+				if err != nil {
+					return err
+				}
+
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					return err
+				}
+
+				return resp.Body.Close()
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// [GET] /downstream
+	start := time.Now()
+
+	req := httptest.NewRequest(http.MethodGet, "/downstream", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	select {
+	case <-downstreamCancelled:
+	case <-time.After(time.Second):
+		t.Error("downstream call was not cancelled")
+	}
+}
+
+func TestModuleWithRequestTimeoutExclusion(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_TIMEOUT_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_TIMEOUT_DURATION", "10ms")
+	t.Setenv("MODULES_HTTP_SERVER_TIMEOUT_EXCLUDE", "/ws")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/ws/slow", func(c echo.Context) error {
+				_, ok := httpserver.CtxRemainingBudget(c)
+				assert.False(t, ok)
+
+				time.Sleep(20 * time.Millisecond)
+
+				return c.NoContent(http.StatusNoContent)
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// [GET] /ws/slow
+	req := httptest.NewRequest(http.MethodGet, "/ws/slow", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestModuleWithLogSuccessSampling(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_LOG_SUCCESS_SAMPLE_RATE", "0")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/success", func(c echo.Context) error {
+				return c.String(http.StatusOK, httpserver.CtxRequestId(c))
+			}),
+			fxhttpserver.AsHandler("GET", "/failure", func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failure")
+			}),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// [GET] /success
+	req := httptest.NewRequest(http.MethodGet, "/success", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, testRequestId, rec.Body.String())
+	assert.Equal(t, testRequestId, rec.Header().Get("x-request-id"))
+
+	// [GET] /failure
+	req = httptest.NewRequest(http.MethodGet, "/failure", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"uri":     "/success",
+		"message": "request logger",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "error",
+		"uri":       "/failure",
+		"status":    500,
+		"requestID": testRequestId,
+		"message":   "request logger",
+	})
+}
+
+var ErrQuotaExceeded = errors.New("quota exceeded for account 42")
+
+func TestModuleWithErrorMappers(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_ERRORS_OBFUSCATE", "true")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHttpServerErrorMapper(func(err error) (*echo.HTTPError, bool) {
+			if errors.Is(err, ErrQuotaExceeded) {
+				return echo.NewHTTPError(http.StatusTooManyRequests, "quota exceeded, retry later"), true
+			}
+
+			return nil, false
+		}),
+		fxhttpserver.AsHttpServerErrorMapper(func(err error) (*echo.HTTPError, bool) {
+			if errors.Is(err, ErrQuotaExceeded) {
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "registered later, never used"), true
+			}
+
+			return nil, false
+		}),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/quota", func(c echo.Context) error {
+				return fmt.Errorf("cannot process order: %w", ErrQuotaExceeded)
+			}),
+			fxhttpserver.AsHandler("GET", "/random", func(c echo.Context) error {
+				return fmt.Errorf("random error with sensitive details")
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// [GET] /quota
+	req := httptest.NewRequest(http.MethodGet, "/quota", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, `{"message":"quota exceeded, retry later"}`+"\n", rec.Body.String())
+
+	// [GET] /random
+	req = httptest.NewRequest(http.MethodGet, "/random", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, `{"message":"Internal Server Error"}`+"\n", rec.Body.String())
+}
+
+func TestModuleWithDynamicHandlers(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+	var registry *fxhttpserver.HttpServerRegistry
+	var logBuffer logtest.TestLogBuffer
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer, &registry, &logBuffer),
+	).RequireStart()
+
+	client := httpservertest.NewTestClient(httpServer)
+
+	get := func(path string) (int, string) {
+		resp, err := client.Get("http://test" + path)
+		if !assert.NoError(t, err) {
+			return 0, ""
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		return resp.StatusCode, string(body)
+	}
+
+	// conflicting handler
+	err := registry.AddHandler("GET", "/bar", func(c echo.Context) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "route GET /bar is already registered", err.Error())
+
+	// registering while serving
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				code, _ := get("/bar")
+				assert.Equal(t, http.StatusOK, code)
+			}
+		}()
+	}
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			err := registry.AddHandlersGroup(fmt.Sprintf("/plugin-%d", i), []*httpserver.DynamicRoute{
+				{
+					Method: "GET",
+					Path:   "/foo",
+					Handler: func(c echo.Context) error {
+						return c.String(http.StatusOK, c.Path())
+					},
+				},
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i := 0; i < 5; i++ {
+		code, body := get(fmt.Sprintf("/plugin-%d/foo", i))
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, fmt.Sprintf("/plugin-%d/foo", i), body)
+	}
+
+	app.RequireStop()
+
+	// dynamic handlers go through the default middlewares
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "test",
+		"module":  "httpserver",
+		"method":  "GET",
+		"uri":     "/plugin-0/foo",
+		"status":  200,
+		"message": "request logger",
+	})
+}
+
+func TestModuleWithUploads(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_UPLOADS_MAX_MEMORY", "4")
+	t.Setenv("MODULES_HTTP_SERVER_UPLOADS_TEMP_DIR", t.TempDir())
+	t.Setenv("MODULES_HTTP_SERVER_UPLOADS_ALLOWED_CONTENT_TYPES", "text/plain")
+	t.Setenv("MODULES_HTTP_SERVER_UPLOADS_MAX_FILE_SIZE", "10")
+
+	var httpServer *echo.Echo
+	var metricsRegistry *prometheus.Registry
+
+	uploadHandler := func(c echo.Context) error {
+		file, err := httpserver.CtxUploadedFile(c, "file")
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		return c.String(http.StatusOK, fmt.Sprintf("%s:%d", file.Filename, file.Size))
+	}
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("POST", "/upload", uploadHandler, fxhttpserver.WithUploads()),
+			fxhttpserver.AsHandler("POST", "/other", uploadHandler),
+		),
+		fx.Populate(&httpServer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	newUploadRequest := func(path string, contentType string, content string) *http.Request {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="foo"`)
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		assert.NoError(t, err)
+
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, path, body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+
+		return req
+	}
+
+	// [POST] /upload within limits
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, newUploadRequest("/upload", "text/plain", "some text"))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "foo:9", rec.Body.String())
+
+	// [POST] /upload beyond max file size
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, newUploadRequest("/upload", "text/plain", "some bigger text"))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// [POST] /upload with not allowed content type
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, newUploadRequest("/upload", "image/png", "png"))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	// [POST] /other without uploads handling
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, newUploadRequest("/other", "text/plain", "some text"))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	expected := `
+		# HELP foo_bar_uploads_rejected_total Number of rejected HTTP uploads
+		# TYPE foo_bar_uploads_rejected_total counter
+		foo_bar_uploads_rejected_total{reason="too_large"} 1
+		foo_bar_uploads_rejected_total{reason="unsupported_content_type"} 1
+	`
+
+	err := testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expected),
+		"foo_bar_uploads_rejected_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithMetricsExposition(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_METRICS_EXPOSE_ENABLED", "true")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// [GET] /bar
+	req := httptest.NewRequest(http.MethodGet, "/bar", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// [GET] /metrics
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "# TYPE foo_bar_requests_total counter")
+	assert.Contains(t, rec.Body.String(), `foo_bar_requests_total{handler="/bar",method="GET",status="2xx"} 1`)
+	assert.NotContains(t, rec.Body.String(), `handler="/metrics"`)
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "test",
+		"module":  "httpserver",
+		"method":  "GET",
+		"uri":     "/metrics",
+		"message": "request logger",
+	})
+}
+
+func TestModuleWithProtectedMetricsExposition(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_METRICS_EXPOSE_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_METRICS_EXPOSE_PATH", "/custom-metrics")
+	t.Setenv("MODULES_HTTP_SERVER_METRICS_EXPOSE_TOKEN", "secret")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// [GET] /custom-metrics with invalid token
+	req := httptest.NewRequest(http.MethodGet, "/custom-metrics", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer invalid")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// [GET] /custom-metrics with valid token
+	req = httptest.NewRequest(http.MethodGet, "/custom-metrics", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer secret")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestModuleWithConfiguredJsonSerializer(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_JSON_SERIALIZER", "goccy")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("POST", "/json", func(c echo.Context) error {
+				payload := map[string]int{}
+				if err := c.Bind(&payload); err != nil {
+					return err
+				}
+
+				return c.JSON(http.StatusOK, payload)
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	assert.IsType(t, &httpserver.GoccyEchoJsonSerializer{}, httpServer.JSONSerializer)
+
+	// [POST] /json
+	req := httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"foo":1}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "{\"foo\":1}\n", rec.Body.String())
+
+	// [POST] /json with malformed payload
+	req = httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"foo":"bar"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestModuleWithConfiguredJsonSerializerAlias(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+
+	err := os.WriteFile(file, []byte("app:\n  name: test\nmodules:\n  http:\n    server:\n      json_serializer: goccy\n"), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("APP_CONFIG_PATH", dir)
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	assert.IsType(t, &httpserver.GoccyEchoJsonSerializer{}, httpServer.JSONSerializer)
+}
+
+func TestModuleWithProvidedJsonSerializer(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_JSON_SERIALIZER", "goccy")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(
+			fx.Annotate(
+				func() *echo.DefaultJSONSerializer {
+					return &echo.DefaultJSONSerializer{}
+				},
+				fx.As(new(echo.JSONSerializer)),
+			),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	assert.IsType(t, &echo.DefaultJSONSerializer{}, httpServer.JSONSerializer)
+}
+
+func TestModuleWithValidation(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_VALIDATION_ENABLED", "true")
+
+	type testRequest struct {
+		Name string `json:"name" validate:"required,lowercase_only"`
+	}
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsValidation("lowercase_only", func(fl validator.FieldLevel) bool {
+			return strings.ToLower(fl.Field().String()) == fl.Field().String()
+		}),
+		fx.Options(
+			fxhttpserver.AsHandler("POST", "/validate", func(c echo.Context) error {
+				req, err := httpserver.BindAndValidate[testRequest](c)
+				if err != nil {
+					return err
+				}
+
+				return c.String(http.StatusOK, req.Name)
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	assert.IsType(t, &httpserver.EchoValidator{}, httpServer.Validator)
+
+	// [POST] /validate with valid payload
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"name":"foo"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "foo", rec.Body.String())
+
+	// [POST] /validate with invalid payload
+	req = httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"name":"FOO"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"name","tag":"lowercase_only"`)
+}
+
+func TestModuleWithExcludedDefaultMiddlewaresByRoute(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+	var traceExporter tracetest.TestTraceExporter
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/skipped/:id", func(c echo.Context) error {
+				return c.String(http.StatusOK, "skipped")
+			}, fxhttpserver.WithoutDefaultMiddlewares(fxhttpserver.Logger, fxhttpserver.Metrics, fxhttpserver.Tracer)),
+			fxhttpserver.AsHandler("GET", "/sibling/:id", func(c echo.Context) error {
+				return c.String(http.StatusOK, "sibling")
+			}),
+		),
+		fx.Populate(&httpServer, &logBuffer, &traceExporter, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	for _, path := range []string{"/skipped/1", "/sibling/1"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "test",
+		"module":  "httpserver",
+		"uri":     "/skipped/1",
+		"message": "request logger",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "test",
+		"module":  "httpserver",
+		"uri":     "/sibling/1",
+		"message": "request logger",
+	})
+
+	tracetest.AssertHasNotTraceSpan(t, traceExporter, "GET /skipped/:id")
+	tracetest.AssertHasTraceSpan(t, traceExporter, "GET /sibling/:id")
+
+	expectedHelp := `
+		# HELP foo_bar_requests_total Number of processed HTTP requests
+		# TYPE foo_bar_requests_total counter
+	`
+	expectedMetric := `
+		foo_bar_requests_total{handler="/sibling/:id",method="GET",status="2xx"} 1
+	`
+
+	err := testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedHelp+expectedMetric),
+		"foo_bar_requests_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithNamedRoutes(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	urlHandler := func(c echo.Context) error {
+		url, err := httpserver.URL(c, c.QueryParam("name"), c.QueryParam("id"))
+		if err != nil {
+			return err
+		}
+
+		return c.String(http.StatusOK, url)
+	}
+
+	tests := []struct {
+		name        string
+		baseUrl     string
+		expectedUrl string
+	}{
+		{"relative", "", "/group/users/42"},
+		{"absolute", "https://example.com", "https://example.com/group/users/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MODULES_HTTP_SERVER_BASE_URL", tt.baseUrl)
+
+			var httpServer *echo.Echo
+
+			fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Options(
+					fxhttpserver.AsHandler("GET", "/url", urlHandler, fxhttpserver.WithName("url")),
+					fxhttpserver.AsHandlersGroup(
+						"/group",
+						[]*fxhttpserver.HandlerRegistration{
+							fxhttpserver.NewHandlerRegistration("GET", "/users/:id", concreteHandler, fxhttpserver.WithName("user.show")),
+						},
+					),
+				),
+				fx.Populate(&httpServer),
+			).RequireStart().RequireStop()
+
+			// [GET] /url
+			req := httptest.NewRequest(http.MethodGet, "/url?name=user.show&id=42", nil)
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.expectedUrl, rec.Body.String())
+		})
+	}
+}
+
+func TestModuleWithDuplicateRouteNames(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/foo", concreteHandler, fxhttpserver.WithName("duplicate")),
+			fxhttpserver.AsHandler("GET", "/bar", concreteHandler, fxhttpserver.WithName("duplicate")),
+		),
+		fx.Populate(&httpServer),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "route name duplicate is used by both GET /")
+}
+
+func TestModuleWithTemplates(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("TEMPLATES_ENABLED", "true")
+	t.Setenv("TEMPLATES_PATH", "testdata/templates/*.html")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+	var traceExporter tracetest.TestTraceExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/template", handler.NewTestTemplateHandler),
+		),
+		fx.Populate(&httpServer, &logBuffer, &traceExporter),
+	).RequireStart().RequireStop()
+
+	// [GET] /template
+	req := httptest.NewRequest(http.MethodGet, "/template", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "App name: test")
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "test",
+		"module":  "httpserver",
+		"message": "in template handler",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "test",
+		"module":  "httpserver",
+		"method":  "GET",
+		"uri":     "/template",
+		"status":  200,
+		"message": "request logger",
+	})
+
+	tracetest.AssertHasTraceSpan(
+		t,
+		traceExporter,
+		"GET /template",
+		semconv.HTTPMethod(http.MethodGet),
+		semconv.HTTPRoute("/template"),
+		semconv.HTTPStatusCode(http.StatusOK),
+	)
+}
+
+func TestModuleWithRemoveTrailingSlashRewrite(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_ROUTING_REMOVE_TRAILING_SLASH", "true")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/users", func(c echo.Context) error {
+				return c.String(http.StatusOK, c.Request().URL.Path)
+			}),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// [GET] /users/
+	req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/users", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"service":   "test",
+		"module":    "httpserver",
+		"method":    "GET",
+		"uri":       "/users",
+		"status":    200,
+		"requestID": testRequestId,
+		"message":   "request logger",
+	})
+}
+
+func TestModuleWithAddTrailingSlashRedirect(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_ROUTING_ADD_TRAILING_SLASH", "true")
+	t.Setenv("MODULES_HTTP_SERVER_ROUTING_REDIRECT_CODE", "308")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/users/", func(c echo.Context) error {
+				return c.String(http.StatusOK, c.Request().URL.Path)
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// [GET] /users?foo=bar
+	req := httptest.NewRequest(http.MethodGet, "/users?foo=bar", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+	assert.Equal(t, "/users/?foo=bar", rec.Header().Get(echo.HeaderLocation))
+}
+
+func TestModuleWithConflictingTrailingSlashConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_ROUTING_REMOVE_TRAILING_SLASH", "true")
+	t.Setenv("MODULES_HTTP_SERVER_ROUTING_ADD_TRAILING_SLASH", "true")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "trailing slash cannot be both removed and added")
+}
+
+func TestModuleWithHeadersPropagation(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_CLIENT_PROPAGATE_HEADERS", "x-tenant-id")
+
+	// downstream service
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-request-id", r.Header.Get("x-request-id"))
+		w.Header().Set("received-tenant-id", r.Header.Get("x-tenant-id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer downstream.Close()
+
+	client, err := httpclient.NewDefaultHttpClientFactory().Create(
+		httpclient.WithTransport(
+			transport.NewPropagationTransportWithConfig(
+				transport.NewRequestIdTransport(nil),
+				&transport.PropagationTransportConfig{
+					Headers: []string{"x-tenant-id"},
+				},
+			),
+		),
+	)
+	assert.NoError(t, err)
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/downstream", func(c echo.Context) error {
+				req, err := http.NewRequestWithContext(c.Request().Context(), http.MethodGet, downstream.URL, nil)
+				if err != nil {
+					return err
+				}
+
+				resp, err := client.Do(req)
+				if err != nil {
+					return err
+				}
+				defer resp.Body.Close()
+
+				return c.String(
+					http.StatusOK,
+					resp.Header.Get("received-request-id")+" "+resp.Header.Get("received-tenant-id"),
+				)
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// [GET] /downstream
+	req := httptest.NewRequest(http.MethodGet, "/downstream", nil)
+	req.Header.Set("x-request-id", testRequestId)
+	req.Header.Set("x-tenant-id", "test-tenant")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, testRequestId+" test-tenant", rec.Body.String())
+}
+
+func TestModuleWithTrailingSlashModes(t *testing.T) {
+	tests := []struct {
+		mode             string
+		expectedCode     int
+		expectedBody     string
+		expectedLocation string
+		expectedHandler  string
+		expectedStatus   string
+	}{
+		{
+			mode:            "",
+			expectedCode:    http.StatusNotFound,
+			expectedHandler: httpservermiddleware.HttpServerMetricsNotFoundPath,
+			expectedStatus:  "4xx",
+		},
+		{
+			mode:            fxhttpserver.TrailingSlashStrict,
+			expectedCode:    http.StatusNotFound,
+			expectedHandler: httpservermiddleware.HttpServerMetricsNotFoundPath,
+			expectedStatus:  "4xx",
+		},
+		{
+			mode:            fxhttpserver.TrailingSlashStrip,
+			expectedCode:    http.StatusOK,
+			expectedBody:    "/users",
+			expectedHandler: "/users",
+			expectedStatus:  "2xx",
+		},
+		{
+			mode:             fxhttpserver.TrailingSlashRedirect,
+			expectedCode:     http.StatusMovedPermanently,
+			expectedLocation: "/users?foo=bar",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			t.Setenv("MODULES_HTTP_SERVER_ROUTER_TRAILING_SLASH", tt.mode)
+
+			var httpServer *echo.Echo
+			var metricsRegistry *prometheus.Registry
+
+			fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Options(
+					fxhttpserver.AsHandler("GET", "/users", func(c echo.Context) error {
+						return c.String(http.StatusOK, c.Request().URL.Path)
+					}),
+				),
+				fx.Populate(&httpServer, &metricsRegistry),
+			).RequireStart().RequireStop()
+
+			// [GET] /users/?foo=bar
+			req := httptest.NewRequest(http.MethodGet, "/users/?foo=bar", nil)
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Equal(t, tt.expectedLocation, rec.Header().Get(echo.HeaderLocation))
+
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+
+			// redirected before routing, and therefore before the metrics middleware
+			if tt.expectedHandler == "" {
+				count, err := testutil.GatherAndCount(metricsRegistry, "foo_bar_requests_total")
+				assert.NoError(t, err)
+				assert.Equal(t, 0, count)
+
+				return
+			}
+
+			// metrics labelled with the route path template
+			expectedMetric := fmt.Sprintf(
+				`
+				# HELP foo_bar_requests_total Number of processed HTTP requests
+				# TYPE foo_bar_requests_total counter
+				foo_bar_requests_total{handler="%s",method="GET",status="%s"} 1
+				`,
+				tt.expectedHandler,
+				tt.expectedStatus,
+			)
+
+			err := testutil.GatherAndCompare(metricsRegistry, strings.NewReader(expectedMetric), "foo_bar_requests_total")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestModuleWithTrailingSlashRedirectModeAndRedirectCode(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_ROUTER_TRAILING_SLASH", "redirect")
+	t.Setenv("MODULES_HTTP_SERVER_ROUTING_REDIRECT_CODE", "308")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/users", func(c echo.Context) error {
+				return c.String(http.StatusOK, c.Request().URL.Path)
+			}),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// [GET] /users/
+	req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+	assert.Equal(t, "/users", rec.Header().Get(echo.HeaderLocation))
+}
+
+func TestModuleWithInvalidTrailingSlashConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		expectedError string
+	}{
+		{
+			name:          "invalid mode",
+			env:           map[string]string{"MODULES_HTTP_SERVER_ROUTER_TRAILING_SLASH": "invalid"},
+			expectedError: "invalid trailing slash mode invalid",
+		},
+		{
+			name: "strip mode with add trailing slash",
+			env: map[string]string{
+				"MODULES_HTTP_SERVER_ROUTER_TRAILING_SLASH":      "strip",
+				"MODULES_HTTP_SERVER_ROUTING_ADD_TRAILING_SLASH": "true",
+			},
+			expectedError: "trailing slash cannot be both removed and added",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var httpServer *echo.Echo
+
+			app := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Populate(&httpServer),
+			)
+
+			assert.Error(t, app.Err())
+			assert.Contains(t, app.Err().Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestModuleDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_ENABLED", "false")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// not serving
+	assert.Nil(t, httpServer.Listener)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"message": "http server disabled, not serving",
+	})
+
+	// still constructed, with its handlers
+	req := httptest.NewRequest(http.MethodGet, "/bar", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "bar: test")
+}
+
+func TestModuleWithMaxHeaderBytes(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_MAX_HEADER_BYTES", "1KB")
+
+	var httpServer *echo.Echo
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart()
+
+	assert.Equal(t, 1024, httpServer.Server.MaxHeaderBytes)
+
+	// within limit
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://test/bar", nil)
+	assert.NoError(t, err)
+
+	resp, err := httpservertest.NewTestClient(httpServer).Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// oversized headers (beyond the limit and the net/http 4096 bytes slack)
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, "http://test/bar", nil)
+	assert.NoError(t, err)
+	req.Header.Set("x-foo", strings.Repeat("x", 8192))
+
+	resp, err = httpservertest.NewTestClient(httpServer).Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+
+	app.RequireStop()
+}
+
+func TestModuleWithShutdownCoordinator(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+	var events []string
+
+	coordinator := healthcheck.NewShutdownCoordinator(healthcheck.ShutdownConfig{
+		Order: []string{fxhttpserver.ModuleName, "grpcserver"},
+	})
+
+	// registered before the http server stage, but stopped after it
+	coordinator.RegisterStage("grpcserver", func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://test/bar", nil)
+		assert.NoError(t, err)
+
+		//nolint:bodyclose
+		_, err = httpservertest.NewTestClient(httpServer).Do(req)
+
+		events = append(events, fmt.Sprintf("grpcserver stopped, http server serving: %v", err == nil))
+
+		return nil
+	})
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Supply(coordinator),
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart()
+
+	var stages []string
+	for _, stage := range coordinator.Stages() {
+		stages = append(stages, stage.Name)
+	}
+
+	assert.Equal(t, []string{fxhttpserver.ModuleName, "grpcserver"}, stages)
+
+	app.RequireStop()
+
+	assert.True(t, coordinator.Stopping())
+	assert.Equal(t, []string{"grpcserver stopped, http server serving: false"}, events)
+}
+
+func TestModuleWithDefaultMaxHeaderBytes(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, fxhttpserver.DefaultMaxHeaderBytes, httpServer.Server.MaxHeaderBytes)
+}
+
+func TestModuleWithCustomIdGenerator(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Decorate(generator.NewTestIdGenerator),
+		fx.Provide(service.NewTestService),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// request id generated by the custom generator
+	req := httptest.NewRequest(http.MethodGet, "/bar", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, generator.TestIdValue, rec.Header().Get("x-request-id"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"uri":       "/bar",
+		"requestID": generator.TestIdValue,
+		"message":   "request logger",
+	})
+}
+
+func TestModuleWithRecoveryStatus(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_RECOVERY_STATUS", "503")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/panic", handler.NewTestPanicHandler),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"Service Unavailable"`)
+}
+
+func TestModuleWithRecoveryDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_RECOVERY_ENABLED", "false")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/panic", handler.NewTestPanicHandler),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rec := httptest.NewRecorder()
+
+	// panic not recovered
+	assert.Panics(t, func() {
+		httpServer.ServeHTTP(rec, req)
+	})
+}
+
+func TestModuleWithLogsDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_LOG_ENABLED", "false")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/concrete", func(c echo.Context) error {
+				httpserver.CtxLogger(c).Info().Msg("handler log")
+
+				return c.String(http.StatusOK, httpserver.CtxRequestId(c))
+			}),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, "/concrete", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	// request id still propagated
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, testRequestId, rec.Header().Get(echo.HeaderXRequestID))
+	assert.Equal(t, testRequestId, rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"message":   "handler log",
+		"requestID": testRequestId,
+	})
+
+	// request not logged
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"message": "request logger",
+	})
+}
+
+func TestModuleWithRecoveryReadiness(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_RECOVERY_READINESS_THRESHOLD", "2")
+	t.Setenv("MODULES_HTTP_SERVER_RECOVERY_READINESS_WINDOW", "1m")
+
+	checker, err := healthcheck.NewDefaultCheckerFactory().Create()
+	assert.NoError(t, err)
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Supply(checker),
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/panic", handler.NewTestPanicHandler),
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	assert.True(t, checker.Check(context.Background(), healthcheck.Readiness).Success)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	}
+
+	// readiness flipped, liveness untouched
+	readiness := checker.Check(context.Background(), healthcheck.Readiness)
+	assert.False(t, readiness.Success)
+	assert.Equal(
+		t,
+		"2 recovered panics within 1m0s, threshold is 2",
+		readiness.ProbesResults[httpserver.DefaultPanicTrackerProbeName].Message,
+	)
+
+	assert.True(t, checker.Check(context.Background(), healthcheck.Liveness).Success)
+}
+
+func TestModuleWithInvalidRecoveryStatus(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_RECOVERY_STATUS", "200")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "invalid recovery status 200, must be a server error status")
+}
+
+func TestModuleWithInvalidRecoveryReadinessWindow(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_RECOVERY_READINESS_THRESHOLD", "5")
+	t.Setenv("MODULES_HTTP_SERVER_RECOVERY_READINESS_WINDOW", "often")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), `invalid config modules.http.server.recovery.readiness.window: invalid duration "often"`)
+}
+
+func TestModuleWithForwardedHeaders(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_FORWARDED_HEADERS_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_TRUSTED_PROXIES", "10.0.0.0/8")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/redirect", func(c echo.Context) error {
+				return c.Redirect(http.StatusFound, c.Scheme()+"://"+c.Request().Host+"/target")
+			}),
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// [GET] /redirect from trusted proxy
+	req := httptest.NewRequest(http.MethodGet, "/redirect", nil)
+	req.RemoteAddr = "10.0.0.1:12345"
+	req.Host = "internal.local"
+	req.Header.Set(echo.HeaderXForwardedProto, "https")
+	req.Header.Set("X-Forwarded-Host", "example.com")
+	req.Header.Set("x-request-id", "trusted-request-id")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://example.com/target", rec.Header().Get(echo.HeaderLocation))
+
+	// [GET] /redirect from untrusted address
+	req = httptest.NewRequest(http.MethodGet, "/redirect", nil)
+	req.RemoteAddr = "192.168.1.1:12345"
+	req.Host = "internal.local"
+	req.Header.Set(echo.HeaderXForwardedProto, "https")
+	req.Header.Set("X-Forwarded-Host", "example.com")
+	req.Header.Set("x-request-id", "untrusted-request-id")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "http://internal.local/target", rec.Header().Get(echo.HeaderLocation))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"scheme":    "https",
+		"uri":       "/redirect",
+		"requestID": "trusted-request-id",
+		"message":   "request logger",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"scheme":    "http",
+		"uri":       "/redirect",
+		"requestID": "untrusted-request-id",
+		"message":   "request logger",
+	})
+}
+
+func TestModuleWithInvalidTrustedProxies(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_FORWARDED_HEADERS_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_TRUSTED_PROXIES", "invalid")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "failed to create http server: invalid trusted proxy invalid")
+}
+
+func TestModuleDecoration(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Decorate(factory.NewTestHttpServerFactory),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	assert.False(t, httpServer.HideBanner)
+}
+
+func TestModuleWithTraceResponseHeader(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_TRACE_RESPONSE_HEADER", "x-trace-id")
+
+	var httpServer *echo.Echo
+	var traceExporter tracetest.TestTraceExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Options(
+			fxhttpserver.AsHandler("GET", "/concrete", concreteHandler),
+		),
+		fx.Populate(&httpServer, &traceExporter),
+	).RequireStart().RequireStop()
+
+	// propagated trace
+	req := httptest.NewRequest(http.MethodGet, "/concrete", nil)
+	req.Header.Add("traceparent", testTraceParent)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, testTraceId, rec.Header().Get("x-trace-id"))
+
+	// new trace
+	traceExporter.Reset()
+
+	req = httptest.NewRequest(http.MethodGet, "/concrete", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	span, err := traceExporter.Span("GET /concrete")
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, testTraceId, rec.Header().Get("x-trace-id"))
+	assert.Equal(t, span.SpanContext.TraceID().String(), rec.Header().Get("x-trace-id"))
+}
+
+func TestModuleWithMetricsTypes(t *testing.T) {
+	tests := []struct {
+		name              string
+		metricsType       string
+		objectives        string
+		expectedType      dto.MetricType
+		expectedQuantiles []float64
+	}{
+		{
+			name:         "default",
+			expectedType: dto.MetricType_HISTOGRAM,
+		},
+		{
+			name:         "histogram",
+			metricsType:  "histogram",
+			expectedType: dto.MetricType_HISTOGRAM,
+		},
+		{
+			name:              "summary",
+			metricsType:       "summary",
+			expectedType:      dto.MetricType_SUMMARY,
+			expectedQuantiles: []float64{0.5, 0.9, 0.99},
+		},
+		{
+			name:              "summary with objectives",
+			metricsType:       "summary",
+			objectives:        "0.5:0.05, 0.999:0.0001",
+			expectedType:      dto.MetricType_SUMMARY,
+			expectedQuantiles: []float64{0.5, 0.999},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			t.Setenv("MODULES_HTTP_SERVER_METRICS_TYPE", tt.metricsType)
+			t.Setenv("MODULES_HTTP_SERVER_METRICS_OBJECTIVES", tt.objectives)
+
+			var httpServer *echo.Echo
+			var metricsRegistry *prometheus.Registry
+
+			fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Provide(service.NewTestService),
+				fx.Options(
+					fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+				),
+				fx.Populate(&httpServer, &metricsRegistry),
+			).RequireStart().RequireStop()
+
+			req := httptest.NewRequest(http.MethodGet, "/bar", nil)
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			families, err := metricsRegistry.Gather()
+			assert.NoError(t, err)
+
+			var family *dto.MetricFamily
+			for _, f := range families {
+				if f.GetName() == "foo_bar_request_duration_seconds" {
+					family = f
+				}
+			}
+
+			assert.NotNil(t, family)
+			assert.Equal(t, tt.expectedType, family.GetType())
+
+			if tt.expectedType == dto.MetricType_SUMMARY {
+				var quantiles []float64
+				for _, q := range family.GetMetric()[0].GetSummary().GetQuantile() {
+					quantiles = append(quantiles, q.GetQuantile())
+				}
+
+				assert.Equal(t, tt.expectedQuantiles, quantiles)
+			}
+		})
+	}
+}
+
+func TestModuleWithInvalidMetricsType(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_METRICS_TYPE", "gauge")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "invalid http server metrics type gauge")
+}
+
+func TestModuleWithInvalidTypedConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_HTTP_SERVER_TIMEOUT_DURATION", "forever")
+	t.Setenv("MODULES_HTTP_SERVER_UPLOADS_MAX_MEMORY", "32 parsecs")
+
+	var httpServer *echo.Echo
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "could not decode config modules.http.server")
+	assert.Contains(t, app.Err().Error(), "'modules.http.server.timeout.duration'")
+	assert.Contains(t, app.Err().Error(), "'modules.http.server.uploads.max_memory'")
 }
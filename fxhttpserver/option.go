@@ -0,0 +1,125 @@
+package fxhttpserver
+
+// HandlerOptions are options for the registered handlers.
+type HandlerOptions struct {
+	Name                       string
+	ForceBodyLogging           bool
+	DisableBodyLogging         bool
+	ExcludedDefaultMiddlewares []DefaultMiddleware
+	Uploads                    bool
+	Streaming                  bool
+}
+
+// DefaultHandlerOptions are the default options used for the registered handlers.
+func DefaultHandlerOptions() HandlerOptions {
+	return HandlerOptions{
+		Name:                       "",
+		ForceBodyLogging:           false,
+		DisableBodyLogging:         false,
+		ExcludedDefaultMiddlewares: []DefaultMiddleware{},
+		Uploads:                    false,
+		Streaming:                  false,
+	}
+}
+
+// HandlerOption are functional options for the registered handlers.
+type HandlerOption func(o *HandlerOptions)
+
+// WithName is used to name the handler route, to generate its url with [httpserver.URL].
+func WithName(name string) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Name = name
+	}
+}
+
+// WithBodyLogging is used to force the request and response bodies logging for a handler, whatever the global configuration.
+func WithBodyLogging() HandlerOption {
+	return func(o *HandlerOptions) {
+		o.ForceBodyLogging = true
+		o.DisableBodyLogging = false
+	}
+}
+
+// WithoutBodyLogging is used to prevent the request and response bodies logging (and buffering) for a handler, whatever the global configuration.
+func WithoutBodyLogging() HandlerOption {
+	return func(o *HandlerOptions) {
+		o.ForceBodyLogging = false
+		o.DisableBodyLogging = true
+	}
+}
+
+// WithoutDefaultMiddlewares is used to exclude a handler from a list of [DefaultMiddleware] (logger, metrics, tracer).
+func WithoutDefaultMiddlewares(middlewares ...DefaultMiddleware) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.ExcludedDefaultMiddlewares = append(o.ExcludedDefaultMiddlewares, middlewares...)
+	}
+}
+
+// WithUploads is used to handle the handler multipart uploads with the configured limits, see [httpserver.CtxUploadedFiles].
+func WithUploads() HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Uploads = true
+	}
+}
+
+// WithStreaming is used to mark the handler route as streaming (server-sent events or websocket), to exclude it from the
+// request timeout and the requests duration metrics, see [httpserver.MarkStreamingRoutes].
+func WithStreaming() HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Streaming = true
+	}
+}
+
+// ExcludesDefaultMiddleware returns true if the [DefaultMiddleware] is excluded by the options.
+func (o HandlerOptions) ExcludesDefaultMiddleware(middleware DefaultMiddleware) bool {
+	for _, excluded := range o.ExcludedDefaultMiddlewares {
+		if excluded == middleware {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ApplyHandlerOptions returns the [HandlerOptions] resulting of the application of a list of [HandlerOption] on the [DefaultHandlerOptions].
+func ApplyHandlerOptions(options ...HandlerOption) HandlerOptions {
+	appliedOpts := DefaultHandlerOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	return appliedOpts
+}
+
+// MiddlewareOptions are options for the registered middlewares.
+type MiddlewareOptions struct {
+	Priority int
+}
+
+// DefaultMiddlewareOptions are the default options used for the registered middlewares.
+func DefaultMiddlewareOptions() MiddlewareOptions {
+	return MiddlewareOptions{
+		Priority: 0,
+	}
+}
+
+// MiddlewareOption are functional options for the registered middlewares.
+type MiddlewareOption func(o *MiddlewareOptions)
+
+// WithPriority is used to order the global middlewares (0 by default): the ones with higher priorities are applied
+// first, and the ones with the same priority in registration order.
+func WithPriority(priority int) MiddlewareOption {
+	return func(o *MiddlewareOptions) {
+		o.Priority = priority
+	}
+}
+
+// ApplyMiddlewareOptions returns the [MiddlewareOptions] resulting of the application of a list of [MiddlewareOption] on the [DefaultMiddlewareOptions].
+func ApplyMiddlewareOptions(options ...MiddlewareOption) MiddlewareOptions {
+	appliedOpts := DefaultMiddlewareOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	return appliedOpts
+}
@@ -0,0 +1,93 @@
+package fxhttpserver_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultHandlerOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.DefaultHandlerOptions()
+
+	assert.Equal(t, "", opts.Name)
+	assert.False(t, opts.ForceBodyLogging)
+	assert.False(t, opts.DisableBodyLogging)
+	assert.Empty(t, opts.ExcludedDefaultMiddlewares)
+	assert.False(t, opts.Uploads)
+	assert.False(t, opts.Streaming)
+}
+
+func TestWithName(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.ApplyHandlerOptions(fxhttpserver.WithName("user.show"))
+
+	assert.Equal(t, "user.show", opts.Name)
+}
+
+func TestWithBodyLogging(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.ApplyHandlerOptions(fxhttpserver.WithoutBodyLogging(), fxhttpserver.WithBodyLogging())
+
+	assert.True(t, opts.ForceBodyLogging)
+	assert.False(t, opts.DisableBodyLogging)
+}
+
+func TestWithoutBodyLogging(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.ApplyHandlerOptions(fxhttpserver.WithBodyLogging(), fxhttpserver.WithoutBodyLogging())
+
+	assert.False(t, opts.ForceBodyLogging)
+	assert.True(t, opts.DisableBodyLogging)
+}
+
+func TestWithoutDefaultMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.ApplyHandlerOptions(
+		fxhttpserver.WithoutDefaultMiddlewares(fxhttpserver.Logger),
+		fxhttpserver.WithoutDefaultMiddlewares(fxhttpserver.Metrics),
+	)
+
+	assert.Equal(t, []fxhttpserver.DefaultMiddleware{fxhttpserver.Logger, fxhttpserver.Metrics}, opts.ExcludedDefaultMiddlewares)
+	assert.True(t, opts.ExcludesDefaultMiddleware(fxhttpserver.Logger))
+	assert.True(t, opts.ExcludesDefaultMiddleware(fxhttpserver.Metrics))
+	assert.False(t, opts.ExcludesDefaultMiddleware(fxhttpserver.Tracer))
+}
+
+func TestWithUploads(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.ApplyHandlerOptions(fxhttpserver.WithUploads())
+
+	assert.True(t, opts.Uploads)
+}
+
+func TestDefaultMiddlewareOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.DefaultMiddlewareOptions()
+
+	assert.Equal(t, 0, opts.Priority)
+}
+
+func TestWithPriority(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.ApplyMiddlewareOptions(fxhttpserver.WithPriority(10))
+
+	assert.Equal(t, 10, opts.Priority)
+}
+
+func TestWithStreaming(t *testing.T) {
+	t.Parallel()
+
+	opts := fxhttpserver.ApplyHandlerOptions(fxhttpserver.WithStreaming())
+
+	assert.True(t, opts.Streaming)
+}
@@ -1,6 +1,10 @@
 package fxhttpserver
 
 import (
+	"sync/atomic"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/go-playground/validator/v10"
 	"go.uber.org/fx"
 )
 
@@ -8,13 +12,15 @@ import (
 type MiddlewareRegistration struct {
 	middleware any
 	kind       MiddlewareKind
+	options    []MiddlewareOption
 }
 
 // NewMiddlewareRegistration returns a new [MiddlewareRegistration].
-func NewMiddlewareRegistration(middleware any, kind MiddlewareKind) *MiddlewareRegistration {
+func NewMiddlewareRegistration(middleware any, kind MiddlewareKind, options ...MiddlewareOption) *MiddlewareRegistration {
 	return &MiddlewareRegistration{
 		middleware: middleware,
 		kind:       kind,
+		options:    options,
 	}
 }
 
@@ -28,15 +34,27 @@ func (m *MiddlewareRegistration) Kind() MiddlewareKind {
 	return m.kind
 }
 
+// Options returns the middleware options.
+func (m *MiddlewareRegistration) Options() []MiddlewareOption {
+	return m.options
+}
+
+var middlewaresPosition atomic.Int64
+
 // AsMiddleware registers a middleware into Fx.
-func AsMiddleware(middleware any, kind MiddlewareKind) fx.Option {
-	return RegisterMiddleware(NewMiddlewareRegistration(middleware, kind))
+// The global middlewares can be ordered with [WithPriority].
+func AsMiddleware(middleware any, kind MiddlewareKind, options ...MiddlewareOption) fx.Option {
+	return RegisterMiddleware(NewMiddlewareRegistration(middleware, kind, options...))
 }
 
 // RegisterMiddleware registers a middleware registration into Fx.
 func RegisterMiddleware(middlewareRegistration *MiddlewareRegistration) fx.Option {
 	var providers []any
 
+	// the Fx groups are not ordered, the registration position keeps the global middlewares with the same priority
+	// in registration order
+	position := middlewaresPosition.Add(1)
+
 	var middlewareDef MiddlewareDefinition
 	if !IsConcreteMiddleware(middlewareRegistration.Middleware()) {
 		providers = append(
@@ -48,9 +66,19 @@ func RegisterMiddleware(middlewareRegistration *MiddlewareRegistration) fx.Optio
 			),
 		)
 
-		middlewareDef = NewMiddlewareDefinition(GetReturnType(middlewareRegistration.Middleware()), middlewareRegistration.kind)
+		middlewareDef = NewMiddlewareDefinitionWithPosition(
+			GetReturnType(middlewareRegistration.Middleware()),
+			middlewareRegistration.kind,
+			position,
+			middlewareRegistration.options...,
+		)
 	} else {
-		middlewareDef = NewMiddlewareDefinition(middlewareRegistration.Middleware(), middlewareRegistration.kind)
+		middlewareDef = NewMiddlewareDefinitionWithPosition(
+			middlewareRegistration.Middleware(),
+			middlewareRegistration.kind,
+			position,
+			middlewareRegistration.options...,
+		)
 	}
 
 	return fx.Options(
@@ -71,15 +99,29 @@ type HandlerRegistration struct {
 	path        string
 	handler     any
 	middlewares []any
+	options     []HandlerOption
 }
 
 // NewHandlerRegistration returns a new [HandlerRegistration].
+// The provided middlewares can be mixed with [HandlerOption], to configure the handler.
 func NewHandlerRegistration(method string, path string, handler any, middlewares ...any) *HandlerRegistration {
+	var handlerMiddlewares []any
+	var handlerOptions []HandlerOption
+
+	for _, middleware := range middlewares {
+		if option, ok := middleware.(HandlerOption); ok {
+			handlerOptions = append(handlerOptions, option)
+		} else {
+			handlerMiddlewares = append(handlerMiddlewares, middleware)
+		}
+	}
+
 	return &HandlerRegistration{
 		method:      method,
 		path:        path,
 		handler:     handler,
-		middlewares: middlewares,
+		middlewares: handlerMiddlewares,
+		options:     handlerOptions,
 	}
 }
 
@@ -103,7 +145,13 @@ func (h *HandlerRegistration) Middlewares() []any {
 	return h.middlewares
 }
 
+// Options returns the handler associated options.
+func (h *HandlerRegistration) Options() []HandlerOption {
+	return h.options
+}
+
 // AsHandler registers a handler into Fx.
+// The provided middlewares can be mixed with [HandlerOption], to configure the handler.
 func AsHandler(method string, path string, handler any, middlewares ...any) fx.Option {
 	return RegisterHandler(NewHandlerRegistration(method, path, handler, middlewares...))
 }
@@ -145,6 +193,7 @@ func RegisterHandler(handlerRegistration *HandlerRegistration) fx.Option {
 			handlerRegistration.Path(),
 			GetReturnType(handlerRegistration.Handler()),
 			middlewareDefs,
+			handlerRegistration.Options()...,
 		)
 	} else {
 		handlerDef = NewHandlerDefinition(
@@ -152,6 +201,7 @@ func RegisterHandler(handlerRegistration *HandlerRegistration) fx.Option {
 			handlerRegistration.Path(),
 			handlerRegistration.Handler(),
 			middlewareDefs,
+			handlerRegistration.Options()...,
 		)
 	}
 
@@ -261,6 +311,7 @@ func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration)
 				handlerRegistration.Path(),
 				GetReturnType(handlerRegistration.Handler()),
 				middlewareDefs,
+				handlerRegistration.Options()...,
 			)
 		} else {
 			handlerDef = NewHandlerDefinition(
@@ -268,6 +319,7 @@ func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration)
 				handlerRegistration.Path(),
 				handlerRegistration.Handler(),
 				middlewareDefs,
+				handlerRegistration.Options()...,
 			)
 		}
 
@@ -291,3 +343,27 @@ func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration)
 		),
 	)
 }
+
+// AsValidation registers a custom validation for a tag into Fx, used when the validation is enabled.
+func AsValidation(tag string, fn validator.Func) fx.Option {
+	return fx.Supply(
+		fx.Annotate(
+			httpserver.NewValidation(tag, fn),
+			fx.ResultTags(`group:"httpserver-validations"`),
+		),
+	)
+}
+
+var errorMappersPosition atomic.Int64
+
+// AsHttpServerErrorMapper registers an error mapper into Fx, consulted by the http server error handler before
+// obfuscation: the first mapper (in registration order) handling an error provides the response status and message.
+func AsHttpServerErrorMapper(mapper httpserver.ErrorMapper) fx.Option {
+	return fx.Supply(
+		fx.Annotate(
+			NewErrorMapperDefinition(mapper, errorMappersPosition.Add(1)),
+			fx.As(new(ErrorMapperDefinition)),
+			fx.ResultTags(`group:"httpserver-error-mappers"`),
+		),
+	)
+}
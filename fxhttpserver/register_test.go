@@ -35,6 +35,7 @@ func TestMiddlewareRegistration(t *testing.T) {
 
 			assert.Equal(t, tt.middleware, mr.Middleware())
 			assert.Equal(t, tt.kind, mr.Kind())
+			assert.Empty(t, mr.Options())
 		})
 	}
 }
@@ -80,6 +81,26 @@ func TestHandlerRegistration(t *testing.T) {
 	}
 }
 
+func TestHandlerRegistrationWithOptions(t *testing.T) {
+	t.Parallel()
+
+	type exampleHandler struct {
+		name string
+	}
+	type exampleMiddleware struct {
+		name string
+	}
+
+	handler := exampleHandler{name: "handler-test"}
+	mw := exampleMiddleware{name: "middleware"}
+
+	hr := fxhttpserver.NewHandlerRegistration("GET", "/path", handler, mw, fxhttpserver.WithoutBodyLogging())
+
+	assert.Equal(t, []any{mw}, hr.Middlewares())
+	assert.Len(t, hr.Options(), 1)
+	assert.True(t, fxhttpserver.ApplyHandlerOptions(hr.Options()...).DisableBodyLogging)
+}
+
 func TestHandlersGroupRegistration(t *testing.T) {
 	t.Parallel()
 
@@ -1,8 +1,11 @@
 package fxhttpserver
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 
+	"github.com/ankorstore/yokai/httpserver"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/fx"
 )
@@ -24,6 +27,7 @@ type HttpServerRegistry struct {
 	handlers                 []Handler
 	handlerDefinitions       []HandlerDefinition
 	handlersGroupDefinitions []HandlersGroupDefinition
+	dynamicRouter            *httpserver.DynamicRouter
 }
 
 // FxHttpServerRegistryParam allows injection of the required dependencies in [NewFxHttpServerRegistry].
@@ -47,11 +51,19 @@ func NewFxHttpServerRegistry(p FxHttpServerRegistryParam) *HttpServerRegistry {
 	}
 }
 
-// ResolveMiddlewares resolves a list of [ResolvedMiddleware] from their definitions.
+// ResolveMiddlewares resolves a list of [ResolvedMiddleware] from their definitions, sorted by descending priority
+// (the ones with the same priority being kept in registration position order).
 func (r *HttpServerRegistry) ResolveMiddlewares() ([]ResolvedMiddleware, error) {
 	var resolvedMiddlewares []ResolvedMiddleware
 
-	for _, middlewareDef := range r.middlewareDefinitions {
+	middlewareDefs := make([]MiddlewareDefinition, len(r.middlewareDefinitions))
+	copy(middlewareDefs, r.middlewareDefinitions)
+
+	sort.SliceStable(middlewareDefs, func(i, j int) bool {
+		return middlewareDefs[i].Position() < middlewareDefs[j].Position()
+	})
+
+	for _, middlewareDef := range middlewareDefs {
 		if middlewareDef.Kind() != Attached {
 			resMiddleware, err := r.resolveMiddlewareDefinition(middlewareDef)
 			if err != nil {
@@ -62,6 +74,10 @@ func (r *HttpServerRegistry) ResolveMiddlewares() ([]ResolvedMiddleware, error)
 		}
 	}
 
+	sort.SliceStable(resolvedMiddlewares, func(i, j int) bool {
+		return resolvedMiddlewares[i].Priority() > resolvedMiddlewares[j].Priority()
+	})
+
 	return resolvedMiddlewares, nil
 }
 
@@ -143,12 +159,64 @@ func (r *HttpServerRegistry) ResolveHandlersGroups() ([]ResolvedHandlersGroup, e
 	return resolvedHandlersGroups, nil
 }
 
+// HandlersOptions returns the registered handlers (including the ones in handlers groups) [HandlerOptions],
+// indexed by their route key (see [httpserver.RouteKey]).
+func (r *HttpServerRegistry) HandlersOptions() map[string]HandlerOptions {
+	handlersOptions := map[string]HandlerOptions{}
+
+	for _, handlerDef := range r.handlerDefinitions {
+		handlersOptions[httpserver.RouteKey(handlerDef.Method(), handlerDef.Path())] = handlerDef.Options()
+	}
+
+	for _, handlerGroupDef := range r.handlersGroupDefinitions {
+		for _, handlerDef := range handlerGroupDef.Handlers() {
+			handlersOptions[httpserver.RouteKey(handlerDef.Method(), handlerGroupDef.Prefix()+handlerDef.Path())] = handlerDef.Options()
+		}
+	}
+
+	return handlersOptions
+}
+
+// AddHandler registers at runtime a handler on the http server, while it is serving.
+// It returns an error if a handler is already registered for the same method and path.
+// See [httpserver.DynamicRouter] for the thread-safety guarantees.
+func (r *HttpServerRegistry) AddHandler(method string, path string, handler echo.HandlerFunc, middlewares ...echo.MiddlewareFunc) error {
+	if r.dynamicRouter == nil {
+		return errors.New("cannot add handler: http server is not created")
+	}
+
+	_, err := r.dynamicRouter.Add(method, path, handler, middlewares...)
+
+	return err
+}
+
+// AddHandlersGroup registers at runtime a group of handlers on the http server, while it is serving.
+// It returns an error, without registering any of the group handlers, if one of them is already registered.
+// See [httpserver.DynamicRouter] for the thread-safety guarantees.
+func (r *HttpServerRegistry) AddHandlersGroup(prefix string, routes []*httpserver.DynamicRoute, middlewares ...echo.MiddlewareFunc) error {
+	if r.dynamicRouter == nil {
+		return errors.New("cannot add handlers group: http server is not created")
+	}
+
+	_, err := r.dynamicRouter.AddGroup(prefix, routes, middlewares...)
+
+	return err
+}
+
 func (r *HttpServerRegistry) resolveMiddlewareDefinition(middlewareDefinition MiddlewareDefinition) (ResolvedMiddleware, error) {
 	if middlewareDefinition.Concrete() {
 		if castMiddleware, ok := middlewareDefinition.Middleware().(func(echo.HandlerFunc) echo.HandlerFunc); ok {
-			return NewResolvedMiddleware(castMiddleware, middlewareDefinition.Kind()), nil
+			return NewResolvedMiddlewareWithPriority(
+				castMiddleware,
+				middlewareDefinition.Kind(),
+				middlewareDefinition.Options().Priority,
+			), nil
 		} else if castMiddleware, ok = middlewareDefinition.Middleware().(echo.MiddlewareFunc); ok {
-			return NewResolvedMiddleware(castMiddleware, middlewareDefinition.Kind()), nil
+			return NewResolvedMiddlewareWithPriority(
+				castMiddleware,
+				middlewareDefinition.Kind(),
+				middlewareDefinition.Options().Priority,
+			), nil
 		} else {
 			return nil, fmt.Errorf("cannot cast middleware definition as MiddlewareFunc")
 		}
@@ -159,26 +227,29 @@ func (r *HttpServerRegistry) resolveMiddlewareDefinition(middlewareDefinition Mi
 		return nil, fmt.Errorf("cannot lookup registered middleware")
 	}
 
-	return NewResolvedMiddleware(
+	return NewResolvedMiddlewareWithPriority(
 		registeredMiddleware.Handle(),
 		middlewareDefinition.Kind(),
+		middlewareDefinition.Options().Priority,
 	), nil
 }
 
 func (r *HttpServerRegistry) resolveHandlerDefinition(handlerDefinition HandlerDefinition, handlerMiddlewares []echo.MiddlewareFunc) (ResolvedHandler, error) {
 	if handlerDefinition.Concrete() {
 		if castHandler, ok := handlerDefinition.Handler().(func(echo.Context) error); ok {
-			return NewResolvedHandler(
+			return NewResolvedHandlerWithOptions(
 				handlerDefinition.Method(),
 				handlerDefinition.Path(),
 				castHandler,
+				handlerDefinition.Options(),
 				handlerMiddlewares...,
 			), nil
 		} else if castHandler, ok = handlerDefinition.Handler().(echo.HandlerFunc); ok {
-			return NewResolvedHandler(
+			return NewResolvedHandlerWithOptions(
 				handlerDefinition.Method(),
 				handlerDefinition.Path(),
 				castHandler,
+				handlerDefinition.Options(),
 				handlerMiddlewares...,
 			), nil
 		} else {
@@ -191,10 +262,11 @@ func (r *HttpServerRegistry) resolveHandlerDefinition(handlerDefinition HandlerD
 		return nil, fmt.Errorf("cannot lookup registered handler")
 	}
 
-	return NewResolvedHandler(
+	return NewResolvedHandlerWithOptions(
 		handlerDefinition.Method(),
 		handlerDefinition.Path(),
 		registeredHandler.Handle(),
+		handlerDefinition.Options(),
 		handlerMiddlewares...,
 	), nil
 }
@@ -1,6 +1,8 @@
 package fxhttpserver_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ankorstore/yokai/fxhttpserver"
@@ -40,6 +42,20 @@ func (m *testMiddlewareDefinitionMock) Kind() fxhttpserver.MiddlewareKind {
 	return args.Get(0).(fxhttpserver.MiddlewareKind)
 }
 
+func (m *testMiddlewareDefinitionMock) Options() fxhttpserver.MiddlewareOptions {
+	args := m.Called()
+
+	//nolint:forcetypeassert
+	return args.Get(0).(fxhttpserver.MiddlewareOptions)
+}
+
+func (m *testMiddlewareDefinitionMock) Position() int64 {
+	args := m.Called()
+
+	//nolint:forcetypeassert
+	return args.Get(0).(int64)
+}
+
 type testHandlerDefinitionMock struct {
 	mock.Mock
 }
@@ -75,6 +91,10 @@ func (m *testHandlerDefinitionMock) Middlewares() []fxhttpserver.MiddlewareDefin
 	return args.Get(0).([]fxhttpserver.MiddlewareDefinition)
 }
 
+func (m *testHandlerDefinitionMock) Options() fxhttpserver.HandlerOptions {
+	return fxhttpserver.DefaultHandlerOptions()
+}
+
 type testMiddlewareImplementation struct{}
 
 func (m testMiddlewareImplementation) Handle() echo.MiddlewareFunc {
@@ -99,6 +119,22 @@ func TestNewFxHttpServerRegistry(t *testing.T) {
 	assert.IsType(t, &fxhttpserver.HttpServerRegistry{}, registry)
 }
 
+func TestAddHandlerFailureOnNotCreatedHttpServer(t *testing.T) {
+	t.Parallel()
+
+	registry := fxhttpserver.NewFxHttpServerRegistry(fxhttpserver.FxHttpServerRegistryParam{})
+
+	err := registry.AddHandler("GET", "/dynamic", func(c echo.Context) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "cannot add handler: http server is not created", err.Error())
+
+	err = registry.AddHandlersGroup("/dynamic", nil)
+	assert.Error(t, err)
+	assert.Equal(t, "cannot add handlers group: http server is not created", err.Error())
+}
+
 func TestResolveMiddlewaresSuccessWithAnonFuncType(t *testing.T) {
 	t.Parallel()
 
@@ -120,6 +156,96 @@ func TestResolveMiddlewaresSuccessWithAnonFuncType(t *testing.T) {
 	assert.Equal(t, testMiddleware(testHandler)(nil), resolvedMiddlewares[0].Middleware()(testHandler)(nil))
 }
 
+func TestResolveMiddlewaresSortedByPriority(t *testing.T) {
+	t.Parallel()
+
+	namedMiddleware := func(name string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Response().Header().Add("x-order", name)
+
+				return next(c)
+			}
+		}
+	}
+
+	param := fxhttpserver.FxHttpServerRegistryParam{
+		MiddlewareDefinitions: []fxhttpserver.MiddlewareDefinition{
+			fxhttpserver.NewMiddlewareDefinition(namedMiddleware("a"), fxhttpserver.GlobalUse),
+			fxhttpserver.NewMiddlewareDefinition(namedMiddleware("b"), fxhttpserver.GlobalUse, fxhttpserver.WithPriority(5)),
+			fxhttpserver.NewMiddlewareDefinition(namedMiddleware("attached"), fxhttpserver.Attached, fxhttpserver.WithPriority(100)),
+			fxhttpserver.NewMiddlewareDefinition(namedMiddleware("c"), fxhttpserver.GlobalPre),
+			fxhttpserver.NewMiddlewareDefinition(namedMiddleware("d"), fxhttpserver.GlobalUse, fxhttpserver.WithPriority(-5)),
+			fxhttpserver.NewMiddlewareDefinition(namedMiddleware("e"), fxhttpserver.GlobalUse, fxhttpserver.WithPriority(5)),
+		},
+	}
+	registry := fxhttpserver.NewFxHttpServerRegistry(param)
+
+	resolvedMiddlewares, err := registry.ResolveMiddlewares()
+	assert.NoError(t, err)
+
+	var priorities []int
+	var order []string
+
+	for _, m := range resolvedMiddlewares {
+		priorities = append(priorities, m.Priority())
+
+		rec := httptest.NewRecorder()
+		err = m.Middleware()(testHandler)(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec))
+		assert.NoError(t, err)
+
+		order = append(order, rec.Header().Get("x-order"))
+	}
+
+	// ties are kept in registration order
+	assert.Equal(t, []int{5, 5, 0, 0, -5}, priorities)
+	assert.Equal(t, []string{"b", "e", "a", "c", "d"}, order)
+}
+
+func TestResolveMiddlewaresInRegistrationPosition(t *testing.T) {
+	t.Parallel()
+
+	testHandler := func(c echo.Context) error {
+		return nil
+	}
+
+	namedMiddleware := func(name string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Response().Header().Add("x-order", name)
+
+				return next(c)
+			}
+		}
+	}
+
+	// provided in a different order than registered, like with Fx groups
+	param := fxhttpserver.FxHttpServerRegistryParam{
+		MiddlewareDefinitions: []fxhttpserver.MiddlewareDefinition{
+			fxhttpserver.NewMiddlewareDefinitionWithPosition(namedMiddleware("c"), fxhttpserver.GlobalUse, 3),
+			fxhttpserver.NewMiddlewareDefinitionWithPosition(namedMiddleware("a"), fxhttpserver.GlobalUse, 1),
+			fxhttpserver.NewMiddlewareDefinitionWithPosition(namedMiddleware("d"), fxhttpserver.GlobalUse, 4, fxhttpserver.WithPriority(5)),
+			fxhttpserver.NewMiddlewareDefinitionWithPosition(namedMiddleware("b"), fxhttpserver.GlobalUse, 2),
+		},
+	}
+	registry := fxhttpserver.NewFxHttpServerRegistry(param)
+
+	resolvedMiddlewares, err := registry.ResolveMiddlewares()
+	assert.NoError(t, err)
+
+	var order []string
+
+	for _, m := range resolvedMiddlewares {
+		rec := httptest.NewRecorder()
+		err = m.Middleware()(testHandler)(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec))
+		assert.NoError(t, err)
+
+		order = append(order, rec.Header().Get("x-order"))
+	}
+
+	assert.Equal(t, []string{"d", "a", "b", "c"}, order)
+}
+
 func TestResolveMiddlewaresFailureOnInvalidImplementation(t *testing.T) {
 	t.Parallel()
 
@@ -468,3 +594,31 @@ func TestResolveHandlersGroupFailureOnInvalidHandlerMiddlewareImplementation(t *
 	assert.Error(t, err)
 	assert.Equal(t, "cannot cast middleware definition as MiddlewareFunc", err.Error())
 }
+
+func TestHandlersOptions(t *testing.T) {
+	t.Parallel()
+
+	param := fxhttpserver.FxHttpServerRegistryParam{
+		HandlerDefinitions: []fxhttpserver.HandlerDefinition{
+			fxhttpserver.NewHandlerDefinition("get", "/foo", testHandler, nil, fxhttpserver.WithBodyLogging()),
+		},
+		HandlersGroupDefinitions: []fxhttpserver.HandlersGroupDefinition{
+			fxhttpserver.NewHandlersGroupDefinition(
+				"/group",
+				[]fxhttpserver.HandlerDefinition{
+					fxhttpserver.NewHandlerDefinition("POST", "/bar", testHandler, nil, fxhttpserver.WithoutBodyLogging()),
+					fxhttpserver.NewHandlerDefinition("GET", "/baz", testHandler, nil),
+				},
+				nil,
+			),
+		},
+	}
+	registry := fxhttpserver.NewFxHttpServerRegistry(param)
+
+	handlersOptions := registry.HandlersOptions()
+
+	assert.Len(t, handlersOptions, 3)
+	assert.True(t, handlersOptions["GET /foo"].ForceBodyLogging)
+	assert.True(t, handlersOptions["POST /group/bar"].DisableBodyLogging)
+	assert.Equal(t, fxhttpserver.DefaultHandlerOptions(), handlersOptions["GET /group/baz"])
+}
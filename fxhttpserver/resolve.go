@@ -6,18 +6,26 @@ import "github.com/labstack/echo/v4"
 type ResolvedMiddleware interface {
 	Middleware() echo.MiddlewareFunc
 	Kind() MiddlewareKind
+	Priority() int
 }
 
 type resolvedMiddleware struct {
 	middleware echo.MiddlewareFunc
 	kind       MiddlewareKind
+	priority   int
 }
 
 // NewResolvedMiddleware returns a new [ResolvedMiddleware].
 func NewResolvedMiddleware(middleware echo.MiddlewareFunc, kind MiddlewareKind) ResolvedMiddleware {
+	return NewResolvedMiddlewareWithPriority(middleware, kind, 0)
+}
+
+// NewResolvedMiddlewareWithPriority returns a new [ResolvedMiddleware], with a priority.
+func NewResolvedMiddlewareWithPriority(middleware echo.MiddlewareFunc, kind MiddlewareKind, priority int) ResolvedMiddleware {
 	return &resolvedMiddleware{
 		middleware: middleware,
 		kind:       kind,
+		priority:   priority,
 	}
 }
 
@@ -31,12 +39,18 @@ func (r *resolvedMiddleware) Kind() MiddlewareKind {
 	return r.kind
 }
 
+// Priority return the resolved middleware priority.
+func (r *resolvedMiddleware) Priority() int {
+	return r.priority
+}
+
 // ResolvedHandler is an interface for the resolved handlers.
 type ResolvedHandler interface {
 	Method() string
 	Path() string
 	Handler() echo.HandlerFunc
 	Middlewares() []echo.MiddlewareFunc
+	Options() HandlerOptions
 }
 
 type resolvedHandler struct {
@@ -44,15 +58,22 @@ type resolvedHandler struct {
 	path        string
 	handler     echo.HandlerFunc
 	middlewares []echo.MiddlewareFunc
+	options     HandlerOptions
 }
 
-// NewResolvedHandler returns a new [ResolvedHandler].
+// NewResolvedHandler returns a new [ResolvedHandler], with [DefaultHandlerOptions].
 func NewResolvedHandler(method string, path string, handler echo.HandlerFunc, middlewares ...echo.MiddlewareFunc) ResolvedHandler {
+	return NewResolvedHandlerWithOptions(method, path, handler, DefaultHandlerOptions(), middlewares...)
+}
+
+// NewResolvedHandlerWithOptions returns a new [ResolvedHandler], with provided [HandlerOptions].
+func NewResolvedHandlerWithOptions(method string, path string, handler echo.HandlerFunc, options HandlerOptions, middlewares ...echo.MiddlewareFunc) ResolvedHandler {
 	return &resolvedHandler{
 		method:      method,
 		path:        path,
 		handler:     handler,
 		middlewares: middlewares,
+		options:     options,
 	}
 }
 
@@ -76,6 +97,11 @@ func (r *resolvedHandler) Middlewares() []echo.MiddlewareFunc {
 	return r.middlewares
 }
 
+// Options return the resolved handler options.
+func (r *resolvedHandler) Options() HandlerOptions {
+	return r.options
+}
+
 // ResolvedHandlersGroup is an interface for the resolved handlers groups.
 type ResolvedHandlersGroup interface {
 	Prefix() string
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+)
+
+type TestEventsHandler struct{}
+
+func NewTestEventsHandler() *TestEventsHandler {
+	return &TestEventsHandler{}
+}
+
+func (h *TestEventsHandler) Handle() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		stream, err := httpserver.NewSSEStream(c)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+
+		for i := 1; i <= 3; i++ {
+			select {
+			case <-stream.Done():
+				return nil
+			case <-ticker.C:
+				if err = stream.Send("tick", fmt.Sprintf("%d", i)); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+}
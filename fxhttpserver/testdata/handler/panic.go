@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+type TestPanicHandler struct{}
+
+func NewTestPanicHandler() *TestPanicHandler {
+	return &TestPanicHandler{}
+}
+
+func (h *TestPanicHandler) Handle() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		panic("test panic")
+	}
+}
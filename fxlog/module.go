@@ -1,13 +1,21 @@
 package fxlog
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
+	"syscall"
 
 	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/rs/zerolog"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/fx"
 )
 
@@ -22,19 +30,43 @@ var FxLogModule = fx.Module(
 	fx.Provide(
 		log.NewDefaultLoggerFactory,
 		logtest.NewDefaultTestLogBuffer,
+		logtest.NewDefaultTestOtlpExporter,
 		NewFxLogger,
+		NewFxAuditLogger,
 	),
 )
 
 // FxLogParam allows injection of the required dependencies in [NewFxLogger].
 type FxLogParam struct {
 	fx.In
-	Factory log.LoggerFactory
-	Buffer  logtest.TestLogBuffer
-	Config  *config.Config
+	LifeCycle    fx.Lifecycle
+	Factory      log.LoggerFactory
+	Buffer       logtest.TestLogBuffer
+	OtlpExporter logtest.TestOtlpExporter
+	Resource     *resource.Resource `optional:"true"`
+	Config       *config.Config
 }
 
 // NewFxLogger returns a [log.Logger].
+//
+// If modules.log.output is file (or multi, to also log to stdout), the log records are appended to the
+// modules.log.file.path file, rotated when exceeding modules.log.file.max_size megabytes, and reopened on SIGHUP.
+//
+// The values of the modules.log.redact.fields log fields are redacted (see [log.RedactingWriter]), including in the
+// nested JSON payloads if modules.log.redact.deep is true.
+//
+// The log records are formatted as modules.log.format (console by default in dev env, json otherwise), except in test
+// env where they are kept as JSON. Their timestamp is added in the modules.log.time_field field, with the
+// modules.log.time_format format.
+//
+// If modules.log.sampling.enabled is true, the log records are sampled per level (see [log.Sampler]).
+//
+// If modules.log.otlp.enabled is true, the log records are also exported with OTLP (see [log.OtlpWriter]), to the
+// modules.log.otlp.endpoint gRPC endpoint (or in the test exporter in test env), with the resource shared with the
+// tracer provider if provided (like by the fxtrace module).
+//
+// The config files reload failures (see modules.config.watch.enabled) are logged with this logger, and its level is
+// changed at runtime when the modules.log.level (or app.debug) config values change on reload (see [reloadLevel]).
 func NewFxLogger(p FxLogParam) (*log.Logger, error) {
 	var level zerolog.Level
 	if p.Config.AppDebug() {
@@ -43,25 +75,425 @@ func NewFxLogger(p FxLogParam) (*log.Logger, error) {
 		level = log.FetchLogLevel(p.Config.GetString("modules.log.level"))
 	}
 
+	format := configuredFormat(p.Config)
+
 	var outputWriter io.Writer
 	if p.Config.IsTestEnv() {
+		// the test log buffer records are kept as JSON, for the assertions
 		outputWriter = p.Buffer
+		format = log.JsonFormat
 	} else {
 		switch log.FetchLogOutputWriter(p.Config.GetString("modules.log.output")) {
 		case log.NoopOutputWriter:
 			outputWriter = io.Discard
 		case log.TestOutputWriter:
 			outputWriter = p.Buffer
+			format = log.JsonFormat
 		case log.ConsoleOutputWriter:
-			outputWriter = zerolog.ConsoleWriter{Out: os.Stderr}
+			outputWriter = os.Stderr
+			format = log.ConsoleFormat
+		case log.StderrOutputWriter:
+			outputWriter = os.Stderr
+		case log.FileOutputWriter:
+			fileWriter, err := configuredFileWriter(p.Config, p.LifeCycle)
+			if err != nil {
+				return nil, err
+			}
+
+			outputWriter = fileWriter
+		case log.MultiOutputWriter:
+			fileWriter, err := configuredFileWriter(p.Config, p.LifeCycle)
+			if err != nil {
+				return nil, err
+			}
+
+			outputWriter = zerolog.MultiLevelWriter(os.Stdout, fileWriter)
+		case log.OtlpOutputWriter:
+			// the log records are only exported with OTLP
+			outputWriter = io.Discard
 		default:
 			outputWriter = os.Stdout
 		}
 	}
 
-	return p.Factory.Create(
+	options := []log.LoggerOption{
 		log.WithServiceName(p.Config.AppName()),
+		log.WithServiceVersion(p.Config.AppVersion()),
 		log.WithLevel(level),
 		log.WithOutputWriter(outputWriter),
-	)
+		log.WithFormat(format),
+	}
+
+	options = append(options, configuredTimeOptions(p.Config)...)
+
+	if redaction := configuredRedaction(p.Config); len(redaction.Fields) > 0 {
+		options = append(options, log.WithRedaction(redaction))
+	}
+
+	if p.Config.GetBool("modules.log.sampling.enabled") {
+		sampler, err := configuredSampler(p.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, log.WithSampler(sampler))
+	}
+
+	if p.Config.GetBool("modules.log.stack.enabled") {
+		options = append(options, log.WithErrorStack(true))
+	}
+
+	var otlpWriter *log.OtlpWriter
+	if p.Config.GetBool("modules.log.otlp.enabled") || (!p.Config.IsTestEnv() && isOtlpOutput(p.Config)) {
+		var err error
+
+		otlpWriter, err = configuredOtlpWriter(p.Config, p.OtlpExporter, p.Resource)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, log.WithOtlpWriter(otlpWriter))
+	}
+
+	logger, err := p.Factory.Create(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if otlpWriter != nil {
+		shutdownOtlpWriterOnStop(p.LifeCycle, otlpWriter, logger)
+	}
+
+	logger.Debug().Str("format", format.String()).Msg("logger format")
+
+	p.Config.OnReloadError(func(err error) {
+		logger.Error().Err(err).Msg("config reload failed, keeping the previous config")
+	})
+
+	p.Config.OnChange(func(keys []string) {
+		reloadLevel(p.Config, logger, keys)
+	})
+
+	return logger, nil
+}
+
+// reloadLevel changes the level of the logger if the modules.log.level (or app.debug) config values changed, observed
+// immediately by all the loggers derived from it (with With, [log.FromZerolog] or [log.CtxLogger]). The invalid levels
+// are logged at error level, the previous level being kept, and the level transitions at info level.
+func reloadLevel(cfg *config.Config, logger *log.Logger, keys []string) {
+	if !slices.Contains(keys, "modules.log.level") && !slices.Contains(keys, "app.debug") {
+		return
+	}
+
+	var level zerolog.Level
+	if cfg.AppDebug() {
+		level = zerolog.DebugLevel
+	} else {
+		name := cfg.GetString("modules.log.level")
+		if !isValidLevel(name) {
+			logger.Error().Str("value", name).Msg("invalid log level in config, keeping the previous level")
+
+			return
+		}
+
+		level = log.FetchLogLevel(name)
+	}
+
+	previous := logger.GetLevel()
+	if level == previous {
+		return
+	}
+
+	logTransition := func() {
+		logger.Info().Str("from", previous.String()).Str("to", level.String()).Msg("log level changed")
+	}
+
+	// the transition is logged with the most verbose of both levels
+	if level < previous {
+		logger.SetLevel(level)
+		logTransition()
+	} else {
+		logTransition()
+		logger.SetLevel(level)
+	}
+}
+
+// validLevels are the known modules.log.level config values, see [log.FetchLogLevel].
+var validLevels = []string{"trace", "debug", "info", "warn", "warning", "error", "fatal", "panic", "no-level", "disabled"}
+
+// isValidLevel returns true if a modules.log.level config value is a known log level (case-insensitive), or empty for
+// the default one.
+func isValidLevel(name string) bool {
+	return name == "" || slices.Contains(validLevels, strings.ToLower(name))
+}
+
+// configuredFormat returns the [log.LogFormat] configured in the modules.log.format config key, console by default in
+// dev env and json otherwise.
+func configuredFormat(cfg *config.Config) log.LogFormat {
+	if format := cfg.GetString("modules.log.format"); format != "" {
+		return log.FetchLogFormat(format)
+	}
+
+	if cfg.IsDevEnv() {
+		return log.ConsoleFormat
+	}
+
+	return log.JsonFormat
+}
+
+// configuredTimeOptions returns the [log.LoggerOption] of the log records timestamp, configured in the
+// modules.log.time_format and modules.log.time_field config keys.
+func configuredTimeOptions(cfg *config.Config) []log.LoggerOption {
+	var options []log.LoggerOption
+
+	if timeFormat := cfg.GetString("modules.log.time_format"); timeFormat != "" {
+		options = append(options, log.WithTimeFormat(log.FetchTimeFormat(timeFormat)))
+	}
+
+	if timeField := cfg.GetString("modules.log.time_field"); timeField != "" {
+		options = append(options, log.WithTimeField(timeField))
+	}
+
+	return options
+}
+
+// configuredFileWriter returns the [log.FileWriter] configured in the modules.log.file config keys, reopened on SIGHUP
+// (for logrotate compatibility) and closed on the application stop.
+func configuredFileWriter(cfg *config.Config, lc fx.Lifecycle) (*log.FileWriter, error) {
+	path := cfg.GetString("modules.log.file.path")
+	if path == "" {
+		return nil, fmt.Errorf("missing log file path, expected in modules.log.file.path config")
+	}
+
+	fileWriter := log.NewFileWriter(log.FileWriterConfig{
+		Path:       path,
+		MaxSize:    cfg.GetInt("modules.log.file.max_size"),
+		MaxBackups: cfg.GetInt("modules.log.file.max_backups"),
+		MaxAge:     cfg.GetInt("modules.log.file.max_age"),
+		Compress:   cfg.GetBool("modules.log.file.compress"),
+	})
+
+	stopReopen := fileWriter.ReopenOnSignal(syscall.SIGHUP)
+
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			stopReopen()
+
+			return fileWriter.Close()
+		},
+	})
+
+	return fileWriter, nil
+}
+
+// isOtlpOutput returns true if the modules.log.output config key is otlp, to only export the log records with OTLP.
+func isOtlpOutput(cfg *config.Config) bool {
+	return log.FetchLogOutputWriter(cfg.GetString("modules.log.output")) == log.OtlpOutputWriter
+}
+
+// configuredOtlpWriter returns the [log.OtlpWriter] configured in the modules.log.otlp config keys, exporting the log
+// records in batches to the modules.log.otlp.endpoint gRPC endpoint, or to the test exporter in test env. The log
+// records resource is the provided one if any (like the tracer provider one), or else described with the app.name and
+// app.version config keys.
+func configuredOtlpWriter(
+	cfg *config.Config,
+	testExporter logtest.TestOtlpExporter,
+	res *resource.Resource,
+) (*log.OtlpWriter, error) {
+	timeout, err := cfg.GetDuration("modules.log.otlp.timeout")
+	if err != nil {
+		return nil, fmt.Errorf("could not parse log otlp timeout: %w", err)
+	}
+
+	batchInterval, err := cfg.GetDuration("modules.log.otlp.batch.interval")
+	if err != nil {
+		return nil, fmt.Errorf("could not parse log otlp batch interval: %w", err)
+	}
+
+	batchTimeout, err := cfg.GetDuration("modules.log.otlp.batch.timeout")
+	if err != nil {
+		return nil, fmt.Errorf("could not parse log otlp batch timeout: %w", err)
+	}
+
+	var exporter sdklog.Exporter
+	if cfg.IsTestEnv() {
+		exporter = testExporter
+	} else {
+		exporter, err = log.NewOtlpGrpcExporter(context.Background(), log.OtlpConfig{
+			Endpoint: cfg.GetString("modules.log.otlp.endpoint"),
+			Headers:  cfg.GetStringMapString("modules.log.otlp.headers"),
+			Insecure: cfg.GetBool("modules.log.otlp.insecure"),
+			Timeout:  timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not create log otlp exporter: %w", err)
+		}
+	}
+
+	if res == nil {
+		res = resource.NewSchemaless(
+			semconv.ServiceNameKey.String(cfg.AppName()),
+			semconv.ServiceVersionKey.String(cfg.AppVersion()),
+		)
+	}
+
+	return log.NewOtlpWriter(log.OtlpWriterConfig{
+		Exporter: exporter,
+		Resource: res,
+		Batch: log.OtlpBatchConfig{
+			MaxQueueSize:  cfg.GetInt("modules.log.otlp.batch.max_queue_size"),
+			MaxExportSize: cfg.GetInt("modules.log.otlp.batch.max_export_size"),
+			Interval:      batchInterval,
+			Timeout:       batchTimeout,
+		},
+	}), nil
+}
+
+// shutdownOtlpWriterOnStop shuts down a [log.OtlpWriter] on the application stop, exporting its queued log records,
+// and logs the number of log records it dropped, if any.
+func shutdownOtlpWriterOnStop(lc fx.Lifecycle, otlpWriter *log.OtlpWriter, logger *log.Logger) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			// the export failures never fail the application stop
+			if err := otlpWriter.Shutdown(ctx); err != nil {
+				logger.Warn().Err(err).Msg("log records otlp export shutdown failed")
+			}
+
+			if dropped := otlpWriter.Dropped(); dropped > 0 {
+				logger.Warn().Uint64("dropped", dropped).Msg("log records dropped by the otlp export")
+			}
+
+			return nil
+		},
+	})
+}
+
+// configuredRedaction returns the [log.RedactionConfig] configured in the modules.log.redact config keys.
+func configuredRedaction(cfg *config.Config) log.RedactionConfig {
+	return log.RedactionConfig{
+		Fields: cfg.GetStringSlice("modules.log.redact.fields"),
+		Deep:   cfg.GetBool("modules.log.redact.deep"),
+	}
+}
+
+// configuredSampler returns the [log.Sampler] configured in the modules.log.sampling config keys: the first burst
+// log records of each period are logged per level (for example modules.log.sampling.levels.info.burst and
+// modules.log.sampling.levels.info.period), the error (and above) ones being never sampled unless
+// modules.log.sampling.sample_errors is true.
+func configuredSampler(cfg *config.Config) (*log.Sampler, error) {
+	levels := map[zerolog.Level]zerolog.Sampler{}
+
+	for name := range cfg.GetStringMap("modules.log.sampling.levels") {
+		key := fmt.Sprintf("modules.log.sampling.levels.%s", name)
+
+		period, err := cfg.GetDuration(key + ".period")
+		if err != nil {
+			return nil, fmt.Errorf("could not parse log sampling period of level %s: %w", name, err)
+		}
+
+		levels[log.FetchLogLevel(name)] = log.NewBurstSampler(cfg.GetUint32(key+".burst"), period)
+	}
+
+	return log.NewSamplerWithConfig(log.SamplerConfig{
+		Levels:       levels,
+		SampleErrors: cfg.GetBool("modules.log.sampling.sample_errors"),
+	}), nil
+}
+
+// FxAuditLogParam allows injection of the required dependencies in [NewFxAuditLogger].
+type FxAuditLogParam struct {
+	fx.In
+	LifeCycle    fx.Lifecycle
+	Factory      log.LoggerFactory
+	Buffer       logtest.TestLogBuffer
+	OtlpExporter logtest.TestOtlpExporter
+	Resource     *resource.Resource `optional:"true"`
+	Config       *config.Config
+	Logger       *log.Logger
+}
+
+// NewFxAuditLogger returns a [log.AuditLogger].
+//
+// The audit events are written to their own sink, separated from the application log records, and are never filtered
+// by the log level, sampled nor redacted. The sink is configured with the modules.log.audit.output config key: stdout
+// (by default), stderr, console, noop, test, file (appended to the modules.log.audit.file path, also used if only this
+// path is configured) or otlp (exported with the modules.log.otlp config keys). In test env, the audit events are
+// written to the test log buffer, or exported to the test OTLP exporter with the otlp output.
+func NewFxAuditLogger(p FxAuditLogParam) (*log.AuditLogger, error) {
+	file := p.Config.GetString("modules.log.audit.file")
+
+	output := log.FetchLogOutputWriter(p.Config.GetString("modules.log.audit.output"))
+	if file != "" && output != log.OtlpOutputWriter {
+		output = log.FileOutputWriter
+	}
+
+	if p.Config.IsTestEnv() && output != log.OtlpOutputWriter {
+		output = log.TestOutputWriter
+	}
+
+	format := log.JsonFormat
+
+	var outputWriter io.Writer
+	var otlpWriter *log.OtlpWriter
+
+	switch output {
+	case log.NoopOutputWriter:
+		outputWriter = io.Discard
+	case log.TestOutputWriter:
+		outputWriter = p.Buffer
+	case log.ConsoleOutputWriter:
+		outputWriter = os.Stderr
+		format = log.ConsoleFormat
+	case log.StderrOutputWriter:
+		outputWriter = os.Stderr
+	case log.FileOutputWriter:
+		if file == "" {
+			return nil, fmt.Errorf("missing audit log file path, expected in modules.log.audit.file config")
+		}
+
+		auditFile, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("could not open audit log file %s: %w", file, err)
+		}
+
+		p.LifeCycle.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				return auditFile.Close()
+			},
+		})
+
+		outputWriter = auditFile
+	case log.OtlpOutputWriter:
+		var err error
+
+		otlpWriter, err = configuredOtlpWriter(p.Config, p.OtlpExporter, p.Resource)
+		if err != nil {
+			return nil, err
+		}
+
+		shutdownOtlpWriterOnStop(p.LifeCycle, otlpWriter, p.Logger)
+
+		outputWriter = io.Discard
+	default:
+		outputWriter = os.Stdout
+	}
+
+	// the audit events are written without level, and never redacted nor sampled
+	options := []log.LoggerOption{
+		log.WithServiceName(p.Config.AppName()),
+		log.WithServiceVersion(p.Config.AppVersion()),
+		log.WithOutputWriter(outputWriter),
+		log.WithFormat(format),
+	}
+
+	if otlpWriter != nil {
+		options = append(options, log.WithOtlpWriter(otlpWriter))
+	}
+
+	logger, err := p.Factory.Create(append(options, configuredTimeOptions(p.Config)...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return log.NewAuditLogger(logger), nil
 }
@@ -1,16 +1,29 @@
 package fxlog_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxlog"
 	"github.com/ankorstore/yokai/fxlog/testdata/factory"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
 )
@@ -42,6 +55,32 @@ func TestModuleWithDebug(t *testing.T) {
 	})
 }
 
+func TestModuleWithVersion(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_VERSION", "1.2.3")
+	t.Setenv("TEST_LOG_OUTPUT", "test")
+
+	var buffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Info().Msg("test message")
+		}),
+		fx.Populate(&buffer),
+	).RequireStart().RequireStop()
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "info",
+		"service": "dev",
+		"version": "1.2.3",
+		"message": "test message",
+	})
+}
+
 func TestModuleWithTestEnv(t *testing.T) {
 	// test output writer should be used when APP_ENV=test, even if configured otherwise
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
@@ -183,6 +222,702 @@ func TestModuleWithStdoutOutputWriter(t *testing.T) {
 	assert.False(t, hasRecord)
 }
 
+func TestModuleWithAuditLogger(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_LEVEL", "error")
+	t.Setenv("TEST_LOG_OUTPUT", "test")
+	t.Setenv("TEST_LOG_AUDIT_OUTPUT", "test")
+
+	var buffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(auditLogger *log.AuditLogger) error {
+			return auditLogger.Audit(context.Background(), log.AuditEvent{
+				Actor:    "user-1",
+				Action:   "delete",
+				Resource: "order/1",
+				Outcome:  log.AuditOutcomeSuccess,
+				Fields: map[string]interface{}{
+					"password": "secret",
+				},
+			})
+		}),
+		fx.Populate(&buffer),
+	).RequireStart().RequireStop()
+
+	// audit events are written whatever the level, and never redacted
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"service":  "dev",
+		"audit":    true,
+		"actor":    "user-1",
+		"action":   "delete",
+		"resource": "order/1",
+		"outcome":  "success",
+		"password": "secret",
+	})
+}
+
+func TestModuleWithCtxAuditLogger(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	var buffer logtest.TestLogBuffer
+	var auditLogger *log.AuditLogger
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Populate(&buffer, &auditLogger),
+	).RequireStart().RequireStop()
+
+	// the module audit logger carried by the context, like done by the http and grpc servers
+	ctx := auditLogger.WithContext(context.Background())
+	assert.Equal(t, auditLogger, log.CtxAuditLogger(ctx))
+
+	member, err := baggage.NewMember(log.BaggageRequestIdKey, "request-1")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	ctx = baggage.ContextWithBaggage(log.WithAuditActor(ctx, "user-1"), bag)
+
+	err = log.CtxAuditLogger(ctx).Audit(ctx, log.AuditEvent{
+		Action:   "delete",
+		Resource: "order/1",
+		Outcome:  log.AuditOutcomeSuccess,
+	})
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"audit":     true,
+		"requestID": "request-1",
+		"actor":     "user-1",
+		"action":    "delete",
+	})
+
+	// the required fields are enforced
+	err = log.CtxAuditLogger(ctx).Audit(context.Background(), log.AuditEvent{
+		Action: "delete",
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "missing audit event required fields: actor, resource, outcome", err.Error())
+}
+
+func TestModuleWithAuditLoggerOtlpOutput(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("TEST_LOG_AUDIT_OUTPUT", "otlp")
+
+	var buffer logtest.TestLogBuffer
+	var exporter logtest.TestOtlpExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger, auditLogger *log.AuditLogger) error {
+			logger.Info().Msg("test message")
+
+			return auditLogger.Audit(context.Background(), log.AuditEvent{
+				Actor:    "user-1",
+				Action:   "login",
+				Resource: "session",
+				Outcome:  log.AuditOutcomeSuccess,
+			})
+		}),
+		fx.Populate(&buffer, &exporter),
+	).RequireStart().RequireStop()
+
+	// audit events are exported only, the application log records being written only
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "info",
+		"message": "test message",
+	})
+	logtest.AssertHasNotLogRecord(t, buffer, map[string]interface{}{
+		"audit": true,
+	})
+
+	records := exporter.Records()
+	assert.Len(t, records, 1)
+
+	attributes := map[string]otellog.Value{}
+	records[0].WalkAttributes(func(kv otellog.KeyValue) bool {
+		attributes[kv.Key] = kv.Value
+
+		return true
+	})
+
+	assert.Equal(t, otellog.BoolValue(true), attributes["audit"])
+	assert.Equal(t, otellog.StringValue("user-1"), attributes["actor"])
+	assert.Equal(t, otellog.StringValue("login"), attributes["action"])
+}
+
+func TestModuleWithAuditLoggerFileOutputWithoutPath(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_AUDIT_OUTPUT", "file")
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(*log.AuditLogger) {}),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "missing audit log file path, expected in modules.log.audit.file config")
+}
+
+func TestModuleWithAuditLoggerFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+	t.Setenv("TEST_LOG_OUTPUT", "test")
+	t.Setenv("TEST_LOG_AUDIT_FILE", file)
+
+	var buffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger, auditLogger *log.AuditLogger) error {
+			logger.Info().Msg("test message")
+
+			return auditLogger.Audit(context.Background(), log.AuditEvent{
+				Actor:    "user-1",
+				Action:   "login",
+				Resource: "session",
+				Outcome:  log.AuditOutcomeFailure,
+			})
+		}),
+		fx.Populate(&buffer),
+	).RequireStart().RequireStop()
+
+	// audit events are routed to the audit file only
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"audit":true`)
+	assert.Contains(t, string(content), `"action":"login"`)
+	assert.NotContains(t, string(content), "test message")
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "info",
+		"message": "test message",
+	})
+	logtest.AssertHasNotLogRecord(t, buffer, map[string]interface{}{
+		"audit": true,
+	})
+}
+
+func TestModuleWithFileOutputWriter(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+	t.Setenv("TEST_LOG_OUTPUT", "file")
+	t.Setenv("TEST_LOG_FILE_PATH", file)
+
+	message := strings.Repeat("a", 1024)
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			// exceeds the 1 megabyte max size
+			for i := 0; i < 1100; i++ {
+				logger.Info().Msg(message)
+			}
+
+			logger.Debug().Msg("last message")
+		}),
+	).RequireStart().RequireStop()
+
+	rotated, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	assert.NoError(t, err)
+	assert.Len(t, rotated, 1)
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"message":"last message"`)
+}
+
+func TestModuleWithMultiOutputWriter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.log")
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+	t.Setenv("TEST_LOG_OUTPUT", "multi")
+	t.Setenv("TEST_LOG_FILE_PATH", file)
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Debug().Msg("test message")
+		}),
+	).RequireStart().RequireStop()
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"level":"debug"`)
+	assert.Contains(t, string(content), `"message":"test message"`)
+}
+
+func TestModuleWithFileOutputWriterWithoutPath(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_OUTPUT", "file")
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {}),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "missing log file path")
+}
+
+func TestModuleWithConsoleFormatInDevEnv(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.log")
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "dev")
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+	t.Setenv("TEST_LOG_OUTPUT", "file")
+	t.Setenv("TEST_LOG_FILE_PATH", file)
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Info().Msg("test message")
+		}),
+	).RequireStart().RequireStop()
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+
+	// human-readable and colorized records
+	assert.Contains(t, lines[0], "DBG")
+	assert.Contains(t, lines[0], "logger format")
+	assert.Contains(t, lines[0], "console")
+	assert.Contains(t, lines[1], "INF")
+	assert.Contains(t, lines[1], "test message")
+	assert.Contains(t, lines[1], "\x1b[")
+	assert.NotContains(t, string(content), `"message"`)
+}
+
+func TestModuleWithJsonFormatInDevEnv(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.log")
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "dev")
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+	t.Setenv("TEST_LOG_OUTPUT", "file")
+	t.Setenv("TEST_LOG_FILE_PATH", file)
+	t.Setenv("TEST_LOG_FORMAT", "json")
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Info().Msg("test message")
+		}),
+	).RequireStart().RequireStop()
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"format":"json"`)
+	assert.Contains(t, string(content), `"message":"logger format"`)
+	assert.Contains(t, string(content), `"message":"test message"`)
+}
+
+func TestModuleWithConsoleFormatInTestEnv(t *testing.T) {
+	// the test log buffer records should be kept as JSON, even if configured otherwise
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+	t.Setenv("TEST_LOG_FORMAT", "console")
+
+	var buffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Info().Msg("test message")
+		}),
+		fx.Populate(&buffer),
+	).RequireStart().RequireStop()
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "debug",
+		"format":  "json",
+		"message": "logger format",
+	})
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "info",
+		"message": "test message",
+	})
+}
+
+func TestModuleWithTimeFormatAndField(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_OUTPUT", "test")
+	t.Setenv("TEST_LOG_TIME_FORMAT", "rfc3339")
+	t.Setenv("TEST_LOG_TIME_FIELD", "timestamp")
+
+	var buffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Info().Msg("test message")
+		}),
+		fx.Populate(&buffer),
+	).RequireStart().RequireStop()
+
+	records, err := buffer.Records()
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	_, err = records[0].Attribute(log.Time)
+	assert.Error(t, err)
+
+	timestamp, err := records[0].Attribute("timestamp")
+	assert.NoError(t, err)
+
+	recordTime, err := time.Parse(time.RFC3339, timestamp.(string))
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), recordTime, time.Minute)
+}
+
+func TestModuleWithRedaction(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_LEVEL", "info")
+	t.Setenv("TEST_LOG_OUTPUT", "test")
+
+	for _, deep := range []bool{false, true} {
+		t.Setenv("TEST_LOG_REDACT_DEEP", strconv.FormatBool(deep))
+
+		var buffer logtest.TestLogBuffer
+
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fx.Invoke(func(logger *log.Logger) {
+				logger.Info().
+					Str("password", "secret").
+					Str("authorization", "Bearer token").
+					RawJSON("payload", []byte(`{"password":"secret"}`)).
+					Msg("test message")
+			}),
+			fx.Populate(&buffer),
+		).RequireStart().RequireStop()
+
+		logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+			"level":         "info",
+			"password":      log.Redacted,
+			"authorization": log.Redacted,
+			"message":       "test message",
+		})
+
+		records, err := buffer.Records()
+		assert.NoError(t, err)
+		assert.Len(t, records, 1)
+
+		expectedPayloadPassword := "secret"
+		if deep {
+			expectedPayloadPassword = log.Redacted
+		}
+
+		payload, err := records[0].Attribute("payload")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"password": expectedPayloadPassword}, payload)
+	}
+}
+
+func TestModuleWithSampling(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_LEVEL", "info")
+	t.Setenv("TEST_LOG_OUTPUT", "test")
+	t.Setenv("TEST_LOG_SAMPLING_ENABLED", "true")
+
+	var buffer logtest.TestLogBuffer
+
+	dropped := log.DroppedRecords()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			for i := 0; i < 100; i++ {
+				logger.Debug().Msg("debug message")
+				logger.Info().Msg("info message")
+				logger.Error().Msg("error message")
+			}
+		}),
+		fx.Populate(&buffer),
+	).RequireStart().RequireStop()
+
+	records, err := buffer.Records()
+	assert.NoError(t, err)
+
+	counts := map[string]int{}
+	for _, record := range records {
+		message, err := record.Message()
+		assert.NoError(t, err)
+
+		counts[message]++
+	}
+
+	// the debug records are dropped by the level, and not counted as sampled
+	assert.Equal(t, 0, counts["debug message"])
+	assert.Equal(t, 10, counts["info message"])
+	assert.Equal(t, 100, counts["error message"])
+	assert.Equal(t, uint64(90), log.DroppedRecords()-dropped)
+}
+
+func TestModuleWithErrorStack(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_LEVEL", "info")
+	t.Setenv("TEST_LOG_OUTPUT", "test")
+	t.Setenv("TEST_LOG_STACK_ENABLED", "true")
+
+	var buffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Error().Err(log.WrapWithStack(errors.New("test error"))).Msg("error message")
+		}),
+		fx.Populate(&buffer),
+	).RequireStart().RequireStop()
+
+	records, err := buffer.Records()
+	assert.NoError(t, err)
+
+	var stack interface{}
+	for _, record := range records {
+		if message, _ := record.Message(); message == "error message" {
+			stack, err = record.Attribute("stack")
+			assert.NoError(t, err)
+		}
+	}
+
+	frames, ok := stack.([]interface{})
+	assert.True(t, ok)
+	assert.NotEmpty(t, frames)
+
+	frame, ok := frames[0].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "module_test.go", frame["source"])
+	assert.Contains(t, frame["func"], "TestModuleWithErrorStack")
+}
+
+func TestModuleWithoutErrorStack(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEST_LOG_LEVEL", "info")
+	t.Setenv("TEST_LOG_OUTPUT", "test")
+
+	var buffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Error().Err(log.WrapWithStack(errors.New("test error"))).Msg("error message")
+		}),
+		fx.Populate(&buffer),
+	).RequireStart().RequireStop()
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "test error",
+		"message": "error message",
+	})
+
+	records, err := buffer.Records()
+	assert.NoError(t, err)
+
+	for _, record := range records {
+		_, err = record.Attribute("stack")
+		assert.Error(t, err)
+	}
+}
+
+func TestModuleWithOtlpExport(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_VERSION", "1.2.3")
+	t.Setenv("TEST_LOG_LEVEL", "info")
+	t.Setenv("TEST_LOG_OTLP_ENABLED", "true")
+
+	var buffer logtest.TestLogBuffer
+	var exporter logtest.TestOtlpExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Warn().
+				Str("traceID", "c4ca4238a0b923820dcc509a6f75849b").
+				Str("spanID", "c81e728d9d4c2f63").
+				Str("password", "secret").
+				Msg("test message")
+		}),
+		fx.Populate(&buffer, &exporter),
+	).RequireStart().RequireStop()
+
+	// the log records are still written to the output
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "warn",
+		"service": "test",
+		"message": "test message",
+	})
+
+	records := exporter.Records()
+	assert.Len(t, records, 1)
+
+	assert.Equal(t, otellog.SeverityWarn, records[0].Severity())
+	assert.Equal(t, "test message", records[0].Body().AsString())
+	assert.Equal(t, "c4ca4238a0b923820dcc509a6f75849b", records[0].TraceID().String())
+	assert.Equal(t, "c81e728d9d4c2f63", records[0].SpanID().String())
+
+	attributes := map[string]string{}
+	records[0].WalkAttributes(func(kv otellog.KeyValue) bool {
+		attributes[kv.Key] = kv.Value.AsString()
+
+		return true
+	})
+
+	assert.Equal(t, map[string]string{"service": "test", "version": "1.2.3", "password": log.Redacted}, attributes)
+
+	res := records[0].Resource()
+	serviceName, ok := res.Set().Value(semconv.ServiceNameKey)
+	assert.True(t, ok)
+	assert.Equal(t, "test", serviceName.AsString())
+
+	serviceVersion, ok := res.Set().Value(semconv.ServiceVersionKey)
+	assert.True(t, ok)
+	assert.Equal(t, "1.2.3", serviceVersion.AsString())
+}
+
+func TestModuleWithOtlpExportAndProvidedResource(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("TEST_LOG_OTLP_ENABLED", "true")
+
+	var exporter logtest.TestOtlpExporter
+
+	// like the tracer provider resource, provided by the fxtrace module
+	res := resource.NewSchemaless(
+		semconv.ServiceNameKey.String("test"),
+		attribute.String("service.build.commit", "abc123"),
+	)
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Supply(res),
+		fx.Invoke(func(logger *log.Logger) {
+			logger.Info().Msg("test message")
+		}),
+		fx.Populate(&exporter),
+	).RequireStart().RequireStop()
+
+	records := exporter.Records()
+	assert.Len(t, records, 1)
+
+	recordResource := records[0].Resource()
+	assert.True(t, res.Equal(&recordResource))
+}
+
+func TestModuleWithOtlpExportFailures(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("TEST_LOG_OTLP_ENABLED", "true")
+
+	var buffer logtest.TestLogBuffer
+	var exporter logtest.TestOtlpExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(logger *log.Logger, exporter logtest.TestOtlpExporter) {
+			exporter.FailWith(errors.New("export error"))
+
+			logger.Info().Msg("first message")
+			logger.Info().Msg("second message")
+		}),
+		fx.Populate(&buffer, &exporter),
+	).RequireStart().RequireStop()
+
+	// the export failures never fail the application logging
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "info",
+		"message": "second message",
+	})
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "warn",
+		"error":   "export error",
+		"message": "log records otlp export shutdown failed",
+	})
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "warn",
+		"dropped": float64(2),
+		"message": "log records dropped by the otlp export",
+	})
+
+	assert.Len(t, exporter.Records(), 0)
+}
+
 func TestModuleDecoration(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 
@@ -197,5 +932,140 @@ func TestModuleDecoration(t *testing.T) {
 		fx.Populate(&logger),
 	).RequireStart().RequireStop()
 
-	assert.Equal(t, &log.Logger{}, logger)
+	assert.Equal(t, log.FromZerolog(zerolog.Nop()), logger)
+}
+
+func TestModuleWithConfigReloadError(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+
+	err := os.WriteFile(file, []byte("app:\n  name: test\nmodules:\n  config:\n    watch:\n      enabled: true\n  log:\n    output: test\n"), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("APP_CONFIG_PATH", dir)
+
+	var buffer logtest.TestLogBuffer
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Invoke(func(*log.Logger) {}),
+		fx.Populate(&buffer),
+	).RequireStart()
+
+	err = os.WriteFile(file, []byte("app: ["), 0o600)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		found, err := buffer.HasRecord(map[string]interface{}{
+			"level":   "error",
+			"service": "test",
+			"message": "config reload failed, keeping the previous config",
+		})
+
+		return err == nil && found
+	}, 5*time.Second, 50*time.Millisecond)
+
+	app.RequireStop()
+}
+
+func TestModuleWithConfigReloadLevel(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+
+	content := "app:\n  name: test\nmodules:\n  config:\n    watch:\n      enabled: true\n  log:\n    level: %s\n    output: test\n"
+
+	err := os.WriteFile(file, []byte(fmt.Sprintf(content, "info")), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("APP_CONFIG_PATH", dir)
+
+	var logger *log.Logger
+	var buffer logtest.TestLogBuffer
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fx.Populate(&logger, &buffer),
+	).RequireStart()
+
+	// module scoped logger, derived before the reload
+	moduleLogger := log.FromZerolog(logger.With().Str("module", "test").Logger())
+
+	moduleLogger.Debug().Msg("suppressed debug message")
+
+	// invalid level, the previous level is kept
+	err = os.WriteFile(file, []byte(fmt.Sprintf(content, "invalid")), 0o600)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		found, err := buffer.HasRecord(map[string]interface{}{
+			"level":   "error",
+			"value":   "invalid",
+			"message": "invalid log level in config, keeping the previous level",
+		})
+
+		return err == nil && found
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, zerolog.InfoLevel, logger.GetLevel())
+
+	// valid level, applied at runtime
+	err = os.WriteFile(file, []byte(fmt.Sprintf(content, "debug")), 0o600)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		found, err := buffer.HasRecord(map[string]interface{}{
+			"level":   "info",
+			"from":    "info",
+			"to":      "debug",
+			"message": "log level changed",
+		})
+
+		return err == nil && found
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, zerolog.DebugLevel, logger.GetLevel())
+
+	moduleLogger.Debug().Msg("visible debug message")
+
+	logtest.AssertHasNotLogRecord(t, buffer, map[string]interface{}{
+		"level":   "debug",
+		"message": "suppressed debug message",
+	})
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "debug",
+		"service": "test",
+		"module":  "test",
+		"message": "visible debug message",
+	})
+
+	// upper case and short level names
+	err = os.WriteFile(file, []byte(fmt.Sprintf(content, "WARN")), 0o600)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		found, err := buffer.HasRecord(map[string]interface{}{
+			"level":   "info",
+			"from":    "debug",
+			"to":      "warn",
+			"message": "log level changed",
+		})
+
+		return err == nil && found
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, zerolog.WarnLevel, logger.GetLevel())
+
+	logtest.AssertHasNotLogRecord(t, buffer, map[string]interface{}{
+		"level": "error",
+		"value": "WARN",
+	})
+
+	app.RequireStop()
 }
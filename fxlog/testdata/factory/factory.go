@@ -2,6 +2,7 @@ package factory
 
 import (
 	"github.com/ankorstore/yokai/log"
+	"github.com/rs/zerolog"
 )
 
 type TestLoggerFactory struct{}
@@ -11,5 +12,5 @@ func NewTestLoggerFactory() log.LoggerFactory {
 }
 
 func (f *TestLoggerFactory) Create(options ...log.LoggerOption) (*log.Logger, error) {
-	return &log.Logger{}, nil
+	return log.FromZerolog(zerolog.Nop()), nil
 }
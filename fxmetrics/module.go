@@ -1,14 +1,26 @@
 package fxmetrics
 
 import (
+	"errors"
+	"strconv"
+
+	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.uber.org/fx"
 )
 
 // ModuleName is the module name.
 const ModuleName = "metrics"
 
+const (
+	// FlagEvaluationsMetricName is the name of the config feature flags evaluations counter.
+	FlagEvaluationsMetricName = "config_flag_evaluations_total"
+	// LogDroppedRecordsMetricName is the name of the log records dropped by sampling counter.
+	LogDroppedRecordsMetricName = "log_sampling_dropped_records_total"
+)
+
 // FxMetricsModule is the [Fx] metrics module.
 //
 // [Fx]: https://github.com/uber-go/fx
@@ -24,6 +36,7 @@ var FxMetricsModule = fx.Module(
 type FxMetricsRegistryParam struct {
 	fx.In
 	Factory    MetricsRegistryFactory
+	Config     *config.Config
 	Logger     *log.Logger
 	Collectors []prometheus.Collector `group:"metrics-collectors"`
 }
@@ -48,5 +61,63 @@ func NewFxMetricsRegistry(p FxMetricsRegistryParam) (*prometheus.Registry, error
 		}
 	}
 
+	registerFlagEvaluationsCollector(registry, p.Config, p.Logger)
+	registerOptionalCollector(registry, logDroppedRecordsCollector(), p.Logger)
+
+	if p.Config.GetBool("modules.metrics.runtime.enabled") {
+		registerOptionalCollector(registry, collectors.NewGoCollector(), p.Logger)
+	}
+
+	if p.Config.GetBool("modules.metrics.process.enabled") {
+		registerOptionalCollector(registry, collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}), p.Logger)
+	}
+
 	return registry, err
 }
+
+func registerOptionalCollector(registry *prometheus.Registry, collector prometheus.Collector, logger *log.Logger) {
+	err := registry.Register(collector)
+	if err != nil {
+		if errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			logger.Debug().Msgf("metrics collector %+T already registered, skipping", collector)
+		} else {
+			logger.Warn().Err(err).Msgf("failed to register metrics collector %+T", collector)
+		}
+	} else {
+		logger.Debug().Msgf("registered metrics collector %+T", collector)
+	}
+}
+
+// registerFlagEvaluationsCollector registers a counter of the config feature flags evaluations, by flag name and result.
+func registerFlagEvaluationsCollector(registry *prometheus.Registry, cfg *config.Config, logger *log.Logger) {
+	flagEvaluations := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: FlagEvaluationsMetricName,
+			Help: "Number of config feature flags evaluations",
+		},
+		[]string{"flag", "enabled"},
+	)
+
+	if err := registry.Register(flagEvaluations); err != nil {
+		logger.Warn().Err(err).Msg("failed to register feature flags evaluations metrics collector")
+
+		return
+	}
+
+	cfg.OnFlagEvaluation(func(name string, enabled bool) {
+		flagEvaluations.WithLabelValues(name, strconv.FormatBool(enabled)).Inc()
+	})
+}
+
+// logDroppedRecordsCollector returns a counter of the log records dropped by sampling (see modules.log.sampling).
+func logDroppedRecordsCollector() prometheus.Collector {
+	return prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: LogDroppedRecordsMetricName,
+			Help: "Number of log records dropped by sampling",
+		},
+		func() float64 {
+			return float64(log.DroppedRecords())
+		},
+	)
+}
@@ -1,18 +1,25 @@
 package fxmetrics_test
 
 import (
+	"context"
+	"fmt"
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxlog"
 	"github.com/ankorstore/yokai/fxmetrics"
 	"github.com/ankorstore/yokai/fxmetrics/testdata/factory"
 	"github.com/ankorstore/yokai/fxmetrics/testdata/metrics"
 	"github.com/ankorstore/yokai/fxmetrics/testdata/spy"
+	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
@@ -86,6 +93,148 @@ func TestModuleErrorWithDuplicatedCollector(t *testing.T) {
 	assert.Contains(t, spyTB.Errors().String(), "duplicate metrics collector registration attempted")
 }
 
+func TestModuleWithRuntimeAndProcessCollectors(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_METRICS_RUNTIME_ENABLED", "true")
+	t.Setenv("MODULES_METRICS_PROCESS_ENABLED", "true")
+
+	var logBuffer logtest.TestLogBuffer
+	var registry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fx.Populate(&logBuffer, &registry),
+	).RequireStart().RequireStop()
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "debug",
+		"message": "registered metrics collector *prometheus.goCollector",
+	})
+
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+
+	var names []string
+	for _, metricFamily := range metricFamilies {
+		names = append(names, metricFamily.GetName())
+	}
+
+	assert.Contains(t, names, "go_goroutines")
+	assert.Contains(t, names, "go_memstats_alloc_bytes")
+
+	if runtime.GOOS == "linux" {
+		assert.Contains(t, names, "process_resident_memory_bytes")
+	}
+}
+
+func TestModuleWithAlreadyRegisteredRuntimeCollector(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_METRICS_RUNTIME_ENABLED", "true")
+
+	var logBuffer logtest.TestLogBuffer
+	var registry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fx.Options(
+			fxmetrics.AsMetricsCollector(collectors.NewGoCollector()),
+		),
+		fx.Populate(&logBuffer, &registry),
+	).RequireStart().RequireStop()
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "debug",
+		"message": "metrics collector *prometheus.goCollector already registered, skipping",
+	})
+
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, metricFamilies)
+}
+
+func TestModuleWithFlagEvaluations(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var registry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fx.Populate(&registry),
+		fx.Invoke(func(cfg *config.Config) {
+			cfg.Flag("test").Enabled(context.Background())
+			cfg.Flag("test").Enabled(context.Background())
+			cfg.Flag("missing").Enabled(context.Background())
+		}),
+	).RequireStart().RequireStop()
+
+	expectedHelp := `
+		# HELP config_flag_evaluations_total Number of config feature flags evaluations
+		# TYPE config_flag_evaluations_total counter
+	`
+	expectedMetric := `
+		config_flag_evaluations_total{enabled="false",flag="missing"} 1
+		config_flag_evaluations_total{enabled="true",flag="test"} 2
+	`
+
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedHelp+expectedMetric),
+		fxmetrics.FlagEvaluationsMetricName,
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithLogDroppedRecords(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var registry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fx.Populate(&registry),
+	).RequireStart().RequireStop()
+
+	sampler := log.NewSampler(map[zerolog.Level]zerolog.Sampler{
+		zerolog.InfoLevel: log.NewRatioSampler(0),
+	})
+
+	for i := 0; i < 3; i++ {
+		sampler.Sample(zerolog.InfoLevel)
+	}
+
+	expectedHelp := `
+		# HELP log_sampling_dropped_records_total Number of log records dropped by sampling
+		# TYPE log_sampling_dropped_records_total counter
+	`
+	expectedMetric := fmt.Sprintf(`
+		log_sampling_dropped_records_total %d
+	`, log.DroppedRecords())
+
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedHelp+expectedMetric),
+		fxmetrics.LogDroppedRecordsMetricName,
+	)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, log.DroppedRecords(), uint64(3))
+}
+
 func TestModuleDecoration(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 
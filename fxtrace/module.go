@@ -7,7 +7,9 @@ import (
 	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/trace"
 	"github.com/ankorstore/yokai/trace/tracetest"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	otelsdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
@@ -15,8 +17,10 @@ import (
 	"go.uber.org/fx"
 )
 
-// ModuleName is the module name.
-const ModuleName = "trace"
+const (
+	ModuleName           = "trace"                // module name
+	BuildCommitAttribute = "service.build.commit" // resource attribute of the application build git commit SHA
+)
 
 // FxTraceModule is the [Fx] trace module.
 //
@@ -26,6 +30,7 @@ var FxTraceModule = fx.Module(
 	fx.Provide(
 		trace.NewDefaultTracerProviderFactory,
 		tracetest.NewDefaultTestTraceExporter,
+		NewFxTraceResource,
 		fx.Annotate(
 			NewFxTracerProvider,
 			fx.As(new(oteltrace.TracerProvider)),
@@ -39,18 +44,46 @@ type FxTraceParam struct {
 	LifeCycle fx.Lifecycle
 	Factory   trace.TracerProviderFactory
 	Exporter  tracetest.TestTraceExporter
+	Resource  *resource.Resource
 	Config    *config.Config
 }
 
-// NewFxTracerProvider returns a [otelsdktrace.TracerProvider].
-func NewFxTracerProvider(p FxTraceParam) (*otelsdktrace.TracerProvider, error) {
-	ctx := context.Background()
+// FxTraceResourceParam allows injection of the required dependencies in [NewFxTraceResource].
+type FxTraceResourceParam struct {
+	fx.In
+	Config *config.Config
+}
+
+// NewFxTraceResource returns the [resource.Resource] of the tracer provider, described with the app.name (as
+// service.name attribute), app.version (as service.version attribute) and, if set, app.build.commit (as
+// [BuildCommitAttribute] attribute) config keys.
+//
+// It can be shared with the other telemetry signals providers, like the log module OTLP export.
+func NewFxTraceResource(p FxTraceResourceParam) (*resource.Resource, error) {
+	attributes := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(p.Config.AppName()),
+		semconv.ServiceVersionKey.String(p.Config.AppVersion()),
+	}
 
-	res, err := createResource(ctx, p)
+	if commit := p.Config.GetString("app.build.commit"); commit != "" {
+		attributes = append(attributes, attribute.String(BuildCommitAttribute, commit))
+	}
+
+	res, err := resource.New(context.Background(), resource.WithAttributes(attributes...))
 	if err != nil {
 		return nil, fmt.Errorf("cannot create tracer provider resource: %w", err)
 	}
 
+	return res, nil
+}
+
+// NewFxTracerProvider returns a [otelsdktrace.TracerProvider].
+//
+// The propagators listed in modules.trace.propagators (tracecontext, baggage, b3, b3multi or jaeger) are set globally,
+// to be used by the http and gRPC servers and clients (tracecontext and baggage by default).
+func NewFxTracerProvider(p FxTraceParam) (*otelsdktrace.TracerProvider, error) {
+	ctx := context.Background()
+
 	proc, err := createSpanProcessor(ctx, p)
 	if err != nil {
 		// safety fallback to noop span processor
@@ -59,10 +92,13 @@ func NewFxTracerProvider(p FxTraceParam) (*otelsdktrace.TracerProvider, error) {
 
 	samp := createSampler(p)
 
+	prop := createPropagator(p)
+
 	tracerProvider, err := p.Factory.Create(
-		trace.WithResource(res),
+		trace.WithResource(p.Resource),
 		trace.WithSpanProcessor(proc),
 		trace.WithSampler(samp),
+		trace.WithPropagator(prop),
 	)
 	if err != nil {
 		return nil, err
@@ -95,20 +131,6 @@ func fetchSpanProcessorType(p FxTraceParam) trace.SpanProcessor {
 	}
 }
 
-func createResource(ctx context.Context, p FxTraceParam) (*resource.Resource, error) {
-	res, err := resource.New(
-		ctx,
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String(p.Config.AppName()),
-		),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return res, nil
-}
-
 func createSpanProcessor(ctx context.Context, p FxTraceParam) (otelsdktrace.SpanProcessor, error) {
 	switch fetchSpanProcessorType(p) {
 	case trace.StdoutSpanProcessor:
@@ -150,3 +172,12 @@ func createSampler(p FxTraceParam) otelsdktrace.Sampler {
 		return trace.NewParentBasedAlwaysOnSampler()
 	}
 }
+
+func createPropagator(p FxTraceParam) propagation.TextMapPropagator {
+	var propagators []trace.Propagator
+	for _, name := range p.Config.GetStringSlice("modules.trace.propagators") {
+		propagators = append(propagators, trace.FetchPropagator(name))
+	}
+
+	return trace.NewPropagator(propagators...)
+}
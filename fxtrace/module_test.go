@@ -9,7 +9,11 @@ import (
 	"github.com/ankorstore/yokai/fxtrace/testdata/factory"
 	"github.com/ankorstore/yokai/trace/tracetest"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
@@ -43,6 +47,86 @@ func TestModuleWithTestEnv(t *testing.T) {
 	tracetest.AssertHasTraceSpan(t, exporter, "test span", attribute.String("test attribute name", "test attribute value"))
 }
 
+func TestModuleWithPropagators(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	traceId := "c4ca71e03e42c2c3d54293a6e2608bfa"
+	spanId := "8d0fdc8a74baaaea"
+
+	var exporter tracetest.TestTraceExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxtrace.FxTraceModule,
+		fx.Invoke(func(tracerProvider oteltrace.TracerProvider) {
+			// incoming B3 headers, extracted with the global propagator
+			ctx := otel.GetTextMapPropagator().Extract(
+				context.Background(),
+				propagation.MapCarrier{
+					"x-b3-traceid": traceId,
+					"x-b3-spanid":  spanId,
+					"x-b3-sampled": "1",
+				},
+			)
+
+			_, span := tracerProvider.Tracer("test tracer").Start(ctx, "test span")
+			defer span.End()
+		}),
+		fx.Populate(&exporter),
+	).RequireStart().RequireStop()
+
+	assert.ElementsMatch(
+		t,
+		[]string{"traceparent", "tracestate", "baggage", "x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-flags"},
+		otel.GetTextMapPropagator().Fields(),
+	)
+
+	// the B3 trace is continued
+	span, err := exporter.Span("test span")
+	assert.NoError(t, err)
+	assert.Equal(t, traceId, span.SpanContext.TraceID().String())
+	assert.Equal(t, spanId, span.Parent.SpanID().String())
+}
+
+func TestModuleWithVersionResourceAttributes(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_VERSION", "1.2.3")
+	t.Setenv("APP_BUILD_COMMIT", "abc123")
+
+	var exporter tracetest.TestTraceExporter
+	var res *resource.Resource
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxtrace.FxTraceModule,
+		fx.Invoke(func(tracerProvider oteltrace.TracerProvider) {
+			_, span := tracerProvider.Tracer("test tracer").Start(context.Background(), "test span")
+			defer span.End()
+		}),
+		fx.Populate(&exporter, &res),
+	).RequireStart().RequireStop()
+
+	span, err := exporter.Span("test span")
+	assert.NoError(t, err)
+
+	// the provided resource is the tracer provider one, to be shared with the other signals
+	assert.Equal(t, res, span.Resource)
+
+	version, ok := span.Resource.Set().Value(semconv.ServiceVersionKey)
+	assert.True(t, ok)
+	assert.Equal(t, "1.2.3", version.AsString())
+
+	commit, ok := span.Resource.Set().Value(fxtrace.BuildCommitAttribute)
+	assert.True(t, ok)
+	assert.Equal(t, "abc123", commit.AsString())
+}
+
 func TestModuleSafetyFallbackOnNoopProcessor(t *testing.T) {
 	// should fall back on noop processor
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
@@ -0,0 +1,11 @@
+package id
+
+// IdGenerator is the interface for identifiers generators, for example for requests ids.
+//
+// The [uuid.UuidGenerator] implementations are also IdGenerator implementations, and other identifiers formats (like
+// KSUID or ULID) can be provided by implementing this interface.
+//
+// [uuid.UuidGenerator]: https://pkg.go.dev/github.com/ankorstore/yokai/generate/uuid#UuidGenerator
+type IdGenerator interface {
+	Generate() string
+}
@@ -0,0 +1,29 @@
+package id_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai/generate/id"
+	"github.com/ankorstore/yokai/generate/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type testIdGenerator struct{}
+
+func (g *testIdGenerator) Generate() string {
+	return "custom"
+}
+
+func TestIdGenerator(t *testing.T) {
+	t.Parallel()
+
+	var generator id.IdGenerator = &testIdGenerator{}
+
+	assert.Equal(t, "custom", generator.Generate())
+}
+
+func TestUuidGeneratorImplementsIdGenerator(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*id.IdGenerator)(nil), uuid.NewDefaultUuidGenerator())
+}
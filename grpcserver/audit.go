@@ -0,0 +1,39 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/ankorstore/yokai/log"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+)
+
+// GrpcAuditLoggerInterceptor is a gRPC unary and stream server interceptor carrying a [log.AuditLogger] in the
+// requests context, to be retrieved with [CtxAuditLogger].
+type GrpcAuditLoggerInterceptor struct {
+	auditLogger *log.AuditLogger
+}
+
+// NewGrpcAuditLoggerInterceptor returns a new [GrpcAuditLoggerInterceptor] instance, for a provided [log.AuditLogger].
+func NewGrpcAuditLoggerInterceptor(auditLogger *log.AuditLogger) *GrpcAuditLoggerInterceptor {
+	return &GrpcAuditLoggerInterceptor{
+		auditLogger: auditLogger,
+	}
+}
+
+// UnaryInterceptor handles the unary requests.
+func (i *GrpcAuditLoggerInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(i.auditLogger.WithContext(ctx), req)
+	}
+}
+
+// StreamInterceptor handles the stream requests.
+func (i *GrpcAuditLoggerInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &middleware.WrappedServerStream{
+			ServerStream:   ss,
+			WrappedContext: i.auditLogger.WithContext(ss.Context()),
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package grpcserver_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestGrpcAuditLoggerInterceptorUnary(t *testing.T) {
+	t.Parallel()
+
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(logtest.NewDefaultTestLogBuffer())))
+
+	interceptor := grpcserver.NewGrpcAuditLoggerInterceptor(auditLogger)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"}
+
+	resp, err := interceptor.UnaryInterceptor()(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		assert.Same(t, auditLogger, grpcserver.CtxAuditLogger(ctx))
+
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestGrpcAuditLoggerInterceptorStream(t *testing.T) {
+	t.Parallel()
+
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(logtest.NewDefaultTestLogBuffer())))
+
+	interceptor := grpcserver.NewGrpcAuditLoggerInterceptor(auditLogger)
+
+	stream := &testServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Bidi"}
+
+	err := interceptor.StreamInterceptor()(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		assert.Same(t, auditLogger, grpcserver.CtxAuditLogger(ss.Context()))
+
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,160 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const GrpcServerMetricsInFlightRequests = "grpc_server_in_flight_requests"
+
+// GrpcConcurrencyLimiterInterceptor is a gRPC unary and stream server interceptor to limit the concurrent in-flight
+// calls, rejecting the calls exceeding the limit with a [codes.ResourceExhausted] status.
+type GrpcConcurrencyLimiterInterceptor struct {
+	limit        int
+	methodLimits map[string]int
+	mutex        sync.Mutex
+	inFlight     int
+	methods      map[string]int
+	gauge        *prometheus.GaugeVec
+}
+
+// NewGrpcConcurrencyLimiterInterceptor returns a new [GrpcConcurrencyLimiterInterceptor] instance, limiting to a
+// given number of concurrent in-flight calls (unlimited if zero or negative).
+func NewGrpcConcurrencyLimiterInterceptor(limit int) *GrpcConcurrencyLimiterInterceptor {
+	return &GrpcConcurrencyLimiterInterceptor{
+		limit:        limit,
+		methodLimits: map[string]int{},
+		methods:      map[string]int{},
+	}
+}
+
+// MethodLimits configures per method name limits, overriding the global limit: the calls to these methods are only
+// limited by their own limit, and are not counted in the global limit.
+func (i *GrpcConcurrencyLimiterInterceptor) MethodLimits(limits map[string]int) *GrpcConcurrencyLimiterInterceptor {
+	for k, v := range limits {
+		i.methodLimits[k] = v
+	}
+
+	return i
+}
+
+// Metrics registers in a given registry the grpc_server_in_flight_requests gauge, exposing the in-flight calls
+// count per method.
+func (i *GrpcConcurrencyLimiterInterceptor) Metrics(
+	registry prometheus.Registerer,
+	namespace string,
+	subsystem string,
+) *GrpcConcurrencyLimiterInterceptor {
+	gauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      GrpcServerMetricsInFlightRequests,
+			Help:      "Number of in-flight gRPC calls",
+		},
+		[]string{
+			"grpc_method",
+		},
+	)
+
+	if err := registry.Register(gauge); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+
+		//nolint:forcetypeassert
+		gauge = are.ExistingCollector.(*prometheus.GaugeVec)
+	}
+
+	i.gauge = gauge
+
+	return i
+}
+
+// InFlight returns the number of in-flight calls for a given method name.
+func (i *GrpcConcurrencyLimiterInterceptor) InFlight(method string) int {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.methods[method]
+}
+
+// UnaryInterceptor handles the unary requests.
+func (i *GrpcConcurrencyLimiterInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := i.acquire(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		defer i.release(info.FullMethod)
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor handles the stream requests.
+func (i *GrpcConcurrencyLimiterInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.acquire(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		defer i.release(info.FullMethod)
+
+		return handler(srv, ss)
+	}
+}
+
+func (i *GrpcConcurrencyLimiterInterceptor) acquire(ctx context.Context, method string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if methodLimit, ok := i.methodLimits[method]; ok {
+		if methodLimit > 0 && i.methods[method] >= methodLimit {
+			return i.reject(ctx, method, methodLimit)
+		}
+	} else {
+		if i.limit > 0 && i.inFlight >= i.limit {
+			return i.reject(ctx, method, i.limit)
+		}
+
+		i.inFlight++
+	}
+
+	i.methods[method]++
+
+	if i.gauge != nil {
+		i.gauge.WithLabelValues(method).Inc()
+	}
+
+	return nil
+}
+
+func (i *GrpcConcurrencyLimiterInterceptor) release(method string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if _, ok := i.methodLimits[method]; !ok {
+		i.inFlight--
+	}
+
+	i.methods[method]--
+	if i.methods[method] == 0 {
+		delete(i.methods, method)
+	}
+
+	if i.gauge != nil {
+		i.gauge.WithLabelValues(method).Dec()
+	}
+}
+
+func (i *GrpcConcurrencyLimiterInterceptor) reject(ctx context.Context, method string, limit int) error {
+	CtxLogger(ctx).Warn().Str("grpcMethod", method).Int("limit", limit).Msg("grpc call rejected by concurrency limit")
+
+	return status.Errorf(codes.ResourceExhausted, "too many concurrent calls for %s, limit is %d", method, limit)
+}
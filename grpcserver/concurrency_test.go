@@ -0,0 +1,170 @@
+package grpcserver_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fireConcurrentUnaryCalls fires calls to a blocking handler until they are all either in-flight or rejected, and
+// returns the number of rejected calls and a function releasing the in-flight ones.
+func fireConcurrentUnaryCalls(
+	t *testing.T,
+	interceptor grpc.UnaryServerInterceptor,
+	method string,
+	calls int,
+) (int, func()) {
+	t.Helper()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, calls)
+	results := make(chan error, calls)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+
+		return req, nil
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < calls; n++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := interceptor(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: method}, handler)
+			results <- err
+		}()
+	}
+
+	rejected := 0
+	for n := 0; n < calls; n++ {
+		select {
+		case <-started:
+		case err := <-results:
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+			rejected++
+		}
+	}
+
+	return rejected, func() {
+		close(release)
+		wg.Wait()
+		close(results)
+
+		for err := range results {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestGrpcConcurrencyLimiterInterceptorUnary(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	limiter := grpcserver.NewGrpcConcurrencyLimiterInterceptor(3).Metrics(registry, "foo", "bar")
+
+	rejected, release := fireConcurrentUnaryCalls(t, limiter.UnaryInterceptor(), "/test.Service/Unary", 10)
+
+	assert.Equal(t, 7, rejected)
+	assert.Equal(t, 3, limiter.InFlight("/test.Service/Unary"))
+
+	expectedMetric := `
+		# HELP foo_bar_grpc_server_in_flight_requests Number of in-flight gRPC calls
+		# TYPE foo_bar_grpc_server_in_flight_requests gauge
+		foo_bar_grpc_server_in_flight_requests{grpc_method="/test.Service/Unary"} 3
+	`
+
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_grpc_server_in_flight_requests")
+	assert.NoError(t, err)
+
+	release()
+
+	assert.Equal(t, 0, limiter.InFlight("/test.Service/Unary"))
+
+	expectedMetric = `
+		# HELP foo_bar_grpc_server_in_flight_requests Number of in-flight gRPC calls
+		# TYPE foo_bar_grpc_server_in_flight_requests gauge
+		foo_bar_grpc_server_in_flight_requests{grpc_method="/test.Service/Unary"} 0
+	`
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_grpc_server_in_flight_requests")
+	assert.NoError(t, err)
+
+	// accepted again once released
+	_, err = limiter.UnaryInterceptor()(
+		context.Background(),
+		"request",
+		&grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		},
+	)
+	assert.NoError(t, err)
+}
+
+func TestGrpcConcurrencyLimiterInterceptorUnaryWithMethodLimits(t *testing.T) {
+	t.Parallel()
+
+	limiter := grpcserver.
+		NewGrpcConcurrencyLimiterInterceptor(2).
+		MethodLimits(map[string]int{
+			"/test.Service/Limited":   1,
+			"/test.Service/Unlimited": 0,
+		})
+
+	limitedRejected, releaseLimited := fireConcurrentUnaryCalls(t, limiter.UnaryInterceptor(), "/test.Service/Limited", 5)
+	unlimitedRejected, releaseUnlimited := fireConcurrentUnaryCalls(t, limiter.UnaryInterceptor(), "/test.Service/Unlimited", 5)
+	globalRejected, releaseGlobal := fireConcurrentUnaryCalls(t, limiter.UnaryInterceptor(), "/test.Service/Unary", 5)
+
+	assert.Equal(t, 4, limitedRejected)
+	assert.Equal(t, 0, unlimitedRejected)
+	assert.Equal(t, 3, globalRejected)
+
+	releaseLimited()
+	releaseUnlimited()
+	releaseGlobal()
+}
+
+func TestGrpcConcurrencyLimiterInterceptorStream(t *testing.T) {
+	t.Parallel()
+
+	limiter := grpcserver.NewGrpcConcurrencyLimiterInterceptor(1)
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Bidi"}
+	stream := &testServerStream{ctx: context.Background()}
+
+	err := limiter.StreamInterceptor()(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		assert.Equal(t, 1, limiter.InFlight("/test.Service/Bidi"))
+
+		// nested call exceeding the limit
+		return limiter.StreamInterceptor()(nil, ss, info, func(srv interface{}, ss grpc.ServerStream) error {
+			return nil
+		})
+	})
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "too many concurrent calls for /test.Service/Bidi, limit is 1")
+	assert.Equal(t, 0, limiter.InFlight("/test.Service/Bidi"))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -11,11 +11,30 @@ import (
 // TracerName is the grpcserver tracer name.
 const TracerName = "grpcserver"
 
+// CtxRequestIdKey is a contextual struct key.
+type CtxRequestIdKey struct{}
+
+// CtxRequestId returns the contextual request id (see the [GrpcLoggerInterceptor]).
+func CtxRequestId(ctx context.Context) string {
+	if rid, ok := ctx.Value(CtxRequestIdKey{}).(string); ok {
+		return rid
+	}
+
+	return ""
+}
+
 // CtxLogger returns the contextual [log.Logger].
 func CtxLogger(ctx context.Context) *log.Logger {
 	return log.CtxLogger(ctx)
 }
 
+// CtxAuditLogger returns the contextual [log.AuditLogger] (see the [GrpcAuditLoggerInterceptor]), the audit events being
+// enriched with the request id. The authentication interceptors can stamp the audit events actor in the context with
+// [log.WithAuditActor].
+func CtxAuditLogger(ctx context.Context) *log.AuditLogger {
+	return log.CtxAuditLogger(ctx)
+}
+
 // CtxTracer returns the contextual [oteltrace.Tracer].
 func CtxTracer(ctx context.Context) oteltrace.Tracer {
 	return trace.CtxTracerProvider(ctx).Tracer(TracerName)
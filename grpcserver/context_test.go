@@ -4,12 +4,16 @@ import (
 	"context"
 	"testing"
 
+	"github.com/ankorstore/yokai/generate/generatetest/uuid"
+	"github.com/ankorstore/yokai/grpcserver"
 	"github.com/ankorstore/yokai/grpcserver/testdata/proto"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/ankorstore/yokai/trace"
 	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -104,3 +108,52 @@ func TestCtxTracer(t *testing.T) {
 	// trace assertions
 	tracetest.AssertHasTraceSpan(t, exporter, "unary trace")
 }
+
+func TestCtxAuditLogger(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(log.WithOutputWriter(logBuffer))
+	assert.NoError(t, err)
+
+	auditLogBuffer := logtest.NewDefaultTestLogBuffer()
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(auditLogBuffer)))
+
+	loggerInterceptor := grpcserver.NewGrpcLoggerInterceptor(uuid.NewTestUuidGenerator("generated"), logger)
+	auditLoggerInterceptor := grpcserver.NewGrpcAuditLoggerInterceptor(auditLogger)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", testRequestId))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		// like stamped by an authentication interceptor
+		ctx = log.WithAuditActor(ctx, "user-1")
+
+		return req, grpcserver.CtxAuditLogger(ctx).Audit(ctx, log.AuditEvent{
+			Action:   "delete",
+			Resource: "order/1",
+			Outcome:  log.AuditOutcomeSuccess,
+		})
+	}
+
+	_, err = loggerInterceptor.UnaryInterceptor()(
+		ctx,
+		"request",
+		info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return auditLoggerInterceptor.UnaryInterceptor()(ctx, req, info, handler)
+		},
+	)
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, auditLogBuffer, map[string]interface{}{
+		"audit":     true,
+		"requestID": testRequestId,
+		"actor":     "user-1",
+		"action":    "delete",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"audit": true,
+	})
+}
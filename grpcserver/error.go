@@ -0,0 +1,185 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorMatcher returns true if an error matches, to be mapped by a [GrpcErrorRegistry].
+type ErrorMatcher func(err error) bool
+
+// MatchErrorIs returns an [ErrorMatcher] matching the errors wrapping a target error (see [errors.Is]).
+func MatchErrorIs(target error) ErrorMatcher {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// MatchErrorAs returns an [ErrorMatcher] matching the errors wrapping an error of type T (see [errors.As]).
+func MatchErrorAs[T error]() ErrorMatcher {
+	return func(err error) bool {
+		var target T
+
+		return errors.As(err, &target)
+	}
+}
+
+// GrpcErrorRegistry maps the registered errors (for example domain errors) to gRPC status codes, to be consulted by
+// the [GrpcErrorInterceptor].
+type GrpcErrorRegistry struct {
+	entries []grpcErrorRegistryEntry
+	mutex   sync.RWMutex
+}
+
+type grpcErrorRegistryEntry struct {
+	matcher ErrorMatcher
+	code    codes.Code
+}
+
+// NewGrpcErrorRegistry returns a new empty [GrpcErrorRegistry].
+func NewGrpcErrorRegistry() *GrpcErrorRegistry {
+	return &GrpcErrorRegistry{
+		entries: []grpcErrorRegistryEntry{},
+	}
+}
+
+// Register maps the errors matched by an [ErrorMatcher] to a gRPC status code. The matchers are tried in their
+// registration order.
+func (r *GrpcErrorRegistry) Register(matcher ErrorMatcher, code codes.Code) *GrpcErrorRegistry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = append(r.entries, grpcErrorRegistryEntry{
+		matcher: matcher,
+		code:    code,
+	})
+
+	return r
+}
+
+// RegisterIs maps the errors wrapping a target error to a gRPC status code (see [GrpcErrorRegistry.Register]).
+func (r *GrpcErrorRegistry) RegisterIs(target error, code codes.Code) *GrpcErrorRegistry {
+	return r.Register(MatchErrorIs(target), code)
+}
+
+// Resolve returns the gRPC status code of the first registration matching an error, or false if none matches.
+func (r *GrpcErrorRegistry) Resolve(err error) (codes.Code, bool) {
+	if err == nil {
+		return codes.OK, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, entry := range r.entries {
+		if entry.matcher(err) {
+			return entry.code, true
+		}
+	}
+
+	return codes.Unknown, false
+}
+
+// GrpcErrorInterceptor is a gRPC unary and stream server interceptor converting the plain errors returned by the
+// handlers into gRPC status errors, with the code mapped by its [GrpcErrorRegistry] and the error message.
+//
+// The unmapped errors are converted into [codes.Internal] status errors, with a generic message unless in debug mode,
+// and the context errors into [codes.Canceled] or [codes.DeadlineExceeded] ones. The status errors are returned as is.
+//
+// The converted errors keep the returned error message, for the interceptors logging them before (like the
+// [GrpcLoggerInterceptor], logging the converted status code).
+type GrpcErrorInterceptor struct {
+	registry *GrpcErrorRegistry
+	debug    bool
+}
+
+// NewGrpcErrorInterceptor returns a new [GrpcErrorInterceptor] instance, for a provided [GrpcErrorRegistry].
+func NewGrpcErrorInterceptor(registry *GrpcErrorRegistry) *GrpcErrorInterceptor {
+	return &GrpcErrorInterceptor{
+		registry: registry,
+	}
+}
+
+// Debug is used to expose the unmapped errors message to the clients (not suitable for production).
+func (i *GrpcErrorInterceptor) Debug(debug bool) *GrpcErrorInterceptor {
+	i.debug = debug
+
+	return i
+}
+
+// UnaryInterceptor handles the unary requests.
+func (i *GrpcErrorInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+
+		return resp, i.convert(err)
+	}
+}
+
+// StreamInterceptor handles the stream requests.
+func (i *GrpcErrorInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return i.convert(handler(srv, ss))
+	}
+}
+
+// convert returns the gRPC status error of an error returned by a handler.
+func (i *GrpcErrorInterceptor) convert(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	if code, ok := i.registry.Resolve(err); ok {
+		return &grpcStatusError{
+			err:    err,
+			status: status.New(code, err.Error()),
+		}
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return &grpcStatusError{
+			err:    err,
+			status: status.FromContextError(err),
+		}
+	}
+
+	message := "internal grpc server error"
+	if i.debug {
+		message = err.Error()
+	}
+
+	return &grpcStatusError{
+		err:    err,
+		status: status.New(codes.Internal, message),
+	}
+}
+
+// grpcStatusError is an error converted into a gRPC status, keeping the converted error message.
+type grpcStatusError struct {
+	err    error
+	status *status.Status
+}
+
+// Error returns the converted error message.
+func (e *grpcStatusError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the converted error.
+func (e *grpcStatusError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns the gRPC status of the converted error.
+func (e *grpcStatusError) GRPCStatus() *status.Status {
+	return e.status
+}
@@ -0,0 +1,222 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ankorstore/yokai/generate/generatetest/uuid"
+	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errOrderNotFound  = errors.New("order not found")
+	errOrderConflict  = errors.New("order already exists")
+	errOrderSensitive = errors.New("order database password is secret")
+)
+
+type orderQuotaError struct {
+	limit int
+}
+
+func (e *orderQuotaError) Error() string {
+	return fmt.Sprintf("order quota of %d exceeded", e.limit)
+}
+
+func testGrpcErrorRegistry() *grpcserver.GrpcErrorRegistry {
+	return grpcserver.NewGrpcErrorRegistry().
+		RegisterIs(errOrderNotFound, codes.NotFound).
+		RegisterIs(errOrderConflict, codes.AlreadyExists).
+		Register(grpcserver.MatchErrorAs[*orderQuotaError](), codes.ResourceExhausted)
+}
+
+func TestGrpcErrorRegistryResolve(t *testing.T) {
+	t.Parallel()
+
+	registry := testGrpcErrorRegistry().RegisterIs(errOrderNotFound, codes.Unavailable)
+
+	code, ok := registry.Resolve(errOrderNotFound)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, code)
+
+	code, ok = registry.Resolve(fmt.Errorf("cannot create: %w", errOrderConflict))
+	assert.True(t, ok)
+	assert.Equal(t, codes.AlreadyExists, code)
+
+	code, ok = registry.Resolve(&orderQuotaError{limit: 10})
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, code)
+
+	_, ok = registry.Resolve(errOrderSensitive)
+	assert.False(t, ok)
+
+	_, ok = registry.Resolve(nil)
+	assert.False(t, ok)
+}
+
+func TestGrpcErrorInterceptorUnary(t *testing.T) {
+	t.Parallel()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"}
+
+	tests := []struct {
+		name            string
+		debug           bool
+		err             error
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{
+			name:            "mapped error",
+			err:             errOrderNotFound,
+			expectedCode:    codes.NotFound,
+			expectedMessage: "order not found",
+		},
+		{
+			name:            "mapped wrapped error",
+			err:             fmt.Errorf("cannot create: %w", errOrderConflict),
+			expectedCode:    codes.AlreadyExists,
+			expectedMessage: "cannot create: order already exists",
+		},
+		{
+			name:            "mapped error type",
+			err:             &orderQuotaError{limit: 10},
+			expectedCode:    codes.ResourceExhausted,
+			expectedMessage: "order quota of 10 exceeded",
+		},
+		{
+			name:            "status error",
+			err:             status.Error(codes.PermissionDenied, "denied"),
+			expectedCode:    codes.PermissionDenied,
+			expectedMessage: "denied",
+		},
+		{
+			name:            "context error",
+			err:             fmt.Errorf("cannot fetch: %w", context.DeadlineExceeded),
+			expectedCode:    codes.DeadlineExceeded,
+			expectedMessage: "cannot fetch: context deadline exceeded",
+		},
+		{
+			name:            "unmapped error",
+			err:             errOrderSensitive,
+			expectedCode:    codes.Internal,
+			expectedMessage: "internal grpc server error",
+		},
+		{
+			name:            "unmapped error with debug",
+			debug:           true,
+			err:             errOrderSensitive,
+			expectedCode:    codes.Internal,
+			expectedMessage: "order database password is secret",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			interceptor := grpcserver.NewGrpcErrorInterceptor(testGrpcErrorRegistry()).Debug(tt.debug)
+
+			resp, err := interceptor.UnaryInterceptor()(context.Background(), "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.err
+			})
+			assert.Nil(t, resp)
+
+			st, ok := status.FromError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expectedCode, st.Code())
+			assert.Equal(t, tt.expectedMessage, st.Message())
+
+			// the returned error is still matching
+			assert.True(t, errors.Is(err, tt.err))
+		})
+	}
+
+	// success
+	interceptor := grpcserver.NewGrpcErrorInterceptor(testGrpcErrorRegistry())
+
+	resp, err := interceptor.UnaryInterceptor()(context.Background(), "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestGrpcErrorInterceptorStream(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcErrorInterceptor(testGrpcErrorRegistry())
+
+	stream := &testServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Bidi"}
+
+	err := interceptor.StreamInterceptor()(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return errOrderNotFound
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "order not found", status.Convert(err).Message())
+
+	err = interceptor.StreamInterceptor()(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return errOrderSensitive
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "internal grpc server error", status.Convert(err).Message())
+
+	err = interceptor.StreamInterceptor()(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestGrpcErrorInterceptorWithLoggerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(log.WithOutputWriter(logBuffer))
+	assert.NoError(t, err)
+
+	loggerInterceptor := grpcserver.NewGrpcLoggerInterceptor(uuid.NewTestUuidGenerator("generated"), logger)
+	errorInterceptor := grpcserver.NewGrpcErrorInterceptor(testGrpcErrorRegistry())
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"}
+
+	for _, handlerErr := range []error{errOrderNotFound, errOrderSensitive} {
+		handlerErr := handlerErr
+
+		_, err = loggerInterceptor.UnaryInterceptor()(context.Background(), "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return errorInterceptor.UnaryInterceptor()(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, handlerErr
+			})
+		})
+		assert.Error(t, err)
+	}
+
+	// the mapped status is logged, with the returned error
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":      "error",
+		"grpcMethod": "/test.Service/Unary",
+		"grpcCode":   float64(codes.NotFound),
+		"grpcStatus": "NotFound",
+		"error":      "order not found",
+		"message":    "grpc call error",
+	})
+
+	// the unmapped errors are logged as internal, with their message
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":      "error",
+		"grpcMethod": "/test.Service/Unary",
+		"grpcCode":   float64(codes.Internal),
+		"grpcStatus": "Internal",
+		"error":      "order database password is secret",
+		"message":    "grpc call error",
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ankorstore/yokai/healthcheck"
 	"google.golang.org/grpc/codes"
@@ -11,35 +13,100 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// DefaultHealthCheckWatchInterval is the default interval of the checker re-evaluation for the watch streams.
+const DefaultHealthCheckWatchInterval = 5 * time.Second
+
 // GrpcHealthCheckService is a default gRPC health check server implementation working with the [healthcheck.Checker].
+//
+// The status of a service is resolved from its serving status if set at runtime (see SetServingStatus), or else from
+// the [healthcheck.Checker] results of the probe kind matching its name (liveness, readiness or startup by default).
 type GrpcHealthCheckService struct {
 	grpc_health_v1.UnimplementedHealthServer
-	checker *healthcheck.Checker
+	checker       *healthcheck.Checker
+	watchInterval time.Duration
+	mutex         sync.RWMutex
+	shutdown      bool
+	statuses      map[string]grpc_health_v1.HealthCheckResponse_ServingStatus
+	watchers      map[string]map[chan struct{}]struct{}
 }
 
 // NewGrpcHealthCheckService returns a new [GrpcHealthCheckService] instance.
 func NewGrpcHealthCheckService(checker *healthcheck.Checker) *GrpcHealthCheckService {
 	return &GrpcHealthCheckService{
-		checker: checker,
+		checker:       checker,
+		watchInterval: DefaultHealthCheckWatchInterval,
+		statuses:      map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{},
+		watchers:      map[string]map[chan struct{}]struct{}{},
 	}
 }
 
+// WatchInterval configures the interval of the checker re-evaluation for the watch streams (5s by default).
+func (s *GrpcHealthCheckService) WatchInterval(interval time.Duration) *GrpcHealthCheckService {
+	if interval > 0 {
+		s.watchInterval = interval
+	}
+
+	return s
+}
+
+// SetServingStatus sets at runtime the serving status of a given service (for example NOT_SERVING during a drain),
+// taking precedence over the checker results, and notifies its watchers.
+func (s *GrpcHealthCheckService) SetServingStatus(service string, servingStatus grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.mutex.Lock()
+	s.statuses[service] = servingStatus
+	s.mutex.Unlock()
+
+	s.notify(service)
+}
+
+// ClearServingStatus clears the serving status of a given service set at runtime, to resolve it again from the
+// checker results, and notifies its watchers.
+func (s *GrpcHealthCheckService) ClearServingStatus(service string) {
+	s.mutex.Lock()
+	delete(s.statuses, service)
+	s.mutex.Unlock()
+
+	s.notify(service)
+}
+
+// Shutdown sets all services as NOT_SERVING, whatever their serving status or checker results, and notifies all
+// watchers: meant to be called when the server is about to stop.
+func (s *GrpcHealthCheckService) Shutdown() {
+	s.mutex.Lock()
+	s.shutdown = true
+	s.mutex.Unlock()
+
+	s.notify("")
+}
+
+// Resume ends a previous Shutdown, and notifies all watchers.
+func (s *GrpcHealthCheckService) Resume() {
+	s.mutex.Lock()
+	s.shutdown = false
+	s.mutex.Unlock()
+
+	s.notify("")
+}
+
 // Check performs checks on the registered [healthcheck.CheckerProbe].
 func (s *GrpcHealthCheckService) Check(ctx context.Context, in *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
 	logger := CtxLogger(ctx)
 
-	serviceName := strings.ToLower(in.Service)
+	if servingStatus, ok := s.servingStatus(in.Service); ok {
+		logger.
+			Info().
+			Str("caller", in.Service).
+			Str("status", servingStatus.String()).
+			Msg("grpc health check serving status")
 
-	var kind healthcheck.ProbeKind
-	switch {
-	case strings.Contains(serviceName, healthcheck.Liveness.String()):
-		kind = healthcheck.Liveness
-	case strings.Contains(serviceName, healthcheck.Readiness.String()):
-		kind = healthcheck.Readiness
-	default:
-		kind = healthcheck.Startup
+		return &grpc_health_v1.HealthCheckResponse{
+			Status: servingStatus,
+		}, nil
 	}
 
+	serviceName := strings.ToLower(in.Service)
+	kind := probeKind(serviceName)
+
 	result := s.checker.Check(ctx, kind)
 	if !result.Success {
 		evt := logger.Error()
@@ -69,12 +136,124 @@ func (s *GrpcHealthCheckService) Check(ctx context.Context, in *grpc_health_v1.H
 	}, nil
 }
 
-// Watch is not implemented.
+// Watch streams the status of a service: the current one first, and then each status transition (from a serving
+// status change, or from the checker results re-evaluated every watch interval), until the client cancels the stream.
 func (s *GrpcHealthCheckService) Watch(in *grpc_health_v1.HealthCheckRequest, watchServer grpc_health_v1.Health_WatchServer) error {
-	CtxLogger(watchServer.Context()).
-		Warn().
-		Str("caller", in.Service).
-		Msg("grpc health watch not implemented")
+	ctx := watchServer.Context()
+	logger := CtxLogger(ctx)
+
+	update := make(chan struct{}, 1)
 
-	return status.Error(codes.Unimplemented, "watch is not implemented")
+	s.addWatcher(in.Service, update)
+	defer s.removeWatcher(in.Service, update)
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	lastStatus := grpc_health_v1.HealthCheckResponse_ServingStatus(-1)
+
+	for {
+		currentStatus := s.watchedStatus(ctx, in.Service)
+
+		if currentStatus != lastStatus {
+			err := watchServer.Send(&grpc_health_v1.HealthCheckResponse{
+				Status: currentStatus,
+			})
+			if err != nil {
+				return status.Error(codes.Canceled, "watch stream has ended")
+			}
+
+			logger.
+				Info().
+				Str("caller", in.Service).
+				Str("status", currentStatus.String()).
+				Msg("grpc health watch status")
+
+			lastStatus = currentStatus
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.Error(codes.Canceled, "watch stream has ended")
+		case <-update:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *GrpcHealthCheckService) servingStatus(service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.shutdown {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING, true
+	}
+
+	servingStatus, ok := s.statuses[service]
+
+	return servingStatus, ok
+}
+
+func (s *GrpcHealthCheckService) watchedStatus(ctx context.Context, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if servingStatus, ok := s.servingStatus(service); ok {
+		return servingStatus
+	}
+
+	if s.checker.Check(ctx, probeKind(strings.ToLower(service))).Success {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
+
+func (s *GrpcHealthCheckService) addWatcher(service string, update chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.watchers[service]; !ok {
+		s.watchers[service] = map[chan struct{}]struct{}{}
+	}
+
+	s.watchers[service][update] = struct{}{}
+}
+
+func (s *GrpcHealthCheckService) removeWatcher(service string, update chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.watchers[service], update)
+	if len(s.watchers[service]) == 0 {
+		delete(s.watchers, service)
+	}
+}
+
+// notify notifies the watchers of a given service, or all watchers if empty.
+func (s *GrpcHealthCheckService) notify(service string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for watchedService, updates := range s.watchers {
+		if service != "" && watchedService != service {
+			continue
+		}
+
+		for update := range updates {
+			// non-blocking, a pending update being enough to re-evaluate the status
+			select {
+			case update <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func probeKind(serviceName string) healthcheck.ProbeKind {
+	switch {
+	case strings.Contains(serviceName, healthcheck.Liveness.String()):
+		return healthcheck.Liveness
+	case strings.Contains(serviceName, healthcheck.Readiness.String()):
+		return healthcheck.Readiness
+	default:
+		return healthcheck.Startup
+	}
 }
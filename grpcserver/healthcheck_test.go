@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/generate/generatetest/uuid"
 	"github.com/ankorstore/yokai/grpcserver"
@@ -14,8 +15,10 @@ import (
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 func TestCheckSuccess(t *testing.T) {
@@ -117,6 +120,69 @@ func TestCheckFailure(t *testing.T) {
 	})
 }
 
+func TestCheckWithServingStatus(t *testing.T) {
+	t.Parallel()
+
+	// checker
+	checker, err := healthcheck.NewDefaultCheckerFactory().Create(
+		healthcheck.WithProbe(probes.NewSuccessProbe()),
+	)
+	assert.NoError(t, err)
+
+	// logger
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	// client
+	service := grpcserver.NewGrpcHealthCheckService(checker)
+
+	client, closer := prepareGrpcServerAndClientForHealthCheckService(t, service, logger)
+	defer closer()
+
+	// serving status call assertions
+	service.SetServingStatus("test", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	response, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "test"})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"caller":  "test",
+		"status":  "NOT_SERVING",
+		"message": "grpc health check serving status",
+	})
+
+	// other services call assertions
+	response, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	// cleared serving status call assertions
+	service.ClearServingStatus("test")
+
+	response, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "test"})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	// shutdown call assertions
+	service.Shutdown()
+
+	response, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	// resume call assertions
+	service.Resume()
+
+	response, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+}
+
 func TestWatch(t *testing.T) {
 	t.Parallel()
 
@@ -134,28 +200,99 @@ func TestWatch(t *testing.T) {
 	assert.NoError(t, err)
 
 	// client
-	client, closer := prepareHealthCheckServiceGrpcServerAndClient(t, checker, logger)
+	service := grpcserver.NewGrpcHealthCheckService(checker).WatchInterval(time.Hour)
+
+	client, closer := prepareGrpcServerAndClientForHealthCheckService(t, service, logger)
 	defer closer()
 
-	// call assertions
-	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "test"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "test"})
+	assert.NoError(t, err)
+
+	// initial status assertions
+	response, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	// status transition assertions
+	service.SetServingStatus("test", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	response, err = stream.Recv()
 	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	service.ClearServingStatus("test")
+
+	response, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	// cancellation assertions
+	cancel()
 
 	_, err = stream.Recv()
 	assert.Error(t, err)
-	assert.Equal(t, "rpc error: code = Unimplemented desc = watch is not implemented", err.Error())
+	assert.Equal(t, codes.Canceled, status.Code(err))
 
 	// logs assertions
 	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
-		"level":   "warn",
+		"level":   "info",
 		"caller":  "test",
-		"message": "grpc health watch not implemented",
+		"status":  "NOT_SERVING",
+		"message": "grpc health watch status",
 	})
 }
 
+func TestWatchWithCheckerTransition(t *testing.T) {
+	t.Parallel()
+
+	// checker
+	probe := probes.NewSwitchableProbe()
+
+	checker, err := healthcheck.NewDefaultCheckerFactory().Create(
+		healthcheck.WithProbe(probe, healthcheck.Readiness),
+	)
+	assert.NoError(t, err)
+
+	// logger
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logtest.NewDefaultTestLogBuffer()),
+	)
+	assert.NoError(t, err)
+
+	// client
+	service := grpcserver.NewGrpcHealthCheckService(checker).WatchInterval(10 * time.Millisecond)
+
+	client, closer := prepareGrpcServerAndClientForHealthCheckService(t, service, logger)
+	defer closer()
+
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "test::readiness"})
+	assert.NoError(t, err)
+
+	// initial status assertions
+	response, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	// checker transition assertions
+	probe.Fail()
+
+	response, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+}
+
 func prepareHealthCheckServiceGrpcServerAndClient(t *testing.T, checker *healthcheck.Checker, logger *log.Logger) (grpc_health_v1.HealthClient, func()) {
 	t.Helper()
 
+	return prepareGrpcServerAndClientForHealthCheckService(t, grpcserver.NewGrpcHealthCheckService(checker), logger)
+}
+
+func prepareGrpcServerAndClientForHealthCheckService(t *testing.T, service *grpcserver.GrpcHealthCheckService, logger *log.Logger) (grpc_health_v1.HealthClient, func()) {
+	t.Helper()
+
 	// context preparation
 	ctx := logger.WithContext(context.Background())
 
@@ -172,7 +309,7 @@ func prepareHealthCheckServiceGrpcServerAndClient(t *testing.T, checker *healthc
 
 	server.RegisterService(
 		&grpc_health_v1.Health_ServiceDesc,
-		grpcserver.NewGrpcHealthCheckService(checker),
+		service,
 	)
 
 	go func() {
@@ -2,11 +2,13 @@ package grpcserver
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/ankorstore/yokai/generate/uuid"
 	"github.com/ankorstore/yokai/log"
 	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -15,26 +17,70 @@ import (
 )
 
 const (
-	HeaderXRequestId  = "x-request-id"
-	LogFieldRequestId = "requestID"
+	HeaderXRequestId     = "x-request-id"
+	LogFieldRequestId    = "requestID"
+	LogFieldGrpcType     = "grpcType"
+	LogFieldGrpcMethod   = "grpcMethod"
+	LogFieldGrpcCode     = "grpcCode"
+	LogFieldGrpcStatus   = "grpcStatus"
+	LogFieldGrpcDuration = "grpcDuration"
+	LogFieldTraceId      = "traceID"
+	LogFieldSpanId       = "spanID"
 )
 
+// LogFieldsFunc returns additional fields to add to the logs of the end of a gRPC call, from its context, full method
+// name and error (nil on success).
+type LogFieldsFunc func(ctx context.Context, fullMethod string, err error) map[string]interface{}
+
+// DefaultLogLevelFromCode returns the default log level of the end of a gRPC call: info for OK, error otherwise.
+func DefaultLogLevelFromCode(code codes.Code) zerolog.Level {
+	if code == codes.OK {
+		return zerolog.InfoLevel
+	}
+
+	return zerolog.ErrorLevel
+}
+
 // GrpcLoggerInterceptor is a gRPC unary and stream server interceptor to produce correlated logs.
 type GrpcLoggerInterceptor struct {
-	generator  uuid.UuidGenerator
-	logger     *log.Logger
-	metadata   map[string]string
-	exclusions []string
+	generator    uuid.UuidGenerator
+	logger       *log.Logger
+	requestIdKey string
+	metadata     map[string]string
+	exclusions   []string
+	levels       map[codes.Code]zerolog.Level
+	fieldNames   map[string]string
+	fieldsFunc   LogFieldsFunc
+	sampler      zerolog.Sampler
+	disabled     bool
 }
 
 // NewGrpcLoggerInterceptor returns a new [GrpcLoggerInterceptor] instance.
 func NewGrpcLoggerInterceptor(generator uuid.UuidGenerator, logger *log.Logger) *GrpcLoggerInterceptor {
 	return &GrpcLoggerInterceptor{
-		generator:  generator,
-		logger:     logger,
-		metadata:   map[string]string{HeaderXRequestId: LogFieldRequestId},
-		exclusions: []string{},
+		generator:    generator,
+		logger:       logger,
+		requestIdKey: HeaderXRequestId,
+		metadata:     map[string]string{HeaderXRequestId: LogFieldRequestId},
+		exclusions:   []string{},
+		levels:       map[codes.Code]zerolog.Level{},
+		fieldNames:   map[string]string{},
+	}
+}
+
+// RequestIdMetadataKey configures the metadata key of the request id (x-request-id by default).
+func (i *GrpcLoggerInterceptor) RequestIdMetadataKey(key string) *GrpcLoggerInterceptor {
+	key = strings.ToLower(key)
+	if key == "" || key == i.requestIdKey {
+		return i
 	}
+
+	delete(i.metadata, i.requestIdKey)
+
+	i.requestIdKey = key
+	i.metadata[key] = LogFieldRequestId
+
+	return i
 }
 
 // Metadata configures a list of metadata to log from incoming context.
@@ -53,110 +99,77 @@ func (i *GrpcLoggerInterceptor) Exclude(methods ...string) *GrpcLoggerIntercepto
 	return i
 }
 
+// LogLevels configures the log level of the end of the gRPC calls per status code, overriding the default ones (see
+// [DefaultLogLevelFromCode]), for example to log NotFound at info level. The zerolog.Disabled level disables the logs.
+func (i *GrpcLoggerInterceptor) LogLevels(levels map[codes.Code]zerolog.Level) *GrpcLoggerInterceptor {
+	for code, level := range levels {
+		i.levels[code] = level
+	}
+
+	return i
+}
+
+// FieldNames configures the renaming of the logged fields, from their default names (like grpcMethod, see the LogField
+// constants) to custom ones.
+func (i *GrpcLoggerInterceptor) FieldNames(names map[string]string) *GrpcLoggerInterceptor {
+	for name, renamed := range names {
+		i.fieldNames[name] = renamed
+	}
+
+	return i
+}
+
+// Fields configures a function returning additional fields to add to the logs of the end of the gRPC calls.
+func (i *GrpcLoggerInterceptor) Fields(fn LogFieldsFunc) *GrpcLoggerInterceptor {
+	i.fieldsFunc = fn
+
+	return i
+}
+
+// Sampler configures a sampler replacing the one of the logger for the logs of the gRPC calls only (for example to
+// sample the high-volume access logs more than the application logs).
+func (i *GrpcLoggerInterceptor) Sampler(sampler zerolog.Sampler) *GrpcLoggerInterceptor {
+	i.sampler = sampler
+
+	return i
+}
+
+// DisableLogs disables the logs of the gRPC calls (including the failed ones), the request id being still handled and
+// the correlated logger still stored in the context.
+func (i *GrpcLoggerInterceptor) DisableLogs() *GrpcLoggerInterceptor {
+	i.disabled = true
+
+	return i
+}
+
 // UnaryInterceptor handles the unary requests.
 //
-//nolint:cyclop,dupl,gocognit,nestif
+// The request id is read from the incoming metadata (or generated if absent), stored in the context (see
+// [CtxRequestId]), propagated in the outgoing metadata and baggage of the context, and returned to the client in the
+// trailers.
 func (i *GrpcLoggerInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		exclude := Contains(i.exclusions, info.FullMethod)
+		exclude := i.disabled || Contains(i.exclusions, info.FullMethod)
 
-		grpcLogger := i.logger.With().Fields(i.extractLogFieldsFromContextMetadata(ctx)).Logger()
+		requestId := i.extractRequestIdFromContextMetadata(ctx)
 
-		newCtx := grpcLogger.WithContext(ctx)
+		grpcLogger := i.logger.With().Fields(i.extractLogFieldsFromContextMetadata(ctx, requestId)).Logger()
 
-		spanContext := trace.SpanContextFromContext(newCtx)
+		newCtx := i.propagateRequestId(grpcLogger.WithContext(ctx), requestId)
 
-		traceId := ""
-		if spanContext.HasTraceID() {
-			traceId = spanContext.TraceID().String()
-		}
-
-		spanId := ""
-		if spanContext.HasSpanID() {
-			spanId = spanContext.SpanID().String()
-		}
+		//nolint:errcheck
+		grpc.SetTrailer(ctx, metadata.Pairs(i.requestIdKey, requestId))
 
 		if !exclude {
-			evt := grpcLogger.
-				Debug().
-				Str("grpcType", "unary").
-				Str("grpcMethod", info.FullMethod)
-
-			if traceId != "" {
-				evt.Str("traceID", traceId)
-			}
-
-			if spanId != "" {
-				evt.Str("spanID", spanId)
-			}
-
-			evt.Msg("grpc call start")
+			i.logCallStart(newCtx, &grpcLogger, zerolog.DebugLevel, "unary", info.FullMethod)
 		}
 
 		now := time.Now()
 
 		resp, err := handler(newCtx, req)
 
-		errStatus := status.Convert(err)
-
-		if !exclude {
-			if err != nil {
-				evt := grpcLogger.
-					Error().
-					Err(err).
-					Str("grpcType", "unary").
-					Str("grpcMethod", info.FullMethod).
-					Int32("grpcCode", int32(errStatus.Code())).
-					Str("grpcStatus", errStatus.Code().String()).
-					Str("grpcDuration", time.Since(now).String())
-
-				if traceId != "" {
-					evt.Str("traceID", traceId)
-				}
-
-				if spanId != "" {
-					evt.Str("spanID", spanId)
-				}
-
-				evt.Msg("grpc call error")
-			} else {
-				evt := grpcLogger.
-					Info().
-					Str("grpcType", "unary").
-					Str("grpcMethod", info.FullMethod).
-					Int32("grpcCode", int32(codes.OK)).
-					Str("grpcStatus", codes.OK.String()).
-					Str("grpcDuration", time.Since(now).String())
-
-				if traceId != "" {
-					evt.Str("traceID", traceId)
-				}
-
-				if spanId != "" {
-					evt.Str("spanID", spanId)
-				}
-
-				evt.Msg("grpc call success")
-			}
-		} else if err != nil {
-			evt := grpcLogger.
-				Error().
-				Err(err).
-				Str("grpcType", "unary").
-				Str("grpcMethod", info.FullMethod).
-				Int32("grpcCode", int32(errStatus.Code())).
-				Str("grpcStatus", errStatus.Code().String()).
-				Str("grpcDuration", time.Since(now).String())
-
-			if traceId != "" {
-				evt.Str("traceID", traceId)
-			}
-
-			if spanId != "" {
-				evt.Str("spanID", spanId)
-			}
-
-			evt.Msg("grpc call error")
+		if !exclude || (err != nil && !i.disabled) {
+			i.logCallEnd(newCtx, &grpcLogger, "unary", info.FullMethod, now, err)
 		}
 
 		return resp, err
@@ -165,47 +178,26 @@ func (i *GrpcLoggerInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
 
 // StreamInterceptor handles the stream requests.
 //
-//nolint:cyclop,dupl,gocognit,nestif
+// The request id is handled the same way as in the UnaryInterceptor.
 func (i *GrpcLoggerInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		ctx := ss.Context()
 
-		exclude := Contains(i.exclusions, info.FullMethod)
+		exclude := i.disabled || Contains(i.exclusions, info.FullMethod)
+
+		requestId := i.extractRequestIdFromContextMetadata(ctx)
 
 		grpcLogger := i.logger.
 			With().
-			Fields(i.extractLogFieldsFromContextMetadata(ctx)).
+			Fields(i.extractLogFieldsFromContextMetadata(ctx, requestId)).
 			Logger()
 
-		newCtx := grpcLogger.WithContext(ctx)
-
-		spanContext := trace.SpanContextFromContext(newCtx)
-
-		traceId := ""
-		if spanContext.HasTraceID() {
-			traceId = spanContext.TraceID().String()
-		}
+		newCtx := i.propagateRequestId(grpcLogger.WithContext(ctx), requestId)
 
-		spanId := ""
-		if spanContext.HasSpanID() {
-			spanId = spanContext.SpanID().String()
-		}
+		ss.SetTrailer(metadata.Pairs(i.requestIdKey, requestId))
 
 		if !exclude {
-			evt := grpcLogger.
-				Info().
-				Str("grpcType", "server-streaming").
-				Str("grpcMethod", info.FullMethod)
-
-			if traceId != "" {
-				evt.Str("traceID", traceId)
-			}
-
-			if spanId != "" {
-				evt.Str("spanID", spanId)
-			}
-
-			evt.Msg("grpc call start")
+			i.logCallStart(newCtx, &grpcLogger, zerolog.InfoLevel, "server-streaming", info.FullMethod)
 		}
 
 		wrappedStream := &middleware.WrappedServerStream{
@@ -217,83 +209,135 @@ func (i *GrpcLoggerInterceptor) StreamInterceptor() grpc.StreamServerInterceptor
 
 		err := handler(srv, wrappedStream)
 
-		errStatus := status.Convert(err)
-
-		if !exclude {
-			if err != nil {
-				evt := grpcLogger.
-					Error().
-					Err(err).
-					Str("grpcType", "server-streaming").
-					Str("grpcMethod", info.FullMethod).
-					Int32("grpcCode", int32(errStatus.Code())).
-					Str("grpcStatus", errStatus.Code().String()).
-					Str("grpcDuration", time.Since(now).String())
-
-				if traceId != "" {
-					evt.Str("traceID", traceId)
-				}
-
-				if spanId != "" {
-					evt.Str("spanID", spanId)
-				}
-
-				evt.Msg("grpc call error")
-			} else {
-				evt := grpcLogger.
-					Info().
-					Str("grpcType", "server-streaming").
-					Str("grpcMethod", info.FullMethod).
-					Int32("grpcCode", int32(codes.OK)).
-					Str("grpcStatus", codes.OK.String()).
-					Str("grpcDuration", time.Since(now).String())
-
-				if traceId != "" {
-					evt.Str("traceID", traceId)
-				}
-
-				if spanId != "" {
-					evt.Str("spanID", spanId)
-				}
-
-				evt.Msg("grpc call success")
-			}
-		} else if err != nil {
-			evt := grpcLogger.
-				Error().
-				Err(err).
-				Str("grpcType", "server-streaming").
-				Str("grpcMethod", info.FullMethod).
-				Int32("grpcCode", int32(errStatus.Code())).
-				Str("grpcStatus", errStatus.Code().String()).
-				Str("grpcDuration", time.Since(now).String())
-
-			if traceId != "" {
-				evt.Str("traceID", traceId)
-			}
-
-			if spanId != "" {
-				evt.Str("spanID", spanId)
-			}
-
-			evt.Msg("grpc call error")
+		if !exclude || (err != nil && !i.disabled) {
+			i.logCallEnd(newCtx, &grpcLogger, "server-streaming", info.FullMethod, now, err)
 		}
 
 		return err
 	}
 }
 
-func (i *GrpcLoggerInterceptor) extractLogFieldsFromContextMetadata(ctx context.Context) map[string]interface{} {
+func (i *GrpcLoggerInterceptor) logCallStart(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	level zerolog.Level,
+	grpcType string,
+	fullMethod string,
+) {
+	evt := i.sampled(logger).
+		WithLevel(level).
+		Str(i.fieldName(LogFieldGrpcType), grpcType).
+		Str(i.fieldName(LogFieldGrpcMethod), fullMethod)
+
+	i.withTraceFields(ctx, evt).Msg("grpc call start")
+}
+
+func (i *GrpcLoggerInterceptor) logCallEnd(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	grpcType string,
+	fullMethod string,
+	start time.Time,
+	err error,
+) {
+	code := status.Code(err)
+
+	evt := i.sampled(logger).WithLevel(i.levelFromCode(code))
+	if err != nil {
+		evt.Err(err)
+	}
+
+	evt.
+		Str(i.fieldName(LogFieldGrpcType), grpcType).
+		Str(i.fieldName(LogFieldGrpcMethod), fullMethod).
+		Int32(i.fieldName(LogFieldGrpcCode), int32(code)).
+		Str(i.fieldName(LogFieldGrpcStatus), code.String()).
+		Str(i.fieldName(LogFieldGrpcDuration), time.Since(start).String())
+
+	if i.fieldsFunc != nil {
+		evt.Fields(i.fieldsFunc(ctx, fullMethod, err))
+	}
+
+	if err != nil {
+		i.withTraceFields(ctx, evt).Msg("grpc call error")
+	} else {
+		i.withTraceFields(ctx, evt).Msg("grpc call success")
+	}
+}
+
+// sampled returns the logger of the gRPC calls logs, with the configured sampler if any.
+func (i *GrpcLoggerInterceptor) sampled(logger *zerolog.Logger) *zerolog.Logger {
+	if i.sampler == nil {
+		return logger
+	}
+
+	sampledLogger := logger.Sample(i.sampler)
+
+	return &sampledLogger
+}
+
+func (i *GrpcLoggerInterceptor) withTraceFields(ctx context.Context, evt *zerolog.Event) *zerolog.Event {
+	spanContext := trace.SpanContextFromContext(ctx)
+
+	if spanContext.HasTraceID() {
+		evt.Str(i.fieldName(LogFieldTraceId), spanContext.TraceID().String())
+	}
+
+	if spanContext.HasSpanID() {
+		evt.Str(i.fieldName(LogFieldSpanId), spanContext.SpanID().String())
+	}
+
+	return evt
+}
+
+func (i *GrpcLoggerInterceptor) levelFromCode(code codes.Code) zerolog.Level {
+	if level, ok := i.levels[code]; ok {
+		return level
+	}
+
+	return DefaultLogLevelFromCode(code)
+}
+
+func (i *GrpcLoggerInterceptor) fieldName(name string) string {
+	if renamed, ok := i.fieldNames[name]; ok && renamed != "" {
+		return renamed
+	}
+
+	return name
+}
+
+func (i *GrpcLoggerInterceptor) extractRequestIdFromContextMetadata(ctx context.Context) string {
+	ctxMd, _ := metadata.FromIncomingContext(ctx)
+
+	if val, ok := ctxMd[i.requestIdKey]; ok && len(val) > 0 && val[0] != "" {
+		return val[0]
+	}
+
+	return i.generator.Generate()
+}
+
+func (i *GrpcLoggerInterceptor) extractLogFieldsFromContextMetadata(ctx context.Context, requestId string) map[string]interface{} {
 	ctxMd, _ := metadata.FromIncomingContext(ctx)
 
 	md := make(map[string]interface{})
 	for mk, mv := range i.metadata {
-		if val, ok := ctxMd[mk]; ok && len(val) > 0 {
+		if mk == i.requestIdKey {
+			md[mv] = requestId
+		} else if val, ok := ctxMd[mk]; ok && len(val) > 0 {
 			md[mv] = val[0]
-		} else if mk == HeaderXRequestId {
-			md[mv] = i.generator.Generate()
 		}
 	}
 
 	return md
 }
+
+func (i *GrpcLoggerInterceptor) propagateRequestId(ctx context.Context, requestId string) context.Context {
+	ctx = context.WithValue(ctx, CtxRequestIdKey{}, requestId)
+	ctx = withRequestIdBaggage(ctx, requestId)
+
+	outgoingMd, _ := metadata.FromOutgoingContext(ctx)
+	outgoingMd = outgoingMd.Copy()
+	outgoingMd.Set(i.requestIdKey, requestId)
+
+	return metadata.NewOutgoingContext(ctx, outgoingMd)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/generate/generatetest/uuid"
 	"github.com/ankorstore/yokai/grpcserver"
@@ -18,8 +19,10 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -831,3 +834,324 @@ func prepareTestServiceGrpcServerAndClient(t *testing.T, logger *log.Logger, exc
 
 	return client, closer
 }
+
+func TestUnaryRequestIdPropagationWithIncomingRequestId(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(log.WithOutputWriter(logBuffer))
+	assert.NoError(t, err)
+
+	client, closer := prepareTestServiceGrpcServerAndClient(t, logger, []string{}, map[string]string{}, false)
+	defer closer()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", testRequestId)
+
+	var trailer metadata.MD
+	_, err = client.Unary(ctx, &proto.Request{Message: "test"}, grpc.Trailer(&trailer))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{testRequestId}, trailer.Get("x-request-id"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"requestID": testRequestId,
+		"message":   "grpc call success",
+	})
+}
+
+func TestUnaryRequestIdPropagationWithoutIncomingRequestId(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(log.WithOutputWriter(logBuffer))
+	assert.NoError(t, err)
+
+	client, closer := prepareTestServiceGrpcServerAndClient(t, logger, []string{}, map[string]string{}, false)
+	defer closer()
+
+	var trailer metadata.MD
+	_, err = client.Unary(context.Background(), &proto.Request{Message: "test"}, grpc.Trailer(&trailer))
+	assert.NoError(t, err)
+
+	// generated request id
+	assert.Equal(t, []string{"test"}, trailer.Get("x-request-id"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"requestID": "test",
+		"message":   "grpc call success",
+	})
+}
+
+func TestRequestIdPropagationWithCustomMetadataKey(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(log.WithOutputWriter(logBuffer))
+	assert.NoError(t, err)
+
+	loggerInterceptor := grpcserver.
+		NewGrpcLoggerInterceptor(uuid.NewTestUuidGenerator("generated"), logger).
+		RequestIdMetadataKey("X-Correlation-Id")
+
+	// incoming present
+	ctx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs("x-correlation-id", testRequestId, "x-request-id", "ignored"),
+	)
+
+	_, err = loggerInterceptor.UnaryInterceptor()(
+		ctx,
+		"request",
+		&grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			assert.Equal(t, testRequestId, grpcserver.CtxRequestId(ctx))
+
+			outgoingMd, ok := metadata.FromOutgoingContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, []string{testRequestId}, outgoingMd.Get("x-correlation-id"))
+
+			grpcserver.CtxLogger(ctx).Info().Msg("unary handler")
+
+			return req, nil
+		},
+	)
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"requestID": testRequestId,
+		"message":   "unary handler",
+	})
+
+	// incoming absent
+	stream := &testTrailerServerStream{ctx: context.Background()}
+
+	err = loggerInterceptor.StreamInterceptor()(
+		nil,
+		stream,
+		&grpc.StreamServerInfo{FullMethod: "/test.Service/Bidi"},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			assert.Equal(t, "generated", grpcserver.CtxRequestId(ss.Context()))
+
+			outgoingMd, ok := metadata.FromOutgoingContext(ss.Context())
+			assert.True(t, ok)
+			assert.Equal(t, []string{"generated"}, outgoingMd.Get("x-correlation-id"))
+
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"generated"}, stream.trailer.Get("x-correlation-id"))
+	assert.Empty(t, grpcserver.CtxRequestId(context.Background()))
+}
+
+func TestLogLevelsAndFields(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	loggerInterceptor := grpcserver.
+		NewGrpcLoggerInterceptor(uuid.NewTestUuidGenerator("test"), logger).
+		LogLevels(map[codes.Code]zerolog.Level{
+			codes.NotFound:        zerolog.InfoLevel,
+			codes.InvalidArgument: zerolog.WarnLevel,
+			codes.Canceled:        zerolog.Disabled,
+		}).
+		FieldNames(map[string]string{
+			grpcserver.LogFieldGrpcMethod: "rpcMethod",
+			grpcserver.LogFieldGrpcStatus: "rpcStatus",
+		}).
+		Fields(func(ctx context.Context, fullMethod string, err error) map[string]interface{} {
+			return map[string]interface{}{"failed": err != nil}
+		})
+
+	unary := func(method string, err error) {
+		_, _ = loggerInterceptor.UnaryInterceptor()(
+			context.Background(),
+			"request",
+			&grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, err
+			},
+		)
+	}
+
+	unary("/test.Service/Found", nil)
+	unary("/test.Service/NotFound", status.Error(codes.NotFound, "not found"))
+	unary("/test.Service/InvalidArgument", status.Error(codes.InvalidArgument, "invalid"))
+	unary("/test.Service/Internal", status.Error(codes.Internal, "failure"))
+	unary("/test.Service/Canceled", status.Error(codes.Canceled, "canceled"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "debug",
+		"rpcMethod": "/test.Service/Found",
+		"grpcType":  "unary",
+		"message":   "grpc call start",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"rpcMethod": "/test.Service/Found",
+		"rpcStatus": "OK",
+		"failed":    false,
+		"message":   "grpc call success",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"rpcMethod": "/test.Service/NotFound",
+		"rpcStatus": "NotFound",
+		"error":     "rpc error: code = NotFound desc = not found",
+		"failed":    true,
+		"message":   "grpc call error",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "warn",
+		"rpcMethod": "/test.Service/InvalidArgument",
+		"rpcStatus": "InvalidArgument",
+		"message":   "grpc call error",
+	})
+
+	// default level
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "error",
+		"rpcMethod": "/test.Service/Internal",
+		"rpcStatus": "Internal",
+		"message":   "grpc call error",
+	})
+
+	// disabled level
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"rpcMethod": "/test.Service/Canceled",
+		"message":   "grpc call error",
+	})
+
+	// renamed fields
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"grpcMethod": "/test.Service/Found",
+	})
+}
+
+func TestDisableLogs(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	loggerInterceptor := grpcserver.
+		NewGrpcLoggerInterceptor(uuid.NewTestUuidGenerator("test"), logger).
+		DisableLogs()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", testRequestId))
+
+	_, err = loggerInterceptor.UnaryInterceptor()(
+		ctx,
+		"request",
+		&grpc.UnaryServerInfo{FullMethod: "/test.Service/Internal"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			// request id still propagated
+			assert.Equal(t, testRequestId, grpcserver.CtxRequestId(ctx))
+
+			log.CtxLogger(ctx).Info().Msg("handler log")
+
+			return nil, status.Error(codes.Internal, "failure")
+		},
+	)
+	assert.Error(t, err)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"requestID": testRequestId,
+		"message":   "handler log",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"message": "grpc call start",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"message": "grpc call error",
+	})
+}
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	loggerInterceptor := grpcserver.
+		NewGrpcLoggerInterceptor(uuid.NewTestUuidGenerator("test"), logger).
+		Sampler(&zerolog.LevelSampler{
+			InfoSampler: &zerolog.BurstSampler{Burst: 10, Period: time.Hour},
+		})
+
+	for i := 0; i < 50; i++ {
+		_, err = loggerInterceptor.UnaryInterceptor()(
+			context.Background(),
+			"request",
+			&grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				log.CtxLogger(ctx).Info().Msg("handler log")
+
+				return "response", nil
+			},
+		)
+		assert.NoError(t, err)
+	}
+
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+
+	counts := map[string]int{}
+	for _, record := range records {
+		message, err := record.Message()
+		assert.NoError(t, err)
+
+		counts[message]++
+	}
+
+	// only the gRPC calls logs are sampled
+	assert.Equal(t, 50, counts["grpc call start"])
+	assert.Equal(t, 10, counts["grpc call success"])
+	assert.Equal(t, 50, counts["handler log"])
+}
+
+func TestDefaultLogLevelFromCode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, zerolog.InfoLevel, grpcserver.DefaultLogLevelFromCode(codes.OK))
+	assert.Equal(t, zerolog.ErrorLevel, grpcserver.DefaultLogLevelFromCode(codes.NotFound))
+	assert.Equal(t, zerolog.ErrorLevel, grpcserver.DefaultLogLevelFromCode(codes.Internal))
+}
+
+type testTrailerServerStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	trailer metadata.MD
+}
+
+func (s *testTrailerServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testTrailerServerStream) SetTrailer(md metadata.MD) {
+	s.trailer = metadata.Join(s.trailer, md)
+}
@@ -0,0 +1,163 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	GrpcServerMetricsMessageSize = "grpc_server_message_size_bytes"
+	MessageDirectionRecv         = "recv"
+	MessageDirectionSent         = "sent"
+)
+
+// DefaultMessageSizeBuckets are the default message size histogram buckets, from 64B to 16MB.
+var DefaultMessageSizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
+
+// GrpcMessageSizeInterceptor is a gRPC unary and stream server interceptor to observe the messages sizes per method,
+// and to reject the received messages exceeding a per method size limit with a [codes.ResourceExhausted] status.
+type GrpcMessageSizeInterceptor struct {
+	methodLimits map[string]int
+	histogram    *prometheus.HistogramVec
+}
+
+// NewGrpcMessageSizeInterceptor returns a new [GrpcMessageSizeInterceptor] instance.
+func NewGrpcMessageSizeInterceptor() *GrpcMessageSizeInterceptor {
+	return &GrpcMessageSizeInterceptor{
+		methodLimits: map[string]int{},
+	}
+}
+
+// MethodLimits configures per method name received messages size limits, in bytes (unlimited if zero or negative).
+// The method names are matched case-insensitively.
+func (i *GrpcMessageSizeInterceptor) MethodLimits(limits map[string]int) *GrpcMessageSizeInterceptor {
+	for k, v := range limits {
+		i.methodLimits[strings.ToLower(k)] = v
+	}
+
+	return i
+}
+
+// Metrics registers in a given registry the grpc_server_message_size_bytes histogram, observing the messages sizes
+// per method and direction, with the [DefaultMessageSizeBuckets] if no buckets are provided.
+func (i *GrpcMessageSizeInterceptor) Metrics(
+	registry prometheus.Registerer,
+	namespace string,
+	subsystem string,
+	buckets ...float64,
+) *GrpcMessageSizeInterceptor {
+	if len(buckets) == 0 {
+		buckets = DefaultMessageSizeBuckets
+	}
+
+	histogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      GrpcServerMetricsMessageSize,
+			Help:      "Size of the gRPC messages in bytes",
+			Buckets:   buckets,
+		},
+		[]string{
+			"grpc_method",
+			"grpc_direction",
+		},
+	)
+
+	if err := registry.Register(histogram); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+
+		//nolint:forcetypeassert
+		histogram = are.ExistingCollector.(*prometheus.HistogramVec)
+	}
+
+	i.histogram = histogram
+
+	return i
+}
+
+// UnaryInterceptor handles the unary requests.
+func (i *GrpcMessageSizeInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := i.received(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			i.observe(info.FullMethod, MessageDirectionSent, resp)
+		}
+
+		return resp, err
+	}
+}
+
+// StreamInterceptor handles the stream requests.
+func (i *GrpcMessageSizeInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &messageSizeServerStream{
+			ServerStream: ss,
+			interceptor:  i,
+			method:       info.FullMethod,
+		})
+	}
+}
+
+func (i *GrpcMessageSizeInterceptor) received(ctx context.Context, method string, msg interface{}) error {
+	size := i.observe(method, MessageDirectionRecv, msg)
+
+	if limit := i.methodLimits[strings.ToLower(method)]; limit > 0 && size > limit {
+		CtxLogger(ctx).Warn().Str("grpcMethod", method).Int("size", size).Int("limit", limit).Msg("grpc message rejected by size limit")
+
+		return status.Errorf(codes.ResourceExhausted, "message of %d bytes too large for %s, limit is %d", size, method, limit)
+	}
+
+	return nil
+}
+
+// observe observes the size of a given message, and returns it (-1 if the message is not a proto message).
+func (i *GrpcMessageSizeInterceptor) observe(method string, direction string, msg interface{}) int {
+	protoMsg, ok := msg.(proto.Message)
+	if !ok {
+		return -1
+	}
+
+	size := proto.Size(protoMsg)
+
+	if i.histogram != nil {
+		i.histogram.WithLabelValues(method, direction).Observe(float64(size))
+	}
+
+	return size
+}
+
+// messageSizeServerStream is a [grpc.ServerStream] observing and limiting the sizes of its messages.
+type messageSizeServerStream struct {
+	grpc.ServerStream
+	interceptor *GrpcMessageSizeInterceptor
+	method      string
+}
+
+func (s *messageSizeServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.interceptor.received(s.Context(), s.method, m)
+}
+
+func (s *messageSizeServerStream) SendMsg(m interface{}) error {
+	s.interceptor.observe(s.method, MessageDirectionSent, m)
+
+	return s.ServerStream.SendMsg(m)
+}
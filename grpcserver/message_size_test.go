@@ -0,0 +1,179 @@
+package grpcserver_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/ankorstore/yokai/grpcserver/testdata/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// messageSizeSamples returns the samples count and sum of the message size histogram for a given method and direction.
+func messageSizeSamples(t *testing.T, registry *prometheus.Registry, method string, direction string) (uint64, float64) {
+	t.Helper()
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "foo_bar_"+grpcserver.GrpcServerMetricsMessageSize {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["grpc_method"] == method && labels["grpc_direction"] == direction {
+				return metric.GetHistogram().GetSampleCount(), metric.GetHistogram().GetSampleSum()
+			}
+		}
+	}
+
+	return 0, 0
+}
+
+func TestGrpcMessageSizeInterceptorUnary(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	interceptor := grpcserver.
+		NewGrpcMessageSizeInterceptor().
+		MethodLimits(map[string]int{"/test.Service/Limited": 10}).
+		Metrics(registry, "foo", "bar")
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &proto.Response{Success: true, Message: "ok"}, nil
+	}
+
+	req := &proto.Request{Message: "a message larger than 10 bytes"}
+	reqSize := float64(protobuf.Size(req))
+	respSize := float64(protobuf.Size(&proto.Response{Success: true, Message: "ok"}))
+
+	// not limited
+	resp, err := interceptor.UnaryInterceptor()(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp.(*proto.Response).Message)
+
+	count, sum := messageSizeSamples(t, registry, "/test.Service/Unary", grpcserver.MessageDirectionRecv)
+	assert.Equal(t, uint64(1), count)
+	assert.Equal(t, reqSize, sum)
+
+	count, sum = messageSizeSamples(t, registry, "/test.Service/Unary", grpcserver.MessageDirectionSent)
+	assert.Equal(t, uint64(1), count)
+	assert.Equal(t, respSize, sum)
+
+	// limited, case-insensitively
+	_, err = interceptor.UnaryInterceptor()(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/test.service/limited"}, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "too large for /test.service/limited, limit is 10")
+
+	count, sum = messageSizeSamples(t, registry, "/test.service/limited", grpcserver.MessageDirectionRecv)
+	assert.Equal(t, uint64(1), count)
+	assert.Equal(t, reqSize, sum)
+
+	count, _ = messageSizeSamples(t, registry, "/test.service/limited", grpcserver.MessageDirectionSent)
+	assert.Equal(t, uint64(0), count)
+
+	// limited, but within the limit
+	_, err = interceptor.UnaryInterceptor()(context.Background(), &proto.Request{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Limited"}, handler)
+	assert.NoError(t, err)
+}
+
+func TestGrpcMessageSizeInterceptorUnaryWithoutMetrics(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcMessageSizeInterceptor()
+
+	resp, err := interceptor.UnaryInterceptor()(
+		context.Background(),
+		"request",
+		&grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "request", resp)
+}
+
+func TestGrpcMessageSizeInterceptorStream(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	interceptor := grpcserver.
+		NewGrpcMessageSizeInterceptor().
+		MethodLimits(map[string]int{"/test.Service/Bidi": 10}).
+		Metrics(registry, "foo", "bar")
+
+	small := &proto.Request{Message: "small"}
+	large := &proto.Request{Message: "a message larger than 10 bytes"}
+
+	stream := &testMessagesServerStream{
+		testServerStream: testServerStream{ctx: context.Background()},
+		messages:         []*proto.Request{small, large},
+	}
+
+	var received []string
+	err := interceptor.StreamInterceptor()(nil, stream, &grpc.StreamServerInfo{FullMethod: "/test.Service/Bidi"}, func(srv interface{}, ss grpc.ServerStream) error {
+		for {
+			req := &proto.Request{}
+			if err := ss.RecvMsg(req); err != nil {
+				return err
+			}
+
+			received = append(received, req.Message)
+
+			if err := ss.SendMsg(&proto.Response{Message: req.Message}); err != nil {
+				return err
+			}
+		}
+	})
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"small"}, received)
+	assert.Len(t, stream.sent, 1)
+
+	count, sum := messageSizeSamples(t, registry, "/test.Service/Bidi", grpcserver.MessageDirectionRecv)
+	assert.Equal(t, uint64(2), count)
+	assert.Equal(t, float64(protobuf.Size(small)+protobuf.Size(large)), sum)
+
+	count, sum = messageSizeSamples(t, registry, "/test.Service/Bidi", grpcserver.MessageDirectionSent)
+	assert.Equal(t, uint64(1), count)
+	assert.Equal(t, float64(protobuf.Size(&proto.Response{Message: "small"})), sum)
+}
+
+type testMessagesServerStream struct {
+	testServerStream
+	messages []*proto.Request
+	sent     []interface{}
+}
+
+func (s *testMessagesServerStream) RecvMsg(m interface{}) error {
+	if len(s.messages) == 0 {
+		return io.EOF
+	}
+
+	//nolint:forcetypeassert
+	protobuf.Merge(m.(*proto.Request), s.messages[0])
+	s.messages = s.messages[1:]
+
+	return nil
+}
+
+func (s *testMessagesServerStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime/debug"
 
+	"github.com/ankorstore/yokai/log"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -20,13 +21,17 @@ func NewGrpcPanicRecoveryHandler() *GrpcPanicRecoveryHandler {
 	return &GrpcPanicRecoveryHandler{}
 }
 
-// Handle handles the panic recovery.
+// Handle handles the panic recovery, logging the recovered panic with its stack (as a list of frames, or as a raw
+// stack also returned in the error with debug).
 func (h *GrpcPanicRecoveryHandler) Handle(withDebug bool) recovery.RecoveryHandlerFuncContext {
 	return func(ctx context.Context, pnc any) error {
 		evt := CtxLogger(ctx).Error().Str("panic", fmt.Sprintf("%s", pnc))
 
 		if withDebug {
 			evt.Str("stack", string(debug.Stack()))
+		} else {
+			// captured while recovering, the stack includes the frames of the panic
+			evt.Stack().Err(log.WrapWithStack(fmt.Errorf("%v", pnc)))
 		}
 
 		evt.Msg("grpc recovered from panic")
@@ -33,8 +33,27 @@ func TestHandleWithoutDebug(t *testing.T) {
 	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
 		"level":   "error",
 		"panic":   "%!s(<nil>)",
+		"error":   "<nil>",
 		"message": "grpc recovered from panic",
 	})
+
+	// stack assertion
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	stack, err := records[0].Attribute("stack")
+	assert.NoError(t, err)
+
+	frames, ok := stack.([]interface{})
+	assert.True(t, ok)
+	assert.NotEmpty(t, frames)
+
+	frame, ok := frames[0].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "panic.go", frame["source"])
+	assert.Equal(t, "(*GrpcPanicRecoveryHandler).Handle.func1", frame["func"])
+	assert.NotEmpty(t, frame["line"])
 }
 
 func TestHandleWithDebug(t *testing.T) {
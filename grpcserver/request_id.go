@@ -0,0 +1,110 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// BaggageRequestIdKey is the baggage key used to bridge the request id between the HTTP and gRPC flows.
+const BaggageRequestIdKey = "x-request-id"
+
+// GrpcRequestIdClientInterceptor is a gRPC unary and stream client interceptor propagating the contextual request id
+// in the outgoing calls metadata.
+type GrpcRequestIdClientInterceptor struct {
+	metadataKey string
+	baggageKey  string
+}
+
+// NewGrpcRequestIdClientInterceptor returns a new [GrpcRequestIdClientInterceptor] instance, propagating the request
+// id in the x-request-id metadata.
+func NewGrpcRequestIdClientInterceptor() *GrpcRequestIdClientInterceptor {
+	return &GrpcRequestIdClientInterceptor{
+		metadataKey: HeaderXRequestId,
+		baggageKey:  BaggageRequestIdKey,
+	}
+}
+
+// MetadataKey configures the outgoing metadata key of the request id (x-request-id by default).
+func (i *GrpcRequestIdClientInterceptor) MetadataKey(key string) *GrpcRequestIdClientInterceptor {
+	if key != "" {
+		i.metadataKey = strings.ToLower(key)
+	}
+
+	return i
+}
+
+// BaggageKey configures the baggage key the request id is read from (x-request-id by default).
+func (i *GrpcRequestIdClientInterceptor) BaggageKey(key string) *GrpcRequestIdClientInterceptor {
+	if key != "" {
+		i.baggageKey = key
+	}
+
+	return i
+}
+
+// UnaryInterceptor handles the unary calls.
+func (i *GrpcRequestIdClientInterceptor) UnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return invoker(i.propagate(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamInterceptor handles the stream calls.
+func (i *GrpcRequestIdClientInterceptor) StreamInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		return streamer(i.propagate(ctx), desc, cc, method, opts...)
+	}
+}
+
+// propagate sets the contextual request id in the outgoing metadata, if not already set. The request id is resolved
+// from the gRPC server context (see [CtxRequestId]), or from the context baggage (as set by the HTTP server).
+func (i *GrpcRequestIdClientInterceptor) propagate(ctx context.Context) context.Context {
+	outgoingMd, _ := metadata.FromOutgoingContext(ctx)
+	if len(outgoingMd.Get(i.metadataKey)) > 0 {
+		return ctx
+	}
+
+	requestId := CtxRequestId(ctx)
+	if requestId == "" {
+		requestId = baggage.FromContext(ctx).Member(i.baggageKey).Value()
+	}
+
+	if requestId == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, i.metadataKey, requestId)
+}
+
+// withRequestIdBaggage returns a context containing the request id in its baggage, for the outgoing HTTP calls.
+func withRequestIdBaggage(ctx context.Context, requestId string) context.Context {
+	member, err := baggage.NewMember(BaggageRequestIdKey, requestId)
+	if err != nil {
+		return ctx
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag)
+}
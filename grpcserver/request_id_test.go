@@ -0,0 +1,222 @@
+package grpcserver_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/generate/generatetest/uuid"
+	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/ankorstore/yokai/grpcserver/grpcservertest"
+	"github.com/ankorstore/yokai/grpcserver/testdata/proto"
+	"github.com/ankorstore/yokai/grpcserver/testdata/service"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+func invokeWithRequestIdClientInterceptor(
+	t *testing.T,
+	ctx context.Context,
+	interceptor *grpcserver.GrpcRequestIdClientInterceptor,
+) metadata.MD {
+	t.Helper()
+
+	var outgoingMd metadata.MD
+
+	err := interceptor.UnaryInterceptor()(
+		ctx,
+		"/test.Service/Unary",
+		nil,
+		nil,
+		nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			outgoingMd, _ = metadata.FromOutgoingContext(ctx)
+
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+
+	return outgoingMd
+}
+
+func TestGrpcRequestIdClientInterceptorUnary(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcRequestIdClientInterceptor()
+
+	// from baggage
+	member, err := baggage.NewMember(grpcserver.BaggageRequestIdKey, "baggage-request-id")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	md := invokeWithRequestIdClientInterceptor(t, ctx, interceptor)
+	assert.Equal(t, []string{"baggage-request-id"}, md.Get("x-request-id"))
+
+	// from gRPC server context, preferred over baggage
+	ctx = context.WithValue(ctx, grpcserver.CtxRequestIdKey{}, "ctx-request-id")
+
+	md = invokeWithRequestIdClientInterceptor(t, ctx, interceptor)
+	assert.Equal(t, []string{"ctx-request-id"}, md.Get("x-request-id"))
+
+	// already present in outgoing metadata
+	md = invokeWithRequestIdClientInterceptor(
+		t,
+		metadata.AppendToOutgoingContext(ctx, "x-request-id", "outgoing-request-id"),
+		interceptor,
+	)
+	assert.Equal(t, []string{"outgoing-request-id"}, md.Get("x-request-id"))
+
+	// absent
+	md = invokeWithRequestIdClientInterceptor(t, context.Background(), interceptor)
+	assert.Empty(t, md.Get("x-request-id"))
+}
+
+func TestGrpcRequestIdClientInterceptorWithCustomKeys(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.
+		NewGrpcRequestIdClientInterceptor().
+		MetadataKey("X-Correlation-Id").
+		BaggageKey("correlation-id")
+
+	member, err := baggage.NewMember("correlation-id", "test-request-id")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	md := invokeWithRequestIdClientInterceptor(t, baggage.ContextWithBaggage(context.Background(), bag), interceptor)
+	assert.Equal(t, []string{"test-request-id"}, md.Get("x-correlation-id"))
+	assert.Empty(t, md.Get("x-request-id"))
+}
+
+func TestGrpcRequestIdClientInterceptorStream(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcRequestIdClientInterceptor()
+
+	ctx := context.WithValue(context.Background(), grpcserver.CtxRequestIdKey{}, "test-request-id")
+
+	var outgoingMd metadata.MD
+
+	_, err := interceptor.StreamInterceptor()(
+		ctx,
+		&grpc.StreamDesc{},
+		nil,
+		"/test.Service/Bidi",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			outgoingMd, _ = metadata.FromOutgoingContext(ctx)
+
+			//nolint:nilnil
+			return nil, nil
+		},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"test-request-id"}, outgoingMd.Get("x-request-id"))
+}
+
+func TestRequestIdPropagationFromHttpToGrpc(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(log.WithOutputWriter(logBuffer))
+	assert.NoError(t, err)
+
+	// gRPC server, capturing the request id of its context and baggage
+	var grpcRequestId, grpcBaggageRequestId string
+
+	lis := grpcservertest.NewBufconnListener(1024 * 1024)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcserver.NewGrpcLoggerInterceptor(uuid.NewTestUuidGenerator("generated"), logger).UnaryInterceptor(),
+			func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				grpcRequestId = grpcserver.CtxRequestId(ctx)
+				grpcBaggageRequestId = baggage.FromContext(ctx).Member(grpcserver.BaggageRequestIdKey).Value()
+
+				return handler(ctx, req)
+			},
+		),
+	)
+	server.RegisterService(&proto.Service_ServiceDesc, service.NewTestServiceServer())
+
+	go func() {
+		//nolint:errcheck
+		server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	requestIdInterceptor := grpcserver.NewGrpcRequestIdClientInterceptor()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(requestIdInterceptor.UnaryInterceptor()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	client := proto.NewServiceClient(conn)
+
+	// HTTP server, storing the request id in the baggage (as the httpserver request id middleware) and calling gRPC
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rid := r.Header.Get("x-request-id")
+
+		member, err := baggage.NewMember(grpcserver.BaggageRequestIdKey, rid)
+		assert.NoError(t, err)
+
+		bag, err := baggage.New(member)
+		assert.NoError(t, err)
+
+		var trailer metadata.MD
+		_, err = client.Unary(
+			baggage.ContextWithBaggage(r.Context(), bag),
+			&proto.Request{Message: "test"},
+			grpc.Trailer(&trailer),
+		)
+		assert.NoError(t, err)
+
+		w.Header().Set("x-request-id", rid)
+		w.Header().Set("x-grpc-request-id", trailer.Get("x-request-id")[0])
+	}))
+	defer httpServer.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+	req.Header.Set("x-request-id", testRequestId)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	// same request id on both legs
+	assert.Equal(t, testRequestId, resp.Header.Get("x-request-id"))
+	assert.Equal(t, testRequestId, resp.Header.Get("x-grpc-request-id"))
+	assert.Equal(t, testRequestId, grpcRequestId)
+
+	// bridged back in the gRPC context baggage, for the outgoing HTTP calls
+	assert.Equal(t, testRequestId, grpcBaggageRequestId)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"requestID": testRequestId,
+		"message":   "grpc call success",
+	})
+}
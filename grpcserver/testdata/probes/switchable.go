@@ -0,0 +1,32 @@
+package probes
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/ankorstore/yokai/healthcheck"
+)
+
+type SwitchableProbe struct {
+	failing atomic.Bool
+}
+
+func NewSwitchableProbe() *SwitchableProbe {
+	return &SwitchableProbe{}
+}
+
+func (p *SwitchableProbe) Name() string {
+	return "switchableProbe"
+}
+
+func (p *SwitchableProbe) Fail() {
+	p.failing.Store(true)
+}
+
+func (p *SwitchableProbe) Check(ctx context.Context) *healthcheck.CheckerProbeResult {
+	if p.failing.Load() {
+		return healthcheck.NewCheckerProbeResult(false, "some failure")
+	}
+
+	return healthcheck.NewCheckerProbeResult(true, "some success")
+}
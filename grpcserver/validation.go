@@ -0,0 +1,152 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validator is implemented by the messages generated with the protoc-gen-validate rules.
+type validator interface {
+	Validate() error
+}
+
+// allValidator is implemented by the messages generated with the protoc-gen-validate rules, to report all the
+// violations at once.
+type allValidator interface {
+	ValidateAll() error
+}
+
+// multiValidationError is implemented by the protoc-gen-validate errors returned by ValidateAll.
+type multiValidationError interface {
+	AllErrors() []error
+}
+
+// fieldValidationError is implemented by the protoc-gen-validate field errors.
+type fieldValidationError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// GrpcValidationInterceptor is a gRPC unary and stream server interceptor validating the received messages with
+// their protoc-gen-validate rules (ValidateAll, or Validate otherwise), and rejecting the invalid ones with a
+// [codes.InvalidArgument] status, detailing the fields violations in a [errdetails.BadRequest].
+//
+// The messages without validation rules are not validated.
+type GrpcValidationInterceptor struct{}
+
+// NewGrpcValidationInterceptor returns a new [GrpcValidationInterceptor] instance.
+func NewGrpcValidationInterceptor() *GrpcValidationInterceptor {
+	return &GrpcValidationInterceptor{}
+}
+
+// UnaryInterceptor handles the unary requests.
+func (i *GrpcValidationInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := i.validate(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor handles the stream requests.
+func (i *GrpcValidationInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validationServerStream{
+			ServerStream: ss,
+			interceptor:  i,
+			method:       info.FullMethod,
+		})
+	}
+}
+
+func (i *GrpcValidationInterceptor) validate(ctx context.Context, method string, msg interface{}) error {
+	var err error
+
+	switch v := msg.(type) {
+	case allValidator:
+		err = v.ValidateAll()
+	case validator:
+		err = v.Validate()
+	default:
+		return nil
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	CtxLogger(ctx).Debug().Err(err).Str("grpcMethod", method).Msg("grpc message rejected by validation")
+
+	st := status.New(codes.InvalidArgument, err.Error())
+
+	violations := fieldViolations(err, "")
+	if len(violations) == 0 {
+		return st.Err()
+	}
+
+	detailedSt, detailsErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailsErr != nil {
+		return st.Err()
+	}
+
+	return detailedSt.Err()
+}
+
+// fieldViolations returns the fields violations of a validation error, the nested messages fields being prefixed by
+// their parent field name.
+func fieldViolations(err error, prefix string) []*errdetails.BadRequest_FieldViolation {
+	if multiErr, ok := err.(multiValidationError); ok {
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, e := range multiErr.AllErrors() {
+			violations = append(violations, fieldViolations(e, prefix)...)
+		}
+
+		return violations
+	}
+
+	fieldErr, ok := err.(fieldValidationError)
+	if !ok {
+		return nil
+	}
+
+	field := fieldErr.Field()
+	if prefix != "" {
+		field = fmt.Sprintf("%s.%s", prefix, field)
+	}
+
+	if cause := fieldErr.Cause(); cause != nil {
+		if nested := fieldViolations(cause, field); len(nested) > 0 {
+			return nested
+		}
+	}
+
+	return []*errdetails.BadRequest_FieldViolation{
+		{
+			Field:       field,
+			Description: fieldErr.Reason(),
+		},
+	}
+}
+
+// validationServerStream is a [grpc.ServerStream] validating its received messages.
+type validationServerStream struct {
+	grpc.ServerStream
+	interceptor *GrpcValidationInterceptor
+	method      string
+}
+
+func (s *validationServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.interceptor.validate(s.Context(), s.method, m)
+}
@@ -0,0 +1,234 @@
+package grpcserver_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testFieldValidationError mimics the protoc-gen-validate field errors.
+type testFieldValidationError struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (e testFieldValidationError) Field() string  { return e.field }
+func (e testFieldValidationError) Reason() string { return e.reason }
+func (e testFieldValidationError) Cause() error   { return e.cause }
+func (e testFieldValidationError) Key() bool      { return false }
+func (e testFieldValidationError) Error() string {
+	return "invalid Request." + e.field + ": " + e.reason
+}
+
+// testMultiValidationError mimics the protoc-gen-validate errors returned by ValidateAll.
+type testMultiValidationError []error
+
+func (m testMultiValidationError) AllErrors() []error { return m }
+func (m testMultiValidationError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// testValidatedRequest mimics a message generated with protoc-gen-validate rules.
+type testValidatedRequest struct {
+	Name  string
+	Email string
+	City  string
+}
+
+func (r *testValidatedRequest) Validate() error {
+	if errs := r.violations(); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+func (r *testValidatedRequest) ValidateAll() error {
+	if errs := r.violations(); len(errs) > 0 {
+		return testMultiValidationError(errs)
+	}
+
+	return nil
+}
+
+func (r *testValidatedRequest) violations() []error {
+	var errs []error
+
+	if r.Name == "" {
+		errs = append(errs, testFieldValidationError{field: "Name", reason: "value length must be at least 1 runes"})
+	}
+
+	if !strings.Contains(r.Email, "@") {
+		errs = append(errs, testFieldValidationError{field: "Email", reason: "value must be a valid email address"})
+	}
+
+	if r.City == "" {
+		errs = append(errs, testFieldValidationError{
+			field:  "Address",
+			reason: "embedded message failed validation",
+			cause:  testFieldValidationError{field: "City", reason: "value length must be at least 1 runes"},
+		})
+	}
+
+	return errs
+}
+
+// testSingleValidatedRequest mimics a message generated with an older protoc-gen-validate, without ValidateAll.
+type testSingleValidatedRequest struct {
+	Name string
+}
+
+func (r *testSingleValidatedRequest) Validate() error {
+	if r.Name == "" {
+		return testFieldValidationError{field: "Name", reason: "value length must be at least 1 runes"}
+	}
+
+	return nil
+}
+
+func badRequestViolations(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	violations := map[string]string{}
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violations[violation.GetField()] = violation.GetDescription()
+			}
+		}
+	}
+
+	return violations
+}
+
+func TestGrpcValidationInterceptorUnary(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcValidationInterceptor()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	// valid
+	resp, err := interceptor.UnaryInterceptor()(
+		context.Background(),
+		&testValidatedRequest{Name: "name", Email: "name@example.com", City: "Paris"},
+		info,
+		handler,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	// invalid, with all the violations
+	resp, err = interceptor.UnaryInterceptor()(
+		context.Background(),
+		&testValidatedRequest{Email: "invalid"},
+		info,
+		handler,
+	)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "invalid Request.Name: value length must be at least 1 runes")
+	assert.Equal(
+		t,
+		map[string]string{
+			"Name":         "value length must be at least 1 runes",
+			"Email":        "value must be a valid email address",
+			"Address.City": "value length must be at least 1 runes",
+		},
+		badRequestViolations(t, err),
+	)
+}
+
+func TestGrpcValidationInterceptorUnaryWithValidateOnly(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcValidationInterceptor()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	_, err := interceptor.UnaryInterceptor()(context.Background(), &testSingleValidatedRequest{Name: "name"}, info, handler)
+	assert.NoError(t, err)
+
+	_, err = interceptor.UnaryInterceptor()(context.Background(), &testSingleValidatedRequest{}, info, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{"Name": "value length must be at least 1 runes"}, badRequestViolations(t, err))
+
+	// messages without validation rules are not validated
+	resp, err := interceptor.UnaryInterceptor()(context.Background(), "request", info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestGrpcValidationInterceptorStream(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcValidationInterceptor()
+
+	stream := &testValidatedServerStream{
+		testServerStream: testServerStream{ctx: context.Background()},
+		messages: []testValidatedRequest{
+			{Name: "valid", Email: "valid@example.com", City: "Paris"},
+			{Name: "invalid"},
+		},
+	}
+
+	var received []string
+	err := interceptor.StreamInterceptor()(nil, stream, &grpc.StreamServerInfo{FullMethod: "/test.Service/Bidi"}, func(srv interface{}, ss grpc.ServerStream) error {
+		for {
+			req := &testValidatedRequest{}
+			if err := ss.RecvMsg(req); err != nil {
+				return err
+			}
+
+			received = append(received, req.Name)
+		}
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, []string{"valid"}, received)
+	assert.Equal(
+		t,
+		map[string]string{
+			"Email":        "value must be a valid email address",
+			"Address.City": "value length must be at least 1 runes",
+		},
+		badRequestViolations(t, err),
+	)
+}
+
+type testValidatedServerStream struct {
+	testServerStream
+	messages []testValidatedRequest
+}
+
+func (s *testValidatedServerStream) RecvMsg(m interface{}) error {
+	if len(s.messages) == 0 {
+		return io.EOF
+	}
+
+	//nolint:forcetypeassert
+	*m.(*testValidatedRequest) = s.messages[0]
+	s.messages = s.messages[1:]
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShutdownProbeName is the name of the [ShutdownCoordinator] probe.
+const ShutdownProbeName = "shutdown"
+
+// ShutdownConfig is the configuration of a [ShutdownCoordinator].
+type ShutdownConfig struct {
+	Order          []string      // stages names, stopped in this order before the other stages (in registration order)
+	ReadinessDelay time.Duration // delay between the readiness failure and the first stage stop
+	StageDelay     time.Duration // delay between the stop of a stage and the next one
+}
+
+// ShutdownStage is a stage of a [ShutdownCoordinator], like a server to stop.
+type ShutdownStage struct {
+	Name string
+	Stop func(ctx context.Context) error
+}
+
+// ShutdownCoordinator coordinates the graceful shutdown of several stages (like the http and grpc servers), in a
+// deterministic order: the readiness checks are failed first (as a readiness [CheckerProbe]), then after a delay to let
+// the load balancers stop routing traffic, each stage is stopped in the configured order.
+type ShutdownCoordinator struct {
+	config   ShutdownConfig
+	mutex    sync.Mutex
+	stages   []ShutdownStage
+	stopping atomic.Bool
+	once     sync.Once
+	errs     map[string]error
+}
+
+// NewShutdownCoordinator returns a new [ShutdownCoordinator] for a provided [ShutdownConfig].
+func NewShutdownCoordinator(config ShutdownConfig) *ShutdownCoordinator {
+	return &ShutdownCoordinator{
+		config: config,
+		errs:   map[string]error{},
+	}
+}
+
+// RegisterStage registers a stage, stopped by a provided function on shutdown.
+func (c *ShutdownCoordinator) RegisterStage(name string, stop func(ctx context.Context) error) *ShutdownCoordinator {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stages = append(c.stages, ShutdownStage{
+		Name: name,
+		Stop: stop,
+	})
+
+	return c
+}
+
+// Stages returns the registered stages, in their stop order.
+func (c *ShutdownCoordinator) Stages() []ShutdownStage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stages := make([]ShutdownStage, 0, len(c.stages))
+	ordered := map[int]bool{}
+
+	for _, name := range c.config.Order {
+		for i, stage := range c.stages {
+			if stage.Name == name && !ordered[i] {
+				stages = append(stages, stage)
+				ordered[i] = true
+			}
+		}
+	}
+
+	for i, stage := range c.stages {
+		if !ordered[i] {
+			stages = append(stages, stage)
+		}
+	}
+
+	return stages
+}
+
+// Stopping returns true if the shutdown has started.
+func (c *ShutdownCoordinator) Stopping() bool {
+	return c.stopping.Load()
+}
+
+// Name returns the probe name.
+func (c *ShutdownCoordinator) Name() string {
+	return ShutdownProbeName
+}
+
+// Check fails once the shutdown has started, to stop receiving traffic while the stages are draining.
+func (c *ShutdownCoordinator) Check(context.Context) *CheckerProbeResult {
+	if c.Stopping() {
+		return NewCheckerProbeResult(false, "shutting down")
+	}
+
+	return NewCheckerProbeResult(true, "running")
+}
+
+// Shutdown fails the readiness, waits for the readiness delay, then stops the stages in order (with the stage delay
+// between them). It is executed only once: the next calls return the same result.
+func (c *ShutdownCoordinator) Shutdown(ctx context.Context) error {
+	c.once.Do(func() {
+		c.stopping.Store(true)
+
+		stages := c.Stages()
+
+		delay := c.config.ReadinessDelay
+		for _, stage := range stages {
+			// the stages are stopped even if the delay is interrupted, their stop function handling the context end
+			wait(ctx, delay)
+
+			if err := stage.Stop(ctx); err != nil {
+				c.errs[stage.Name] = errors.Join(c.errs[stage.Name], fmt.Errorf("failed to stop %s: %w", stage.Name, err))
+			}
+
+			delay = c.config.StageDelay
+		}
+	})
+
+	var errs []error
+	seen := map[string]bool{}
+
+	for _, stage := range c.Stages() {
+		if !seen[stage.Name] {
+			errs = append(errs, c.errs[stage.Name])
+			seen[stage.Name] = true
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// StopStage triggers the shutdown (if not already done) and returns the error of the stage with the provided name, to
+// be used by each stage lifecycle stop hook, whatever their invocation order.
+func (c *ShutdownCoordinator) StopStage(ctx context.Context, name string) error {
+	//nolint:errcheck
+	c.Shutdown(ctx)
+
+	return c.errs[name]
+}
+
+// wait waits for a delay, or until the end of the provided context.
+func wait(ctx context.Context, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,182 @@
+package healthcheck_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/stretchr/testify/assert"
+)
+
+type shutdownRecorder struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (r *shutdownRecorder) record(event string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *shutdownRecorder) Events() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.events
+}
+
+func TestShutdownCoordinatorStopOrder(t *testing.T) {
+	t.Parallel()
+
+	recorder := &shutdownRecorder{}
+
+	checker := healthcheck.NewChecker()
+
+	coordinator := healthcheck.NewShutdownCoordinator(healthcheck.ShutdownConfig{
+		Order: []string{"http", "grpc"},
+	})
+
+	checker.RegisterProbe(coordinator, healthcheck.Readiness)
+
+	stage := func(name string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			// the readiness is already failing when the stages are stopped
+			if !checker.Check(ctx, healthcheck.Readiness).Success {
+				recorder.record("not ready")
+			}
+
+			recorder.record(name)
+
+			return nil
+		}
+	}
+
+	// registered in a different order than the configured one
+	coordinator.
+		RegisterStage("worker", stage("worker")).
+		RegisterStage("grpc", stage("grpc")).
+		RegisterStage("http", stage("http"))
+
+	var names []string
+	for _, s := range coordinator.Stages() {
+		names = append(names, s.Name)
+	}
+
+	assert.Equal(t, []string{"http", "grpc", "worker"}, names)
+
+	assert.True(t, checker.Check(context.Background(), healthcheck.Readiness).Success)
+	assert.False(t, coordinator.Stopping())
+
+	// the stages lifecycle hooks can be invoked in any order
+	assert.NoError(t, coordinator.StopStage(context.Background(), "grpc"))
+	assert.NoError(t, coordinator.StopStage(context.Background(), "http"))
+	assert.NoError(t, coordinator.StopStage(context.Background(), "worker"))
+
+	assert.Equal(
+		t,
+		[]string{"not ready", "http", "not ready", "grpc", "not ready", "worker"},
+		recorder.Events(),
+	)
+
+	assert.True(t, coordinator.Stopping())
+
+	result := checker.Check(context.Background(), healthcheck.Readiness)
+	assert.False(t, result.Success)
+	assert.Equal(t, "shutting down", result.ProbesResults[healthcheck.ShutdownProbeName].Message)
+}
+
+func TestShutdownCoordinatorDelays(t *testing.T) {
+	t.Parallel()
+
+	var times []time.Time
+
+	coordinator := healthcheck.NewShutdownCoordinator(healthcheck.ShutdownConfig{
+		ReadinessDelay: 50 * time.Millisecond,
+		StageDelay:     30 * time.Millisecond,
+	})
+
+	stop := func(context.Context) error {
+		times = append(times, time.Now())
+
+		return nil
+	}
+
+	coordinator.RegisterStage("http", stop).RegisterStage("grpc", stop)
+
+	start := time.Now()
+
+	assert.NoError(t, coordinator.Shutdown(context.Background()))
+
+	assert.Len(t, times, 2)
+	assert.GreaterOrEqual(t, times[0].Sub(start), 50*time.Millisecond)
+	assert.GreaterOrEqual(t, times[1].Sub(times[0]), 30*time.Millisecond)
+}
+
+func TestShutdownCoordinatorDelaysInterruptedByContext(t *testing.T) {
+	t.Parallel()
+
+	var stopped []string
+
+	coordinator := healthcheck.NewShutdownCoordinator(healthcheck.ShutdownConfig{
+		ReadinessDelay: time.Hour,
+		StageDelay:     time.Hour,
+	})
+
+	stop := func(name string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			stopped = append(stopped, name)
+
+			return ctx.Err()
+		}
+	}
+
+	coordinator.RegisterStage("http", stop("http")).RegisterStage("grpc", stop("grpc"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := coordinator.Shutdown(ctx)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// the stages are still stopped, without waiting for the delays
+	assert.Equal(t, []string{"http", "grpc"}, stopped)
+}
+
+func TestShutdownCoordinatorStagesErrors(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+
+	coordinator := healthcheck.NewShutdownCoordinator(healthcheck.ShutdownConfig{})
+
+	coordinator.
+		RegisterStage("http", func(context.Context) error {
+			calls++
+
+			return errors.New("http error")
+		}).
+		RegisterStage("grpc", func(context.Context) error {
+			calls++
+
+			return nil
+		})
+
+	err := coordinator.StopStage(context.Background(), "http")
+	assert.Error(t, err)
+	assert.Equal(t, "failed to stop http: http error", err.Error())
+
+	assert.NoError(t, coordinator.StopStage(context.Background(), "grpc"))
+
+	err = coordinator.Shutdown(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, "failed to stop http: http error", err.Error())
+
+	// executed only once
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,11 @@
+package httpclient
+
+import "github.com/ankorstore/yokai/httpclient/transport"
+
+// ErrCircuitOpen is returned (wrapped) by the http client when the circuit breaker of the request host is open, see
+// [transport.CircuitBreakerTransport].
+var ErrCircuitOpen = transport.ErrCircuitOpen
+
+// OAuth2TokenError is returned (wrapped) by the http client when the OAuth2 token of the request cannot be fetched, see
+// [transport.OAuth2Transport].
+type OAuth2TokenError = transport.OAuth2TokenError
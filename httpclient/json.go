@@ -0,0 +1,198 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// MaxApiErrorBodySize is the maximum size in bytes of the unexpected responses bodies read into an [ApiError].
+const MaxApiErrorBodySize = 1 << 20
+
+// ApiError is the error returned by [Json] for the responses with an unexpected status code.
+type ApiError struct {
+	Status int
+	Body   []byte
+}
+
+// Error returns the error message, with the response status code and body.
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("unexpected http response status %d: %s", e.Status, e.Body)
+}
+
+// ErrorDecoder turns an unexpected response, and its already read body, into an error.
+type ErrorDecoder func(resp *http.Response, body []byte) error
+
+// JsonOptions are options for the [Json] requests.
+type JsonOptions struct {
+	Headers        http.Header
+	Query          url.Values
+	ExpectedStatus []int
+	ErrorDecoder   ErrorDecoder
+}
+
+// JsonOption are functional options for the [Json] requests.
+type JsonOption func(o *JsonOptions)
+
+// WithJsonHeader is used to add a header to the request.
+func WithJsonHeader(name string, value string) JsonOption {
+	return func(o *JsonOptions) {
+		o.Headers.Add(name, value)
+	}
+}
+
+// WithJsonQueryParam is used to add a query parameter to the request url.
+func WithJsonQueryParam(name string, value string) JsonOption {
+	return func(o *JsonOptions) {
+		o.Query.Add(name, value)
+	}
+}
+
+// WithJsonExpectedStatus is used to specify the expected response status codes (any 2xx by default).
+func WithJsonExpectedStatus(codes ...int) JsonOption {
+	return func(o *JsonOptions) {
+		o.ExpectedStatus = codes
+	}
+}
+
+// WithJsonErrorDecoder is used to specify the [ErrorDecoder] of the unexpected responses ([ApiError] by default).
+func WithJsonErrorDecoder(decoder ErrorDecoder) JsonOption {
+	return func(o *JsonOptions) {
+		o.ErrorDecoder = decoder
+	}
+}
+
+// Json sends a JSON request with a provided [http.Client], and decodes its JSON response.
+//
+// The request body is JSON encoded if not nil, and the response body is decoded if the response has an expected status
+// code (any 2xx by default), the decoded response being nil if the response has no body (like a 204). Otherwise, the
+// response body is turned into an error with the [ErrorDecoder], an [ApiError] by default.
+//
+// The provided client (and its transport) sends the request, so its logging, tracing and metrics apply, and the
+// returned [http.Response] body is already read and closed.
+func Json[TReq any, TResp any](
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	rawUrl string,
+	body *TReq,
+	options ...JsonOption,
+) (*TResp, *http.Response, error) {
+	appliedOpts := JsonOptions{
+		Headers:        http.Header{},
+		Query:          map[string][]string{},
+		ExpectedStatus: nil,
+		ErrorDecoder:   defaultErrorDecoder,
+	}
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	req, err := newJsonRequest(ctx, method, rawUrl, body, appliedOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	//nolint:errcheck
+	defer resp.Body.Close()
+
+	if !isExpectedStatus(resp.StatusCode, appliedOpts.ExpectedStatus) {
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, MaxApiErrorBodySize))
+		if err != nil {
+			return nil, resp, fmt.Errorf("failed to read http response body: %w", err)
+		}
+
+		return nil, resp, appliedOpts.ErrorDecoder(resp, respBody)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp, fmt.Errorf("failed to read http response body: %w", err)
+	}
+
+	if len(bytes.TrimSpace(respBody)) == 0 {
+		return nil, resp, nil
+	}
+
+	decoded := new(TResp)
+	if err = json.Unmarshal(respBody, decoded); err != nil {
+		return nil, resp, fmt.Errorf("failed to decode http response body: %w", err)
+	}
+
+	return decoded, resp, nil
+}
+
+func newJsonRequest[TReq any](ctx context.Context, method string, rawUrl string, body *TReq, options JsonOptions) (*http.Request, error) {
+	var reqBody io.Reader = http.NoBody
+
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode http request body: %w", err)
+		}
+
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawUrl, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	if len(options.Query) > 0 {
+		query := req.URL.Query()
+		for name, values := range options.Query {
+			for _, value := range values {
+				query.Add(name, value)
+			}
+		}
+
+		req.URL.RawQuery = query.Encode()
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	for name, values := range options.Headers {
+		req.Header.Del(name)
+
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	return req, nil
+}
+
+func isExpectedStatus(code int, expected []int) bool {
+	if len(expected) == 0 {
+		return code >= http.StatusOK && code < http.StatusMultipleChoices
+	}
+
+	for _, expectedCode := range expected {
+		if code == expectedCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func defaultErrorDecoder(resp *http.Response, body []byte) error {
+	return &ApiError{
+		Status: resp.StatusCode,
+		Body:   body,
+	}
+}
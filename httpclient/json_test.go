@@ -0,0 +1,249 @@
+package httpclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+type testJsonRequest struct {
+	Name string `json:"name"`
+}
+
+type testJsonResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+type testJsonError struct {
+	Code    int
+	Message string `json:"message"`
+}
+
+func (e *testJsonError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+func newTestJsonServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-accept", r.Header.Get("Accept"))
+		w.Header().Set("received-content-type", r.Header.Get("Content-Type"))
+		w.Header().Set("received-foo", r.Header.Get("x-foo"))
+		w.Header().Set("received-query", r.URL.RawQuery)
+
+		switch r.URL.Path {
+		case "/greet":
+			var req testJsonRequest
+			err := json.NewDecoder(r.Body).Decode(&req)
+			assert.NoError(t, err)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, err = w.Write([]byte(fmt.Sprintf(`{"greeting":"hello %s"}`, req.Name)))
+			assert.NoError(t, err)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/error":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, err := w.Write([]byte(`{"message":"invalid name"}`))
+			assert.NoError(t, err)
+		case "/malformed":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{"greeting":`))
+			assert.NoError(t, err)
+		}
+	}))
+}
+
+func TestJsonSuccess(t *testing.T) {
+	t.Parallel()
+
+	server := newTestJsonServer(t)
+	defer server.Close()
+
+	result, resp, err := httpclient.Json[testJsonRequest, testJsonResponse](
+		context.Background(),
+		server.Client(),
+		http.MethodPost,
+		server.URL+"/greet?a=1",
+		&testJsonRequest{Name: "john"},
+		httpclient.WithJsonHeader("x-foo", "foo"),
+		httpclient.WithJsonQueryParam("b", "2"),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "hello john", result.Greeting)
+
+	assert.Equal(t, "application/json", resp.Header.Get("received-accept"))
+	assert.Equal(t, "application/json", resp.Header.Get("received-content-type"))
+	assert.Equal(t, "foo", resp.Header.Get("received-foo"))
+	assert.Equal(t, "a=1&b=2", resp.Header.Get("received-query"))
+}
+
+func TestJsonWithoutBodies(t *testing.T) {
+	t.Parallel()
+
+	server := newTestJsonServer(t)
+	defer server.Close()
+
+	result, resp, err := httpclient.Json[any, testJsonResponse](
+		context.Background(),
+		server.Client(),
+		http.MethodDelete,
+		server.URL+"/empty",
+		nil,
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Nil(t, result)
+	assert.Equal(t, "", resp.Header.Get("received-content-type"))
+}
+
+func TestJsonWithUnexpectedStatus(t *testing.T) {
+	t.Parallel()
+
+	server := newTestJsonServer(t)
+	defer server.Close()
+
+	// default api error
+	result, resp, err := httpclient.Json[testJsonRequest, testJsonResponse](
+		context.Background(),
+		server.Client(),
+		http.MethodPost,
+		server.URL+"/error",
+		&testJsonRequest{Name: "john"},
+	)
+	assert.Nil(t, result)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var apiErr *httpclient.ApiError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.Status)
+	assert.Equal(t, `{"message":"invalid name"}`, string(apiErr.Body))
+	assert.Equal(t, `unexpected http response status 422: {"message":"invalid name"}`, err.Error())
+
+	// custom error decoder
+	_, _, err = httpclient.Json[testJsonRequest, testJsonResponse](
+		context.Background(),
+		server.Client(),
+		http.MethodPost,
+		server.URL+"/error",
+		&testJsonRequest{Name: "john"},
+		httpclient.WithJsonErrorDecoder(func(resp *http.Response, body []byte) error {
+			decoded := &testJsonError{Code: resp.StatusCode}
+			if err := json.Unmarshal(body, decoded); err != nil {
+				return err
+			}
+
+			return decoded
+		}),
+	)
+
+	var customErr *testJsonError
+	assert.True(t, errors.As(err, &customErr))
+	assert.Equal(t, "422: invalid name", customErr.Error())
+
+	// unexpected success status
+	_, resp, err = httpclient.Json[testJsonRequest, testJsonResponse](
+		context.Background(),
+		server.Client(),
+		http.MethodPost,
+		server.URL+"/greet",
+		&testJsonRequest{Name: "john"},
+		httpclient.WithJsonExpectedStatus(http.StatusOK),
+	)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, `{"greeting":"hello john"}`, string(apiErr.Body))
+}
+
+func TestJsonWithMalformedResponse(t *testing.T) {
+	t.Parallel()
+
+	server := newTestJsonServer(t)
+	defer server.Close()
+
+	result, resp, err := httpclient.Json[any, testJsonResponse](
+		context.Background(),
+		server.Client(),
+		http.MethodGet,
+		server.URL+"/malformed",
+		nil,
+	)
+	assert.Nil(t, result)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode http response body")
+
+	// body already read and closed
+	_, err = io.ReadAll(resp.Body)
+	assert.Error(t, err)
+}
+
+func TestJsonWithInvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	// not encodable
+	_, resp, err := httpclient.Json[func(), testJsonResponse](
+		context.Background(),
+		http.DefaultClient,
+		http.MethodPost,
+		"http://example.com",
+		new(func()),
+	)
+	assert.Nil(t, resp)
+	assert.Contains(t, err.Error(), "failed to encode http request body")
+
+	// invalid url
+	_, resp, err = httpclient.Json[any, testJsonResponse](
+		context.Background(),
+		http.DefaultClient,
+		http.MethodGet,
+		"://invalid",
+		nil,
+	)
+	assert.Nil(t, resp)
+	assert.Contains(t, err.Error(), "failed to create http request")
+}
+
+func TestJsonWithClientTransport(t *testing.T) {
+	t.Parallel()
+
+	server := newTestJsonServer(t)
+	defer server.Close()
+
+	var calls int
+
+	client, err := httpclient.NewDefaultHttpClientFactory().Create(
+		httpclient.WithTransport(testRoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+
+			return http.DefaultTransport.RoundTrip(req)
+		})),
+	)
+	assert.NoError(t, err)
+
+	_, _, err = httpclient.Json[any, testJsonResponse](context.Background(), client, http.MethodDelete, server.URL+"/empty", nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, calls)
+}
+
+type testRoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f testRoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,27 @@
+package propagated
+
+import (
+	"context"
+	"net/http"
+)
+
+type ctxHeadersKey struct{}
+
+// WithHeaders appends to a given context a set of incoming request headers, to be propagated as is on the outgoing
+// requests made with this context (see the transport.PropagationTransport).
+//
+// Unlike baggage members, those are kept in process only, and never sent to downstream services unless explicitly
+// copied on an outgoing request.
+func WithHeaders(ctx context.Context, headers http.Header) context.Context {
+	return context.WithValue(ctx, ctxHeadersKey{}, headers)
+}
+
+// CtxHeaders retrieves the incoming request headers to propagate from a provided context (or returns an empty
+// [http.Header] if missing).
+func CtxHeaders(ctx context.Context) http.Header {
+	if headers, ok := ctx.Value(ctxHeadersKey{}).(http.Header); ok {
+		return headers
+	}
+
+	return http.Header{}
+}
@@ -0,0 +1,29 @@
+package propagated_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/propagated"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtxHeadersWithoutHeaders(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, http.Header{}, propagated.CtxHeaders(context.Background()))
+}
+
+func TestCtxHeadersWithHeaders(t *testing.T) {
+	t.Parallel()
+
+	headers := http.Header{}
+	headers.Set("x-tenant-id", "tenant-1")
+	headers.Set("x-locale", "fr, en;q=0.8")
+
+	ctx := propagated.WithHeaders(context.Background(), headers)
+
+	assert.Equal(t, "tenant-1", propagated.CtxHeaders(ctx).Get("X-Tenant-Id"))
+	assert.Equal(t, "fr, en;q=0.8", propagated.CtxHeaders(ctx).Get("X-Locale"))
+}
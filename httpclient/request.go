@@ -3,8 +3,9 @@ package httpclient
 import "net/http"
 
 const (
-	HeaderXRequestId  = "x-request-id"
-	HeaderTraceParent = "traceparent"
+	HeaderXRequestId    = "x-request-id"
+	HeaderTraceParent   = "traceparent"
+	BaggageRequestIdKey = "x-request-id"
 )
 
 // CopyRequestHeaders performs a copy of a specified list of headers between two [http.Request].
@@ -1,7 +1,15 @@
 package transport
 
 import (
+	"crypto/tls"
+	"net"
 	"net/http"
+	"time"
+)
+
+const (
+	DefaultDialerTimeout   = 30 * time.Second
+	DefaultDialerKeepAlive = 30 * time.Second
 )
 
 // BaseTransport is a wrapper around [http.Transport] with some [BaseTransportConfig] configuration.
@@ -11,10 +19,24 @@ type BaseTransport struct {
 }
 
 // BaseTransportConfig is the configuration of the [BaseTransport].
+//
+// The timeouts left to zero keep the [http.DefaultTransport] values, as well as a nil TlsConfig (see [NewTlsConfig])
+// and a nil Proxy, resolving the proxy from the environment (see [NewProxyFunc]).
+//
+// DisableCompression disables the transparent gzip compression: the requests are not sent with an Accept-Encoding:
+// gzip header anymore, and the responses explicitly requested compressed are returned as received, still encoded.
 type BaseTransportConfig struct {
 	MaxIdleConnections        int
 	MaxConnectionsPerHost     int
 	MaxIdleConnectionsPerHost int
+	IdleConnectionTimeout     time.Duration
+	TlsHandshakeTimeout       time.Duration
+	ExpectContinueTimeout     time.Duration
+	DialerTimeout             time.Duration
+	DialerKeepAlive           time.Duration
+	TlsConfig                 *tls.Config
+	Proxy                     ProxyFunc
+	DisableCompression        bool
 }
 
 // NewBaseTransport returns a [BaseTransport] instance with optimized default [BaseTransportConfig] configuration.
@@ -36,6 +58,44 @@ func NewBaseTransportWithConfig(config *BaseTransportConfig) *BaseTransport {
 	transport.MaxIdleConns = config.MaxIdleConnections
 	transport.MaxConnsPerHost = config.MaxConnectionsPerHost
 	transport.MaxIdleConnsPerHost = config.MaxIdleConnectionsPerHost
+	transport.DisableCompression = config.DisableCompression
+
+	if config.IdleConnectionTimeout > 0 {
+		transport.IdleConnTimeout = config.IdleConnectionTimeout
+	}
+
+	if config.TlsHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = config.TlsHandshakeTimeout
+	}
+
+	if config.ExpectContinueTimeout > 0 {
+		transport.ExpectContinueTimeout = config.ExpectContinueTimeout
+	}
+
+	if config.TlsConfig != nil {
+		transport.TLSClientConfig = config.TlsConfig
+	}
+
+	if config.Proxy != nil {
+		transport.Proxy = config.Proxy
+	}
+
+	if config.DialerTimeout > 0 || config.DialerKeepAlive > 0 {
+		dialer := &net.Dialer{
+			Timeout:   DefaultDialerTimeout,
+			KeepAlive: DefaultDialerKeepAlive,
+		}
+
+		if config.DialerTimeout > 0 {
+			dialer.Timeout = config.DialerTimeout
+		}
+
+		if config.DialerKeepAlive > 0 {
+			dialer.KeepAlive = config.DialerKeepAlive
+		}
+
+		transport.DialContext = dialer.DialContext
+	}
 
 	return &BaseTransport{
 		transport: transport,
@@ -1,9 +1,11 @@
 package transport_test
 
 import (
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/httpclient/transport"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +34,66 @@ func TestBaseTransportBaseWithConfig(t *testing.T) {
 	assert.Equal(t, 50, trans.Base().MaxIdleConns)
 	assert.Equal(t, 50, trans.Base().MaxConnsPerHost)
 	assert.Equal(t, 50, trans.Base().MaxIdleConnsPerHost)
+
+	//nolint:forcetypeassert
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+
+	assert.Equal(t, defaultTransport.IdleConnTimeout, trans.Base().IdleConnTimeout)
+	assert.Equal(t, defaultTransport.TLSHandshakeTimeout, trans.Base().TLSHandshakeTimeout)
+	assert.Equal(t, defaultTransport.ExpectContinueTimeout, trans.Base().ExpectContinueTimeout)
+	assert.False(t, trans.Base().DisableCompression)
+}
+
+func TestBaseTransportBaseWithDisabledCompression(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewBaseTransportWithConfig(
+		&transport.BaseTransportConfig{
+			DisableCompression: true,
+		},
+	)
+
+	assert.True(t, trans.Base().DisableCompression)
+}
+
+func TestBaseTransportBaseWithTimeoutsConfig(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewBaseTransportWithConfig(
+		&transport.BaseTransportConfig{
+			IdleConnectionTimeout: 10 * time.Second,
+			TlsHandshakeTimeout:   5 * time.Second,
+			ExpectContinueTimeout: 2 * time.Second,
+			DialerTimeout:         time.Second,
+			DialerKeepAlive:       time.Minute,
+		},
+	)
+
+	assert.Equal(t, 10*time.Second, trans.Base().IdleConnTimeout)
+	assert.Equal(t, 5*time.Second, trans.Base().TLSHandshakeTimeout)
+	assert.Equal(t, 2*time.Second, trans.Base().ExpectContinueTimeout)
+	assert.NotNil(t, trans.Base().DialContext)
+}
+
+func TestBaseTransportDialerTimeout(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	trans := transport.NewBaseTransportWithConfig(
+		&transport.BaseTransportConfig{
+			DialerTimeout: time.Nanosecond,
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "http://"+listener.Addr().String(), nil)
+
+	//nolint:bodyclose
+	_, err = trans.RoundTrip(req)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout")
 }
 
 func TestBaseTransportRoundTrip(t *testing.T) {
@@ -0,0 +1,529 @@
+package transport
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	HttpClientMetricsCacheRequestsCount = "http_client_cache_requests_total"
+	HeaderXFromCache                    = "X-From-Cache"
+	DefaultCacheMaxEntries              = 1000
+	DefaultCacheMaxBodySize             = 1024 * 1024
+	CacheResultHit                      = "hit"
+	CacheResultMiss                     = "miss"
+	CacheResultRevalidated              = "revalidated"
+)
+
+// CacheTransport is a wrapper around [http.RoundTripper] caching the GET responses in memory, honoring their
+// Cache-Control headers, with some [CacheTransportConfig] configuration.
+type CacheTransport struct {
+	transport       http.RoundTripper
+	config          *CacheTransportConfig
+	requestsCounter *prometheus.CounterVec
+	mutex           sync.Mutex
+	entries         map[string][]*list.Element
+	lru             *list.List
+}
+
+// CacheTransportConfig is the configuration of the [CacheTransport].
+type CacheTransportConfig struct {
+	MaxEntries      int
+	MaxBodySize     int
+	DefaultTTL      time.Duration
+	AllowAuthorized bool
+	Registry        prometheus.Registerer
+	Namespace       string
+	Subsystem       string
+}
+
+// cacheEntry is a cached response, for a request URL and the values of its response Vary headers.
+type cacheEntry struct {
+	url        string
+	vary       map[string]string
+	proto      string
+	protoMajor int
+	protoMinor int
+	status     string
+	statusCode int
+	header     http.Header
+	body       []byte
+	storedAt   time.Time
+	ttl        time.Duration
+}
+
+// NewCacheTransport returns a [CacheTransport] instance with default [CacheTransportConfig] configuration.
+func NewCacheTransport(base http.RoundTripper) *CacheTransport {
+	return NewCacheTransportWithConfig(
+		base,
+		&CacheTransportConfig{
+			MaxEntries:  DefaultCacheMaxEntries,
+			MaxBodySize: DefaultCacheMaxBodySize,
+			Registry:    prometheus.DefaultRegisterer,
+		},
+	)
+}
+
+// NewCacheTransportWithConfig returns a [CacheTransport] instance for a provided [CacheTransportConfig] configuration.
+//
+// Only the GET requests are cached, keyed by URL and by the values of the response Vary headers, up to MaxEntries
+// entries (least recently used evicted first), for bodies up to MaxBodySize bytes. The responses freshness comes
+// from their Cache-Control max-age (or Expires) header, or else from the DefaultTTL (not cached if zero). The fresh
+// entries are served without calling the wrapped [http.RoundTripper] (with the X-From-Cache header), and the stale
+// ones are revalidated with If-None-Match / If-Modified-Since if they have an ETag / Last-Modified header. The
+// responses to requests with an Authorization header are not cached, unless AllowAuthorized is true. The cache
+// hits, misses and revalidations are counted in the http_client_cache_requests_total metric.
+func NewCacheTransportWithConfig(base http.RoundTripper, config *CacheTransportConfig) *CacheTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultCacheMaxEntries
+	}
+
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultCacheMaxBodySize
+	}
+
+	if config.Registry == nil {
+		config.Registry = prometheus.DefaultRegisterer
+	}
+
+	requestsCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      HttpClientMetricsCacheRequestsCount,
+			Help:      "Number of HTTP client cacheable requests, by cache result",
+		},
+		[]string{
+			"host",
+			"result",
+		},
+	)
+
+	if err := config.Registry.Register(requestsCounter); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+
+		//nolint:forcetypeassert
+		requestsCounter = are.ExistingCollector.(*prometheus.CounterVec)
+	}
+
+	return &CacheTransport{
+		transport:       base,
+		config:          config,
+		requestsCounter: requestsCounter,
+		entries:         map[string][]*list.Element{},
+		lru:             list.New(),
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *CacheTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// Len returns the number of cached entries.
+func (t *CacheTransport) Len() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.lru.Len()
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper], unless a fresh
+// response is cached for the request.
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := t.transport.RoundTrip(req)
+
+		// unsafe methods invalidate the cached responses of their URL
+		if err == nil && req.Method != http.MethodHead && resp.StatusCode < http.StatusBadRequest {
+			t.invalidate(req.URL.String())
+		}
+
+		return resp, err
+	}
+
+	if !t.isCacheableRequest(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	now := time.Now()
+	entry := t.lookup(req)
+
+	if entry != nil && !hasCacheControlDirective(req.Header, "no-cache") && entry.isFresh(now) {
+		t.count(req, CacheResultHit)
+
+		return entry.response(req, now), nil
+	}
+
+	if entry != nil && entry.hasValidators() {
+		revalidationReq := req.Clone(req.Context())
+		if etag := entry.header.Get("ETag"); etag != "" {
+			revalidationReq.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.header.Get("Last-Modified"); lastModified != "" {
+			revalidationReq.Header.Set("If-Modified-Since", lastModified)
+		}
+
+		resp, err := t.transport.RoundTrip(revalidationReq)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusNotModified {
+			//nolint:errcheck
+			io.Copy(io.Discard, resp.Body)
+			//nolint:errcheck
+			resp.Body.Close()
+
+			now = time.Now()
+			entry = t.refresh(entry, resp.Header, now)
+			t.count(req, CacheResultRevalidated)
+
+			return entry.response(req, now), nil
+		}
+
+		t.count(req, CacheResultMiss)
+
+		return t.store(req, resp)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.count(req, CacheResultMiss)
+
+	return t.store(req, resp)
+}
+
+func (t *CacheTransport) isCacheableRequest(req *http.Request) bool {
+	if req.Header.Get("Range") != "" || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return false
+	}
+
+	if !t.config.AllowAuthorized && req.Header.Get("Authorization") != "" {
+		return false
+	}
+
+	return !hasCacheControlDirective(req.Header, "no-store")
+}
+
+// store caches a given response if cacheable, and returns it with its body rewound.
+func (t *CacheTransport) store(req *http.Request, resp *http.Response) (*http.Response, error) {
+	ttl, ok := t.responseTTL(resp)
+	if !ok {
+		return resp, nil
+	}
+
+	// the body is read up to the max body size, and kept as is (not cached) if bigger
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.config.MaxBodySize)+1))
+	if err != nil {
+		//nolint:errcheck
+		resp.Body.Close()
+
+		return nil, err
+	}
+
+	if len(body) > t.config.MaxBodySize {
+		resp.Body = &struct {
+			io.Reader
+			io.Closer
+		}{
+			io.MultiReader(bytes.NewReader(body), resp.Body),
+			resp.Body,
+		}
+
+		return resp, nil
+	}
+
+	//nolint:errcheck
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &cacheEntry{
+		url:        req.URL.String(),
+		vary:       varyValues(req, resp.Header),
+		proto:      resp.Proto,
+		protoMajor: resp.ProtoMajor,
+		protoMinor: resp.ProtoMinor,
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       body,
+		storedAt:   time.Now(),
+		ttl:        ttl,
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	elements := t.entries[entry.url]
+	for i, element := range elements {
+		//nolint:forcetypeassert
+		if sameVaryValues(element.Value.(*cacheEntry).vary, entry.vary) {
+			t.lru.Remove(element)
+			elements = append(elements[:i], elements[i+1:]...)
+
+			break
+		}
+	}
+
+	t.entries[entry.url] = append(elements, t.lru.PushFront(entry))
+
+	for t.lru.Len() > t.config.MaxEntries {
+		//nolint:forcetypeassert
+		t.remove(t.lru.Back().Value.(*cacheEntry))
+	}
+
+	return resp, nil
+}
+
+// responseTTL returns the freshness lifetime of a given response, and if it can be cached.
+func (t *CacheTransport) responseTTL(resp *http.Response) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMultipleChoices, http.StatusMovedPermanently,
+		http.StatusNotFound, http.StatusGone:
+	default:
+		return 0, false
+	}
+
+	if hasCacheControlDirective(resp.Header, "no-store") || resp.Header.Get("Vary") == "*" {
+		return 0, false
+	}
+
+	hasValidators := resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+
+	if hasCacheControlDirective(resp.Header, "no-cache") {
+		return 0, hasValidators
+	}
+
+	if maxAge, ok := cacheControlMaxAge(resp.Header); ok {
+		return maxAge, maxAge > 0 || hasValidators
+	}
+
+	if expires := resp.Header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return 0, hasValidators
+		}
+
+		date, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+
+		ttl := expiresAt.Sub(date)
+
+		return ttl, ttl > 0 || hasValidators
+	}
+
+	if resp.Header.Get("Cache-Control") == "" && t.config.DefaultTTL > 0 {
+		return t.config.DefaultTTL, true
+	}
+
+	return 0, hasValidators
+}
+
+func (t *CacheTransport) lookup(req *http.Request) *cacheEntry {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, element := range t.entries[req.URL.String()] {
+		//nolint:forcetypeassert
+		entry := element.Value.(*cacheEntry)
+		if entry.matches(req) {
+			t.lru.MoveToFront(element)
+
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// refresh returns a revalidated copy of a given entry, updated with the headers of the 304 response, the cached
+// entries being immutable.
+func (t *CacheTransport) refresh(entry *cacheEntry, header http.Header, now time.Time) *cacheEntry {
+	refreshed := *entry
+	refreshed.header = entry.header.Clone()
+	refreshed.storedAt = now
+
+	for name, values := range header {
+		if name != "Content-Length" {
+			refreshed.header[name] = values
+		}
+	}
+
+	if ttl, ok := t.responseTTL(&http.Response{StatusCode: refreshed.statusCode, Header: refreshed.header}); ok {
+		refreshed.ttl = ttl
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, element := range t.entries[entry.url] {
+		if element.Value == entry {
+			element.Value = &refreshed
+		}
+	}
+
+	return &refreshed
+}
+
+func (t *CacheTransport) invalidate(url string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, element := range t.entries[url] {
+		t.lru.Remove(element)
+	}
+
+	delete(t.entries, url)
+}
+
+// remove removes a given entry, the mutex being held.
+func (t *CacheTransport) remove(entry *cacheEntry) {
+	elements := t.entries[entry.url]
+	for i, element := range elements {
+		if element.Value == entry {
+			t.lru.Remove(element)
+			elements = append(elements[:i], elements[i+1:]...)
+
+			break
+		}
+	}
+
+	if len(elements) == 0 {
+		delete(t.entries, entry.url)
+	} else {
+		t.entries[entry.url] = elements
+	}
+}
+
+func (t *CacheTransport) count(req *http.Request, result string) {
+	t.requestsCounter.WithLabelValues(req.URL.Host, result).Inc()
+}
+
+func (e *cacheEntry) isFresh(now time.Time) bool {
+	return e.age(now) < e.ttl
+}
+
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	age := now.Sub(e.storedAt)
+
+	if initialAge, err := strconv.Atoi(e.header.Get("Age")); err == nil && initialAge > 0 {
+		age += time.Duration(initialAge) * time.Second
+	}
+
+	return age
+}
+
+func (e *cacheEntry) hasValidators() bool {
+	return e.header.Get("ETag") != "" || e.header.Get("Last-Modified") != ""
+}
+
+func (e *cacheEntry) matches(req *http.Request) bool {
+	for name, value := range e.vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *cacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	header := e.header.Clone()
+	header.Set("Age", strconv.Itoa(int(e.age(now).Seconds())))
+	header.Set(HeaderXFromCache, "1")
+
+	return &http.Response{
+		Status:        e.status,
+		StatusCode:    e.statusCode,
+		Proto:         e.proto,
+		ProtoMajor:    e.protoMajor,
+		ProtoMinor:    e.protoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// varyValues returns the values of the request headers listed in the response Vary headers.
+func varyValues(req *http.Request, header http.Header) map[string]string {
+	values := map[string]string{}
+
+	for _, vary := range header.Values("Vary") {
+		for _, name := range strings.Split(vary, ",") {
+			if name = http.CanonicalHeaderKey(strings.TrimSpace(name)); name != "" {
+				values[name] = req.Header.Get(name)
+			}
+		}
+	}
+
+	return values
+}
+
+func sameVaryValues(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasCacheControlDirective(header http.Header, directive string) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), directive) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func cacheControlMaxAge(header http.Header) (time.Duration, bool) {
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			name, arg, found := strings.Cut(strings.TrimSpace(part), "=")
+			if !found || !strings.EqualFold(name, "max-age") {
+				continue
+			}
+
+			maxAge, err := strconv.Atoi(strings.Trim(arg, `"`))
+			if err != nil || maxAge < 0 {
+				return 0, false
+			}
+
+			return time.Duration(maxAge) * time.Second, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,421 @@
+package transport_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheTestTransport(registry prometheus.Registerer, config *transport.CacheTransportConfig) *transport.CacheTransport {
+	config.Registry = registry
+	config.Namespace = "foo"
+	config.Subsystem = "bar"
+
+	return transport.NewCacheTransportWithConfig(transport.NewBaseTransport(), config)
+}
+
+// cacheTestRoundTrip performs a GET round trip with optional headers, and returns the response with its body.
+func cacheTestRoundTrip(t *testing.T, trans http.RoundTripper, url string, headers ...string) (*http.Response, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	return resp, string(body)
+}
+
+func TestNewCacheTransport(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewCacheTransport(nil)
+
+	assert.IsType(t, &transport.CacheTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+
+	// already registered metric reused
+	assert.NotPanics(t, func() {
+		transport.NewCacheTransport(nil)
+	})
+}
+
+func TestCacheTransportBase(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+
+	trans := transport.NewCacheTransportWithConfig(base, &transport.CacheTransportConfig{
+		Registry: prometheus.NewPedanticRegistry(),
+	})
+
+	assert.Equal(t, base, trans.Base())
+}
+
+func TestCacheTransportFreshHit(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, err := w.Write([]byte("document"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	trans := newCacheTestTransport(registry, &transport.CacheTransportConfig{})
+
+	resp, body := cacheTestRoundTrip(t, trans, server.URL+"/config")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "document", body)
+	assert.Empty(t, resp.Header.Get(transport.HeaderXFromCache))
+
+	resp, body = cacheTestRoundTrip(t, trans, server.URL+"/config")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "document", body)
+	assert.Equal(t, "1", resp.Header.Get(transport.HeaderXFromCache))
+	assert.Equal(t, "0", resp.Header.Get("Age"))
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, 1, trans.Len())
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	expectedMetric := `
+		# HELP foo_bar_http_client_cache_requests_total Number of HTTP client cacheable requests, by cache result
+		# TYPE foo_bar_http_client_cache_requests_total counter
+		foo_bar_http_client_cache_requests_total{host="` + host + `",result="hit"} 1
+		foo_bar_http_client_cache_requests_total{host="` + host + `",result="miss"} 1
+	`
+
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_http_client_cache_requests_total")
+	assert.NoError(t, err)
+
+	// request no-cache directive forcing a new request, the entry having no validators
+	_, body = cacheTestRoundTrip(t, trans, server.URL+"/config", "Cache-Control", "no-cache")
+	assert.Equal(t, "document", body)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCacheTransportRevalidation(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var revalidations atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations.Add(1)
+			w.Header().Set("X-Revalidated", "true")
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		_, err := w.Write([]byte("document v1"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	trans := newCacheTestTransport(registry, &transport.CacheTransportConfig{})
+
+	resp, body := cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "document v1", body)
+
+	resp, body = cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "document v1", body)
+	assert.Equal(t, "1", resp.Header.Get(transport.HeaderXFromCache))
+	assert.Equal(t, "true", resp.Header.Get("X-Revalidated"))
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int32(1), revalidations.Load())
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	expectedMetric := `
+		# HELP foo_bar_http_client_cache_requests_total Number of HTTP client cacheable requests, by cache result
+		# TYPE foo_bar_http_client_cache_requests_total counter
+		foo_bar_http_client_cache_requests_total{host="` + host + `",result="miss"} 1
+		foo_bar_http_client_cache_requests_total{host="` + host + `",result="revalidated"} 1
+	`
+
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_http_client_cache_requests_total")
+	assert.NoError(t, err)
+}
+
+func TestCacheTransportRevalidationWithChangedResponse(t *testing.T) {
+	t.Parallel()
+
+	var version atomic.Int32
+	version.Store(1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, version.Load())
+
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", etag)
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		_, err := w.Write([]byte("document " + etag))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := newCacheTestTransport(prometheus.NewPedanticRegistry(), &transport.CacheTransportConfig{})
+
+	_, body := cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, `document "v1"`, body)
+
+	version.Store(2)
+
+	resp, body := cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, `document "v2"`, body)
+	assert.Empty(t, resp.Header.Get(transport.HeaderXFromCache))
+
+	resp, body = cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, `document "v2"`, body)
+	assert.Equal(t, "1", resp.Header.Get(transport.HeaderXFromCache))
+	assert.Equal(t, 1, trans.Len())
+}
+
+func TestCacheTransportExpiry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, err := w.Write([]byte("document"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := newCacheTestTransport(prometheus.NewPedanticRegistry(), &transport.CacheTransportConfig{
+		DefaultTTL: 50 * time.Millisecond,
+	})
+
+	cacheTestRoundTrip(t, trans, server.URL)
+	resp, _ := cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, "1", resp.Header.Get(transport.HeaderXFromCache))
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+
+	resp, body := cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, "document", body)
+	assert.Empty(t, resp.Header.Get(transport.HeaderXFromCache))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCacheTransportNotCached(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		switch r.URL.Path {
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/error":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/large":
+			w.Header().Set("Cache-Control", "max-age=60")
+			_, err := w.Write([]byte(strings.Repeat("x", 20)))
+			assert.NoError(t, err)
+
+			return
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+
+		_, err := w.Write([]byte("document"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := newCacheTestTransport(prometheus.NewPedanticRegistry(), &transport.CacheTransportConfig{
+		MaxBodySize: 10,
+	})
+
+	for _, path := range []string{"/no-store", "/error"} {
+		cacheTestRoundTrip(t, trans, server.URL+path)
+		cacheTestRoundTrip(t, trans, server.URL+path)
+	}
+
+	// large body returned entirely
+	_, body := cacheTestRoundTrip(t, trans, server.URL+"/large")
+	assert.Equal(t, strings.Repeat("x", 20), body)
+	_, body = cacheTestRoundTrip(t, trans, server.URL+"/large")
+	assert.Equal(t, strings.Repeat("x", 20), body)
+
+	// authorized requests
+	cacheTestRoundTrip(t, trans, server.URL+"/authorized", "Authorization", "Bearer token")
+	cacheTestRoundTrip(t, trans, server.URL+"/authorized", "Authorization", "Bearer token")
+
+	assert.Equal(t, int32(8), calls.Load())
+	assert.Equal(t, 0, trans.Len())
+}
+
+func TestCacheTransportWithRequestCacheControl(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var revalidations atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		_, err := w.Write([]byte(fmt.Sprintf("document %d", calls.Load())))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := newCacheTestTransport(prometheus.NewPedanticRegistry(), &transport.CacheTransportConfig{})
+
+	_, body := cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, "document 1", body)
+
+	// no-store requests bypass the cache, without replacing the cached response
+	resp, body := cacheTestRoundTrip(t, trans, server.URL, "Cache-Control", "no-store")
+	assert.Equal(t, "document 2", body)
+	assert.Empty(t, resp.Header.Get(transport.HeaderXFromCache))
+
+	resp, body = cacheTestRoundTrip(t, trans, server.URL)
+	assert.Equal(t, "document 1", body)
+	assert.Equal(t, "1", resp.Header.Get(transport.HeaderXFromCache))
+
+	// no-cache requests revalidate the fresh cached response
+	resp, body = cacheTestRoundTrip(t, trans, server.URL, "Cache-Control", "no-cache")
+	assert.Equal(t, "document 1", body)
+	assert.Equal(t, "1", resp.Header.Get(transport.HeaderXFromCache))
+
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, int32(1), revalidations.Load())
+	assert.Equal(t, 1, trans.Len())
+}
+
+func TestCacheTransportWithAllowAuthorized(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Authorization")
+		_, err := w.Write([]byte(r.Header.Get("Authorization")))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := newCacheTestTransport(prometheus.NewPedanticRegistry(), &transport.CacheTransportConfig{
+		AllowAuthorized: true,
+	})
+
+	_, body := cacheTestRoundTrip(t, trans, server.URL, "Authorization", "Bearer foo")
+	assert.Equal(t, "Bearer foo", body)
+
+	_, body = cacheTestRoundTrip(t, trans, server.URL, "Authorization", "Bearer bar")
+	assert.Equal(t, "Bearer bar", body)
+
+	// varied entries
+	resp, body := cacheTestRoundTrip(t, trans, server.URL, "Authorization", "Bearer foo")
+	assert.Equal(t, "Bearer foo", body)
+	assert.Equal(t, "1", resp.Header.Get(transport.HeaderXFromCache))
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, 2, trans.Len())
+}
+
+func TestCacheTransportEvictionAndInvalidation(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, err := w.Write([]byte(r.URL.Path))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := newCacheTestTransport(prometheus.NewPedanticRegistry(), &transport.CacheTransportConfig{
+		MaxEntries: 2,
+	})
+
+	cacheTestRoundTrip(t, trans, server.URL+"/a")
+	cacheTestRoundTrip(t, trans, server.URL+"/b")
+	cacheTestRoundTrip(t, trans, server.URL+"/a")
+	cacheTestRoundTrip(t, trans, server.URL+"/c")
+
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 2, trans.Len())
+
+	// least recently used /b evicted
+	cacheTestRoundTrip(t, trans, server.URL+"/a")
+	assert.Equal(t, int32(3), calls.Load())
+
+	cacheTestRoundTrip(t, trans, server.URL+"/b")
+	assert.Equal(t, int32(4), calls.Load())
+
+	// unsafe method invalidating /b
+	req := httptest.NewRequest(http.MethodPost, server.URL+"/b", nil)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	cacheTestRoundTrip(t, trans, server.URL+"/b")
+	assert.Equal(t, int32(6), calls.Load())
+}
@@ -0,0 +1,389 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+const (
+	HttpClientMetricsCircuitBreakerState     = "http_client_circuit_breaker_state"
+	DefaultCircuitBreakerFailureRatio        = 0.5
+	DefaultCircuitBreakerMinimumRequests     = 10
+	DefaultCircuitBreakerOpenTimeout         = 30 * time.Second
+	DefaultCircuitBreakerHalfOpenMaxRequests = 1
+	DefaultCircuitBreakerInterval            = 60 * time.Second
+)
+
+// ErrCircuitOpen is returned by the [CircuitBreakerTransport] when the circuit breaker of the request host is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerState is the state of a host circuit breaker.
+type CircuitBreakerState int
+
+const (
+	CircuitBreakerClosed   CircuitBreakerState = iota // requests are allowed
+	CircuitBreakerHalfOpen                            // a limited number of trial requests are allowed
+	CircuitBreakerOpen                                // requests are rejected
+)
+
+// String returns a string representation of the [CircuitBreakerState].
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	case CircuitBreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSettings are the settings of a host circuit breaker.
+type CircuitBreakerSettings struct {
+	FailureRatio        float64
+	MinimumRequests     int
+	OpenTimeout         time.Duration
+	HalfOpenMaxRequests int
+	Interval            time.Duration
+}
+
+// DefaultCircuitBreakerSettings are the default [CircuitBreakerSettings].
+func DefaultCircuitBreakerSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		FailureRatio:        DefaultCircuitBreakerFailureRatio,
+		MinimumRequests:     DefaultCircuitBreakerMinimumRequests,
+		OpenTimeout:         DefaultCircuitBreakerOpenTimeout,
+		HalfOpenMaxRequests: DefaultCircuitBreakerHalfOpenMaxRequests,
+		Interval:            DefaultCircuitBreakerInterval,
+	}
+}
+
+// CircuitBreakerTransport is a wrapper around [http.RoundTripper] failing fast the requests to the hosts failing too
+// often, with some [CircuitBreakerTransportConfig] configuration.
+type CircuitBreakerTransport struct {
+	transport  http.RoundTripper
+	config     *CircuitBreakerTransportConfig
+	stateGauge *prometheus.GaugeVec
+	mutex      sync.Mutex
+	breakers   map[string]*circuitBreaker
+}
+
+// CircuitBreakerTransportConfig is the configuration of the [CircuitBreakerTransport].
+type CircuitBreakerTransportConfig struct {
+	Settings     CircuitBreakerSettings
+	HostSettings map[string]CircuitBreakerSettings
+	Registry     prometheus.Registerer
+	Namespace    string
+	Subsystem    string
+}
+
+// NewCircuitBreakerTransport returns a [CircuitBreakerTransport] instance with default [CircuitBreakerTransportConfig]
+// configuration.
+func NewCircuitBreakerTransport(base http.RoundTripper) *CircuitBreakerTransport {
+	return NewCircuitBreakerTransportWithConfig(
+		base,
+		&CircuitBreakerTransportConfig{
+			Settings: DefaultCircuitBreakerSettings(),
+			Registry: prometheus.DefaultRegisterer,
+		},
+	)
+}
+
+// NewCircuitBreakerTransportWithConfig returns a [CircuitBreakerTransport] instance for a provided
+// [CircuitBreakerTransportConfig] configuration.
+//
+// A circuit breaker is kept per request host, with the HostSettings of this host (their unset fields falling back to
+// Settings). Transport errors and 5xx responses are failures: once at least MinimumRequests requests were made within
+// the Interval, and their failures ratio reaches FailureRatio, the circuit opens and the requests fail immediately
+// with [ErrCircuitOpen]. After OpenTimeout, the circuit becomes half-open and lets HalfOpenMaxRequests trial requests
+// through: it closes if they all succeed, and opens again on the first failure. The state transitions are logged,
+// and exposed in the http_client_circuit_breaker_state metric (0 closed, 1 half-open, 2 open).
+func NewCircuitBreakerTransportWithConfig(
+	base http.RoundTripper,
+	config *CircuitBreakerTransportConfig,
+) *CircuitBreakerTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	config.Settings = mergeCircuitBreakerSettings(config.Settings, DefaultCircuitBreakerSettings())
+
+	if config.Registry == nil {
+		config.Registry = prometheus.DefaultRegisterer
+	}
+
+	stateGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      HttpClientMetricsCircuitBreakerState,
+			Help:      "State of the HTTP client circuit breakers (0 closed, 1 half-open, 2 open)",
+		},
+		[]string{
+			"host",
+		},
+	)
+
+	if err := config.Registry.Register(stateGauge); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+
+		//nolint:forcetypeassert
+		stateGauge = are.ExistingCollector.(*prometheus.GaugeVec)
+	}
+
+	return &CircuitBreakerTransport{
+		transport:  base,
+		config:     config,
+		stateGauge: stateGauge,
+		breakers:   map[string]*circuitBreaker{},
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *CircuitBreakerTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// State returns the [CircuitBreakerState] of the circuit breaker of a given host.
+func (t *CircuitBreakerTransport) State(host string) CircuitBreakerState {
+	t.mutex.Lock()
+	breaker, ok := t.breakers[host]
+	t.mutex.Unlock()
+
+	if !ok {
+		return CircuitBreakerClosed
+	}
+
+	return breaker.currentState(context.Background(), time.Now())
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper], unless the circuit
+// breaker of the request host is open.
+func (t *CircuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	breaker := t.breaker(req)
+
+	generation, err := breaker.allow(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+
+	// caller cancellations are not host failures
+	success := (err == nil || errors.Is(err, context.Canceled)) &&
+		(resp == nil || resp.StatusCode < http.StatusInternalServerError)
+
+	breaker.record(ctx, generation, success, time.Now())
+
+	return resp, err
+}
+
+func (t *CircuitBreakerTransport) breaker(req *http.Request) *circuitBreaker {
+	host := req.URL.Host
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if breaker, ok := t.breakers[host]; ok {
+		return breaker
+	}
+
+	settings, ok := t.config.HostSettings[host]
+	if !ok {
+		settings, ok = t.config.HostSettings[req.URL.Hostname()]
+	}
+
+	if ok {
+		settings = mergeCircuitBreakerSettings(settings, t.config.Settings)
+	} else {
+		settings = t.config.Settings
+	}
+
+	breaker := &circuitBreaker{
+		host:     host,
+		settings: settings,
+		expiry:   time.Now().Add(settings.Interval),
+		onStateChange: func(ctx context.Context, from CircuitBreakerState, to CircuitBreakerState) {
+			t.stateGauge.WithLabelValues(host).Set(float64(to))
+
+			level := zerolog.InfoLevel
+			if to == CircuitBreakerOpen {
+				level = zerolog.WarnLevel
+			}
+
+			log.CtxLogger(ctx).
+				WithLevel(level).
+				Str("host", host).
+				Str("from", from.String()).
+				Str("to", to.String()).
+				Msg("http client circuit breaker state change")
+		},
+	}
+
+	t.stateGauge.WithLabelValues(host).Set(float64(CircuitBreakerClosed))
+	t.breakers[host] = breaker
+
+	return breaker
+}
+
+// mergeCircuitBreakerSettings returns the settings, with their unset fields taken from the fallback settings.
+func mergeCircuitBreakerSettings(settings CircuitBreakerSettings, fallback CircuitBreakerSettings) CircuitBreakerSettings {
+	if settings.FailureRatio <= 0 || settings.FailureRatio > 1 {
+		settings.FailureRatio = fallback.FailureRatio
+	}
+
+	if settings.MinimumRequests <= 0 {
+		settings.MinimumRequests = fallback.MinimumRequests
+	}
+
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = fallback.OpenTimeout
+	}
+
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = fallback.HalfOpenMaxRequests
+	}
+
+	if settings.Interval <= 0 {
+		settings.Interval = fallback.Interval
+	}
+
+	return settings
+}
+
+// circuitBreaker is the circuit breaker of a host.
+type circuitBreaker struct {
+	mutex         sync.Mutex
+	host          string
+	settings      CircuitBreakerSettings
+	onStateChange func(ctx context.Context, from CircuitBreakerState, to CircuitBreakerState)
+	state         CircuitBreakerState
+	generation    uint64
+	expiry        time.Time
+	requests      int
+	failures      int
+	successes     int
+}
+
+// allow returns the current generation if the request is allowed, or an error wrapping [ErrCircuitOpen] otherwise.
+func (b *circuitBreaker) allow(ctx context.Context, now time.Time) (uint64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refresh(ctx, now)
+
+	switch b.state {
+	case CircuitBreakerOpen:
+		return b.generation, fmt.Errorf("%w for host %s", ErrCircuitOpen, b.host)
+	case CircuitBreakerHalfOpen:
+		if b.requests >= b.settings.HalfOpenMaxRequests {
+			return b.generation, fmt.Errorf("%w for host %s (half-open)", ErrCircuitOpen, b.host)
+		}
+	}
+
+	b.requests++
+
+	return b.generation, nil
+}
+
+// record records a request outcome, ignored if the state changed since the request was allowed.
+func (b *circuitBreaker) record(ctx context.Context, generation uint64, success bool, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refresh(ctx, now)
+
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case CircuitBreakerClosed:
+		if success {
+			b.successes++
+		} else {
+			b.failures++
+		}
+
+		if b.requests >= b.settings.MinimumRequests &&
+			float64(b.failures)/float64(b.requests) >= b.settings.FailureRatio {
+			b.setState(ctx, CircuitBreakerOpen, now)
+		}
+	case CircuitBreakerHalfOpen:
+		if !success {
+			b.setState(ctx, CircuitBreakerOpen, now)
+
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.settings.HalfOpenMaxRequests {
+			b.setState(ctx, CircuitBreakerClosed, now)
+		}
+	}
+}
+
+func (b *circuitBreaker) currentState(ctx context.Context, now time.Time) CircuitBreakerState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refresh(ctx, now)
+
+	return b.state
+}
+
+// refresh moves an open circuit to half-open after its timeout, and resets the counts of a closed circuit after its
+// interval.
+func (b *circuitBreaker) refresh(ctx context.Context, now time.Time) {
+	switch b.state {
+	case CircuitBreakerClosed:
+		if now.After(b.expiry) {
+			b.reset(now)
+		}
+	case CircuitBreakerOpen:
+		if now.After(b.expiry) {
+			b.setState(ctx, CircuitBreakerHalfOpen, now)
+		}
+	}
+}
+
+func (b *circuitBreaker) setState(ctx context.Context, state CircuitBreakerState, now time.Time) {
+	from := b.state
+	b.state = state
+	b.reset(now)
+
+	if b.onStateChange != nil {
+		b.onStateChange(ctx, from, state)
+	}
+}
+
+// reset starts a new generation, with empty counts and the expiry of the current state.
+func (b *circuitBreaker) reset(now time.Time) {
+	b.generation++
+	b.requests = 0
+	b.failures = 0
+	b.successes = 0
+
+	switch b.state {
+	case CircuitBreakerClosed:
+		b.expiry = now.Add(b.settings.Interval)
+	case CircuitBreakerOpen:
+		b.expiry = now.Add(b.settings.OpenTimeout)
+	default:
+		b.expiry = time.Time{}
+	}
+}
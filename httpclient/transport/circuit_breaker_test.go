@@ -0,0 +1,250 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCircuitBreakerTestTransport(registry prometheus.Registerer) *transport.CircuitBreakerTransport {
+	return transport.NewCircuitBreakerTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.CircuitBreakerTransportConfig{
+			Settings: transport.CircuitBreakerSettings{
+				FailureRatio:        0.5,
+				MinimumRequests:     4,
+				OpenTimeout:         50 * time.Millisecond,
+				HalfOpenMaxRequests: 2,
+			},
+			Registry:  registry,
+			Namespace: "foo",
+			Subsystem: "bar",
+		},
+	)
+}
+
+func TestNewCircuitBreakerTransport(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewCircuitBreakerTransport(nil)
+
+	assert.IsType(t, &transport.CircuitBreakerTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+
+	// already registered metric reused
+	assert.NotPanics(t, func() {
+		transport.NewCircuitBreakerTransport(nil)
+	})
+}
+
+func TestCircuitBreakerTransportBase(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+
+	trans := transport.NewCircuitBreakerTransportWithConfig(base, &transport.CircuitBreakerTransportConfig{
+		Registry: prometheus.NewPedanticRegistry(),
+	})
+
+	assert.Equal(t, base, trans.Base())
+}
+
+func TestCircuitBreakerStateString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "closed", transport.CircuitBreakerClosed.String())
+	assert.Equal(t, "half-open", transport.CircuitBreakerHalfOpen.String())
+	assert.Equal(t, "open", transport.CircuitBreakerOpen.String())
+	assert.Equal(t, "unknown", transport.CircuitBreakerState(-1).String())
+}
+
+func TestCircuitBreakerTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	var healthy atomic.Bool
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	trans := newCircuitBreakerTestTransport(registry)
+	ctx := logger.WithContext(context.Background())
+
+	roundTrip := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := trans.RoundTrip(req)
+		if err == nil {
+			assert.NoError(t, resp.Body.Close())
+		}
+
+		return resp, err
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// trip the breaker
+	for i := 0; i < 4; i++ {
+		resp, err := roundTrip()
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	assert.Equal(t, transport.CircuitBreakerOpen, trans.State(host))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"host":    host,
+		"from":    "closed",
+		"to":      "open",
+		"message": "http client circuit breaker state change",
+	})
+
+	expectedMetric := `
+		# HELP foo_bar_http_client_circuit_breaker_state State of the HTTP client circuit breakers (0 closed, 1 half-open, 2 open)
+		# TYPE foo_bar_http_client_circuit_breaker_state gauge
+		foo_bar_http_client_circuit_breaker_state{host="` + host + `"} 2
+	`
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_http_client_circuit_breaker_state")
+	assert.NoError(t, err)
+
+	// fast fail
+	start := time.Now()
+
+	_, err = roundTrip()
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, transport.ErrCircuitOpen)
+	assert.ErrorIs(t, err, httpclient.ErrCircuitOpen)
+	assert.Contains(t, err.Error(), host)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, int32(4), requests.Load())
+
+	// recovery after the open timeout
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+
+	assert.Equal(t, transport.CircuitBreakerHalfOpen, trans.State(host))
+
+	for i := 0; i < 2; i++ {
+		resp, err := roundTrip()
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	assert.Equal(t, transport.CircuitBreakerClosed, trans.State(host))
+	assert.Equal(t, int32(6), requests.Load())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"host":    host,
+		"from":    "half-open",
+		"to":      "closed",
+		"message": "http client circuit breaker state change",
+	})
+
+	expectedMetric = `
+		# HELP foo_bar_http_client_circuit_breaker_state State of the HTTP client circuit breakers (0 closed, 1 half-open, 2 open)
+		# TYPE foo_bar_http_client_circuit_breaker_state gauge
+		foo_bar_http_client_circuit_breaker_state{host="` + host + `"} 0
+	`
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_http_client_circuit_breaker_state")
+	assert.NoError(t, err)
+}
+
+func TestCircuitBreakerTransportRoundTripHalfOpenFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	trans := newCircuitBreakerTestTransport(prometheus.NewPedanticRegistry())
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	for i := 0; i < 4; i++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+	}
+
+	assert.Equal(t, transport.CircuitBreakerOpen, trans.State(host))
+
+	time.Sleep(60 * time.Millisecond)
+
+	// failed trial request opens the circuit again
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, transport.CircuitBreakerOpen, trans.State(host))
+}
+
+func TestCircuitBreakerTransportRoundTripWithHostSettings(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	trans := transport.NewCircuitBreakerTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.CircuitBreakerTransportConfig{
+			HostSettings: map[string]transport.CircuitBreakerSettings{
+				"127.0.0.1": {
+					MinimumRequests: 1,
+				},
+			},
+			Registry: prometheus.NewPedanticRegistry(),
+		},
+	)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, transport.CircuitBreakerOpen, trans.State(strings.TrimPrefix(server.URL, "http://")))
+	assert.Equal(t, transport.CircuitBreakerClosed, trans.State("example.com"))
+}
@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"net/http"
+)
+
+// HeadersTransport is a wrapper around [http.RoundTripper] with some [HeadersTransportConfig] configuration.
+type HeadersTransport struct {
+	transport http.RoundTripper
+	config    *HeadersTransportConfig
+}
+
+// HeadersTransportConfig is the configuration of the [HeadersTransport].
+type HeadersTransportConfig struct {
+	Headers map[string]string
+}
+
+// NewHeadersTransport returns a [HeadersTransport] instance for a provided map of default headers.
+func NewHeadersTransport(base http.RoundTripper, headers map[string]string) *HeadersTransport {
+	return NewHeadersTransportWithConfig(
+		base,
+		&HeadersTransportConfig{
+			Headers: headers,
+		},
+	)
+}
+
+// NewHeadersTransportWithConfig returns a [HeadersTransport] instance for a provided [HeadersTransportConfig]
+// configuration.
+func NewHeadersTransportWithConfig(base http.RoundTripper, config *HeadersTransportConfig) *HeadersTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	return &HeadersTransport{
+		transport: base,
+		config:    config,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *HeadersTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+// It adds the configured default headers to the outgoing request, if not already set on it.
+func (t *HeadersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := false
+	for header, value := range t.config.Headers {
+		if value == "" || req.Header.Get(header) != "" {
+			continue
+		}
+
+		if !cloned {
+			req = req.Clone(req.Context())
+			cloned = true
+		}
+
+		req.Header.Set(header, value)
+	}
+
+	return t.transport.RoundTrip(req)
+}
@@ -0,0 +1,79 @@
+package transport_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHeadersTransport(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewHeadersTransport(nil, nil)
+
+	assert.IsType(t, &transport.HeadersTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+}
+
+func TestHeadersTransportBase(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+
+	trans := transport.NewHeadersTransport(base, nil)
+
+	assert.Equal(t, base, trans.Base())
+}
+
+func TestHeadersTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-user-agent", r.Header.Get("User-Agent"))
+		w.Header().Set("received-api-key", r.Header.Get("X-Api-Key"))
+		w.Header().Set("received-tenant-id", r.Header.Get("X-Tenant-Id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	trans := transport.NewHeadersTransport(
+		transport.NewBaseTransport(),
+		map[string]string{
+			"User-Agent":  "app/1.0.0 yokai-httpclient",
+			"x-api-key":   "default-key",
+			"X-Tenant-Id": "",
+		},
+	)
+
+	// default headers
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "app/1.0.0 yokai-httpclient", resp.Header.Get("received-user-agent"))
+	assert.Equal(t, "default-key", resp.Header.Get("received-api-key"))
+	assert.Empty(t, resp.Header.Get("received-tenant-id"))
+	assert.Empty(t, req.Header.Get("X-Api-Key"))
+
+	// explicit request headers win
+	req = httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("User-Agent", "custom-agent")
+	req.Header.Set("X-Api-Key", "request-key")
+
+	resp, err = trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "custom-agent", resp.Header.Get("received-user-agent"))
+	assert.Equal(t, "request-key", resp.Header.Get("received-api-key"))
+}
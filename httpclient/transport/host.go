@@ -0,0 +1,34 @@
+package transport
+
+import (
+	"strings"
+)
+
+// MatchHost returns true if the provided host (without port) matches one of the provided patterns: a pattern starting
+// with a dot is a suffix match (.internal.local matching api.internal.local), any other pattern an exact match, both
+// case-insensitive.
+func MatchHost(host string, patterns ...string) bool {
+	host = strings.ToLower(host)
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+		if pattern == "" {
+			continue
+		}
+
+		if strings.HasPrefix(pattern, ".") {
+			if strings.HasSuffix(host, pattern) {
+				return true
+			}
+
+			continue
+		}
+
+		if host == pattern {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package transport_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchHost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		host     string
+		patterns []string
+		expected bool
+	}{
+		{"status.example.com", nil, false},
+		{"status.example.com", []string{""}, false},
+		{"status.example.com", []string{"status.example.com"}, true},
+		{"Status.Example.com", []string{"status.example.COM"}, true},
+		{"api.example.com", []string{"status.example.com"}, false},
+		{"api.internal.local", []string{".internal.local"}, true},
+		{"internal.local", []string{".internal.local"}, false},
+		{"api.notinternal.local", []string{".internal.local"}, false},
+		{"api.example.com", []string{"status.example.com", ".example.com"}, true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, transport.MatchHost(tt.host, tt.patterns...), "%s %v", tt.host, tt.patterns)
+	}
+}
@@ -1,14 +1,51 @@
 package transport
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ankorstore/yokai/log"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// RedactedValue is the value replacing the redacted JSON fields values in the logged bodies, and the redacted
+// headers values.
+const RedactedValue = "[redacted]"
+
+// StreamingBodyValue is the value replacing the skipped streamed response bodies in the logs.
+const StreamingBodyValue = "<streaming>"
+
+// MaxRedactedBodySize is the maximum size in bytes of the JSON response bodies captured to be redacted before their
+// truncation: the larger ones are not logged, since they cannot be redacted.
+const MaxRedactedBodySize = 1 << 20
+
+// Handling modes of the streamed response bodies (with a content length unknown or exceeding the LogBodyMaxSize).
+const (
+	ResponseBodyStreamingCapture = "capture" // captured while read by the caller, up to LogBodyMaxSize
+	ResponseBodyStreamingSkip    = "skip"    // not captured, logged as <streaming> as soon as the response is received
+)
+
+// RedactedHeaders are the headers always redacted in the logs, regardless of the configuration.
+func RedactedHeaders() []string {
+	return []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+	}
+}
+
 // LoggerTransport is a wrapper around [http.RoundTripper] with some [LoggerTransportConfig] configuration.
 type LoggerTransport struct {
 	transport http.RoundTripper
@@ -24,6 +61,13 @@ type LoggerTransportConfig struct {
 	LogRequestLevel                  zerolog.Level
 	LogResponseLevel                 zerolog.Level
 	LogResponseLevelFromResponseCode bool
+	LogResponseLevelsByResponseCode  map[string]zerolog.Level
+	LogBodyMaxSize                   int
+	LogResponseBodyStreaming         string
+	RedactJsonFields                 []string
+	RequestHeadersToLog              map[string]string
+	ResponseHeadersToLog             map[string]string
+	ExcludeHosts                     []string
 }
 
 // NewLoggerTransport returns a [LoggerTransport] instance with default [LoggerTransportConfig] configuration.
@@ -38,11 +82,37 @@ func NewLoggerTransport(base http.RoundTripper) *LoggerTransport {
 			LogRequestLevel:                  zerolog.InfoLevel,
 			LogResponseLevel:                 zerolog.InfoLevel,
 			LogResponseLevelFromResponseCode: false,
+			LogBodyMaxSize:                   0,
+			LogResponseBodyStreaming:         ResponseBodyStreamingCapture,
+			RedactJsonFields:                 nil,
+			RequestHeadersToLog:              nil,
+			ResponseHeadersToLog:             nil,
+			ExcludeHosts:                     nil,
 		},
 	)
 }
 
 // NewLoggerTransportWithConfig returns a [LoggerTransport] instance for a provided [LoggerTransportConfig] configuration.
+//
+// The logged bodies have the values of their RedactJsonFields JSON fields (at any depth) replaced by [redacted], and
+// are truncated to LogBodyMaxSize bytes (unlimited if zero). The binary bodies are never logged, only their size.
+//
+// The response bodies are never read ahead of the caller: they are captured (up to LogBodyMaxSize bytes, or
+// [MaxRedactedBodySize] for the JSON ones to redact) while the caller reads them, and the response is logged once its
+// body is fully read or closed (the not yet read part being then only read up to the capture size). If
+// LogResponseBodyStreaming is [ResponseBodyStreamingSkip], the responses with a content length unknown or exceeding
+// LogBodyMaxSize are instead logged as soon as received, with a [StreamingBodyValue] body. The logged latency is
+// always the one of the response headers reception.
+//
+// The RequestHeadersToLog and ResponseHeadersToLog headers are logged in their mapped log fields (multiple values
+// being joined with commas), and the [RedactedHeaders] values are always replaced by [redacted], in these fields as
+// well as in the requests and responses details.
+//
+// The LogResponseLevelsByResponseCode levels are keyed by exact response codes (like 404) or classes (like 4xx), the
+// exact codes taking precedence: if the response code has no configured level, the response is logged at the
+// LogResponseLevel level, or at warn for 4xx and error for 5xx if LogResponseLevelFromResponseCode is enabled.
+//
+// The requests to the ExcludeHosts hosts (see [MatchHost]) are not logged at all.
 func NewLoggerTransportWithConfig(base http.RoundTripper, config *LoggerTransportConfig) *LoggerTransport {
 	if base == nil {
 		base = NewBaseTransport()
@@ -61,13 +131,41 @@ func (t *LoggerTransport) Base() http.RoundTripper {
 
 // RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
 func (t *LoggerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	logger := log.CtxLogger(req.Context())
+	if MatchHost(req.URL.Hostname(), t.config.ExcludeHosts...) {
+		return t.transport.RoundTrip(req)
+	}
+
+	// contextual logger, with tracing fields only for valid and sampled spans
+	loggerContext := zerolog.Ctx(req.Context()).With()
+
+	spanContext := trace.SpanContextFromContext(req.Context())
+	if spanContext.IsValid() && spanContext.IsSampled() {
+		loggerContext = loggerContext.
+			Str("traceID", spanContext.TraceID().String()).
+			Str("spanID", spanContext.SpanID().String())
+	}
+
+	logger := log.FromZerolog(loggerContext.Logger())
 
 	reqEvt := logger.WithLevel(t.config.LogRequestLevel)
 
+	logHeaders(reqEvt, req.Header, t.config.RequestHeadersToLog)
+
 	if t.config.LogRequest {
-		reqDump, err := httputil.DumpRequestOut(req, t.config.LogRequestBody)
+		redactedReq := req.Clone(req.Context())
+		redactHeaders(redactedReq.Header)
+
+		reqDump, err := httputil.DumpRequestOut(redactedReq, false)
 		if err == nil {
+			if t.config.LogRequestBody && req.Body != nil && req.Body != http.NoBody {
+				body, bodyErr := io.ReadAll(req.Body)
+				req.Body = io.NopCloser(bytes.NewReader(body))
+
+				if bodyErr == nil {
+					reqDump = append(reqDump, t.formatBody(req.Header, body, int64(len(body)))...)
+				}
+			}
+
 			reqEvt.Bytes("request", reqDump)
 		}
 	}
@@ -81,33 +179,414 @@ func (t *LoggerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	resp, err := t.transport.RoundTrip(req)
 	latency := time.Since(start).String()
 
-	var respEvt *zerolog.Event
-	if t.config.LogResponseLevelFromResponseCode {
-		switch {
-		case resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError:
-			respEvt = logger.Warn()
-		case resp.StatusCode >= http.StatusInternalServerError:
-			respEvt = logger.Error()
-		default:
-			respEvt = logger.WithLevel(t.config.LogResponseLevel)
-		}
-	} else {
-		respEvt = logger.WithLevel(t.config.LogResponseLevel)
-	}
+	if err != nil {
+		logger.
+			Error().
+			Err(err).
+			Str("method", req.Method).
+			Str("url", req.URL.String()).
+			Str("latency", latency).
+			Msg("http client response")
 
-	if t.config.LogResponse {
-		respDump, err := httputil.DumpResponse(resp, t.config.LogResponseBody)
-		if err == nil {
-			respEvt.Bytes("response", respDump)
-		}
+		return resp, err
 	}
 
+	respEvt := logger.WithLevel(t.responseLevel(resp.StatusCode))
+
+	logHeaders(respEvt, resp.Header, t.config.ResponseHeadersToLog)
+
 	respEvt.
 		Str("method", resp.Request.Method).
 		Str("url", resp.Request.URL.String()).
 		Int("code", resp.StatusCode).
-		Str("latency", latency).
-		Msg("http client response")
+		Str("latency", latency)
+
+	if t.config.LogResponse {
+		redactedResp := *resp
+		redactedResp.Header = resp.Header.Clone()
+		redactHeaders(redactedResp.Header)
+
+		respDump, dumpErr := httputil.DumpResponse(&redactedResp, false)
+		if dumpErr == nil {
+			if t.config.LogResponseBody && t.hasReadableBody(resp) {
+				if t.skipResponseBody(resp) {
+					respDump = append(respDump, StreamingBodyValue...)
+				} else {
+					header := resp.Header.Clone()
+
+					// logged once the body is fully read or closed
+					resp.Body = newLoggerBody(resp, t.captureSize(header), func(body []byte, size int64) {
+						respEvt.
+							Bytes("response", append(respDump, t.formatBody(header, body, size)...)).
+							Msg("http client response")
+					})
+
+					return resp, err
+				}
+			}
+
+			respEvt.Bytes("response", respDump)
+		}
+	}
+
+	respEvt.Msg("http client response")
 
 	return resp, err
 }
+
+// hasReadableBody returns true if a response has a body that can be captured (not the connection of a protocol switch).
+func (t *LoggerTransport) hasReadableBody(resp *http.Response) bool {
+	return resp.Body != nil && resp.Body != http.NoBody && resp.StatusCode != http.StatusSwitchingProtocols
+}
+
+// skipResponseBody returns true if a response body is streamed and configured to be skipped.
+func (t *LoggerTransport) skipResponseBody(resp *http.Response) bool {
+	if t.config.LogResponseBodyStreaming != ResponseBodyStreamingSkip {
+		return false
+	}
+
+	return resp.ContentLength < 0 || (t.config.LogBodyMaxSize > 0 && resp.ContentLength > int64(t.config.LogBodyMaxSize))
+}
+
+// captureSize returns the maximum size in bytes of a response body to capture for logging (negative if unlimited).
+func (t *LoggerTransport) captureSize(header http.Header) int {
+	contentType := header.Get("Content-Type")
+
+	if isEncodedContent(header) || (contentType != "" && !isTextualContentType(contentType)) {
+		return 0
+	}
+
+	if t.config.LogBodyMaxSize <= 0 {
+		return -1
+	}
+
+	size := t.config.LogBodyMaxSize
+
+	// enough to detect the content type
+	if contentType == "" && size < sniffLen {
+		size = sniffLen
+	}
+
+	// enough to redact before truncating
+	if len(t.config.RedactJsonFields) > 0 && (contentType == "" || isJsonContentType(contentType)) && size < MaxRedactedBodySize {
+		size = MaxRedactedBodySize
+	}
+
+	return size
+}
+
+// responseLevel returns the log level of a response, from its response code.
+func (t *LoggerTransport) responseLevel(code int) zerolog.Level {
+	if level, ok := t.config.LogResponseLevelsByResponseCode[strconv.Itoa(code)]; ok {
+		return level
+	}
+
+	if level, ok := t.config.LogResponseLevelsByResponseCode[fmt.Sprintf("%dxx", code/100)]; ok {
+		return level
+	}
+
+	if t.config.LogResponseLevelFromResponseCode {
+		switch {
+		case code >= http.StatusBadRequest && code < http.StatusInternalServerError:
+			return zerolog.WarnLevel
+		case code >= http.StatusInternalServerError:
+			return zerolog.ErrorLevel
+		}
+	}
+
+	return t.config.LogResponseLevel
+}
+
+// formatBody returns the body to log: redacted, truncated, or replaced by its size for binary content types and
+// encoded (like compressed) contents.
+//
+// The body can be only the first bytes of a body of a given total size (negative if unknown), in which case it is
+// logged truncated, or not logged at all if it should be redacted.
+func (t *LoggerTransport) formatBody(header http.Header, body []byte, size int64) []byte {
+	if len(body) == 0 && size <= 0 {
+		return body
+	}
+
+	if isEncodedContent(header) {
+		encoding := strings.Join(header.Values("Content-Encoding"), ",")
+
+		if size < 0 {
+			return []byte(fmt.Sprintf("[%s encoded body]", encoding))
+		}
+
+		return []byte(fmt.Sprintf("[%s encoded body, %d bytes]", encoding, size))
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+
+	if !isTextualContentType(contentType) {
+		if size < 0 {
+			return []byte("[binary body]")
+		}
+
+		return []byte(fmt.Sprintf("[binary body, %d bytes]", size))
+	}
+
+	partial := size < 0 || int64(len(body)) < size
+
+	if len(t.config.RedactJsonFields) > 0 {
+		if partial && isJsonContentType(contentType) {
+			if size < 0 {
+				return []byte("[body too large to be redacted]")
+			}
+
+			return []byte(fmt.Sprintf("[body too large to be redacted, %d bytes]", size))
+		}
+
+		body = redactJsonFields(body, t.config.RedactJsonFields)
+	}
+
+	total := int64(len(body))
+	if partial {
+		total = size
+	}
+
+	if (t.config.LogBodyMaxSize > 0 && len(body) > t.config.LogBodyMaxSize) || partial {
+		end := len(body)
+		if t.config.LogBodyMaxSize > 0 && end > t.config.LogBodyMaxSize {
+			end = t.config.LogBodyMaxSize
+		}
+
+		truncated := append([]byte{}, body[:end]...)
+
+		if total < 0 {
+			return append(truncated, "...[truncated]"...)
+		}
+
+		return append(truncated, fmt.Sprintf("...[truncated, %d bytes total]", total)...)
+	}
+
+	return body
+}
+
+// logHeaders adds to a log event the headers to log in their mapped log fields, redacting the [RedactedHeaders].
+func logHeaders(evt *zerolog.Event, header http.Header, headersToLog map[string]string) {
+	for headerName, fieldName := range headersToLog {
+		values := header.Values(headerName)
+		if len(values) == 0 {
+			continue
+		}
+
+		if isRedactedHeader(headerName) {
+			evt.Str(fieldName, RedactedValue)
+		} else {
+			evt.Str(fieldName, strings.Join(values, ","))
+		}
+	}
+}
+
+// redactHeaders replaces in place the values of the [RedactedHeaders].
+func redactHeaders(header http.Header) {
+	for _, headerName := range RedactedHeaders() {
+		if header.Get(headerName) != "" {
+			header.Set(headerName, RedactedValue)
+		}
+	}
+}
+
+func isRedactedHeader(headerName string) bool {
+	return containsFold(RedactedHeaders(), headerName)
+}
+
+// isEncodedContent returns true if a body has a content encoding (like gzip), and can therefore not be logged as is.
+func isEncodedContent(header http.Header) bool {
+	for _, encoding := range header.Values("Content-Encoding") {
+		if encoding != "" && !strings.EqualFold(encoding, "identity") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTextualContentType returns true if a content type represents a textual body.
+func isTextualContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/json",
+		"application/xml",
+		"application/x-www-form-urlencoded",
+		"application/javascript",
+		"application/graphql",
+		"application/x-ndjson":
+		return true
+	default:
+		return false
+	}
+}
+
+// isJsonContentType returns true if a content type represents a JSON body.
+func isJsonContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// redactJsonFields returns a JSON body with the values of the provided fields replaced by [redacted], or the body
+// untouched if it is not valid JSON.
+func redactJsonFields(body []byte, fields []string) []byte {
+	var data interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&data); err != nil {
+		return body
+	}
+
+	redacted := new(bytes.Buffer)
+
+	encoder := json.NewEncoder(redacted)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(redactJsonValue(data, fields)); err != nil {
+		return body
+	}
+
+	return bytes.TrimSuffix(redacted.Bytes(), []byte("\n"))
+}
+
+func redactJsonValue(value interface{}, fields []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if containsFold(fields, key) {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactJsonValue(item, fields)
+			}
+		}
+	case []interface{}:
+		for index, item := range v {
+			v[index] = redactJsonValue(item, fields)
+		}
+	}
+
+	return value
+}
+
+func containsFold(list []string, str string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, str) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sniffLen is the number of bytes used by [http.DetectContentType].
+const sniffLen = 512
+
+// loggerBody is a response body capturing its first bytes while read by the caller, and logging them once fully read
+// or closed.
+type loggerBody struct {
+	io.ReadCloser
+	contentLength int64
+	captureSize   int
+	log           func(body []byte, size int64)
+	mutex         sync.Mutex
+	captured      []byte
+	size          int64
+	eof           bool
+	once          sync.Once
+}
+
+func newLoggerBody(resp *http.Response, captureSize int, log func(body []byte, size int64)) *loggerBody {
+	return &loggerBody{
+		ReadCloser:    resp.Body,
+		contentLength: resp.ContentLength,
+		captureSize:   captureSize,
+		log:           log,
+	}
+}
+
+// Read reads from the wrapped body, capturing the read bytes up to the capture size.
+func (b *loggerBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.mutex.Lock()
+	b.size += int64(n)
+
+	if b.captureSize < 0 {
+		b.captured = append(b.captured, p[:n]...)
+	} else if remaining := b.captureSize - len(b.captured); remaining > 0 {
+		if remaining > n {
+			remaining = n
+		}
+
+		b.captured = append(b.captured, p[:remaining]...)
+	}
+
+	b.eof = errors.Is(err, io.EOF)
+	b.mutex.Unlock()
+
+	if b.eof {
+		b.done()
+	}
+
+	return n, err
+}
+
+// Close reads the not yet read part of the body to capture (to log the bodies closed without being read), and closes
+// the wrapped body.
+func (b *loggerBody) Close() error {
+	b.mutex.Lock()
+	toRead := int64(-1)
+	if b.captureSize >= 0 {
+		// one more byte, to know if there is more to read
+		toRead = int64(b.captureSize-len(b.captured)) + 1
+	}
+	b.mutex.Unlock()
+
+	if toRead < 0 {
+		//nolint:errcheck
+		io.Copy(io.Discard, b)
+	} else {
+		//nolint:errcheck
+		io.CopyN(io.Discard, b, toRead)
+	}
+
+	err := b.ReadCloser.Close()
+
+	b.done()
+
+	return err
+}
+
+// done logs the captured body, once.
+func (b *loggerBody) done() {
+	b.once.Do(func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		size := b.size
+		if !b.eof {
+			size = b.contentLength
+		}
+
+		b.log(b.captured, size)
+	})
+}
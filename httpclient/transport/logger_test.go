@@ -2,17 +2,25 @@ package transport_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/ankorstore/yokai/httpclient/transport"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/ankorstore/yokai/trace"
+	"github.com/ankorstore/yokai/trace/tracetest"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	otelsdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func TestNewLoggerTransport(t *testing.T) {
@@ -201,3 +209,908 @@ func TestLoggerTransportRoundTripWithConfig(t *testing.T) {
 		"message":  "http client response",
 	})
 }
+
+func TestLoggerTransportRoundTripWithTracing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sampler otelsdktrace.Sampler
+		sampled bool
+	}{
+		{"sampled", trace.NewAlwaysOnSampler(), true},
+		{"not sampled", trace.NewAlwaysOffSampler(), false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logBuffer := logtest.NewDefaultTestLogBuffer()
+			logger, err := log.NewDefaultLoggerFactory().Create(
+				log.WithLevel(zerolog.DebugLevel),
+				log.WithOutputWriter(logBuffer),
+			)
+			assert.NoError(t, err)
+
+			traceExporter := tracetest.NewDefaultTestTraceExporter()
+			tracerProvider, err := trace.NewDefaultTracerProviderFactory().Create(
+				trace.WithSampler(tt.sampler),
+				trace.WithSpanProcessor(trace.NewTestSpanProcessor(traceExporter)),
+			)
+			assert.NoError(t, err)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			ctx, span := tracerProvider.Tracer("test").Start(logger.WithContext(context.Background()), "client span")
+
+			req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+			req = req.WithContext(ctx)
+
+			resp, err := transport.NewLoggerTransport(nil).RoundTrip(req)
+			assert.NoError(t, err)
+
+			err = resp.Body.Close()
+			assert.NoError(t, err)
+
+			span.End()
+
+			traceId := span.SpanContext().TraceID().String()
+			spanId := span.SpanContext().SpanID().String()
+
+			for _, message := range []string{"http client request", "http client response"} {
+				if tt.sampled {
+					logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+						"level":   "info",
+						"traceID": traceId,
+						"spanID":  spanId,
+						"message": message,
+					})
+				} else {
+					logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+						"traceID": traceId,
+						"message": message,
+					})
+					logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+						"spanID":  spanId,
+						"message": message,
+					})
+				}
+			}
+
+			if tt.sampled {
+				tracetest.AssertHasTraceSpan(t, traceExporter, "client span")
+			} else {
+				tracetest.AssertHasNotTraceSpan(t, traceExporter, "client span")
+			}
+		})
+	}
+}
+
+func TestLoggerTransportRoundTripWithBodyRedactionAndTruncation(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		_, err = w.Write([]byte(`{"token":"secret-token","items":["` + strings.Repeat("a", 100) + `"]}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+		LogRequest:       true,
+		LogResponse:      true,
+		LogRequestBody:   true,
+		LogResponseBody:  true,
+		LogRequestLevel:  zerolog.InfoLevel,
+		LogResponseLevel: zerolog.InfoLevel,
+		LogBodyMaxSize:   40,
+		RedactJsonFields: []string{"password", "token"},
+	})
+
+	data := []byte(`{"user":{"login":"john","Password":"secret"}}`)
+	req := httptest.NewRequest(http.MethodPost, server.URL, bytes.NewBuffer(data))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	// bodies still fully readable
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "secret-token")
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"request": `{"user":{"Password":"[redacted]","login"...[truncated, 49 bytes total]`,
+		"message": "http client request",
+	})
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":    "info",
+		"response": `{"items":["aaaaaaaaaaaaaaaaaaaaaaaaaaaaa...[truncated, 135 bytes total]`,
+		"message":  "http client response",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"request": "secret",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"response": "secret-token",
+	})
+}
+
+func TestLoggerTransportRoundTripWithBinaryBody(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusOK)
+
+		_, err = w.Write([]byte("\x89PNG\r\n\x1a\n"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+		LogRequest:       true,
+		LogResponse:      true,
+		LogRequestBody:   true,
+		LogResponseBody:  true,
+		LogRequestLevel:  zerolog.InfoLevel,
+		LogResponseLevel: zerolog.InfoLevel,
+	})
+
+	req := httptest.NewRequest(http.MethodPost, server.URL, bytes.NewBuffer([]byte{0x00, 0x01, 0x02}))
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"request": "[binary body, 3 bytes]",
+		"message": "http client request",
+	})
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"response": "[binary body, 8 bytes]",
+		"message":  "http client response",
+	})
+}
+
+func TestLoggerTransportRoundTripWithError(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	//nolint:bodyclose
+	_, err = transport.NewLoggerTransport(nil).RoundTrip(req)
+	assert.Error(t, err)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"method":  "GET",
+		"url":     server.URL,
+		"message": "http client response",
+	})
+}
+
+func TestLoggerTransportRoundTripWithHeaders(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("x-response-foo", "foo1")
+		w.Header().Add("x-response-foo", "foo2")
+		w.Header().Set("set-cookie", "session=secret-session")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+		LogRequest:       true,
+		LogResponse:      true,
+		LogRequestLevel:  zerolog.InfoLevel,
+		LogResponseLevel: zerolog.InfoLevel,
+		RequestHeadersToLog: map[string]string{
+			"x-request-foo": "requestFoo",
+			"authorization": "authorization",
+			"x-missing":     "missing",
+		},
+		ResponseHeadersToLog: map[string]string{
+			"x-response-foo": "responseFoo",
+			"set-cookie":     "cookie",
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("x-request-foo", "foo")
+	req.Header.Set("authorization", "Bearer secret-token")
+	req.Header.Set("cookie", "session=secret-session")
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	// request and response headers left untouched
+	assert.Equal(t, "Bearer secret-token", req.Header.Get("authorization"))
+	assert.Equal(t, "session=secret-session", resp.Header.Get("set-cookie"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":         "info",
+		"requestFoo":    "foo",
+		"authorization": "[redacted]",
+		"message":       "http client request",
+	})
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"request": "Authorization: [redacted]",
+		"message": "http client request",
+	})
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"request": "Cookie: [redacted]",
+		"message": "http client request",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"missing": "",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":       "info",
+		"responseFoo": "foo1,foo2",
+		"cookie":      "[redacted]",
+		"message":     "http client response",
+	})
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"response": "Set-Cookie: [redacted]",
+		"message":  "http client response",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"request": "secret",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"response": "secret",
+	})
+}
+
+func TestLoggerTransportRoundTripWithExcludedHosts(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(
+		nil,
+		&transport.LoggerTransportConfig{
+			LogRequestLevel:  zerolog.InfoLevel,
+			LogResponseLevel: zerolog.InfoLevel,
+			ExcludeHosts:     []string{"127.0.0.1"},
+		},
+	)
+
+	excludedUrl := server.URL
+	includedUrl := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	for _, url := range []string{excludedUrl, includedUrl} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = req.WithContext(logger.WithContext(context.Background()))
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"url":     excludedUrl,
+		"message": "http client request",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"url":     excludedUrl,
+		"message": "http client response",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"method":  "GET",
+		"url":     includedUrl,
+		"message": "http client request",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"url":     includedUrl,
+		"code":    http.StatusNoContent,
+		"message": "http client response",
+	})
+}
+
+func TestLoggerTransportRoundTripWithLevelsByResponseCode(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, err := strconv.Atoi(r.URL.Query().Get("code"))
+		assert.NoError(t, err)
+
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(
+		nil,
+		&transport.LoggerTransportConfig{
+			LogRequestLevel:                  zerolog.InfoLevel,
+			LogResponseLevel:                 zerolog.DebugLevel,
+			LogResponseLevelFromResponseCode: true,
+			LogResponseLevelsByResponseCode: map[string]zerolog.Level{
+				"404": zerolog.InfoLevel,
+				"429": zerolog.WarnLevel,
+				"4xx": zerolog.ErrorLevel,
+				"5xx": zerolog.WarnLevel,
+			},
+		},
+	)
+
+	tests := []struct {
+		code          int
+		expectedLevel string
+	}{
+		{http.StatusOK, "debug"},
+		{http.StatusNotFound, "info"},
+		{http.StatusTooManyRequests, "warn"},
+		{http.StatusBadRequest, "error"},
+		{http.StatusServiceUnavailable, "warn"},
+	}
+
+	for _, tt := range tests {
+		url := server.URL + "?code=" + strconv.Itoa(tt.code)
+
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = req.WithContext(logger.WithContext(context.Background()))
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":   tt.expectedLevel,
+			"url":     url,
+			"code":    tt.code,
+			"message": "http client response",
+		})
+	}
+}
+
+func TestLoggerTransportRoundTripWithLevelsByResponseCodeFallback(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, err := strconv.Atoi(r.URL.Query().Get("code"))
+		assert.NoError(t, err)
+
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(
+		nil,
+		&transport.LoggerTransportConfig{
+			LogRequestLevel:                  zerolog.InfoLevel,
+			LogResponseLevel:                 zerolog.InfoLevel,
+			LogResponseLevelFromResponseCode: true,
+			LogResponseLevelsByResponseCode: map[string]zerolog.Level{
+				"404": zerolog.InfoLevel,
+			},
+		},
+	)
+
+	tests := []struct {
+		code          int
+		expectedLevel string
+	}{
+		{http.StatusNotFound, "info"},
+		{http.StatusBadRequest, "warn"},
+		{http.StatusInternalServerError, "error"},
+	}
+
+	for _, tt := range tests {
+		url := server.URL + "?code=" + strconv.Itoa(tt.code)
+
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = req.WithContext(logger.WithContext(context.Background()))
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":   tt.expectedLevel,
+			"url":     url,
+			"code":    tt.code,
+			"message": "http client response",
+		})
+	}
+}
+
+func TestLoggerTransportRoundTripWithStreamedResponseBody(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Length", strconv.Itoa(testStreamedBodySize))
+		w.WriteHeader(http.StatusOK)
+
+		chunk := []byte(strings.Repeat("a", 32*1024))
+		for written := 0; written < testStreamedBodySize; written += len(chunk) {
+			_, err := w.Write(chunk)
+			assert.NoError(t, err)
+		}
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+		LogResponse:      true,
+		LogResponseBody:  true,
+		LogResponseLevel: zerolog.InfoLevel,
+		LogBodyMaxSize:   1024,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	allocated := allocatedBytes(t, func() {
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		// not logged until the body is read
+		logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+			"message": "http client response",
+		})
+
+		read, err := io.Copy(io.Discard, resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(testStreamedBodySize), read)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+	})
+
+	// the body is not buffered
+	assert.Less(t, allocated, uint64(testStreamedBodySize/10))
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":    "info",
+		"code":     http.StatusOK,
+		"response": strings.Repeat("a", 1024) + "...[truncated, 52428800 bytes total]",
+		"message":  "http client response",
+	})
+}
+
+func TestLoggerTransportRoundTripWithSkippedStreamedResponseBody(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+
+		// chunked, with unknown content length
+		chunk := []byte(strings.Repeat("a", 32*1024))
+		for written := 0; written < testStreamedBodySize; written += len(chunk) {
+			_, err := w.Write(chunk)
+			assert.NoError(t, err)
+		}
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+		LogResponse:              true,
+		LogResponseBody:          true,
+		LogResponseLevel:         zerolog.InfoLevel,
+		LogBodyMaxSize:           1024,
+		LogResponseBodyStreaming: transport.ResponseBodyStreamingSkip,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	allocated := allocatedBytes(t, func() {
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		// logged as soon as received
+		logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+			"level":    "info",
+			"code":     http.StatusOK,
+			"response": transport.StreamingBodyValue,
+			"message":  "http client response",
+		})
+
+		read, err := io.Copy(io.Discard, resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(testStreamedBodySize), read)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+	})
+
+	assert.Less(t, allocated, uint64(testStreamedBodySize/10))
+}
+
+func TestLoggerTransportRoundTripWithResponseBodyClosedBeforeRead(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+
+		_, err = w.Write([]byte(strings.Repeat("a", 100)))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+		LogResponse:      true,
+		LogResponseBody:  true,
+		LogResponseLevel: zerolog.InfoLevel,
+		LogBodyMaxSize:   10,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	// partially read
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(resp.Body, buf)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	// closing twice logs once
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":    "info",
+		"response": "aaaaaaaaaa...[truncated, 100 bytes total]",
+		"message":  "http client response",
+	})
+
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+
+	count := 0
+	for _, record := range records {
+		if message, err := record.Message(); err == nil && message == "http client response" {
+			count++
+		}
+	}
+
+	assert.Equal(t, 1, count)
+}
+
+func TestLoggerTransportRoundTripWithResponseBodyTooLargeToBeRedacted(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	body := `{"token":"secret-token","items":["` + strings.Repeat("a", transport.MaxRedactedBodySize) + `"]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		_, err = w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+		LogResponse:      true,
+		LogResponseBody:  true,
+		LogResponseLevel: zerolog.InfoLevel,
+		LogBodyMaxSize:   40,
+		RedactJsonFields: []string{"token"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	read, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(read))
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"response": fmt.Sprintf("[body too large to be redacted, %d bytes]", len(body)),
+		"message":  "http client response",
+	})
+
+	logtest.AssertContainNotLogRecord(t, logBuffer, map[string]interface{}{
+		"response": "secret-token",
+	})
+}
+
+const testStreamedBodySize = 50 * 1024 * 1024
+
+// allocatedBytes returns the heap bytes allocated while running a given function.
+func allocatedBytes(t *testing.T, fn func()) uint64 {
+	t.Helper()
+
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	fn()
+
+	runtime.ReadMemStats(&after)
+
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestLoggerTransportRoundTripWithTracingAndDeferredResponseLog(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	traceExporter := tracetest.NewDefaultTestTraceExporter()
+	tracerProvider, err := trace.NewDefaultTracerProviderFactory().Create(
+		trace.WithSpanProcessor(trace.NewTestSpanProcessor(traceExporter)),
+	)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+
+		_, err = w.Write([]byte("ok"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+		LogResponse:      true,
+		LogResponseBody:  true,
+		LogResponseLevel: zerolog.InfoLevel,
+	})
+
+	ctx, span := tracerProvider.Tracer("test").Start(logger.WithContext(context.Background()), "client span")
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(ctx)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	// span ended on response headers, before the body is read
+	span.End()
+
+	tracetest.AssertHasTraceSpan(t, traceExporter, "client span")
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"traceID":  span.SpanContext().TraceID().String(),
+		"spanID":   span.SpanContext().SpanID().String(),
+		"response": "ok",
+		"message":  "http client response",
+	})
+}
+
+func TestLoggerTransportRoundTripWithCompressedResponseBody(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("compressed content ", 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			_, err := w.Write([]byte(content))
+			assert.NoError(t, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+
+		writer := gzip.NewWriter(w)
+
+		_, err := writer.Write([]byte(content))
+		assert.NoError(t, err)
+
+		err = writer.Close()
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name               string
+		disableCompression bool
+		acceptEncoding     string
+		expectedResponse   string
+	}{
+		{
+			name:             "uncompressed",
+			acceptEncoding:   "identity",
+			expectedResponse: content,
+		},
+		{
+			name:             "transparently decompressed",
+			expectedResponse: content,
+		},
+		{
+			name:               "compressed, with disabled decompression",
+			disableCompression: true,
+			acceptEncoding:     "gzip",
+			expectedResponse:   "[gzip encoded body, ",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			logBuffer := logtest.NewDefaultTestLogBuffer()
+			logger, err := log.NewDefaultLoggerFactory().Create(
+				log.WithLevel(zerolog.DebugLevel),
+				log.WithOutputWriter(logBuffer),
+			)
+			assert.NoError(t, err)
+
+			trans := transport.NewLoggerTransportWithConfig(
+				transport.NewBaseTransportWithConfig(&transport.BaseTransportConfig{
+					DisableCompression: tt.disableCompression,
+				}),
+				&transport.LoggerTransportConfig{
+					LogResponse:      true,
+					LogResponseBody:  true,
+					LogResponseLevel: zerolog.InfoLevel,
+				},
+			)
+
+			req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+			req = req.WithContext(logger.WithContext(context.Background()))
+
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+
+			resp, err := trans.RoundTrip(req)
+			assert.NoError(t, err)
+
+			_, err = io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			err = resp.Body.Close()
+			assert.NoError(t, err)
+
+			logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+				"response": tt.expectedResponse,
+				"message":  "http client response",
+			})
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	HttpClientMetricsRequestsCount    = "http_client_requests_total"
+	HttpClientMetricsRequestsDuration = "http_client_request_duration_seconds"
+	HttpClientMetricsInFlightRequests = "http_client_in_flight_requests"
+	HttpClientMetricsErrorStatusClass = "error"
+	DefaultMetricsClientName          = "default"
+)
+
+// MetricsTransport is a wrapper around [http.RoundTripper] collecting requests metrics, with some
+// [MetricsTransportConfig] configuration.
+type MetricsTransport struct {
+	transport        http.RoundTripper
+	config           *MetricsTransportConfig
+	requestsCounter  *prometheus.CounterVec
+	requestsDuration *prometheus.HistogramVec
+	inFlightGauge    *prometheus.GaugeVec
+}
+
+// MetricsTransportConfig is the configuration of the [MetricsTransport].
+type MetricsTransportConfig struct {
+	Registry        prometheus.Registerer
+	Namespace       string
+	Subsystem       string
+	Buckets         []float64
+	CollectInFlight bool
+	Client          string
+}
+
+// NewMetricsTransport returns a [MetricsTransport] instance with default [MetricsTransportConfig] configuration.
+func NewMetricsTransport(base http.RoundTripper) *MetricsTransport {
+	return NewMetricsTransportWithConfig(
+		base,
+		&MetricsTransportConfig{
+			Registry: prometheus.DefaultRegisterer,
+			Buckets:  prometheus.DefBuckets,
+		},
+	)
+}
+
+// NewMetricsTransportWithConfig returns a [MetricsTransport] instance for a provided [MetricsTransportConfig] configuration.
+//
+// The requests are counted in the http_client_requests_total metric and their durations observed in the
+// http_client_request_duration_seconds metric, labelled by method and host only (the urls are not used as labels,
+// to avoid high cardinality), with the trace id of the sampled span of the request context as exemplar. If
+// CollectInFlight is true, the in-flight requests are also exposed per host in the http_client_in_flight_requests metric.
+// All metrics are labelled by the Client name (default if empty), to share them between several clients.
+func NewMetricsTransportWithConfig(base http.RoundTripper, config *MetricsTransportConfig) *MetricsTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.Registry == nil {
+		config.Registry = prometheus.DefaultRegisterer
+	}
+
+	if len(config.Buckets) == 0 {
+		config.Buckets = prometheus.DefBuckets
+	}
+
+	if config.Client == "" {
+		config.Client = DefaultMetricsClientName
+	}
+
+	constLabels := prometheus.Labels{"client": config.Client}
+
+	requestsCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   config.Namespace,
+			Subsystem:   config.Subsystem,
+			Name:        HttpClientMetricsRequestsCount,
+			Help:        "Number of HTTP client requests",
+			ConstLabels: constLabels,
+		},
+		[]string{
+			"method",
+			"host",
+			"status_class",
+		},
+	)
+
+	//nolint:forcetypeassert
+	requestsCounter = registerMetricsCollector(config.Registry, requestsCounter).(*prometheus.CounterVec)
+
+	requestsDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:   config.Namespace,
+			Subsystem:   config.Subsystem,
+			Name:        HttpClientMetricsRequestsDuration,
+			Help:        "Time spent performing HTTP client requests",
+			Buckets:     config.Buckets,
+			ConstLabels: constLabels,
+		},
+		[]string{
+			"method",
+			"host",
+		},
+	)
+
+	//nolint:forcetypeassert
+	requestsDuration = registerMetricsCollector(config.Registry, requestsDuration).(*prometheus.HistogramVec)
+
+	var inFlightGauge *prometheus.GaugeVec
+	if config.CollectInFlight {
+		inFlightGauge = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   config.Namespace,
+				Subsystem:   config.Subsystem,
+				Name:        HttpClientMetricsInFlightRequests,
+				Help:        "Number of in-flight HTTP client requests",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"host",
+			},
+		)
+
+		//nolint:forcetypeassert
+		inFlightGauge = registerMetricsCollector(config.Registry, inFlightGauge).(*prometheus.GaugeVec)
+	}
+
+	return &MetricsTransport{
+		transport:        base,
+		config:           config,
+		requestsCounter:  requestsCounter,
+		requestsDuration: requestsDuration,
+		inFlightGauge:    inFlightGauge,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *MetricsTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper], collecting its metrics.
+func (t *MetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+
+	if t.inFlightGauge != nil {
+		t.inFlightGauge.WithLabelValues(host).Inc()
+		defer t.inFlightGauge.WithLabelValues(host).Dec()
+	}
+
+	start := time.Now()
+
+	resp, err := t.transport.RoundTrip(req)
+
+	duration := time.Since(start).Seconds()
+
+	statusClass := HttpClientMetricsErrorStatusClass
+	if err == nil {
+		statusClass = normalizeStatusClass(resp.StatusCode)
+	}
+
+	var exemplar prometheus.Labels
+	if spanContext := trace.SpanContextFromContext(req.Context()); spanContext.IsSampled() {
+		exemplar = prometheus.Labels{"traceID": spanContext.TraceID().String()}
+	}
+
+	counter := t.requestsCounter.WithLabelValues(req.Method, host, statusClass)
+	observer := t.requestsDuration.WithLabelValues(req.Method, host)
+
+	if exemplar != nil {
+		//nolint:forcetypeassert
+		counter.(prometheus.ExemplarAdder).AddWithExemplar(1, exemplar)
+		//nolint:forcetypeassert
+		observer.(prometheus.ExemplarObserver).ObserveWithExemplar(duration, exemplar)
+	} else {
+		counter.Inc()
+		observer.Observe(duration)
+	}
+
+	return resp, err
+}
+
+func normalizeStatusClass(status int) string {
+	switch {
+	case status < 200:
+		return "1xx"
+	case status < 300:
+		return "2xx"
+	case status < 400:
+		return "3xx"
+	case status < 500:
+		return "4xx"
+	default:
+		return "5xx"
+	}
+}
+
+// registerMetricsCollector registers a collector, or returns the already registered one.
+func registerMetricsCollector(registry prometheus.Registerer, collector prometheus.Collector) prometheus.Collector {
+	if err := registry.Register(collector); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+
+		return are.ExistingCollector
+	}
+
+	return collector
+}
@@ -0,0 +1,185 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewMetricsTransport(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewMetricsTransport(nil)
+
+	assert.IsType(t, &transport.MetricsTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+
+	// already registered metrics reused
+	assert.NotPanics(t, func() {
+		transport.NewMetricsTransport(nil)
+	})
+}
+
+func TestMetricsTransportBase(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+
+	trans := transport.NewMetricsTransportWithConfig(base, &transport.MetricsTransportConfig{
+		Registry: prometheus.NewPedanticRegistry(),
+	})
+
+	assert.Equal(t, base, trans.Base())
+}
+
+func TestMetricsTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	trans := transport.NewMetricsTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.MetricsTransportConfig{
+			Registry:        registry,
+			Namespace:       "foo",
+			Subsystem:       "bar",
+			Buckets:         []float64{1, 5},
+			CollectInFlight: true,
+			Client:          "payments",
+		},
+	)
+
+	// metrics shared with another client
+	assert.NotPanics(t, func() {
+		transport.NewMetricsTransportWithConfig(nil, &transport.MetricsTransportConfig{
+			Registry:  registry,
+			Namespace: "foo",
+			Subsystem: "bar",
+			Client:    "orders",
+		})
+	})
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	for _, path := range []string{"/success/1", "/success/2", "/error"} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+path, nil)
+		assert.NoError(t, err)
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+	}
+
+	// transport error
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://invalid.localhost:1", nil)
+	assert.NoError(t, err)
+
+	_, err = trans.RoundTrip(req)
+	assert.Error(t, err)
+
+	expectedMetric := `
+		# HELP foo_bar_http_client_requests_total Number of HTTP client requests
+		# TYPE foo_bar_http_client_requests_total counter
+		foo_bar_http_client_requests_total{client="payments",host="` + host + `",method="GET",status_class="2xx"} 2
+		foo_bar_http_client_requests_total{client="payments",host="` + host + `",method="GET",status_class="5xx"} 1
+		foo_bar_http_client_requests_total{client="payments",host="invalid.localhost:1",method="POST",status_class="error"} 1
+		# HELP foo_bar_http_client_in_flight_requests Number of in-flight HTTP client requests
+		# TYPE foo_bar_http_client_in_flight_requests gauge
+		foo_bar_http_client_in_flight_requests{client="payments",host="` + host + `"} 0
+		foo_bar_http_client_in_flight_requests{client="payments",host="invalid.localhost:1"} 0
+	`
+
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedMetric),
+		"foo_bar_http_client_requests_total",
+		"foo_bar_http_client_in_flight_requests",
+	)
+	assert.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(registry, "foo_bar_http_client_request_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestMetricsTransportRoundTripWithExemplars(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	trans := transport.NewMetricsTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.MetricsTransportConfig{
+			Registry: registry,
+		},
+	)
+
+	traceId, err := trace.TraceIDFromHex("c4ca71e03e42c2c3d54293a6e2608bfa")
+	assert.NoError(t, err)
+
+	spanId, err := trace.SpanIDFromHex("8d0fdc8a74baaaea")
+	assert.NoError(t, err)
+
+	ctx := trace.ContextWithSpanContext(
+		context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceId,
+			SpanID:     spanId,
+			TraceFlags: trace.FlagsSampled,
+		}),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+
+	exemplars := 0
+	for _, metricFamily := range metricFamilies {
+		switch metricFamily.GetName() {
+		case transport.HttpClientMetricsRequestsCount:
+			exemplar := metricFamily.GetMetric()[0].GetCounter().GetExemplar()
+			assert.Equal(t, "traceID", exemplar.GetLabel()[0].GetName())
+			assert.Equal(t, "c4ca71e03e42c2c3d54293a6e2608bfa", exemplar.GetLabel()[0].GetValue())
+			exemplars++
+		case transport.HttpClientMetricsRequestsDuration:
+			for _, bucket := range metricFamily.GetMetric()[0].GetHistogram().GetBucket() {
+				if exemplar := bucket.GetExemplar(); exemplar != nil {
+					assert.Equal(t, "c4ca71e03e42c2c3d54293a6e2608bfa", exemplar.GetLabel()[0].GetValue())
+					exemplars++
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, 2, exemplars)
+}
@@ -0,0 +1,147 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// DefaultOAuth2ExpiryDelta is the default delay before the tokens expiry to refresh them.
+const DefaultOAuth2ExpiryDelta = 10 * time.Second
+
+// OAuth2TokenError is returned by the [OAuth2Transport] when the token cannot be fetched, the request being not sent.
+type OAuth2TokenError struct {
+	TokenUrl string
+	Err      error
+}
+
+// Error returns the error message, with the token url.
+func (e *OAuth2TokenError) Error() string {
+	return fmt.Sprintf("failed to fetch oauth2 token from %s: %v", e.TokenUrl, e.Err)
+}
+
+// Unwrap returns the token fetch error.
+func (e *OAuth2TokenError) Unwrap() error {
+	return e.Err
+}
+
+// OAuth2Transport is a wrapper around [http.RoundTripper] authenticating the requests with OAuth2 client credentials
+// tokens, with some [OAuth2TransportConfig] configuration.
+type OAuth2Transport struct {
+	transport http.RoundTripper
+	config    *OAuth2TransportConfig
+	source    oauth2.TokenSource
+}
+
+// OAuth2TransportConfig is the configuration of the [OAuth2Transport].
+type OAuth2TransportConfig struct {
+	TokenUrl     string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+	Audience     string
+	ExpiryDelta  time.Duration
+	TokenClient  *http.Client
+}
+
+// NewOAuth2Transport returns a [OAuth2Transport] instance for a provided token url and client credentials.
+func NewOAuth2Transport(base http.RoundTripper, tokenUrl string, clientId string, clientSecret string) *OAuth2Transport {
+	return NewOAuth2TransportWithConfig(
+		base,
+		&OAuth2TransportConfig{
+			TokenUrl:     tokenUrl,
+			ClientId:     clientId,
+			ClientSecret: clientSecret,
+			ExpiryDelta:  DefaultOAuth2ExpiryDelta,
+		},
+	)
+}
+
+// NewOAuth2TransportWithConfig returns a [OAuth2Transport] instance for a provided [OAuth2TransportConfig] configuration.
+//
+// The token is fetched lazily, on the first request, from the TokenUrl with the client credentials grant (with the
+// Scopes and the Audience if provided), then reused until ExpiryDelta before its expiry ([DefaultOAuth2ExpiryDelta]
+// if zero), to be refreshed by the next request. The tokens are fetched with the TokenClient (a client with a
+// [BaseTransport] if nil), and the requests already having an Authorization header are sent as is.
+func NewOAuth2TransportWithConfig(base http.RoundTripper, config *OAuth2TransportConfig) *OAuth2Transport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.ExpiryDelta <= 0 {
+		config.ExpiryDelta = DefaultOAuth2ExpiryDelta
+	}
+
+	if config.TokenClient == nil {
+		config.TokenClient = &http.Client{Transport: NewBaseTransport()}
+	}
+
+	credentialsConfig := &clientcredentials.Config{
+		ClientID:     config.ClientId,
+		ClientSecret: config.ClientSecret,
+		TokenURL:     config.TokenUrl,
+		Scopes:       config.Scopes,
+	}
+
+	if config.Audience != "" {
+		credentialsConfig.EndpointParams = url.Values{"audience": {config.Audience}}
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, config.TokenClient)
+
+	// not using the credentials config token source, since its own reuse would ignore the configured expiry delta
+	source := oauth2.ReuseTokenSourceWithExpiry(
+		nil,
+		tokenSourceFunc(func() (*oauth2.Token, error) {
+			return credentialsConfig.Token(ctx)
+		}),
+		config.ExpiryDelta,
+	)
+
+	return &OAuth2Transport{
+		transport: base,
+		config:    config,
+		source:    source,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *OAuth2Transport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+func (t *OAuth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	token, err := t.source.Token()
+	if err != nil {
+		if req.Body != nil {
+			//nolint:errcheck
+			req.Body.Close()
+		}
+
+		return nil, &OAuth2TokenError{
+			TokenUrl: t.config.TokenUrl,
+			Err:      err,
+		}
+	}
+
+	authorizedReq := req.Clone(req.Context())
+	token.SetAuthHeader(authorizedReq)
+
+	return t.transport.RoundTrip(authorizedReq)
+}
+
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
@@ -0,0 +1,240 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOAuth2TokenServer(t *testing.T, expiresIn int, fetches *int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		assert.NoError(t, err)
+
+		clientId, clientSecret, ok := r.BasicAuth()
+		if !ok || clientId != "client-id" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+		assert.Equal(t, "test-audience", r.PostForm.Get("audience"))
+
+		fetch := atomic.AddInt32(fetches, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write([]byte(fmt.Sprintf(
+			`{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`,
+			fetch,
+			expiresIn,
+		)))
+		assert.NoError(t, err)
+	}))
+}
+
+func newTestOAuth2Server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-authorization", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestNewOAuth2Transport(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewOAuth2Transport(nil, "http://example.com/token", "client-id", "client-secret")
+
+	assert.IsType(t, &transport.OAuth2Transport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+}
+
+func TestOAuth2TransportBase(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+
+	trans := transport.NewOAuth2Transport(base, "http://example.com/token", "client-id", "client-secret")
+
+	assert.Equal(t, base, trans.Base())
+}
+
+func TestOAuth2TransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var fetches int32
+
+	tokenServer := newTestOAuth2TokenServer(t, 2, &fetches)
+	defer tokenServer.Close()
+
+	server := newTestOAuth2Server(t)
+	defer server.Close()
+
+	trans := transport.NewOAuth2TransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.OAuth2TransportConfig{
+			TokenUrl:     tokenServer.URL,
+			ClientId:     "client-id",
+			ClientSecret: "client-secret",
+			Scopes:       []string{"read", "write"},
+			Audience:     "test-audience",
+			ExpiryDelta:  1900 * time.Millisecond,
+		},
+	)
+
+	// token fetched lazily
+	assert.Equal(t, int32(0), atomic.LoadInt32(&fetches))
+
+	roundTrip := func() string {
+		req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.Empty(t, req.Header.Get("Authorization"))
+
+		return resp.Header.Get("received-authorization")
+	}
+
+	// token fetched and reused
+	assert.Equal(t, "Bearer token-1", roundTrip())
+	assert.Equal(t, "Bearer token-1", roundTrip())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// token refreshed before expiry
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(t, "Bearer token-2", roundTrip())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+
+	// explicit authorization header wins
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Authorization", "Bearer custom")
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Bearer custom", resp.Header.Get("received-authorization"))
+}
+
+func TestOAuth2TransportRoundTripWithRedactedToken(t *testing.T) {
+	t.Parallel()
+
+	var fetches int32
+
+	tokenServer := newTestOAuth2TokenServer(t, 3600, &fetches)
+	defer tokenServer.Close()
+
+	server := newTestOAuth2Server(t)
+	defer server.Close()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	trans := transport.NewOAuth2TransportWithConfig(
+		transport.NewLoggerTransportWithConfig(nil, &transport.LoggerTransportConfig{
+			LogRequest:      true,
+			LogRequestLevel: zerolog.InfoLevel,
+			RequestHeadersToLog: map[string]string{
+				"authorization": "authorization",
+			},
+		}),
+		&transport.OAuth2TransportConfig{
+			TokenUrl:     tokenServer.URL,
+			ClientId:     "client-id",
+			ClientSecret: "client-secret",
+			Scopes:       []string{"read", "write"},
+			Audience:     "test-audience",
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Bearer token-1", resp.Header.Get("received-authorization"))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":         "info",
+		"authorization": "[redacted]",
+		"message":       "http client request",
+	})
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"request": "Authorization: [redacted]",
+		"message": "http client request",
+	})
+}
+
+func TestOAuth2TransportRoundTripWithTokenError(t *testing.T) {
+	t.Parallel()
+
+	var fetches int32
+
+	tokenServer := newTestOAuth2TokenServer(t, 3600, &fetches)
+	defer tokenServer.Close()
+
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	trans := transport.NewOAuth2TransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.OAuth2TransportConfig{
+			TokenUrl:     tokenServer.URL,
+			ClientId:     "client-id",
+			ClientSecret: "invalid-secret",
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodPost, server.URL, strings.NewReader("body"))
+
+	//nolint:bodyclose
+	resp, err := trans.RoundTrip(req)
+	assert.Nil(t, resp)
+	assert.Error(t, err)
+
+	var tokenErr *transport.OAuth2TokenError
+	assert.True(t, errors.As(err, &tokenErr))
+	assert.Equal(t, tokenServer.URL, tokenErr.TokenUrl)
+	assert.Contains(t, err.Error(), fmt.Sprintf("failed to fetch oauth2 token from %s", tokenServer.URL))
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&fetches))
+}
@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/ankorstore/yokai/httpclient/propagated"
+)
+
+// PropagationTransport is a wrapper around [http.RoundTripper] with some [PropagationTransportConfig] configuration.
+type PropagationTransport struct {
+	transport http.RoundTripper
+	config    *PropagationTransportConfig
+}
+
+// PropagationTransportConfig is the configuration of the [PropagationTransport].
+type PropagationTransportConfig struct {
+	Headers []string
+}
+
+// NewPropagationTransport returns a [PropagationTransport] instance with default [PropagationTransportConfig]
+// configuration, propagating no headers.
+func NewPropagationTransport(base http.RoundTripper) *PropagationTransport {
+	return NewPropagationTransportWithConfig(base, &PropagationTransportConfig{})
+}
+
+// NewPropagationTransportWithConfig returns a [PropagationTransport] instance for a provided
+// [PropagationTransportConfig] configuration.
+func NewPropagationTransportWithConfig(base http.RoundTripper, config *PropagationTransportConfig) *PropagationTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	return &PropagationTransport{
+		transport: base,
+		config:    config,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *PropagationTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+// It copies the configured headers values of the incoming request (from the request context, see
+// [propagated.CtxHeaders]) into the outgoing request headers, if not already set. Only the configured headers are
+// propagated, to not leak the incoming request headers to every host the client calls.
+func (t *PropagationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.config.Headers) == 0 {
+		return t.transport.RoundTrip(req)
+	}
+
+	incoming := propagated.CtxHeaders(req.Context())
+
+	cloned := false
+	for _, header := range t.config.Headers {
+		if req.Header.Get(header) != "" {
+			continue
+		}
+
+		value := incoming.Get(header)
+		if value == "" {
+			continue
+		}
+
+		if !cloned {
+			req = req.Clone(req.Context())
+			cloned = true
+		}
+
+		req.Header.Set(header, value)
+	}
+
+	return t.transport.RoundTrip(req)
+}
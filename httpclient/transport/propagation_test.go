@@ -0,0 +1,133 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/propagated"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPropagationTransport(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewPropagationTransport(nil)
+
+	assert.IsType(t, &transport.PropagationTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+}
+
+func TestPropagationTransportBase(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+
+	trans := transport.NewPropagationTransport(base)
+
+	assert.Equal(t, base, trans.Base())
+}
+
+func TestPropagationTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-tenant-id", r.Header.Get("x-tenant-id"))
+		w.Header().Set("received-locale", r.Header.Get("x-locale"))
+		w.Header().Set("received-other", r.Header.Get("x-other"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	headers := http.Header{}
+	headers.Set("x-tenant-id", "test-tenant-id")
+	headers.Set("x-locale", "fr, en;q=0.8")
+	headers.Set("x-other", "other")
+
+	trans := transport.NewPropagationTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.PropagationTransportConfig{
+			Headers: []string{"X-Tenant-Id", "X-Locale", "X-Missing"},
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(propagated.WithHeaders(context.Background(), headers))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "test-tenant-id", resp.Header.Get("received-tenant-id"))
+	assert.Equal(t, "fr, en;q=0.8", resp.Header.Get("received-locale"))
+	assert.Empty(t, resp.Header.Get("received-other"))
+	assert.Empty(t, req.Header.Get("x-tenant-id"))
+}
+
+func TestPropagationTransportRoundTripWithDefaultConfig(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-tenant-id", r.Header.Get("x-tenant-id"))
+		w.Header().Set("received-locale", r.Header.Get("x-locale"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	headers := http.Header{}
+	headers.Set("x-tenant-id", "test-tenant-id")
+	headers.Set("x-locale", "fr, en;q=0.8")
+
+	trans := transport.NewPropagationTransport(nil)
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(propagated.WithHeaders(context.Background(), headers))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("received-tenant-id"))
+	assert.Empty(t, resp.Header.Get("received-locale"))
+}
+
+func TestPropagationTransportRoundTripWithExistingHeader(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-tenant-id", r.Header.Get("x-tenant-id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	headers := http.Header{}
+	headers.Set("x-tenant-id", "test-tenant-id")
+
+	trans := transport.NewPropagationTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.PropagationTransportConfig{
+			Headers: []string{"x-tenant-id"},
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("x-tenant-id", "existing-tenant-id")
+	req = req.WithContext(propagated.WithHeaders(context.Background(), headers))
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "existing-tenant-id", resp.Header.Get("received-tenant-id"))
+}
@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyFunc is the [http.Transport] Proxy function, resolving the proxy url of a request (nil for direct requests).
+type ProxyFunc func(*http.Request) (*url.URL, error)
+
+// ProxyConfig is the configuration of a [ProxyFunc], see [NewProxyFunc].
+type ProxyConfig struct {
+	URL             string
+	NoProxy         []string
+	FromEnvironment bool
+}
+
+// NewProxyFunc returns a [ProxyFunc] for a provided [ProxyConfig] configuration.
+//
+// The requests are sent through the proxy URL (basic-auth credentials can be provided in its user info), except the
+// ones to the NoProxy hosts, domains or CIDRs, and the ones to localhost and loopback addresses. If no URL is provided,
+// the proxy is resolved from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables when FromEnvironment is
+// true, or else the requests are sent directly. An error is returned if the URL is invalid.
+func NewProxyFunc(config *ProxyConfig) (ProxyFunc, error) {
+	if config.URL == "" {
+		if config.FromEnvironment {
+			return http.ProxyFromEnvironment, nil
+		}
+
+		return directProxyFunc, nil
+	}
+
+	proxyUrl, err := url.Parse(config.URL)
+	if err != nil {
+		// the url parsing error contains the raw url, and therefore the credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return nil, fmt.Errorf("invalid proxy url %s: %w", RedactProxyUrl(config.URL), err)
+	}
+
+	if proxyUrl.Scheme == "" || proxyUrl.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %s: scheme and host are required", RedactProxyUrl(config.URL))
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxyUrl.String(),
+		HTTPSProxy: proxyUrl.String(),
+		NoProxy:    strings.Join(config.NoProxy, ","),
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+// RedactProxyUrl returns a proxy url with its password replaced by xxxxx, to be safely logged.
+func RedactProxyUrl(proxyUrl string) string {
+	parsed, err := url.Parse(proxyUrl)
+	if err != nil {
+		// unparsable url, the whole user info is redacted
+		if i := strings.LastIndex(proxyUrl, "@"); i >= 0 {
+			return "xxxxx" + proxyUrl[i:]
+		}
+
+		return proxyUrl
+	}
+
+	return parsed.Redacted()
+}
+
+func directProxyFunc(*http.Request) (*url.URL, error) {
+	//nolint:nilnil
+	return nil, nil
+}
@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// RequestIdTransport is a wrapper around [http.RoundTripper] with some [RequestIdTransportConfig] configuration.
+type RequestIdTransport struct {
+	transport http.RoundTripper
+	config    *RequestIdTransportConfig
+}
+
+// RequestIdTransportConfig is the configuration of the [RequestIdTransport].
+type RequestIdTransportConfig struct {
+	RequestIdHeader     string
+	BaggageRequestIdKey string
+}
+
+// NewRequestIdTransport returns a [RequestIdTransport] instance with default [RequestIdTransportConfig] configuration.
+func NewRequestIdTransport(base http.RoundTripper) *RequestIdTransport {
+	return NewRequestIdTransportWithConfig(
+		base,
+		&RequestIdTransportConfig{
+			RequestIdHeader:     "x-request-id",
+			BaggageRequestIdKey: "x-request-id",
+		},
+	)
+}
+
+// NewRequestIdTransportWithConfig returns a [RequestIdTransport] instance for a provided [RequestIdTransportConfig] configuration.
+func NewRequestIdTransportWithConfig(base http.RoundTripper, config *RequestIdTransportConfig) *RequestIdTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	return &RequestIdTransport{
+		transport: base,
+		config:    config,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *RequestIdTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+// It copies the contextual request id (from the request context baggage) into the outgoing request header, if not already set.
+func (t *RequestIdTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(t.config.RequestIdHeader) == "" {
+		rid := baggage.FromContext(req.Context()).Member(t.config.BaggageRequestIdKey).Value()
+
+		if rid != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set(t.config.RequestIdHeader, rid)
+		}
+	}
+
+	return t.transport.RoundTrip(req)
+}
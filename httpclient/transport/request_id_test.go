@@ -0,0 +1,108 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestNewRequestIdTransport(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewRequestIdTransport(nil)
+
+	assert.IsType(t, &transport.RequestIdTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+}
+
+func TestRequestIdTransportBase(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+
+	trans := transport.NewRequestIdTransport(base)
+
+	assert.Equal(t, base, trans.Base())
+}
+
+func TestRequestIdTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-request-id", r.Header.Get("x-request-id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	member, err := baggage.NewMember("x-request-id", "test-request-id")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(baggage.ContextWithBaggage(context.Background(), bag))
+
+	resp, err := transport.NewRequestIdTransport(nil).RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "test-request-id", resp.Header.Get("received-request-id"))
+	assert.Empty(t, req.Header.Get("x-request-id"))
+}
+
+func TestRequestIdTransportRoundTripWithExistingHeader(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-request-id", r.Header.Get("x-request-id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	member, err := baggage.NewMember("x-request-id", "test-request-id")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("x-request-id", "existing-request-id")
+	req = req.WithContext(baggage.ContextWithBaggage(context.Background(), bag))
+
+	resp, err := transport.NewRequestIdTransport(nil).RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "existing-request-id", resp.Header.Get("received-request-id"))
+}
+
+func TestRequestIdTransportRoundTripWithoutRequestId(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("received-request-id", r.Header.Get("x-request-id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+
+	resp, err := transport.NewRequestIdTransport(nil).RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Empty(t, resp.Header.Get("received-request-id"))
+}
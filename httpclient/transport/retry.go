@@ -0,0 +1,295 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	HttpClientMetricsRetriesCount = "http_client_retries_total"
+	DefaultRetryMaxAttempts       = 3
+	DefaultRetryInitialBackoff    = 100 * time.Millisecond
+	DefaultRetryMaxBackoff        = 5 * time.Second
+	DefaultRetryJitter            = 0.2
+)
+
+// RetryTransport is a wrapper around [http.RoundTripper] retrying failed requests with an exponential backoff, with
+// some [RetryTransportConfig] configuration.
+type RetryTransport struct {
+	transport      http.RoundTripper
+	config         *RetryTransportConfig
+	retriesCounter *prometheus.CounterVec
+}
+
+// RetryTransportConfig is the configuration of the [RetryTransport].
+type RetryTransportConfig struct {
+	MaxAttempts        int
+	InitialBackoff     time.Duration
+	MaxBackoff         time.Duration
+	Jitter             float64
+	RetryOnStatusCodes []int
+	RetryOnMethods     []string
+	Registry           prometheus.Registerer
+	Namespace          string
+	Subsystem          string
+}
+
+// DefaultRetryOnStatusCodes are the response status codes retried by default.
+func DefaultRetryOnStatusCodes() []int {
+	return []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+}
+
+// DefaultRetryOnMethods are the idempotent request methods retried by default.
+func DefaultRetryOnMethods() []string {
+	return []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPut,
+		http.MethodDelete,
+	}
+}
+
+// NewRetryTransport returns a [RetryTransport] instance with default [RetryTransportConfig] configuration.
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return NewRetryTransportWithConfig(
+		base,
+		&RetryTransportConfig{
+			MaxAttempts:        DefaultRetryMaxAttempts,
+			InitialBackoff:     DefaultRetryInitialBackoff,
+			MaxBackoff:         DefaultRetryMaxBackoff,
+			Jitter:             DefaultRetryJitter,
+			RetryOnStatusCodes: DefaultRetryOnStatusCodes(),
+			RetryOnMethods:     DefaultRetryOnMethods(),
+			Registry:           prometheus.DefaultRegisterer,
+		},
+	)
+}
+
+// NewRetryTransportWithConfig returns a [RetryTransport] instance for a provided [RetryTransportConfig] configuration.
+//
+// The requests are retried (up to MaxAttempts attempts in total) on transport errors and on the RetryOnStatusCodes
+// responses, only for the RetryOnMethods methods, and if their body can be rewound (no body, or GetBody provided).
+// The backoff between attempts doubles from InitialBackoff up to MaxBackoff, randomized by the Jitter ratio. A
+// Retry-After response header is honored, and no retry is made if it exceeds MaxBackoff. The retries stop as soon as
+// the request context is done. Each retry is logged at warn level and counted in the http_client_retries_total metric.
+func NewRetryTransportWithConfig(base http.RoundTripper, config *RetryTransportConfig) *RetryTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultRetryMaxAttempts
+	}
+
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultRetryInitialBackoff
+	}
+
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultRetryMaxBackoff
+	}
+
+	if config.Jitter < 0 || config.Jitter > 1 {
+		config.Jitter = DefaultRetryJitter
+	}
+
+	if config.RetryOnStatusCodes == nil {
+		config.RetryOnStatusCodes = DefaultRetryOnStatusCodes()
+	}
+
+	if config.RetryOnMethods == nil {
+		config.RetryOnMethods = DefaultRetryOnMethods()
+	}
+
+	if config.Registry == nil {
+		config.Registry = prometheus.DefaultRegisterer
+	}
+
+	retriesCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      HttpClientMetricsRetriesCount,
+			Help:      "Number of retried HTTP client requests",
+		},
+		[]string{
+			"method",
+			"host",
+		},
+	)
+
+	if err := config.Registry.Register(retriesCounter); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+
+		//nolint:forcetypeassert
+		retriesCounter = are.ExistingCollector.(*prometheus.CounterVec)
+	}
+
+	return &RetryTransport{
+		transport:      base,
+		config:         config,
+		retriesCounter: retriesCounter,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *RetryTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper], retrying it if needed.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.isRetryable(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	attemptReq := req
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.transport.RoundTrip(attemptReq)
+
+		if attempt >= t.config.MaxAttempts || ctx.Err() != nil || !t.shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		backoff := t.backoff(attempt)
+
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if retryAfter > t.config.MaxBackoff {
+					return resp, err
+				}
+
+				backoff = retryAfter
+			}
+		}
+
+		// next attempt request, with a rewound body
+		nextReq := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+
+			nextReq.Body = body
+		}
+
+		logEvt := log.CtxLogger(ctx).
+			Warn().
+			Int("attempt", attempt+1).
+			Str("method", req.Method).
+			Str("url", req.URL.String()).
+			Str("backoff", backoff.String())
+
+		if err != nil {
+			logEvt.Err(err)
+		} else {
+			logEvt.Int("code", resp.StatusCode)
+
+			// response discarded, to reuse the connection
+			//nolint:errcheck
+			io.CopyN(io.Discard, resp.Body, 4096)
+			resp.Body.Close()
+		}
+
+		logEvt.Msg("http client request retry")
+
+		t.retriesCounter.WithLabelValues(req.Method, req.URL.Host).Inc()
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		attemptReq = nextReq
+	}
+}
+
+func (t *RetryTransport) isRetryable(req *http.Request) bool {
+	if t.config.MaxAttempts <= 1 {
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	for _, method := range t.config.RetryOnMethods {
+		if strings.EqualFold(method, req.Method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *RetryTransport) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	for _, code := range t.config.RetryOnStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	backoff := float64(t.config.InitialBackoff) * math.Pow(2, float64(attempt-1))
+
+	if t.config.Jitter > 0 {
+		//nolint:gosec
+		backoff = backoff * (1 + t.config.Jitter*(2*rand.Float64()-1))
+	}
+
+	return time.Duration(math.Min(backoff, float64(t.config.MaxBackoff)))
+}
+
+// parseRetryAfter parses a Retry-After header value, provided in seconds or as an http date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+
+		return delay, true
+	}
+
+	return 0, false
+}
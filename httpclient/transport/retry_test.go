@@ -0,0 +1,280 @@
+package transport_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRetryTestTransport(registry prometheus.Registerer) *transport.RetryTransport {
+	return transport.NewRetryTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.RetryTransportConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+			Registry:       registry,
+			Namespace:      "foo",
+			Subsystem:      "bar",
+		},
+	)
+}
+
+func TestNewRetryTransport(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewRetryTransport(nil)
+
+	assert.IsType(t, &transport.RetryTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+
+	// already registered metric reused
+	assert.NotPanics(t, func() {
+		transport.NewRetryTransport(nil)
+	})
+}
+
+func TestRetryTransportBase(t *testing.T) {
+	t.Parallel()
+
+	base := &http.Transport{}
+
+	trans := transport.NewRetryTransportWithConfig(base, &transport.RetryTransportConfig{
+		Registry: prometheus.NewPedanticRegistry(),
+	})
+
+	assert.Equal(t, base, trans.Base())
+}
+
+func TestRetryTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	var requests atomic.Int32
+	var bodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		bodies = append(bodies, string(body))
+
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	req, err := http.NewRequestWithContext(
+		logger.WithContext(context.Background()),
+		http.MethodPut,
+		server.URL,
+		strings.NewReader("payload"),
+	)
+	assert.NoError(t, err)
+
+	resp, err := newRetryTestTransport(registry).RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+
+	for _, attempt := range []int{2, 3} {
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":   "warn",
+			"attempt": attempt,
+			"method":  "PUT",
+			"url":     server.URL,
+			"code":    http.StatusServiceUnavailable,
+			"message": "http client request retry",
+		})
+	}
+
+	expectedMetric := `
+		# HELP foo_bar_http_client_retries_total Number of retried HTTP client requests
+		# TYPE foo_bar_http_client_retries_total counter
+		foo_bar_http_client_retries_total{host="` + req.URL.Host + `",method="PUT"} 2
+	`
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_http_client_retries_total")
+	assert.NoError(t, err)
+}
+
+func TestRetryTransportRoundTripWithMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+
+	resp, err := newRetryTestTransport(prometheus.NewPedanticRegistry()).RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestRetryTransportRoundTripWithoutRetry(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.URL.Path == "/not-found" {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	trans := newRetryTestTransport(prometheus.NewPedanticRegistry())
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   io.Reader
+		code   int
+	}{
+		{"non retried status code", http.MethodGet, "/not-found", nil, http.StatusNotFound},
+		{"non idempotent method", http.MethodPost, "/", nil, http.StatusServiceUnavailable},
+		{"non rewindable body", http.MethodPut, "/", io.NopCloser(strings.NewReader("payload")), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		requests.Store(0)
+
+		req, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL+tt.path, tt.body)
+		assert.NoError(t, err)
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, tt.code, resp.StatusCode, tt.name)
+		assert.Equal(t, int32(1), requests.Load(), tt.name)
+	}
+}
+
+func TestRetryTransportRoundTripWithRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", r.URL.Query().Get("retry-after"))
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	trans := transport.NewRetryTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.RetryTransportConfig{
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     2 * time.Second,
+			Registry:       prometheus.NewPedanticRegistry(),
+		},
+	)
+
+	// honored
+	start := time.Now()
+
+	req := httptest.NewRequest(http.MethodGet, server.URL+"?retry-after=1", nil)
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	// exceeding max backoff
+	requests.Store(0)
+
+	req = httptest.NewRequest(http.MethodGet, server.URL+"?retry-after=60", nil)
+	resp, err = trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestRetryTransportRoundTripWithCancelledContext(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	trans := transport.NewRetryTransportWithConfig(
+		transport.NewBaseTransport(),
+		&transport.RetryTransportConfig{
+			MaxAttempts:    5,
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Second,
+			Registry:       prometheus.NewPedanticRegistry(),
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	start := time.Now()
+
+	//nolint:bodyclose
+	_, err = trans.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), requests.Load())
+}
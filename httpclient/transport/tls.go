@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TlsFilesConfig is the configuration of a [tls.Config] built from files, see [NewTlsConfig].
+type TlsFilesConfig struct {
+	CaFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+	MinVersion         string
+}
+
+// NewTlsConfig returns a [tls.Config] for a provided [TlsFilesConfig] configuration.
+//
+// The CaFile PEM certificates are trusted on top of the system ones, the CertFile and KeyFile PEM files are used
+// as client certificate (both are required if one is set), and the MinVersion accepts 1.0, 1.1, 1.2 or 1.3
+// (Go default if empty). An error is returned if any file cannot be loaded.
+func NewTlsConfig(config *TlsFilesConfig) (*tls.Config, error) {
+	//nolint:gosec
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CaFile != "" {
+		caPem, err := os.ReadFile(config.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read tls ca file: %w", err)
+		}
+
+		caPool, err := x509.SystemCertPool()
+		if err != nil {
+			caPool = x509.NewCertPool()
+		}
+
+		if !caPool.AppendCertsFromPEM(caPem) {
+			return nil, fmt.Errorf("cannot parse tls ca file %s: no valid PEM certificate found", config.CaFile)
+		}
+
+		tlsConfig.RootCAs = caPool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		if config.CertFile == "" || config.KeyFile == "" {
+			return nil, fmt.Errorf("tls cert file and key file must be both provided")
+		}
+
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load tls client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if config.MinVersion != "" {
+		minVersion, err := FetchTlsVersion(config.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.MinVersion = minVersion
+	}
+
+	return tlsConfig, nil
+}
+
+// FetchTlsVersion returns a TLS version for a given name (1.0, 1.1, 1.2 or 1.3).
+func FetchTlsVersion(name string) (uint16, error) {
+	switch name {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid tls version %s", name)
+	}
+}
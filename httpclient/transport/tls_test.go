@@ -0,0 +1,224 @@
+package transport_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestServerCaFile writes the certificate of a provided TLS test server in a PEM file, and returns its path.
+func writeTestServerCaFile(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+
+	err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+	assert.NoError(t, err)
+
+	return caFile
+}
+
+// writeTestClientCertificateFiles writes a self-signed client certificate and its key in PEM files, and returns
+// their paths.
+func writeTestClientCertificateFiles(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0o600)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)
+	assert.NoError(t, err)
+
+	return certFile, keyFile
+}
+
+func TestNewTlsConfigWithCaFile(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// unknown authority without ca file
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+
+	//nolint:bodyclose
+	_, err := transport.NewBaseTransport().RoundTrip(req)
+	assert.Error(t, err)
+
+	// trusted with ca file
+	tlsConfig, err := transport.NewTlsConfig(&transport.TlsFilesConfig{
+		CaFile:     writeTestServerCaFile(t, server),
+		MinVersion: "1.2",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+
+	trans := transport.NewBaseTransportWithConfig(&transport.BaseTransportConfig{
+		TlsConfig: tlsConfig,
+	})
+	assert.Equal(t, tlsConfig, trans.Base().TLSClientConfig)
+
+	resp, err := trans.RoundTrip(httptest.NewRequest(http.MethodGet, server.URL, nil))
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestNewTlsConfigWithClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-client", r.TLS.PeerCertificates[0].Subject.CommonName)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAnyClientCert,
+		MinVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	certFile, keyFile := writeTestClientCertificateFiles(t)
+
+	tlsConfig, err := transport.NewTlsConfig(&transport.TlsFilesConfig{
+		CaFile:   writeTestServerCaFile(t, server),
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, tlsConfig.Certificates, 1)
+
+	trans := transport.NewBaseTransportWithConfig(&transport.BaseTransportConfig{
+		TlsConfig: tlsConfig,
+	})
+
+	resp, err := trans.RoundTrip(httptest.NewRequest(http.MethodGet, server.URL, nil))
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestNewTlsConfigWithInsecureSkipVerify(t *testing.T) {
+	t.Parallel()
+
+	tlsConfig, err := transport.NewTlsConfig(&transport.TlsFilesConfig{
+		InsecureSkipVerify: true,
+	})
+	assert.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+	assert.Nil(t, tlsConfig.RootCAs)
+}
+
+func TestNewTlsConfigFailures(t *testing.T) {
+	t.Parallel()
+
+	invalidFile := filepath.Join(t.TempDir(), "invalid.pem")
+	err := os.WriteFile(invalidFile, []byte("invalid"), 0o600)
+	assert.NoError(t, err)
+
+	certFile, keyFile := writeTestClientCertificateFiles(t)
+
+	tests := []struct {
+		name     string
+		config   *transport.TlsFilesConfig
+		expected string
+	}{
+		{
+			name:     "missing ca file",
+			config:   &transport.TlsFilesConfig{CaFile: "/invalid/ca.pem"},
+			expected: "cannot read tls ca file",
+		},
+		{
+			name:     "invalid ca file",
+			config:   &transport.TlsFilesConfig{CaFile: invalidFile},
+			expected: "no valid PEM certificate found",
+		},
+		{
+			name:     "missing key file",
+			config:   &transport.TlsFilesConfig{CertFile: certFile},
+			expected: "tls cert file and key file must be both provided",
+		},
+		{
+			name:     "invalid key file",
+			config:   &transport.TlsFilesConfig{CertFile: certFile, KeyFile: invalidFile},
+			expected: "cannot load tls client certificate",
+		},
+		{
+			name:     "mismatching files",
+			config:   &transport.TlsFilesConfig{CertFile: keyFile, KeyFile: certFile},
+			expected: "cannot load tls client certificate",
+		},
+		{
+			name:     "invalid min version",
+			config:   &transport.TlsFilesConfig{MinVersion: "2.0"},
+			expected: "invalid tls version 2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := transport.NewTlsConfig(tt.config)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestFetchTlsVersion(t *testing.T) {
+	t.Parallel()
+
+	versions := map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+
+	for name, expected := range versions {
+		version, err := transport.FetchTlsVersion(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, version)
+	}
+}
@@ -1,14 +1,20 @@
 package httpserver
 
 import (
+	"time"
+
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/trace"
 	"github.com/labstack/echo/v4"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-// TracerName is the httpserver tracer name.
-const TracerName = "httpserver"
+const (
+	// TracerName is the httpserver tracer name.
+	TracerName = "httpserver"
+	// BaggageRequestIdKey is the well known baggage member key used to propagate the request id.
+	BaggageRequestIdKey = "x-request-id"
+)
 
 // CtxRequestIdKey is a contextual struct key.
 type CtxRequestIdKey struct{}
@@ -27,9 +33,37 @@ func CtxLogger(c echo.Context) *log.Logger {
 	return log.CtxLogger(c.Request().Context())
 }
 
+// CtxAuditLogger returns the contextual [log.AuditLogger] (see the AuditLoggerMiddleware), the audit events being
+// enriched with the request id.
+func CtxAuditLogger(c echo.Context) *log.AuditLogger {
+	return log.CtxAuditLogger(c.Request().Context())
+}
+
+// SetAuditActor stamps the actor of the audit events in the request context, for example from an authentication
+// middleware once the request caller is identified (see [log.WithAuditActor]).
+func SetAuditActor(c echo.Context, actor string) {
+	c.SetRequest(c.Request().WithContext(log.WithAuditActor(c.Request().Context(), actor)))
+}
+
 // CtxTracer returns the contextual [Tracer].
 //
 // [Tracer]: https://go.opentelemetry.io/otel/trace
 func CtxTracer(c echo.Context) oteltrace.Tracer {
 	return trace.CtxTracerProvider(c.Request().Context()).Tracer(TracerName)
 }
+
+// CtxRemainingBudget returns the remaining time before the contextual request deadline, and false if the request
+// has no deadline (see the RequestTimeoutMiddleware).
+func CtxRemainingBudget(c echo.Context) (time.Duration, bool) {
+	deadline, ok := c.Request().Context().Deadline()
+	if !ok {
+		return 0, false
+	}
+
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return remaining, true
+}
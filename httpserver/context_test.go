@@ -1,10 +1,12 @@
 package httpserver_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/httpserver"
 	"github.com/ankorstore/yokai/httpserver/middleware"
@@ -96,6 +98,71 @@ func TestCtxLogger(t *testing.T) {
 	})
 }
 
+func TestCtxAuditLogger(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithServiceName("test service"),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	auditLogBuffer := logtest.NewDefaultTestLogBuffer()
+	auditLogger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithServiceName("test service"),
+		log.WithOutputWriter(auditLogBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Add(middleware.HeaderXRequestId, testRequestId)
+	rec := httptest.NewRecorder()
+
+	ctx := httpServer.NewContext(req, rec)
+	handler := func(c echo.Context) error {
+		err := httpserver.CtxAuditLogger(c).Audit(c.Request().Context(), log.AuditEvent{
+			Action:   "delete",
+			Resource: "order/1",
+			Outcome:  log.AuditOutcomeSuccess,
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.String(http.StatusOK, "ok")
+	}
+
+	// like an authentication middleware
+	authMiddleware := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			httpserver.SetAuditActor(c, "user-1")
+
+			return next(c)
+		}
+	}
+
+	h := middleware.AuditLoggerMiddleware(log.NewAuditLogger(auditLogger))(
+		middleware.RequestIdMiddleware()(authMiddleware(handler)),
+	)
+
+	err = h(ctx)
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, auditLogBuffer, map[string]interface{}{
+		"service":   "test service",
+		"audit":     true,
+		"requestID": testRequestId,
+		"actor":     "user-1",
+		"action":    "delete",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"audit": true,
+	})
+}
+
 func TestCtxTracer(t *testing.T) {
 	exporter := tracetest.NewDefaultTestTraceExporter()
 
@@ -196,3 +263,37 @@ func TestCtxTracerFromGlobalsAndWithoutMiddleware(t *testing.T) {
 	assert.False(t, exporter.HasSpan("GET /test"))
 	tracetest.AssertHasTraceSpan(t, exporter, "test span")
 }
+
+func TestCtxRemainingBudget(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+
+	// without deadline
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	budget, ok := httpserver.CtxRemainingBudget(c)
+	assert.False(t, ok)
+	assert.Equal(t, time.Duration(0), budget)
+
+	// with deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	c.SetRequest(req.WithContext(ctx))
+
+	budget, ok = httpserver.CtxRemainingBudget(c)
+	assert.True(t, ok)
+	assert.Greater(t, budget, 50*time.Second)
+
+	// with elapsed deadline
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	c.SetRequest(req.WithContext(ctx))
+
+	budget, ok = httpserver.CtxRemainingBudget(c)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), budget)
+}
@@ -0,0 +1,175 @@
+package httpserver
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+)
+
+const dynamicRouterUnlockKey = "httpserver-dynamic-router-unlock"
+
+// DynamicRoute is a route to register at runtime with a [DynamicRouter].
+type DynamicRoute struct {
+	Method      string
+	Path        string
+	Handler     echo.HandlerFunc
+	Middlewares []echo.MiddlewareFunc
+}
+
+// DynamicRouter allows to safely register routes on an already serving echo instance.
+//
+// The echo router is not safe for concurrent use: the DynamicRouter holds a read lock on it while the incoming requests
+// are routed (from its pre middleware to its middleware, released before any handler execution), and a write lock while
+// registering new routes. Routes registration therefore only waits for the in-flight requests routing, never for
+// handlers executions, and the new routes are served as soon as the registration returns.
+//
+// Routes introspection (like echo Routes() or Reverse()) is not covered by this lock: use [DynamicRouter.Routes] instead.
+type DynamicRouter struct {
+	echo  *echo.Echo
+	mutex sync.RWMutex
+	names atomic.Pointer[map[string]string]
+}
+
+// NewDynamicRouter returns a new [DynamicRouter] for a provided echo instance.
+//
+// It registers its pre middleware and middleware on the echo instance: it should be created before registering other
+// middlewares, so they are executed outside the routing lock.
+func NewDynamicRouter(e *echo.Echo) *DynamicRouter {
+	router := &DynamicRouter{
+		echo: e,
+	}
+
+	e.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			router.mutex.RLock()
+
+			var once sync.Once
+			unlock := func() {
+				once.Do(router.mutex.RUnlock)
+			}
+			defer unlock()
+
+			c.Set(dynamicRouterUnlockKey, unlock)
+
+			return next(c)
+		}
+	})
+
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if unlock, ok := c.Get(dynamicRouterUnlockKey).(func()); ok {
+				// the route name is resolved while routing, under the read lock
+				c.Set(CtxRouteNameKey, router.routeName(c.Request().Method, c.Path()))
+
+				unlock()
+			}
+
+			return next(c)
+		}
+	})
+
+	return router
+}
+
+// Add registers a route at runtime, and returns an error if a route is already registered for the same method and path.
+func (r *DynamicRouter) Add(method string, path string, handler echo.HandlerFunc, middlewares ...echo.MiddlewareFunc) (*echo.Route, error) {
+	routes, err := r.AddGroup("", []*DynamicRoute{
+		{
+			Method:      method,
+			Path:        path,
+			Handler:     handler,
+			Middlewares: middlewares,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return routes[0], nil
+}
+
+// AddGroup registers at runtime a group of routes under a common prefix and middlewares.
+// The group is registered atomically: if any of its routes conflicts with an already registered one, none is registered.
+func (r *DynamicRouter) AddGroup(prefix string, routes []*DynamicRoute, middlewares ...echo.MiddlewareFunc) ([]*echo.Route, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existingRoutes := map[string]bool{}
+	for _, route := range r.echo.Routes() {
+		existingRoutes[dynamicRouteKey(route.Method, route.Path)] = true
+	}
+
+	for _, route := range routes {
+		key := dynamicRouteKey(route.Method, prefix+route.Path)
+
+		if existingRoutes[key] {
+			return nil, fmt.Errorf("route %s is already registered", RouteKey(route.Method, prefix+route.Path))
+		}
+
+		existingRoutes[key] = true
+	}
+
+	var registeredRoutes []*echo.Route
+
+	for _, route := range routes {
+		routeMiddlewares := append(append([]echo.MiddlewareFunc{}, middlewares...), route.Middlewares...)
+
+		registeredRoutes = append(
+			registeredRoutes,
+			r.echo.Add(strings.ToUpper(route.Method), prefix+route.Path, route.Handler, routeMiddlewares...),
+		)
+	}
+
+	r.names.Store(nil)
+
+	return registeredRoutes, nil
+}
+
+// Routes returns the registered routes.
+func (r *DynamicRouter) Routes() []*echo.Route {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.echo.Routes()
+}
+
+// routeName must be called under the read lock.
+func (r *DynamicRouter) routeName(method string, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	names := r.names.Load()
+	if names == nil {
+		index := map[string]string{}
+		for _, route := range r.echo.Routes() {
+			index[RouteKey(route.Method, route.Path)] = route.Name
+		}
+
+		r.names.Store(&index)
+		names = &index
+	}
+
+	if name, ok := (*names)[RouteKey(method, path)]; ok {
+		return name
+	}
+
+	// route registered directly on echo, after the index creation
+	return routeName(r.echo.Routes(), method, path)
+}
+
+// dynamicRouteKey returns a route key ignoring the path params names, since echo routes /foo/:id and /foo/:name
+// on the same node.
+func dynamicRouteKey(method string, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = ":"
+		}
+	}
+
+	return RouteKey(method, strings.Join(segments, "/"))
+}
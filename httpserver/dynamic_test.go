@@ -0,0 +1,232 @@
+package httpserver_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamicRouterAdd(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	router := httpserver.NewDynamicRouter(httpServer)
+
+	httpServer.GET("/static", func(c echo.Context) error {
+		return c.String(http.StatusOK, "static")
+	})
+
+	route, err := router.Add("get", "/dynamic/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, fmt.Sprintf("dynamic:%s", c.Param("id")))
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set("X-Dynamic", "true")
+
+			return next(c)
+		}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodGet, route.Method)
+	assert.Equal(t, "/dynamic/:id", route.Path)
+
+	req := httptest.NewRequest(http.MethodGet, "/dynamic/42", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "dynamic:42", rec.Body.String())
+	assert.Equal(t, "true", rec.Header().Get("X-Dynamic"))
+
+	// conflicts
+	_, err = router.Add(http.MethodGet, "/static", func(c echo.Context) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "route GET /static is already registered", err.Error())
+
+	_, err = router.Add(http.MethodGet, "/dynamic/:name", func(c echo.Context) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "route GET /dynamic/:name is already registered", err.Error())
+
+	// same path, other method
+	_, err = router.Add(http.MethodPost, "/static", func(c echo.Context) error {
+		return c.String(http.StatusCreated, "created")
+	})
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodPost, "/static", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestDynamicRouterAddGroup(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	router := httpserver.NewDynamicRouter(httpServer)
+
+	_, err := router.Add(http.MethodGet, "/plugin/bar", func(c echo.Context) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Path())
+	}
+
+	groupMiddleware := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set("X-Group", "plugin")
+
+			return next(c)
+		}
+	}
+
+	// conflicting group is not registered at all
+	_, err = router.AddGroup("/plugin", []*httpserver.DynamicRoute{
+		{Method: http.MethodGet, Path: "/foo", Handler: handler},
+		{Method: http.MethodGet, Path: "/bar", Handler: handler},
+	}, groupMiddleware)
+	assert.Error(t, err)
+	assert.Equal(t, "route GET /plugin/bar is already registered", err.Error())
+
+	req := httptest.NewRequest(http.MethodGet, "/plugin/foo", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// valid group
+	routes, err := router.AddGroup("/plugin", []*httpserver.DynamicRoute{
+		{Method: http.MethodGet, Path: "/foo", Handler: handler},
+		{Method: http.MethodGet, Path: "/baz", Handler: handler},
+	}, groupMiddleware)
+	assert.NoError(t, err)
+	assert.Len(t, routes, 2)
+
+	for _, path := range []string{"/plugin/foo", "/plugin/baz"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		rec = httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, path, rec.Body.String())
+		assert.Equal(t, "plugin", rec.Header().Get("X-Group"))
+	}
+
+	assert.Len(t, router.Routes(), 3)
+}
+
+func TestDynamicRouterRouteName(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	router := httpserver.NewDynamicRouter(httpServer)
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, httpserver.RouteName(c))
+	}
+
+	httpServer.GET("/static", handler).Name = "static"
+
+	request := func(path string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		return rec.Body.String()
+	}
+
+	assert.Equal(t, "static", request("/static"))
+
+	// registered at runtime
+	route, err := router.Add(http.MethodGet, "/dynamic", handler)
+	assert.NoError(t, err)
+
+	route.Name = "dynamic"
+
+	assert.Equal(t, "dynamic", request("/dynamic"))
+
+	// registered directly on echo, after the names resolution
+	httpServer.GET("/late", handler).Name = "late"
+
+	assert.Equal(t, "late", request("/late"))
+}
+
+func TestDynamicRouterConcurrency(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	router := httpserver.NewDynamicRouter(httpServer)
+
+	httpServer.GET("/static", func(c echo.Context) error {
+		return c.String(http.StatusOK, "static")
+	})
+
+	server := httptest.NewServer(httpServer)
+	defer server.Close()
+
+	var wg sync.WaitGroup
+
+	// serving
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				resp, err := http.Get(server.URL + "/static")
+				if assert.NoError(t, err) {
+					body, err := io.ReadAll(resp.Body)
+					assert.NoError(t, err)
+					assert.NoError(t, resp.Body.Close())
+
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					assert.Equal(t, "static", string(body))
+				}
+			}
+		}()
+	}
+
+	// registering while serving
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			path := fmt.Sprintf("/dynamic/%d", i)
+
+			_, err := router.Add(http.MethodGet, path, func(c echo.Context) error {
+				return c.String(http.StatusOK, path)
+			})
+			assert.NoError(t, err)
+
+			resp, err := http.Get(server.URL + path)
+			if assert.NoError(t, err) {
+				body, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.NoError(t, resp.Body.Close())
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, path, string(body))
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Len(t, router.Routes(), 11)
+}
@@ -1,16 +1,115 @@
 package httpserver
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/ankorstore/yokai/log"
 	"github.com/go-errors/errors"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
 )
 
+// Error is a structured API error, rendered by the [JsonErrorHandler] with its status, code, message and details.
+type Error struct {
+	Status   int
+	Code     string
+	Message  string
+	Details  any
+	Internal error
+}
+
+// NewError returns a new [Error], with a code derived from the status (ex: 404 => not_found).
+func NewError(status int, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Message: message,
+	}
+}
+
+// NewBadRequest returns a new 400 [Error].
+func NewBadRequest(message string) *Error {
+	return NewError(http.StatusBadRequest, message)
+}
+
+// NewUnauthorized returns a new 401 [Error].
+func NewUnauthorized(message string) *Error {
+	return NewError(http.StatusUnauthorized, message)
+}
+
+// NewForbidden returns a new 403 [Error].
+func NewForbidden(message string) *Error {
+	return NewError(http.StatusForbidden, message)
+}
+
+// NewNotFound returns a new 404 [Error].
+func NewNotFound(message string) *Error {
+	return NewError(http.StatusNotFound, message)
+}
+
+// NewConflict returns a new 409 [Error].
+func NewConflict(message string) *Error {
+	return NewError(http.StatusConflict, message)
+}
+
+// NewUnprocessableEntity returns a new 422 [Error].
+func NewUnprocessableEntity(message string) *Error {
+	return NewError(http.StatusUnprocessableEntity, message)
+}
+
+// NewInternalServerError returns a new 500 [Error].
+func NewInternalServerError(message string) *Error {
+	return NewError(http.StatusInternalServerError, message)
+}
+
+// WithCode sets the [Error] code.
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+
+	return e
+}
+
+// WithDetails sets the [Error] details.
+func (e *Error) WithDetails(details any) *Error {
+	e.Details = details
+
+	return e
+}
+
+// WithInternal sets the [Error] internal error, logged but not rendered.
+func (e *Error) WithInternal(err error) *Error {
+	e.Internal = err
+
+	return e
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	if e.Internal != nil {
+		return fmt.Sprintf("code=%d, error=%s, message=%s, internal=%v", e.Status, e.Code, e.Message, e.Internal)
+	}
+
+	return fmt.Sprintf("code=%d, error=%s, message=%s", e.Status, e.Code, e.Message)
+}
+
+// Unwrap returns the internal error.
+func (e *Error) Unwrap() error {
+	return e.Internal
+}
+
+// ErrorMapper maps an error to an [echo.HTTPError], and returns false if it does not handle the error.
+type ErrorMapper func(err error) (*echo.HTTPError, bool)
+
 // JsonErrorHandler is an [echo.HTTPErrorHandler] that outputs errors in JSON format.
 // It can also be configured to obfuscate error message (to avoid to leak sensitive details), and to add the error stack to the response.
-func JsonErrorHandler(obfuscate bool, stack bool) echo.HTTPErrorHandler {
+// Without it, the server errors (5xx) are still logged with their stack (see [log.WrapWithStack]).
+//
+// The provided [ErrorMapper] are tried in order before any other handling: the first one handling the error provides
+// the response status and message, never obfuscated since meant to be safe and documented (and the response code and
+// details if its internal error is an [Error], like with the [ErrorRegistry.Mapper]).
+func JsonErrorHandler(obfuscate bool, stack bool, mappers ...ErrorMapper) echo.HTTPErrorHandler {
 	return func(err error, c echo.Context) {
 		logger := log.CtxLogger(c.Request().Context())
 
@@ -18,8 +117,30 @@ func JsonErrorHandler(obfuscate bool, stack bool) echo.HTTPErrorHandler {
 			return
 		}
 
+		var mappedError *echo.HTTPError
+		for _, mapper := range mappers {
+			if httpError, ok := mapper(err); ok && httpError != nil {
+				mappedError = httpError
+
+				break
+			}
+		}
+
+		var apiError *Error
 		var httpError *echo.HTTPError
-		if errors.As(err, &httpError) {
+		if mappedError != nil {
+			httpError = mappedError
+
+			// mapped structured api errors (like the error registry ones) also render their code and details
+			if mappedError.Internal != nil {
+				errors.As(mappedError.Internal, &apiError)
+			}
+		} else if errors.As(err, &apiError) {
+			httpError = &echo.HTTPError{
+				Code:    apiError.Status,
+				Message: apiError.Message,
+			}
+		} else if errors.As(err, &httpError) {
 			if httpError.Internal != nil {
 				var internalHttpError *echo.HTTPError
 				if errors.As(httpError.Internal, &internalHttpError) {
@@ -36,7 +157,12 @@ func JsonErrorHandler(obfuscate bool, stack bool) echo.HTTPErrorHandler {
 		var logRespFields map[string]interface{}
 
 		if stack {
-			errStack := errors.New(err).ErrorStack()
+			var errStack interface{} = errors.New(err).ErrorStack()
+
+			// the errors carrying their own stack (like the recovered panics) are rendered with it
+			if structuredStack := log.ErrorStack(err); structuredStack != nil {
+				errStack = structuredStack
+			}
 
 			switch m := httpError.Message.(type) {
 			case error:
@@ -63,11 +189,37 @@ func JsonErrorHandler(obfuscate bool, stack bool) echo.HTTPErrorHandler {
 			}
 		}
 
-		logger.Error().Err(err).Fields(logRespFields).Msg("error handler")
+		if apiError != nil {
+			logRespFields["code"] = apiError.Code
+
+			if apiError.Details != nil {
+				logRespFields["details"] = apiError.Details
+			}
+		}
+
+		var validationError *ValidationError
+		if errors.As(err, &validationError) {
+			logRespFields["violations"] = validationError.Violations
+		}
+
+		logEvent := logger.Error()
+		if stack {
+			// the error stack is already in the fields, and must not be duplicated by the loggers with error stacks
+			logEvent = logEvent.AnErr(zerolog.ErrorFieldName, err)
+		} else if httpError.Code >= http.StatusInternalServerError {
+			// the server errors are logged with their stack, or the error handler one if they do not carry any
+			logEvent = logEvent.Stack().Err(log.WrapWithStack(err))
+		} else {
+			logEvent = logEvent.Err(err)
+		}
+
+		logEvent.Fields(logRespFields).Msg("error handler")
 
 		httpRespFields := logRespFields
 
-		if obfuscate {
+		// mapped errors messages are meant for clients, and never obfuscated
+		// structured api errors messages are meant for clients, and only obfuscated on server errors
+		if obfuscate && mappedError == nil && (apiError == nil || httpError.Code >= http.StatusInternalServerError) {
 			httpRespFields["message"] = http.StatusText(httpError.Code)
 		}
 
@@ -0,0 +1,147 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorMatcher returns true if an error matches, to be mapped by an [ErrorRegistry].
+type ErrorMatcher func(err error) bool
+
+// MatchErrorIs returns an [ErrorMatcher] matching the errors wrapping a target error (see [errors.Is]).
+func MatchErrorIs(target error) ErrorMatcher {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// MatchErrorAs returns an [ErrorMatcher] matching the errors wrapping an error of type T (see [errors.As]).
+func MatchErrorAs[T error]() ErrorMatcher {
+	return func(err error) bool {
+		var target T
+
+		return errors.As(err, &target)
+	}
+}
+
+// ErrorRegistry maps the registered errors (for example domain errors) to structured API [Error], with their status
+// and code, to be consulted by the [JsonErrorHandler] with its [ErrorRegistry.Mapper].
+type ErrorRegistry struct {
+	entries []errorRegistryEntry
+	mutex   sync.RWMutex
+}
+
+type errorRegistryEntry struct {
+	matcher ErrorMatcher
+	status  int
+	code    string
+}
+
+// NewErrorRegistry returns a new empty [ErrorRegistry].
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{
+		entries: []errorRegistryEntry{},
+	}
+}
+
+// Register maps the errors matched by an [ErrorMatcher] to a status and a code (derived from the status if empty, ex:
+// 404 => not_found). The matchers are tried in their registration order.
+func (r *ErrorRegistry) Register(matcher ErrorMatcher, status int, code string) *ErrorRegistry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if code == "" {
+		code = strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	}
+
+	r.entries = append(r.entries, errorRegistryEntry{
+		matcher: matcher,
+		status:  status,
+		code:    code,
+	})
+
+	return r
+}
+
+// RegisterIs maps the errors wrapping a target error to a status and a code (see [ErrorRegistry.Register]).
+func (r *ErrorRegistry) RegisterIs(target error, status int, code string) *ErrorRegistry {
+	return r.Register(MatchErrorIs(target), status, code)
+}
+
+// Resolve returns the structured API [Error] of the first registration matching an error, with the error message, the
+// details it was wrapped with (see [WrapErrorWithDetails]) and the error as internal error, or false if none matches.
+func (r *ErrorRegistry) Resolve(err error) (*Error, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, entry := range r.entries {
+		if !entry.matcher(err) {
+			continue
+		}
+
+		apiError := &Error{
+			Status:   entry.status,
+			Code:     entry.code,
+			Message:  err.Error(),
+			Internal: err,
+		}
+
+		var detailedErr *detailedError
+		if errors.As(err, &detailedErr) {
+			apiError.Details = detailedErr.details
+		}
+
+		return apiError, true
+	}
+
+	return nil, false
+}
+
+// Mapper returns the [ErrorMapper] resolving the errors with the [ErrorRegistry], to be provided to the
+// [JsonErrorHandler] (rendering the resolved errors code and details).
+func (r *ErrorRegistry) Mapper() ErrorMapper {
+	return func(err error) (*echo.HTTPError, bool) {
+		apiError, ok := r.Resolve(err)
+		if !ok {
+			return nil, false
+		}
+
+		return &echo.HTTPError{
+			Code:     apiError.Status,
+			Message:  apiError.Message,
+			Internal: apiError,
+		}, true
+	}
+}
+
+// WrapErrorWithDetails wraps an error with additional details, rendered when the error is resolved by an
+// [ErrorRegistry]. The wrapping error keeps the wrapped error message.
+func WrapErrorWithDetails(err error, details any) error {
+	return &detailedError{
+		err:     err,
+		details: details,
+	}
+}
+
+type detailedError struct {
+	err     error
+	details any
+}
+
+// Error returns the wrapped error message.
+func (e *detailedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *detailedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,208 @@
+package httpserver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errUserNotFound  = errors.New("user not found")
+	errUserConflict  = errors.New("user already exists")
+	errUserSuspended = errors.New("user suspended")
+)
+
+type quotaError struct {
+	limit int
+}
+
+func (e *quotaError) Error() string {
+	return fmt.Sprintf("quota of %d exceeded", e.limit)
+}
+
+func TestErrorRegistryResolve(t *testing.T) {
+	t.Parallel()
+
+	registry := httpserver.NewErrorRegistry().
+		RegisterIs(errUserNotFound, http.StatusNotFound, "").
+		RegisterIs(errUserConflict, http.StatusConflict, "user_conflict").
+		Register(httpserver.MatchErrorAs[*quotaError](), http.StatusTooManyRequests, "quota_exceeded")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected *httpserver.Error
+	}{
+		{
+			name: "error is",
+			err:  errUserNotFound,
+			expected: &httpserver.Error{
+				Status:   http.StatusNotFound,
+				Code:     "not_found",
+				Message:  "user not found",
+				Internal: errUserNotFound,
+			},
+		},
+		{
+			name: "wrapped error is",
+			err:  fmt.Errorf("cannot create: %w", errUserConflict),
+			expected: &httpserver.Error{
+				Status:  http.StatusConflict,
+				Code:    "user_conflict",
+				Message: "cannot create: user already exists",
+			},
+		},
+		{
+			name: "error as",
+			err:  &quotaError{limit: 10},
+			expected: &httpserver.Error{
+				Status:  http.StatusTooManyRequests,
+				Code:    "quota_exceeded",
+				Message: "quota of 10 exceeded",
+			},
+		},
+		{
+			name: "error with details",
+			err:  httpserver.WrapErrorWithDetails(errUserNotFound, map[string]string{"id": "12"}),
+			expected: &httpserver.Error{
+				Status:  http.StatusNotFound,
+				Code:    "not_found",
+				Message: "user not found",
+				Details: map[string]string{"id": "12"},
+			},
+		},
+		{
+			name: "unmapped error",
+			err:  errUserSuspended,
+		},
+		{
+			name: "nil error",
+			err:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			apiError, ok := registry.Resolve(tt.err)
+
+			if tt.expected == nil {
+				assert.False(t, ok)
+				assert.Nil(t, apiError)
+
+				return
+			}
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected.Status, apiError.Status)
+			assert.Equal(t, tt.expected.Code, apiError.Code)
+			assert.Equal(t, tt.expected.Message, apiError.Message)
+			assert.Equal(t, tt.expected.Details, apiError.Details)
+			assert.Equal(t, tt.err, apiError.Internal)
+		})
+	}
+}
+
+func TestErrorRegistryFirstMatchingRegistration(t *testing.T) {
+	t.Parallel()
+
+	registry := httpserver.NewErrorRegistry().
+		RegisterIs(errUserNotFound, http.StatusNotFound, "first").
+		RegisterIs(errUserNotFound, http.StatusGone, "second")
+
+	apiError, ok := registry.Resolve(errUserNotFound)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, apiError.Status)
+	assert.Equal(t, "first", apiError.Code)
+}
+
+func TestErrorHandlingWithErrorRegistry(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	registry := httpserver.NewErrorRegistry().
+		RegisterIs(errUserNotFound, http.StatusNotFound, "").
+		RegisterIs(errUserConflict, http.StatusConflict, "user_conflict").
+		Register(httpserver.MatchErrorAs[*quotaError](), http.StatusTooManyRequests, "quota_exceeded")
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.HTTPErrorHandler = httpserver.JsonErrorHandler(true, false, registry.Mapper())
+
+	httpServer.GET("/not-found", func(c echo.Context) error {
+		return httpserver.WrapErrorWithDetails(errUserNotFound, map[string]string{"id": "12"})
+	})
+
+	httpServer.GET("/conflict", func(c echo.Context) error {
+		return fmt.Errorf("cannot create: %w", errUserConflict)
+	})
+
+	httpServer.GET("/quota", func(c echo.Context) error {
+		return &quotaError{limit: 10}
+	})
+
+	httpServer.GET("/unmapped", func(c echo.Context) error {
+		return errUserSuspended
+	})
+
+	tests := []struct {
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			path:         "/not-found",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"code":"not_found","details":{"id":"12"},"message":"user not found"}`,
+		},
+		{
+			path:         "/conflict",
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"code":"user_conflict","message":"cannot create: user already exists"}`,
+		},
+		{
+			path:         "/quota",
+			expectedCode: http.StatusTooManyRequests,
+			expectedBody: `{"code":"quota_exceeded","message":"quota of 10 exceeded"}`,
+		},
+		{
+			path:         "/unmapped",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"message":"Internal Server Error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req = req.WithContext(logger.WithContext(context.Background()))
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.expectedCode, rec.Code, tt.path)
+		assert.Equal(t, tt.expectedBody+"\n", rec.Body.String(), tt.path)
+	}
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "cannot create: user already exists",
+		"code":    "user_conflict",
+		"message": "error handler",
+	})
+}
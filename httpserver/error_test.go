@@ -2,6 +2,7 @@ package httpserver_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -530,3 +531,246 @@ func TestErrorHandlingWithInternalHttpErrorWithObfuscateAndStack(t *testing.T) {
 		"message": "error handler",
 	})
 }
+
+func TestError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err            *httpserver.Error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{httpserver.NewBadRequest("msg"), http.StatusBadRequest, "bad_request"},
+		{httpserver.NewUnauthorized("msg"), http.StatusUnauthorized, "unauthorized"},
+		{httpserver.NewForbidden("msg"), http.StatusForbidden, "forbidden"},
+		{httpserver.NewNotFound("msg"), http.StatusNotFound, "not_found"},
+		{httpserver.NewConflict("msg"), http.StatusConflict, "conflict"},
+		{httpserver.NewUnprocessableEntity("msg"), http.StatusUnprocessableEntity, "unprocessable_entity"},
+		{httpserver.NewInternalServerError("msg"), http.StatusInternalServerError, "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.expectedCode, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expectedStatus, tt.err.Status)
+			assert.Equal(t, tt.expectedCode, tt.err.Code)
+			assert.Equal(t, "msg", tt.err.Message)
+			assert.Nil(t, tt.err.Details)
+			assert.Nil(t, tt.err.Internal)
+		})
+	}
+
+	internal := fmt.Errorf("internal error")
+	err := httpserver.NewNotFound("user not found").
+		WithCode("user_not_found").
+		WithDetails(map[string]int{"id": 12}).
+		WithInternal(internal)
+
+	assert.Equal(t, "user_not_found", err.Code)
+	assert.Equal(t, map[string]int{"id": 12}, err.Details)
+	assert.Equal(t, "code=404, error=user_not_found, message=user not found, internal=internal error", err.Error())
+	assert.ErrorIs(t, err, internal)
+	assert.Equal(t, "code=404, error=not_found, message=user not found", httpserver.NewNotFound("user not found").Error())
+}
+
+func TestErrorHandlingWithError(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.HTTPErrorHandler = httpserver.JsonErrorHandler(true, false)
+
+	httpServer.GET("/not-found", func(c echo.Context) error {
+		return fmt.Errorf(
+			"wrapped: %w",
+			httpserver.NewNotFound("user not found").WithCode("user_not_found").WithDetails(map[string]int{"id": 12}),
+		)
+	})
+
+	httpServer.GET("/internal", func(c echo.Context) error {
+		return httpserver.NewInternalServerError("database down").WithInternal(fmt.Errorf("connection refused"))
+	})
+
+	// client error, not obfuscated
+	req := httptest.NewRequest(http.MethodGet, "/not-found", nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"code":"user_not_found","message":"user not found","details":{"id":12}}`, rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "wrapped: code=404, error=user_not_found, message=user not found",
+		"code":    "user_not_found",
+		"message": "error handler",
+	})
+
+	// server error, obfuscated
+	req = httptest.NewRequest(http.MethodGet, "/internal", nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"code":"internal_server_error","message":"Internal Server Error"}`, rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "code=500, error=internal_server_error, message=database down, internal=connection refused",
+		"code":    "internal_server_error",
+		"message": "error handler",
+	})
+}
+
+func TestErrorHandlingWithErrorOnHeadRequest(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.HTTPErrorHandler = httpserver.JsonErrorHandler(false, false)
+
+	httpServer.HEAD("/test", func(c echo.Context) error {
+		return httpserver.NewConflict("conflict")
+	})
+
+	req := httptest.NewRequest(http.MethodHead, "/test", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+var errQuotaExceeded = errors.New("quota exceeded for account 42")
+
+func TestErrorHandlingWithErrorMappers(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	quotaMapper := func(err error) (*echo.HTTPError, bool) {
+		if errors.Is(err, errQuotaExceeded) {
+			return echo.NewHTTPError(http.StatusTooManyRequests, "quota exceeded"), true
+		}
+
+		return nil, false
+	}
+
+	shadowedMapper := func(err error) (*echo.HTTPError, bool) {
+		if errors.Is(err, errQuotaExceeded) {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "shadowed"), true
+		}
+
+		return nil, false
+	}
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.HTTPErrorHandler = httpserver.JsonErrorHandler(true, false, quotaMapper, shadowedMapper)
+
+	httpServer.GET("/quota", func(c echo.Context) error {
+		return fmt.Errorf("cannot process: %w", errQuotaExceeded)
+	})
+
+	httpServer.GET("/other", func(c echo.Context) error {
+		return fmt.Errorf("some sensitive error")
+	})
+
+	// mapped error, not obfuscated
+	req := httptest.NewRequest(http.MethodGet, "/quota", nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, `{"message":"quota exceeded"}`+"\n", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "cannot process: quota exceeded for account 42",
+		"message": "error handler",
+	})
+
+	// unmapped error, obfuscated
+	req = httptest.NewRequest(http.MethodGet, "/other", nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, `{"message":"Internal Server Error"}`+"\n", rec.Body.String())
+}
+
+func TestErrorHandlingWithServerErrorLoggedStack(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.HTTPErrorHandler = httpserver.JsonErrorHandler(false, false)
+
+	httpServer.GET("/server-error", func(c echo.Context) error {
+		return fmt.Errorf("server error")
+	})
+
+	httpServer.GET("/client-error", func(c echo.Context) error {
+		return httpserver.NewBadRequest("client error")
+	})
+
+	for _, path := range []string{"/server-error", "/client-error"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(logger.WithContext(context.Background()))
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		// the stack is only logged, never rendered without the stack option
+		assert.NotContains(t, rec.Body.String(), "stack")
+	}
+
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	// server errors are logged with their stack
+	stack, err := records[0].Attribute("stack")
+	assert.NoError(t, err)
+
+	frames, ok := stack.([]interface{})
+	assert.True(t, ok)
+	assert.NotEmpty(t, frames)
+
+	frame, ok := frames[0].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "error.go", frame["source"])
+	assert.Contains(t, frame["func"], "JsonErrorHandler")
+	assert.NotEmpty(t, frame["line"])
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "server error",
+		"message": "error handler",
+	})
+
+	// client errors are logged without stack
+	_, err = records[1].Attribute("stack")
+	assert.Error(t, err)
+}
@@ -29,9 +29,13 @@ func NewDefaultHttpServerFactory() HttpServerFactory {
 //		httpserver.WithDebug(false),                                  // debug disabled by default
 //		httpserver.WithBanner(false),                                 // banner disabled by default
 //		httpserver.WithRecovery(true),                                // panic recovery middleware enabled by default
+//		httpserver.WithRecoveryStatus(500),                           // panic recovery response status 500 by default
+//		httpserver.WithPanicTracker(nil),                             // no recovered panics tracking by default
+//		httpserver.WithMaxHeaderBytes(0),                             // net/http default max header bytes (1MB)
 //		httpserver.WithLogger(log.New("default")),                    // echo default logger
 //		httpserver.WithBinder(&echo.DefaultBinder{}),                 // echo default binder
 //		httpserver.WithJsonSerializer(&echo.DefaultJSONSerializer{}), // echo default json serializer
+//		httpserver.WithValidator(nil),                                // no validator by default
 //		httpserver.WithHttpErrorHandler(nil),                         // echo default error handler
 //	)
 func (f *DefaultHttpServerFactory) Create(options ...HttpServerOption) (*echo.Echo, error) {
@@ -49,6 +53,10 @@ func (f *DefaultHttpServerFactory) Create(options ...HttpServerOption) (*echo.Ec
 	httpServer.Binder = appliedOpts.Binder
 	httpServer.JSONSerializer = appliedOpts.JsonSerializer
 
+	if appliedOpts.Validator != nil {
+		httpServer.Validator = appliedOpts.Validator
+	}
+
 	if appliedOpts.HttpErrorHandler != nil {
 		httpServer.HTTPErrorHandler = appliedOpts.HttpErrorHandler
 	}
@@ -57,8 +65,13 @@ func (f *DefaultHttpServerFactory) Create(options ...HttpServerOption) (*echo.Ec
 		httpServer.Renderer = appliedOpts.Renderer
 	}
 
+	if appliedOpts.MaxHeaderBytes > 0 {
+		httpServer.Server.MaxHeaderBytes = appliedOpts.MaxHeaderBytes
+		httpServer.TLSServer.MaxHeaderBytes = appliedOpts.MaxHeaderBytes
+	}
+
 	if appliedOpts.Recovery {
-		httpServer.Use(middleware.Recover())
+		httpServer.Use(middleware.RecoverWithConfig(RecoverConfig(appliedOpts.RecoveryStatus, appliedOpts.PanicTracker)))
 	}
 
 	return httpServer, nil
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/httpservertest"
 	"github.com/ankorstore/yokai/httpserver/middleware"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
@@ -43,6 +45,8 @@ func TestCreate(t *testing.T) {
 	echoLogger := httpserver.NewEchoLogger(logger)
 	binder := &echo.DefaultBinder{}
 	jsonSerializer := &echo.DefaultJSONSerializer{}
+	validator, err := httpserver.NewEchoValidator()
+	assert.NoError(t, err)
 	httpErrorHandler := func(err error, c echo.Context) {}
 	render := httpserver.NewHtmlTemplateRenderer("testdata/templates/*.html")
 
@@ -53,6 +57,7 @@ func TestCreate(t *testing.T) {
 		httpserver.WithLogger(echoLogger),
 		httpserver.WithBinder(binder),
 		httpserver.WithJsonSerializer(jsonSerializer),
+		httpserver.WithValidator(validator),
 		httpserver.WithHttpErrorHandler(httpErrorHandler),
 		httpserver.WithRenderer(render),
 	)
@@ -65,6 +70,7 @@ func TestCreate(t *testing.T) {
 	assert.Equal(t, echoLogger, httpServer.Logger)
 	assert.Equal(t, binder, httpServer.Binder)
 	assert.Equal(t, jsonSerializer, httpServer.JSONSerializer)
+	assert.Equal(t, validator, httpServer.Validator)
 	assert.NotNil(t, httpServer.HTTPErrorHandler)
 	assert.NotNil(t, httpServer.Renderer)
 }
@@ -1970,3 +1976,48 @@ func TestCreateWithPanicRecovery(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), `{"message":"Internal Server Error"}`)
 	}
 }
+
+func TestCreateWithMaxHeaderBytes(t *testing.T) {
+	t.Parallel()
+
+	httpServer, err := httpserver.NewDefaultHttpServerFactory().Create(
+		httpserver.WithMaxHeaderBytes(1024),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1024, httpServer.Server.MaxHeaderBytes)
+	assert.Equal(t, 1024, httpServer.TLSServer.MaxHeaderBytes)
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	httpServer.HidePort = true
+	httpServer.Listener = httpservertest.NewTestListener()
+
+	//nolint:errcheck
+	go httpServer.Start("")
+	defer httpServer.Close()
+
+	client := httpservertest.NewTestClient(httpServer)
+
+	// within limit
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/test", nil)
+	req.RequestURI = ""
+	req.Header.Set("x-foo", "foo")
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// oversized headers (beyond the limit and the net/http 4096 bytes slack), on a new connection
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/test", nil)
+	req.RequestURI = ""
+	req.Header.Set("x-foo", strings.Repeat("x", 8192))
+
+	resp, err = httpservertest.NewTestClient(httpServer).Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+}
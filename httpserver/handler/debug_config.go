@@ -7,9 +7,18 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// DebugConfigHandler is an [echo.HandlerFunc] that returns config information.
+// maskedSettingsProvider is implemented by the configs masking their secret values when dumped.
+type maskedSettingsProvider interface {
+	MaskedSettings() map[string]interface{}
+}
+
+// DebugConfigHandler is an [echo.HandlerFunc] that returns config information, with the secret values masked.
 func DebugConfigHandler(config *config.Config) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		if provider, ok := interface{}(config).(maskedSettingsProvider); ok {
+			return c.JSON(http.StatusOK, provider.MaskedSettings())
+		}
+
 		return c.JSON(http.StatusOK, config.AllSettings())
 	}
 }
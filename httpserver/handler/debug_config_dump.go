@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	configDumpFormatJson = "json"
+	configDumpFormatYaml = "yaml"
+)
+
+// ConfigDumper is implemented by the configs able to dump their values in a format (json or yaml), like the
+// [config.Config] one (masking the secret values).
+//
+// [config.Config]: https://github.com/ankorstore/yokai/tree/main/config
+type ConfigDumper interface {
+	Dump(w io.Writer, format string) error
+}
+
+// DebugConfigDumpHandler is an [echo.HandlerFunc] that returns the fully merged config dump of a [ConfigDumper].
+//
+// The dump format is negotiated from the format query parameter (json or yaml) if provided, or else from the Accept
+// header (JSON by default).
+func DebugConfigDumpHandler(dumper ConfigDumper) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		format, contentType := configDumpFormat(c.Request())
+
+		var buffer bytes.Buffer
+		if err := dumper.Dump(&buffer, format); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "error dumping config")
+		}
+
+		return c.Blob(http.StatusOK, contentType, buffer.Bytes())
+	}
+}
+
+// configDumpFormat returns the negotiated config dump format, and its content type.
+func configDumpFormat(req *http.Request) (string, string) {
+	format := strings.ToLower(req.URL.Query().Get("format"))
+
+	if format == "" && strings.Contains(strings.ToLower(req.Header.Get(echo.HeaderAccept)), configDumpFormatYaml) {
+		format = configDumpFormatYaml
+	}
+
+	if format == configDumpFormatYaml || format == "yml" {
+		return configDumpFormatYaml, "application/yaml; charset=UTF-8"
+	}
+
+	return configDumpFormatJson, echo.MIMEApplicationJSONCharsetUTF8
+}
@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver/handler"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfigDumper struct {
+	err error
+}
+
+func (d *testConfigDumper) Dump(w io.Writer, format string) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	_, err := fmt.Fprintf(w, "dump as %s", format)
+
+	return err
+}
+
+func TestDebugConfigDumpHandler(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.GET("/_config", handler.DebugConfigDumpHandler(&testConfigDumper{}))
+
+	tests := []struct {
+		name                string
+		target              string
+		accept              string
+		expectedBody        string
+		expectedContentType string
+	}{
+		{
+			name:                "default",
+			target:              "/_config",
+			expectedBody:        "dump as json",
+			expectedContentType: "application/json; charset=UTF-8",
+		},
+		{
+			name:                "yaml accept header",
+			target:              "/_config",
+			accept:              "application/yaml",
+			expectedBody:        "dump as yaml",
+			expectedContentType: "application/yaml; charset=UTF-8",
+		},
+		{
+			name:                "yaml format query parameter",
+			target:              "/_config?format=yml",
+			accept:              "application/json",
+			expectedBody:        "dump as yaml",
+			expectedContentType: "application/yaml; charset=UTF-8",
+		},
+		{
+			name:                "json format query parameter",
+			target:              "/_config?format=json",
+			accept:              "application/yaml",
+			expectedBody:        "dump as json",
+			expectedContentType: "application/json; charset=UTF-8",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+			assert.Equal(t, tt.expectedContentType, rec.Header().Get(echo.HeaderContentType))
+		})
+	}
+}
+
+func TestDebugConfigDumpHandlerWithDumpError(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.GET("/_config", handler.DebugConfigDumpHandler(&testConfigDumper{err: fmt.Errorf("secret error")}))
+
+	req := httptest.NewRequest(http.MethodGet, "/_config", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret error")
+}
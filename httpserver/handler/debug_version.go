@@ -7,12 +7,15 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// DebugVersionHandler is an [echo.HandlerFunc] that returns version information.
+// DebugVersionHandler is an [echo.HandlerFunc] that returns version information: the application name and version,
+// and its build git commit SHA and date (from the app.build.commit and app.build.date config fields).
 func DebugVersionHandler(config *config.Config) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		return c.JSON(http.StatusOK, echo.Map{
 			"application": config.AppName(),
 			"version":     config.AppVersion(),
+			"commit":      config.GetString("app.build.commit"),
+			"date":        config.GetString("app.build.date"),
 		})
 	}
 }
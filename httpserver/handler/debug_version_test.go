@@ -30,6 +30,30 @@ func TestDebugVersionHandler(t *testing.T) {
 	assert.Contains(
 		t,
 		rec.Body.String(),
-		`{"application":"test-app","version":"0.1.0"}`,
+		`{"application":"test-app","commit":"","date":"","version":"0.1.0"}`,
+	)
+}
+
+func TestDebugVersionHandlerWithBuildInfo(t *testing.T) {
+	t.Setenv("APP_BUILD_COMMIT", "abc123")
+	t.Setenv("APP_BUILD_DATE", "2024-01-01T00:00:00Z")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("../testdata/config"),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.GET("/version", handler.DebugVersionHandler(cfg))
+
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(
+		t,
+		rec.Body.String(),
+		`{"application":"test-app","commit":"abc123","date":"2024-01-01T00:00:00Z","version":"0.1.0"}`,
 	)
 }
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+// LogLevelSetter is implemented by the loggers whose level can be changed at runtime, like the [log.Logger] one.
+//
+// [log.Logger]: https://github.com/ankorstore/yokai/tree/main/log
+type LogLevelSetter interface {
+	GetLevel() zerolog.Level
+	SetLevel(level zerolog.Level)
+}
+
+// LogLevel is the body of the [LogLevelHandler] requests and responses.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// LogLevelHandler is an [echo.HandlerFunc] that returns the current level of a [LogLevelSetter] (on GET requests), or
+// changes it at runtime (on PUT requests, with a {"level":"debug"} JSON body).
+func LogLevelHandler(setter LogLevelSetter) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Method == http.MethodPut {
+			var body LogLevel
+			if err := c.Bind(&body); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid log level request body")
+			}
+
+			level, err := parseLogLevel(body.Level)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+
+			setter.SetLevel(level)
+		}
+
+		return c.JSON(http.StatusOK, LogLevel{Level: setter.GetLevel().String()})
+	}
+}
+
+// parseLogLevel parses a log level name, accepting the log module names (like warning or no-level).
+func parseLogLevel(name string) (zerolog.Level, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+
+	switch normalized {
+	case "":
+		return zerolog.NoLevel, fmt.Errorf("missing log level")
+	case "warning":
+		return zerolog.WarnLevel, nil
+	case "no-level":
+		return zerolog.NoLevel, nil
+	}
+
+	level, err := zerolog.ParseLevel(normalized)
+	if err != nil {
+		return zerolog.NoLevel, fmt.Errorf("invalid log level %s", name)
+	}
+
+	return level, nil
+}
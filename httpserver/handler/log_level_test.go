@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver/handler"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogLevelSetter struct {
+	level zerolog.Level
+}
+
+func (s *testLogLevelSetter) GetLevel() zerolog.Level {
+	return s.level
+}
+
+func (s *testLogLevelSetter) SetLevel(level zerolog.Level) {
+	s.level = level
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	t.Parallel()
+
+	setter := &testLogLevelSetter{level: zerolog.InfoLevel}
+
+	httpServer := echo.New()
+	httpServer.GET("/_log/level", handler.LogLevelHandler(setter))
+	httpServer.PUT("/_log/level", handler.LogLevelHandler(setter))
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedCode   int
+		expectedBody   string
+		expectedLevel  zerolog.Level
+		expectedErrMsg string
+	}{
+		{
+			name:          "get",
+			method:        http.MethodGet,
+			expectedCode:  http.StatusOK,
+			expectedBody:  `{"level":"info"}`,
+			expectedLevel: zerolog.InfoLevel,
+		},
+		{
+			name:          "put debug",
+			method:        http.MethodPut,
+			body:          `{"level":"debug"}`,
+			expectedCode:  http.StatusOK,
+			expectedBody:  `{"level":"debug"}`,
+			expectedLevel: zerolog.DebugLevel,
+		},
+		{
+			name:          "put warning",
+			method:        http.MethodPut,
+			body:          `{"level":"Warning"}`,
+			expectedCode:  http.StatusOK,
+			expectedBody:  `{"level":"warn"}`,
+			expectedLevel: zerolog.WarnLevel,
+		},
+		{
+			name:           "put invalid level",
+			method:         http.MethodPut,
+			body:           `{"level":"verbose"}`,
+			expectedCode:   http.StatusBadRequest,
+			expectedErrMsg: "invalid log level verbose",
+			expectedLevel:  zerolog.WarnLevel,
+		},
+		{
+			name:           "put missing level",
+			method:         http.MethodPut,
+			body:           `{}`,
+			expectedCode:   http.StatusBadRequest,
+			expectedErrMsg: "missing log level",
+			expectedLevel:  zerolog.WarnLevel,
+		},
+		{
+			name:           "put invalid body",
+			method:         http.MethodPut,
+			body:           `{"level":`,
+			expectedCode:   http.StatusBadRequest,
+			expectedErrMsg: "invalid log level request body",
+			expectedLevel:  zerolog.WarnLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/_log/level", strings.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.expectedCode, rec.Code, tt.name)
+		assert.Equal(t, tt.expectedLevel, setter.GetLevel(), tt.name)
+
+		if tt.expectedBody != "" {
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String(), tt.name)
+		}
+
+		if tt.expectedErrMsg != "" {
+			assert.Contains(t, rec.Body.String(), tt.expectedErrMsg, tt.name)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsHandler is an [echo.HandlerFunc] that exposes the metrics of a [prometheus.Gatherer], in prometheus format.
+func MetricsHandler(gatherer prometheus.Gatherer) echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
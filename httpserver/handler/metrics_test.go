@@ -0,0 +1,36 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver/handler"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	t.Parallel()
+
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "test_counter_total",
+		Help: "Test counter",
+	})
+	counter.Add(3)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(counter)
+
+	httpServer := echo.New()
+	httpServer.GET("/metrics", handler.MetricsHandler(registry))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "# TYPE test_counter_total counter")
+	assert.Contains(t, rec.Body.String(), "test_counter_total 3")
+}
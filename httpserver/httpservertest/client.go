@@ -0,0 +1,34 @@
+package httpservertest
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewTestClient returns an [http.Client] sending its requests to the provided started [echo.Echo], whatever the
+// requests urls host.
+//
+// If the server listens on a [TestListener], the requests are sent in-memory, otherwise on the server listener address.
+func NewTestClient(httpServer *echo.Echo) *http.Client {
+	transport := &http.Transport{}
+
+	switch listener := httpServer.Listener.(type) {
+	case *TestListener:
+		transport.DialContext = listener.DialContext
+	case net.Listener:
+		addr := listener.Addr()
+
+		transport.DialContext = func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+
+			return dialer.DialContext(ctx, addr.Network(), addr.String())
+		}
+	}
+
+	return &http.Client{
+		Transport: transport,
+	}
+}
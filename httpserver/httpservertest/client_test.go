@@ -0,0 +1,76 @@
+package httpservertest_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver/httpservertest"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTestClient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		listener func(t *testing.T) net.Listener
+	}{
+		{
+			"with test listener",
+			func(t *testing.T) net.Listener {
+				t.Helper()
+
+				return httpservertest.NewTestListener()
+			},
+		},
+		{
+			"with tcp listener",
+			func(t *testing.T) net.Listener {
+				t.Helper()
+
+				listener, err := net.Listen("tcp", "127.0.0.1:0")
+				assert.NoError(t, err)
+
+				return listener
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpServer := echo.New()
+			httpServer.HideBanner = true
+			httpServer.HidePort = true
+			httpServer.Listener = tt.listener(t)
+
+			httpServer.GET("/test", func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			//nolint:errcheck
+			go httpServer.Start("")
+			defer httpServer.Shutdown(context.Background())
+
+			client := httpservertest.NewTestClient(httpServer)
+
+			//nolint:noctx
+			resp, err := client.Get("http://any-host/test")
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "ok", string(body))
+		})
+	}
+}
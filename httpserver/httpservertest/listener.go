@@ -0,0 +1,76 @@
+package httpservertest
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// TestListener is an in-memory [net.Listener], to serve http requests without network.
+type TestListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewTestListener returns a new [TestListener].
+func NewTestListener() *TestListener {
+	return &TestListener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l *TestListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the listener.
+func (l *TestListener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+
+	return nil
+}
+
+// Addr returns the listener in-memory network address.
+func (l *TestListener) Addr() net.Addr {
+	return testAddr{}
+}
+
+// DialContext returns a new in-memory connection to the listener, the network and address are ignored.
+func (l *TestListener) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	serverConn, clientConn := net.Pipe()
+
+	select {
+	case l.conns <- serverConn:
+		return clientConn, nil
+	case <-l.done:
+		serverConn.Close()
+		clientConn.Close()
+
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		serverConn.Close()
+		clientConn.Close()
+
+		return nil, ctx.Err()
+	}
+}
+
+type testAddr struct{}
+
+func (testAddr) Network() string {
+	return "memory"
+}
+
+func (testAddr) String() string {
+	return "memory"
+}
@@ -0,0 +1,59 @@
+package httpservertest_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver/httpservertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestListener(t *testing.T) {
+	t.Parallel()
+
+	listener := httpservertest.NewTestListener()
+	assert.Implements(t, (*net.Listener)(nil), listener)
+	assert.Equal(t, "memory", listener.Addr().Network())
+	assert.Equal(t, "memory", listener.Addr().String())
+
+	go func() {
+		conn, err := listener.Accept()
+		assert.NoError(t, err)
+
+		_, err = conn.Write([]byte("foo"))
+		assert.NoError(t, err)
+		assert.NoError(t, conn.Close())
+	}()
+
+	conn, err := listener.DialContext(context.Background(), "tcp", "ignored:80")
+	assert.NoError(t, err)
+
+	buffer := make([]byte, 3)
+	_, err = conn.Read(buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(buffer))
+	assert.NoError(t, conn.Close())
+
+	assert.NoError(t, listener.Close())
+	assert.NoError(t, listener.Close())
+
+	_, err = listener.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	_, err = listener.DialContext(context.Background(), "tcp", "ignored:80")
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
+
+func TestTestListenerDialWithCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	listener := httpservertest.NewTestListener()
+	defer listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := listener.DialContext(ctx, "tcp", "ignored:80")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -34,6 +34,16 @@ func TestLevelConversion(t *testing.T) {
 	echoLogger := httpserver.NewEchoLogger(logger)
 
 	assert.Equal(t, echologger.DEBUG, echoLogger.Level())
+
+	logger, err = log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.WarnLevel),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, echologger.WARN, httpserver.NewEchoLogger(logger).Level())
+
+	logger.SetLevel(zerolog.InfoLevel)
+	assert.Equal(t, echologger.INFO, httpserver.NewEchoLogger(logger).Level())
 }
 
 func TestPrefix(t *testing.T) {
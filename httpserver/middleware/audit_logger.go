@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// AuditLoggerMiddlewareConfig is the configuration for the [AuditLoggerMiddleware].
+type AuditLoggerMiddlewareConfig struct {
+	Skipper     middleware.Skipper
+	AuditLogger *log.AuditLogger
+}
+
+// DefaultAuditLoggerMiddlewareConfig is the default configuration for the [AuditLoggerMiddleware].
+var DefaultAuditLoggerMiddlewareConfig = AuditLoggerMiddlewareConfig{
+	Skipper: middleware.DefaultSkipper,
+}
+
+// AuditLoggerMiddleware returns a [AuditLoggerMiddleware] for a provided [log.AuditLogger].
+func AuditLoggerMiddleware(auditLogger *log.AuditLogger) echo.MiddlewareFunc {
+	return AuditLoggerMiddlewareWithConfig(AuditLoggerMiddlewareConfig{
+		AuditLogger: auditLogger,
+	})
+}
+
+// AuditLoggerMiddlewareWithConfig returns a [AuditLoggerMiddleware] for a provided [AuditLoggerMiddlewareConfig].
+//
+// The configured [log.AuditLogger] is carried by the request context, to be retrieved with the httpserver module
+// CtxAuditLogger.
+func AuditLoggerMiddlewareWithConfig(config AuditLoggerMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultAuditLoggerMiddlewareConfig.Skipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || config.AuditLogger == nil {
+				return next(c)
+			}
+
+			req := c.Request()
+			c.SetRequest(req.WithContext(config.AuditLogger.WithContext(req.Context())))
+
+			return next(c)
+		}
+	}
+}
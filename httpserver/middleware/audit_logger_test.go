@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLoggerMiddleware(t *testing.T) {
+	t.Parallel()
+
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(logtest.NewDefaultTestLogBuffer())))
+
+	httpServer := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	var ctxAuditLogger *log.AuditLogger
+
+	ctx := httpServer.NewContext(req, rec)
+	handler := func(c echo.Context) error {
+		ctxAuditLogger = log.CtxAuditLogger(c.Request().Context())
+
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	m := middleware.AuditLoggerMiddleware(auditLogger)
+	h := m(handler)
+
+	err := h(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Same(t, auditLogger, ctxAuditLogger)
+}
+
+func TestAuditLoggerMiddlewareWithSkipper(t *testing.T) {
+	t.Parallel()
+
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(logtest.NewDefaultTestLogBuffer())))
+
+	httpServer := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	var ctxAuditLogger *log.AuditLogger
+
+	ctx := httpServer.NewContext(req, rec)
+	handler := func(c echo.Context) error {
+		ctxAuditLogger = log.CtxAuditLogger(c.Request().Context())
+
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	m := middleware.AuditLoggerMiddlewareWithConfig(middleware.AuditLoggerMiddlewareConfig{
+		Skipper: func(echo.Context) bool {
+			return true
+		},
+		AuditLogger: auditLogger,
+	})
+	h := m(handler)
+
+	err := h(ctx)
+	assert.NoError(t, err)
+
+	assert.NotSame(t, auditLogger, ctxAuditLogger)
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+)
+
+// BaseUrlMiddleware returns a middleware setting the external base url in the request context, to generate absolute
+// urls with [httpserver.URL].
+func BaseUrlMiddleware(baseUrl string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), httpserver.CtxBaseUrlKey{}, baseUrl)))
+
+			return next(c)
+		}
+	}
+}
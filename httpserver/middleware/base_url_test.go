@@ -0,0 +1,33 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseUrlMiddleware(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	ctx := httpServer.NewContext(req, rec)
+
+	assert.Equal(t, "", httpserver.CtxBaseUrl(ctx))
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, httpserver.CtxBaseUrl(c))
+	}
+
+	err := middleware.BaseUrlMiddleware("https://example.com")(handler)(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://example.com", rec.Body.String())
+}
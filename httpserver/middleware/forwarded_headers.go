@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const HeaderXForwardedHost = "X-Forwarded-Host"
+
+// ForwardedHeadersMiddlewareConfig is the configuration for the [ForwardedHeadersMiddleware].
+type ForwardedHeadersMiddlewareConfig struct {
+	Skipper        middleware.Skipper
+	TrustedProxies []*net.IPNet
+}
+
+// DefaultForwardedHeadersMiddlewareConfig is the default configuration for the [ForwardedHeadersMiddleware].
+var DefaultForwardedHeadersMiddlewareConfig = ForwardedHeadersMiddlewareConfig{
+	Skipper:        middleware.DefaultSkipper,
+	TrustedProxies: defaultTrustedProxies(),
+}
+
+// ForwardedHeadersMiddleware returns a [ForwardedHeadersMiddleware] with the [DefaultForwardedHeadersMiddlewareConfig].
+func ForwardedHeadersMiddleware() echo.MiddlewareFunc {
+	return ForwardedHeadersMiddlewareWithConfig(DefaultForwardedHeadersMiddlewareConfig)
+}
+
+// ForwardedHeadersMiddlewareWithConfig returns a [ForwardedHeadersMiddleware] for a provided [ForwardedHeadersMiddlewareConfig].
+//
+// It is meant to be used as a pre middleware: for requests coming from the trusted proxies, it applies the
+// X-Forwarded-Proto and X-Forwarded-Host headers to the request url scheme and host, so c.Scheme() and c.Request().Host
+// reflect the values seen by the client (to build absolute redirects or urls). For requests coming from other addresses,
+// the forwarded scheme and host headers are removed, so they cannot be spoofed.
+func ForwardedHeadersMiddlewareWithConfig(config ForwardedHeadersMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultForwardedHeadersMiddlewareConfig.Skipper
+	}
+
+	if config.TrustedProxies == nil {
+		config.TrustedProxies = DefaultForwardedHeadersMiddlewareConfig.TrustedProxies
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// skipper
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+
+			if !httpserver.IsTrustedProxy(req.RemoteAddr, config.TrustedProxies) {
+				req.Header.Del(echo.HeaderXForwardedProto)
+				req.Header.Del(echo.HeaderXForwardedProtocol)
+				req.Header.Del(echo.HeaderXForwardedSsl)
+				req.Header.Del(echo.HeaderXUrlScheme)
+				req.Header.Del(HeaderXForwardedHost)
+
+				return next(c)
+			}
+
+			if proto := req.Header.Get(echo.HeaderXForwardedProto); proto != "" {
+				if scheme := strings.ToLower(firstForwardedValue(proto)); scheme == "http" || scheme == "https" {
+					req.Header.Set(echo.HeaderXForwardedProto, scheme)
+					req.URL.Scheme = scheme
+				} else {
+					req.Header.Del(echo.HeaderXForwardedProto)
+				}
+			}
+
+			if host := firstForwardedValue(req.Header.Get(HeaderXForwardedHost)); host != "" && !strings.ContainsAny(host, "/\\ @") {
+				req.Host = host
+				req.URL.Host = host
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// firstForwardedValue returns the first value of a comma separated forwarded header, set by the proxy the closest to
+// the client.
+func firstForwardedValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+
+	return strings.TrimSpace(first)
+}
+
+func defaultTrustedProxies() []*net.IPNet {
+	networks, err := httpserver.ParseTrustedProxies(httpserver.DefaultTrustedProxies)
+	if err != nil {
+		panic(err)
+	}
+
+	return networks
+}
@@ -0,0 +1,138 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardedHeadersMiddlewareWithTrustedProxy(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.Pre(middleware.ForwardedHeadersMiddleware())
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Scheme()+"://"+c.Request().Host)
+	})
+
+	httpServer.GET("/redirect", func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, c.Scheme()+"://"+c.Request().Host+"/test")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.RemoteAddr = "10.0.0.1:12345"
+	req.Header.Set(echo.HeaderXForwardedProto, "https, http")
+	req.Header.Set(middleware.HeaderXForwardedHost, "example.com, internal.local")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://example.com", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/redirect", nil)
+	req.RemoteAddr = "10.0.0.1:12345"
+	req.Header.Set(echo.HeaderXForwardedProto, "https")
+	req.Header.Set(middleware.HeaderXForwardedHost, "example.com")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://example.com/test", rec.Header().Get(echo.HeaderLocation))
+
+	// invalid values are ignored
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.RemoteAddr = "10.0.0.1:12345"
+	req.Host = "internal.local"
+	req.Header.Set(echo.HeaderXForwardedProto, "ftp")
+	req.Header.Set(middleware.HeaderXForwardedHost, "evil.com/path")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "http://internal.local", rec.Body.String())
+}
+
+func TestForwardedHeadersMiddlewareWithUntrustedProxy(t *testing.T) {
+	t.Parallel()
+
+	trustedProxies, err := httpserver.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Pre(middleware.ForwardedHeadersMiddlewareWithConfig(middleware.ForwardedHeadersMiddlewareConfig{
+		TrustedProxies: trustedProxies,
+	}))
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Scheme()+"://"+c.Request().Host)
+	})
+
+	for _, remoteAddr := range []string{"192.168.1.1:12345", "203.0.113.1:12345"} {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		req.Host = "internal.local"
+		req.Header.Set(echo.HeaderXForwardedProto, "https")
+		req.Header.Set(echo.HeaderXForwardedSsl, "on")
+		req.Header.Set(middleware.HeaderXForwardedHost, "example.com")
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "http://internal.local", rec.Body.String(), remoteAddr)
+	}
+}
+
+func TestForwardedHeadersMiddlewareWithRequestLogger(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.Pre(middleware.ForwardedHeadersMiddleware())
+	httpServer.Use(middleware.RequestLoggerMiddleware())
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	// trusted
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	req.Header.Set(echo.HeaderXForwardedProto, "https")
+	req.Header.Set(middleware.HeaderXRequestId, "trusted-request-id")
+	httpServer.ServeHTTP(httptest.NewRecorder(), req)
+
+	// untrusted
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.RemoteAddr = "203.0.113.1:12345"
+	req.Header.Set(echo.HeaderXForwardedProto, "https")
+	req.Header.Set(middleware.HeaderXRequestId, "untrusted-request-id")
+	httpServer.ServeHTTP(httptest.NewRecorder(), req)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"scheme":    "https",
+		"requestID": "trusted-request-id",
+		"message":   "request logger",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"scheme":    "http",
+		"requestID": "untrusted-request-id",
+		"message":   "request logger",
+	})
+}
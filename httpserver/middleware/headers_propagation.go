@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"github.com/ankorstore/yokai/httpclient/propagated"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// HeadersPropagationMiddlewareConfig is the configuration for the [HeadersPropagationMiddleware].
+type HeadersPropagationMiddlewareConfig struct {
+	Skipper middleware.Skipper
+	Headers []string
+}
+
+// DefaultHeadersPropagationMiddlewareConfig is the default configuration for the [HeadersPropagationMiddleware].
+var DefaultHeadersPropagationMiddlewareConfig = HeadersPropagationMiddlewareConfig{
+	Skipper: middleware.DefaultSkipper,
+	Headers: []string{},
+}
+
+// HeadersPropagationMiddleware returns a [HeadersPropagationMiddleware] for a provided list of headers.
+func HeadersPropagationMiddleware(headers ...string) echo.MiddlewareFunc {
+	return HeadersPropagationMiddlewareWithConfig(HeadersPropagationMiddlewareConfig{
+		Headers: headers,
+	})
+}
+
+// HeadersPropagationMiddlewareWithConfig returns a [HeadersPropagationMiddleware] for a provided
+// [HeadersPropagationMiddlewareConfig].
+//
+// The values of the configured incoming request headers are stored as is in the request context (see
+// [propagated.WithHeaders]), to be propagated on the outgoing requests made with this context (see the
+// httpclient module PropagationTransport). They are not added to the baggage, to not leak them to every downstream service.
+func HeadersPropagationMiddlewareWithConfig(config HeadersPropagationMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultHeadersPropagationMiddlewareConfig.Skipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || len(config.Headers) == 0 {
+				return next(c)
+			}
+
+			req := c.Request()
+			ctx := req.Context()
+			headers := propagated.CtxHeaders(ctx).Clone()
+
+			for _, header := range config.Headers {
+				if value := req.Header.Get(header); value != "" {
+					headers.Set(header, value)
+				}
+			}
+
+			c.SetRequest(req.WithContext(propagated.WithHeaders(ctx, headers)))
+
+			return next(c)
+		}
+	}
+}
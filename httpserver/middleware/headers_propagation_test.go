@@ -0,0 +1,79 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/propagated"
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestHeadersPropagationMiddleware(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant-Id", "tenant-1")
+	req.Header.Set("X-Locale", "fr, en;q=0.8")
+	req.Header.Set("X-Other", "other")
+	rec := httptest.NewRecorder()
+
+	var headers http.Header
+	var bag baggage.Baggage
+
+	ctx := httpServer.NewContext(req, rec)
+	handler := func(c echo.Context) error {
+		headers = propagated.CtxHeaders(c.Request().Context())
+		bag = baggage.FromContext(c.Request().Context())
+
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	m := middleware.HeadersPropagationMiddleware("X-Tenant-Id", "x-locale", "x-missing")
+	h := m(handler)
+
+	err := h(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, headers, 2)
+	assert.Equal(t, "tenant-1", headers.Get("x-tenant-id"))
+	assert.Equal(t, "fr, en;q=0.8", headers.Get("x-locale"))
+	assert.Equal(t, "", headers.Get("x-other"))
+	assert.Equal(t, 0, bag.Len())
+}
+
+func TestHeadersPropagationMiddlewareWithSkipper(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant-Id", "tenant-1")
+	rec := httptest.NewRecorder()
+
+	var headers http.Header
+
+	ctx := httpServer.NewContext(req, rec)
+	handler := func(c echo.Context) error {
+		headers = propagated.CtxHeaders(c.Request().Context())
+
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	m := middleware.HeadersPropagationMiddlewareWithConfig(middleware.HeadersPropagationMiddlewareConfig{
+		Skipper: func(echo.Context) bool {
+			return true
+		},
+		Headers: []string{"x-tenant-id"},
+	})
+	h := m(handler)
+
+	err := h(ctx)
+	assert.NoError(t, err)
+
+	assert.Len(t, headers, 0)
+}
@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/sync/singleflight"
+)
+
+// HeaderXCoalesced is the header set on the responses shared from a coalesced request.
+const HeaderXCoalesced = "X-Coalesced"
+
+// coalesced requests states.
+const (
+	coalescingPending int32 = iota
+	coalescingRunning
+	coalescingAbandoned
+)
+
+var errCoalescedRequestAbandoned = errors.New("coalesced request abandoned")
+
+// DefaultRequestCoalescingSharedHeaders are the response headers shared with the coalesced requests by default: only
+// the content ones, the others (like Set-Cookie, or the request and trace ids) being specific to the executing request.
+var DefaultRequestCoalescingSharedHeaders = []string{
+	echo.HeaderCacheControl,
+	echo.HeaderContentDisposition,
+	echo.HeaderContentEncoding,
+	"Content-Language",
+	echo.HeaderContentLength,
+	echo.HeaderContentType,
+	"ETag",
+	"Expires",
+	echo.HeaderLastModified,
+	echo.HeaderVary,
+}
+
+// RequestCoalescingKeyFunc computes the key of a request: the concurrent requests with the same key are coalesced,
+// and an empty key disables the coalescing of the request.
+//
+// Since the coalesced requests share the same response, the key must include everything the response depends on,
+// and especially the caller identity (credentials headers, tenant, etc.), to not leak responses across users.
+type RequestCoalescingKeyFunc func(c echo.Context) string
+
+// RequestCoalescingMiddlewareConfig is the configuration for the [RequestCoalescingMiddleware].
+type RequestCoalescingMiddlewareConfig struct {
+	Skipper       middleware.Skipper
+	KeyFunc       RequestCoalescingKeyFunc
+	SharedHeaders []string
+}
+
+// DefaultRequestCoalescingMiddlewareConfig is the default configuration for the [RequestCoalescingMiddleware].
+var DefaultRequestCoalescingMiddlewareConfig = RequestCoalescingMiddlewareConfig{
+	Skipper:       middleware.DefaultSkipper,
+	KeyFunc:       DefaultRequestCoalescingKeyFunc,
+	SharedHeaders: []string{},
+}
+
+// DefaultRequestCoalescingKeyFunc is the default [RequestCoalescingKeyFunc], keying the GET requests by method, path,
+// query and credentials (Authorization and Cookie headers), and disabling the coalescing of the other requests.
+func DefaultRequestCoalescingKeyFunc(c echo.Context) string {
+	req := c.Request()
+
+	if req.Method != http.MethodGet {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"%s %s?%s %q %q",
+		req.Method,
+		req.URL.Path,
+		req.URL.Query().Encode(),
+		req.Header.Values(echo.HeaderAuthorization),
+		req.Header.Values(echo.HeaderCookie),
+	)
+}
+
+// RequestCoalescingMiddleware returns a [RequestCoalescingMiddleware] with the [DefaultRequestCoalescingMiddlewareConfig].
+func RequestCoalescingMiddleware() echo.MiddlewareFunc {
+	return RequestCoalescingMiddlewareWithConfig(DefaultRequestCoalescingMiddlewareConfig)
+}
+
+// RequestCoalescingMiddlewareWithConfig returns a [RequestCoalescingMiddleware] for a provided
+// [RequestCoalescingMiddlewareConfig].
+//
+// The concurrent requests with the same key are coalesced into a single handler execution: the first request runs
+// the handler, and its response is shared with the requests waiting for it (with the X-Coalesced header). Nothing is
+// cached: once the handler returns, the next request runs it again.
+//
+// Only the [DefaultRequestCoalescingSharedHeaders] response headers, and the configured SharedHeaders, are shared with
+// the waiting requests: the Set-Cookie header is never shared.
+//
+// If the handler fails (returned error or 5xx response), its failure is not shared: the waiting requests run the
+// handler on their own. A waiting request stops waiting when its context is done, without impacting the others.
+func RequestCoalescingMiddlewareWithConfig(config RequestCoalescingMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultRequestCoalescingMiddlewareConfig.Skipper
+	}
+
+	if config.KeyFunc == nil {
+		config.KeyFunc = DefaultRequestCoalescingMiddlewareConfig.KeyFunc
+	}
+
+	var sharedHeaders []string
+	for _, names := range [][]string{DefaultRequestCoalescingSharedHeaders, config.SharedHeaders} {
+		for _, name := range names {
+			if name = http.CanonicalHeaderKey(name); name != echo.HeaderSetCookie {
+				sharedHeaders = append(sharedHeaders, name)
+			}
+		}
+	}
+
+	group := &singleflight.Group{}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || httpserver.IsSSERequest(c) || httpserver.IsWebSocketRequest(c) {
+				return next(c)
+			}
+
+			key := config.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+
+			// only the function of the first request of the key is executed, unless this request is already gone
+			state := &atomic.Int32{}
+			started := make(chan struct{})
+
+			resultChan := group.DoChan(key, func() (interface{}, error) {
+				if !state.CompareAndSwap(coalescingPending, coalescingRunning) {
+					return nil, errCoalescedRequestAbandoned
+				}
+
+				close(started)
+
+				return executeCoalescedRequest(c, next, sharedHeaders)
+			})
+
+			select {
+			case <-started:
+				return leaderResult(<-resultChan)
+			case result := <-resultChan:
+				if state.Load() == coalescingRunning {
+					return leaderResult(result)
+				}
+
+				//nolint:forcetypeassert
+				if result.Err != nil || result.Val.(*coalescedResponse).status >= http.StatusInternalServerError {
+					return next(c)
+				}
+
+				//nolint:forcetypeassert
+				return writeCoalescedResponse(c, result.Val.(*coalescedResponse))
+			case <-c.Request().Context().Done():
+				// the leader handler honors its own context, and writes its own response
+				if !state.CompareAndSwap(coalescingPending, coalescingAbandoned) {
+					return leaderResult(<-resultChan)
+				}
+
+				return c.Request().Context().Err()
+			}
+		}
+	}
+}
+
+// coalescedResponse is a response shared between coalesced requests.
+type coalescedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// leaderResult returns the result of the request which executed the handler, its response being already written.
+func leaderResult(result singleflight.Result) error {
+	var panicErr *coalescedPanicError
+	if errors.As(result.Err, &panicErr) {
+		panic(panicErr.value)
+	}
+
+	return result.Err
+}
+
+// coalescedPanicError transports a handler panic to the leader request, to be raised again in its own goroutine.
+type coalescedPanicError struct {
+	value interface{}
+}
+
+func (e *coalescedPanicError) Error() string {
+	return fmt.Sprintf("coalesced request panic: %v", e.value)
+}
+
+func executeCoalescedRequest(c echo.Context, next echo.HandlerFunc, sharedHeaders []string) (resp *coalescedResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp, err = nil, &coalescedPanicError{value: r}
+		}
+	}()
+
+	body := new(bytes.Buffer)
+	writer := c.Response().Writer
+	c.Response().Writer = newBodyDumpResponseWriter(writer, body)
+
+	defer func() {
+		c.Response().Writer = writer
+	}()
+
+	if err = next(c); err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	for _, name := range sharedHeaders {
+		if values := c.Response().Header().Values(name); len(values) > 0 {
+			header[name] = append([]string(nil), values...)
+		}
+	}
+
+	return &coalescedResponse{
+		status: c.Response().Status,
+		header: header,
+		body:   body.Bytes(),
+	}, nil
+}
+
+func writeCoalescedResponse(c echo.Context, resp *coalescedResponse) error {
+	res := c.Response()
+
+	for name, values := range resp.header {
+		res.Header()[name] = values
+	}
+	res.Header().Set(HeaderXCoalesced, "true")
+	res.WriteHeader(resp.status)
+
+	_, err := res.Write(resp.body)
+
+	return err
+}
@@ -0,0 +1,372 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestCoalescingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	const concurrency = 10
+
+	var calls atomic.Int32
+	var keys atomic.Int32
+	release := make(chan struct{})
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestCoalescingMiddlewareWithConfig(middleware.RequestCoalescingMiddlewareConfig{
+		KeyFunc: func(c echo.Context) string {
+			keys.Add(1)
+
+			return middleware.DefaultRequestCoalescingKeyFunc(c)
+		},
+	}))
+	httpServer.GET("/expensive", func(c echo.Context) error {
+		calls.Add(1)
+
+		<-release
+
+		c.Response().Header().Set("ETag", "foo")
+
+		return c.String(http.StatusOK, "expensive result")
+	})
+
+	recorders := make([]*httptest.ResponseRecorder, concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodGet, "/expensive?a=1&b=2", nil)
+			recorders[i] = httptest.NewRecorder()
+			httpServer.ServeHTTP(recorders[i], req)
+		}(i)
+	}
+
+	// all requests keyed and waiting for the single handler execution
+	assert.Eventually(t, func() bool {
+		return keys.Load() == concurrency && calls.Load() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	coalesced := 0
+	for _, rec := range recorders {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "expensive result", rec.Body.String())
+		assert.Equal(t, "foo", rec.Header().Get("ETag"))
+
+		if rec.Header().Get(middleware.HeaderXCoalesced) == "true" {
+			coalesced++
+		}
+	}
+
+	assert.Equal(t, concurrency-1, coalesced)
+
+	// nothing cached once the handler returned
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/expensive?a=1&b=2", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(middleware.HeaderXCoalesced))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRequestCoalescingMiddlewareWithSharedHeaders(t *testing.T) {
+	t.Parallel()
+
+	const concurrency = 5
+
+	var calls atomic.Int32
+	var requests atomic.Int32
+	release := make(chan struct{})
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestIdMiddleware())
+	httpServer.Use(middleware.RequestCoalescingMiddlewareWithConfig(middleware.RequestCoalescingMiddlewareConfig{
+		SharedHeaders: []string{"x-foo", "Set-Cookie"},
+	}))
+	httpServer.GET("/expensive", func(c echo.Context) error {
+		calls.Add(1)
+
+		<-release
+
+		c.Response().Header().Set("X-Foo", "foo")
+		c.Response().Header().Set("X-Bar", "bar")
+		c.Response().Header().Set(middleware.HeaderXTraceId, "leader-trace-id")
+		c.Response().Header().Add(echo.HeaderSetCookie, "session=leader")
+
+		return c.JSON(http.StatusOK, map[string]string{"result": "expensive"})
+	})
+
+	recorders := make([]*httptest.ResponseRecorder, concurrency)
+	requestIds := make([]string, concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			requestIds[i] = fmt.Sprintf("request-%d", i)
+
+			req := httptest.NewRequest(http.MethodGet, "/expensive", nil)
+			req.Header.Set(echo.HeaderXRequestID, requestIds[i])
+			recorders[i] = httptest.NewRecorder()
+
+			requests.Add(1)
+			httpServer.ServeHTTP(recorders[i], req)
+		}(i)
+	}
+
+	assert.Eventually(t, func() bool {
+		return requests.Load() == concurrency && calls.Load() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	coalesced := 0
+	for i, rec := range recorders {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, requestIds[i], rec.Header().Get(echo.HeaderXRequestID))
+
+		if rec.Header().Get(middleware.HeaderXCoalesced) != "true" {
+			continue
+		}
+
+		coalesced++
+
+		// content and configured headers shared
+		assert.Equal(t, echo.MIMEApplicationJSONCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "foo", rec.Header().Get("X-Foo"))
+
+		// other and per request headers not shared
+		assert.Empty(t, rec.Header().Get("X-Bar"))
+		assert.Empty(t, rec.Header().Get(middleware.HeaderXTraceId))
+		assert.Empty(t, rec.Header().Values(echo.HeaderSetCookie))
+	}
+
+	assert.Equal(t, concurrency-1, coalesced)
+}
+
+func TestRequestCoalescingMiddlewareWithError(t *testing.T) {
+	t.Parallel()
+
+	const concurrency = 5
+
+	var calls atomic.Int32
+	var keys atomic.Int32
+	release := make(chan struct{})
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestCoalescingMiddlewareWithConfig(middleware.RequestCoalescingMiddlewareConfig{
+		KeyFunc: func(c echo.Context) string {
+			keys.Add(1)
+
+			return middleware.DefaultRequestCoalescingKeyFunc(c)
+		},
+	}))
+	httpServer.GET("/failing", func(c echo.Context) error {
+		// only the first execution fails
+		if calls.Add(1) == 1 {
+			<-release
+
+			return echo.NewHTTPError(http.StatusBadGateway, "backend failure")
+		}
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	recorders := make([]*httptest.ResponseRecorder, concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			recorders[i] = httptest.NewRecorder()
+			httpServer.ServeHTTP(recorders[i], httptest.NewRequest(http.MethodGet, "/failing", nil))
+		}(i)
+	}
+
+	assert.Eventually(t, func() bool {
+		return keys.Load() == concurrency && calls.Load() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	// the error is not shared: the waiting requests ran the handler on their own
+	assert.Equal(t, int32(concurrency), calls.Load())
+
+	failures := 0
+	for _, rec := range recorders {
+		if rec.Code == http.StatusBadGateway {
+			failures++
+		} else {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "ok", rec.Body.String())
+		}
+	}
+
+	assert.Equal(t, 1, failures)
+}
+
+func TestRequestCoalescingMiddlewareWithCancelledWaitingRequest(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	coalescing := middleware.RequestCoalescingMiddleware()
+
+	handler := coalescing(func(c echo.Context) error {
+		calls.Add(1)
+
+		<-release
+
+		return c.String(http.StatusOK, "slow result")
+	})
+
+	httpServer := echo.New()
+
+	// leader request
+	leaderRec := httptest.NewRecorder()
+	leaderDone := make(chan struct{})
+
+	go func() {
+		defer close(leaderDone)
+
+		err := handler(httpServer.NewContext(httptest.NewRequest(http.MethodGet, "/slow", nil), leaderRec))
+		assert.NoError(t, err)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	// waiting request, cancelled while waiting
+	ctx, cancel := context.WithCancel(context.Background())
+
+	waitingDone := make(chan error)
+
+	go func() {
+		req := httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx)
+
+		waitingDone <- handler(httpServer.NewContext(req, httptest.NewRecorder()))
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-waitingDone:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("waiting request should have stopped waiting")
+	}
+
+	// the leader is not impacted
+	close(release)
+	<-leaderDone
+
+	assert.Equal(t, http.StatusOK, leaderRec.Code)
+	assert.Equal(t, "slow result", leaderRec.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRequestCoalescingMiddlewareWithPanic(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.Use(echomiddleware.Recover())
+	httpServer.Use(middleware.RequestCoalescingMiddleware())
+	httpServer.GET("/panic", func(c echo.Context) error {
+		panic("handler panic")
+	})
+
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestRequestCoalescingMiddlewareWithNotCoalescedRequests(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestCoalescingMiddleware())
+	httpServer.POST("/resource", func(c echo.Context) error {
+		calls.Add(1)
+
+		return c.NoContent(http.StatusCreated)
+	})
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/resource", nil))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(middleware.HeaderXCoalesced))
+	}
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestDefaultRequestCoalescingKeyFunc(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+
+	key := func(method string, headers map[string]string) string {
+		req := httptest.NewRequest(method, "/resource?b=2&a=1", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		return middleware.DefaultRequestCoalescingKeyFunc(httpServer.NewContext(req, httptest.NewRecorder()))
+	}
+
+	assert.Empty(t, key(http.MethodPost, nil))
+	assert.Equal(t, key(http.MethodGet, nil), key(http.MethodGet, nil))
+
+	alice := key(http.MethodGet, map[string]string{echo.HeaderAuthorization: "Bearer alice"})
+	bob := key(http.MethodGet, map[string]string{echo.HeaderAuthorization: "Bearer bob"})
+
+	assert.Equal(t, alice, key(http.MethodGet, map[string]string{echo.HeaderAuthorization: "Bearer alice"}))
+	assert.NotEqual(t, alice, bob)
+	assert.NotEqual(t, key(http.MethodGet, nil), alice)
+
+	assert.NotEqual(
+		t,
+		key(http.MethodGet, map[string]string{echo.HeaderCookie: "session=alice"}),
+		key(http.MethodGet, map[string]string{echo.HeaderCookie: "session=bob"}),
+	)
+}
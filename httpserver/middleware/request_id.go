@@ -2,25 +2,36 @@ package middleware
 
 import (
 	"context"
+	"regexp"
 
 	"github.com/ankorstore/yokai/generate/uuid"
 	"github.com/ankorstore/yokai/httpserver"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel/baggage"
 )
 
+// RequestIdMaxLength is the maximum accepted length for an incoming request id.
+const RequestIdMaxLength = 128
+
+var requestIdRegexp = regexp.MustCompile(`^[a-zA-Z0-9\-_.:]+$`)
+
 // RequestIdMiddlewareConfig is the configuration for the [RequestIdMiddleware].
 type RequestIdMiddlewareConfig struct {
-	Skipper         middleware.Skipper
-	Generator       uuid.UuidGenerator
-	RequestIdHeader string
+	Skipper            middleware.Skipper
+	Generator          uuid.UuidGenerator
+	RequestIdHeader    string
+	RequestIdValidator func(string) bool
+	IgnoreIncoming     bool
 }
 
 // DefaultRequestIdMiddlewareConfig is the default configuration for the [RequestIdMiddleware].
 var DefaultRequestIdMiddlewareConfig = RequestIdMiddlewareConfig{
-	Skipper:         middleware.DefaultSkipper,
-	Generator:       uuid.NewDefaultUuidGenerator(),
-	RequestIdHeader: echo.HeaderXRequestID,
+	Skipper:            middleware.DefaultSkipper,
+	Generator:          uuid.NewDefaultUuidGenerator(),
+	RequestIdHeader:    echo.HeaderXRequestID,
+	RequestIdValidator: IsValidRequestId,
+	IgnoreIncoming:     false,
 }
 
 // RequestIdMiddleware returns a [RequestIdMiddleware] with the [DefaultRequestIdMiddlewareConfig].
@@ -42,6 +53,10 @@ func RequestIdMiddlewareWithConfig(config RequestIdMiddlewareConfig) echo.Middle
 		config.RequestIdHeader = echo.HeaderXRequestID
 	}
 
+	if config.RequestIdValidator == nil {
+		config.RequestIdValidator = DefaultRequestIdMiddlewareConfig.RequestIdValidator
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if config.Skipper(c) {
@@ -54,7 +69,7 @@ func RequestIdMiddlewareWithConfig(config RequestIdMiddlewareConfig) echo.Middle
 			// request_id req / resp header propagation
 			rid := req.Header.Get(config.RequestIdHeader)
 
-			if rid == "" {
+			if rid == "" || config.IgnoreIncoming || !config.RequestIdValidator(rid) {
 				rid = config.Generator.Generate()
 				req.Header.Set(config.RequestIdHeader, rid)
 			}
@@ -62,9 +77,23 @@ func RequestIdMiddlewareWithConfig(config RequestIdMiddlewareConfig) echo.Middle
 			resp.Header().Set(config.RequestIdHeader, rid)
 
 			// request_id ctx propagation
-			c.SetRequest(req.WithContext(context.WithValue(req.Context(), httpserver.CtxRequestIdKey{}, rid)))
+			ctx := context.WithValue(req.Context(), httpserver.CtxRequestIdKey{}, rid)
+
+			// request_id baggage propagation
+			if member, err := baggage.NewMember(httpserver.BaggageRequestIdKey, rid); err == nil {
+				if bag, err := baggage.FromContext(ctx).SetMember(member); err == nil {
+					ctx = baggage.ContextWithBaggage(ctx, bag)
+				}
+			}
+
+			c.SetRequest(req.WithContext(ctx))
 
 			return next(c)
 		}
 	}
 }
+
+// IsValidRequestId returns true if a provided request id has a sane length and charset.
+func IsValidRequestId(rid string) bool {
+	return len(rid) <= RequestIdMaxLength && requestIdRegexp.MatchString(rid)
+}
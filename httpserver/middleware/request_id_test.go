@@ -3,12 +3,15 @@ package middleware_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/ankorstore/yokai/generate/generatetest/uuid"
+	"github.com/ankorstore/yokai/httpserver"
 	"github.com/ankorstore/yokai/httpserver/middleware"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
 )
 
 func TestRequestIdMiddlewareWithDefaults(t *testing.T) {
@@ -143,3 +146,110 @@ func TestRequestIdMiddlewareWithCustomIdHeader(t *testing.T) {
 	assert.Equal(t, "custom-id", rec.Body.String())
 	assert.Equal(t, "custom-id", rec.Header().Get("custom-header"))
 }
+
+func TestRequestIdMiddlewareWithIgnoredIncomingId(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add(echo.HeaderXRequestID, "test-id")
+	rec := httptest.NewRecorder()
+
+	ctx := httpServer.NewContext(req, rec)
+	handler := func(c echo.Context) error {
+		return c.String(
+			http.StatusOK,
+			c.Request().Header.Get(echo.HeaderXRequestID),
+		)
+	}
+
+	m := middleware.RequestIdMiddlewareWithConfig(middleware.RequestIdMiddlewareConfig{
+		Generator:      uuid.NewTestUuidGenerator("generated-id"),
+		IgnoreIncoming: true,
+	})
+	h := m(handler)
+
+	err := h(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "generated-id", rec.Body.String())
+	assert.Equal(t, "generated-id", rec.Header().Get(echo.HeaderXRequestID))
+}
+
+func TestRequestIdMiddlewareWithInvalidIncomingId(t *testing.T) {
+	t.Parallel()
+
+	invalidIds := []string{
+		"invalid id",
+		"invalid<id>",
+		strings.Repeat("a", middleware.RequestIdMaxLength+1),
+	}
+
+	for _, invalidId := range invalidIds {
+		httpServer := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Add(echo.HeaderXRequestID, invalidId)
+		rec := httptest.NewRecorder()
+
+		ctx := httpServer.NewContext(req, rec)
+		handler := func(c echo.Context) error {
+			return c.String(
+				http.StatusOK,
+				c.Request().Header.Get(echo.HeaderXRequestID),
+			)
+		}
+
+		m := middleware.RequestIdMiddlewareWithConfig(middleware.RequestIdMiddlewareConfig{
+			Generator: uuid.NewTestUuidGenerator("generated-id"),
+		})
+		h := m(handler)
+
+		err := h(ctx)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "generated-id", rec.Body.String())
+		assert.Equal(t, "generated-id", rec.Header().Get(echo.HeaderXRequestID))
+	}
+}
+
+func TestRequestIdMiddlewareContextPropagation(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add(echo.HeaderXRequestID, "test-id")
+	rec := httptest.NewRecorder()
+
+	ctx := httpServer.NewContext(req, rec)
+	handler := func(c echo.Context) error {
+		assert.Equal(t, "test-id", httpserver.CtxRequestId(c))
+		assert.Equal(
+			t,
+			"test-id",
+			baggage.FromContext(c.Request().Context()).Member(httpserver.BaggageRequestIdKey).Value(),
+		)
+
+		return c.NoContent(http.StatusOK)
+	}
+
+	m := middleware.RequestIdMiddleware()
+	h := m(handler)
+
+	err := h(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "test-id", rec.Header().Get(echo.HeaderXRequestID))
+}
+
+func TestIsValidRequestId(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, middleware.IsValidRequestId("33084b3e-9b90-926c-af19-3859d70bd296"))
+	assert.True(t, middleware.IsValidRequestId("some_id.with:separators"))
+	assert.False(t, middleware.IsValidRequestId(""))
+	assert.False(t, middleware.IsValidRequestId("invalid id"))
+	assert.False(t, middleware.IsValidRequestId(strings.Repeat("a", middleware.RequestIdMaxLength+1)))
+}
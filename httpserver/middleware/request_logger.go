@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"math/rand"
 	"net/http"
 	"time"
 
@@ -18,6 +21,11 @@ const (
 	HeaderXRequestId  = "x-request-id"
 	HeaderTraceParent = "traceparent"
 	LogFieldRequestId = "requestID"
+	LogFieldRoute     = "route"
+	LogFieldHandler   = "handler"
+	LogFieldSSE       = "sse"
+	LogFieldWebSocket = "websocket"
+	UnmatchedRoute    = "unmatched"
 )
 
 // RequestLoggerMiddlewareConfig is the configuration for the [RequestLoggerMiddleware].
@@ -26,6 +34,14 @@ type RequestLoggerMiddlewareConfig struct {
 	LogLevelFromResponseOrErrorCode bool
 	RequestHeadersToLog             map[string]string
 	RequestUriPrefixesToExclude     []string
+	LogRequestBody                  bool
+	LogResponseBody                 bool
+	RouteBodyLoggingOverrides       map[string]bool
+	LogRoute                        bool
+	LogHandler                      bool
+	SuccessSampler                  func() bool
+	Sampler                         zerolog.Sampler
+	Disabled                        bool
 }
 
 // DefaultRequestLoggerMiddlewareConfig is the default configuration for the [RequestLoggerMiddleware].
@@ -34,6 +50,14 @@ var DefaultRequestLoggerMiddlewareConfig = RequestLoggerMiddlewareConfig{
 	LogLevelFromResponseOrErrorCode: false,
 	RequestHeadersToLog:             map[string]string{HeaderXRequestId: LogFieldRequestId},
 	RequestUriPrefixesToExclude:     []string{},
+	LogRequestBody:                  false,
+	LogResponseBody:                 false,
+	RouteBodyLoggingOverrides:       map[string]bool{},
+	LogRoute:                        false,
+	LogHandler:                      false,
+	SuccessSampler:                  nil,
+	Sampler:                         nil,
+	Disabled:                        false,
 }
 
 // RequestLoggerMiddleware returns a [RequestLoggerMiddleware] with the [DefaultRequestLoggerMiddlewareConfig].
@@ -43,7 +67,24 @@ func RequestLoggerMiddleware() echo.MiddlewareFunc {
 
 // RequestLoggerMiddlewareWithConfig returns a [RequestLoggerMiddleware] for a provided [RequestLoggerMiddlewareConfig].
 //
-//nolint:gocognit,nestif
+// The RouteBodyLoggingOverrides config allows to force the request and response bodies logging (true) or to prevent it (false)
+// for specific routes, whatever the LogRequestBody and LogResponseBody configs. Its keys are built with [httpserver.RouteKey].
+//
+// The LogRoute and LogHandler configs allow to log the matched route path template and handler name (see
+// [httpserver.RouteName]), or unmatched if no route matched the request.
+//
+// The SuccessSampler config allows to log only a fraction of the successful requests (see [RatioSampler]): it is
+// consulted for each request without error and with a status code lower than 400, and the request is not logged if it
+// returns false (errors are always logged). If nil, all requests are logged.
+//
+// The Sampler config allows to sample the requests logs with a different sampler than the one of the logger (for
+// example to sample the high-volume access logs more than the application logs): it replaces the logger sampler for
+// the requests logs only. If nil, the requests logs are sampled as the other logs of the logger.
+//
+// The Disabled config allows to not log the requests at all, the request id and the correlated logger being still
+// propagated in the request context.
+//
+//nolint:gocognit,gocyclo,nestif
 func RequestLoggerMiddlewareWithConfig(config RequestLoggerMiddlewareConfig) echo.MiddlewareFunc {
 	if config.Skipper == nil {
 		config.Skipper = DefaultRequestIdMiddlewareConfig.Skipper
@@ -57,6 +98,10 @@ func RequestLoggerMiddlewareWithConfig(config RequestLoggerMiddlewareConfig) ech
 		config.RequestUriPrefixesToExclude = DefaultRequestLoggerMiddlewareConfig.RequestUriPrefixesToExclude
 	}
 
+	if config.RouteBodyLoggingOverrides == nil {
+		config.RouteBodyLoggingOverrides = DefaultRequestLoggerMiddlewareConfig.RouteBodyLoggingOverrides
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// skipper
@@ -99,6 +144,34 @@ func RequestLoggerMiddlewareWithConfig(config RequestLoggerMiddlewareConfig) ech
 			c.SetRequest(c.Request().WithContext(logger.WithContext(ctx)))
 			c.SetLogger(httpserver.NewEchoLogger(log.FromZerolog(logger)))
 
+			// bodies capture
+			logRequestBody := config.LogRequestBody
+			logResponseBody := config.LogResponseBody
+
+			if override, ok := config.RouteBodyLoggingOverrides[httpserver.RouteKey(req.Method, c.Path())]; ok {
+				logRequestBody = override
+				logResponseBody = override
+			}
+
+			// server-sent events streams and websocket connections are never buffered
+			sse := httpserver.IsSSERequest(c)
+			websocket := httpserver.IsWebSocketRequest(c)
+			if sse || websocket {
+				logRequestBody = false
+				logResponseBody = false
+			}
+
+			var reqBody []byte
+			if logRequestBody && c.Request().Body != nil {
+				reqBody, _ = io.ReadAll(c.Request().Body)
+				c.Request().Body = io.NopCloser(bytes.NewReader(reqBody))
+			}
+
+			resBody := new(bytes.Buffer)
+			if logResponseBody {
+				res.Writer = newBodyDumpResponseWriter(res.Writer, resBody)
+			}
+
 			// invoke next in chain
 			start := time.Now()
 			err := next(c)
@@ -109,6 +182,11 @@ func RequestLoggerMiddlewareWithConfig(config RequestLoggerMiddlewareConfig) ech
 				c.Error(err)
 			}
 
+			// skip if disabled, with error propagation
+			if config.Disabled {
+				return err
+			}
+
 			// response status
 			status := res.Status
 			if err != nil {
@@ -127,6 +205,19 @@ func RequestLoggerMiddlewareWithConfig(config RequestLoggerMiddlewareConfig) ech
 				return nil
 			}
 
+			// skip if successful and not sampled
+			if config.SuccessSampler != nil &&
+				err == nil &&
+				status < http.StatusBadRequest &&
+				!config.SuccessSampler() {
+				return nil
+			}
+
+			// log event sampling override
+			if config.Sampler != nil {
+				logger = logger.Sample(config.Sampler)
+			}
+
 			// log event preparation
 			var evt *zerolog.Event
 			if config.LogLevelFromResponseOrErrorCode {
@@ -164,20 +255,55 @@ func RequestLoggerMiddlewareWithConfig(config RequestLoggerMiddlewareConfig) ech
 				}
 			}
 
-			// log event tracing
+			// log event tracing, only for valid and sampled spans
 			spanContext := trace.SpanContextFromContext(c.Request().Context())
 
-			if spanContext.HasTraceID() {
+			if spanContext.IsValid() && spanContext.IsSampled() {
 				evt.Str("traceID", spanContext.TraceID().String())
+				evt.Str("spanID", spanContext.SpanID().String())
 			}
 
-			if spanContext.HasSpanID() {
-				evt.Str("spanID", spanContext.SpanID().String())
+			// log event route and handler
+			if config.LogRoute || config.LogHandler {
+				route := c.Path()
+				handler := httpserver.RouteName(c)
+
+				if handler == "" {
+					route = UnmatchedRoute
+					handler = UnmatchedRoute
+				}
+
+				if config.LogRoute {
+					evt.Str(LogFieldRoute, route)
+				}
+
+				if config.LogHandler {
+					evt.Str(LogFieldHandler, handler)
+				}
+			}
+
+			// log event server-sent events stream or websocket connection, the latency being their duration
+			if sse {
+				evt.Bool(LogFieldSSE, true)
+			}
+
+			if websocket {
+				evt.Bool(LogFieldWebSocket, true)
+			}
+
+			// log event bodies
+			if logRequestBody {
+				evt.Str("requestBody", string(reqBody))
+			}
+
+			if logResponseBody {
+				evt.Str("responseBody", resBody.String())
 			}
 
 			// log event propagation
 			evt.
 				Str("method", req.Method).
+				Str("scheme", c.Scheme()).
 				Str("uri", req.RequestURI).
 				Int("status", status).
 				Str("latency", latency.String()).
@@ -191,3 +317,19 @@ func RequestLoggerMiddlewareWithConfig(config RequestLoggerMiddlewareConfig) ech
 		}
 	}
 }
+
+// RatioSampler returns a sampler for the [RequestLoggerMiddlewareConfig] SuccessSampler, sampling a given ratio
+// (from 0.0 to 1.0) of the requests.
+func RatioSampler(ratio float64) func() bool {
+	return func() bool {
+		switch {
+		case ratio <= 0:
+			return false
+		case ratio >= 1:
+			return true
+		default:
+			//nolint:gosec
+			return rand.Float64() < ratio
+		}
+	}
+}
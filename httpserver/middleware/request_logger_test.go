@@ -3,9 +3,12 @@ package middleware_test
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/httpserver"
 	"github.com/ankorstore/yokai/httpserver/middleware"
@@ -13,7 +16,9 @@ import (
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/labstack/echo/v4"
 	gommonlog "github.com/labstack/gommon/log"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestRequestLoggerMiddlewareWithDefaults(t *testing.T) {
@@ -63,6 +68,7 @@ func TestRequestLoggerMiddlewareWithDefaults(t *testing.T) {
 	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
 		"level":     "info",
 		"method":    "GET",
+		"scheme":    "http",
 		"uri":       "/test",
 		"status":    200,
 		"message":   "request logger",
@@ -724,3 +730,565 @@ func TestRequestLoggerMiddlewareWithoutContextLogger(t *testing.T) {
 	err := h(ctx)
 	assert.NoError(t, err)
 }
+
+func TestRequestLoggerMiddlewareWithBodies(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString("request body"))
+	rec := httptest.NewRecorder()
+
+	ctx := httpServer.NewContext(req, rec)
+	ctx.SetPath("/test")
+	handler := func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "request body", string(body))
+
+		return c.String(http.StatusOK, "response body")
+	}
+
+	m := middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		LogRequestBody:  true,
+		LogResponseBody: true,
+	})
+	h := m(handler)
+
+	err = h(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "response body", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":        "info",
+		"method":       "POST",
+		"uri":          "/test",
+		"status":       200,
+		"requestBody":  "request body",
+		"responseBody": "response body",
+		"message":      "request logger",
+	})
+}
+
+func TestRequestLoggerMiddlewareWithRouteBodyLoggingForceEnabled(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/test/123", bytes.NewBufferString("request body"))
+	rec := httptest.NewRecorder()
+
+	ctx := httpServer.NewContext(req, rec)
+	ctx.SetPath("/test/:id")
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "response body")
+	}
+
+	m := middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		LogRequestBody:  false,
+		LogResponseBody: false,
+		RouteBodyLoggingOverrides: map[string]bool{
+			httpserver.RouteKey(http.MethodPost, "/test/:id"): true,
+		},
+	})
+	h := m(handler)
+
+	err = h(ctx)
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":        "info",
+		"method":       "POST",
+		"uri":          "/test/123",
+		"status":       200,
+		"requestBody":  "request body",
+		"responseBody": "response body",
+		"message":      "request logger",
+	})
+}
+
+func TestRequestLoggerMiddlewareWithRouteBodyLoggingForceDisabled(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+
+	reqBody := bytes.NewBufferString("request body")
+
+	req := httptest.NewRequest(http.MethodPost, "/test", reqBody)
+	rec := httptest.NewRecorder()
+
+	ctx := httpServer.NewContext(req, rec)
+	ctx.SetPath("/test")
+	handler := func(c echo.Context) error {
+		// body not buffered by the middleware
+		assert.Equal(t, "request body", reqBody.String())
+
+		return c.String(http.StatusOK, "response body")
+	}
+
+	m := middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		LogRequestBody:  true,
+		LogResponseBody: true,
+		RouteBodyLoggingOverrides: map[string]bool{
+			httpserver.RouteKey(http.MethodPost, "/test"): false,
+		},
+	})
+	h := m(handler)
+
+	err = h(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, rec, ctx.Response().Writer)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"method":  "POST",
+		"uri":     "/test",
+		"status":  200,
+		"message": "request logger",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"requestBody": "request body",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"responseBody": "response body",
+	})
+}
+
+func TestRequestLoggerMiddlewareWithResponseBodyLoggingOnSSERequest(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+	req.Header.Set(echo.HeaderAccept, httpserver.MIMETextEventStream)
+	rec := httptest.NewRecorder()
+
+	ctx := httpServer.NewContext(req, rec)
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "data: event\n\n")
+	}
+
+	m := middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		LogResponseBody: true,
+	})
+	h := m(handler)
+
+	err = h(ctx)
+	assert.NoError(t, err)
+
+	// response not buffered by the middleware
+	assert.Equal(t, rec, ctx.Response().Writer)
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"responseBody": "data: event\n\n",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"method":  "GET",
+		"uri":     "/sse",
+		"status":  200,
+		"sse":     true,
+		"message": "request logger",
+	})
+}
+
+func TestRequestLoggerMiddlewareWithSpanContext(t *testing.T) {
+	traceId, err := trace.TraceIDFromHex("c4ca71e03e42c2c3d54293a6e2608bfa")
+	assert.NoError(t, err)
+
+	spanId, err := trace.SpanIDFromHex("8d0fdc8a74baaaea")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		spanContext     trace.SpanContext
+		expectTraceInfo bool
+	}{
+		{
+			"sampled",
+			trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId, TraceFlags: trace.FlagsSampled}),
+			true,
+		},
+		{
+			"not sampled",
+			trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId}),
+			false,
+		},
+		{
+			"invalid",
+			trace.SpanContext{},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logBuffer := logtest.NewDefaultTestLogBuffer()
+			logger, err := log.NewDefaultLoggerFactory().Create(
+				log.WithOutputWriter(logBuffer),
+			)
+			assert.NoError(t, err)
+
+			httpServer := echo.New()
+			httpServer.Logger = httpserver.NewEchoLogger(logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req = req.WithContext(trace.ContextWithSpanContext(req.Context(), tt.spanContext))
+			rec := httptest.NewRecorder()
+
+			ctx := httpServer.NewContext(req, rec)
+			handler := func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			}
+
+			m := middleware.RequestLoggerMiddleware()
+			h := m(handler)
+
+			err = h(ctx)
+			assert.NoError(t, err)
+
+			expectedAttributes := map[string]interface{}{
+				"level":   "info",
+				"uri":     "/test",
+				"message": "request logger",
+				"traceID": "c4ca71e03e42c2c3d54293a6e2608bfa",
+				"spanID":  "8d0fdc8a74baaaea",
+			}
+
+			if tt.expectTraceInfo {
+				logtest.AssertHasLogRecord(t, logBuffer, expectedAttributes)
+			} else {
+				logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+					"message": "request logger",
+					"traceID": "c4ca71e03e42c2c3d54293a6e2608bfa",
+				})
+				logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+					"message": "request logger",
+					"spanID":  "8d0fdc8a74baaaea",
+				})
+			}
+		})
+	}
+}
+
+func testRouteHandler(c echo.Context) error {
+	return c.String(http.StatusOK, "ok")
+}
+
+func TestRequestLoggerMiddlewareWithRouteAndHandler(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.Use(middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		LogRoute:   true,
+		LogHandler: true,
+	}))
+
+	httpServer.GET("/users/:id", testRouteHandler)
+	httpServer.GET("/named/:id", testRouteHandler).Name = "named.show"
+
+	for _, target := range []string{"/users/1", "/named/2", "/unknown"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+	}
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"uri":     "/users/1",
+		"status":  200,
+		"route":   "/users/:id",
+		"handler": "github.com/ankorstore/yokai/httpserver/middleware_test.testRouteHandler",
+		"message": "request logger",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"uri":     "/named/2",
+		"status":  200,
+		"route":   "/named/:id",
+		"handler": "named.show",
+		"message": "request logger",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"uri":     "/unknown",
+		"status":  404,
+		"route":   "unmatched",
+		"handler": "unmatched",
+		"message": "request logger",
+	})
+}
+
+func TestRequestLoggerMiddlewareWithoutRouteAndHandler(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.Use(middleware.RequestLoggerMiddleware())
+
+	httpServer.GET("/users/:id", testRouteHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"uri":     "/users/1",
+		"status":  200,
+		"message": "request logger",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"route":   "/users/:id",
+		"message": "request logger",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"handler": "github.com/ankorstore/yokai/httpserver/middleware_test.testRouteHandler",
+		"message": "request logger",
+	})
+}
+
+func TestRequestLoggerMiddlewareWithSampler(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.Use(middleware.RequestIdMiddleware())
+	httpServer.Use(middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		Sampler: &zerolog.BurstSampler{Burst: 10, Period: time.Hour},
+	}))
+
+	httpServer.GET("/burst", func(c echo.Context) error {
+		httpserver.CtxLogger(c).Info().Msg("handler message")
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	for i := 0; i < 100; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/burst", nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+
+	requestLogs := 0
+	handlerLogs := 0
+
+	for _, record := range records {
+		message, err := record.Message()
+		assert.NoError(t, err)
+
+		switch message {
+		case "request logger":
+			requestLogs++
+		case "handler message":
+			handlerLogs++
+		}
+	}
+
+	// only the requests logs are sampled
+	assert.Equal(t, 10, requestLogs)
+	assert.Equal(t, 100, handlerLogs)
+}
+
+func TestRequestLoggerMiddlewareWithSuccessSampler(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	var requestIds []string
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.Use(middleware.RequestIdMiddleware())
+	httpServer.Use(middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		SuccessSampler: middleware.RatioSampler(0),
+	}))
+
+	httpServer.GET("/success", func(c echo.Context) error {
+		requestIds = append(requestIds, httpserver.CtxRequestId(c))
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	httpServer.GET("/redirect", func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, "/success")
+	})
+
+	httpServer.GET("/client-error", func(c echo.Context) error {
+		return c.String(http.StatusBadRequest, "bad request")
+	})
+
+	httpServer.GET("/server-error", func(c echo.Context) error {
+		return fmt.Errorf("server error")
+	})
+
+	for _, target := range []string{"/success", "/redirect", "/client-error", "/server-error"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Add(middleware.HeaderXRequestId, "request-id"+strings.ReplaceAll(target, "/", "-"))
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		// the request id is always propagated
+		assert.Equal(t, "request-id"+strings.ReplaceAll(target, "/", "-"), rec.Header().Get(middleware.HeaderXRequestId))
+	}
+
+	// unlogged successful requests still get a request id for correlation
+	assert.Equal(t, []string{"request-id-success"}, requestIds)
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"uri":     "/success",
+		"message": "request logger",
+	})
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"uri":     "/redirect",
+		"message": "request logger",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"uri":       "/client-error",
+		"status":    400,
+		"requestID": "request-id-client-error",
+		"message":   "request logger",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"uri":       "/server-error",
+		"status":    500,
+		"requestID": "request-id-server-error",
+		"message":   "request logger",
+	})
+}
+
+func TestRequestLoggerMiddlewareDisabled(t *testing.T) {
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	var propagatedErr error
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			propagatedErr = next(c)
+
+			return propagatedErr
+		}
+	})
+	httpServer.Use(middleware.RequestIdMiddleware())
+	httpServer.Use(middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		Disabled: true,
+	}))
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		httpserver.CtxLogger(c).Info().Msg("test-zero-logger")
+
+		return c.String(http.StatusOK, httpserver.CtxRequestId(c))
+	})
+
+	httpServer.GET("/error", func(c echo.Context) error {
+		return fmt.Errorf("server error")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Add(middleware.HeaderXRequestId, "test-request-id")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	// the request id and correlated logger are still propagated
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "test-request-id", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"message":   "test-zero-logger",
+		"requestID": "test-request-id",
+	})
+
+	req = httptest.NewRequest(http.MethodGet, "/error", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	// the error is still propagated
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.EqualError(t, propagatedErr, "server error")
+
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"message": "request logger",
+	})
+}
+
+func TestRatioSampler(t *testing.T) {
+	t.Parallel()
+
+	countSampled := func(sampler func() bool) int {
+		sampled := 0
+		for i := 0; i < 10000; i++ {
+			if sampler() {
+				sampled++
+			}
+		}
+
+		return sampled
+	}
+
+	assert.Equal(t, 0, countSampled(middleware.RatioSampler(0)))
+	assert.Equal(t, 0, countSampled(middleware.RatioSampler(-1)))
+	assert.Equal(t, 10000, countSampled(middleware.RatioSampler(1)))
+	assert.Equal(t, 10000, countSampled(middleware.RatioSampler(2)))
+	assert.InDelta(t, 2500, countSampled(middleware.RatioSampler(0.25)), 500)
+}
@@ -1,9 +1,13 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"io"
 	"reflect"
 	"strconv"
 
+	"github.com/ankorstore/yokai/httpserver"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,17 +16,31 @@ import (
 const (
 	HttpServerMetricsRequestsCount    = "requests_total"
 	HttpServerMetricsRequestsDuration = "request_duration_seconds"
+	HttpServerMetricsRequestsAborted  = "requests_aborted_total"
+	HttpServerMetricsRequestsSize     = "request_size_bytes"
+	HttpServerMetricsResponsesSize    = "response_size_bytes"
+	HttpServerMetricsAbortCanceled    = "canceled"
+	HttpServerMetricsAbortTimeout     = "deadline_exceeded"
 	HttpServerMetricsNotFoundPath     = "/not-found"
+	HttpServerMetricsTypeHistogram    = "histogram"
+	HttpServerMetricsTypeSummary      = "summary"
 )
 
+// DefaultSizeBuckets are the default buckets of the requests and responses sizes histograms, from 100 bytes to 10
+// megabytes.
+var DefaultSizeBuckets = prometheus.ExponentialBuckets(100, 10, 6)
+
 // RequestMetricsMiddlewareConfig is the configuration for the [RequestMetricsMiddleware].
 type RequestMetricsMiddlewareConfig struct {
 	Skipper             middleware.Skipper
 	Registry            prometheus.Registerer
 	Namespace           string
 	Buckets             []float64
+	SizeBuckets         []float64
 	Subsystem           string
 	NormalizeHTTPStatus bool
+	Type                string
+	Objectives          map[float64]float64
 }
 
 // DefaultRequestMetricsMiddlewareConfig is the default configuration for the [RequestMetricsMiddleware].
@@ -32,7 +50,10 @@ var DefaultRequestMetricsMiddlewareConfig = RequestMetricsMiddlewareConfig{
 	Namespace:           "",
 	Subsystem:           "",
 	Buckets:             prometheus.DefBuckets,
+	SizeBuckets:         DefaultSizeBuckets,
 	NormalizeHTTPStatus: true,
+	Type:                HttpServerMetricsTypeHistogram,
+	Objectives:          map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 }
 
 // RequestMetricsMiddleware returns a [RequestMetricsMiddleware] with the [DefaultRequestMetricsMiddlewareConfig].
@@ -41,6 +62,22 @@ func RequestMetricsMiddleware() echo.MiddlewareFunc {
 }
 
 // RequestMetricsMiddlewareWithConfig returns a [RequestMetricsMiddleware] for a provided [RequestMetricsMiddlewareConfig].
+//
+// The requests durations are observed in a histogram (with the Buckets) by default, or in a summary (with the
+// Objectives quantiles and their allowed errors) if the Type is [HttpServerMetricsTypeSummary]. The histograms can be
+// aggregated across instances (with histogram_quantile), while the summaries quantiles are computed per instance and
+// cannot be aggregated, but are cheaper to query and precise for a single instance.
+//
+// The requests of the routes marked as streaming with [httpserver.MarkStreamingRoutes] (like the server-sent events and
+// websocket ones) are counted, but their durations and sizes (the whole stream or connection lifetime) are not
+// observed, to not distort the requests durations and sizes.
+//
+// The requests and responses bodies sizes are observed in histograms (with the SizeBuckets, separate from the durations
+// ones): the request Content-Length, or the bytes read by the handler when unknown (like for chunked requests), and the
+// bytes written in the response.
+//
+// The requests whose context ended before the handler returned are also counted by reason: canceled (client
+// disconnect) or deadline_exceeded (server timeout, like with the [RequestTimeoutMiddleware]).
 func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) echo.MiddlewareFunc {
 	if config.Skipper == nil {
 		config.Skipper = DefaultRequestMetricsMiddlewareConfig.Skipper
@@ -62,6 +99,18 @@ func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) e
 		config.Buckets = DefaultRequestMetricsMiddlewareConfig.Buckets
 	}
 
+	if len(config.SizeBuckets) == 0 {
+		config.SizeBuckets = DefaultRequestMetricsMiddlewareConfig.SizeBuckets
+	}
+
+	if config.Type == "" {
+		config.Type = DefaultRequestMetricsMiddlewareConfig.Type
+	}
+
+	if len(config.Objectives) == 0 {
+		config.Objectives = DefaultRequestMetricsMiddlewareConfig.Objectives
+	}
+
 	httpRequestsCounter := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: config.Namespace,
@@ -76,13 +125,78 @@ func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) e
 		},
 	)
 
-	httpRequestsDuration := prometheus.NewHistogramVec(
+	var httpRequestsDuration prometheus.ObserverVec
+	var httpRequestsDurationCollector prometheus.Collector
+
+	if config.Type == HttpServerMetricsTypeSummary {
+		summaryVec := prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace:  config.Namespace,
+				Subsystem:  config.Subsystem,
+				Name:       HttpServerMetricsRequestsDuration,
+				Help:       "Time spent processing HTTP requests",
+				Objectives: config.Objectives,
+			},
+			[]string{
+				"method",
+				"handler",
+			},
+		)
+
+		httpRequestsDuration, httpRequestsDurationCollector = summaryVec, summaryVec
+	} else {
+		histogramVec := prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: config.Namespace,
+				Subsystem: config.Subsystem,
+				Name:      HttpServerMetricsRequestsDuration,
+				Help:      "Time spent processing HTTP requests",
+				Buckets:   config.Buckets,
+			},
+			[]string{
+				"method",
+				"handler",
+			},
+		)
+
+		httpRequestsDuration, httpRequestsDurationCollector = histogramVec, histogramVec
+	}
+
+	httpRequestsAbortedCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      HttpServerMetricsRequestsAborted,
+			Help:      "Number of HTTP requests aborted by a client cancellation or a server timeout",
+		},
+		[]string{
+			"reason",
+			"method",
+			"handler",
+		},
+	)
+
+	httpRequestsSize := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      HttpServerMetricsRequestsSize,
+			Help:      "Size in bytes of the HTTP requests bodies",
+			Buckets:   config.SizeBuckets,
+		},
+		[]string{
+			"method",
+			"handler",
+		},
+	)
+
+	httpResponsesSize := prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: config.Namespace,
 			Subsystem: config.Subsystem,
-			Name:      HttpServerMetricsRequestsDuration,
-			Help:      "Time spent processing HTTP requests",
-			Buckets:   config.Buckets,
+			Name:      HttpServerMetricsResponsesSize,
+			Help:      "Size in bytes of the HTTP responses bodies",
+			Buckets:   config.SizeBuckets,
 		},
 		[]string{
 			"method",
@@ -90,7 +204,13 @@ func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) e
 		},
 	)
 
-	config.Registry.MustRegister(httpRequestsCounter, httpRequestsDuration)
+	config.Registry.MustRegister(
+		httpRequestsCounter,
+		httpRequestsDurationCollector,
+		httpRequestsAbortedCounter,
+		httpRequestsSize,
+		httpResponsesSize,
+	)
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -102,19 +222,46 @@ func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) e
 			req := c.Request()
 			path := c.Path()
 
+			// the incoming request context, canceled on client disconnect
+			reqCtx := req.Context()
+
 			// to avoid high cardinality
 			if isNotFoundHandler(c.Handler()) {
 				path = HttpServerMetricsNotFoundPath
 			}
 
-			timer := prometheus.NewTimer(httpRequestsDuration.WithLabelValues(req.Method, path))
-			err := next(c)
-			timer.ObserveDuration()
+			streaming := httpserver.IsStreamingRequest(c)
+
+			// the bytes read are counted for the requests of unknown length, like the chunked ones
+			var requestBody *countingReadCloser
+			if !streaming && req.ContentLength < 0 && req.Body != nil {
+				requestBody = &countingReadCloser{ReadCloser: req.Body}
+				req.Body = requestBody
+			}
+
+			var err error
+			if streaming {
+				err = next(c)
+			} else {
+				timer := prometheus.NewTimer(httpRequestsDuration.WithLabelValues(req.Method, path))
+				err = next(c)
+				timer.ObserveDuration()
+			}
 
 			if err != nil {
 				c.Error(err)
 			}
 
+			if !streaming {
+				requestSize := req.ContentLength
+				if requestBody != nil {
+					requestSize = requestBody.count
+				}
+
+				httpRequestsSize.WithLabelValues(req.Method, path).Observe(float64(requestSize))
+				httpResponsesSize.WithLabelValues(req.Method, path).Observe(float64(c.Response().Size))
+			}
+
 			status := ""
 			if config.NormalizeHTTPStatus {
 				status = normalizeHTTPStatus(c.Response().Status)
@@ -124,11 +271,42 @@ func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) e
 
 			httpRequestsCounter.WithLabelValues(status, req.Method, path).Inc()
 
+			if reason := abortReason(reqCtx, c.Request().Context()); reason != "" {
+				httpRequestsAbortedCounter.WithLabelValues(reason, req.Method, path).Inc()
+			}
+
 			return err
 		}
 	}
 }
 
+// abortReason returns the reason why a request was aborted, or an empty string if it was not: the handler context
+// (possibly derived by the next middlewares, like with a timeout) is inspected for a deadline first, since the derived
+// contexts are canceled once their middleware returns, and the incoming request context for a client cancellation.
+func abortReason(reqCtx context.Context, handlerCtx context.Context) string {
+	switch {
+	case errors.Is(handlerCtx.Err(), context.DeadlineExceeded), errors.Is(reqCtx.Err(), context.DeadlineExceeded):
+		return HttpServerMetricsAbortTimeout
+	case errors.Is(reqCtx.Err(), context.Canceled):
+		return HttpServerMetricsAbortCanceled
+	default:
+		return ""
+	}
+}
+
+// countingReadCloser is an [io.ReadCloser] counting the bytes read from a request body.
+type countingReadCloser struct {
+	io.ReadCloser
+	count int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.count += int64(n)
+
+	return n, err
+}
+
 func normalizeHTTPStatus(status int) string {
 	switch {
 	case status < 200:
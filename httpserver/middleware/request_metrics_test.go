@@ -1,16 +1,20 @@
 package middleware_test
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ankorstore/yokai/httpserver"
 	"github.com/ankorstore/yokai/httpserver/middleware"
 	"github.com/labstack/echo/v4"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -144,3 +148,301 @@ func TestRequestMetricsMiddlewareWithCustomOptions(t *testing.T) {
 	)
 	assert.NoError(t, err)
 }
+
+func TestRequestMetricsMiddlewareWithStreamingRoute(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestMetricsMiddlewareWithConfig(middleware.RequestMetricsMiddlewareConfig{
+		Registry:  registry,
+		Namespace: "foo",
+		Subsystem: "bar",
+	}))
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	route := httpServer.GET("/stream", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	httpserver.MarkStreamingRoutes(httpServer, route)
+
+	// regular request
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	httpServer.ServeHTTP(httptest.NewRecorder(), req)
+
+	// regular request with spoofed server-sent events and websocket headers
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(echo.HeaderAccept, httpserver.MIMETextEventStream)
+	req.Header.Set(echo.HeaderConnection, "Upgrade")
+	req.Header.Set(echo.HeaderUpgrade, "websocket")
+	httpServer.ServeHTTP(httptest.NewRecorder(), req)
+
+	// streaming route request
+	req = httptest.NewRequest(http.MethodGet, "/stream", nil)
+	httpServer.ServeHTTP(httptest.NewRecorder(), req)
+
+	// all requests counted
+	expectedCounterMetric := `
+		# HELP foo_bar_requests_total Number of processed HTTP requests
+		# TYPE foo_bar_requests_total counter
+		foo_bar_requests_total{handler="/stream",method="GET",status="200"} 1
+		foo_bar_requests_total{handler="/test",method="GET",status="200"} 2
+	`
+
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedCounterMetric),
+		"foo_bar_requests_total",
+	)
+	assert.NoError(t, err)
+
+	// only the regular requests durations and sizes observed
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+
+	sampleCounts := map[string]uint64{}
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetType() == dto.MetricType_HISTOGRAM {
+			for _, metric := range metricFamily.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					assert.False(t, label.GetName() == "handler" && label.GetValue() == "/stream")
+				}
+
+				sampleCounts[metricFamily.GetName()] += metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+
+	assert.Equal(t, uint64(2), sampleCounts["foo_bar_request_duration_seconds"])
+	assert.Equal(t, uint64(2), sampleCounts["foo_bar_request_size_bytes"])
+	assert.Equal(t, uint64(2), sampleCounts["foo_bar_response_size_bytes"])
+}
+
+func TestRequestMetricsMiddlewareWithSizes(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestMetricsMiddlewareWithConfig(middleware.RequestMetricsMiddlewareConfig{
+		Registry:    registry,
+		Namespace:   "foo",
+		Subsystem:   "bar",
+		SizeBuckets: []float64{10, 100, 1000},
+	}))
+
+	httpServer.POST("/echo", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+
+		return c.Blob(http.StatusOK, echo.MIMEOctetStream, body)
+	})
+
+	httpServer.POST("/error", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid")
+	})
+
+	// request with Content-Length
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("hello")))
+	assert.Equal(t, "hello", rec.Body.String())
+
+	// chunked request, of unknown length
+	req := httptest.NewRequest(http.MethodPost, "/echo", io.NopCloser(strings.NewReader(strings.Repeat("a", 500))))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+	assert.Equal(t, 500, rec.Body.Len())
+
+	// error response, written after the handler
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/error", strings.NewReader(strings.Repeat("b", 50))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "{\"message\":\"invalid\"}\n", rec.Body.String())
+
+	expectedSizesMetrics := `
+		# HELP foo_bar_request_size_bytes Size in bytes of the HTTP requests bodies
+		# TYPE foo_bar_request_size_bytes histogram
+		foo_bar_request_size_bytes_bucket{handler="/echo",method="POST",le="10"} 1
+		foo_bar_request_size_bytes_bucket{handler="/echo",method="POST",le="100"} 1
+		foo_bar_request_size_bytes_bucket{handler="/echo",method="POST",le="1000"} 2
+		foo_bar_request_size_bytes_bucket{handler="/echo",method="POST",le="+Inf"} 2
+		foo_bar_request_size_bytes_sum{handler="/echo",method="POST"} 505
+		foo_bar_request_size_bytes_count{handler="/echo",method="POST"} 2
+		foo_bar_request_size_bytes_bucket{handler="/error",method="POST",le="10"} 0
+		foo_bar_request_size_bytes_bucket{handler="/error",method="POST",le="100"} 1
+		foo_bar_request_size_bytes_bucket{handler="/error",method="POST",le="1000"} 1
+		foo_bar_request_size_bytes_bucket{handler="/error",method="POST",le="+Inf"} 1
+		foo_bar_request_size_bytes_sum{handler="/error",method="POST"} 50
+		foo_bar_request_size_bytes_count{handler="/error",method="POST"} 1
+		# HELP foo_bar_response_size_bytes Size in bytes of the HTTP responses bodies
+		# TYPE foo_bar_response_size_bytes histogram
+		foo_bar_response_size_bytes_bucket{handler="/echo",method="POST",le="10"} 1
+		foo_bar_response_size_bytes_bucket{handler="/echo",method="POST",le="100"} 1
+		foo_bar_response_size_bytes_bucket{handler="/echo",method="POST",le="1000"} 2
+		foo_bar_response_size_bytes_bucket{handler="/echo",method="POST",le="+Inf"} 2
+		foo_bar_response_size_bytes_sum{handler="/echo",method="POST"} 505
+		foo_bar_response_size_bytes_count{handler="/echo",method="POST"} 2
+		foo_bar_response_size_bytes_bucket{handler="/error",method="POST",le="10"} 0
+		foo_bar_response_size_bytes_bucket{handler="/error",method="POST",le="100"} 1
+		foo_bar_response_size_bytes_bucket{handler="/error",method="POST",le="1000"} 1
+		foo_bar_response_size_bytes_bucket{handler="/error",method="POST",le="+Inf"} 1
+		foo_bar_response_size_bytes_sum{handler="/error",method="POST"} 22
+		foo_bar_response_size_bytes_count{handler="/error",method="POST"} 1
+	`
+
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedSizesMetrics),
+		"foo_bar_request_size_bytes",
+		"foo_bar_response_size_bytes",
+	)
+	assert.NoError(t, err)
+}
+
+func TestRequestMetricsMiddlewareWithAbortedRequests(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestMetricsMiddlewareWithConfig(middleware.RequestMetricsMiddlewareConfig{
+		Registry:  registry,
+		Namespace: "foo",
+		Subsystem: "bar",
+	}))
+
+	waitHandler := func(c echo.Context) error {
+		<-c.Request().Context().Done()
+
+		return c.Request().Context().Err()
+	}
+
+	httpServer.GET("/ok", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	httpServer.GET("/cancel", waitHandler)
+	httpServer.GET("/timeout", waitHandler, middleware.RequestTimeoutMiddlewareWithConfig(
+		middleware.RequestTimeoutMiddlewareConfig{
+			Timeout: 10 * time.Millisecond,
+		},
+	))
+
+	// completed request, within the timeout
+	httpServer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+	// request canceled by the client
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	httpServer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/cancel", nil).WithContext(ctx))
+
+	// request timed out by the server
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/timeout", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	expectedCounterMetric := `
+		# HELP foo_bar_requests_aborted_total Number of HTTP requests aborted by a client cancellation or a server timeout
+		# TYPE foo_bar_requests_aborted_total counter
+		foo_bar_requests_aborted_total{handler="/cancel",method="GET",reason="canceled"} 1
+		foo_bar_requests_aborted_total{handler="/timeout",method="GET",reason="deadline_exceeded"} 1
+	`
+
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedCounterMetric),
+		"foo_bar_requests_aborted_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestRequestMetricsMiddlewareWithMetricsTypes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		metricsType       string
+		expectedType      dto.MetricType
+		expectedQuantiles []float64
+	}{
+		{
+			name:         "default",
+			metricsType:  "",
+			expectedType: dto.MetricType_HISTOGRAM,
+		},
+		{
+			name:         "histogram",
+			metricsType:  middleware.HttpServerMetricsTypeHistogram,
+			expectedType: dto.MetricType_HISTOGRAM,
+		},
+		{
+			name:              "summary",
+			metricsType:       middleware.HttpServerMetricsTypeSummary,
+			expectedType:      dto.MetricType_SUMMARY,
+			expectedQuantiles: []float64{0.5, 0.95},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := prometheus.NewPedanticRegistry()
+
+			httpServer := echo.New()
+			httpServer.Use(middleware.RequestMetricsMiddlewareWithConfig(middleware.RequestMetricsMiddlewareConfig{
+				Registry:   registry,
+				Type:       tt.metricsType,
+				Objectives: map[float64]float64{0.5: 0.05, 0.95: 0.005},
+			}))
+			httpServer.GET("/test", func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			families, err := registry.Gather()
+			assert.NoError(t, err)
+
+			var family *dto.MetricFamily
+			for _, f := range families {
+				if f.GetName() == "request_duration_seconds" {
+					family = f
+				}
+			}
+
+			assert.NotNil(t, family)
+			assert.Equal(t, tt.expectedType, family.GetType())
+			assert.Len(t, family.GetMetric(), 1)
+
+			if tt.expectedType == dto.MetricType_SUMMARY {
+				summary := family.GetMetric()[0].GetSummary()
+				assert.Equal(t, uint64(1), summary.GetSampleCount())
+
+				var quantiles []float64
+				for _, q := range summary.GetQuantile() {
+					quantiles = append(quantiles, q.GetQuantile())
+				}
+
+				assert.Equal(t, tt.expectedQuantiles, quantiles)
+			} else {
+				assert.Equal(t, uint64(1), family.GetMetric()[0].GetHistogram().GetSampleCount())
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const DefaultRequestTimeout = 30 * time.Second
+
+// RequestTimeoutMiddlewareConfig is the configuration for the [RequestTimeoutMiddleware].
+type RequestTimeoutMiddlewareConfig struct {
+	Skipper middleware.Skipper
+	Timeout time.Duration
+}
+
+// DefaultRequestTimeoutMiddlewareConfig is the default configuration for the [RequestTimeoutMiddleware].
+var DefaultRequestTimeoutMiddlewareConfig = RequestTimeoutMiddlewareConfig{
+	Skipper: middleware.DefaultSkipper,
+	Timeout: DefaultRequestTimeout,
+}
+
+// RequestTimeoutMiddleware returns a [RequestTimeoutMiddleware] with the [DefaultRequestTimeoutMiddlewareConfig].
+func RequestTimeoutMiddleware() echo.MiddlewareFunc {
+	return RequestTimeoutMiddlewareWithConfig(DefaultRequestTimeoutMiddlewareConfig)
+}
+
+// RequestTimeoutMiddlewareWithConfig returns a [RequestTimeoutMiddleware] for a provided [RequestTimeoutMiddlewareConfig].
+//
+// It sets a deadline on the request context, so the downstream calls made with this context (like http client calls)
+// are cancelled once the timeout elapses (an earlier incoming deadline is kept), and responds with a 503 if the handler
+// fails because of this deadline. The remaining budget can be read with [httpserver.CtxRemainingBudget]. The requests of
+// the routes marked as streaming with [httpserver.MarkStreamingRoutes] (like the server-sent events and websocket ones)
+// are never subject to the timeout, whatever their headers.
+func RequestTimeoutMiddlewareWithConfig(config RequestTimeoutMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultRequestTimeoutMiddlewareConfig.Skipper
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultRequestTimeoutMiddlewareConfig.Timeout
+	}
+
+	skipper := httpserver.StreamingSkipper(config.Skipper)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// skipper
+			if skipper(c) {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), config.Timeout)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+
+			if err != nil && errors.Is(err, context.DeadlineExceeded) && !c.Response().Committed {
+				return echo.ErrServiceUnavailable.WithInternal(err)
+			}
+
+			return err
+		}
+	}
+}
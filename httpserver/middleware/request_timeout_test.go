@@ -0,0 +1,166 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeoutMiddlewareCancelsDownstreamCall(t *testing.T) {
+	t.Parallel()
+
+	downstreamCancelled := make(chan struct{})
+
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(downstreamCancelled)
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer downstream.Close()
+
+	var downstreamErr error
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestTimeoutMiddlewareWithConfig(middleware.RequestTimeoutMiddlewareConfig{
+		Timeout: 50 * time.Millisecond,
+	}))
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		req, err := http.NewRequestWithContext(c.Request().Context(), http.MethodGet, downstream.URL, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			downstreamErr = err
+
+			return err
+		}
+
+		return resp.Body.Close()
+	})
+
+	start := time.Now()
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.True(t, errors.Is(downstreamErr, context.DeadlineExceeded))
+
+	select {
+	case <-downstreamCancelled:
+	case <-time.After(time.Second):
+		t.Error("downstream call was not cancelled")
+	}
+}
+
+func TestRequestTimeoutMiddlewareRemainingBudget(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestTimeoutMiddlewareWithConfig(middleware.RequestTimeoutMiddlewareConfig{
+		Timeout: time.Minute,
+	}))
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		budget, ok := httpserver.CtxRemainingBudget(c)
+		assert.True(t, ok)
+		assert.Greater(t, budget, 50*time.Second)
+		assert.LessOrEqual(t, budget, time.Minute)
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+}
+
+func TestRequestTimeoutMiddlewareKeepsEarlierDeadline(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestTimeoutMiddlewareWithConfig(middleware.RequestTimeoutMiddlewareConfig{
+		Timeout: time.Minute,
+	}))
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		budget, ok := httpserver.CtxRemainingBudget(c)
+		assert.True(t, ok)
+		assert.LessOrEqual(t, budget, time.Second)
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRequestTimeoutMiddlewareWithStreamingRoute(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestTimeoutMiddleware())
+
+	route := httpServer.GET("/stream", func(c echo.Context) error {
+		_, ok := httpserver.CtxRemainingBudget(c)
+		assert.False(t, ok)
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	httpserver.MarkStreamingRoutes(httpServer, route)
+
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRequestTimeoutMiddlewareWithSpoofedStreamingHeaders(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestTimeoutMiddleware())
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		_, ok := httpserver.CtxRemainingBudget(c)
+		assert.True(t, ok)
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(echo.HeaderAccept, httpserver.MIMETextEventStream)
+	req.Header.Set(echo.HeaderConnection, "Upgrade")
+	req.Header.Set(echo.HeaderUpgrade, "websocket")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
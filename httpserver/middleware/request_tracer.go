@@ -28,7 +28,7 @@ type RequestTracerMiddlewareConfig struct {
 var DefaultRequestTracerMiddlewareConfig = RequestTracerMiddlewareConfig{
 	Skipper:                     middleware.DefaultSkipper,
 	TracerProvider:              otel.GetTracerProvider(),
-	TextMapPropagator:           propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	TextMapPropagator:           nil,
 	RequestUriPrefixesToExclude: []string{},
 }
 
@@ -38,6 +38,9 @@ func RequestTracerMiddleware(serviceName string) echo.MiddlewareFunc {
 }
 
 // RequestTracerMiddlewareWithConfig returns a [RequestTracerMiddleware] for a provided [RequestTracerMiddlewareConfig].
+//
+// Without TextMapPropagator config, the global propagator (see otel.SetTextMapPropagator) is used to extract the
+// incoming trace context, W3C trace context and baggage by default.
 func RequestTracerMiddlewareWithConfig(serviceName string, config RequestTracerMiddlewareConfig) echo.MiddlewareFunc {
 	if config.Skipper == nil {
 		config.Skipper = DefaultRequestTracerMiddlewareConfig.Skipper
@@ -48,7 +51,7 @@ func RequestTracerMiddlewareWithConfig(serviceName string, config RequestTracerM
 	}
 
 	if config.TextMapPropagator == nil {
-		config.TextMapPropagator = DefaultRequestTracerMiddlewareConfig.TextMapPropagator
+		config.TextMapPropagator = otel.GetTextMapPropagator()
 	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -1,6 +1,7 @@
 package middleware_test
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,7 +12,9 @@ import (
 	"github.com/ankorstore/yokai/trace/tracetest"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 func TestRequestTracerMiddlewareWithDefaults(t *testing.T) {
@@ -46,6 +49,64 @@ func TestRequestTracerMiddlewareWithDefaults(t *testing.T) {
 	tracetest.AssertHasTraceSpan(t, exporter, "test span")
 }
 
+func TestRequestTracerMiddlewareWithGlobalPropagator(t *testing.T) {
+	exporter := tracetest.NewDefaultTestTraceExporter()
+
+	tracerProvider, err := trace.NewDefaultTracerProviderFactory().Create(
+		trace.Global(false),
+		trace.WithSpanProcessor(trace.NewTestSpanProcessor(exporter)),
+	)
+	assert.NoError(t, err)
+
+	traceId := "c4ca71e03e42c2c3d54293a6e2608bfa"
+
+	tests := []struct {
+		name             string
+		globalPropagator propagation.TextMapPropagator
+		expectedContinue bool
+	}{
+		{
+			name:             "with trace context global propagator",
+			globalPropagator: propagation.TraceContext{},
+			expectedContinue: true,
+		},
+		{
+			name:             "without trace context global propagator",
+			globalPropagator: propagation.Baggage{},
+			expectedContinue: false,
+		},
+	}
+
+	previousPropagator := otel.GetTextMapPropagator()
+	defer otel.SetTextMapPropagator(previousPropagator)
+
+	for _, tt := range tests {
+		otel.SetTextMapPropagator(tt.globalPropagator)
+		exporter.Reset()
+
+		httpServer := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("traceparent", fmt.Sprintf("00-%s-8d0fdc8a74baaaea-01", traceId))
+		rec := httptest.NewRecorder()
+
+		ctx := httpServer.NewContext(req, rec)
+		handler := func(c echo.Context) error {
+			return c.String(http.StatusOK, "ok")
+		}
+
+		m := middleware.RequestTracerMiddlewareWithConfig("test", middleware.RequestTracerMiddlewareConfig{
+			TracerProvider: tracerProvider,
+		})
+
+		err = m(handler)(ctx)
+		assert.NoError(t, err, tt.name)
+
+		span, err := exporter.Span("GET /test")
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expectedContinue, span.SpanContext.TraceID().String() == traceId, tt.name)
+	}
+}
+
 func TestRequestTracerMiddlewareWithOptions(t *testing.T) {
 	exporter := tracetest.NewDefaultTestTraceExporter()
 
@@ -0,0 +1,270 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	HeaderXCache                         = "X-Cache"
+	ResponseCacheHit                     = "HIT"
+	ResponseCacheMiss                    = "MISS"
+	HttpServerMetricsResponseCacheHits   = "response_cache_hits_total"
+	HttpServerMetricsResponseCacheMisses = "response_cache_misses_total"
+	DefaultResponseCacheTTL              = time.Minute
+	DefaultResponseCacheMaxEntries       = 1000
+)
+
+// ResponseCacheMiddlewareConfig is the configuration for the [ResponseCacheMiddleware].
+type ResponseCacheMiddlewareConfig struct {
+	Skipper                 middleware.Skipper
+	TTL                     time.Duration
+	MaxEntries              int
+	PrefixesTTL             map[string]time.Duration
+	VaryHeaders             []string
+	AllowAuthorizedRequests bool
+	Registry                prometheus.Registerer
+	Namespace               string
+	Subsystem               string
+	Now                     func() time.Time
+}
+
+// DefaultResponseCacheMiddlewareConfig is the default configuration for the [ResponseCacheMiddleware].
+var DefaultResponseCacheMiddlewareConfig = ResponseCacheMiddlewareConfig{
+	Skipper:                 middleware.DefaultSkipper,
+	TTL:                     DefaultResponseCacheTTL,
+	MaxEntries:              DefaultResponseCacheMaxEntries,
+	PrefixesTTL:             map[string]time.Duration{},
+	VaryHeaders:             []string{},
+	AllowAuthorizedRequests: false,
+	Registry:                prometheus.DefaultRegisterer,
+	Namespace:               "",
+	Subsystem:               "",
+	Now:                     time.Now,
+}
+
+// ResponseCacheMiddleware returns a [ResponseCacheMiddleware] with the [DefaultResponseCacheMiddlewareConfig].
+func ResponseCacheMiddleware() echo.MiddlewareFunc {
+	return ResponseCacheMiddlewareWithConfig(DefaultResponseCacheMiddlewareConfig)
+}
+
+// ResponseCacheMiddlewareWithConfig returns a [ResponseCacheMiddleware] for a provided [ResponseCacheMiddlewareConfig].
+//
+// It caches in memory the GET requests 200 responses, keyed by path, query and vary headers, and serves them
+// with the X-Cache header.
+func ResponseCacheMiddlewareWithConfig(config ResponseCacheMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultResponseCacheMiddlewareConfig.Skipper
+	}
+
+	if config.TTL <= 0 {
+		config.TTL = DefaultResponseCacheMiddlewareConfig.TTL
+	}
+
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultResponseCacheMiddlewareConfig.MaxEntries
+	}
+
+	if config.Registry == nil {
+		config.Registry = DefaultResponseCacheMiddlewareConfig.Registry
+	}
+
+	if config.Now == nil {
+		config.Now = DefaultResponseCacheMiddlewareConfig.Now
+	}
+
+	cacheHitsCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      HttpServerMetricsResponseCacheHits,
+			Help:      "Number of HTTP responses served from cache",
+		},
+	)
+
+	cacheMissesCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      HttpServerMetricsResponseCacheMisses,
+			Help:      "Number of cacheable HTTP requests not served from cache",
+		},
+	)
+
+	config.Registry.MustRegister(cacheHitsCounter, cacheMissesCounter)
+
+	cache := newResponseCache(config.MaxEntries)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			// skipper
+			if config.Skipper(c) ||
+				req.Method != http.MethodGet ||
+				httpserver.IsSSERequest(c) ||
+				httpserver.IsWebSocketRequest(c) ||
+				(req.Header.Get(echo.HeaderAuthorization) != "" && !config.AllowAuthorizedRequests) {
+				return next(c)
+			}
+
+			key := responseCacheKey(req, config.VaryHeaders)
+			now := config.Now()
+
+			// hit
+			if entry, ok := cache.get(key, now); ok {
+				cacheHitsCounter.Inc()
+
+				res := c.Response()
+				for name, values := range entry.header {
+					res.Header()[name] = values
+				}
+				res.Header().Set(HeaderXCache, ResponseCacheHit)
+				res.WriteHeader(http.StatusOK)
+
+				_, err := res.Write(entry.body)
+
+				return err
+			}
+
+			// miss
+			cacheMissesCounter.Inc()
+
+			c.Response().Header().Set(HeaderXCache, ResponseCacheMiss)
+
+			body := new(bytes.Buffer)
+			writer := c.Response().Writer
+			c.Response().Writer = newBodyDumpResponseWriter(writer, body)
+
+			err := next(c)
+
+			c.Response().Writer = writer
+
+			if err == nil && c.Response().Status == http.StatusOK {
+				header := c.Response().Header().Clone()
+				header.Del(HeaderXCache)
+
+				cache.set(key, &responseCacheEntry{
+					header:    header,
+					body:      body.Bytes(),
+					expiresAt: now.Add(responseCacheTTL(config, req.URL.Path)),
+				})
+			}
+
+			return err
+		}
+	}
+}
+
+func responseCacheKey(req *http.Request, varyHeaders []string) string {
+	var builder strings.Builder
+
+	builder.WriteString(req.URL.Path)
+	builder.WriteString("?")
+	builder.WriteString(req.URL.Query().Encode())
+
+	for _, header := range varyHeaders {
+		builder.WriteString("|")
+		builder.WriteString(strings.ToLower(header))
+		builder.WriteString("=")
+		builder.WriteString(strings.Join(req.Header.Values(header), ","))
+	}
+
+	return builder.String()
+}
+
+func responseCacheTTL(config ResponseCacheMiddlewareConfig, path string) time.Duration {
+	ttl := config.TTL
+	longestPrefix := ""
+
+	for prefix, prefixTTL := range config.PrefixesTTL {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(longestPrefix) {
+			ttl = prefixTTL
+			longestPrefix = prefix
+		}
+	}
+
+	return ttl
+}
+
+// responseCacheEntry is a cached response.
+type responseCacheEntry struct {
+	key       string
+	header    http.Header
+	body      []byte
+	expiresAt time.Time
+}
+
+// responseCache is a LRU cache of [responseCacheEntry].
+type responseCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+func newResponseCache(maxEntries int) *responseCache {
+	return &responseCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (rc *responseCache) get(key string, now time.Time) (*responseCacheEntry, bool) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	element, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	//nolint:forcetypeassert
+	entry := element.Value.(*responseCacheEntry)
+
+	if !now.Before(entry.expiresAt) {
+		rc.order.Remove(element)
+		delete(rc.entries, key)
+
+		return nil, false
+	}
+
+	rc.order.MoveToFront(element)
+
+	return entry, true
+}
+
+func (rc *responseCache) set(key string, entry *responseCacheEntry) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	entry.key = key
+
+	if element, ok := rc.entries[key]; ok {
+		element.Value = entry
+		rc.order.MoveToFront(element)
+
+		return
+	}
+
+	rc.entries[key] = rc.order.PushFront(entry)
+
+	for rc.order.Len() > rc.maxEntries {
+		oldest := rc.order.Back()
+
+		rc.order.Remove(oldest)
+
+		//nolint:forcetypeassert
+		delete(rc.entries, oldest.Value.(*responseCacheEntry).key)
+	}
+}
@@ -0,0 +1,227 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newResponseCacheTestServer(t *testing.T, config middleware.ResponseCacheMiddlewareConfig) (*echo.Echo, *int) {
+	t.Helper()
+
+	calls := 0
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.ResponseCacheMiddlewareWithConfig(config))
+
+	httpServer.GET("/catalog/:id", func(c echo.Context) error {
+		calls++
+
+		c.Response().Header().Set("X-Foo", "foo")
+
+		return c.String(http.StatusOK, fmt.Sprintf("%s:%d", c.Param("id"), calls))
+	})
+
+	httpServer.GET("/error", func(c echo.Context) error {
+		calls++
+
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("error:%d", calls))
+	})
+
+	return httpServer, &calls
+}
+
+func requestResponseCache(httpServer *echo.Echo, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestResponseCacheMiddlewareHitAndMiss(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	httpServer, calls := newResponseCacheTestServer(t, middleware.ResponseCacheMiddlewareConfig{
+		Registry:  registry,
+		Namespace: "foo",
+		Subsystem: "bar",
+	})
+
+	rec := requestResponseCache(httpServer, "/catalog/1?b=2&a=1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1:1", rec.Body.String())
+	assert.Equal(t, middleware.ResponseCacheMiss, rec.Header().Get(middleware.HeaderXCache))
+
+	// same query, different order
+	rec = requestResponseCache(httpServer, "/catalog/1?a=1&b=2", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1:1", rec.Body.String())
+	assert.Equal(t, "foo", rec.Header().Get("X-Foo"))
+	assert.Equal(t, middleware.ResponseCacheHit, rec.Header().Get(middleware.HeaderXCache))
+
+	// different path param
+	rec = requestResponseCache(httpServer, "/catalog/2?a=1&b=2", nil)
+	assert.Equal(t, "2:2", rec.Body.String())
+	assert.Equal(t, middleware.ResponseCacheMiss, rec.Header().Get(middleware.HeaderXCache))
+
+	// non 200 responses are not cached
+	rec = requestResponseCache(httpServer, "/error", nil)
+	assert.Equal(t, "error:3", rec.Body.String())
+	rec = requestResponseCache(httpServer, "/error", nil)
+	assert.Equal(t, "error:4", rec.Body.String())
+	assert.Equal(t, middleware.ResponseCacheMiss, rec.Header().Get(middleware.HeaderXCache))
+
+	assert.Equal(t, 4, *calls)
+
+	expected := `
+		# HELP foo_bar_response_cache_hits_total Number of HTTP responses served from cache
+		# TYPE foo_bar_response_cache_hits_total counter
+		foo_bar_response_cache_hits_total 1
+		# HELP foo_bar_response_cache_misses_total Number of cacheable HTTP requests not served from cache
+		# TYPE foo_bar_response_cache_misses_total counter
+		foo_bar_response_cache_misses_total 4
+	`
+
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expected),
+		"foo_bar_response_cache_hits_total",
+		"foo_bar_response_cache_misses_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestResponseCacheMiddlewareTTL(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	httpServer, _ := newResponseCacheTestServer(t, middleware.ResponseCacheMiddlewareConfig{
+		TTL: 10 * time.Second,
+		PrefixesTTL: map[string]time.Duration{
+			"/catalog/2": time.Minute,
+		},
+		Registry: prometheus.NewPedanticRegistry(),
+		Now:      clock.Now,
+	})
+
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", nil).Body.String())
+	assert.Equal(t, "2:2", requestResponseCache(httpServer, "/catalog/2", nil).Body.String())
+
+	clock.Advance(9 * time.Second)
+
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", nil).Body.String())
+	assert.Equal(t, "2:2", requestResponseCache(httpServer, "/catalog/2", nil).Body.String())
+
+	clock.Advance(time.Second)
+
+	// default ttl expired
+	rec := requestResponseCache(httpServer, "/catalog/1", nil)
+	assert.Equal(t, "1:3", rec.Body.String())
+	assert.Equal(t, middleware.ResponseCacheMiss, rec.Header().Get(middleware.HeaderXCache))
+
+	// prefix ttl not expired
+	rec = requestResponseCache(httpServer, "/catalog/2", nil)
+	assert.Equal(t, "2:2", rec.Body.String())
+	assert.Equal(t, middleware.ResponseCacheHit, rec.Header().Get(middleware.HeaderXCache))
+
+	clock.Advance(time.Minute)
+
+	// prefix ttl expired
+	assert.Equal(t, "2:4", requestResponseCache(httpServer, "/catalog/2", nil).Body.String())
+}
+
+func TestResponseCacheMiddlewareMaxEntries(t *testing.T) {
+	t.Parallel()
+
+	httpServer, _ := newResponseCacheTestServer(t, middleware.ResponseCacheMiddlewareConfig{
+		MaxEntries: 2,
+		Registry:   prometheus.NewPedanticRegistry(),
+	})
+
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", nil).Body.String())
+	assert.Equal(t, "2:2", requestResponseCache(httpServer, "/catalog/2", nil).Body.String())
+
+	// 1 becomes the most recently used
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", nil).Body.String())
+
+	// 3 evicts 2, the least recently used
+	assert.Equal(t, "3:3", requestResponseCache(httpServer, "/catalog/3", nil).Body.String())
+
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", nil).Body.String())
+	assert.Equal(t, "2:4", requestResponseCache(httpServer, "/catalog/2", nil).Body.String())
+}
+
+func TestResponseCacheMiddlewareVaryHeaders(t *testing.T) {
+	t.Parallel()
+
+	httpServer, _ := newResponseCacheTestServer(t, middleware.ResponseCacheMiddlewareConfig{
+		VaryHeaders: []string{"Accept-Language"},
+		Registry:    prometheus.NewPedanticRegistry(),
+	})
+
+	en := map[string]string{"Accept-Language": "en"}
+	fr := map[string]string{"Accept-Language": "fr"}
+
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", en).Body.String())
+	assert.Equal(t, "1:2", requestResponseCache(httpServer, "/catalog/1", fr).Body.String())
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", en).Body.String())
+	assert.Equal(t, "1:2", requestResponseCache(httpServer, "/catalog/1", fr).Body.String())
+}
+
+func TestResponseCacheMiddlewareWithAuthorizedRequests(t *testing.T) {
+	t.Parallel()
+
+	authorized := map[string]string{echo.HeaderAuthorization: "Bearer token"}
+
+	// bypassed by default
+	httpServer, _ := newResponseCacheTestServer(t, middleware.ResponseCacheMiddlewareConfig{
+		Registry: prometheus.NewPedanticRegistry(),
+	})
+
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", authorized).Body.String())
+
+	rec := requestResponseCache(httpServer, "/catalog/1", authorized)
+	assert.Equal(t, "1:2", rec.Body.String())
+	assert.Equal(t, "", rec.Header().Get(middleware.HeaderXCache))
+
+	// explicitly allowed
+	httpServer, _ = newResponseCacheTestServer(t, middleware.ResponseCacheMiddlewareConfig{
+		AllowAuthorizedRequests: true,
+		Registry:                prometheus.NewPedanticRegistry(),
+	})
+
+	assert.Equal(t, "1:1", requestResponseCache(httpServer, "/catalog/1", authorized).Body.String())
+
+	rec = requestResponseCache(httpServer, "/catalog/1", authorized)
+	assert.Equal(t, "1:1", rec.Body.String())
+	assert.Equal(t, middleware.ResponseCacheHit, rec.Header().Get(middleware.HeaderXCache))
+}
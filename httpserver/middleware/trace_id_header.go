@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderXTraceId is the default response header of the [TraceIdHeaderMiddleware].
+const HeaderXTraceId = "x-trace-id"
+
+// TraceIdHeaderMiddlewareConfig is the configuration for the [TraceIdHeaderMiddleware].
+type TraceIdHeaderMiddlewareConfig struct {
+	Skipper middleware.Skipper
+	Header  string
+}
+
+// DefaultTraceIdHeaderMiddlewareConfig is the default configuration for the [TraceIdHeaderMiddleware].
+var DefaultTraceIdHeaderMiddlewareConfig = TraceIdHeaderMiddlewareConfig{
+	Skipper: middleware.DefaultSkipper,
+	Header:  HeaderXTraceId,
+}
+
+// TraceIdHeaderMiddleware returns a [TraceIdHeaderMiddleware] with the [DefaultTraceIdHeaderMiddlewareConfig].
+func TraceIdHeaderMiddleware() echo.MiddlewareFunc {
+	return TraceIdHeaderMiddlewareWithConfig(DefaultTraceIdHeaderMiddlewareConfig)
+}
+
+// TraceIdHeaderMiddlewareWithConfig returns a [TraceIdHeaderMiddleware] for a provided [TraceIdHeaderMiddlewareConfig].
+//
+// The trace id of the request span is written in the configured response header, if this span is sampled: it must
+// therefore be registered after the [RequestTracerMiddleware], to find its span in the request context.
+func TraceIdHeaderMiddlewareWithConfig(config TraceIdHeaderMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultTraceIdHeaderMiddlewareConfig.Skipper
+	}
+
+	if config.Header == "" {
+		config.Header = DefaultTraceIdHeaderMiddlewareConfig.Header
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			spanContext := trace.SpanContextFromContext(c.Request().Context())
+			if spanContext.IsValid() && spanContext.IsSampled() {
+				c.Response().Header().Set(config.Header, spanContext.TraceID().String())
+			}
+
+			return next(c)
+		}
+	}
+}
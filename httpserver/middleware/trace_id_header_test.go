@@ -0,0 +1,134 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/ankorstore/yokai/trace"
+	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTraceIdHeaderMiddlewareWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewDefaultTestTraceExporter()
+
+	tracerProvider, err := trace.NewDefaultTracerProviderFactory().Create(
+		trace.Global(false),
+		trace.WithSpanProcessor(trace.NewTestSpanProcessor(exporter)),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestTracerMiddlewareWithConfig("test", middleware.RequestTracerMiddlewareConfig{
+		TracerProvider: tracerProvider,
+	}))
+	httpServer.Use(middleware.TraceIdHeaderMiddleware())
+	httpServer.GET("/test", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	httpServer.GET("/error", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusInternalServerError, "error")
+	})
+
+	for _, path := range []string{"/test", "/error"} {
+		exporter.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		span, err := exporter.Span("GET " + path)
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, rec.Header().Get(middleware.HeaderXTraceId))
+		assert.Equal(t, span.SpanContext.TraceID().String(), rec.Header().Get(middleware.HeaderXTraceId))
+	}
+}
+
+func TestTraceIdHeaderMiddlewareWithConfig(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewDefaultTestTraceExporter()
+
+	tracerProvider, err := trace.NewDefaultTracerProviderFactory().Create(
+		trace.Global(false),
+		trace.WithSpanProcessor(trace.NewTestSpanProcessor(exporter)),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestTracerMiddlewareWithConfig("test", middleware.RequestTracerMiddlewareConfig{
+		TracerProvider:              tracerProvider,
+		RequestUriPrefixesToExclude: []string{"/excluded"},
+	}))
+	httpServer.Use(middleware.TraceIdHeaderMiddlewareWithConfig(middleware.TraceIdHeaderMiddlewareConfig{
+		Header: "x-custom-trace-id",
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/skipped"
+		},
+	}))
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}
+	httpServer.GET("/test", handler)
+	httpServer.GET("/skipped", handler)
+	httpServer.GET("/excluded", handler)
+
+	// custom header
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	span, err := exporter.Span("GET /test")
+	assert.NoError(t, err)
+
+	assert.Equal(t, span.SpanContext.TraceID().String(), rec.Header().Get("x-custom-trace-id"))
+	assert.Empty(t, rec.Header().Get(middleware.HeaderXTraceId))
+
+	// skipped
+	req = httptest.NewRequest(http.MethodGet, "/skipped", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Empty(t, rec.Header().Get("x-custom-trace-id"))
+
+	// not traced
+	req = httptest.NewRequest(http.MethodGet, "/excluded", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Empty(t, rec.Header().Get("x-custom-trace-id"))
+}
+
+func TestTraceIdHeaderMiddlewareWithUnsampledSpan(t *testing.T) {
+	t.Parallel()
+
+	tracerProvider, err := trace.NewDefaultTracerProviderFactory().Create(
+		trace.Global(false),
+		trace.WithSampler(sdktrace.NeverSample()),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestTracerMiddlewareWithConfig("test", middleware.RequestTracerMiddlewareConfig{
+		TracerProvider: tracerProvider,
+	}))
+	httpServer.Use(middleware.TraceIdHeaderMiddleware())
+	httpServer.GET("/test", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(middleware.HeaderXTraceId))
+}
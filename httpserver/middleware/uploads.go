@@ -0,0 +1,317 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	HttpServerMetricsUploadsRejected = "uploads_rejected_total"
+	UploadRejectionTooLarge          = "too_large"
+	UploadRejectionContentType       = "unsupported_content_type"
+	UploadRejectionMalformed         = "malformed"
+	DefaultUploadsMaxMemory          = 32 << 20
+)
+
+// UploadsMiddlewareConfig is the configuration for the [UploadsMiddleware].
+type UploadsMiddlewareConfig struct {
+	Skipper             middleware.Skipper
+	MaxMemory           int64
+	TempDir             string
+	AllowedContentTypes []string
+	MaxFileSize         int64
+	Registry            prometheus.Registerer
+	Namespace           string
+	Subsystem           string
+}
+
+// DefaultUploadsMiddlewareConfig is the default configuration for the [UploadsMiddleware].
+var DefaultUploadsMiddlewareConfig = UploadsMiddlewareConfig{
+	Skipper:             middleware.DefaultSkipper,
+	MaxMemory:           DefaultUploadsMaxMemory,
+	TempDir:             "",
+	AllowedContentTypes: []string{},
+	MaxFileSize:         0,
+	Registry:            prometheus.DefaultRegisterer,
+	Namespace:           "",
+	Subsystem:           "",
+}
+
+// UploadsMiddleware returns a [UploadsMiddleware] with the [DefaultUploadsMiddlewareConfig].
+func UploadsMiddleware() echo.MiddlewareFunc {
+	return UploadsMiddlewareWithConfig(DefaultUploadsMiddlewareConfig)
+}
+
+// UploadsMiddlewareWithConfig returns a [UploadsMiddleware] for a provided [UploadsMiddlewareConfig].
+//
+// It parses the multipart/form-data requests, keeps the uploaded files in memory up to MaxMemory and stores the
+// remaining ones in TempDir (defaults to [os.TempDir]). It rejects files bigger than MaxFileSize with a 413 (0 means
+// unlimited) and files not matching the AllowedContentTypes (supporting wildcards like image/*) with a 415 (empty
+// means any). The uploaded files are available via [httpserver.CtxUploadedFiles], the form values via the usual
+// echo helpers, and the temporary files are removed once the handler returns.
+func UploadsMiddlewareWithConfig(config UploadsMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultUploadsMiddlewareConfig.Skipper
+	}
+
+	if config.MaxMemory <= 0 {
+		config.MaxMemory = DefaultUploadsMiddlewareConfig.MaxMemory
+	}
+
+	if config.AllowedContentTypes == nil {
+		config.AllowedContentTypes = DefaultUploadsMiddlewareConfig.AllowedContentTypes
+	}
+
+	if config.Registry == nil {
+		config.Registry = DefaultUploadsMiddlewareConfig.Registry
+	}
+
+	uploadsRejectedCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      HttpServerMetricsUploadsRejected,
+			Help:      "Number of rejected HTTP uploads",
+		},
+		[]string{
+			"reason",
+		},
+	)
+
+	config.Registry.MustRegister(uploadsRejectedCounter)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			// skipper
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			mediaType, params, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+			if err != nil || mediaType != echo.MIMEMultipartForm {
+				return next(c)
+			}
+
+			parser := &uploadsParser{config: config}
+			defer parser.cleanup()
+
+			err = parser.parse(multipart.NewReader(req.Body, params["boundary"]))
+			if err != nil {
+				var rejection *uploadRejection
+				if !errors.As(err, &rejection) {
+					rejection = &uploadRejection{
+						reason: UploadRejectionMalformed,
+						code:   http.StatusBadRequest,
+						err:    err,
+					}
+				}
+
+				uploadsRejectedCounter.WithLabelValues(rejection.reason).Inc()
+
+				return echo.NewHTTPError(rejection.code, rejection.err.Error())
+			}
+
+			req.Form = parser.values
+			req.PostForm = parser.values
+			req.MultipartForm = &multipart.Form{
+				Value: parser.values,
+				File:  map[string][]*multipart.FileHeader{},
+			}
+
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), httpserver.CtxUploadedFilesKey{}, parser.files)))
+
+			return next(c)
+		}
+	}
+}
+
+// uploadRejection is an upload rejection, with its reason and status code.
+type uploadRejection struct {
+	reason string
+	code   int
+	err    error
+}
+
+func (r *uploadRejection) Error() string {
+	return r.err.Error()
+}
+
+// uploadsParser parses and stores multipart uploads.
+type uploadsParser struct {
+	config UploadsMiddlewareConfig
+	values url.Values
+	files  map[string][]*httpserver.UploadedFile
+	paths  []string
+}
+
+func (p *uploadsParser) parse(reader *multipart.Reader) error {
+	p.values = url.Values{}
+	p.files = map[string][]*httpserver.UploadedFile{}
+
+	remainingMemory := p.config.MaxMemory
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		field := part.FormName()
+		if field == "" {
+			continue
+		}
+
+		// form value
+		if part.FileName() == "" {
+			var buf bytes.Buffer
+
+			n, err := io.CopyN(&buf, part, remainingMemory+1)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			remainingMemory -= n
+			if remainingMemory < 0 {
+				return &uploadRejection{
+					reason: UploadRejectionTooLarge,
+					code:   http.StatusRequestEntityTooLarge,
+					err:    errors.New("form values too large"),
+				}
+			}
+
+			p.values.Add(field, buf.String())
+
+			continue
+		}
+
+		// form file
+		file, err := p.store(part, &remainingMemory)
+		if err != nil {
+			return err
+		}
+
+		p.files[field] = append(p.files[field], file)
+	}
+}
+
+func (p *uploadsParser) store(part *multipart.Part, remainingMemory *int64) (*httpserver.UploadedFile, error) {
+	contentType := part.Header.Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if !isAllowedUploadContentType(p.config.AllowedContentTypes, contentType) {
+		return nil, &uploadRejection{
+			reason: UploadRejectionContentType,
+			code:   http.StatusUnsupportedMediaType,
+			err:    fmt.Errorf("unsupported content type %s for file %s", contentType, part.FileName()),
+		}
+	}
+
+	var source io.Reader = part
+	if p.config.MaxFileSize > 0 {
+		source = io.LimitReader(part, p.config.MaxFileSize+1)
+	}
+
+	file := &httpserver.UploadedFile{
+		Field:       part.FormName(),
+		Filename:    part.FileName(),
+		ContentType: contentType,
+		Header:      part.Header,
+	}
+
+	var buf bytes.Buffer
+
+	n, err := io.CopyN(&buf, source, *remainingMemory+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if n <= *remainingMemory {
+		// in memory
+		file.Content = buf.Bytes()
+		file.Size = n
+		*remainingMemory -= n
+	} else {
+		// on disk
+		tmp, err := os.CreateTemp(p.config.TempDir, "upload-")
+		if err != nil {
+			return nil, err
+		}
+
+		p.paths = append(p.paths, tmp.Name())
+
+		size, err := io.Copy(tmp, io.MultiReader(&buf, source))
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		file.Path = tmp.Name()
+		file.Size = size
+	}
+
+	if p.config.MaxFileSize > 0 && file.Size > p.config.MaxFileSize {
+		return nil, &uploadRejection{
+			reason: UploadRejectionTooLarge,
+			code:   http.StatusRequestEntityTooLarge,
+			err:    fmt.Errorf("file %s exceeds the maximum size of %d bytes", part.FileName(), p.config.MaxFileSize),
+		}
+	}
+
+	return file, nil
+}
+
+func (p *uploadsParser) cleanup() {
+	for _, path := range p.paths {
+		_ = os.Remove(path)
+	}
+}
+
+func isAllowedUploadContentType(allowedContentTypes []string, contentType string) bool {
+	if len(allowedContentTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range allowedContentTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+
+		if allowed == mediaType {
+			return true
+		}
+
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,251 @@
+package middleware_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUpload struct {
+	field       string
+	filename    string
+	contentType string
+	content     string
+}
+
+func newUploadsTestRequest(t *testing.T, values map[string]string, uploads ...testUpload) *http.Request {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for name, value := range values {
+		assert.NoError(t, writer.WriteField(name, value))
+	}
+
+	for _, upload := range uploads {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, upload.field, upload.filename))
+		header.Set("Content-Type", upload.contentType)
+
+		part, err := writer.CreatePart(header)
+		assert.NoError(t, err)
+
+		_, err = part.Write([]byte(upload.content))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+
+	return req
+}
+
+func newUploadsTestServer(t *testing.T, config middleware.UploadsMiddlewareConfig, paths *[]string) *echo.Echo {
+	t.Helper()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.UploadsMiddlewareWithConfig(config))
+
+	httpServer.POST("/upload", func(c echo.Context) error {
+		file, err := httpserver.CtxUploadedFile(c, "file")
+		if err != nil {
+			return err
+		}
+
+		if file.Path != "" {
+			*paths = append(*paths, file.Path)
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		return c.String(
+			http.StatusOK,
+			fmt.Sprintf("%s:%s:%s:%d:%s", c.FormValue("name"), file.Filename, file.ContentType, file.Size, content),
+		)
+	})
+
+	return httpServer
+}
+
+func TestUploadsMiddlewareWithinLimits(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+
+	httpServer := newUploadsTestServer(t, middleware.UploadsMiddlewareConfig{
+		AllowedContentTypes: []string{"text/plain"},
+		MaxFileSize:         10,
+		Registry:            prometheus.NewPedanticRegistry(),
+	}, &paths)
+
+	req := newUploadsTestRequest(
+		t,
+		map[string]string{"name": "foo"},
+		testUpload{field: "file", filename: "foo.txt", contentType: "text/plain", content: "some text"},
+	)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "foo:foo.txt:text/plain:9:some text", rec.Body.String())
+	assert.Len(t, paths, 0)
+}
+
+func TestUploadsMiddlewareWithTempDir(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+
+	tempDir := t.TempDir()
+
+	httpServer := newUploadsTestServer(t, middleware.UploadsMiddlewareConfig{
+		MaxMemory: 4,
+		TempDir:   tempDir,
+		Registry:  prometheus.NewPedanticRegistry(),
+	}, &paths)
+
+	req := newUploadsTestRequest(
+		t,
+		map[string]string{"name": "foo"},
+		testUpload{field: "file", filename: "foo.txt", contentType: "text/plain", content: "some text"},
+	)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "foo:foo.txt:text/plain:9:some text", rec.Body.String())
+
+	// stored in temp dir, and cleaned up after the handler returned
+	assert.Len(t, paths, 1)
+	assert.True(t, strings.HasPrefix(paths[0], tempDir))
+
+	_, err := os.Stat(paths[0])
+	assert.True(t, os.IsNotExist(err))
+
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 0)
+}
+
+func TestUploadsMiddlewareBeyondLimits(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+
+	tempDir := t.TempDir()
+	registry := prometheus.NewPedanticRegistry()
+
+	httpServer := newUploadsTestServer(t, middleware.UploadsMiddlewareConfig{
+		MaxMemory:           4,
+		TempDir:             tempDir,
+		AllowedContentTypes: []string{"image/*"},
+		MaxFileSize:         10,
+		Registry:            registry,
+		Namespace:           "foo",
+		Subsystem:           "bar",
+	}, &paths)
+
+	// allowed content type within size limit
+	req := newUploadsTestRequest(
+		t,
+		nil,
+		testUpload{field: "file", filename: "foo.png", contentType: "image/png", content: "png"},
+	)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// allowed content type beyond size limit
+	req = newUploadsTestRequest(
+		t,
+		nil,
+		testUpload{field: "file", filename: "foo.png", contentType: "image/png", content: "some big png"},
+	)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "file foo.png exceeds the maximum size of 10 bytes")
+
+	// not allowed content type
+	req = newUploadsTestRequest(
+		t,
+		nil,
+		testUpload{field: "file", filename: "foo.txt", contentType: "text/plain", content: "txt"},
+	)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unsupported content type text/plain for file foo.txt")
+
+	// temp files cleaned up, even for rejected uploads
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 0)
+
+	expected := `
+		# HELP foo_bar_uploads_rejected_total Number of rejected HTTP uploads
+		# TYPE foo_bar_uploads_rejected_total counter
+		foo_bar_uploads_rejected_total{reason="too_large"} 1
+		foo_bar_uploads_rejected_total{reason="unsupported_content_type"} 1
+	`
+
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expected),
+		"foo_bar_uploads_rejected_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestUploadsMiddlewareWithNonMultipartRequest(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.UploadsMiddlewareWithConfig(middleware.UploadsMiddlewareConfig{
+		Registry: prometheus.NewPedanticRegistry(),
+	}))
+
+	httpServer.POST("/upload", func(c echo.Context) error {
+		_, err := httpserver.CtxUploadedFile(c, "file")
+		assert.ErrorIs(t, err, http.ErrMissingFile)
+
+		return c.String(http.StatusOK, c.FormValue("name"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("name=foo"))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "foo", rec.Body.String())
+}
@@ -1,6 +1,8 @@
 package httpserver
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 )
@@ -10,11 +12,15 @@ type Options struct {
 	Debug            bool
 	Banner           bool
 	Recovery         bool
+	RecoveryStatus   int
+	PanicTracker     *PanicTracker
 	Logger           echo.Logger
 	Binder           echo.Binder
 	JsonSerializer   echo.JSONSerializer
+	Validator        echo.Validator
 	HttpErrorHandler echo.HTTPErrorHandler
 	Renderer         echo.Renderer
+	MaxHeaderBytes   int
 }
 
 // DefaultHttpServerOptions are the default options used in the [DefaultHttpServerFactory].
@@ -23,11 +29,15 @@ func DefaultHttpServerOptions() Options {
 		Debug:            false,
 		Banner:           false,
 		Recovery:         true,
+		RecoveryStatus:   http.StatusInternalServerError,
+		PanicTracker:     nil,
 		Logger:           log.New("default"),
 		Binder:           &echo.DefaultBinder{},
 		JsonSerializer:   &echo.DefaultJSONSerializer{},
+		Validator:        nil,
 		HttpErrorHandler: nil,
 		Renderer:         nil,
+		MaxHeaderBytes:   0,
 	}
 }
 
@@ -55,6 +65,20 @@ func WithRecovery(r bool) HttpServerOption {
 	}
 }
 
+// WithRecoveryStatus is used to specify the response status of the server automatic panic recovery.
+func WithRecoveryStatus(s int) HttpServerOption {
+	return func(o *Options) {
+		o.RecoveryStatus = s
+	}
+}
+
+// WithPanicTracker is used to specify a [PanicTracker] recording the server recovered panics.
+func WithPanicTracker(t *PanicTracker) HttpServerOption {
+	return func(o *Options) {
+		o.PanicTracker = t
+	}
+}
+
 // WithLogger is used to specify a [echo.Logger] to be used by the server.
 func WithLogger(l echo.Logger) HttpServerOption {
 	return func(o *Options) {
@@ -76,6 +100,18 @@ func WithJsonSerializer(s echo.JSONSerializer) HttpServerOption {
 	}
 }
 
+// WithJSONSerializer is an alias of [WithJsonSerializer].
+func WithJSONSerializer(s echo.JSONSerializer) HttpServerOption {
+	return WithJsonSerializer(s)
+}
+
+// WithValidator is used to specify a [echo.Validator] to be used by the server.
+func WithValidator(v echo.Validator) HttpServerOption {
+	return func(o *Options) {
+		o.Validator = v
+	}
+}
+
 // WithHttpErrorHandler is used to specify a [echo.HTTPErrorHandler] to be used by the server.
 func WithHttpErrorHandler(h echo.HTTPErrorHandler) HttpServerOption {
 	return func(o *Options) {
@@ -89,3 +125,11 @@ func WithRenderer(r echo.Renderer) HttpServerOption {
 		o.Renderer = r
 	}
 }
+
+// WithMaxHeaderBytes is used to specify the maximum size in bytes of the requests headers (http.DefaultMaxHeaderBytes
+// if zero), the requests exceeding it being rejected with a 431 status.
+func WithMaxHeaderBytes(b int) HttpServerOption {
+	return func(o *Options) {
+		o.MaxHeaderBytes = b
+	}
+}
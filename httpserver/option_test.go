@@ -1,7 +1,9 @@
 package httpserver_test
 
 import (
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/httpserver"
 	"github.com/labstack/echo/v4"
@@ -36,6 +38,25 @@ func TestWithRecovery(t *testing.T) {
 	assert.Equal(t, false, opt.Recovery)
 }
 
+func TestWithRecoveryStatus(t *testing.T) {
+	t.Parallel()
+
+	opt := httpserver.DefaultHttpServerOptions()
+	httpserver.WithRecoveryStatus(http.StatusServiceUnavailable)(&opt)
+
+	assert.Equal(t, http.StatusServiceUnavailable, opt.RecoveryStatus)
+}
+
+func TestWithPanicTracker(t *testing.T) {
+	t.Parallel()
+
+	opt := httpserver.DefaultHttpServerOptions()
+	tracker := httpserver.NewPanicTracker(1, time.Minute)
+	httpserver.WithPanicTracker(tracker)(&opt)
+
+	assert.Equal(t, tracker, opt.PanicTracker)
+}
+
 func TestWithLogger(t *testing.T) {
 	t.Parallel()
 
@@ -66,6 +87,27 @@ func TestWithJsonSerializer(t *testing.T) {
 	assert.Equal(t, jsonSerializer, opt.JsonSerializer)
 }
 
+func TestWithJSONSerializer(t *testing.T) {
+	t.Parallel()
+
+	opt := httpserver.DefaultHttpServerOptions()
+	jsonSerializer := &httpserver.GoccyEchoJsonSerializer{}
+	httpserver.WithJSONSerializer(jsonSerializer)(&opt)
+
+	assert.Equal(t, jsonSerializer, opt.JsonSerializer)
+}
+
+func TestWithValidator(t *testing.T) {
+	t.Parallel()
+
+	opt := httpserver.DefaultHttpServerOptions()
+	validator, err := httpserver.NewEchoValidator()
+	assert.NoError(t, err)
+	httpserver.WithValidator(validator)(&opt)
+
+	assert.Equal(t, validator, opt.Validator)
+}
+
 func TestWithHttpErrorHandler(t *testing.T) {
 	t.Parallel()
 
@@ -84,3 +126,12 @@ func TestWithRenderer(t *testing.T) {
 
 	assert.NotNil(t, opt.Renderer)
 }
+
+func TestWithMaxHeaderBytes(t *testing.T) {
+	t.Parallel()
+
+	opt := httpserver.DefaultHttpServerOptions()
+	httpserver.WithMaxHeaderBytes(1024)(&opt)
+
+	assert.Equal(t, 1024, opt.MaxHeaderBytes)
+}
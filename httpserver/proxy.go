@@ -0,0 +1,71 @@
+package httpserver
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DefaultTrustedProxies are the default trusted proxies ranges: loopback, link-local and private networks.
+var DefaultTrustedProxies = []string{
+	"127.0.0.0/8",
+	"::1/128",
+	"169.254.0.0/16",
+	"fe80::/10",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+}
+
+// ParseTrustedProxies returns the networks for a list of trusted proxies, provided as CIDR ranges or single IPs.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s", proxy)
+			}
+
+			if ip.To4() != nil {
+				proxy = proxy + "/32"
+			} else {
+				proxy = proxy + "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s: %w", proxy, err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// IsTrustedProxy returns true if a remote address (host or host:port) belongs to one of the provided networks.
+func IsTrustedProxy(remoteAddr string, networks []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
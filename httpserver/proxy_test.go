@@ -0,0 +1,46 @@
+package httpserver_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	networks, err := httpserver.ParseTrustedProxies([]string{"10.0.0.0/8", " 192.168.1.10 ", "::1", "fc00::/7"})
+	assert.NoError(t, err)
+	assert.Len(t, networks, 4)
+	assert.Equal(t, "10.0.0.0/8", networks[0].String())
+	assert.Equal(t, "192.168.1.10/32", networks[1].String())
+	assert.Equal(t, "::1/128", networks[2].String())
+	assert.Equal(t, "fc00::/7", networks[3].String())
+
+	_, err = httpserver.ParseTrustedProxies([]string{"invalid"})
+	assert.Error(t, err)
+	assert.Equal(t, "invalid trusted proxy invalid", err.Error())
+
+	_, err = httpserver.ParseTrustedProxies([]string{"10.0.0.0/64"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid trusted proxy 10.0.0.0/64")
+
+	networks, err = httpserver.ParseTrustedProxies(httpserver.DefaultTrustedProxies)
+	assert.NoError(t, err)
+	assert.Len(t, networks, len(httpserver.DefaultTrustedProxies))
+}
+
+func TestIsTrustedProxy(t *testing.T) {
+	t.Parallel()
+
+	networks, err := httpserver.ParseTrustedProxies([]string{"10.0.0.0/8", "::1"})
+	assert.NoError(t, err)
+
+	assert.True(t, httpserver.IsTrustedProxy("10.1.2.3:8080", networks))
+	assert.True(t, httpserver.IsTrustedProxy("10.1.2.3", networks))
+	assert.True(t, httpserver.IsTrustedProxy("[::1]:8080", networks))
+	assert.False(t, httpserver.IsTrustedProxy("11.1.2.3:8080", networks))
+	assert.False(t, httpserver.IsTrustedProxy("invalid", networks))
+	assert.False(t, httpserver.IsTrustedProxy("10.1.2.3:8080", nil))
+}
@@ -0,0 +1,127 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// DefaultPanicTrackerProbeName is the default name of the [PanicTracker] probe.
+const DefaultPanicTrackerProbeName = "httpserver:panics"
+
+// PanicTracker tracks the recovered panics, and reports an unhealthy status once a threshold of panics is reached
+// within a sliding time window, to be used as a readiness [healthcheck.CheckerProbe].
+//
+// The status gets healthy again once enough panics are out of the window.
+type PanicTracker struct {
+	threshold int
+	window    time.Duration
+	mutex     sync.Mutex
+	panics    []time.Time
+	now       func() time.Time
+}
+
+// NewPanicTracker returns a new [PanicTracker], reporting unhealthy after threshold panics within the window.
+func NewPanicTracker(threshold int, window time.Duration) *PanicTracker {
+	return &PanicTracker{
+		threshold: threshold,
+		window:    window,
+		panics:    []time.Time{},
+		now:       time.Now,
+	}
+}
+
+// Record records a recovered panic.
+func (t *PanicTracker) Record() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.panics = append(t.evict(), t.now())
+}
+
+// Count returns the number of recorded panics within the window.
+func (t *PanicTracker) Count() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.panics = t.evict()
+
+	return len(t.panics)
+}
+
+// Healthy returns false if the threshold of panics is reached within the window.
+func (t *PanicTracker) Healthy() bool {
+	return t.threshold <= 0 || t.Count() < t.threshold
+}
+
+// Name returns the name of the [PanicTracker] probe.
+func (t *PanicTracker) Name() string {
+	return DefaultPanicTrackerProbeName
+}
+
+// Check returns a failing [healthcheck.CheckerProbeResult] if the threshold of panics is reached within the window.
+func (t *PanicTracker) Check(context.Context) *healthcheck.CheckerProbeResult {
+	count := t.Count()
+
+	if t.threshold > 0 && count >= t.threshold {
+		return healthcheck.NewCheckerProbeResult(
+			false,
+			fmt.Sprintf("%d recovered panics within %s, threshold is %d", count, t.window, t.threshold),
+		)
+	}
+
+	return healthcheck.NewCheckerProbeResult(
+		true,
+		fmt.Sprintf("%d recovered panics within %s", count, t.window),
+	)
+}
+
+// evict returns the recorded panics within the window, and must be called with the lock held.
+func (t *PanicTracker) evict() []time.Time {
+	limit := t.now().Add(-t.window)
+
+	idx := 0
+	for idx < len(t.panics) && !t.panics[idx].After(limit) {
+		idx++
+	}
+
+	return t.panics[idx:]
+}
+
+// RecoverConfig returns the [middleware.RecoverConfig] of the server panic recovery middleware, responding with the
+// provided status (500 if not provided), and recording the recovered panics on the provided [PanicTracker] if any.
+//
+// The response is rendered by the server [echo.HTTPErrorHandler], as for any other error, with the status text as
+// message and the recovered panic as internal error, carrying the panic stack (see [log.WrapWithStack]).
+func RecoverConfig(status int, tracker *PanicTracker) middleware.RecoverConfig {
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	config := middleware.DefaultRecoverConfig
+	config.LogErrorFunc = func(c echo.Context, err error, stack []byte) error {
+		if tracker != nil {
+			tracker.Record()
+		}
+
+		c.Logger().Print(fmt.Sprintf("[PANIC RECOVER] %v %s\n", err, stack))
+
+		// captured while recovering, the stack includes the frames of the panic
+		err = log.WrapWithStack(err)
+
+		if status == http.StatusInternalServerError {
+			return err
+		}
+
+		return echo.NewHTTPError(status, http.StatusText(status)).SetInternal(err)
+	}
+
+	return config
+}
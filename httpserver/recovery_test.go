@@ -0,0 +1,193 @@
+package httpserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanicTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := httpserver.NewPanicTracker(2, 100*time.Millisecond)
+
+	assert.Equal(t, httpserver.DefaultPanicTrackerProbeName, tracker.Name())
+
+	tracker.Record()
+
+	assert.Equal(t, 1, tracker.Count())
+	assert.True(t, tracker.Healthy())
+
+	result := tracker.Check(context.Background())
+	assert.True(t, result.Success)
+	assert.Equal(t, "1 recovered panics within 100ms", result.Message)
+
+	tracker.Record()
+
+	assert.Equal(t, 2, tracker.Count())
+	assert.False(t, tracker.Healthy())
+
+	result = tracker.Check(context.Background())
+	assert.False(t, result.Success)
+	assert.Equal(t, "2 recovered panics within 100ms, threshold is 2", result.Message)
+
+	// panics out of the window
+	time.Sleep(150 * time.Millisecond)
+
+	assert.Equal(t, 0, tracker.Count())
+	assert.True(t, tracker.Healthy())
+	assert.True(t, tracker.Check(context.Background()).Success)
+}
+
+func TestPanicTrackerWithoutThreshold(t *testing.T) {
+	t.Parallel()
+
+	tracker := httpserver.NewPanicTracker(0, time.Minute)
+
+	tracker.Record()
+	tracker.Record()
+
+	assert.Equal(t, 2, tracker.Count())
+	assert.True(t, tracker.Healthy())
+	assert.True(t, tracker.Check(context.Background()).Success)
+}
+
+func TestCreateWithPanicRecoveryStatusAndTracker(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	tracker := httpserver.NewPanicTracker(3, time.Minute)
+
+	httpServer, err := httpserver.NewDefaultHttpServerFactory().Create(
+		httpserver.WithLogger(httpserver.NewEchoLogger(logger)),
+		httpserver.WithRecovery(true),
+		httpserver.WithRecoveryStatus(http.StatusServiceUnavailable),
+		httpserver.WithPanicTracker(tracker),
+		httpserver.WithHttpErrorHandler(httpserver.JsonErrorHandler(true, false)),
+	)
+	assert.NoError(t, err)
+
+	httpServer.GET("/panic", func(c echo.Context) error {
+		panic("custom panic")
+	})
+
+	for i := 1; i <= 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, `{"message":"Service Unavailable"}`+"\n", rec.Body.String())
+		assert.Equal(t, i, tracker.Count())
+	}
+
+	assert.False(t, tracker.Check(context.Background()).Success)
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"message": "[PANIC RECOVER] custom panic",
+	})
+}
+
+func TestCreateWithPanicRecoveryDefaultStatus(t *testing.T) {
+	t.Parallel()
+
+	tracker := httpserver.NewPanicTracker(1, time.Minute)
+
+	httpServer, err := httpserver.NewDefaultHttpServerFactory().Create(
+		httpserver.WithPanicTracker(tracker),
+		httpserver.WithHttpErrorHandler(httpserver.JsonErrorHandler(false, false)),
+	)
+	assert.NoError(t, err)
+
+	httpServer.GET("/panic", func(c echo.Context) error {
+		panic("custom panic")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, `{"message":"custom panic"}`+"\n", rec.Body.String())
+	assert.Equal(t, 1, tracker.Count())
+	assert.False(t, tracker.Healthy())
+}
+
+func TestCreateWithPanicRecoveryStack(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer, err := httpserver.NewDefaultHttpServerFactory().Create(
+		httpserver.WithLogger(httpserver.NewEchoLogger(logger)),
+		httpserver.WithHttpErrorHandler(httpserver.JsonErrorHandler(false, true)),
+	)
+	assert.NoError(t, err)
+
+	httpServer.GET("/panic", func(c echo.Context) error {
+		panic("custom panic")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req = req.WithContext(logger.WithContext(context.Background()))
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	// the rendered stack includes the frames of the panic
+	var body struct {
+		Message string              `json:"message"`
+		Stack   []map[string]string `json:"stack"`
+	}
+
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "custom panic", body.Message)
+	assert.True(t, hasStackFrame(body.Stack, "TestCreateWithPanicRecoveryStack.func1", "recovery_test.go"))
+
+	// the logged stack too
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+
+	var loggedStack []map[string]string
+	for _, record := range records {
+		if message, _ := record.Message(); message == "error handler" {
+			stack, err := record.Attribute("stack")
+			assert.NoError(t, err)
+
+			encodedStack, err := json.Marshal(stack)
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(encodedStack, &loggedStack))
+		}
+	}
+
+	assert.Equal(t, body.Stack, loggedStack)
+}
+
+func hasStackFrame(stack []map[string]string, fn string, source string) bool {
+	for _, frame := range stack {
+		if frame["func"] == fn && frame["source"] == source && frame["line"] != "" {
+			return true
+		}
+	}
+
+	return false
+}
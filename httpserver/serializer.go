@@ -0,0 +1,111 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	goccyjson "github.com/goccy/go-json"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	Stdlib = "stdlib"
+	Goccy  = "goccy"
+)
+
+// JsonSerializer is an enum for the supported JSON serializers.
+type JsonSerializer int
+
+const (
+	StdlibJsonSerializer JsonSerializer = iota
+	GoccyJsonSerializer
+)
+
+// String returns a string representation of the [JsonSerializer].
+//
+//nolint:exhaustive
+func (s JsonSerializer) String() string {
+	switch s {
+	case GoccyJsonSerializer:
+		return Goccy
+	default:
+		return Stdlib
+	}
+}
+
+// FetchJsonSerializer returns a [JsonSerializer] for a given value.
+func FetchJsonSerializer(s string) JsonSerializer {
+	switch strings.ToLower(s) {
+	case Goccy:
+		return GoccyJsonSerializer
+	default:
+		return StdlibJsonSerializer
+	}
+}
+
+// NewJsonSerializer returns an [echo.JSONSerializer] implementation for a given [JsonSerializer].
+//
+//nolint:exhaustive
+func NewJsonSerializer(s JsonSerializer) echo.JSONSerializer {
+	switch s {
+	case GoccyJsonSerializer:
+		return &GoccyEchoJsonSerializer{}
+	default:
+		return &echo.DefaultJSONSerializer{}
+	}
+}
+
+// GoccyEchoJsonSerializer is an [echo.JSONSerializer] implementation based on [goccy/go-json].
+//
+// [goccy/go-json]: https://github.com/goccy/go-json
+type GoccyEchoJsonSerializer struct{}
+
+// Serialize converts an interface into a json and writes it to the response.
+func (s *GoccyEchoJsonSerializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	enc := goccyjson.NewEncoder(c.Response())
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
+
+	return enc.Encode(i)
+}
+
+// Deserialize reads a json from the request body and converts it into an interface.
+func (s *GoccyEchoJsonSerializer) Deserialize(c echo.Context, i interface{}) error {
+	err := goccyjson.NewDecoder(c.Request().Body).Decode(i)
+	if err == nil {
+		return nil
+	}
+
+	var ute *goccyjson.UnmarshalTypeError
+	if errors.As(err, &ute) {
+		return unmarshalTypeHttpError(ute.Type, ute.Value, ute.Field, ute.Offset, err)
+	}
+
+	var se *goccyjson.SyntaxError
+	if errors.As(err, &se) {
+		return syntaxHttpError(se.Offset, err)
+	}
+
+	return deserializationHttpError(err)
+}
+
+func unmarshalTypeHttpError(expected any, got string, field string, offset int64, err error) *echo.HTTPError {
+	return echo.NewHTTPError(
+		http.StatusBadRequest,
+		fmt.Sprintf("Unmarshal type error: expected=%v, got=%v, field=%v, offset=%v", expected, got, field, offset),
+	).SetInternal(err)
+}
+
+func syntaxHttpError(offset int64, err error) *echo.HTTPError {
+	return echo.NewHTTPError(
+		http.StatusBadRequest,
+		fmt.Sprintf("Syntax error: offset=%v, error=%v", offset, err.Error()),
+	).SetInternal(err)
+}
+
+func deserializationHttpError(err error) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+}
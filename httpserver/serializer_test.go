@@ -0,0 +1,189 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPayload struct {
+	Id       int               `json:"id"`
+	Name     string            `json:"name"`
+	Enabled  bool              `json:"enabled"`
+	Score    float64           `json:"score"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Children []testPayload     `json:"children,omitempty"`
+}
+
+var testPayloadValue = testPayload{
+	Id:      1,
+	Name:    "parent",
+	Enabled: true,
+	Score:   12.5,
+	Tags:    []string{"foo", "bar", "baz"},
+	Labels:  map[string]string{"env": "test", "team": "platform"},
+	Children: []testPayload{
+		{Id: 2, Name: "first child", Tags: []string{"foo"}, Labels: map[string]string{"env": "test"}},
+		{Id: 3, Name: "second child", Tags: []string{"bar"}, Labels: map[string]string{"env": "test"}},
+	},
+}
+
+var testSerializers = []httpserver.JsonSerializer{
+	httpserver.StdlibJsonSerializer,
+	httpserver.GoccyJsonSerializer,
+}
+
+func TestJsonSerializerAsString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, httpserver.Stdlib, httpserver.StdlibJsonSerializer.String())
+	assert.Equal(t, httpserver.Goccy, httpserver.GoccyJsonSerializer.String())
+}
+
+func TestFetchJsonSerializer(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, httpserver.StdlibJsonSerializer, httpserver.FetchJsonSerializer("stdlib"))
+	assert.Equal(t, httpserver.GoccyJsonSerializer, httpserver.FetchJsonSerializer("goccy"))
+	assert.Equal(t, httpserver.GoccyJsonSerializer, httpserver.FetchJsonSerializer("Goccy"))
+	assert.Equal(t, httpserver.StdlibJsonSerializer, httpserver.FetchJsonSerializer("invalid"))
+}
+
+func TestNewJsonSerializer(t *testing.T) {
+	t.Parallel()
+
+	assert.IsType(t, &echo.DefaultJSONSerializer{}, httpserver.NewJsonSerializer(httpserver.StdlibJsonSerializer))
+	assert.IsType(t, &httpserver.GoccyEchoJsonSerializer{}, httpserver.NewJsonSerializer(httpserver.GoccyJsonSerializer))
+}
+
+func TestJsonSerializerSerialize(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range testSerializers {
+		s := s
+
+		t.Run(s.String(), func(t *testing.T) {
+			t.Parallel()
+
+			httpServer := echo.New()
+			httpServer.JSONSerializer = httpserver.NewJsonSerializer(s)
+			httpServer.GET("/test", func(c echo.Context) error {
+				return c.JSON(http.StatusOK, testPayloadValue)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"name":"parent"`)
+			assert.Contains(t, rec.Body.String(), `"tags":["foo","bar","baz"]`)
+
+			req = httptest.NewRequest(http.MethodGet, "/test?pretty", nil)
+			rec = httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "\n  \"name\": \"parent\"")
+		})
+	}
+}
+
+func TestJsonSerializerDeserialize(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range testSerializers {
+		s := s
+
+		t.Run(s.String(), func(t *testing.T) {
+			t.Parallel()
+
+			httpServer := echo.New()
+			httpServer.JSONSerializer = httpserver.NewJsonSerializer(s)
+			httpServer.POST("/test", func(c echo.Context) error {
+				payload := new(testPayload)
+				if err := c.Bind(payload); err != nil {
+					return err
+				}
+
+				return c.String(http.StatusOK, payload.Name)
+			})
+
+			tests := []struct {
+				body         string
+				expectedCode int
+				expectedBody string
+			}{
+				{`{"id":1,"name":"valid"}`, http.StatusOK, "valid"},
+				{`{"id":1,"name":`, http.StatusBadRequest, `"message":`},
+				{`{"id":1,"name":"invalid",}`, http.StatusBadRequest, "Syntax error: offset="},
+				{`{"id":"1","name":"mismatch"}`, http.StatusBadRequest, "Unmarshal type error: expected=int"},
+			}
+
+			for _, tt := range tests {
+				req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(tt.body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				httpServer.ServeHTTP(rec, req)
+
+				assert.Equal(t, tt.expectedCode, rec.Code, tt.body)
+				assert.Contains(t, rec.Body.String(), tt.expectedBody, tt.body)
+			}
+		})
+	}
+}
+
+func BenchmarkJsonSerializerSerialize(b *testing.B) {
+	for _, s := range testSerializers {
+		serializer := httpserver.NewJsonSerializer(s)
+
+		b.Run(s.String(), func(b *testing.B) {
+			httpServer := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				c := httpServer.NewContext(req, httptest.NewRecorder())
+				if err := serializer.Serialize(c, testPayloadValue, ""); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkJsonSerializerDeserialize(b *testing.B) {
+	body := `{"id":1,"name":"parent","enabled":true,"score":12.5,"tags":["foo","bar","baz"],` +
+		`"labels":{"env":"test","team":"platform"},"children":[` +
+		`{"id":2,"name":"first child","tags":["foo"],"labels":{"env":"test"}},` +
+		`{"id":3,"name":"second child","tags":["bar"],"labels":{"env":"test"}}]}`
+
+	for _, s := range testSerializers {
+		serializer := httpserver.NewJsonSerializer(s)
+
+		b.Run(s.String(), func(b *testing.B) {
+			httpServer := echo.New()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+				c := httpServer.NewContext(req, httptest.NewRecorder())
+
+				payload := new(testPayload)
+				if err := serializer.Deserialize(c, payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	// MIMETextEventStream is the server-sent events MIME type.
+	MIMETextEventStream = "text/event-stream"
+	// DefaultSSEKeepAliveInterval is the default interval between server-sent events keep-alive comments.
+	DefaultSSEKeepAliveInterval = 15 * time.Second
+)
+
+// ErrSSEStreamClosed is returned when sending on a closed [SSEStream].
+var ErrSSEStreamClosed = errors.New("sse stream closed")
+
+// SSEOptions are options for the [SSEStream].
+type SSEOptions struct {
+	KeepAliveInterval time.Duration
+	CloseEvent        string
+	CloseData         string
+}
+
+// DefaultSSEOptions are the default options used in the [SSEStream].
+func DefaultSSEOptions() SSEOptions {
+	return SSEOptions{
+		KeepAliveInterval: DefaultSSEKeepAliveInterval,
+		CloseEvent:        "",
+		CloseData:         "",
+	}
+}
+
+// SSEOption are functional options for the [SSEStream].
+type SSEOption func(o *SSEOptions)
+
+// WithSSEKeepAliveInterval is used to specify the interval between keep-alive comments (0 to disable them).
+func WithSSEKeepAliveInterval(interval time.Duration) SSEOption {
+	return func(o *SSEOptions) {
+		o.KeepAliveInterval = interval
+	}
+}
+
+// WithSSECloseEvent is used to specify a final event to send to the client when the server shuts down.
+func WithSSECloseEvent(event string, data string) SSEOption {
+	return func(o *SSEOptions) {
+		o.CloseEvent = event
+		o.CloseData = data
+	}
+}
+
+// SSEStream is a server-sent events stream, sending keep-alive comments and closing gracefully on server shutdown.
+type SSEStream struct {
+	writer   *SSEWriter
+	options  SSEOptions
+	notifier *sseShutdownNotifier
+	mutex    sync.Mutex
+	closed   bool
+	done     chan struct{}
+}
+
+// NewSSEStream starts a new [SSEStream] on the response of the provided [echo.Context].
+// The handler should wait on [SSEStream.Done] (or return when it has nothing left to send) and always [SSEStream.Close] the stream.
+func NewSSEStream(c echo.Context, options ...SSEOption) (*SSEStream, error) {
+	appliedOpts := DefaultSSEOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	writer, err := NewSSEWriter(c)
+	if err != nil {
+		return nil, fmt.Errorf("cannot start sse stream: %w", err)
+	}
+
+	stream := &SSEStream{
+		writer:  writer,
+		options: appliedOpts,
+		done:    make(chan struct{}),
+	}
+
+	if c.Echo() != nil {
+		stream.notifier = sseShutdownNotifierFor(c.Echo())
+		stream.notifier.add(stream)
+	}
+
+	go stream.watch(c.Request().Context().Done())
+
+	return stream, nil
+}
+
+// Send sends an event, with its data (split on new lines), to the client.
+func (s *SSEStream) Send(event string, data string) error {
+	return s.write(formatSSEEvent(SSEEvent{
+		Event: event,
+		Data:  data,
+	}))
+}
+
+// Done returns a channel closed when the stream is closed: on client disconnection, server shutdown or [SSEStream.Close].
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close closes the stream, further sends will return [ErrSSEStreamClosed].
+func (s *SSEStream) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.close()
+}
+
+func (s *SSEStream) write(payload string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return ErrSSEStreamClosed
+	}
+
+	err := s.writer.write(payload)
+	if err != nil {
+		s.close()
+	}
+
+	return err
+}
+
+func (s *SSEStream) watch(requestDone <-chan struct{}) {
+	var ticks <-chan time.Time
+	if s.options.KeepAliveInterval > 0 {
+		ticker := time.NewTicker(s.options.KeepAliveInterval)
+		defer ticker.Stop()
+
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-requestDone:
+			s.Close()
+
+			return
+		case <-ticks:
+			//nolint:errcheck
+			s.write(": ping\n\n")
+		}
+	}
+}
+
+func (s *SSEStream) shutdown() {
+	if s.options.CloseEvent != "" {
+		//nolint:errcheck
+		s.Send(s.options.CloseEvent, s.options.CloseData)
+	}
+
+	s.Close()
+}
+
+func (s *SSEStream) close() {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.done)
+
+	if s.notifier != nil {
+		s.notifier.remove(s)
+	}
+}
+
+// IsSSERequest returns true if the request accepts a server-sent events stream.
+func IsSSERequest(c echo.Context) bool {
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), MIMETextEventStream)
+}
+
+// SSESkipper returns a [middleware.Skipper] skipping server-sent events requests, to exclude them from the middlewares
+// not compatible with streaming (like timeout or gzip), and delegating to an optional skipper for other requests.
+func SSESkipper(skipper middleware.Skipper) middleware.Skipper {
+	if skipper == nil {
+		skipper = middleware.DefaultSkipper
+	}
+
+	return func(c echo.Context) bool {
+		return IsSSERequest(c) || skipper(c)
+	}
+}
+
+var sseShutdownNotifiers sync.Map
+
+type sseShutdownNotifier struct {
+	mutex   sync.Mutex
+	streams map[*SSEStream]struct{}
+}
+
+func sseShutdownNotifierFor(e *echo.Echo) *sseShutdownNotifier {
+	notifier, loaded := sseShutdownNotifiers.LoadOrStore(e, &sseShutdownNotifier{
+		streams: map[*SSEStream]struct{}{},
+	})
+
+	//nolint:forcetypeassert
+	n := notifier.(*sseShutdownNotifier)
+
+	if !loaded {
+		for _, server := range []*http.Server{e.Server, e.TLSServer} {
+			if server != nil {
+				server.RegisterOnShutdown(n.shutdown)
+			}
+		}
+	}
+
+	return n
+}
+
+func (n *sseShutdownNotifier) add(s *SSEStream) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.streams[s] = struct{}{}
+}
+
+func (n *sseShutdownNotifier) remove(s *SSEStream) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	delete(n.streams, s)
+}
+
+func (n *sseShutdownNotifier) shutdown() {
+	n.mutex.Lock()
+	streams := make([]*SSEStream, 0, len(n.streams))
+	for s := range n.streams {
+		streams = append(streams, s)
+	}
+	n.mutex.Unlock()
+
+	for _, s := range streams {
+		s.shutdown()
+	}
+}
@@ -0,0 +1,182 @@
+package httpserver_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func readSSELines(t *testing.T, scanner *bufio.Scanner, until func(lines []string) bool) []string {
+	t.Helper()
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+
+		if until(lines) {
+			break
+		}
+	}
+
+	return lines
+}
+
+func TestDefaultSSEOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := httpserver.DefaultSSEOptions()
+
+	assert.Equal(t, httpserver.DefaultSSEKeepAliveInterval, opts.KeepAliveInterval)
+	assert.Equal(t, "", opts.CloseEvent)
+	assert.Equal(t, "", opts.CloseData)
+}
+
+func TestSSEStream(t *testing.T) {
+	t.Parallel()
+
+	closed := make(chan struct{})
+
+	httpServer := echo.New()
+	httpServer.GET("/sse", func(c echo.Context) error {
+		stream, err := httpserver.NewSSEStream(c, httpserver.WithSSEKeepAliveInterval(20*time.Millisecond))
+		if err != nil {
+			return err
+		}
+		defer close(closed)
+		defer stream.Close()
+
+		err = stream.Send("progress", "10")
+		if err != nil {
+			return err
+		}
+
+		err = stream.Send("progress", "50\n100")
+		if err != nil {
+			return err
+		}
+
+		<-stream.Done()
+
+		assert.ErrorIs(t, stream.Send("progress", "too late"), httpserver.ErrSSEStreamClosed)
+
+		return nil
+	})
+
+	testServer := httptest.NewServer(httpServer)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, testServer.URL+"/sse", nil)
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderAccept, httpserver.MIMETextEventStream)
+
+	resp, err := testServer.Client().Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, httpserver.MIMETextEventStream, resp.Header.Get(echo.HeaderContentType))
+	assert.Equal(t, "no-cache", resp.Header.Get(echo.HeaderCacheControl))
+
+	lines := readSSELines(t, bufio.NewScanner(resp.Body), func(lines []string) bool {
+		return lines[len(lines)-1] == ": ping"
+	})
+
+	assert.Equal(
+		t,
+		"event: progress\ndata: 10\n\nevent: progress\ndata: 50\ndata: 100\n\n: ping",
+		strings.Join(lines, "\n"),
+	)
+
+	// client disconnection
+	cancel()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Error("sse stream was not closed on client disconnection")
+	}
+}
+
+func TestSSEStreamOnServerShutdown(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.GET("/sse", func(c echo.Context) error {
+		stream, err := httpserver.NewSSEStream(
+			c,
+			httpserver.WithSSEKeepAliveInterval(0),
+			httpserver.WithSSECloseEvent("shutdown", "bye"),
+		)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		err = stream.Send("", "hello")
+		if err != nil {
+			return err
+		}
+
+		<-stream.Done()
+
+		return nil
+	})
+
+	testServer := httptest.NewServer(httpServer)
+	defer testServer.Close()
+
+	resp, err := testServer.Client().Get(testServer.URL + "/sse")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+
+	lines := readSSELines(t, scanner, func(lines []string) bool {
+		return lines[len(lines)-1] == ""
+	})
+	assert.Equal(t, []string{"data: hello", ""}, lines)
+
+	err = httpServer.Shutdown(context.Background())
+	assert.NoError(t, err)
+
+	lines = readSSELines(t, scanner, func(lines []string) bool {
+		return lines[len(lines)-1] == ""
+	})
+	assert.Equal(t, []string{"event: shutdown", "data: bye", ""}, lines)
+}
+
+func TestSSESkipper(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+
+	sseReq := httptest.NewRequest(http.MethodGet, "/sse", nil)
+	sseReq.Header.Set(echo.HeaderAccept, httpserver.MIMETextEventStream)
+	sseCtx := httpServer.NewContext(sseReq, httptest.NewRecorder())
+
+	otherReq := httptest.NewRequest(http.MethodGet, "/other", nil)
+	otherCtx := httpServer.NewContext(otherReq, httptest.NewRecorder())
+
+	assert.True(t, httpserver.IsSSERequest(sseCtx))
+	assert.False(t, httpserver.IsSSERequest(otherCtx))
+
+	skipper := httpserver.SSESkipper(nil)
+	assert.True(t, skipper(sseCtx))
+	assert.False(t, skipper(otherCtx))
+
+	skipper = httpserver.SSESkipper(func(c echo.Context) bool {
+		return c.Request().URL.Path == "/other"
+	})
+	assert.True(t, skipper(sseCtx))
+	assert.True(t, skipper(otherCtx))
+}
@@ -0,0 +1,119 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SSEEvent is a server-sent event, see the [event stream format].
+//
+// [event stream format]: https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+type SSEEvent struct {
+	ID    string        // event id, sent back by the client in the Last-Event-ID header on reconnection
+	Event string        // event type, the default "message" one if empty
+	Data  string        // event data, split on new lines
+	Retry time.Duration // client reconnection delay, not sent if zero
+}
+
+// SSEWriter writes server-sent events on the response of a request, flushing them to the client one by one.
+//
+// Unlike the [SSEStream], it sends no keep-alive comments and is not closed on server shutdown: it is bound to the
+// request context, canceled on client disconnection.
+type SSEWriter struct {
+	ctx        context.Context
+	response   *echo.Response
+	controller *http.ResponseController
+	mutex      sync.Mutex
+}
+
+// NewSSEWriter sets the server-sent events headers on the response of the provided [echo.Context], flushes them, and
+// returns a [SSEWriter] bound to the request context.
+func NewSSEWriter(c echo.Context) (*SSEWriter, error) {
+	res := c.Response()
+
+	res.Header().Set(echo.HeaderContentType, MIMETextEventStream)
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	writer := &SSEWriter{
+		ctx:        c.Request().Context(),
+		response:   res,
+		controller: http.NewResponseController(res.Writer),
+	}
+
+	err := writer.controller.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("cannot flush sse writer: %w", err)
+	}
+
+	return writer, nil
+}
+
+// Context returns the request context of the [SSEWriter], done on client disconnection.
+func (w *SSEWriter) Context() context.Context {
+	return w.ctx
+}
+
+// Done returns a channel closed on client disconnection (or when the request context is done).
+func (w *SSEWriter) Done() <-chan struct{} {
+	return w.ctx.Done()
+}
+
+// Send writes an event and flushes it to the client. It returns the request context error once the client is gone,
+// without writing the event.
+func (w *SSEWriter) Send(event SSEEvent) error {
+	return w.write(formatSSEEvent(event))
+}
+
+// Comment writes a comment (ignored by the clients, like a keep-alive) and flushes it to the client.
+func (w *SSEWriter) Comment(comment string) error {
+	return w.write(fmt.Sprintf(": %s\n\n", comment))
+}
+
+func (w *SSEWriter) write(payload string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := w.response.Write([]byte(payload))
+	if err != nil {
+		return err
+	}
+
+	return w.controller.Flush()
+}
+
+// formatSSEEvent formats an event in the event stream format.
+func formatSSEEvent(event SSEEvent) string {
+	var builder strings.Builder
+
+	if event.ID != "" {
+		builder.WriteString(fmt.Sprintf("id: %s\n", event.ID))
+	}
+
+	if event.Event != "" {
+		builder.WriteString(fmt.Sprintf("event: %s\n", event.Event))
+	}
+
+	if event.Retry > 0 {
+		builder.WriteString(fmt.Sprintf("retry: %d\n", event.Retry.Milliseconds()))
+	}
+
+	for _, line := range strings.Split(event.Data, "\n") {
+		builder.WriteString(fmt.Sprintf("data: %s\n", line))
+	}
+
+	builder.WriteString("\n")
+
+	return builder.String()
+}
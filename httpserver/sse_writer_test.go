@@ -0,0 +1,143 @@
+package httpserver_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/testdata/handler"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEWriter(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.GET("/countdown", handler.CountdownHandler(2, time.Millisecond))
+
+	testServer := httptest.NewServer(httpServer)
+	defer testServer.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, testServer.URL+"/countdown", nil)
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderAccept, httpserver.MIMETextEventStream)
+
+	resp, err := testServer.Client().Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, httpserver.MIMETextEventStream, resp.Header.Get(echo.HeaderContentType))
+	assert.Equal(t, "no-cache", resp.Header.Get(echo.HeaderCacheControl))
+	assert.Equal(t, "no", resp.Header.Get("X-Accel-Buffering"))
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	assert.Equal(
+		t,
+		[]string{
+			"id: 0", "event: countdown", "data: 2", "",
+			"id: 1", "event: countdown", "data: 1", "",
+			"id: 2", "event: countdown", "data: 0", "",
+			"event: done", "data: liftoff", "",
+		},
+		lines,
+	)
+}
+
+func TestSSEWriterFormat(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+
+	rec := httptest.NewRecorder()
+	c := httpServer.NewContext(httptest.NewRequest(http.MethodGet, "/sse", nil), rec)
+
+	writer, err := httpserver.NewSSEWriter(c)
+	assert.NoError(t, err)
+	assert.True(t, rec.Flushed)
+
+	err = writer.Send(httpserver.SSEEvent{
+		ID:    "1",
+		Event: "progress",
+		Data:  "50\n100",
+		Retry: 2 * time.Second,
+	})
+	assert.NoError(t, err)
+
+	err = writer.Comment("ping")
+	assert.NoError(t, err)
+
+	err = writer.Send(httpserver.SSEEvent{
+		Data: "message",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"id: 1\nevent: progress\nretry: 2000\ndata: 50\ndata: 100\n\n: ping\n\ndata: message\n\n",
+		rec.Body.String(),
+	)
+}
+
+func TestSSEWriterWithClientDisconnection(t *testing.T) {
+	t.Parallel()
+
+	sent := make(chan struct{})
+	result := make(chan error, 1)
+
+	httpServer := echo.New()
+	httpServer.GET("/sse", func(c echo.Context) error {
+		writer, err := httpserver.NewSSEWriter(c)
+		if err != nil {
+			return err
+		}
+
+		err = writer.Send(httpserver.SSEEvent{Data: "first"})
+		assert.NoError(t, err)
+		close(sent)
+
+		<-writer.Done()
+
+		result <- writer.Send(httpserver.SSEEvent{Data: "too late"})
+
+		return nil
+	})
+
+	testServer := httptest.NewServer(httpServer)
+	defer testServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, testServer.URL+"/sse", nil)
+	assert.NoError(t, err)
+
+	resp, err := testServer.Client().Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	lines := readSSELines(t, scanner, func(lines []string) bool {
+		return len(lines) == 2
+	})
+	assert.Equal(t, []string{"data: first", ""}, lines)
+
+	<-sent
+	cancel()
+
+	select {
+	case err = <-result:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("client disconnection not detected")
+	}
+}
@@ -0,0 +1,77 @@
+package httpserver
+
+import (
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+var streamingRoutesRegistries sync.Map
+
+// streamingRoutes are the routes of an [echo.Echo] marked as streaming, by route key (see [RouteKey]).
+type streamingRoutes struct {
+	mutex  sync.RWMutex
+	routes map[string]struct{}
+}
+
+func streamingRoutesFor(e *echo.Echo) *streamingRoutes {
+	routes, _ := streamingRoutesRegistries.LoadOrStore(e, &streamingRoutes{
+		routes: map[string]struct{}{},
+	})
+
+	//nolint:forcetypeassert
+	return routes.(*streamingRoutes)
+}
+
+// MarkStreamingRoutes marks routes of the provided [echo.Echo] as streaming (server-sent events or websocket ones),
+// to exclude their requests from the middlewares not compatible with streaming (like timeout), see [IsStreamingRequest].
+func MarkStreamingRoutes(e *echo.Echo, routes ...*echo.Route) {
+	registry := streamingRoutesFor(e)
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	for _, route := range routes {
+		if route != nil {
+			registry.routes[RouteKey(route.Method, route.Path)] = struct{}{}
+		}
+	}
+}
+
+// IsStreamingRequest returns true if the request matched a route marked as streaming with [MarkStreamingRoutes].
+//
+// Unlike [IsSSERequest] and [IsWebSocketRequest], it does not rely on the request headers, that can be set by any
+// client: it should be preferred to exempt requests from server protections (like timeout).
+func IsStreamingRequest(c echo.Context) bool {
+	if c.Echo() == nil || c.Path() == "" {
+		return false
+	}
+
+	registry, ok := streamingRoutesRegistries.Load(c.Echo())
+	if !ok {
+		return false
+	}
+
+	//nolint:forcetypeassert
+	routes := registry.(*streamingRoutes)
+
+	routes.mutex.RLock()
+	defer routes.mutex.RUnlock()
+
+	_, ok = routes.routes[RouteKey(c.Request().Method, c.Path())]
+
+	return ok
+}
+
+// StreamingSkipper returns a [middleware.Skipper] skipping the requests of the routes marked as streaming with
+// [MarkStreamingRoutes], and delegating to an optional skipper for other requests.
+func StreamingSkipper(skipper middleware.Skipper) middleware.Skipper {
+	if skipper == nil {
+		skipper = middleware.DefaultSkipper
+	}
+
+	return func(c echo.Context) bool {
+		return IsStreamingRequest(c) || skipper(c)
+	}
+}
@@ -0,0 +1,79 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStreamingRequest(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+
+	var streaming []bool
+	handler := func(c echo.Context) error {
+		streaming = append(streaming, httpserver.IsStreamingRequest(c))
+
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	route := httpServer.GET("/stream", handler)
+	httpServer.POST("/stream", handler)
+	httpServer.GET("/other", handler)
+
+	httpserver.MarkStreamingRoutes(httpServer, route)
+
+	for _, r := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/stream"},
+		{http.MethodPost, "/stream"},
+		{http.MethodGet, "/other"},
+	} {
+		req := httptest.NewRequest(r.method, r.path, nil)
+		req.Header.Set(echo.HeaderAccept, httpserver.MIMETextEventStream)
+		httpServer.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, []bool{true, false, false}, streaming)
+
+	// routes of other servers are not marked
+	otherServer := echo.New()
+	otherServer.GET("/stream", handler)
+
+	otherServer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+	assert.False(t, streaming[3])
+}
+
+func TestStreamingSkipper(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	route := httpServer.GET("/stream", func(c echo.Context) error {
+		return nil
+	})
+
+	httpserver.MarkStreamingRoutes(httpServer, route)
+
+	streamCtx := httpServer.NewContext(httptest.NewRequest(http.MethodGet, "/stream", nil), httptest.NewRecorder())
+	streamCtx.SetPath("/stream")
+
+	otherCtx := httpServer.NewContext(httptest.NewRequest(http.MethodGet, "/other", nil), httptest.NewRecorder())
+	otherCtx.SetPath("/other")
+
+	skipper := httpserver.StreamingSkipper(nil)
+	assert.True(t, skipper(streamCtx))
+	assert.False(t, skipper(otherCtx))
+
+	skipper = httpserver.StreamingSkipper(func(c echo.Context) bool {
+		return c.Path() == "/other"
+	})
+	assert.True(t, skipper(streamCtx))
+	assert.True(t, skipper(otherCtx))
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+)
+
+// CountdownHandler streams a countdown as server-sent events, one event per interval, until zero or until the client
+// disconnects.
+func CountdownHandler(from int, interval time.Duration) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		writer, err := httpserver.NewSSEWriter(c)
+		if err != nil {
+			return err
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for i := from; i >= 0; i-- {
+			err = writer.Send(httpserver.SSEEvent{
+				ID:    strconv.Itoa(from - i),
+				Event: "countdown",
+				Data:  strconv.Itoa(i),
+			})
+			if err != nil {
+				// client gone
+				return nil
+			}
+
+			select {
+			case <-writer.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+
+		return writer.Send(httpserver.SSEEvent{
+			Event: "done",
+			Data:  "liftoff",
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CtxUploadedFilesKey is a contextual struct key.
+type CtxUploadedFilesKey struct{}
+
+// UploadedFile is a file uploaded in a multipart request, handled by the UploadsMiddleware.
+type UploadedFile struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Header      textproto.MIMEHeader
+	Size        int64
+	Content     []byte
+	Path        string
+}
+
+// Open opens the uploaded file content, from memory or from its temporary file.
+func (f *UploadedFile) Open() (io.ReadCloser, error) {
+	if f.Path != "" {
+		return os.Open(f.Path)
+	}
+
+	return io.NopCloser(bytes.NewReader(f.Content)), nil
+}
+
+// CtxUploadedFiles returns the contextual uploaded files, indexed by form field.
+func CtxUploadedFiles(c echo.Context) map[string][]*UploadedFile {
+	if files, ok := c.Request().Context().Value(CtxUploadedFilesKey{}).(map[string][]*UploadedFile); ok {
+		return files
+	} else {
+		return map[string][]*UploadedFile{}
+	}
+}
+
+// CtxUploadedFile returns the first contextual uploaded file for a form field.
+func CtxUploadedFile(c echo.Context, field string) (*UploadedFile, error) {
+	files := CtxUploadedFiles(c)[field]
+	if len(files) == 0 {
+		return nil, fmt.Errorf("cannot find uploaded file for field %s: %w", field, http.ErrMissingFile)
+	}
+
+	return files[0], nil
+}
@@ -0,0 +1,65 @@
+package httpserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadedFileOpen(t *testing.T) {
+	t.Parallel()
+
+	// in memory
+	file := &httpserver.UploadedFile{Content: []byte("in memory")}
+
+	reader, err := file.Open()
+	assert.NoError(t, err)
+
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "in memory", string(content))
+
+	// on disk
+	path := filepath.Join(t.TempDir(), "upload")
+	assert.NoError(t, os.WriteFile(path, []byte("on disk"), 0o600))
+
+	file = &httpserver.UploadedFile{Path: path}
+
+	reader, err = file.Open()
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	content, err = io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "on disk", string(content))
+}
+
+func TestCtxUploadedFile(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	_, err := httpserver.CtxUploadedFile(c, "file")
+	assert.ErrorIs(t, err, http.ErrMissingFile)
+	assert.Equal(t, "cannot find uploaded file for field file: http: no such file", err.Error())
+
+	file := &httpserver.UploadedFile{Field: "file", Filename: "foo.txt"}
+	files := map[string][]*httpserver.UploadedFile{"file": {file}}
+
+	c.SetRequest(req.WithContext(context.WithValue(req.Context(), httpserver.CtxUploadedFilesKey{}, files)))
+
+	assert.Equal(t, files, httpserver.CtxUploadedFiles(c))
+
+	uploaded, err := httpserver.CtxUploadedFile(c, "file")
+	assert.NoError(t, err)
+	assert.Equal(t, file, uploaded)
+}
@@ -0,0 +1,45 @@
+package httpserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CtxBaseUrlKey is a contextual struct key.
+type CtxBaseUrlKey struct{}
+
+// CtxBaseUrl returns the contextual external base url.
+func CtxBaseUrl(c echo.Context) string {
+	if baseUrl, ok := c.Request().Context().Value(CtxBaseUrlKey{}).(string); ok {
+		return baseUrl
+	} else {
+		return ""
+	}
+}
+
+// URL returns the url of a named route, with its path params replaced by the provided params.
+// The url is absolute if an external base url is set in the context (see the BaseUrlMiddleware), relative otherwise.
+func URL(c echo.Context, name string, params ...interface{}) (string, error) {
+	found := false
+	for _, route := range c.Echo().Routes() {
+		if route.Name == name {
+			found = true
+
+			break
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("cannot find route named %s", name)
+	}
+
+	path := c.Echo().Reverse(name, params...)
+
+	if baseUrl := CtxBaseUrl(c); baseUrl != "" {
+		return strings.TrimRight(baseUrl, "/") + path, nil
+	}
+
+	return path, nil
+}
@@ -0,0 +1,68 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		baseUrl     string
+		routeName   string
+		params      []interface{}
+		expectedUrl string
+		expectedErr string
+	}{
+		{"relative without params", "", "user.list", nil, "/users", ""},
+		{"relative with params", "", "user.show", []interface{}{42, "posts"}, "/users/42/posts", ""},
+		{"absolute without params", "https://example.com", "user.list", nil, "https://example.com/users", ""},
+		{"absolute with params", "https://example.com/", "user.show", []interface{}{42, "posts"}, "https://example.com/users/42/posts", ""},
+		{"unknown route", "", "user.unknown", nil, "", "cannot find route named user.unknown"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := func(c echo.Context) error {
+				url, err := httpserver.URL(c, tt.routeName, tt.params...)
+				if err != nil {
+					return c.String(http.StatusInternalServerError, err.Error())
+				}
+
+				return c.String(http.StatusOK, url)
+			}
+
+			httpServer := echo.New()
+			if tt.baseUrl != "" {
+				httpServer.Use(middleware.BaseUrlMiddleware(tt.baseUrl))
+			}
+
+			httpServer.GET("/users", handler).Name = "user.list"
+			httpServer.GET("/users/:id/:section", handler).Name = "user.show"
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			if tt.expectedErr != "" {
+				assert.Equal(t, http.StatusInternalServerError, rec.Code)
+				assert.Equal(t, tt.expectedErr, rec.Body.String())
+			} else {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Equal(t, tt.expectedUrl, rec.Body.String())
+			}
+		})
+	}
+}
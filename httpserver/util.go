@@ -1,9 +1,15 @@
 package httpserver
 
 import (
+	"fmt"
 	"strings"
+
+	"github.com/labstack/echo/v4"
 )
 
+// CtxRouteNameKey is a contextual key.
+const CtxRouteNameKey = "httpserver-route-name"
+
 // MatchPrefix returns true if a given prefix matches an item of a given prefixes list.
 func MatchPrefix(prefixes []string, str string) bool {
 	for _, prefix := range prefixes {
@@ -14,3 +20,32 @@ func MatchPrefix(prefixes []string, str string) bool {
 
 	return false
 }
+
+// RouteKey returns a route identifier, for a given http method and route path template.
+func RouteKey(method string, path string) string {
+	return fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+}
+
+// RouteName returns the name of the route matched by the request (the handler function name, if the route was not
+// explicitly named), or an empty string if no route matched.
+func RouteName(c echo.Context) string {
+	if name, ok := c.Get(CtxRouteNameKey).(string); ok {
+		return name
+	}
+
+	return routeName(c.Echo().Routes(), c.Request().Method, c.Path())
+}
+
+func routeName(routes []*echo.Route, method string, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	for _, route := range routes {
+		if route.Method == method && route.Path == path {
+			return route.Name
+		}
+	}
+
+	return ""
+}
@@ -1,9 +1,12 @@
 package httpserver_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,3 +33,39 @@ func TestMatchPrefix(t *testing.T) {
 	assert.False(t, httpserver.MatchPrefix(prefixes, "/ba/bar"))
 	assert.False(t, httpserver.MatchPrefix(prefixes, "/baz"))
 }
+
+func TestRouteKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "GET /foo/:id", httpserver.RouteKey("get", "/foo/:id"))
+	assert.Equal(t, "POST /bar", httpserver.RouteKey("POST", "/bar"))
+}
+
+func TestRouteName(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.GET("/foo/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, httpserver.RouteName(c))
+	}).Name = "foo.show"
+
+	var notFoundRouteName string
+	httpServer.RouteNotFound("/*", func(c echo.Context) error {
+		notFoundRouteName = httpserver.RouteName(c)
+
+		return c.NoContent(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/foo/1", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, "foo.show", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/bar", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "", notFoundRouteName)
+}
@@ -0,0 +1,158 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// Validation is a custom validation, registered for a tag on the [EchoValidator].
+type Validation struct {
+	Tag  string
+	Func validator.Func
+}
+
+// NewValidation returns a new [Validation].
+func NewValidation(tag string, fn validator.Func) *Validation {
+	return &Validation{
+		Tag:  tag,
+		Func: fn,
+	}
+}
+
+// ValidationViolation is a field level validation violation.
+type ValidationViolation struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError is an error holding the field level [ValidationViolation] list.
+type ValidationError struct {
+	Violations []ValidationViolation
+}
+
+// Error returns the error message.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+
+	return fmt.Sprintf("validation failed: %s", strings.Join(messages, ", "))
+}
+
+// EchoValidator is an [echo.Validator] implementation based on [go-playground/validator].
+//
+// [go-playground/validator]: https://github.com/go-playground/validator
+type EchoValidator struct {
+	validate *validator.Validate
+}
+
+// NewEchoValidator returns a new [EchoValidator], with optional custom [Validation].
+func NewEchoValidator(validations ...*Validation) (*EchoValidator, error) {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "param", "form"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+
+			if name != "" {
+				return name
+			}
+		}
+
+		return field.Name
+	})
+
+	for _, validation := range validations {
+		err := validate.RegisterValidation(validation.Tag, validation.Func)
+		if err != nil {
+			return nil, fmt.Errorf("cannot register validation %s: %w", validation.Tag, err)
+		}
+	}
+
+	return &EchoValidator{
+		validate: validate,
+	}, nil
+}
+
+// Validator returns the underlying [validator.Validate].
+func (v *EchoValidator) Validator() *validator.Validate {
+	return v.validate
+}
+
+// Validate validates the provided struct, and returns a 422 [echo.HTTPError] wrapping a [ValidationError] on violations.
+func (v *EchoValidator) Validate(i interface{}) error {
+	err := v.validate.Struct(i)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	validationError := &ValidationError{
+		Violations: make([]ValidationViolation, len(validationErrors)),
+	}
+
+	for i, fieldError := range validationErrors {
+		field := fieldError.Namespace()
+		if _, after, found := strings.Cut(field, "."); found {
+			field = after
+		}
+
+		message := fmt.Sprintf("%s failed on the %s validation", field, fieldError.Tag())
+		if fieldError.Param() != "" {
+			message = fmt.Sprintf("%s failed on the %s=%s validation", field, fieldError.Tag(), fieldError.Param())
+		}
+
+		validationError.Violations[i] = ValidationViolation{
+			Field:   field,
+			Tag:     fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: message,
+		}
+	}
+
+	return echo.NewHTTPError(http.StatusUnprocessableEntity, "validation failed").SetInternal(validationError)
+}
+
+// BindAndValidate binds the request path params, query params and body into a new T, and validates it.
+// It requires an [echo.Validator] to be registered on the server, like the [EchoValidator].
+func BindAndValidate[T any](c echo.Context) (*T, error) {
+	t := new(T)
+
+	err := c.Bind(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// echo only binds query params for GET, DELETE and HEAD requests
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodDelete, http.MethodHead:
+	default:
+		err = (&echo.DefaultBinder{}).BindQueryParams(c, t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = c.Validate(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
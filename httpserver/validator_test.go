@@ -0,0 +1,194 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testValidationRequest struct {
+	Id    int    `param:"id" validate:"gt=0"`
+	Page  int    `query:"page" validate:"omitempty,min=1"`
+	Name  string `json:"name" validate:"required,max=10"`
+	Email string `json:"email" validate:"omitempty,email"`
+	Color string `json:"color" validate:"omitempty,color"`
+}
+
+func newTestValidationServer(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	echoValidator, err := httpserver.NewEchoValidator(
+		httpserver.NewValidation("color", func(fl validator.FieldLevel) bool {
+			return fl.Field().String() == "red" || fl.Field().String() == "blue"
+		}),
+	)
+	assert.NoError(t, err)
+
+	httpServer, err := httpserver.NewDefaultHttpServerFactory().Create(
+		httpserver.WithValidator(echoValidator),
+		httpserver.WithHttpErrorHandler(httpserver.JsonErrorHandler(true, false)),
+	)
+	assert.NoError(t, err)
+
+	httpServer.POST("/test/:id", func(c echo.Context) error {
+		req, err := httpserver.BindAndValidate[testValidationRequest](c)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, req)
+	})
+
+	return httpServer
+}
+
+func TestNewEchoValidator(t *testing.T) {
+	t.Parallel()
+
+	echoValidator, err := httpserver.NewEchoValidator()
+	assert.NoError(t, err)
+
+	assert.IsType(t, &httpserver.EchoValidator{}, echoValidator)
+	assert.Implements(t, (*echo.Validator)(nil), echoValidator)
+	assert.NotNil(t, echoValidator.Validator())
+}
+
+func TestNewEchoValidatorWithInvalidValidation(t *testing.T) {
+	t.Parallel()
+
+	_, err := httpserver.NewEchoValidator(httpserver.NewValidation("", nil))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot register validation")
+}
+
+func TestValidationError(t *testing.T) {
+	t.Parallel()
+
+	err := &httpserver.ValidationError{
+		Violations: []httpserver.ValidationViolation{
+			{Field: "foo", Tag: "required", Message: "foo failed on the required validation"},
+			{Field: "bar", Tag: "max", Param: "10", Message: "bar failed on the max=10 validation"},
+		},
+	}
+
+	assert.Equal(
+		t,
+		"validation failed: foo failed on the required validation, bar failed on the max=10 validation",
+		err.Error(),
+	)
+}
+
+func TestBindAndValidateSuccess(t *testing.T) {
+	t.Parallel()
+
+	httpServer := newTestValidationServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/test/12?page=3", strings.NewReader(`{"name":"foo","color":"red"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"Id":12`)
+	assert.Contains(t, rec.Body.String(), `"Page":3`)
+	assert.Contains(t, rec.Body.String(), `"name":"foo"`)
+}
+
+func TestBindAndValidateBindingFailures(t *testing.T) {
+	t.Parallel()
+
+	httpServer := newTestValidationServer(t)
+
+	tests := []struct {
+		name string
+		url  string
+		body string
+	}{
+		{"path", "/test/invalid", `{"name":"foo"}`},
+		{"query", "/test/12?page=invalid", `{"name":"foo"}`},
+		{"body", "/test/12", `{"name":`},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, tt.name)
+		assert.NotContains(t, rec.Body.String(), "violations", tt.name)
+	}
+}
+
+func TestBindAndValidateValidationFailures(t *testing.T) {
+	t.Parallel()
+
+	httpServer := newTestValidationServer(t)
+
+	tests := []struct {
+		name              string
+		url               string
+		body              string
+		expectedViolation string
+	}{
+		{
+			"path",
+			"/test/0",
+			`{"name":"foo"}`,
+			`{"field":"id","tag":"gt","param":"0","message":"id failed on the gt=0 validation"}`,
+		},
+		{
+			"query",
+			"/test/12?page=-1",
+			`{"name":"foo"}`,
+			`{"field":"page","tag":"min","param":"1","message":"page failed on the min=1 validation"}`,
+		},
+		{
+			"body",
+			"/test/12",
+			`{"email":"invalid"}`,
+			`{"field":"name","tag":"required","message":"name failed on the required validation"}`,
+		},
+		{
+			"custom",
+			"/test/12",
+			`{"name":"foo","color":"green"}`,
+			`{"field":"color","tag":"color","message":"color failed on the color validation"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, tt.name)
+		assert.Contains(t, rec.Body.String(), `"message":"Unprocessable Entity"`, tt.name)
+		assert.Contains(t, rec.Body.String(), tt.expectedViolation, tt.name)
+	}
+}
+
+func TestBindAndValidateWithoutValidator(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.POST("/test/:id", func(c echo.Context) error {
+		_, err := httpserver.BindAndValidate[testValidationRequest](c)
+
+		return err
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/test/12", strings.NewReader(`{"name":"foo"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
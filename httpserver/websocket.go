@@ -0,0 +1,211 @@
+package httpserver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultWebSocketReadBufferSize is the default websocket connection read buffer size.
+	DefaultWebSocketReadBufferSize = 1024
+	// DefaultWebSocketWriteBufferSize is the default websocket connection write buffer size.
+	DefaultWebSocketWriteBufferSize = 1024
+)
+
+// WebSocketOptions are options for the [WebSocketConn].
+type WebSocketOptions struct {
+	ReadBufferSize  int
+	WriteBufferSize int
+	CheckOrigin     func(r *http.Request) bool
+	Subprotocols    []string
+}
+
+// DefaultWebSocketOptions are the default options used in the [WebSocketConn].
+func DefaultWebSocketOptions() WebSocketOptions {
+	return WebSocketOptions{
+		ReadBufferSize:  DefaultWebSocketReadBufferSize,
+		WriteBufferSize: DefaultWebSocketWriteBufferSize,
+		CheckOrigin:     nil,
+		Subprotocols:    nil,
+	}
+}
+
+// WebSocketOption are functional options for the [WebSocketConn].
+type WebSocketOption func(o *WebSocketOptions)
+
+// WithWebSocketBufferSizes is used to specify the websocket connection read and write buffer sizes.
+func WithWebSocketBufferSizes(read int, write int) WebSocketOption {
+	return func(o *WebSocketOptions) {
+		o.ReadBufferSize = read
+		o.WriteBufferSize = write
+	}
+}
+
+// WithWebSocketCheckOrigin is used to specify the upgrade requests origin check (same origin only by default).
+func WithWebSocketCheckOrigin(f func(r *http.Request) bool) WebSocketOption {
+	return func(o *WebSocketOptions) {
+		o.CheckOrigin = f
+	}
+}
+
+// WithWebSocketSubprotocols is used to specify the supported subprotocols, by order of preference.
+func WithWebSocketSubprotocols(subprotocols ...string) WebSocketOption {
+	return func(o *WebSocketOptions) {
+		o.Subprotocols = subprotocols
+	}
+}
+
+// WebSocketConn is an upgraded [websocket.Conn], logging its connection and disconnection.
+type WebSocketConn struct {
+	*websocket.Conn
+	netConn   *webSocketNetConn
+	logger    zerolog.Logger
+	start     time.Time
+	closeOnce sync.Once
+}
+
+// UpgradeWebSocket upgrades the request of the provided [echo.Context] to a websocket connection.
+//
+// On success, the response is marked as committed with a 101 status, and a websocket connect log record is emitted.
+// The handler should always [WebSocketConn.Close] the connection, emitting a websocket disconnect log record with the
+// connection duration and the bytes transferred. On failure, an error response has already been sent to the client.
+func UpgradeWebSocket(c echo.Context, options ...WebSocketOption) (*WebSocketConn, error) {
+	appliedOpts := DefaultWebSocketOptions()
+	for _, applyOpt := range options {
+		applyOpt(&appliedOpts)
+	}
+
+	if appliedOpts.ReadBufferSize <= 0 {
+		appliedOpts.ReadBufferSize = DefaultWebSocketReadBufferSize
+	}
+
+	if appliedOpts.WriteBufferSize <= 0 {
+		appliedOpts.WriteBufferSize = DefaultWebSocketWriteBufferSize
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  appliedOpts.ReadBufferSize,
+		WriteBufferSize: appliedOpts.WriteBufferSize,
+		CheckOrigin:     appliedOpts.CheckOrigin,
+		Subprotocols:    appliedOpts.Subprotocols,
+	}
+
+	writer := &webSocketResponseWriter{
+		ResponseWriter: c.Response(),
+	}
+
+	conn, err := upgrader.Upgrade(writer, c.Request(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot upgrade websocket connection: %w", err)
+	}
+
+	res := c.Response()
+	res.Status = http.StatusSwitchingProtocols
+	res.Committed = true
+
+	wsConn := &WebSocketConn{
+		Conn:    conn,
+		netConn: writer.netConn,
+		logger:  CtxLogger(c).ToZerolog().With().Str("uri", c.Request().RequestURI).Logger(),
+		start:   time.Now(),
+	}
+
+	wsConn.logger.Info().Str("subprotocol", conn.Subprotocol()).Msg("websocket connect")
+
+	return wsConn, nil
+}
+
+// Close closes the websocket connection, and emits a websocket disconnect log record.
+func (c *WebSocketConn) Close() error {
+	err := c.Conn.Close()
+
+	c.closeOnce.Do(func() {
+		c.logger.Info().
+			Str("duration", time.Since(c.start).String()).
+			Int64("bytesRead", c.BytesRead()).
+			Int64("bytesWritten", c.BytesWritten()).
+			Msg("websocket disconnect")
+	})
+
+	return err
+}
+
+// BytesRead returns the number of bytes read on the connection since the upgrade.
+func (c *WebSocketConn) BytesRead() int64 {
+	return c.netConn.read.Load()
+}
+
+// BytesWritten returns the number of bytes written on the connection since the upgrade (including the handshake).
+func (c *WebSocketConn) BytesWritten() int64 {
+	return c.netConn.written.Load()
+}
+
+// IsWebSocketRequest returns true if the request is a websocket upgrade request.
+func IsWebSocketRequest(c echo.Context) bool {
+	return websocket.IsWebSocketUpgrade(c.Request())
+}
+
+// WebSocketSkipper returns a [middleware.Skipper] skipping websocket upgrade requests, to exclude them from the
+// middlewares not compatible with hijacked connections (like timeout or gzip), and delegating to an optional skipper
+// for other requests.
+func WebSocketSkipper(skipper middleware.Skipper) middleware.Skipper {
+	if skipper == nil {
+		skipper = middleware.DefaultSkipper
+	}
+
+	return func(c echo.Context) bool {
+		return IsWebSocketRequest(c) || skipper(c)
+	}
+}
+
+// webSocketResponseWriter is a [http.ResponseWriter] counting the bytes transferred on its hijacked connection.
+type webSocketResponseWriter struct {
+	http.ResponseWriter
+	netConn *webSocketNetConn
+}
+
+func (w *webSocketResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.netConn = &webSocketNetConn{Conn: conn}
+
+	return w.netConn, rw, nil
+}
+
+func (w *webSocketResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// webSocketNetConn is a [net.Conn] counting the bytes read and written.
+type webSocketNetConn struct {
+	net.Conn
+	read    atomic.Int64
+	written atomic.Int64
+}
+
+func (c *webSocketNetConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.read.Add(int64(n))
+
+	return n, err
+}
+
+func (c *webSocketNetConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.written.Add(int64(n))
+
+	return n, err
+}
@@ -0,0 +1,216 @@
+package httpserver_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultWebSocketOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := httpserver.DefaultWebSocketOptions()
+
+	assert.Equal(t, httpserver.DefaultWebSocketReadBufferSize, opts.ReadBufferSize)
+	assert.Equal(t, httpserver.DefaultWebSocketWriteBufferSize, opts.WriteBufferSize)
+	assert.Nil(t, opts.CheckOrigin)
+	assert.Nil(t, opts.Subprotocols)
+}
+
+func TestUpgradeWebSocket(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	handlerDone := make(chan struct{})
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+
+	httpServer.Use(middleware.RequestLoggerMiddlewareWithConfig(middleware.RequestLoggerMiddlewareConfig{
+		LogRequestBody:  true,
+		LogResponseBody: true,
+	}))
+	httpServer.Use(middleware.RequestMetricsMiddlewareWithConfig(middleware.RequestMetricsMiddlewareConfig{
+		Registry: prometheus.NewPedanticRegistry(),
+	}))
+	httpServer.Use(middleware.RequestTimeoutMiddlewareWithConfig(middleware.RequestTimeoutMiddlewareConfig{
+		Timeout: 10 * time.Millisecond,
+	}))
+
+	route := httpServer.GET("/ws", func(c echo.Context) error {
+		defer close(handlerDone)
+
+		conn, err := httpserver.UpgradeWebSocket(c, httpserver.WithWebSocketSubprotocols("echo"))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		// not subject to the request timeout
+		_, ok := httpserver.CtxRemainingBudget(c)
+		assert.False(t, ok)
+
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return nil
+			}
+
+			err = conn.WriteMessage(messageType, []byte("echo: "+string(message)))
+			if err != nil {
+				return err
+			}
+		}
+	})
+
+	httpserver.MarkStreamingRoutes(httpServer, route)
+
+	server := httptest.NewServer(httpServer)
+	defer server.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"echo"}}
+
+	//nolint:bodyclose
+	client, resp, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "echo", client.Subprotocol())
+
+	// exchanged after the request timeout
+	time.Sleep(20 * time.Millisecond)
+
+	err = client.WriteMessage(websocket.TextMessage, []byte("hello"))
+	assert.NoError(t, err)
+
+	_, message, err := client.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: hello", string(message))
+
+	err = client.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	assert.NoError(t, err)
+	assert.NoError(t, client.Close())
+
+	select {
+	case <-handlerDone:
+	case <-time.After(time.Second):
+		t.Fatal("websocket handler did not return")
+	}
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":       "info",
+		"uri":         "/ws",
+		"subprotocol": "echo",
+		"message":     "websocket connect",
+	})
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"uri":     "/ws",
+		"message": "websocket disconnect",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"method":    "GET",
+		"uri":       "/ws",
+		"status":    http.StatusSwitchingProtocols,
+		"websocket": true,
+		"message":   "request logger",
+	})
+
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+
+	for _, record := range records {
+		message, _ := record.Attribute("message")
+
+		// bodies never buffered
+		if message == "request logger" {
+			_, err = record.Attribute("requestBody")
+			assert.Error(t, err)
+
+			_, err = record.Attribute("responseBody")
+			assert.Error(t, err)
+		}
+
+		if message == "websocket disconnect" {
+			bytesRead, err := record.Attribute("bytesRead")
+			assert.NoError(t, err)
+
+			bytesWritten, err := record.Attribute("bytesWritten")
+			assert.NoError(t, err)
+
+			read, err := bytesRead.(json.Number).Int64()
+			assert.NoError(t, err)
+
+			written, err := bytesWritten.(json.Number).Int64()
+			assert.NoError(t, err)
+
+			// masked client frames (hello and close), server frames (handshake and echo: hello)
+			assert.Greater(t, read, int64(len("hello")))
+			assert.Greater(t, written, int64(len("echo: hello")))
+		}
+	}
+}
+
+func TestUpgradeWebSocketFailure(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+
+	httpServer.GET("/ws", func(c echo.Context) error {
+		_, err := httpserver.UpgradeWebSocket(c)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot upgrade websocket connection")
+
+		return err
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebSocketSkipper(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c := httpServer.NewContext(req, httptest.NewRecorder())
+
+	assert.False(t, httpserver.IsWebSocketRequest(c))
+	assert.False(t, httpserver.WebSocketSkipper(nil)(c))
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+
+	assert.True(t, httpserver.IsWebSocketRequest(c))
+	assert.True(t, httpserver.WebSocketSkipper(nil)(c))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	c = httpServer.NewContext(req, httptest.NewRecorder())
+
+	assert.True(t, httpserver.WebSocketSkipper(func(echo.Context) bool {
+		return true
+	})(c))
+}
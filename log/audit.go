@@ -0,0 +1,157 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	Audit               = "audit"
+	AuditActor          = "actor"
+	AuditAction         = "action"
+	AuditResource       = "resource"
+	AuditOutcome        = "outcome"
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditRequestId      = "requestID"
+
+	// BaggageRequestIdKey is the well known baggage member key of the request id (as set by the http and gRPC servers),
+	// added to the audit events.
+	BaggageRequestIdKey = "x-request-id"
+)
+
+type (
+	ctxAuditLoggerKey struct{}
+	ctxAuditActorKey  struct{}
+)
+
+// AuditEvent is a structured audit event, with its required actor, action, resource and outcome fields, and optional
+// extra fields.
+type AuditEvent struct {
+	Actor    string
+	Action   string
+	Resource string
+	Outcome  string
+	Fields   map[string]interface{}
+}
+
+// Validate returns an error listing the missing required fields of the [AuditEvent], if any.
+func (e AuditEvent) Validate() error {
+	var missing []string
+
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{AuditActor, e.Actor},
+		{AuditAction, e.Action},
+		{AuditResource, e.Resource},
+		{AuditOutcome, e.Outcome},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			missing = append(missing, field.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing audit event required fields: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// AuditLogger writes structured audit events, distinct from the operational logs records.
+//
+// The audit events are flagged with an {"audit":true} field, and are never filtered by the log level nor sampled.
+type AuditLogger struct {
+	logger zerolog.Logger
+}
+
+// NewAuditLogger returns a new [AuditLogger], writing the audit events with a provided [Logger] (and its output).
+//
+// To keep the audit trail separated from the operational logs records, the provided [Logger] should have its own
+// output, without redaction.
+func NewAuditLogger(logger *Logger) *AuditLogger {
+	return &AuditLogger{
+		logger: logger.ToZerolog().
+			Level(zerolog.TraceLevel).
+			Sample(nil).
+			With().
+			Bool(Audit, true).
+			Logger(),
+	}
+}
+
+// WithContext returns a copy of the provided context carrying the [AuditLogger], to be retrieved with
+// [CtxAuditLogger].
+func (a *AuditLogger) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxAuditLoggerKey{}, a)
+}
+
+// CtxAuditLogger retrieves the [AuditLogger] from a provided context (see [AuditLogger.WithContext]), or else a new
+// one writing with the contextual [Logger] (see [CtxLogger]).
+func CtxAuditLogger(ctx context.Context) *AuditLogger {
+	if auditLogger, ok := ctx.Value(ctxAuditLoggerKey{}).(*AuditLogger); ok {
+		return auditLogger
+	}
+
+	return NewAuditLogger(&Logger{Logger: zerolog.Ctx(ctx)})
+}
+
+// WithAuditActor returns a copy of the provided context carrying the actor of the audit events, for example stamped by
+// an authentication middleware once the request caller is identified.
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, ctxAuditActorKey{}, actor)
+}
+
+// CtxAuditActor returns the actor of the audit events carried by a provided context (see [WithAuditActor]).
+func CtxAuditActor(ctx context.Context) string {
+	if actor, ok := ctx.Value(ctxAuditActorKey{}).(string); ok {
+		return actor
+	}
+
+	return ""
+}
+
+// Audit writes an [AuditEvent], with the requestID (from the [BaggageRequestIdKey] baggage member), traceID and spanID
+// fields depending on the provided context. If the
+// event has no actor, the one carried by the context is used (see [WithAuditActor]).
+//
+// An error is returned, and nothing written, if the event misses any of its required fields.
+func (a *AuditLogger) Audit(ctx context.Context, event AuditEvent) error {
+	if event.Actor == "" {
+		event.Actor = CtxAuditActor(ctx)
+	}
+
+	if err := event.Validate(); err != nil {
+		return err
+	}
+
+	logEvent := a.logger.Log().Fields(event.Fields)
+
+	if requestId := baggage.FromContext(ctx).Member(BaggageRequestIdKey).Value(); requestId != "" {
+		logEvent = logEvent.Str(AuditRequestId, requestId)
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.HasTraceID() {
+		logEvent = logEvent.Str("traceID", spanContext.TraceID().String())
+	}
+	if spanContext.HasSpanID() {
+		logEvent = logEvent.Str("spanID", spanContext.SpanID().String())
+	}
+
+	logEvent.
+		Str(AuditActor, event.Actor).
+		Str(AuditAction, event.Action).
+		Str(AuditResource, event.Resource).
+		Str(AuditOutcome, event.Outcome).
+		Send()
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package log_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAuditEventValidate(t *testing.T) {
+	t.Parallel()
+
+	event := log.AuditEvent{
+		Actor:    "user-1",
+		Action:   "delete",
+		Resource: "order/1",
+		Outcome:  log.AuditOutcomeSuccess,
+	}
+	assert.NoError(t, event.Validate())
+
+	err := log.AuditEvent{Action: "delete", Resource: " "}.Validate()
+	assert.Error(t, err)
+	assert.Equal(t, "missing audit event required fields: actor, resource, outcome", err.Error())
+}
+
+func TestAuditLoggerAudit(t *testing.T) {
+	t.Parallel()
+
+	testLogBuffer := logtest.NewDefaultTestLogBuffer()
+
+	// audit events are neither filtered by the level nor sampled
+	logger := log.FromZerolog(
+		zerolog.New(testLogBuffer).
+			Level(zerolog.ErrorLevel).
+			Sample(&zerolog.BasicSampler{N: 1000}),
+	)
+
+	auditLogger := log.NewAuditLogger(logger)
+
+	traceID, err := trace.TraceIDFromHex("c4ca4238a0b923820dcc509a6f75849b")
+	assert.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("c81e728d9d4c2f63")
+	assert.NoError(t, err)
+
+	ctx := trace.ContextWithSpanContext(
+		context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}),
+	)
+
+	for i := 0; i < 3; i++ {
+		err = auditLogger.Audit(ctx, log.AuditEvent{
+			Actor:    "user-1",
+			Action:   "delete",
+			Resource: "order/1",
+			Outcome:  log.AuditOutcomeSuccess,
+			Fields: map[string]interface{}{
+				"reason": "test",
+			},
+		})
+		assert.NoError(t, err)
+	}
+
+	records, err := testLogBuffer.Records()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
+		"audit":    true,
+		"actor":    "user-1",
+		"action":   "delete",
+		"resource": "order/1",
+		"outcome":  "success",
+		"reason":   "test",
+		"traceID":  "c4ca4238a0b923820dcc509a6f75849b",
+		"spanID":   "c81e728d9d4c2f63",
+	})
+}
+
+func TestAuditLoggerAuditFailureOnMissingRequiredFields(t *testing.T) {
+	t.Parallel()
+
+	testLogBuffer := logtest.NewDefaultTestLogBuffer()
+
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(testLogBuffer)))
+
+	err := auditLogger.Audit(context.Background(), log.AuditEvent{
+		Actor:  "user-1",
+		Action: "delete",
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "missing audit event required fields: resource, outcome", err.Error())
+
+	assert.Empty(t, testLogBuffer.Buffer().String())
+}
+
+func TestAuditLoggerRouting(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	auditLogBuffer := logtest.NewDefaultTestLogBuffer()
+
+	logger := log.FromZerolog(zerolog.New(logBuffer))
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(auditLogBuffer)))
+
+	logger.Info().Msg("test message")
+
+	err := auditLogger.Audit(context.Background(), log.AuditEvent{
+		Actor:    "user-1",
+		Action:   "login",
+		Resource: "session",
+		Outcome:  log.AuditOutcomeFailure,
+	})
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"message": "test message",
+	})
+	logtest.AssertHasNotLogRecord(t, logBuffer, map[string]interface{}{
+		"audit": true,
+	})
+
+	logtest.AssertHasLogRecord(t, auditLogBuffer, map[string]interface{}{
+		"audit":   true,
+		"action":  "login",
+		"outcome": "failure",
+	})
+	logtest.AssertHasNotLogRecord(t, auditLogBuffer, map[string]interface{}{
+		"message": "test message",
+	})
+}
+
+func TestAuditLoggerAuditWithContextActorAndRequestId(t *testing.T) {
+	t.Parallel()
+
+	testLogBuffer := logtest.NewDefaultTestLogBuffer()
+
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(testLogBuffer)))
+
+	// the actor stamped in the context, for example by an authentication middleware
+	ctx := log.WithAuditActor(context.Background(), "user-1")
+
+	// the request id propagated in the baggage, for example by the http server request id middleware
+	member, err := baggage.NewMember(log.BaggageRequestIdKey, "request-1")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	ctx = baggage.ContextWithBaggage(ctx, bag)
+
+	assert.Equal(t, "user-1", log.CtxAuditActor(ctx))
+
+	err = auditLogger.Audit(ctx, log.AuditEvent{
+		Action:   "delete",
+		Resource: "order/1",
+		Outcome:  log.AuditOutcomeSuccess,
+	})
+	assert.NoError(t, err)
+
+	// the event actor takes precedence over the context one
+	err = auditLogger.Audit(ctx, log.AuditEvent{
+		Actor:    "admin",
+		Action:   "restore",
+		Resource: "order/1",
+		Outcome:  log.AuditOutcomeSuccess,
+	})
+	assert.NoError(t, err)
+
+	logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
+		"audit":     true,
+		"requestID": "request-1",
+		"actor":     "user-1",
+		"action":    "delete",
+	})
+
+	logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
+		"audit":     true,
+		"requestID": "request-1",
+		"actor":     "admin",
+		"action":    "restore",
+	})
+
+	// the actor is still required without context one
+	err = auditLogger.Audit(context.Background(), log.AuditEvent{
+		Action:   "delete",
+		Resource: "order/1",
+		Outcome:  log.AuditOutcomeSuccess,
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "missing audit event required fields: actor", err.Error())
+
+	assert.Equal(t, "", log.CtxAuditActor(context.Background()))
+}
+
+func TestCtxAuditLogger(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	auditLogBuffer := logtest.NewDefaultTestLogBuffer()
+
+	event := log.AuditEvent{
+		Actor:    "user-1",
+		Action:   "delete",
+		Resource: "order/1",
+		Outcome:  log.AuditOutcomeSuccess,
+	}
+
+	// without audit logger, the contextual logger is used
+	ctx := log.FromZerolog(zerolog.New(logBuffer)).WithContext(context.Background())
+
+	assert.NoError(t, log.CtxAuditLogger(ctx).Audit(ctx, event))
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"audit":  true,
+		"action": "delete",
+	})
+
+	// with contextual audit logger
+	auditLogger := log.NewAuditLogger(log.FromZerolog(zerolog.New(auditLogBuffer)))
+
+	ctx = auditLogger.WithContext(ctx)
+	assert.Equal(t, auditLogger, log.CtxAuditLogger(ctx))
+
+	assert.NoError(t, log.CtxAuditLogger(ctx).Audit(ctx, event))
+
+	logtest.AssertHasLogRecord(t, auditLogBuffer, map[string]interface{}{
+		"audit":  true,
+		"action": "delete",
+	})
+
+	records, err := logBuffer.Records()
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+}
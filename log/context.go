@@ -7,12 +7,19 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+type ctxFieldsKey struct{}
+
 // CtxLogger retrieves a [Logger] from a provided context (or creates and appends a new one if missing).
 //
-// It automatically adds the traceID and spanID log fields depending on current tracing context.
+// It automatically adds the fields added with [AddContextFields], and the traceID and spanID log fields depending on
+// current tracing context.
 func CtxLogger(ctx context.Context) *Logger {
 	fields := make(map[string]interface{})
 
+	for name, value := range CtxFields(ctx) {
+		fields[name] = value
+	}
+
 	spanContext := trace.SpanContextFromContext(ctx)
 	if spanContext.HasTraceID() {
 		fields["traceID"] = spanContext.TraceID().String()
@@ -24,8 +31,42 @@ func CtxLogger(ctx context.Context) *Logger {
 	if len(fields) > 0 {
 		logger := zerolog.Ctx(ctx).With().Fields(fields).Logger()
 
-		return &Logger{&logger}
+		return &Logger{Logger: &logger}
+	}
+
+	return &Logger{Logger: zerolog.Ctx(ctx)}
+}
+
+// AddContextFields returns a copy of the provided context, carrying the provided log fields merged over the ones
+// already carried by the context: the loggers retrieved from this context (or its children) with [CtxLogger] will
+// include them.
+//
+// The provided context and its carried fields are never mutated, making it safe for concurrent use.
+func AddContextFields(ctx context.Context, fields map[string]interface{}) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	parentFields := CtxFields(ctx)
+
+	mergedFields := make(map[string]interface{}, len(parentFields)+len(fields))
+	for name, value := range parentFields {
+		mergedFields[name] = value
+	}
+	for name, value := range fields {
+		mergedFields[name] = value
+	}
+
+	return context.WithValue(ctx, ctxFieldsKey{}, mergedFields)
+}
+
+// CtxFields returns the log fields added to a provided context with [AddContextFields].
+//
+// The returned map must not be modified.
+func CtxFields(ctx context.Context) map[string]interface{} {
+	if fields, ok := ctx.Value(ctxFieldsKey{}).(map[string]interface{}); ok {
+		return fields
 	}
 
-	return &Logger{zerolog.Ctx(ctx)}
+	return nil
 }
@@ -2,6 +2,7 @@ package log_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/ankorstore/yokai/log"
@@ -94,3 +95,87 @@ func TestCtxLoggerWithSpanContext(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, containRecord)
 }
+
+func TestAddContextFields(t *testing.T) {
+	t.Parallel()
+
+	testLogBuffer := logtest.NewDefaultTestLogBuffer()
+
+	zeroLogger := zerolog.New(testLogBuffer).With().Str("test", "some value").Logger()
+	requestCtx := zeroLogger.WithContext(context.Background())
+
+	log.CtxLogger(requestCtx).Info().Msg("before authentication")
+
+	// fields added mid request
+	authenticatedCtx := log.AddContextFields(requestCtx, map[string]interface{}{
+		"userID": "user-1",
+		"role":   "viewer",
+	})
+	authenticatedCtx = log.AddContextFields(authenticatedCtx, map[string]interface{}{
+		"role": "admin",
+	})
+
+	log.CtxLogger(authenticatedCtx).Info().Msg("after authentication")
+	log.CtxLogger(requestCtx).Info().Msg("parent after authentication")
+
+	logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
+		"level":   "info",
+		"test":    "some value",
+		"userID":  "user-1",
+		"role":    "admin",
+		"message": "after authentication",
+	})
+
+	logtest.AssertHasNotLogRecord(t, testLogBuffer, map[string]interface{}{
+		"userID":  "user-1",
+		"message": "before authentication",
+	})
+
+	// parent context not mutated
+	logtest.AssertHasNotLogRecord(t, testLogBuffer, map[string]interface{}{
+		"userID":  "user-1",
+		"message": "parent after authentication",
+	})
+
+	assert.Nil(t, log.CtxFields(requestCtx))
+	assert.Equal(t, map[string]interface{}{"userID": "user-1", "role": "admin"}, log.CtxFields(authenticatedCtx))
+	assert.Equal(t, authenticatedCtx, log.AddContextFields(authenticatedCtx, nil))
+}
+
+func TestAddContextFieldsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	testLogBuffer := logtest.NewDefaultTestLogBuffer()
+
+	zeroLogger := zerolog.New(testLogBuffer)
+	ctx := log.AddContextFields(zeroLogger.WithContext(context.Background()), map[string]interface{}{
+		"requestID": "request-1",
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			workerCtx := log.AddContextFields(ctx, map[string]interface{}{
+				"worker": i,
+			})
+
+			log.CtxLogger(workerCtx).Info().Msg("worker log")
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
+			"requestID": "request-1",
+			"worker":    i,
+			"message":   "worker log",
+		})
+	}
+
+	assert.Equal(t, map[string]interface{}{"requestID": "request-1"}, log.CtxFields(ctx))
+}
@@ -6,20 +6,20 @@ import (
 	"github.com/rs/zerolog"
 )
 
-// FetchLogLevel returns a [Zerolog level] for a given value.
+// FetchLogLevel returns a [Zerolog level] for a given value (case-insensitive), or info for an unknown value.
 //
 // [Zerolog level]: https://github.com/rs/zerolog/blob/master/log.go
 //
 //nolint:cyclop
 func FetchLogLevel(level string) zerolog.Level {
-	switch level {
+	switch strings.ToLower(level) {
 	case "trace":
 		return zerolog.TraceLevel
 	case "debug":
 		return zerolog.DebugLevel
 	case "info":
 		return zerolog.InfoLevel
-	case "warning":
+	case "warn", "warning":
 		return zerolog.WarnLevel
 	case "error":
 		return zerolog.ErrorLevel
@@ -44,6 +44,10 @@ const (
 	NoopOutputWriter
 	TestOutputWriter
 	ConsoleOutputWriter
+	StderrOutputWriter
+	FileOutputWriter
+	MultiOutputWriter
+	OtlpOutputWriter
 )
 
 // String returns a string representation of a [LogOutputWriter].
@@ -57,6 +61,14 @@ func (l LogOutputWriter) String() string {
 		return Test
 	case ConsoleOutputWriter:
 		return Console
+	case StderrOutputWriter:
+		return Stderr
+	case FileOutputWriter:
+		return File
+	case MultiOutputWriter:
+		return Multi
+	case OtlpOutputWriter:
+		return Otlp
 	default:
 		return Stdout
 	}
@@ -71,7 +83,45 @@ func FetchLogOutputWriter(l string) LogOutputWriter {
 		return TestOutputWriter
 	case Console:
 		return ConsoleOutputWriter
+	case Stderr:
+		return StderrOutputWriter
+	case File:
+		return FileOutputWriter
+	case Multi:
+		return MultiOutputWriter
+	case Otlp:
+		return OtlpOutputWriter
 	default:
 		return StdoutOutputWriter
 	}
 }
+
+// LogFormat is an enum for the log records formats.
+type LogFormat int
+
+const (
+	JsonFormat LogFormat = iota
+	ConsoleFormat
+)
+
+// String returns a string representation of a [LogFormat].
+//
+//nolint:exhaustive
+func (f LogFormat) String() string {
+	switch f {
+	case ConsoleFormat:
+		return Console
+	default:
+		return Json
+	}
+}
+
+// FetchLogFormat returns a [LogFormat] for a given value.
+func FetchLogFormat(f string) LogFormat {
+	switch strings.ToLower(f) {
+	case Console:
+		return ConsoleFormat
+	default:
+		return JsonFormat
+	}
+}
@@ -36,6 +36,21 @@ func TestFetchLogLevel(t *testing.T) {
 			level:    "warning",
 			expected: zerolog.WarnLevel,
 		},
+		{
+			name:     "Warn Level",
+			level:    "warn",
+			expected: zerolog.WarnLevel,
+		},
+		{
+			name:     "Upper Case Level",
+			level:    "WARN",
+			expected: zerolog.WarnLevel,
+		},
+		{
+			name:     "Mixed Case Level",
+			level:    "Debug",
+			expected: zerolog.DebugLevel,
+		},
 		{
 			name:     "Error Level",
 			level:    "error",
@@ -80,6 +95,10 @@ func TestLogOutputWriterAsString(t *testing.T) {
 	assert.Equal(t, log.Noop, log.NoopOutputWriter.String())
 	assert.Equal(t, log.Test, log.TestOutputWriter.String())
 	assert.Equal(t, log.Console, log.ConsoleOutputWriter.String())
+	assert.Equal(t, log.Stderr, log.StderrOutputWriter.String())
+	assert.Equal(t, log.File, log.FileOutputWriter.String())
+	assert.Equal(t, log.Multi, log.MultiOutputWriter.String())
+	assert.Equal(t, log.Otlp, log.OtlpOutputWriter.String())
 }
 
 func TestFetchLogOutputWriter(t *testing.T) {
@@ -89,7 +108,29 @@ func TestFetchLogOutputWriter(t *testing.T) {
 	assert.Equal(t, log.NoopOutputWriter, log.FetchLogOutputWriter(log.Noop))
 	assert.Equal(t, log.TestOutputWriter, log.FetchLogOutputWriter(log.Test))
 	assert.Equal(t, log.ConsoleOutputWriter, log.FetchLogOutputWriter(log.Console))
+	assert.Equal(t, log.StderrOutputWriter, log.FetchLogOutputWriter(log.Stderr))
+	assert.Equal(t, log.FileOutputWriter, log.FetchLogOutputWriter(log.File))
+	assert.Equal(t, log.MultiOutputWriter, log.FetchLogOutputWriter(log.Multi))
+	assert.Equal(t, log.OtlpOutputWriter, log.FetchLogOutputWriter(log.Otlp))
 
 	// default fallback on stdout
 	assert.Equal(t, log.StdoutOutputWriter, log.FetchLogOutputWriter("random"))
 }
+
+func TestLogFormatAsString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, log.Json, log.JsonFormat.String())
+	assert.Equal(t, log.Console, log.ConsoleFormat.String())
+}
+
+func TestFetchLogFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, log.JsonFormat, log.FetchLogFormat(log.Json))
+	assert.Equal(t, log.ConsoleFormat, log.FetchLogFormat(log.Console))
+	assert.Equal(t, log.ConsoleFormat, log.FetchLogFormat("CONSOLE"))
+
+	// default fallback on json
+	assert.Equal(t, log.JsonFormat, log.FetchLogFormat("random"))
+}
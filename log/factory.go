@@ -9,6 +9,7 @@ var once sync.Once
 
 func init() {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	zerolog.ErrorStackMarshaler = ErrorStack
 }
 
 // LoggerFactory is the interface for [Logger] factories.
@@ -32,9 +33,17 @@ func NewDefaultLoggerFactory() LoggerFactory {
 // is equivalent to:
 //
 //	var logger, _ = log.NewDefaultLoggerFactory().Create(
-//		log.WithServiceName("default"),   // adds {"service":"default"} to log records
-//		log.WithLevel(zerolog.InfoLevel), // logs records with level >= info
-//		log.WithOutputWriter(os.Stdout),  // sends logs records to stdout
+//		log.WithServiceName("default"),             // adds {"service":"default"} to log records
+//		log.WithServiceVersion(""),                 // adds {"version":"..."} to log records, if not empty
+//		log.WithLevel(zerolog.InfoLevel),           // logs records with level >= info
+//		log.WithOutputWriter(os.Stdout),            // sends logs records to stdout
+//		log.WithSampler(nil),                       // does not sample logs records
+//		log.WithRedaction(log.RedactionConfig{}),   // does not redact logs fields
+//		log.WithFormat(log.JsonFormat),             // formats logs records as JSON
+//		log.WithTimeFormat(zerolog.TimeFormatUnix), // adds unix timestamps to logs records
+//		log.WithTimeField(log.Time),                // in the {"time":...} field
+//		log.WithOtlpWriter(nil),                    // does not export logs records with OTLP
+//		log.WithErrorStack(false),                  // does not add the errors stack to logs records
 //	)
 func (f *DefaultLoggerFactory) Create(options ...LoggerOption) (*Logger, error) {
 	appliedOpts := DefaultLoggerOptions()
@@ -42,17 +51,62 @@ func (f *DefaultLoggerFactory) Create(options ...LoggerOption) (*Logger, error)
 		applyOpt(&appliedOpts)
 	}
 
-	logger := zerolog.
-		New(appliedOpts.OutputWriter).
+	outputWriter := appliedOpts.OutputWriter
+	timestamp := timestampHook{
+		field:  appliedOpts.TimeField,
+		format: appliedOpts.TimeFormat,
+	}
+
+	// the console writer decodes the JSON records, with their timestamp in the zerolog expected field and format
+	if appliedOpts.Format == ConsoleFormat {
+		outputWriter = NewConsoleWriter(outputWriter, appliedOpts.TimeFormat)
+		timestamp = timestampHook{
+			field:  zerolog.TimestampFieldName,
+			format: zerolog.TimeFieldFormat,
+		}
+	}
+
+	// the OTLP export decodes the JSON records, their timestamp field being replaced by the log records one
+	if appliedOpts.OtlpWriter != nil {
+		appliedOpts.OtlpWriter.timeField = timestamp.field
+		outputWriter = zerolog.MultiLevelWriter(outputWriter, appliedOpts.OtlpWriter)
+	}
+
+	// the redaction applies on the JSON records, before their formatting
+	if len(appliedOpts.Redaction.Fields) > 0 {
+		outputWriter = NewRedactingWriter(outputWriter, appliedOpts.Redaction)
+	}
+
+	// the level is applied by a sampler shared by all the derived loggers, to be changed at runtime, and checked by
+	// zerolog before building the log records (the writer filtering the records of the loggers with another sampler)
+	writer := newLeveledWriter(outputWriter, appliedOpts.Level)
+
+	logContext := zerolog.
+		New(writer).
+		Hook(timestamp).
 		With().
-		Timestamp().
-		Str(Service, appliedOpts.ServiceName).
-		Logger().
-		Level(appliedOpts.Level)
+		Str(Service, appliedOpts.ServiceName)
+
+	if appliedOpts.ServiceVersion != "" {
+		logContext = logContext.Str(ServiceVersion, appliedOpts.ServiceVersion)
+	}
+
+	if appliedOpts.ErrorStack {
+		logContext = logContext.Stack()
+	}
+
+	logger := logContext.Logger().Sample(&levelSampler{
+		sampler: appliedOpts.Sampler,
+		level:   writer.level,
+	})
 
 	once.Do(func() {
 		zerolog.DefaultContextLogger = &logger
+
+		defaultLevelMutex.Lock()
+		defaultLevel = writer.level
+		defaultLevelMutex.Unlock()
 	})
 
-	return &Logger{&logger}, nil
+	return &Logger{Logger: &logger, level: writer.level}, nil
 }
@@ -1,6 +1,7 @@
 package log_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ankorstore/yokai/log"
@@ -26,6 +27,7 @@ func TestCreateSuccess(t *testing.T) {
 	factory := log.NewDefaultLoggerFactory()
 	logger, err := factory.Create(
 		log.WithServiceName("test logger"),
+		log.WithServiceVersion("1.2.3"),
 		log.WithLevel(zerolog.InfoLevel),
 		log.WithOutputWriter(testLogBuffer),
 	)
@@ -38,6 +40,7 @@ func TestCreateSuccess(t *testing.T) {
 	logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
 		"level":   "info",
 		"service": "test logger",
+		"version": "1.2.3",
 		"message": "some message",
 	})
 
@@ -66,4 +69,48 @@ func TestCreateSuccess(t *testing.T) {
 		"service": "test logger",
 		"message": "some other message from default global logger",
 	})
+
+	// runtime level changes, observed immediately by the derived loggers
+	ctxLogger := log.CtxLogger(logger.WithContext(context.Background()))
+
+	assert.Equal(t, zerolog.InfoLevel, logger.GetLevel())
+	ctxLogger.Debug().Msg("some debug message before level change")
+
+	logger.SetLevel(zerolog.DebugLevel)
+
+	assert.Equal(t, zerolog.DebugLevel, logger.GetLevel())
+	ctxLogger.Debug().Msg("some debug message after level change")
+
+	logtest.AssertHasNotLogRecord(t, testLogBuffer, map[string]interface{}{
+		"level":   "debug",
+		"message": "some debug message before level change",
+	})
+
+	logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
+		"level":   "debug",
+		"service": "test logger",
+		"message": "some debug message after level change",
+	})
+
+	// disabled levels dropped before the log records are built
+	assert.False(t, ctxLogger.Trace().Enabled())
+	assert.True(t, ctxLogger.Debug().Enabled())
+
+	// runtime level change of the default logger
+	log.SetLevel(zerolog.WarnLevel)
+
+	assert.Equal(t, zerolog.WarnLevel, logger.GetLevel())
+	ctxLogger.Info().Msg("some info message after default level change")
+	zerolog.DefaultContextLogger.Warn().Msg("some warn message after default level change")
+	assert.False(t, ctxLogger.Info().Enabled())
+
+	logtest.AssertHasNotLogRecord(t, testLogBuffer, map[string]interface{}{
+		"level":   "info",
+		"message": "some info message after default level change",
+	})
+
+	logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
+		"level":   "warn",
+		"message": "some warn message after default level change",
+	})
 }
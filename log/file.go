@@ -0,0 +1,123 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// fileWriters references the opened [FileWriter] instances, to be able to reopen them with [ReopenFileWriters].
+var fileWriters = struct {
+	sync.Mutex
+	writers map[*FileWriter]struct{}
+}{
+	writers: map[*FileWriter]struct{}{},
+}
+
+// ReopenFileWriters reopens the files of all the opened [FileWriter] instances, for example after an external log
+// rotation (like logrotate).
+func ReopenFileWriters() error {
+	fileWriters.Lock()
+	defer fileWriters.Unlock()
+
+	var errs []error
+	for writer := range fileWriters.writers {
+		if err := writer.Reopen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// FileWriterConfig is the configuration of a [FileWriter].
+type FileWriterConfig struct {
+	Path       string // path of the log file
+	MaxSize    int    // maximum size in megabytes of the log file before its rotation (100 by default)
+	MaxBackups int    // maximum number of rotated log files to retain (all by default)
+	MaxAge     int    // maximum number of days to retain the rotated log files (no age limit by default)
+	Compress   bool   // to gzip compress the rotated log files
+}
+
+// FileWriter is an [io.Writer] appending the log records to a file, rotated when exceeding its max size.
+type FileWriter struct {
+	logger *lumberjack.Logger
+}
+
+// NewFileWriter returns a new [FileWriter] for a provided [FileWriterConfig].
+//
+// The file is opened (or created) on the first write.
+func NewFileWriter(config FileWriterConfig) *FileWriter {
+	writer := &FileWriter{
+		logger: &lumberjack.Logger{
+			Filename:   config.Path,
+			MaxSize:    config.MaxSize,
+			MaxBackups: config.MaxBackups,
+			MaxAge:     config.MaxAge,
+			Compress:   config.Compress,
+		},
+	}
+
+	fileWriters.Lock()
+	fileWriters.writers[writer] = struct{}{}
+	fileWriters.Unlock()
+
+	return writer
+}
+
+// Write appends a log record to the file, rotating it first if the record would exceed its max size.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	return w.logger.Write(p)
+}
+
+// Rotate rotates the file: the current file is renamed with a timestamp, and a new file is created.
+func (w *FileWriter) Rotate() error {
+	return w.logger.Rotate()
+}
+
+// Reopen closes the file, to be reopened (or recreated if moved) on the next write.
+func (w *FileWriter) Reopen() error {
+	return w.logger.Close()
+}
+
+// ReopenOnSignal reopens the file each time one of the provided signals (for example syscall.SIGHUP) is received,
+// until the returned stop function is called.
+func (w *FileWriter) ReopenOnSignal(signals ...os.Signal) func() {
+	signalsChan := make(chan os.Signal, 1)
+	doneChan := make(chan struct{})
+
+	signal.Notify(signalsChan, signals...)
+
+	go func() {
+		for {
+			select {
+			case <-signalsChan:
+				//nolint:errcheck
+				w.Reopen()
+			case <-doneChan:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(signalsChan)
+			close(doneChan)
+		})
+	}
+}
+
+// Close closes the file, which is not reopened anymore by [ReopenFileWriters].
+func (w *FileWriter) Close() error {
+	fileWriters.Lock()
+	delete(fileWriters.writers, w)
+	fileWriters.Unlock()
+
+	return w.logger.Close()
+}
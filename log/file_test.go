@@ -0,0 +1,161 @@
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriter(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	writer := log.NewFileWriter(log.FileWriterConfig{
+		Path: path,
+	})
+	defer writer.Close()
+
+	_, err := writer.Write([]byte("test\n"))
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "test\n", string(content))
+}
+
+func TestFileWriterRotationOnMaxSize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	writer := log.NewFileWriter(log.FileWriterConfig{
+		Path:    path,
+		MaxSize: 1,
+	})
+	defer writer.Close()
+
+	record := []byte(strings.Repeat("a", 1023) + "\n")
+
+	// exceeds the 1 megabyte max size
+	for i := 0; i < 1100; i++ {
+		_, err := writer.Write(record)
+		assert.NoError(t, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	rotated, err := os.Stat(files[0])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024*1024), rotated.Size())
+
+	current, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(76*1024), current.Size())
+}
+
+func TestFileWriterRotate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	writer := log.NewFileWriter(log.FileWriterConfig{
+		Path: path,
+	})
+	defer writer.Close()
+
+	_, err := writer.Write([]byte("before\n"))
+	assert.NoError(t, err)
+
+	err = writer.Rotate()
+	assert.NoError(t, err)
+
+	_, err = writer.Write([]byte("after\n"))
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "after\n", string(content))
+}
+
+func TestReopenFileWriters(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	writer := log.NewFileWriter(log.FileWriterConfig{
+		Path: path,
+	})
+	defer writer.Close()
+
+	_, err := writer.Write([]byte("before\n"))
+	assert.NoError(t, err)
+
+	// external rotation, like logrotate
+	err = os.Rename(path, path+".1")
+	assert.NoError(t, err)
+
+	err = log.ReopenFileWriters()
+	assert.NoError(t, err)
+
+	_, err = writer.Write([]byte("after\n"))
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path + ".1")
+	assert.NoError(t, err)
+	assert.Equal(t, "before\n", string(content))
+
+	content, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "after\n", string(content))
+}
+
+func TestFileWriterReopenOnSignal(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	writer := log.NewFileWriter(log.FileWriterConfig{
+		Path: path,
+	})
+	defer writer.Close()
+
+	stop := writer.ReopenOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	_, err := writer.Write([]byte("before\n"))
+	assert.NoError(t, err)
+
+	// external rotation, like logrotate
+	err = os.Rename(path, path+".1")
+	assert.NoError(t, err)
+
+	err = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	assert.NoError(t, err)
+
+	// the file is recreated by the first write after the reopening
+	assert.Eventually(t, func() bool {
+		//nolint:errcheck
+		writer.Write([]byte("after\n"))
+
+		_, statErr := os.Stat(path)
+
+		return statErr == nil
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// FetchTimeFormat returns the time format of the log records timestamp for a given value: unix, unixms, unixmicro,
+// unixnano, rfc3339 or rfc3339nano, or else the value itself as a Go time layout (like 2006-01-02 15:04:05).
+func FetchTimeFormat(format string) string {
+	switch strings.ToLower(format) {
+	case "", "unix":
+		return zerolog.TimeFormatUnix
+	case "unixms":
+		return zerolog.TimeFormatUnixMs
+	case "unixmicro":
+		return zerolog.TimeFormatUnixMicro
+	case "unixnano":
+		return zerolog.TimeFormatUnixNano
+	case "rfc3339":
+		return time.RFC3339
+	case "rfc3339nano":
+		return time.RFC3339Nano
+	default:
+		return format
+	}
+}
+
+// NewConsoleWriter returns a colorized human-readable [zerolog.ConsoleWriter] writing to a provided writer, the log
+// records timestamp being displayed with a provided time format (see [FetchTimeFormat]), or as 3:04PM if it is a unix
+// one.
+func NewConsoleWriter(writer io.Writer, timeFormat string) zerolog.ConsoleWriter {
+	consoleWriter := zerolog.ConsoleWriter{
+		Out: writer,
+	}
+
+	if !isUnixTimeFormat(timeFormat) {
+		consoleWriter.TimeFormat = timeFormat
+	}
+
+	return consoleWriter
+}
+
+// timestampHook adds the timestamp of the log records in a field, with a time format (see [FetchTimeFormat]).
+type timestampHook struct {
+	field  string
+	format string
+}
+
+// Run adds the current time to the log record.
+func (h timestampHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	now := zerolog.TimestampFunc()
+
+	switch h.format {
+	case zerolog.TimeFormatUnix:
+		e.Int64(h.field, now.Unix())
+	case zerolog.TimeFormatUnixMs:
+		e.Int64(h.field, now.UnixMilli())
+	case zerolog.TimeFormatUnixMicro:
+		e.Int64(h.field, now.UnixMicro())
+	case zerolog.TimeFormatUnixNano:
+		e.Int64(h.field, now.UnixNano())
+	default:
+		e.Str(h.field, now.Format(h.format))
+	}
+}
+
+func isUnixTimeFormat(format string) bool {
+	switch format {
+	case zerolog.TimeFormatUnix, zerolog.TimeFormatUnixMs, zerolog.TimeFormatUnixMicro, zerolog.TimeFormatUnixNano:
+		return true
+	default:
+		return false
+	}
+}
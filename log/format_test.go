@@ -0,0 +1,52 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, zerolog.TimeFormatUnix, log.FetchTimeFormat(""))
+	assert.Equal(t, zerolog.TimeFormatUnix, log.FetchTimeFormat("unix"))
+	assert.Equal(t, zerolog.TimeFormatUnixMs, log.FetchTimeFormat("unixms"))
+	assert.Equal(t, zerolog.TimeFormatUnixMicro, log.FetchTimeFormat("UnixMicro"))
+	assert.Equal(t, zerolog.TimeFormatUnixNano, log.FetchTimeFormat("unixnano"))
+	assert.Equal(t, time.RFC3339, log.FetchTimeFormat("rfc3339"))
+	assert.Equal(t, time.RFC3339Nano, log.FetchTimeFormat("RFC3339Nano"))
+
+	// fallback on the value as Go time layout
+	assert.Equal(t, "2006-01-02 15:04:05", log.FetchTimeFormat("2006-01-02 15:04:05"))
+}
+
+func TestNewConsoleWriter(t *testing.T) {
+	t.Parallel()
+
+	buffer := &bytes.Buffer{}
+
+	writer := log.NewConsoleWriter(buffer, "2006-01-02")
+	writer.NoColor = true
+
+	_, err := writer.Write([]byte(`{"level":"info","time":1700000000,"service":"test","message":"some message"}`))
+	assert.NoError(t, err)
+
+	expectedDate := time.Unix(1700000000, 0).Format("2006-01-02")
+
+	assert.Equal(t, expectedDate+" INF some message service=test\n", buffer.String())
+}
+
+func TestNewConsoleWriterWithUnixTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	writer := log.NewConsoleWriter(&bytes.Buffer{}, zerolog.TimeFormatUnixMs)
+
+	// displayed with the zerolog console default time format
+	assert.Empty(t, writer.TimeFormat)
+	assert.False(t, writer.NoColor)
+}
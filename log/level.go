@@ -0,0 +1,104 @@
+package log
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	defaultLevelMutex sync.Mutex
+	defaultLevel      *atomic.Int32
+)
+
+// SetLevel atomically changes at runtime the level of the default logger (the first one created by the
+// [DefaultLoggerFactory]), and of all the loggers derived from it.
+func SetLevel(level zerolog.Level) {
+	defaultLevelMutex.Lock()
+	defer defaultLevelMutex.Unlock()
+
+	if defaultLevel != nil {
+		defaultLevel.Store(int32(level))
+	}
+}
+
+// levelSampler is a [zerolog.Sampler] dropping the log records below a level that can be changed at runtime: it is
+// shared by all the loggers derived from a [Logger] (with With, or with WithContext and [CtxLogger]), that observe the
+// level changes immediately, instead of copying the level at derivation time. Being consulted by zerolog before the log
+// records are built, the disabled ones are never formatted.
+//
+// It consults its sampler (if any) only for the log records of an enabled level: the log records dropped by the level
+// are not counted, and do not consume the bursts.
+type levelSampler struct {
+	sampler zerolog.Sampler
+	level   *atomic.Int32
+}
+
+// Sample returns true if a log record of the provided level should be logged.
+func (s *levelSampler) Sample(level zerolog.Level) bool {
+	if level < zerolog.Level(s.level.Load()) {
+		return false
+	}
+
+	return s.sampler == nil || s.sampler.Sample(level)
+}
+
+// leveledWriter is a [zerolog.LevelWriter] filtering the log records by the same runtime level as the [levelSampler],
+// for the derived loggers whose sampler was replaced (with Sample), or when the sampling is disabled.
+type leveledWriter struct {
+	writer io.Writer
+	level  *atomic.Int32
+}
+
+func newLeveledWriter(writer io.Writer, level zerolog.Level) *leveledWriter {
+	w := &leveledWriter{
+		writer: writer,
+		level:  &atomic.Int32{},
+	}
+
+	w.level.Store(int32(level))
+
+	return w
+}
+
+// Write writes a log record without level.
+func (w *leveledWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel writes a log record, if its level is greater or equal to the current level.
+func (w *leveledWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.Level(w.level.Load()) {
+		return len(p), nil
+	}
+
+	if levelWriter, ok := w.writer.(zerolog.LevelWriter); ok {
+		return levelWriter.WriteLevel(level, p)
+	}
+
+	return w.writer.Write(p)
+}
+
+// GetLevel returns the current level of the [Logger], including the runtime changes made with SetLevel.
+func (l *Logger) GetLevel() zerolog.Level {
+	if l.level == nil {
+		return l.Logger.GetLevel()
+	}
+
+	return zerolog.Level(l.level.Load())
+}
+
+// SetLevel atomically changes at runtime the level of the [Logger], observed immediately by all the loggers derived
+// from it if it was created by the [DefaultLoggerFactory] (otherwise only the [Logger] itself is changed).
+func (l *Logger) SetLevel(level zerolog.Level) {
+	if l.level == nil {
+		updated := l.Logger.Level(level)
+		l.Logger = &updated
+
+		return
+	}
+
+	l.level.Store(int32(level))
+}
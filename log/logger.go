@@ -1,18 +1,26 @@
 package log
 
 import (
+	"sync/atomic"
+
 	"github.com/rs/zerolog"
 )
 
 const (
-	Level   = "level"
-	Message = "message"
-	Service = "service"
-	Time    = "time"
-	Stdout  = "stdout"
-	Noop    = "noop"
-	Test    = "test"
-	Console = "console"
+	Level          = "level"
+	Message        = "message"
+	Service        = "service"
+	ServiceVersion = "version"
+	Time           = "time"
+	Stdout         = "stdout"
+	Noop           = "noop"
+	Test           = "test"
+	Console        = "console"
+	Stderr         = "stderr"
+	File           = "file"
+	Multi          = "multi"
+	Otlp           = "otlp"
+	Json           = "json"
 )
 
 // Logger provides the possibility to generate logs, and inherits of all [Zerolog] features.
@@ -20,6 +28,7 @@ const (
 // [Zerolog]: https://github.com/rs/zerolog/tree/master
 type Logger struct {
 	*zerolog.Logger
+	level *atomic.Int32
 }
 
 // ToZerolog converts as [Logger] into a [Zerolog logger].
@@ -33,5 +42,5 @@ func (l *Logger) ToZerolog() *zerolog.Logger {
 //
 // [Zerolog logger]: https://github.com/rs/zerolog/blob/master/log.go
 func FromZerolog(logger zerolog.Logger) *Logger {
-	return &Logger{&logger}
+	return &Logger{Logger: &logger}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +24,28 @@ func TestLoggerConversion(t *testing.T) {
 	assert.IsType(t, &zerolog.Logger{}, backToZeroLogger)
 	assert.Equal(t, &zeroLogger, backToZeroLogger)
 }
+
+func TestLoggerSetLevelWithoutFactory(t *testing.T) {
+	t.Parallel()
+
+	testLogBuffer := logtest.NewDefaultTestLogBuffer()
+
+	logger := log.FromZerolog(zerolog.New(testLogBuffer).Level(zerolog.InfoLevel))
+	assert.Equal(t, zerolog.InfoLevel, logger.GetLevel())
+
+	logger.Debug().Msg("some debug message before level change")
+
+	logger.SetLevel(zerolog.DebugLevel)
+	assert.Equal(t, zerolog.DebugLevel, logger.GetLevel())
+
+	logger.Debug().Msg("some debug message after level change")
+
+	logtest.AssertHasNotLogRecord(t, testLogBuffer, map[string]interface{}{
+		"message": "some debug message before level change",
+	})
+
+	logtest.AssertHasLogRecord(t, testLogBuffer, map[string]interface{}{
+		"level":   "debug",
+		"message": "some debug message after level change",
+	})
+}
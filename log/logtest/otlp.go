@@ -0,0 +1,86 @@
+package logtest
+
+import (
+	"context"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// TestOtlpExporter is the interface for test OTLP log records exporters.
+type TestOtlpExporter interface {
+	sdklog.Exporter
+	Reset() TestOtlpExporter
+	Records() []sdklog.Record
+	FailWith(err error) TestOtlpExporter
+}
+
+// DefaultTestOtlpExporter is the default [TestOtlpExporter] implementation, recording the exported log records in
+// memory.
+type DefaultTestOtlpExporter struct {
+	records []sdklog.Record
+	err     error
+	lock    *sync.Mutex
+}
+
+// NewDefaultTestOtlpExporter returns a [DefaultTestOtlpExporter], implementing [TestOtlpExporter].
+func NewDefaultTestOtlpExporter() TestOtlpExporter {
+	return &DefaultTestOtlpExporter{
+		lock: &sync.Mutex{},
+	}
+}
+
+// Export records the exported log records, or fails with the error provided to FailWith.
+func (e *DefaultTestOtlpExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.err != nil {
+		return e.err
+	}
+
+	for _, record := range records {
+		e.records = append(e.records, record.Clone())
+	}
+
+	return nil
+}
+
+// Shutdown does nothing, the recorded log records being kept.
+func (e *DefaultTestOtlpExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing, the log records being recorded on export.
+func (e *DefaultTestOtlpExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Reset resets the recorded log records and the export error.
+func (e *DefaultTestOtlpExporter) Reset() TestOtlpExporter {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.records = nil
+	e.err = nil
+
+	return e
+}
+
+// Records returns the recorded log records.
+func (e *DefaultTestOtlpExporter) Records() []sdklog.Record {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return append([]sdklog.Record(nil), e.records...)
+}
+
+// FailWith makes the next exports fail with a provided error (none if nil), to simulate export failures.
+func (e *DefaultTestOtlpExporter) FailWith(err error) TestOtlpExporter {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.err = err
+
+	return e
+}
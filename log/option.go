@@ -9,9 +9,17 @@ import (
 
 // Options are options for the [LoggerFactory] implementations.
 type Options struct {
-	ServiceName  string
-	Level        zerolog.Level
-	OutputWriter io.Writer
+	ServiceName    string
+	ServiceVersion string
+	Level          zerolog.Level
+	OutputWriter   io.Writer
+	Sampler        zerolog.Sampler
+	Redaction      RedactionConfig
+	Format         LogFormat
+	TimeFormat     string
+	TimeField      string
+	OtlpWriter     *OtlpWriter
+	ErrorStack     bool
 }
 
 // DefaultLoggerOptions are the default options used in the [DefaultLoggerFactory].
@@ -20,6 +28,9 @@ func DefaultLoggerOptions() Options {
 		ServiceName:  "default",
 		Level:        zerolog.InfoLevel,
 		OutputWriter: os.Stdout,
+		Format:       JsonFormat,
+		TimeFormat:   zerolog.TimeFormatUnix,
+		TimeField:    Time,
 	}
 }
 
@@ -33,6 +44,13 @@ func WithServiceName(n string) LoggerOption {
 	}
 }
 
+// WithServiceVersion is used to add automatically a service version log field value (omitted if empty).
+func WithServiceVersion(v string) LoggerOption {
+	return func(o *Options) {
+		o.ServiceVersion = v
+	}
+}
+
 // WithLevel is used to specify the log level to use.
 func WithLevel(l zerolog.Level) LoggerOption {
 	return func(o *Options) {
@@ -46,3 +64,55 @@ func WithOutputWriter(w io.Writer) LoggerOption {
 		o.OutputWriter = w
 	}
 }
+
+// WithSampler is used to specify the sampler of the log records (see [Sampler]), not sampled by default.
+func WithSampler(s zerolog.Sampler) LoggerOption {
+	return func(o *Options) {
+		o.Sampler = s
+	}
+}
+
+// WithRedaction is used to specify the log fields to redact (see [RedactingWriter]), none by default.
+func WithRedaction(c RedactionConfig) LoggerOption {
+	return func(o *Options) {
+		o.Redaction = c
+	}
+}
+
+// WithFormat is used to specify the format of the log records (see [LogFormat]), json by default.
+func WithFormat(f LogFormat) LoggerOption {
+	return func(o *Options) {
+		o.Format = f
+	}
+}
+
+// WithTimeFormat is used to specify the time format of the log records timestamp (see [FetchTimeFormat]), unix by
+// default.
+func WithTimeFormat(f string) LoggerOption {
+	return func(o *Options) {
+		o.TimeFormat = f
+	}
+}
+
+// WithTimeField is used to specify the field of the log records timestamp, time by default.
+func WithTimeField(f string) LoggerOption {
+	return func(o *Options) {
+		o.TimeField = f
+	}
+}
+
+// WithOtlpWriter is used to also export the log records with an [OtlpWriter], alongside the output writer ones (not
+// exported by default).
+func WithOtlpWriter(w *OtlpWriter) LoggerOption {
+	return func(o *Options) {
+		o.OtlpWriter = w
+	}
+}
+
+// WithErrorStack is used to add the stack trace of the errors logged with Err to the log records stack field, for the
+// errors carrying one (see [WrapWithStack]), disabled by default.
+func WithErrorStack(e bool) LoggerOption {
+	return func(o *Options) {
+		o.ErrorStack = e
+	}
+}
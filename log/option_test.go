@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
@@ -41,4 +42,69 @@ func TestLoggerOptions(t *testing.T) {
 		opt(o)
 		assert.Equal(t, &buf, o.OutputWriter)
 	})
+
+	t.Run("test WithSampler", func(t *testing.T) {
+		t.Parallel()
+
+		o := &log.Options{}
+		sampler := log.NewSampler(nil)
+		opt := log.WithSampler(sampler)
+		opt(o)
+		assert.Equal(t, sampler, o.Sampler)
+	})
+	t.Run("test WithRedaction", func(t *testing.T) {
+		t.Parallel()
+
+		o := &log.Options{}
+		config := log.RedactionConfig{Fields: []string{"password"}, Deep: true}
+		opt := log.WithRedaction(config)
+		opt(o)
+		assert.Equal(t, config, o.Redaction)
+	})
+
+	t.Run("test WithFormat", func(t *testing.T) {
+		t.Parallel()
+
+		o := &log.Options{}
+		opt := log.WithFormat(log.ConsoleFormat)
+		opt(o)
+		assert.Equal(t, log.ConsoleFormat, o.Format)
+	})
+
+	t.Run("test WithTimeFormat", func(t *testing.T) {
+		t.Parallel()
+
+		o := &log.Options{}
+		opt := log.WithTimeFormat(zerolog.TimeFormatUnixMs)
+		opt(o)
+		assert.Equal(t, zerolog.TimeFormatUnixMs, o.TimeFormat)
+	})
+
+	t.Run("test WithTimeField", func(t *testing.T) {
+		t.Parallel()
+
+		o := &log.Options{}
+		opt := log.WithTimeField("timestamp")
+		opt(o)
+		assert.Equal(t, "timestamp", o.TimeField)
+	})
+	t.Run("test WithOtlpWriter", func(t *testing.T) {
+		t.Parallel()
+
+		w := log.NewOtlpWriter(log.OtlpWriterConfig{Exporter: logtest.NewDefaultTestOtlpExporter()})
+
+		o := &log.Options{}
+		opt := log.WithOtlpWriter(w)
+		opt(o)
+		assert.Equal(t, w, o.OtlpWriter)
+	})
+
+	t.Run("test WithErrorStack", func(t *testing.T) {
+		t.Parallel()
+
+		o := &log.Options{}
+		opt := log.WithErrorStack(true)
+		opt(o)
+		assert.True(t, o.ErrorStack)
+	})
 }
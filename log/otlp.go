@@ -0,0 +1,344 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OtlpScopeName is the instrumentation scope name of the log records exported by the [OtlpWriter].
+const OtlpScopeName = "github.com/ankorstore/yokai/log"
+
+// OtlpConfig is the configuration of the OTLP gRPC log records exporter (see [NewOtlpGrpcExporter]).
+type OtlpConfig struct {
+	Endpoint string            // OTLP gRPC endpoint, like localhost:4317 (OTEL_EXPORTER_OTLP_ENDPOINT by default)
+	Headers  map[string]string // headers sent with the export requests
+	Insecure bool              // to disable the client transport security
+	Timeout  time.Duration     // timeout of the export requests (10s by default)
+}
+
+// OtlpBatchConfig is the configuration of the [OtlpWriter] log records batching.
+type OtlpBatchConfig struct {
+	MaxQueueSize  int           // maximum number of queued log records, the oldest ones being dropped (2048 by default)
+	MaxExportSize int           // maximum number of log records per export (512 by default)
+	Interval      time.Duration // delay between two exports (1s by default)
+	Timeout       time.Duration // timeout of an export (30s by default)
+}
+
+// OtlpWriterConfig is the configuration of an [OtlpWriter].
+type OtlpWriterConfig struct {
+	Exporter sdklog.Exporter    // exporter of the log records, like [NewOtlpGrpcExporter]
+	Resource *resource.Resource // resource of the log records, like the tracer provider one
+	Batch    OtlpBatchConfig    // batching of the log records exports
+}
+
+// NewOtlpGrpcExporter returns a new OTLP gRPC [sdklog.Exporter] for a provided [OtlpConfig].
+func NewOtlpGrpcExporter(ctx context.Context, config OtlpConfig) (sdklog.Exporter, error) {
+	var options []otlploggrpc.Option
+
+	if config.Endpoint != "" {
+		options = append(options, otlploggrpc.WithEndpoint(config.Endpoint))
+	}
+
+	if len(config.Headers) > 0 {
+		options = append(options, otlploggrpc.WithHeaders(config.Headers))
+	}
+
+	if config.Insecure {
+		options = append(options, otlploggrpc.WithInsecure())
+	}
+
+	if config.Timeout > 0 {
+		options = append(options, otlploggrpc.WithTimeout(config.Timeout))
+	}
+
+	return otlploggrpc.New(ctx, options...)
+}
+
+// OtlpWriter is a [zerolog.LevelWriter] converting the JSON log records into OpenTelemetry log records, exported in
+// batches with its [sdklog.Exporter], to be used alongside the log records output (see [WithOtlpWriter]).
+//
+// The log records level is mapped to their severity, their message to their body and their other fields to their
+// attributes, the traceID and spanID fields correlating them with the trace they were logged in.
+//
+// The writes never fail nor block: the log records are queued for their export, and the ones which cannot be decoded
+// or exported are dropped, counted by [OtlpWriter.Dropped].
+type OtlpWriter struct {
+	provider  *sdklog.LoggerProvider
+	logger    otellog.Logger
+	timeField string
+	dropped   *atomic.Uint64
+}
+
+// NewOtlpWriter returns a new [OtlpWriter] for a provided [OtlpWriterConfig].
+func NewOtlpWriter(config OtlpWriterConfig) *OtlpWriter {
+	dropped := &atomic.Uint64{}
+
+	var batchOptions []sdklog.BatchProcessorOption
+
+	if config.Batch.MaxQueueSize > 0 {
+		batchOptions = append(batchOptions, sdklog.WithMaxQueueSize(config.Batch.MaxQueueSize))
+	}
+
+	if config.Batch.MaxExportSize > 0 {
+		batchOptions = append(batchOptions, sdklog.WithExportMaxBatchSize(config.Batch.MaxExportSize))
+	}
+
+	if config.Batch.Interval > 0 {
+		batchOptions = append(batchOptions, sdklog.WithExportInterval(config.Batch.Interval))
+	}
+
+	if config.Batch.Timeout > 0 {
+		batchOptions = append(batchOptions, sdklog.WithExportTimeout(config.Batch.Timeout))
+	}
+
+	providerOptions := []sdklog.LoggerProviderOption{
+		sdklog.WithProcessor(
+			sdklog.NewBatchProcessor(
+				&droppingExporter{
+					exporter: config.Exporter,
+					dropped:  dropped,
+				},
+				batchOptions...,
+			),
+		),
+	}
+
+	if config.Resource != nil {
+		providerOptions = append(providerOptions, sdklog.WithResource(config.Resource))
+	}
+
+	provider := sdklog.NewLoggerProvider(providerOptions...)
+
+	return &OtlpWriter{
+		provider:  provider,
+		logger:    provider.Logger(OtlpScopeName),
+		timeField: Time,
+		dropped:   dropped,
+	}
+}
+
+// Write writes a log record without level.
+func (w *OtlpWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel queues a log record for its export, dropping it if it cannot be decoded.
+func (w *OtlpWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	// the application logging must never fail because of the export
+	defer func() {
+		if recover() != nil {
+			w.dropped.Add(1)
+		}
+	}()
+
+	fields := map[string]interface{}{}
+
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+
+	if err = decoder.Decode(&fields); err != nil {
+		w.dropped.Add(1)
+
+		return len(p), nil
+	}
+
+	ctx, record := w.record(level, fields)
+
+	w.logger.Emit(ctx, record)
+
+	return len(p), nil
+}
+
+// Dropped returns the number of log records dropped because they could not be decoded or exported.
+func (w *OtlpWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// ForceFlush exports the queued log records.
+func (w *OtlpWriter) ForceFlush(ctx context.Context) error {
+	return w.provider.ForceFlush(ctx)
+}
+
+// Shutdown exports the queued log records and shuts down the exporter, the next log records being ignored.
+func (w *OtlpWriter) Shutdown(ctx context.Context) error {
+	return w.provider.Shutdown(ctx)
+}
+
+// record returns the OpenTelemetry log record of the decoded log record fields, with a context carrying their span
+// context if they have traceID and spanID fields.
+func (w *OtlpWriter) record(level zerolog.Level, fields map[string]interface{}) (context.Context, otellog.Record) {
+	ctx := context.Background()
+
+	var record otellog.Record
+
+	now := time.Now()
+	record.SetTimestamp(now)
+	record.SetObservedTimestamp(now)
+	record.SetSeverity(otlpSeverity(level))
+
+	if level != zerolog.NoLevel {
+		record.SetSeverityText(level.String())
+	}
+
+	if message, ok := fields[zerolog.MessageFieldName].(string); ok {
+		record.SetBody(otellog.StringValue(message))
+	}
+
+	traceID, _ := fields["traceID"].(string)
+	spanID, _ := fields["spanID"].(string)
+
+	if spanContext := otlpSpanContext(traceID, spanID); spanContext.IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+
+		delete(fields, "traceID")
+		delete(fields, "spanID")
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		switch name {
+		case zerolog.LevelFieldName, zerolog.MessageFieldName, w.timeField:
+			continue
+		default:
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	attributes := make([]otellog.KeyValue, 0, len(names))
+	for _, name := range names {
+		attributes = append(attributes, otellog.KeyValue{
+			Key:   name,
+			Value: otlpValue(fields[name]),
+		})
+	}
+
+	record.AddAttributes(attributes...)
+
+	return ctx, record
+}
+
+// otlpSeverity returns the OpenTelemetry severity of a log level.
+func otlpSeverity(level zerolog.Level) otellog.Severity {
+	switch level {
+	case zerolog.TraceLevel:
+		return otellog.SeverityTrace
+	case zerolog.DebugLevel:
+		return otellog.SeverityDebug
+	case zerolog.InfoLevel:
+		return otellog.SeverityInfo
+	case zerolog.WarnLevel:
+		return otellog.SeverityWarn
+	case zerolog.ErrorLevel:
+		return otellog.SeverityError
+	case zerolog.FatalLevel:
+		return otellog.SeverityFatal
+	case zerolog.PanicLevel:
+		return otellog.SeverityFatal4
+	default:
+		return otellog.SeverityUndefined
+	}
+}
+
+// otlpSpanContext returns the span context of hex encoded trace and span ids, invalid if they cannot be decoded.
+func otlpSpanContext(traceID string, spanID string) trace.SpanContext {
+	tid, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: tid,
+		SpanID:  sid,
+		Remote:  true,
+	})
+}
+
+// otlpValue returns the OpenTelemetry value of a decoded JSON value.
+func otlpValue(value interface{}) otellog.Value {
+	switch v := value.(type) {
+	case string:
+		return otellog.StringValue(v)
+	case bool:
+		return otellog.BoolValue(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return otellog.Int64Value(i)
+		}
+
+		if f, err := v.Float64(); err == nil {
+			return otellog.Float64Value(f)
+		}
+
+		return otellog.StringValue(v.String())
+	case []interface{}:
+		values := make([]otellog.Value, 0, len(v))
+		for _, item := range v {
+			values = append(values, otlpValue(item))
+		}
+
+		return otellog.SliceValue(values...)
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		keyValues := make([]otellog.KeyValue, 0, len(names))
+		for _, name := range names {
+			keyValues = append(keyValues, otellog.KeyValue{
+				Key:   name,
+				Value: otlpValue(v[name]),
+			})
+		}
+
+		return otellog.MapValue(keyValues...)
+	default:
+		return otellog.Value{}
+	}
+}
+
+// droppingExporter is an [sdklog.Exporter] counting the log records of the failed exports as dropped.
+type droppingExporter struct {
+	exporter sdklog.Exporter
+	dropped  *atomic.Uint64
+}
+
+// Export exports the log records, counting them as dropped on failure.
+func (e *droppingExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	err := e.exporter.Export(ctx, records)
+	if err != nil {
+		e.dropped.Add(uint64(len(records)))
+	}
+
+	return err
+}
+
+// Shutdown shuts down the exporter.
+func (e *droppingExporter) Shutdown(ctx context.Context) error {
+	return e.exporter.Shutdown(ctx)
+}
+
+// ForceFlush flushes the exporter.
+func (e *droppingExporter) ForceFlush(ctx context.Context) error {
+	return e.exporter.ForceFlush(ctx)
+}
@@ -0,0 +1,213 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOtlpWriterWithLogger(t *testing.T) {
+	t.Parallel()
+
+	exporter := logtest.NewDefaultTestOtlpExporter()
+
+	writer := log.NewOtlpWriter(log.OtlpWriterConfig{
+		Exporter: exporter,
+		Resource: resource.NewSchemaless(semconv.ServiceNameKey.String("test")),
+	})
+
+	buffer := logtest.NewDefaultTestLogBuffer()
+
+	logger := zerolog.
+		New(log.NewRedactingWriter(
+			zerolog.MultiLevelWriter(buffer, writer),
+			log.RedactionConfig{Fields: []string{"password"}},
+		)).
+		With().
+		Timestamp().
+		Str("service", "test").
+		Logger()
+
+	traceID, err := trace.TraceIDFromHex("c4ca4238a0b923820dcc509a6f75849b")
+	assert.NoError(t, err)
+
+	spanID, err := trace.SpanIDFromHex("c81e728d9d4c2f63")
+	assert.NoError(t, err)
+
+	logger.Debug().Msg("debug message")
+	logger.Warn().
+		Str("traceID", traceID.String()).
+		Str("spanID", spanID.String()).
+		Str("password", "secret").
+		Int("count", 42).
+		Float64("ratio", 0.5).
+		Bool("enabled", true).
+		Strs("tags", []string{"a", "b"}).
+		Msg("warn message")
+
+	assert.NoError(t, writer.ForceFlush(context.Background()))
+
+	// the log records are still written to the output
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "warn",
+		"message": "warn message",
+	})
+
+	records := exporter.Records()
+	assert.Len(t, records, 2)
+
+	assert.Equal(t, otellog.SeverityDebug, records[0].Severity())
+	assert.Equal(t, "debug", records[0].SeverityText())
+	assert.Equal(t, "debug message", records[0].Body().AsString())
+	assert.False(t, records[0].TraceID().IsValid())
+	assert.Equal(t, map[string]otellog.Value{"service": otellog.StringValue("test")}, recordAttributes(records[0]))
+
+	assert.Equal(t, otellog.SeverityWarn, records[1].Severity())
+	assert.Equal(t, "warn", records[1].SeverityText())
+	assert.Equal(t, "warn message", records[1].Body().AsString())
+	assert.Equal(t, traceID, records[1].TraceID())
+	assert.Equal(t, spanID, records[1].SpanID())
+	assert.False(t, records[1].Timestamp().IsZero())
+
+	attributes := recordAttributes(records[1])
+	assert.Equal(t, otellog.StringValue("test"), attributes["service"])
+	assert.Equal(t, otellog.StringValue(log.Redacted), attributes["password"])
+	assert.Equal(t, otellog.Int64Value(42), attributes["count"])
+	assert.Equal(t, otellog.Float64Value(0.5), attributes["ratio"])
+	assert.Equal(t, otellog.BoolValue(true), attributes["enabled"])
+	assert.True(t, otellog.SliceValue(otellog.StringValue("a"), otellog.StringValue("b")).Equal(attributes["tags"]))
+	assert.NotContains(t, attributes, "traceID")
+	assert.NotContains(t, attributes, "spanID")
+	assert.NotContains(t, attributes, "time")
+
+	res := records[1].Resource()
+	serviceName, ok := res.Set().Value(semconv.ServiceNameKey)
+	assert.True(t, ok)
+	assert.Equal(t, "test", serviceName.AsString())
+
+	assert.Equal(t, log.OtlpScopeName, records[1].InstrumentationScope().Name)
+	assert.Equal(t, uint64(0), writer.Dropped())
+}
+
+func TestOtlpWriterSeverities(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		level    zerolog.Level
+		severity otellog.Severity
+	}{
+		{zerolog.TraceLevel, otellog.SeverityTrace},
+		{zerolog.DebugLevel, otellog.SeverityDebug},
+		{zerolog.InfoLevel, otellog.SeverityInfo},
+		{zerolog.WarnLevel, otellog.SeverityWarn},
+		{zerolog.ErrorLevel, otellog.SeverityError},
+		{zerolog.FatalLevel, otellog.SeverityFatal},
+		{zerolog.PanicLevel, otellog.SeverityFatal4},
+		{zerolog.NoLevel, otellog.SeverityUndefined},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.level.String(), func(t *testing.T) {
+			t.Parallel()
+
+			exporter := logtest.NewDefaultTestOtlpExporter()
+
+			writer := log.NewOtlpWriter(log.OtlpWriterConfig{
+				Exporter: exporter,
+			})
+
+			_, err := writer.WriteLevel(tt.level, []byte(`{"message":"test"}`))
+			assert.NoError(t, err)
+
+			assert.NoError(t, writer.ForceFlush(context.Background()))
+
+			records := exporter.Records()
+			assert.Len(t, records, 1)
+			assert.Equal(t, tt.severity, records[0].Severity())
+		})
+	}
+}
+
+func TestOtlpWriterDroppedRecords(t *testing.T) {
+	t.Parallel()
+
+	exporter := logtest.NewDefaultTestOtlpExporter()
+
+	writer := log.NewOtlpWriter(log.OtlpWriterConfig{
+		Exporter: exporter,
+	})
+
+	// invalid records never fail the writes
+	record := []byte("invalid\n")
+
+	n, err := writer.Write(record)
+	assert.NoError(t, err)
+	assert.Equal(t, len(record), n)
+	assert.Equal(t, uint64(1), writer.Dropped())
+
+	// failed exports never fail the writes
+	exporter.FailWith(errors.New("export error"))
+
+	buffer := &bytes.Buffer{}
+
+	logger := zerolog.New(zerolog.MultiLevelWriter(buffer, writer))
+
+	logger.Info().Msg("first message")
+	logger.Info().Msg("second message")
+
+	// the export errors are reported to the OpenTelemetry error handler
+	assert.NoError(t, writer.ForceFlush(context.Background()))
+	assert.Equal(t, uint64(3), writer.Dropped())
+	assert.Len(t, exporter.Records(), 0)
+
+	assert.Contains(t, buffer.String(), "first message")
+	assert.Contains(t, buffer.String(), "second message")
+
+	// the next exports succeed
+	exporter.FailWith(nil)
+
+	logger.Info().Msg("third message")
+
+	assert.NoError(t, writer.Shutdown(context.Background()))
+	assert.Equal(t, uint64(3), writer.Dropped())
+	assert.Len(t, exporter.Records(), 1)
+}
+
+func TestNewOtlpGrpcExporter(t *testing.T) {
+	t.Parallel()
+
+	exporter, err := log.NewOtlpGrpcExporter(context.Background(), log.OtlpConfig{
+		Endpoint: "localhost:4317",
+		Headers:  map[string]string{"x-api-key": "key"},
+		Insecure: true,
+	})
+	assert.NoError(t, err)
+	assert.Implements(t, (*sdklog.Exporter)(nil), exporter)
+
+	assert.NoError(t, exporter.Shutdown(context.Background()))
+}
+
+func recordAttributes(record sdklog.Record) map[string]otellog.Value {
+	attributes := map[string]otellog.Value{}
+
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attributes[kv.Key] = kv.Value
+
+		return true
+	})
+
+	return attributes
+}
@@ -0,0 +1,253 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Redacted is the value replacing the redacted log fields values.
+const Redacted = "[redacted]"
+
+var (
+	redactedValue      = []byte(`"` + Redacted + `"`)
+	errRedactNotObject = errors.New("log record is not a JSON object")
+)
+
+// RedactionConfig is the configuration of a [RedactingWriter].
+type RedactionConfig struct {
+	Fields []string // names of the fields to redact, matched case-insensitively
+	Deep   bool     // to also redact the fields of the nested JSON payloads (like the ones logged with RawJSON)
+}
+
+// RedactingWriter is a [zerolog.LevelWriter] replacing the values of the configured log fields by [Redacted], before
+// writing the log records to its underlying writer.
+//
+// The log records not containing any of the configured fields names are written as is, without being decoded.
+type RedactingWriter struct {
+	writer io.Writer
+	fields map[string]struct{}
+	quoted [][]byte
+	deep   bool
+}
+
+// NewRedactingWriter returns a new [RedactingWriter] for a provided writer and [RedactionConfig].
+func NewRedactingWriter(writer io.Writer, config RedactionConfig) *RedactingWriter {
+	w := &RedactingWriter{
+		writer: writer,
+		fields: make(map[string]struct{}, len(config.Fields)),
+		deep:   config.Deep,
+	}
+
+	for _, field := range config.Fields {
+		field = strings.ToLower(field)
+		if _, ok := w.fields[field]; ok || field == "" {
+			continue
+		}
+
+		w.fields[field] = struct{}{}
+		w.quoted = append(w.quoted, []byte(`"`+field+`"`))
+	}
+
+	return w
+}
+
+// Write writes a log record without level.
+func (w *RedactingWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel writes a log record, with the values of the configured fields redacted.
+func (w *RedactingWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	record := w.redact(p)
+
+	var err error
+	if levelWriter, ok := w.writer.(zerolog.LevelWriter); ok {
+		_, err = levelWriter.WriteLevel(level, record)
+	} else {
+		_, err = w.writer.Write(record)
+	}
+
+	// the caller expects the length of the provided record
+	return len(p), err
+}
+
+// redact returns the provided log record with the configured fields redacted, or as is if none of them is present.
+func (w *RedactingWriter) redact(p []byte) []byte {
+	if len(w.quoted) == 0 || !w.mayContainField(p) {
+		return p
+	}
+
+	trimmed := bytes.TrimRight(p, "\n")
+
+	buf := &bytes.Buffer{}
+	buf.Grow(len(p))
+
+	redacted, err := w.redactObject(buf, trimmed)
+	if err != nil || !redacted {
+		return p
+	}
+
+	buf.Write(p[len(trimmed):])
+
+	return buf.Bytes()
+}
+
+// mayContainField returns true if one of the configured fields names is present in the provided log record (as a
+// case-insensitive quoted string, that may be a key or a value).
+func (w *RedactingWriter) mayContainField(p []byte) bool {
+	for _, quoted := range w.quoted {
+		if containsFold(p, quoted) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactObject writes in buf the provided JSON object with the configured fields redacted, and returns true if at least
+// one field was redacted. The nested objects and arrays are only scanned in deep mode.
+func (w *RedactingWriter) redactObject(buf *bytes.Buffer, data []byte) (bool, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return false, errRedactNotObject
+	}
+
+	buf.WriteByte('{')
+
+	redacted := false
+
+	for i := 0; decoder.More(); i++ {
+		token, err = decoder.Token()
+		if err != nil {
+			return false, err
+		}
+
+		key, _ := token.(string)
+
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return false, err
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return false, err
+		}
+
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+
+		// the fields names are matched case-insensitively
+		if _, ok := w.fields[strings.ToLower(key)]; ok {
+			buf.Write(redactedValue)
+			redacted = true
+
+			continue
+		}
+
+		if w.deep {
+			valueRedacted, err := w.redactValue(buf, value)
+			if err != nil {
+				return false, err
+			}
+
+			redacted = redacted || valueRedacted
+
+			continue
+		}
+
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return redacted, nil
+}
+
+// redactValue writes in buf the provided JSON value, with the configured fields of its nested objects redacted.
+func (w *RedactingWriter) redactValue(buf *bytes.Buffer, value json.RawMessage) (bool, error) {
+	trimmed := bytes.TrimSpace(value)
+
+	if len(trimmed) == 0 || !w.mayContainField(trimmed) {
+		buf.Write(value)
+
+		return false, nil
+	}
+
+	switch trimmed[0] {
+	case '{':
+		return w.redactObject(buf, trimmed)
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return false, err
+		}
+
+		buf.WriteByte('[')
+
+		redacted := false
+
+		for i, item := range items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			itemRedacted, err := w.redactValue(buf, item)
+			if err != nil {
+				return false, err
+			}
+
+			redacted = redacted || itemRedacted
+		}
+
+		buf.WriteByte(']')
+
+		return redacted, nil
+	default:
+		buf.Write(value)
+
+		return false, nil
+	}
+}
+
+// containsFold reports whether the lower-cased quoted needle is within s, case-insensitively.
+func containsFold(s []byte, needle []byte) bool {
+	for i := bytes.IndexByte(s, '"'); i >= 0 && len(s)-i >= len(needle); {
+		// cheap check of the first character before the case-insensitive comparison
+		if len(needle) > 1 && lowerASCII(s[i+1]) == needle[1] && bytes.EqualFold(s[i:i+len(needle)], needle) {
+			return true
+		}
+
+		next := bytes.IndexByte(s[i+1:], '"')
+		if next < 0 {
+			return false
+		}
+
+		i += next + 1
+	}
+
+	return false
+}
+
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+
+	return c
+}
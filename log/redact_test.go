@@ -0,0 +1,182 @@
+package log_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactingWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   log.RedactionConfig
+		record   string
+		expected string
+	}{
+		{
+			name:     "without fields",
+			config:   log.RedactionConfig{},
+			record:   `{"level":"info","password":"secret"}` + "\n",
+			expected: `{"level":"info","password":"secret"}` + "\n",
+		},
+		{
+			name:     "without matching fields",
+			config:   log.RedactionConfig{Fields: []string{"password"}},
+			record:   `{"level":"info","user":"john"}` + "\n",
+			expected: `{"level":"info","user":"john"}` + "\n",
+		},
+		{
+			name:     "with matching fields",
+			config:   log.RedactionConfig{Fields: []string{"password", "token"}},
+			record:   `{"level":"info","password":"secret","token":42,"user":"john"}` + "\n",
+			expected: `{"level":"info","password":"[redacted]","token":"[redacted]","user":"john"}` + "\n",
+		},
+		{
+			name:     "with case-insensitive matching fields",
+			config:   log.RedactionConfig{Fields: []string{"Authorization"}},
+			record:   `{"level":"info","AUTHORIZATION":"Bearer xxx","authorization":"Bearer yyy"}` + "\n",
+			expected: `{"level":"info","AUTHORIZATION":"[redacted]","authorization":"[redacted]"}` + "\n",
+		},
+		{
+			name:     "with matching value only",
+			config:   log.RedactionConfig{Fields: []string{"email"}},
+			record:   `{"level":"info","field":"email"}` + "\n",
+			expected: `{"level":"info","field":"email"}` + "\n",
+		},
+		{
+			name:     "with matching nested fields without deep mode",
+			config:   log.RedactionConfig{Fields: []string{"password"}},
+			record:   `{"level":"info","payload":{"password":"secret"}}` + "\n",
+			expected: `{"level":"info","payload":{"password":"secret"}}` + "\n",
+		},
+		{
+			name:     "with matching nested fields in deep mode",
+			config:   log.RedactionConfig{Fields: []string{"password", "email"}, Deep: true},
+			record:   `{"level":"info","payload":{"user":{"email":"john@example.com","age":42},"password":"secret"},"count":1.50}` + "\n",
+			expected: `{"level":"info","payload":{"user":{"email":"[redacted]","age":42},"password":"[redacted]"},"count":1.50}` + "\n",
+		},
+		{
+			name:     "with matching nested arrays fields in deep mode",
+			config:   log.RedactionConfig{Fields: []string{"token"}, Deep: true},
+			record:   `{"level":"info","items":[{"token":"a"},{"id":1},"token",[{"TOKEN":"b"}]]}` + "\n",
+			expected: `{"level":"info","items":[{"token":"[redacted]"},{"id":1},"token",[{"TOKEN":"[redacted]"}]]}` + "\n",
+		},
+		{
+			name:     "with matching fields in an invalid record",
+			config:   log.RedactionConfig{Fields: []string{"password"}},
+			record:   `not json "password"`,
+			expected: `not json "password"`,
+		},
+	}
+
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+
+		writer := log.NewRedactingWriter(buf, tt.config)
+
+		n, err := writer.Write([]byte(tt.record))
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, len(tt.record), n, tt.name)
+		assert.Equal(t, tt.expected, buf.String(), tt.name)
+	}
+}
+
+func TestRedactingWriterWithLevelWriter(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	levelWriter := &testLevelWriter{writer: buf}
+
+	writer := log.NewRedactingWriter(levelWriter, log.RedactionConfig{Fields: []string{"password"}})
+
+	_, err := writer.WriteLevel(zerolog.WarnLevel, []byte(`{"password":"secret"}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, zerolog.WarnLevel, levelWriter.level)
+	assert.Equal(t, `{"password":"[redacted]"}`, buf.String())
+}
+
+func TestRedactingWriterWithLogger(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+
+	logger := zerolog.New(log.NewRedactingWriter(buf, log.RedactionConfig{
+		Fields: []string{"email", "password"},
+		Deep:   true,
+	}))
+
+	logger.Info().
+		Str("Email", "john@example.com").
+		RawJSON("payload", []byte(`{"credentials":{"password":"secret"}}`)).
+		Msg("test message")
+
+	assert.Equal(
+		t,
+		`{"level":"info","Email":"[redacted]","payload":{"credentials":{"password":"[redacted]"}},"message":"test message"}`+"\n",
+		buf.String(),
+	)
+}
+
+type testLevelWriter struct {
+	writer io.Writer
+	level  zerolog.Level
+}
+
+func (w *testLevelWriter) Write(p []byte) (int, error) {
+	return w.writer.Write(p)
+}
+
+func (w *testLevelWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.level = level
+
+	return w.writer.Write(p)
+}
+
+func BenchmarkRedactingWriter(b *testing.B) {
+	benchmarks := []struct {
+		name   string
+		config log.RedactionConfig
+		record []byte
+	}{
+		{
+			name:   "without fields",
+			config: log.RedactionConfig{},
+			record: []byte(`{"level":"info","service":"app","user":"john","message":"test message"}` + "\n"),
+		},
+		{
+			name:   "without matching fields",
+			config: log.RedactionConfig{Fields: []string{"email", "password", "token", "authorization"}},
+			record: []byte(`{"level":"info","service":"app","user":"john","message":"test message"}` + "\n"),
+		},
+		{
+			name:   "with matching fields",
+			config: log.RedactionConfig{Fields: []string{"email", "password", "token", "authorization"}},
+			record: []byte(`{"level":"info","service":"app","password":"secret","message":"test message"}` + "\n"),
+		},
+		{
+			name:   "with matching nested fields in deep mode",
+			config: log.RedactionConfig{Fields: []string{"email", "password", "token", "authorization"}, Deep: true},
+			record: []byte(`{"level":"info","service":"app","payload":{"user":{"password":"secret"}},"message":"test message"}` + "\n"),
+		},
+	}
+
+	for _, bm := range benchmarks {
+		writer := log.NewRedactingWriter(io.Discard, bm.config)
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				//nolint:errcheck
+				writer.Write(bm.record)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// droppedRecords counts the log records dropped by all the [Sampler] instances.
+var droppedRecords atomic.Uint64
+
+// DroppedRecords returns the total number of log records dropped by sampling, by all the [Sampler] instances.
+func DroppedRecords() uint64 {
+	return droppedRecords.Load()
+}
+
+// SamplerConfig is the configuration of a [Sampler].
+type SamplerConfig struct {
+	Levels       map[zerolog.Level]zerolog.Sampler // samplers of the log records, per level
+	Default      zerolog.Sampler                   // sampler of the levels without a specific sampler (nil to not sample them)
+	SampleErrors bool                              // to also sample the error (and above) log records, never sampled otherwise
+}
+
+// Sampler is a [zerolog.Sampler] sampling the log records per level, and counting the dropped ones.
+//
+// The log records without level (like the audit events) are never sampled, nor the error (and above) ones unless the
+// SampleErrors config is true.
+type Sampler struct {
+	config  SamplerConfig
+	dropped atomic.Uint64
+}
+
+// NewSampler returns a new [Sampler] for the provided samplers per level, never sampling the error (and above) log
+// records.
+func NewSampler(levels map[zerolog.Level]zerolog.Sampler) *Sampler {
+	return NewSamplerWithConfig(SamplerConfig{
+		Levels: levels,
+	})
+}
+
+// NewSamplerWithConfig returns a new [Sampler] for a provided [SamplerConfig].
+func NewSamplerWithConfig(config SamplerConfig) *Sampler {
+	if config.Levels == nil {
+		config.Levels = map[zerolog.Level]zerolog.Sampler{}
+	}
+
+	return &Sampler{
+		config: config,
+	}
+}
+
+// Sample returns true if a log record of the provided level should be logged.
+func (s *Sampler) Sample(level zerolog.Level) bool {
+	if level == zerolog.NoLevel || (level >= zerolog.ErrorLevel && !s.config.SampleErrors) {
+		return true
+	}
+
+	sampler, ok := s.config.Levels[level]
+	if !ok {
+		sampler = s.config.Default
+	}
+
+	if sampler == nil || sampler.Sample(level) {
+		return true
+	}
+
+	s.dropped.Add(1)
+	droppedRecords.Add(1)
+
+	return false
+}
+
+// Dropped returns the number of log records dropped by the [Sampler].
+func (s *Sampler) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// NewBurstSampler returns a [zerolog.Sampler] logging the first burst log records of each period, and dropping the
+// next ones.
+func NewBurstSampler(burst uint32, period time.Duration) zerolog.Sampler {
+	return &zerolog.BurstSampler{
+		Burst:  burst,
+		Period: period,
+	}
+}
+
+// NewRatioSampler returns a [zerolog.Sampler] logging randomly a given ratio (from 0.0 to 1.0) of the log records.
+func NewRatioSampler(ratio float64) zerolog.Sampler {
+	return ratioSampler(ratio)
+}
+
+type ratioSampler float64
+
+// Sample returns true if a log record should be logged.
+func (r ratioSampler) Sample(zerolog.Level) bool {
+	switch {
+	case r <= 0:
+		return false
+	case r >= 1:
+		return true
+	default:
+		//nolint:gosec
+		return rand.Float64() < float64(r)
+	}
+}
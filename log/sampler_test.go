@@ -0,0 +1,139 @@
+package log_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func countLogRecords(t *testing.T, buffer logtest.TestLogBuffer, level string) int {
+	t.Helper()
+
+	records, err := buffer.Records()
+	assert.NoError(t, err)
+
+	count := 0
+	for _, record := range records {
+		if recordLevel, err := record.Level(); err == nil && recordLevel == level {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestSamplerWithBurst(t *testing.T) {
+	t.Parallel()
+
+	buffer := logtest.NewDefaultTestLogBuffer()
+
+	sampler := log.NewSampler(map[zerolog.Level]zerolog.Sampler{
+		zerolog.InfoLevel: log.NewBurstSampler(10, time.Hour),
+	})
+
+	logger := zerolog.New(buffer).Sample(sampler)
+
+	for i := 0; i < 100; i++ {
+		logger.Info().Msg("info message")
+		logger.Warn().Msg("warn message")
+		logger.Error().Msg("error message")
+		logger.Log().Msg("no level message")
+	}
+
+	// only the sampled level is sampled
+	assert.Equal(t, 10, countLogRecords(t, buffer, "info"))
+	assert.Equal(t, 100, countLogRecords(t, buffer, "warn"))
+	assert.Equal(t, 100, countLogRecords(t, buffer, "error"))
+	assert.Equal(t, uint64(90), sampler.Dropped())
+	assert.GreaterOrEqual(t, log.DroppedRecords(), uint64(90))
+}
+
+func TestSamplerWithDefault(t *testing.T) {
+	t.Parallel()
+
+	buffer := logtest.NewDefaultTestLogBuffer()
+
+	sampler := log.NewSamplerWithConfig(log.SamplerConfig{
+		Levels: map[zerolog.Level]zerolog.Sampler{
+			zerolog.WarnLevel: log.NewRatioSampler(1),
+		},
+		Default: log.NewBurstSampler(5, time.Hour),
+	})
+
+	logger := zerolog.New(buffer).Sample(sampler)
+
+	for i := 0; i < 20; i++ {
+		logger.Debug().Msg("debug message")
+		logger.Info().Msg("info message")
+		logger.Warn().Msg("warn message")
+		logger.Error().Msg("error message")
+	}
+
+	// the default sampler is shared by the levels without a specific sampler
+	assert.Equal(t, 5, countLogRecords(t, buffer, "debug")+countLogRecords(t, buffer, "info"))
+	assert.Equal(t, 20, countLogRecords(t, buffer, "warn"))
+	assert.Equal(t, 20, countLogRecords(t, buffer, "error"))
+	assert.Equal(t, uint64(35), sampler.Dropped())
+}
+
+func TestSamplerWithSampledErrors(t *testing.T) {
+	t.Parallel()
+
+	buffer := logtest.NewDefaultTestLogBuffer()
+
+	sampler := log.NewSamplerWithConfig(log.SamplerConfig{
+		Default:      log.NewRatioSampler(0),
+		SampleErrors: true,
+	})
+
+	logger := zerolog.New(buffer).Sample(sampler)
+
+	for i := 0; i < 10; i++ {
+		logger.Error().Msg("error message")
+		logger.Log().Msg("no level message")
+	}
+
+	// the records without level are never sampled
+	assert.Equal(t, 0, countLogRecords(t, buffer, "error"))
+	assert.Equal(t, uint64(10), sampler.Dropped())
+
+	records, err := buffer.Records()
+	assert.NoError(t, err)
+	assert.Len(t, records, 10)
+}
+
+func TestSamplerWithoutSamplers(t *testing.T) {
+	t.Parallel()
+
+	sampler := log.NewSampler(nil)
+
+	for _, level := range []zerolog.Level{zerolog.TraceLevel, zerolog.DebugLevel, zerolog.InfoLevel, zerolog.WarnLevel} {
+		assert.True(t, sampler.Sample(level))
+	}
+
+	assert.Equal(t, uint64(0), sampler.Dropped())
+}
+
+func TestRatioSampler(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, log.NewRatioSampler(0).Sample(zerolog.InfoLevel))
+	assert.False(t, log.NewRatioSampler(-1).Sample(zerolog.InfoLevel))
+	assert.True(t, log.NewRatioSampler(1).Sample(zerolog.InfoLevel))
+	assert.True(t, log.NewRatioSampler(2).Sample(zerolog.InfoLevel))
+
+	sampled := 0
+	sampler := log.NewRatioSampler(0.25)
+
+	for i := 0; i < 10000; i++ {
+		if sampler.Sample(zerolog.InfoLevel) {
+			sampled++
+		}
+	}
+
+	assert.InDelta(t, 2500, sampled, 300)
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"errors"
+	"runtime"
+
+	pkgerrors "github.com/pkg/errors"
+	zerologpkgerrors "github.com/rs/zerolog/pkgerrors"
+)
+
+// stackTracer is the interface of the errors carrying a stack trace, like the ones created or wrapped with
+// [github.com/pkg/errors].
+type stackTracer interface {
+	error
+	StackTrace() pkgerrors.StackTrace
+}
+
+// WrapWithStack wraps an error with the stack trace of its caller, for the errors not carrying one (like the libraries
+// ones), to be logged with a stack field (see [WithErrorStack]). The errors already carrying a stack trace (even
+// wrapped) are returned as is, nil if the error is nil.
+func WrapWithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var tracer stackTracer
+	if errors.As(err, &tracer) {
+		return err
+	}
+
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+
+	return &stackError{
+		err:   err,
+		stack: pcs[:n],
+	}
+}
+
+// ErrorStack returns the stack trace carried by an error (even wrapped), as a list of frames with their source, line
+// and func fields, or nil if the error does not carry any (see [WrapWithStack]).
+//
+// It is the stack marshaler of the errors logged with [zerolog.Event.Err] by the loggers with error stacks enabled.
+func ErrorStack(err error) interface{} {
+	var tracer stackTracer
+	if !errors.As(err, &tracer) {
+		return nil
+	}
+
+	return zerologpkgerrors.MarshalStack(tracer)
+}
+
+// stackError is an error carrying the stack trace it was wrapped with (see [WrapWithStack]).
+type stackError struct {
+	err   error
+	stack []uintptr
+}
+
+// Error returns the wrapped error message.
+func (e *stackError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// StackTrace returns the stack trace the error was wrapped with.
+func (e *stackError) StackTrace() pkgerrors.StackTrace {
+	frames := make(pkgerrors.StackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		frames[i] = pkgerrors.Frame(pc)
+	}
+
+	return frames
+}
@@ -0,0 +1,113 @@
+package log_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapWithStack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil error", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, log.WrapWithStack(nil))
+	})
+
+	t.Run("error without stack", func(t *testing.T) {
+		t.Parallel()
+
+		err := errors.New("test error")
+
+		wrappedErr := log.WrapWithStack(err)
+		assert.NotEqual(t, err, wrappedErr)
+		assert.Equal(t, "test error", wrappedErr.Error())
+		assert.True(t, errors.Is(wrappedErr, err))
+		assert.NotNil(t, log.ErrorStack(wrappedErr))
+	})
+
+	t.Run("error with stack", func(t *testing.T) {
+		t.Parallel()
+
+		err := pkgerrors.New("test error")
+
+		assert.Equal(t, err, log.WrapWithStack(err))
+	})
+
+	t.Run("wrapped error with stack", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("wrapped: %w", pkgerrors.New("test error"))
+
+		assert.Equal(t, err, log.WrapWithStack(err))
+	})
+}
+
+func TestErrorStack(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, log.ErrorStack(nil))
+	assert.Nil(t, log.ErrorStack(errors.New("test error")))
+
+	err := fmt.Errorf("wrapped: %w", log.WrapWithStack(errors.New("test error")))
+
+	stack, ok := log.ErrorStack(err).([]map[string]string)
+	assert.True(t, ok)
+	assert.NotEmpty(t, stack)
+
+	assert.Equal(t, "TestErrorStack", stack[0]["func"])
+	assert.Equal(t, "stack_test.go", stack[0]["source"])
+	assert.NotEmpty(t, stack[0]["line"])
+}
+
+func TestErrorStackLogging(t *testing.T) {
+	t.Parallel()
+
+	buffer := logtest.NewDefaultTestLogBuffer()
+
+	logger := log.FromZerolog(zerolog.New(buffer).With().Stack().Logger())
+
+	logger.Error().Err(log.WrapWithStack(errors.New("with stack"))).Msg("first message")
+	logger.Error().Err(errors.New("without stack")).Msg("second message")
+
+	records, err := buffer.Records()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	// the errors carrying a stack trace are logged with a stack field
+	stack, err := records[0].Attribute("stack")
+	assert.NoError(t, err)
+
+	frames, ok := stack.([]interface{})
+	assert.True(t, ok)
+	assert.NotEmpty(t, frames)
+
+	frame, ok := frames[0].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "TestErrorStackLogging", frame["func"])
+	assert.Equal(t, "stack_test.go", frame["source"])
+	assert.NotEmpty(t, frame["line"])
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "with stack",
+		"message": "first message",
+	})
+
+	// the other errors are logged without stack field
+	_, err = records[1].Attribute("stack")
+	assert.Error(t, err)
+
+	logtest.AssertHasLogRecord(t, buffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "without stack",
+		"message": "second message",
+	})
+}
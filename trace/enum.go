@@ -91,3 +91,48 @@ func FetchSampler(s string) Sampler {
 		return ParentBasedAlwaysOnSampler
 	}
 }
+
+// Propagator is an enum for the supported propagators.
+type Propagator int
+
+const (
+	TraceContextPropagator Propagator = iota
+	BaggagePropagator
+	B3Propagator
+	B3MultiPropagator
+	JaegerPropagator
+)
+
+// String returns a string representation of the [Propagator].
+//
+//nolint:exhaustive
+func (p Propagator) String() string {
+	switch p {
+	case BaggagePropagator:
+		return Baggage
+	case B3Propagator:
+		return B3
+	case B3MultiPropagator:
+		return B3Multi
+	case JaegerPropagator:
+		return Jaeger
+	default:
+		return TraceContext
+	}
+}
+
+// FetchPropagator returns a [Propagator] for a given value.
+func FetchPropagator(p string) Propagator {
+	switch strings.ToLower(p) {
+	case Baggage:
+		return BaggagePropagator
+	case B3:
+		return B3Propagator
+	case B3Multi:
+		return B3MultiPropagator
+	case Jaeger:
+		return JaegerPropagator
+	default:
+		return TraceContextPropagator
+	}
+}
@@ -82,3 +82,42 @@ func TestFetchSampler(t *testing.T) {
 		assert.Equal(t, tt.expected, trace.FetchSampler(tt.input))
 	}
 }
+
+func TestPropagatorAsString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		propagator trace.Propagator
+		expected   string
+	}{
+		{trace.BaggagePropagator, trace.Baggage},
+		{trace.B3Propagator, trace.B3},
+		{trace.B3MultiPropagator, trace.B3Multi},
+		{trace.JaegerPropagator, trace.Jaeger},
+		{trace.TraceContextPropagator, trace.TraceContext},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.propagator.String())
+	}
+}
+
+func TestFetchPropagator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected trace.Propagator
+	}{
+		{trace.Baggage, trace.BaggagePropagator},
+		{trace.B3, trace.B3Propagator},
+		{"B3Multi", trace.B3MultiPropagator},
+		{trace.Jaeger, trace.JaegerPropagator},
+		{trace.TraceContext, trace.TraceContextPropagator},
+		{"default", trace.TraceContextPropagator},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, trace.FetchPropagator(tt.input))
+	}
+}
@@ -2,7 +2,6 @@ package trace
 
 import (
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
@@ -33,6 +32,7 @@ func NewDefaultTracerProviderFactory() TracerProviderFactory {
 //		trace.WithResource(resource.Default()),                   // use the default resource
 //		trace.WithSampler(trace.NewParentBasedAlwaysOnSampler()), // use parent based always on sampling
 //		trace.WithSpanProcessor(trace.NewNoopSpanProcessor()),    // use noop processor (void trace spans)
+//		trace.WithPropagator(trace.NewDefaultPropagator()),       // use W3C trace context and baggage propagation
 //	)
 //
 // [OTEL TracerProvider]: https://github.com/open-telemetry/opentelemetry-go
@@ -58,12 +58,7 @@ func (f *DefaultTracerProviderFactory) Create(options ...TracerProviderOption) (
 	if appliedOptions.Global {
 		otel.SetTracerProvider(tracerProvider)
 
-		otel.SetTextMapPropagator(
-			propagation.NewCompositeTextMapPropagator(
-				propagation.TraceContext{},
-				propagation.Baggage{},
-			),
-		)
+		otel.SetTextMapPropagator(appliedOptions.Propagator)
 	}
 
 	return tracerProvider, nil
@@ -1,6 +1,7 @@
 package trace
 
 import (
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
@@ -11,6 +12,7 @@ type Options struct {
 	Resource       *resource.Resource
 	Sampler        trace.Sampler
 	SpanProcessors []trace.SpanProcessor
+	Propagator     propagation.TextMapPropagator
 }
 
 // DefaultTracerProviderOptions are the default options used in the [TracerProviderFactory].
@@ -20,6 +22,7 @@ func DefaultTracerProviderOptions() Options {
 		Resource:       resource.Default(),
 		Sampler:        NewParentBasedAlwaysOnSampler(),
 		SpanProcessors: []trace.SpanProcessor{},
+		Propagator:     NewDefaultPropagator(),
 	}
 }
 
@@ -61,3 +64,12 @@ func WithSpanProcessor(spanProcessor trace.SpanProcessor) TracerProviderOption {
 		o.SpanProcessors = append(o.SpanProcessors, spanProcessor)
 	}
 }
+
+// WithPropagator is used to set the propagator to set as global with the [OTEL TracerProvider] (see [NewPropagator]).
+//
+// [OTEL TracerProvider]: https://github.com/open-telemetry/opentelemetry-go
+func WithPropagator(propagator propagation.TextMapPropagator) TracerProviderOption {
+	return func(o *Options) {
+		o.Propagator = propagator
+	}
+}
@@ -53,3 +53,14 @@ func TestWithSpanProcessor(t *testing.T) {
 	trace.WithSpanProcessor(p2)(&options)
 	assert.Equal(t, []otelsdktrace.SpanProcessor{p1, p2}, options.SpanProcessors)
 }
+
+func TestWithPropagator(t *testing.T) {
+	t.Parallel()
+
+	var options trace.Options
+
+	propagator := trace.NewPropagator(trace.B3Propagator)
+
+	trace.WithPropagator(propagator)(&options)
+	assert.Equal(t, propagator, options.Propagator)
+}
@@ -0,0 +1,150 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TraceContext = "tracecontext" // W3C trace context propagation
+	Baggage      = "baggage"      // W3C baggage propagation
+	B3           = "b3"           // B3 single header propagation
+	B3Multi      = "b3multi"      // B3 multiple headers propagation
+	Jaeger       = "jaeger"       // Jaeger uber-trace-id header propagation
+)
+
+// NewDefaultPropagator returns a [propagation.TextMapPropagator] with W3C trace context and baggage propagation.
+func NewDefaultPropagator() propagation.TextMapPropagator {
+	return NewPropagator(TraceContextPropagator, BaggagePropagator)
+}
+
+// NewPropagator returns a composite [propagation.TextMapPropagator] for a provided list of [Propagator]: the trace
+// context is injected with all of them, and extracted with the last one finding it.
+//
+// Without provided [Propagator], W3C trace context and baggage propagation is used.
+func NewPropagator(propagators ...Propagator) propagation.TextMapPropagator {
+	if len(propagators) == 0 {
+		return NewDefaultPropagator()
+	}
+
+	textMapPropagators := make([]propagation.TextMapPropagator, 0, len(propagators))
+
+	for _, p := range propagators {
+		switch p {
+		case BaggagePropagator:
+			textMapPropagators = append(textMapPropagators, propagation.Baggage{})
+		case B3Propagator:
+			textMapPropagators = append(textMapPropagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case B3MultiPropagator:
+			textMapPropagators = append(textMapPropagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case JaegerPropagator:
+			textMapPropagators = append(textMapPropagators, NewJaegerPropagator())
+		default:
+			textMapPropagators = append(textMapPropagators, propagation.TraceContext{})
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(textMapPropagators...)
+}
+
+const (
+	jaegerHeader       = "uber-trace-id"
+	jaegerFlagSampled  = 0x01
+	jaegerFlagDebug    = 0x02
+	jaegerTraceIdSize  = 32
+	jaegerSpanIdSize   = 16
+	jaegerHeaderFields = 4
+)
+
+// jaegerPropagator is a [propagation.TextMapPropagator] for the Jaeger uber-trace-id header, formatted as
+// {trace-id}:{span-id}:{parent-span-id}:{flags}.
+type jaegerPropagator struct{}
+
+// NewJaegerPropagator returns a [propagation.TextMapPropagator] for the Jaeger uber-trace-id header.
+func NewJaegerPropagator() propagation.TextMapPropagator {
+	return jaegerPropagator{}
+}
+
+// Inject injects the span context of the provided context in the uber-trace-id header.
+func (p jaegerPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	spanContext := oteltrace.SpanFromContext(ctx).SpanContext()
+	if !spanContext.IsValid() {
+		return
+	}
+
+	var flags byte
+	if spanContext.IsSampled() {
+		flags = jaegerFlagSampled
+	}
+
+	carrier.Set(
+		jaegerHeader,
+		fmt.Sprintf("%s:%s:0:%x", spanContext.TraceID(), spanContext.SpanID(), flags),
+	)
+}
+
+// Extract returns a copy of the provided context, with the remote span context of the uber-trace-id header.
+func (p jaegerPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	spanContext, ok := p.extract(carrier.Get(jaegerHeader))
+	if !ok {
+		return ctx
+	}
+
+	return oteltrace.ContextWithRemoteSpanContext(ctx, spanContext)
+}
+
+// Fields returns the header names set by Inject.
+func (p jaegerPropagator) Fields() []string {
+	return []string{jaegerHeader}
+}
+
+func (p jaegerPropagator) extract(header string) (oteltrace.SpanContext, bool) {
+	parts := strings.Split(header, ":")
+	if len(parts) != jaegerHeaderFields {
+		return oteltrace.SpanContext{}, false
+	}
+
+	if len(parts[0]) > jaegerTraceIdSize || len(parts[1]) > jaegerSpanIdSize {
+		return oteltrace.SpanContext{}, false
+	}
+
+	traceID, err := oteltrace.TraceIDFromHex(leftPadHex(parts[0], jaegerTraceIdSize))
+	if err != nil {
+		return oteltrace.SpanContext{}, false
+	}
+
+	spanID, err := oteltrace.SpanIDFromHex(leftPadHex(parts[1], jaegerSpanIdSize))
+	if err != nil {
+		return oteltrace.SpanContext{}, false
+	}
+
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return oteltrace.SpanContext{}, false
+	}
+
+	var traceFlags oteltrace.TraceFlags
+	if flags&(jaegerFlagSampled|jaegerFlagDebug) != 0 {
+		traceFlags = oteltrace.FlagsSampled
+	}
+
+	spanContext := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: traceFlags,
+		Remote:     true,
+	})
+
+	return spanContext, spanContext.IsValid()
+}
+
+// leftPadHex left pads an hex value with zeros, since Jaeger allows to omit the leading zeros of the ids.
+func leftPadHex(value string, size int) string {
+	return strings.Repeat("0", size-len(value)) + value
+}
@@ -0,0 +1,223 @@
+package trace_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ankorstore/yokai/trace"
+	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	otelsdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testTraceId = "c4ca71e03e42c2c3d54293a6e2608bfa"
+	testSpanId  = "8d0fdc8a74baaaea"
+)
+
+func TestNewDefaultPropagator(t *testing.T) {
+	t.Parallel()
+
+	propagator := trace.NewDefaultPropagator()
+
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, propagator.Fields())
+	assert.ElementsMatch(t, propagator.Fields(), trace.NewPropagator().Fields())
+}
+
+func TestNewPropagatorFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		propagator trace.Propagator
+		expected   []string
+	}{
+		{trace.TraceContextPropagator, []string{"traceparent", "tracestate"}},
+		{trace.BaggagePropagator, []string{"baggage"}},
+		{trace.B3Propagator, []string{"b3"}},
+		{trace.B3MultiPropagator, []string{"x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-flags"}},
+		{trace.JaegerPropagator, []string{"uber-trace-id"}},
+	}
+
+	for _, tt := range tests {
+		assert.ElementsMatch(t, tt.expected, trace.NewPropagator(tt.propagator).Fields(), tt.propagator.String())
+	}
+}
+
+func TestNewPropagatorExtractsB3IntoContinuingTrace(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewDefaultTestTraceExporter()
+
+	tracerProvider, err := trace.NewDefaultTracerProviderFactory().Create(
+		trace.Global(false),
+		trace.WithSpanProcessor(trace.NewTestSpanProcessor(exporter)),
+	)
+	assert.NoError(t, err)
+
+	propagator := trace.NewPropagator(trace.TraceContextPropagator, trace.BaggagePropagator, trace.B3MultiPropagator)
+
+	// incoming request from a B3 based service
+	carrier := propagation.MapCarrier{
+		"x-b3-traceid": testTraceId,
+		"x-b3-spanid":  testSpanId,
+		"x-b3-sampled": "1",
+	}
+
+	ctx := propagator.Extract(context.Background(), carrier)
+
+	ctx, span := tracerProvider.Tracer("test").Start(ctx, "test span")
+	span.End()
+
+	// the trace is continued
+	assert.True(t, exporter.HasSpan("test span"))
+
+	spans := exporter.Spans().Snapshots()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, testTraceId, spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, testSpanId, spans[0].Parent().SpanID().String())
+	assert.True(t, spans[0].Parent().IsRemote())
+
+	// and propagated with all the propagators
+	outgoing := propagation.MapCarrier{}
+	propagator.Inject(ctx, outgoing)
+
+	assert.Equal(t, testTraceId, outgoing.Get("x-b3-traceid"))
+	assert.Equal(t, span.SpanContext().SpanID().String(), outgoing.Get("x-b3-spanid"))
+	assert.Contains(t, outgoing.Get("traceparent"), testTraceId)
+}
+
+func TestNewPropagatorWithB3SingleHeader(t *testing.T) {
+	t.Parallel()
+
+	propagator := trace.NewPropagator(trace.B3Propagator)
+
+	ctx := propagator.Extract(
+		context.Background(),
+		propagation.MapCarrier{"b3": testTraceId + "-" + testSpanId + "-1"},
+	)
+
+	spanContext := oteltrace.SpanContextFromContext(ctx)
+	assert.Equal(t, testTraceId, spanContext.TraceID().String())
+	assert.Equal(t, testSpanId, spanContext.SpanID().String())
+	assert.True(t, spanContext.IsSampled())
+
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+
+	assert.Equal(t, testTraceId+"-"+testSpanId+"-1", carrier.Get("b3"))
+}
+
+func TestNewPropagatorWithBaggage(t *testing.T) {
+	t.Parallel()
+
+	propagator := trace.NewDefaultPropagator()
+
+	ctx := propagator.Extract(context.Background(), propagation.MapCarrier{"baggage": "tenant=foo"})
+
+	assert.Equal(t, "foo", baggage.FromContext(ctx).Member("tenant").Value())
+}
+
+func TestJaegerPropagator(t *testing.T) {
+	t.Parallel()
+
+	propagator := trace.NewJaegerPropagator()
+
+	tests := []struct {
+		name            string
+		header          string
+		expectedValid   bool
+		expectedTraceId string
+		expectedSpanId  string
+		expectedSampled bool
+	}{
+		{
+			name:            "sampled",
+			header:          testTraceId + ":" + testSpanId + ":0:1",
+			expectedValid:   true,
+			expectedTraceId: testTraceId,
+			expectedSpanId:  testSpanId,
+			expectedSampled: true,
+		},
+		{
+			name:            "debug",
+			header:          testTraceId + ":" + testSpanId + ":0:2",
+			expectedValid:   true,
+			expectedTraceId: testTraceId,
+			expectedSpanId:  testSpanId,
+			expectedSampled: true,
+		},
+		{
+			name:            "not sampled",
+			header:          testTraceId + ":" + testSpanId + ":0:0",
+			expectedValid:   true,
+			expectedTraceId: testTraceId,
+			expectedSpanId:  testSpanId,
+			expectedSampled: false,
+		},
+		{
+			name:            "short ids",
+			header:          "a3ce929d0e0e4736:8d0fdc8a74baaa:0:1",
+			expectedValid:   true,
+			expectedTraceId: "0000000000000000a3ce929d0e0e4736",
+			expectedSpanId:  "008d0fdc8a74baaa",
+			expectedSampled: true,
+		},
+		{
+			name:   "invalid fields count",
+			header: testTraceId + ":" + testSpanId + ":1",
+		},
+		{
+			name:   "invalid trace id",
+			header: "invalid:" + testSpanId + ":0:1",
+		},
+		{
+			name:   "invalid flags",
+			header: testTraceId + ":" + testSpanId + ":0:zz",
+		},
+		{
+			name:   "zero ids",
+			header: "0:0:0:1",
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := propagator.Extract(context.Background(), propagation.MapCarrier{"uber-trace-id": tt.header})
+
+		spanContext := oteltrace.SpanContextFromContext(ctx)
+		assert.Equal(t, tt.expectedValid, spanContext.IsValid(), tt.name)
+
+		if tt.expectedValid {
+			assert.Equal(t, tt.expectedTraceId, spanContext.TraceID().String(), tt.name)
+			assert.Equal(t, tt.expectedSpanId, spanContext.SpanID().String(), tt.name)
+			assert.Equal(t, tt.expectedSampled, spanContext.IsSampled(), tt.name)
+			assert.True(t, spanContext.IsRemote(), tt.name)
+		}
+	}
+}
+
+func TestJaegerPropagatorInject(t *testing.T) {
+	t.Parallel()
+
+	tracerProvider := otelsdktrace.NewTracerProvider()
+
+	propagator := trace.NewJaegerPropagator()
+
+	// invalid span context: nothing injected
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(context.Background(), carrier)
+	assert.Empty(t, carrier.Keys())
+
+	ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "test span")
+	defer span.End()
+
+	propagator.Inject(ctx, carrier)
+
+	assert.Equal(
+		t,
+		span.SpanContext().TraceID().String()+":"+span.SpanContext().SpanID().String()+":0:1",
+		carrier.Get("uber-trace-id"),
+	)
+}